@@ -18,31 +18,67 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	sqldb "database/sql"
 	"errors"
 	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"strings"
+	"time"
 
+	// Register the database/sql drivers of the SQL reporter.
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/net/http/httpproxy"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/prow/pkg/io"
+	"sigs.k8s.io/prow/pkg/pjutil"
 	"sigs.k8s.io/prow/pkg/pjutil/pprof"
 	"sigs.k8s.io/prow/pkg/resultstore"
+	"sigs.k8s.io/prow/pkg/tracing"
 
 	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
 	"sigs.k8s.io/prow/pkg/config"
 	"sigs.k8s.io/prow/pkg/config/secret"
 	"sigs.k8s.io/prow/pkg/crier"
+	"sigs.k8s.io/prow/pkg/crier/reporterplugin"
+	bitbucketreporter "sigs.k8s.io/prow/pkg/crier/reporters/bitbucket"
+	datadogreporter "sigs.k8s.io/prow/pkg/crier/reporters/datadog"
 	dingtalkreporter "sigs.k8s.io/prow/pkg/crier/reporters/dingtalk"
+	discordreporter "sigs.k8s.io/prow/pkg/crier/reporters/discord"
+	emailreporter "sigs.k8s.io/prow/pkg/crier/reporters/email"
 	gcsreporter "sigs.k8s.io/prow/pkg/crier/reporters/gcs"
 	k8sgcsreporter "sigs.k8s.io/prow/pkg/crier/reporters/gcs/kubernetes"
 	gerritreporter "sigs.k8s.io/prow/pkg/crier/reporters/gerrit"
 	githubreporter "sigs.k8s.io/prow/pkg/crier/reporters/github"
+	gitlabreporter "sigs.k8s.io/prow/pkg/crier/reporters/gitlab"
+	googlechatreporter "sigs.k8s.io/prow/pkg/crier/reporters/googlechat"
+	jirareporter "sigs.k8s.io/prow/pkg/crier/reporters/jira"
+	kafkareporter "sigs.k8s.io/prow/pkg/crier/reporters/kafka"
+	matrixreporter "sigs.k8s.io/prow/pkg/crier/reporters/matrix"
+	pagerdutyreporter "sigs.k8s.io/prow/pkg/crier/reporters/pagerduty"
 	pubsubreporter "sigs.k8s.io/prow/pkg/crier/reporters/pubsub"
+	pushgatewayreporter "sigs.k8s.io/prow/pkg/crier/reporters/pushgateway"
+	redisreporter "sigs.k8s.io/prow/pkg/crier/reporters/redis"
 	resultstorereporter "sigs.k8s.io/prow/pkg/crier/reporters/resultstore"
 	slackreporter "sigs.k8s.io/prow/pkg/crier/reporters/slack"
+	snsreporter "sigs.k8s.io/prow/pkg/crier/reporters/sns"
+	sqlreporter "sigs.k8s.io/prow/pkg/crier/reporters/sql"
+	teamsreporter "sigs.k8s.io/prow/pkg/crier/reporters/teams"
+	telegramreporter "sigs.k8s.io/prow/pkg/crier/reporters/telegram"
+	webhookreporter "sigs.k8s.io/prow/pkg/crier/reporters/webhook"
+	emailclient "sigs.k8s.io/prow/pkg/email"
 	prowflagutil "sigs.k8s.io/prow/pkg/flagutil"
 	configflagutil "sigs.k8s.io/prow/pkg/flagutil/config"
 	"sigs.k8s.io/prow/pkg/interrupts"
@@ -57,6 +93,7 @@ type options struct {
 	github           prowflagutil.GitHubOptions
 	githubEnablement prowflagutil.GitHubEnablementOptions
 	gerrit           prowflagutil.GerritOptions
+	jira             prowflagutil.JiraOptions
 
 	config configflagutil.ConfigOptions
 
@@ -68,27 +105,190 @@ type options struct {
 	k8sBlobStorageWorkers int
 	resultStoreWorkers    int
 	dingTalkWorkers       int
+	teamsWorkers          int
+	discordWorkers        int
+	webhookWorkers        int
+	emailWorkers          int
+	jiraWorkers           int
+	pagerDutyWorkers      int
+	telegramWorkers       int
+	matrixWorkers         int
+	snsWorkers            int
+	googleChatWorkers     int
+	pushgatewayWorkers    int
+	bitbucketWorkers      int
+	gitlabWorkers         int
+	sqlWorkers            int
+	kafkaWorkers          int
+	datadogWorkers        int
+	redisWorkers          int
 
 	slackTokenFile            string
 	additionalSlackTokenFiles slackclient.HostsFlag
 
+	teamsWebhookFile      string
+	discordWebhookFile    string
+	googleChatWebhookFile string
+
+	webhookTokenFile string
+
+	pushgatewayURL string
+
+	sqlDriver  string
+	sqlDSNFile string
+
+	kafkaCredentialsFile string
+	kafkaCAFile          string
+
+	datadogAPIKeyFile string
+
+	redisCredentialsFile string
+
+	reporterPluginDir string
+
+	telegramTokenFile string
+	matrixTokenFile   string
+
+	bitbucketTokenFile string
+	gitlabTokenFile    string
+
+	emailSMTPHost        string
+	emailSMTPPort        int
+	emailSMTPImplicitTLS bool
+	emailFrom            string
+	emailCredentialsFile string
+
 	storage prowflagutil.StorageClientOptions
 
 	instrumentationOptions prowflagutil.InstrumentationOptions
+	otelEndpoint           string
 
 	k8sReportFraction float64
 
+	k8sUploadConcurrency   int
+	k8sUploadContainerLogs bool
+
+	gcsUploadRetries int
+
 	dryrun      bool
 	reportAgent string
 
 	resultstoreArtifactsDirOnly bool
+	resultstoreUploadCoverage   bool
+	resultstoreConnect          resultstore.ConnectOptions
+
+	circuitBreakerFailureThreshold int
+	circuitBreakerCoolDown         time.Duration
+
+	pubsubMaxPublishAttempts int
+	pubsubAtMostOnce         bool
+
+	githubReportQPS      float64
+	githubReportBurst    int
+	githubStatusDedupTTL time.Duration
+
+	maxReportsPerSecond float64
+	maxReportAttempts   int
+
+	deadLetterURL string
+
+	drainTimeout time.Duration
+
+	reportRetryBase time.Duration
+	reportRetryMax  time.Duration
+
+	reportTimeout time.Duration
+
+	reportAuditLog bool
+
+	prioritizeRecent bool
+
+	prowjobNamespaces prowflagutil.Strings
+
+	readinessCriticalReporters prowflagutil.Strings
+
+	reportHTTPProxy string
+	reportNoProxy   string
+	reportCAFile    string
+
+	skipReportedJobs     bool
+	liveReportStateCheck bool
+
+	exitOnKubeconfigChange bool
+
+	slackTokenSecret     string
+	slackChannelCacheTTL time.Duration
+
+	prowjobSelector string
+
+	replayFrom  string
+	replayLimit int
+
+	validateConfigAndExit bool
 }
 
 func (o *options) validate() error {
-	if o.gerritWorkers+o.pubsubWorkers+o.githubWorkers+o.slackWorkers+o.blobStorageWorkers+o.k8sBlobStorageWorkers+o.resultStoreWorkers+o.dingTalkWorkers <= 0 {
+	if o.otelEndpoint != "" {
+		if _, _, err := net.SplitHostPort(o.otelEndpoint); err != nil {
+			return fmt.Errorf("--otel-endpoint must be host:port, got %q: %w", o.otelEndpoint, err)
+		}
+	}
+	if o.reportHTTPProxy != "" {
+		if u, err := url.Parse(o.reportHTTPProxy); err != nil || u.Host == "" {
+			return fmt.Errorf("--report-http-proxy must be a URL like http://proxy:3128, got %q", o.reportHTTPProxy)
+		}
+	}
+	if o.reportCAFile != "" {
+		if _, err := reportCertPool(o.reportCAFile); err != nil {
+			return fmt.Errorf("invalid --report-ca-file: %w", err)
+		}
+	}
+	for _, namespace := range o.prowjobNamespaces.Strings() {
+		if namespace == "" {
+			return errors.New("--prowjob-namespaces must not contain empty values")
+		}
+	}
+	if o.reportTimeout < 0 {
+		return errors.New("--report-timeout must not be negative")
+	}
+	if o.drainTimeout < 0 {
+		return errors.New("--drain-timeout must not be negative")
+	}
+	if o.reportRetryBase <= 0 {
+		return errors.New("--report-retry-base must be positive")
+	}
+	if o.reportRetryMax < o.reportRetryBase {
+		return errors.New("--report-retry-max must not be less than --report-retry-base")
+	}
+	if o.gerritWorkers+o.pubsubWorkers+o.githubWorkers+o.slackWorkers+o.blobStorageWorkers+o.k8sBlobStorageWorkers+o.resultStoreWorkers+o.dingTalkWorkers+o.teamsWorkers+o.discordWorkers+o.webhookWorkers+o.emailWorkers+o.jiraWorkers+o.pagerDutyWorkers+o.telegramWorkers+o.matrixWorkers+o.snsWorkers+o.googleChatWorkers+o.pushgatewayWorkers+o.bitbucketWorkers+o.gitlabWorkers+o.sqlWorkers+o.kafkaWorkers+o.datadogWorkers+o.redisWorkers <= 0 && o.reporterPluginDir == "" {
 		return errors.New("crier need to have at least one report worker to start")
 	}
 
+	if _, err := labels.Parse(o.prowjobSelector); err != nil {
+		return fmt.Errorf("--prowjob-selector: %w", err)
+	}
+
+	if o.replayFrom != "" {
+		if !o.dryrun {
+			return errors.New("--dry-run must be set when --replay-from is set")
+		}
+		if o.pubsubWorkers+o.resultStoreWorkers > 0 {
+			return errors.New("--replay-from can't be used with the pubsub and resultstore reporters, which don't support dry-run")
+		}
+		if o.replayLimit < 1 {
+			return errors.New("--replay-limit must be at least 1")
+		}
+		if o.validateConfigAndExit {
+			return errors.New("--replay-from and --validate-config-and-exit are mutually exclusive")
+		}
+	}
+
+	if o.k8sUploadConcurrency < 1 {
+		return errors.New("--k8s-upload-concurrency must be at least 1")
+	}
+	if o.gcsUploadRetries < 0 {
+		return errors.New("--gcs-upload-retries must not be negative")
+	}
 	if o.k8sReportFraction < 0 || o.k8sReportFraction > 1 {
 		return errors.New("--kubernetes-report-fraction must be a float between 0 and 1")
 	}
@@ -108,13 +308,116 @@ func (o *options) validate() error {
 		}
 	}
 
+	if o.githubReportQPS < 0 {
+		return errors.New("--github-report-qps must not be negative")
+	}
+	if o.githubReportQPS > 0 && o.githubReportBurst < 1 {
+		return errors.New("--github-report-burst must be at least 1 when --github-report-qps is set")
+	}
+	if o.githubStatusDedupTTL < 0 {
+		return errors.New("--github-status-dedup-ttl must not be negative")
+	}
+	if err := o.resultstoreConnect.Validate(); err != nil {
+		return fmt.Errorf("invalid ResultStore connection flags: %w", err)
+	}
+	if o.maxReportsPerSecond < 0 {
+		return errors.New("--max-reports-per-second must not be negative")
+	}
+	if o.maxReportAttempts < 0 {
+		return errors.New("--max-report-attempts must not be negative")
+	}
+	if o.deadLetterURL != "" {
+		if u, err := url.Parse(o.deadLetterURL); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("--dead-letter-url must be a URL like https://dead-letter.example.com/crier, got %q", o.deadLetterURL)
+		}
+	}
+
 	if o.slackWorkers > 0 {
-		if o.slackTokenFile == "" && len(o.additionalSlackTokenFiles) == 0 {
-			return errors.New("one of --slack-token-file or --additional-slack-token-files must be set")
+		if o.slackTokenFile == "" && o.slackTokenSecret == "" && len(o.additionalSlackTokenFiles) == 0 {
+			return errors.New("one of --slack-token-file, --slack-token-secret or --additional-slack-token-files must be set")
 		}
+		if o.slackTokenFile != "" && o.slackTokenSecret != "" {
+			return errors.New("--slack-token-file and --slack-token-secret are mutually exclusive")
+		}
+		if o.slackTokenSecret != "" {
+			if _, err := secret.ParseKubernetesSecretRef(o.slackTokenSecret); err != nil {
+				return fmt.Errorf("--slack-token-secret: %w", err)
+			}
+		}
+		if o.slackChannelCacheTTL <= 0 {
+			return errors.New("--slack-channel-cache-ttl must be positive")
+		}
+	}
+
+	if o.emailWorkers > 0 && (o.emailSMTPHost == "" || o.emailFrom == "") {
+		return errors.New("--email-smtp-host and --email-from must be set when --email-workers is enabled")
+	}
+
+	if o.jiraWorkers > 0 {
+		if err := o.jira.Validate(o.dryrun); err != nil {
+			return err
+		}
+	}
+
+	if o.pubsubMaxPublishAttempts < 1 {
+		return errors.New("--pubsub-max-publish-attempts must be at least 1")
+	}
+
+	if o.circuitBreakerFailureThreshold < 0 {
+		return errors.New("--circuit-breaker-failure-threshold must not be negative")
+	}
+
+	if o.teamsWorkers > 0 && o.teamsWebhookFile == "" {
+		return errors.New("--teams-webhook-file must be set when --teams-workers is enabled")
+	}
+
+	if o.discordWorkers > 0 && o.discordWebhookFile == "" {
+		return errors.New("--discord-webhook-file must be set when --discord-workers is enabled")
+	}
+
+	if o.telegramWorkers > 0 && o.telegramTokenFile == "" {
+		return errors.New("--telegram-token-file must be set when --telegram-workers is enabled")
 	}
 
-	for _, opt := range []interface{ Validate(bool) error }{&o.client, &o.githubEnablement, &o.config} {
+	if o.matrixWorkers > 0 && o.matrixTokenFile == "" {
+		return errors.New("--matrix-token-file must be set when --matrix-workers is enabled")
+	}
+
+	if o.bitbucketWorkers > 0 && o.bitbucketTokenFile == "" {
+		return errors.New("--bitbucket-token-file must be set when --bitbucket-workers is enabled")
+	}
+
+	if o.gitlabWorkers > 0 && o.gitlabTokenFile == "" {
+		return errors.New("--gitlab-token-file must be set when --gitlab-workers is enabled")
+	}
+
+	if o.googleChatWorkers > 0 && o.googleChatWebhookFile == "" {
+		return errors.New("--googlechat-webhook-file must be set when --googlechat-workers is enabled")
+	}
+
+	if o.datadogWorkers > 0 && o.datadogAPIKeyFile == "" {
+		return errors.New("--datadog-api-key-file must be set when --datadog-workers is enabled")
+	}
+
+	if o.pushgatewayWorkers > 0 {
+		if o.pushgatewayURL == "" {
+			return errors.New("--pushgateway-reporter-url must be set when --pushgateway-reporter-workers is enabled")
+		}
+		if u, err := url.Parse(o.pushgatewayURL); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("--pushgateway-reporter-url must be a URL like http://pushgateway:9091, got %q", o.pushgatewayURL)
+		}
+	}
+
+	if o.sqlWorkers > 0 {
+		if o.sqlDSNFile == "" {
+			return errors.New("--sql-dsn-file must be set when --sql-workers is enabled")
+		}
+		if !sets.New(sqlreporter.SupportedDrivers()...).Has(o.sqlDriver) {
+			return fmt.Errorf("--sql-driver must be one of %s, got %q", strings.Join(sqlreporter.SupportedDrivers(), ", "), o.sqlDriver)
+		}
+	}
+
+	for _, opt := range []interface{ Validate(bool) error }{&o.client, &o.githubEnablement, &o.config, &o.instrumentationOptions} {
 		if err := opt.Validate(o.dryrun); err != nil {
 			return err
 		}
@@ -127,24 +430,101 @@ func (o *options) parseArgs(fs *flag.FlagSet, args []string) error {
 	fs.StringVar(&o.cookiefilePath, "cookiefile", "", "Path to git http.cookiefile, leave empty for anonymous")
 	fs.IntVar(&o.gerritWorkers, "gerrit-workers", 0, "Number of gerrit report workers (0 means disabled)")
 	fs.IntVar(&o.pubsubWorkers, "pubsub-workers", 0, "Number of pubsub report workers (0 means disabled)")
+	fs.IntVar(&o.pubsubMaxPublishAttempts, "pubsub-max-publish-attempts", pubsubreporter.DefaultMaxPublishAttempts, "Number of times the pubsub reporter tries to publish a message before giving up and retrying later")
+	fs.BoolVar(&o.pubsubAtMostOnce, "pubsub-at-most-once", false, "Persist the report state before publishing, so that a job state is published at most once instead of at least once")
 	fs.IntVar(&o.githubWorkers, "github-workers", 0, "Number of github report workers (0 means disabled)")
 	fs.IntVar(&o.slackWorkers, "slack-workers", 0, "Number of Slack report workers (0 means disabled)")
 	fs.IntVar(&o.dingTalkWorkers, "dingtalk-workers", 0, "Number of DingTalk report workers (0 means disabled)")
+	fs.IntVar(&o.teamsWorkers, "teams-workers", 0, "Number of Microsoft Teams report workers (0 means disabled)")
+	fs.StringVar(&o.teamsWebhookFile, "teams-webhook-file", "", "Path to a file containing a map of Microsoft Teams channel names to incoming webhook URLs")
+	fs.IntVar(&o.discordWorkers, "discord-workers", 0, "Number of Discord report workers (0 means disabled)")
+	fs.StringVar(&o.discordWebhookFile, "discord-webhook-file", "", "Path to a file containing a map of Discord channel names to webhook URLs")
+	fs.IntVar(&o.webhookWorkers, "webhook-workers", 0, "Number of HTTP webhook report workers (0 means disabled)")
+	fs.StringVar(&o.webhookTokenFile, "webhook-token-file", "", "Path to a file containing a bearer token sent by the webhook reporter (optional)")
+	fs.IntVar(&o.emailWorkers, "email-workers", 0, "Number of email report workers (0 means disabled)")
+	fs.StringVar(&o.emailSMTPHost, "email-smtp-host", "", "Host of the SMTP server used by the email reporter")
+	fs.IntVar(&o.emailSMTPPort, "email-smtp-port", 587, "Port of the SMTP server used by the email reporter")
+	fs.BoolVar(&o.emailSMTPImplicitTLS, "email-smtp-implicit-tls", false, "Connect to the SMTP server using TLS right away instead of STARTTLS, usually on port 465")
+	fs.StringVar(&o.emailFrom, "email-from", "", "Address the email reporter sends emails from")
+	fs.StringVar(&o.emailCredentialsFile, "email-credentials-file", "", "Path to a YAML file with the username and password of the SMTP server (optional)")
+	fs.IntVar(&o.jiraWorkers, "jira-workers", 0, "Number of Jira report workers (0 means disabled)")
+	fs.IntVar(&o.pagerDutyWorkers, "pagerduty-workers", 0, "Number of PagerDuty report workers (0 means disabled)")
+	fs.IntVar(&o.telegramWorkers, "telegram-workers", 0, "Number of Telegram report workers (0 means disabled)")
+	fs.StringVar(&o.telegramTokenFile, "telegram-token-file", "", "Path to a file containing the token of the Telegram bot")
+	fs.IntVar(&o.matrixWorkers, "matrix-workers", 0, "Number of Matrix report workers (0 means disabled)")
+	fs.StringVar(&o.matrixTokenFile, "matrix-token-file", "", "Path to a file containing the access token of the Matrix user")
+	fs.IntVar(&o.snsWorkers, "sns-workers", 0, "Number of Amazon SNS report workers (0 means disabled)")
+	fs.IntVar(&o.googleChatWorkers, "googlechat-workers", 0, "Number of Google Chat report workers (0 means disabled)")
+	fs.StringVar(&o.googleChatWebhookFile, "googlechat-webhook-file", "", "Path to a file containing a map of Google Chat space names to incoming webhook URLs")
+	fs.IntVar(&o.pushgatewayWorkers, "pushgateway-reporter-workers", 0, "Number of Prometheus Pushgateway report workers (0 means disabled)")
+	fs.StringVar(&o.pushgatewayURL, "pushgateway-reporter-url", "", "URL of the Prometheus Pushgateway the results of jobs are pushed to")
+	fs.IntVar(&o.sqlWorkers, "sql-workers", 0, "Number of SQL database report workers (0 means disabled)")
+	fs.StringVar(&o.sqlDriver, "sql-driver", "", fmt.Sprintf("Name of the database/sql driver the SQL reporter connects with, one of %s", strings.Join(sqlreporter.SupportedDrivers(), ", ")))
+	fs.StringVar(&o.sqlDSNFile, "sql-dsn-file", "", "Path to a file containing the data source name the SQL reporter connects to the database with")
+	fs.StringVar(&o.reporterPluginDir, "reporter-plugin-dir", "", "Directory with Go plugins (.so files) of additional reporters, which are started with the workers set for their name in crier.workers of the config (disabled if empty)")
+	fs.IntVar(&o.kafkaWorkers, "kafka-workers", 0, "Number of Kafka report workers (0 means disabled)")
+	fs.StringVar(&o.kafkaCredentialsFile, "kafka-credentials-file", "", "Path to a YAML file with the username and password the Kafka reporter authenticates with through SASL (optional)")
+	fs.StringVar(&o.kafkaCAFile, "kafka-ca-file", "", "Path to a PEM file with the certificates the Kafka reporter verifies TLS brokers with, instead of the system cert pool (optional)")
+	fs.IntVar(&o.datadogWorkers, "datadog-workers", 0, "Number of Datadog report workers (0 means disabled)")
+	fs.StringVar(&o.datadogAPIKeyFile, "datadog-api-key-file", "", "Path to a file containing the API key the Datadog reporter posts events with")
+	fs.IntVar(&o.redisWorkers, "redis-workers", 0, "Number of Redis stream report workers (0 means disabled)")
+	fs.StringVar(&o.redisCredentialsFile, "redis-credentials-file", "", "Path to a YAML file with the password, and optionally the username, the Redis reporter authenticates with (optional)")
+	fs.IntVar(&o.bitbucketWorkers, "bitbucket-workers", 0, "Number of Bitbucket Server report workers (0 means disabled)")
+	fs.StringVar(&o.bitbucketTokenFile, "bitbucket-token-file", "", "Path to a file containing the HTTP access token used to post build statuses to Bitbucket Server")
+	fs.IntVar(&o.gitlabWorkers, "gitlab-workers", 0, "Number of GitLab report workers (0 means disabled)")
+	fs.StringVar(&o.gitlabTokenFile, "gitlab-token-file", "", "Path to a file containing the access token used to post commit statuses and merge request notes to GitLab")
 	fs.Var(&o.additionalSlackTokenFiles, "additional-slack-token-files", "Map of additional slack token files. example: --additional-slack-token-files=foo=/etc/foo-slack-tokens/token, repeat flag for each host")
 	fs.IntVar(&o.blobStorageWorkers, "blob-storage-workers", 0, "Number of blob storage report workers (0 means disabled)")
 	fs.IntVar(&o.k8sBlobStorageWorkers, "kubernetes-blob-storage-workers", 0, "Number of Kubernetes-specific blob storage report workers (0 means disabled)")
 	fs.Float64Var(&o.k8sReportFraction, "kubernetes-report-fraction", 1.0, "Approximate portion of jobs to report pod information for, if kubernetes-blob-storage-workers are enabled (0 - > none, 1.0 -> all)")
+	fs.IntVar(&o.k8sUploadConcurrency, "k8s-upload-concurrency", 4, "Number of files of a job the Kubernetes-specific blob storage reporter uploads in parallel")
+	fs.IntVar(&o.gcsUploadRetries, "gcs-upload-retries", gcsreporter.DefaultUploadRetries, "Number of times the blob storage reporter retries an upload that failed with a transient error (0 means no retries)")
+	fs.BoolVar(&o.k8sUploadContainerLogs, "k8s-upload-container-logs", false, "Whether the Kubernetes-specific blob storage reporter uploads the logs of all containers of the pod")
 	fs.StringVar(&o.slackTokenFile, "slack-token-file", "", "Path to a Slack token file")
-	fs.StringVar(&o.reportAgent, "report-agent", "", "Only report specified agent - empty means report to all agents (effective for github and Slack only)")
+	fs.StringVar(&o.slackTokenSecret, "slack-token-secret", "", "Kubernetes Secret key holding the Slack token, as namespace/name/key, read from the infrastructure cluster instead of --slack-token-file")
+	fs.DurationVar(&o.slackChannelCacheTTL, "slack-channel-cache-ttl", slackreporter.DefaultChannelCacheTTL, "How long the Slack reporter reuses the channel IDs it looked up before looking them up again")
+	fs.StringVar(&o.reportAgent, "report-agent", "", "Only report jobs of the specified agent, e.g. kubernetes, with all reporters - empty means report jobs of all agents")
 	fs.IntVar(&o.resultStoreWorkers, "resultstore-workers", 0, "Number of ResultStore report workers (0 means disabled)")
 	fs.BoolVar(&o.resultstoreArtifactsDirOnly, "resultstore-artifacts-dir-only", false, "Report the artifacts/ dir instead of subtree files (testing)")
+	fs.BoolVar(&o.resultstoreUploadCoverage, "resultstore-upload-coverage", false, "Report the coverage in the job's artifacts/coverage.json as invocation properties")
+	fs.StringVar(&o.resultstoreConnect.Endpoint, "resultstore-endpoint", "", fmt.Sprintf("host:port of the ResultStore gRPC API, e.g. of a staging instance (defaults to %s)", resultstore.ResultStoreAddress))
+	fs.BoolVar(&o.resultstoreConnect.Insecure, "resultstore-insecure", false, "Connect to --resultstore-endpoint without TLS and credentials, e.g. to a local fake (testing)")
+	fs.StringVar(&o.resultstoreConnect.CAFile, "resultstore-ca-file", "", "Path to a PEM file with the certificates to verify the ResultStore server with instead of the system cert pool")
+	fs.IntVar(&o.circuitBreakerFailureThreshold, "circuit-breaker-failure-threshold", 0, "Number of consecutive reporting failures after which a reporter stops reporting for --circuit-breaker-cool-down (0 means disabled)")
+	fs.Float64Var(&o.githubReportQPS, "github-report-qps", 0, "Maximum number of jobs per second the github reporter reports on average (0 means unlimited)")
+	fs.IntVar(&o.githubReportBurst, "github-report-burst", 1, "Maximum number of jobs the github reporter reports in a burst when --github-report-qps is set")
+	fs.DurationVar(&o.githubStatusDedupTTL, "github-status-dedup-ttl", 0, "How long the github reporter remembers the statuses it created, to skip updates that don't change the state, description or URL of a status (0 means disabled)")
+	fs.Float64Var(&o.maxReportsPerSecond, "max-reports-per-second", 0, "Maximum number of jobs per second reported by all reporters together, e.g. to smooth the burst of reports of a backlog (0 means unlimited)")
+	fs.IntVar(&o.maxReportAttempts, "max-report-attempts", 0, "Number of times the report of a job state may fail before it is given up (0 means retrying until it succeeds)")
+	fs.StringVar(&o.deadLetterURL, "dead-letter-url", "", "URL that a JSON record of every dropped report is posted to, e.g. to recover them later (disabled if empty)")
+	fs.DurationVar(&o.circuitBreakerCoolDown, "circuit-breaker-cool-down", time.Minute, "How long a reporter stops reporting once its circuit breaker opened")
+	fs.DurationVar(&o.drainTimeout, "drain-timeout", 30*time.Second, "How long reports that are in flight on shutdown may continue before they are cancelled")
+	fs.DurationVar(&o.reportRetryBase, "report-retry-base", time.Second, "Delay before retrying a failed report, doubled with every consecutive failure of the same job")
+	fs.DurationVar(&o.reportRetryMax, "report-retry-max", 5*time.Minute, "Maximum delay between retries of a failed report")
+	fs.DurationVar(&o.reportTimeout, "report-timeout", 0, "How long a single report may take before it is cancelled and retried, can be overridden per reporter in the config (0 means no timeout)")
+	fs.BoolVar(&o.reportAuditLog, "report-audit-log", false, "Log a structured audit record for every report")
+	fs.BoolVar(&o.prioritizeRecent, "prioritize-recent", false, "Reconcile the most recently completed ProwJobs first, e.g. to report the freshest results first while working through a backlog (best effort)")
+	fs.Var(&o.prowjobNamespaces, "prowjob-namespaces", "Namespace whose ProwJobs are reported, can be passed multiple times. Defaults to the prowjob_namespace of the config")
+	fs.StringVar(&o.reportHTTPProxy, "report-http-proxy", "", "Proxy for the HTTP and HTTPS requests of reporters, overriding the HTTP_PROXY and HTTPS_PROXY environment variables")
+	fs.StringVar(&o.reportNoProxy, "report-no-proxy", "", "Comma-separated hosts reporters reach without the proxy, overriding the NO_PROXY environment variable")
+	fs.StringVar(&o.reportCAFile, "report-ca-file", "", "Path to a PEM file with CA certificates that reporters trust in addition to the system cert pool, e.g. for endpoints with certificates of a private CA")
+	fs.Var(&o.readinessCriticalReporters, "readiness-critical-reporters", "Name of a reporter, e.g. slackreporter, whose backend must be reachable for crier to be ready, can be passed multiple times")
+	fs.BoolVar(&o.exitOnKubeconfigChange, "exit-on-kubeconfig-change", true, "Exit when a kubeconfig changes, so that a restart picks up new build clusters. If false, changes are only logged and crier keeps the clusters it started with until it is restarted")
+	fs.BoolVar(&o.skipReportedJobs, "skip-reported-jobs", false, "Annotate completed jobs once all enabled reporters are done with them, and stop reconciling them")
+	fs.BoolVar(&o.liveReportStateCheck, "live-report-state-check", false, "Read every job from the API server before reporting it, so that a state isn't reported twice while the cache lags behind. Costs a GET request per report of every reporter")
+	fs.StringVar(&o.prowjobSelector, "prowjob-selector", "", "Label selector, e.g. reporter!=pipeline, restricting the ProwJobs crier reports (empty means all)")
+	fs.StringVar(&o.replayFrom, "replay-from", "", "Storage path, e.g. gs://bucket/logs/my-job, or namespace of completed ProwJobs to run through the enabled reporters in dry-run mode before exiting, instead of reporting")
+	fs.IntVar(&o.replayLimit, "replay-limit", 50, "Maximum number of the most recently completed ProwJobs replayed by --replay-from")
+	fs.StringVar(&o.otelEndpoint, "otel-endpoint", "", "host:port of an OTLP gRPC endpoint, e.g. an OpenTelemetry collector, to export traces to (disabled if empty)")
+	fs.BoolVar(&o.validateConfigAndExit, "validate-config-and-exit", false, "Validate the config of the enabled reporters against their backends, print the results and exit, with a non-zero code if any validation failed")
 
-	// TODO(krzyzacy): implement dryrun for gerrit/pubsub
-	fs.BoolVar(&o.dryrun, "dry-run", false, "Run in dry-run mode, not doing actual report (effective for github and Slack only)")
+	// TODO(krzyzacy): implement dryrun for pubsub
+	fs.BoolVar(&o.dryrun, "dry-run", false, "Run in dry-run mode, not doing actual report (effective for github, gerrit, Slack, Microsoft Teams, Discord, webhook, email, Jira, PagerDuty, Telegram, Matrix, SNS, Google Chat, Pushgateway, Bitbucket, GitLab, SQL, Kafka, Datadog and Redis only)")
 
 	o.config.AddFlags(fs)
 	o.github.AddFlags(fs)
 	o.gerrit.AddFlags(fs)
+	o.jira.AddFlags(fs)
 	o.client.AddFlags(fs)
 	o.storage.AddFlags(fs)
 	o.instrumentationOptions.AddFlags(fs)
@@ -165,12 +545,118 @@ func parseOptions() options {
 	return o
 }
 
+// configureReportProxy makes the transport use the proxy settings of the
+// environment, overridden by the given proxy and no-proxy hosts when set.
+func configureReportProxy(transport *http.Transport, httpProxy, noProxy string) {
+	proxyConfig := httpproxy.FromEnvironment()
+	if httpProxy != "" {
+		proxyConfig.HTTPProxy = httpProxy
+		proxyConfig.HTTPSProxy = httpProxy
+	}
+	if noProxy != "" {
+		proxyConfig.NoProxy = noProxy
+	}
+	proxyFunc := proxyConfig.ProxyFunc()
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+}
+
+// reportCertPool returns the system cert pool with the CA certificates of
+// the PEM file added.
+func reportCertPool(caFile string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to load the system cert pool, only trusting --report-ca-file")
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", caFile)
+	}
+	return pool, nil
+}
+
+// configureReportCAs makes the transport trust the CA certificates of the
+// PEM file in addition to the system cert pool.
+func configureReportCAs(transport *http.Transport, caFile string) error {
+	pool, err := reportCertPool(caFile)
+	if err != nil {
+		return err
+	}
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+	transport.TLSClientConfig.RootCAs = pool
+	return nil
+}
+
+// reportRequestTimeout bounds every request of the reporter HTTP client, so
+// that a backend that doesn't respond can't block a worker even without
+// --report-timeout, which bounds whole reports instead.
+const reportRequestTimeout = 30 * time.Second
+
+// newReportHTTPClient returns the HTTP client of the reporters. Its transport
+// is a copy of the default transport with the proxy and CA certificates of
+// the flags, so other HTTP clients, e.g. of storage, are not affected.
+func newReportHTTPClient(o options) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	configureReportProxy(transport, o.reportHTTPProxy, o.reportNoProxy)
+	if o.reportCAFile != "" {
+		if err := configureReportCAs(transport, o.reportCAFile); err != nil {
+			return nil, err
+		}
+	}
+	return &http.Client{Transport: transport, Timeout: reportRequestTimeout}, nil
+}
+
+// replayJobs runs the completed jobs of the --replay-from storage path or
+// namespace through the reporters, which log what they would send.
+func replayJobs(o options, reader ctrlruntimeclient.Reader, reporters []crier.ReportClient, enablementChecker crier.EnablementChecker) error {
+	// The reporters log what they would send at debug level.
+	logrus.SetLevel(logrus.DebugLevel)
+	ctx := context.Background()
+	log := logrus.WithField("replay-from", o.replayFrom)
+
+	var pjs []prowapi.ProwJob
+	if strings.Contains(o.replayFrom, "://") {
+		opener, err := o.storage.StorageClient(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to create opener: %w", err)
+		}
+		if pjs, err = crier.LoadJobsFromStorage(ctx, log, opener, o.replayFrom, o.replayLimit); err != nil {
+			return err
+		}
+	} else {
+		var err error
+		if pjs, err = crier.LoadJobsFromCluster(ctx, reader, o.replayFrom, o.replayLimit); err != nil {
+			return err
+		}
+	}
+
+	log.Infof("Replaying %d jobs", len(pjs))
+	return crier.Replay(ctx, log, reporters, enablementChecker, pjs)
+}
+
 func main() {
 	logrusutil.ComponentInit()
 
 	o := parseOptions()
 
 	pprof.Instrument(o.instrumentationOptions)
+	if err := tracing.Instrument("crier", o.otelEndpoint); err != nil {
+		logrus.WithError(err).Fatal("Failed to set up tracing")
+	}
+
+	reportClient, err := newReportHTTPClient(o)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to load --report-ca-file")
+	}
+	o.github.BaseRoundTripper = reportClient.Transport
+	o.jira.BaseRoundTripper = reportClient.Transport
 
 	configAgent, err := o.config.ConfigAgent()
 	if err != nil {
@@ -183,12 +669,29 @@ func main() {
 	if err != nil {
 		logrus.WithError(err).Fatal("Failed to get kubeconfig")
 	}
+	namespaces := map[string]cache.Config{}
+	for _, namespace := range o.prowjobNamespaces.Strings() {
+		namespaces[namespace] = cache.Config{}
+	}
+	if len(namespaces) == 0 {
+		namespaces[cfg().ProwJobNamespace] = cache.Config{}
+	}
+	cacheOptions := cache.Options{
+		DefaultNamespaces: namespaces,
+	}
+	// Validated when parsing the options.
+	prowjobSelector, _ := labels.Parse(o.prowjobSelector)
+	if !prowjobSelector.Empty() {
+		// Jobs that don't match are neither cached nor reconciled.
+		cacheOptions.ByObject = map[ctrlruntimeclient.Object]cache.ByObject{
+			&prowapi.ProwJob{}: {Label: prowjobSelector},
+		}
+	}
+	// Leave reporters some time to return once their drain timeout passed.
+	shutdownTimeout := o.drainTimeout + 10*time.Second
 	mgr, err := manager.New(restCfg, manager.Options{
-		Cache: cache.Options{
-			DefaultNamespaces: map[string]cache.Config{
-				cfg().ProwJobNamespace: {},
-			},
-		},
+		GracefulShutdownTimeout: &shutdownTimeout,
+		Cache:                   cacheOptions,
 		Metrics: server.Options{
 			BindAddress: "0",
 		},
@@ -200,12 +703,93 @@ func main() {
 	// The watch apimachinery doesn't support restarts, so just exit the binary if a kubeconfig changes
 	// to make the kubelet restart us.
 	if err := o.client.AddKubeconfigChangeCallback(func() {
+		if !o.exitOnKubeconfigChange {
+			logrus.Info("Kubeconfig changed, keeping the current clusters until crier is restarted")
+			return
+		}
 		logrus.Info("Kubeconfig changed, exiting to trigger a restart")
 		interrupts.Terminate()
 	}); err != nil {
 		logrus.WithError(err).Fatal("Failed to register kubeconfig change callback")
 	}
 
+	// The worker counts passed via flags can be overridden in the config,
+	// which takes effect without restarting crier.
+	crierOpts := []crier.Option{crier.WithWorkerOverrides(func() map[string]int {
+		return cfg().Crier.Workers
+	}), crier.WithDrainTimeout(o.drainTimeout), crier.WithRetryBackoff(crier.RetryBackoffOptions{
+		Base: o.reportRetryBase,
+		Max:  o.reportRetryMax,
+	})}
+	crierOpts = append(crierOpts, crier.WithReportTimeout(func(reporter string) time.Duration {
+		return cfg().Crier.ReportTimeoutFor(reporter, o.reportTimeout)
+	}))
+	readiness := crier.NewReadiness(o.readinessCriticalReporters.Strings())
+	crierOpts = append(crierOpts, crier.WithSkipReporting(func(pj *prowapi.ProwJob) bool {
+		return cfg().Crier.SkipsReporting(pj)
+	}))
+	crierOpts = append(crierOpts, crier.WithReadiness(readiness))
+	if o.liveReportStateCheck {
+		crierOpts = append(crierOpts, crier.WithLiveReportStateCheck())
+	}
+	if o.skipReportedJobs {
+		crierOpts = append(crierOpts, crier.WithSkipReportedJobs(crier.NewReportedJobs()))
+	}
+	if !prowjobSelector.Empty() {
+		crierOpts = append(crierOpts, crier.WithLabelSelector(prowjobSelector))
+	}
+	if o.reportAuditLog {
+		crierOpts = append(crierOpts, crier.WithAuditLog())
+	}
+	if o.prioritizeRecent {
+		crierOpts = append(crierOpts, crier.WithPrioritizeRecent())
+	}
+	if o.reportAgent != "" {
+		crierOpts = append(crierOpts, crier.WithReportAgent(prowapi.ProwJobAgent(o.reportAgent)))
+	}
+	if o.maxReportsPerSecond > 0 {
+		// All controllers share the throttle, so the limit holds across
+		// reporters.
+		crierOpts = append(crierOpts, crier.WithReportThrottle(crier.NewReportThrottle(o.maxReportsPerSecond)))
+	}
+	if o.maxReportAttempts > 0 {
+		crierOpts = append(crierOpts, crier.WithMaxReportAttempts(o.maxReportAttempts))
+	}
+	if o.deadLetterURL != "" {
+		crierOpts = append(crierOpts, crier.WithDeadLetterSink(crier.NewDeadLetterSink(o.deadLetterURL)))
+	}
+	if o.circuitBreakerFailureThreshold > 0 {
+		crierOpts = append(crierOpts, crier.WithCircuitBreaker(crier.CircuitBreakerOptions{
+			FailureThreshold: o.circuitBreakerFailureThreshold,
+			CoolDown:         o.circuitBreakerCoolDown,
+		}))
+	}
+
+	// Reporters are enabled for the repos crier is enabled for via flags
+	// and can be further restricted per reporter in the config.
+	githubEnablement := o.githubEnablement.EnablementChecker()
+	enablementChecker := func(reporter, org, repo string) bool {
+		return githubEnablement(org, repo) && cfg().Crier.ReporterEnabled(reporter, org, repo)
+	}
+
+	// When replaying jobs or validating the config, the reporters are
+	// collected instead of being run by controllers.
+	newController := crier.New
+	var collectedReporters []crier.ReportClient
+	if o.replayFrom != "" || o.validateConfigAndExit {
+		newController = func(_ manager.Manager, reporter crier.ReportClient, _ int, _ crier.EnablementChecker, _ ...crier.Option) error {
+			collectedReporters = append(collectedReporters, reporter)
+			return nil
+		}
+	}
+
+	// Notification reporters can leave out failed attempts of jobs that are
+	// going to be retried. Status reporters report every attempt, so that
+	// no status stays pending if the retry never gets created.
+	notificationOpts := append(append([]crier.Option{}, crierOpts...), crier.WithSuppressRetryableFailures(func() bool {
+		return cfg().Crier.SuppressRetryableFailures
+	}))
+
 	var hasReporter bool
 	if o.slackWorkers > 0 {
 		if cfg().SlackReporterConfigs == nil {
@@ -221,6 +805,14 @@ func main() {
 				logrus.WithError(err).Fatal("could not read slack token")
 			}
 		}
+		if o.slackTokenSecret != "" {
+			// Validated when parsing the options.
+			ref, _ := secret.ParseKubernetesSecretRef(o.slackTokenSecret)
+			tokensMap[slackreporter.DefaultHostName] = secret.GetTokenGenerator(ref.String())
+			if err := secret.AddKubernetesSecret(mgr.GetAPIReader(), ref); err != nil {
+				logrus.WithError(err).Fatal("could not read slack token")
+			}
+		}
 		hasReporter = true
 		for host, additionalTokenFile := range o.additionalSlackTokenFiles {
 			tokensMap[host] = secret.GetTokenGenerator(additionalTokenFile)
@@ -228,8 +820,11 @@ func main() {
 				logrus.WithError(err).Fatal("could not read slack token")
 			}
 		}
-		slackReporter := slackreporter.New(slackConfig, o.dryrun, tokensMap)
-		if err := crier.New(mgr, slackReporter, o.slackWorkers, o.githubEnablement.EnablementChecker()); err != nil {
+		slackConfigKeys := func() []string {
+			return sets.List(sets.KeySet(cfg().SlackReporterConfigs))
+		}
+		slackReporter := slackreporter.New(slackConfig, slackConfigKeys, o.dryrun, tokensMap, mgr.GetClient(), o.slackChannelCacheTTL, reportClient)
+		if err := newController(mgr, slackReporter, o.slackWorkers, enablementChecker, notificationOpts...); err != nil {
 			logrus.WithError(err).Fatal("failed to construct slack reporter controller")
 		}
 	}
@@ -238,24 +833,41 @@ func main() {
 		orgRepoConfigGetter := func() *config.GerritOrgRepoConfigs {
 			return cfg().Gerrit.OrgReposConfig
 		}
-		gerritReporter, err := gerritreporter.NewReporter(orgRepoConfigGetter, o.cookiefilePath, mgr.GetClient(), o.gerrit.MaxQPS, o.gerrit.MaxBurst)
+		gerritReporter, err := gerritreporter.NewReporter(orgRepoConfigGetter, o.cookiefilePath, mgr.GetClient(), o.gerrit.MaxQPS, o.gerrit.MaxBurst, o.dryrun)
 		if err != nil {
 			logrus.WithError(err).Fatal("Error starting gerrit reporter")
 		}
 
 		hasReporter = true
-		if err := crier.New(mgr, gerritReporter, o.gerritWorkers, o.githubEnablement.EnablementChecker()); err != nil {
+		if err := newController(mgr, gerritReporter, o.gerritWorkers, enablementChecker, crierOpts...); err != nil {
 			logrus.WithError(err).Fatal("failed to construct gerrit reporter controller")
 		}
 	}
 
 	if o.pubsubWorkers > 0 {
 		hasReporter = true
-		if err := crier.New(mgr, pubsubreporter.NewReporter(cfg), o.pubsubWorkers, o.githubEnablement.EnablementChecker()); err != nil {
+		pubsubOpts := crierOpts
+		if o.pubsubAtMostOnce {
+			pubsubOpts = append(append([]crier.Option{}, crierOpts...), crier.WithAtMostOnce())
+		}
+		if err := newController(mgr, pubsubreporter.NewReporter(cfg, o.pubsubMaxPublishAttempts), o.pubsubWorkers, enablementChecker, pubsubOpts...); err != nil {
 			logrus.WithError(err).Fatal("failed to construct pubsub reporter controller")
 		}
 	}
 
+	// The GitHub reporter reads build logs to include them in check runs.
+	var opener io.Opener
+	if o.githubWorkers+o.blobStorageWorkers+o.k8sBlobStorageWorkers+o.resultStoreWorkers > 0 {
+		opener, err = o.storage.StorageClient(context.Background())
+		if err != nil {
+			if o.blobStorageWorkers+o.k8sBlobStorageWorkers+o.resultStoreWorkers > 0 {
+				logrus.WithError(err).Fatal("Error creating opener")
+			}
+			logrus.WithError(err).Warn("Error creating opener, check runs will not include build logs")
+			opener = nil
+		}
+	}
+
 	if o.githubWorkers > 0 {
 		if o.github.TokenPath != "" {
 			if err := secret.Add(o.github.TokenPath); err != nil {
@@ -268,25 +880,25 @@ func main() {
 			logrus.WithError(err).Fatal("Error getting GitHub client.")
 		}
 
-		hasReporter = true
-		githubReporter := githubreporter.NewReporter(githubClient, cfg, prowapi.ProwJobAgent(o.reportAgent), mgr.GetCache())
-		if err := crier.New(mgr, githubReporter, o.githubWorkers, o.githubEnablement.EnablementChecker()); err != nil {
-			logrus.WithError(err).Fatal("failed to construct github reporter controller")
+		var statusClient githubreporter.GitHubClient = githubClient
+		if o.githubStatusDedupTTL > 0 {
+			statusClient = githubreporter.NewStatusDeduplicator(githubClient, o.githubStatusDedupTTL)
 		}
-	}
 
-	var opener io.Opener
-	if o.blobStorageWorkers+o.k8sBlobStorageWorkers+o.resultStoreWorkers > 0 {
-		opener, err = o.storage.StorageClient(context.Background())
-		if err != nil {
-			logrus.WithError(err).Fatal("Error creating opener")
+		hasReporter = true
+		var githubReporter crier.ReportClient = githubreporter.NewReporter(statusClient, cfg, prowapi.ProwJobAgent(o.reportAgent), mgr.GetCache(), opener)
+		if o.githubReportQPS > 0 {
+			githubReporter = crier.NewRateLimitedReporter(githubReporter, o.githubReportQPS, o.githubReportBurst)
+		}
+		if err := newController(mgr, githubReporter, o.githubWorkers, enablementChecker, crierOpts...); err != nil {
+			logrus.WithError(err).Fatal("failed to construct github reporter controller")
 		}
 	}
 
 	if o.blobStorageWorkers > 0 || o.k8sBlobStorageWorkers > 0 {
 		hasReporter = true
 		if o.blobStorageWorkers > 0 {
-			if err := crier.New(mgr, gcsreporter.New(cfg, opener, o.dryrun), o.blobStorageWorkers, o.githubEnablement.EnablementChecker()); err != nil {
+			if err := newController(mgr, gcsreporter.New(cfg, opener, o.dryrun, o.gcsUploadRetries), o.blobStorageWorkers, enablementChecker, crierOpts...); err != nil {
 				logrus.WithError(err).Fatal("failed to construct gcsreporter controller")
 			}
 		}
@@ -297,8 +909,11 @@ func main() {
 				logrus.WithError(err).Fatal("Error building pod client sets for Kubernetes GCS workers")
 			}
 
-			k8sGcsReporter := k8sgcsreporter.New(cfg, opener, k8sgcsreporter.NewK8sResourceGetter(coreClients), float32(o.k8sReportFraction), o.dryrun)
-			if err := crier.New(mgr, k8sGcsReporter, o.k8sBlobStorageWorkers, o.githubEnablement.EnablementChecker()); err != nil {
+			k8sGcsReporter := k8sgcsreporter.New(cfg, opener, k8sgcsreporter.NewK8sResourceGetter(coreClients), float32(o.k8sReportFraction), k8sgcsreporter.UploadOptions{
+				Concurrency:   o.k8sUploadConcurrency,
+				ContainerLogs: o.k8sUploadContainerLogs,
+			}, o.dryrun)
+			if err := newController(mgr, k8sGcsReporter, o.k8sBlobStorageWorkers, enablementChecker, crierOpts...); err != nil {
 				logrus.WithError(err).Fatal("failed to construct k8sgcsreporter controller")
 			}
 		}
@@ -306,12 +921,12 @@ func main() {
 
 	if o.resultStoreWorkers > 0 {
 		hasReporter = true
-		conn, err := resultstore.Connect(context.Background())
+		conn, err := resultstore.Connect(context.Background(), o.resultstoreConnect)
 		if err != nil {
 			logrus.WithError(err).Fatal("Error connecting to resultstore")
 		}
 		uploader := resultstore.NewUploader(resultstore.NewClient(conn))
-		if err := crier.New(mgr, resultstorereporter.New(cfg, opener, uploader, o.resultstoreArtifactsDirOnly), o.resultStoreWorkers, o.githubEnablement.EnablementChecker()); err != nil {
+		if err := newController(mgr, resultstorereporter.New(cfg, opener, uploader, o.resultstoreArtifactsDirOnly, o.resultstoreUploadCoverage), o.resultStoreWorkers, enablementChecker, crierOpts...); err != nil {
 			logrus.WithError(err).Fatal("failed to construct resultstorereporter controller")
 		}
 	}
@@ -324,24 +939,386 @@ func main() {
 		dingTalkConfig := func(refs *prowapi.Refs) config.DingTalkReporter {
 			return cfg().DingTalkReporterConfigs.GetDingTalkReporter(refs)
 		}
-		dingTalkReporter := dingtalkreporter.New(dingTalkConfig, o.dryrun)
-		if err := crier.New(mgr, dingTalkReporter, o.dingTalkWorkers, o.githubEnablement.EnablementChecker()); err != nil {
+		for _, reporterConfig := range cfg().DingTalkReporterConfigs {
+			if reporterConfig.SecretFile == "" {
+				continue
+			}
+			if err := secret.Add(reporterConfig.SecretFile); err != nil {
+				logrus.WithError(err).Fatal("could not read DingTalk secret file")
+			}
+		}
+		dingTalkConfigKeys := func() []string {
+			return sets.List(sets.KeySet(cfg().DingTalkReporterConfigs))
+		}
+		dingTalkReporter := dingtalkreporter.New(dingTalkConfig, dingTalkConfigKeys, secret.GetSecret, o.dryrun, reportClient)
+		if err := newController(mgr, dingTalkReporter, o.dingTalkWorkers, enablementChecker, notificationOpts...); err != nil {
 			logrus.WithError(err).Fatal("failed to construct slack reporter controller")
 		}
 	}
 
+	if o.teamsWorkers > 0 {
+		hasReporter = true
+		if cfg().TeamsReporterConfigs == nil {
+			logrus.Fatal("teamsreporter is enabled but has no config")
+		}
+		teamsConfig := func(refs *prowapi.Refs) config.TeamsReporter {
+			return cfg().TeamsReporterConfigs.GetTeamsReporter(refs)
+		}
+		if err := secret.Add(o.teamsWebhookFile); err != nil {
+			logrus.WithError(err).Fatal("could not read teams webhook file")
+		}
+		teamsReporter := teamsreporter.New(teamsConfig, o.dryrun, secret.GetTokenGenerator(o.teamsWebhookFile), reportClient)
+		if err := newController(mgr, teamsReporter, o.teamsWorkers, enablementChecker, notificationOpts...); err != nil {
+			logrus.WithError(err).Fatal("failed to construct teams reporter controller")
+		}
+	}
+
+	if o.discordWorkers > 0 {
+		hasReporter = true
+		if cfg().DiscordReporterConfigs == nil {
+			logrus.Fatal("discordreporter is enabled but has no config")
+		}
+		discordConfig := func(refs *prowapi.Refs) config.DiscordReporter {
+			return cfg().DiscordReporterConfigs.GetDiscordReporter(refs)
+		}
+		if err := secret.Add(o.discordWebhookFile); err != nil {
+			logrus.WithError(err).Fatal("could not read discord webhook file")
+		}
+		discordReporter := discordreporter.New(discordConfig, o.dryrun, secret.GetTokenGenerator(o.discordWebhookFile), reportClient)
+		if err := newController(mgr, discordReporter, o.discordWorkers, enablementChecker, notificationOpts...); err != nil {
+			logrus.WithError(err).Fatal("failed to construct discord reporter controller")
+		}
+	}
+
+	if o.webhookWorkers > 0 {
+		hasReporter = true
+		if cfg().WebhookReporterConfigs == nil {
+			logrus.Fatal("webhookreporter is enabled but has no config")
+		}
+		webhookConfig := func(refs *prowapi.Refs) config.WebhookReporter {
+			return cfg().WebhookReporterConfigs.GetWebhookReporter(refs)
+		}
+		var tokenGenerator func() []byte
+		if o.webhookTokenFile != "" {
+			if err := secret.Add(o.webhookTokenFile); err != nil {
+				logrus.WithError(err).Fatal("could not read webhook token file")
+			}
+			tokenGenerator = secret.GetTokenGenerator(o.webhookTokenFile)
+		}
+		webhookReporter := webhookreporter.New(webhookConfig, o.dryrun, tokenGenerator, reportClient)
+		if err := newController(mgr, webhookReporter, o.webhookWorkers, enablementChecker, crierOpts...); err != nil {
+			logrus.WithError(err).Fatal("failed to construct webhook reporter controller")
+		}
+	}
+
+	if o.emailWorkers > 0 {
+		hasReporter = true
+		if cfg().EmailReporterConfigs == nil {
+			logrus.Fatal("emailreporter is enabled but has no config")
+		}
+		emailConfig := func(refs *prowapi.Refs) config.EmailReporter {
+			return cfg().EmailReporterConfigs.GetEmailReporter(refs)
+		}
+		var credentialsGenerator func() []byte
+		if o.emailCredentialsFile != "" {
+			if err := secret.Add(o.emailCredentialsFile); err != nil {
+				logrus.WithError(err).Fatal("could not read email credentials file")
+			}
+			credentialsGenerator = secret.GetTokenGenerator(o.emailCredentialsFile)
+		}
+		serverOpts := emailclient.ServerOptions{
+			Host:        o.emailSMTPHost,
+			Port:        o.emailSMTPPort,
+			From:        o.emailFrom,
+			ImplicitTLS: o.emailSMTPImplicitTLS,
+		}
+		emailReporter := emailreporter.New(emailConfig, o.dryrun, serverOpts, credentialsGenerator)
+		if err := newController(mgr, emailReporter, o.emailWorkers, enablementChecker, notificationOpts...); err != nil {
+			logrus.WithError(err).Fatal("failed to construct email reporter controller")
+		}
+	}
+
+	if o.jiraWorkers > 0 {
+		hasReporter = true
+		if cfg().JiraReporterConfigs == nil {
+			logrus.Fatal("jirareporter is enabled but has no config")
+		}
+		jiraConfig := func(refs *prowapi.Refs) config.JiraReporter {
+			return cfg().JiraReporterConfigs.GetJiraReporter(refs)
+		}
+		jiraClient, err := o.jira.Client()
+		if err != nil {
+			logrus.WithError(err).Fatal("failed to create Jira client")
+		}
+		jiraReporter := jirareporter.New(jiraConfig, o.dryrun, jiraClient, mgr.GetClient())
+		if err := newController(mgr, jiraReporter, o.jiraWorkers, enablementChecker, crierOpts...); err != nil {
+			logrus.WithError(err).Fatal("failed to construct jira reporter controller")
+		}
+	}
+
+	if o.pagerDutyWorkers > 0 {
+		hasReporter = true
+		if cfg().PagerDutyReporterConfigs == nil {
+			logrus.Fatal("pagerdutyreporter is enabled but has no config")
+		}
+		pagerDutyConfig := func(refs *prowapi.Refs) config.PagerDutyReporter {
+			return cfg().PagerDutyReporterConfigs.GetPagerDutyReporter(refs)
+		}
+		pagerDutyReporter := pagerdutyreporter.New(pagerDutyConfig, o.dryrun, reportClient)
+		if err := newController(mgr, pagerDutyReporter, o.pagerDutyWorkers, enablementChecker, crierOpts...); err != nil {
+			logrus.WithError(err).Fatal("failed to construct pagerduty reporter controller")
+		}
+	}
+
+	if o.telegramWorkers > 0 {
+		hasReporter = true
+		if cfg().TelegramReporterConfigs == nil {
+			logrus.Fatal("telegramreporter is enabled but has no config")
+		}
+		telegramConfig := func(refs *prowapi.Refs) config.TelegramReporter {
+			return cfg().TelegramReporterConfigs.GetTelegramReporter(refs)
+		}
+		if err := secret.Add(o.telegramTokenFile); err != nil {
+			logrus.WithError(err).Fatal("could not read telegram token file")
+		}
+		telegramReporter := telegramreporter.New(telegramConfig, o.dryrun, secret.GetTokenGenerator(o.telegramTokenFile), reportClient)
+		if err := newController(mgr, telegramReporter, o.telegramWorkers, enablementChecker, notificationOpts...); err != nil {
+			logrus.WithError(err).Fatal("failed to construct telegram reporter controller")
+		}
+	}
+
+	if o.matrixWorkers > 0 {
+		hasReporter = true
+		if cfg().MatrixReporterConfigs == nil {
+			logrus.Fatal("matrixreporter is enabled but has no config")
+		}
+		matrixConfig := func(refs *prowapi.Refs) config.MatrixReporter {
+			return cfg().MatrixReporterConfigs.GetMatrixReporter(refs)
+		}
+		if err := secret.Add(o.matrixTokenFile); err != nil {
+			logrus.WithError(err).Fatal("could not read matrix token file")
+		}
+		matrixReporter := matrixreporter.New(matrixConfig, o.dryrun, secret.GetTokenGenerator(o.matrixTokenFile), reportClient)
+		if err := newController(mgr, matrixReporter, o.matrixWorkers, enablementChecker, notificationOpts...); err != nil {
+			logrus.WithError(err).Fatal("failed to construct matrix reporter controller")
+		}
+	}
+
+	if o.snsWorkers > 0 {
+		hasReporter = true
+		if cfg().SNSReporterConfigs == nil {
+			logrus.Fatal("snsreporter is enabled but has no config")
+		}
+		snsConfig := func(refs *prowapi.Refs) config.SNSReporter {
+			return cfg().SNSReporterConfigs.GetSNSReporter(refs)
+		}
+		snsReporter := snsreporter.New(snsConfig, cfg, o.dryrun)
+		if err := newController(mgr, snsReporter, o.snsWorkers, enablementChecker, crierOpts...); err != nil {
+			logrus.WithError(err).Fatal("failed to construct sns reporter controller")
+		}
+	}
+
+	if o.googleChatWorkers > 0 {
+		hasReporter = true
+		if cfg().GoogleChatReporterConfigs == nil {
+			logrus.Fatal("googlechatreporter is enabled but has no config")
+		}
+		googleChatConfig := func(refs *prowapi.Refs) config.GoogleChatReporter {
+			return cfg().GoogleChatReporterConfigs.GetGoogleChatReporter(refs)
+		}
+		if err := secret.Add(o.googleChatWebhookFile); err != nil {
+			logrus.WithError(err).Fatal("could not read googlechat webhook file")
+		}
+		googleChatReporter := googlechatreporter.New(googleChatConfig, o.dryrun, secret.GetTokenGenerator(o.googleChatWebhookFile), reportClient)
+		if err := newController(mgr, googleChatReporter, o.googleChatWorkers, enablementChecker, notificationOpts...); err != nil {
+			logrus.WithError(err).Fatal("failed to construct googlechat reporter controller")
+		}
+	}
+
+	if o.pushgatewayWorkers > 0 {
+		hasReporter = true
+		pushgatewayReporter := pushgatewayreporter.New(o.pushgatewayURL, o.dryrun, reportClient)
+		if err := newController(mgr, pushgatewayReporter, o.pushgatewayWorkers, enablementChecker, crierOpts...); err != nil {
+			logrus.WithError(err).Fatal("failed to construct pushgateway reporter controller")
+		}
+	}
+
+	if o.sqlWorkers > 0 {
+		hasReporter = true
+		if err := secret.Add(o.sqlDSNFile); err != nil {
+			logrus.WithError(err).Fatal("could not read sql dsn file")
+		}
+		db, err := sqldb.Open(o.sqlDriver, strings.TrimSpace(string(secret.GetSecret(o.sqlDSNFile))))
+		if err != nil {
+			logrus.WithError(err).Fatal("failed to open sql database")
+		}
+		db.SetMaxOpenConns(cfg().Crier.SQLReporter.MaxOpenConns())
+		sqlConfig := func() *config.SQLReporter {
+			return cfg().Crier.SQLReporter
+		}
+		sqlReporter, err := sqlreporter.New(db, o.sqlDriver, sqlConfig, o.dryrun)
+		if err != nil {
+			logrus.WithError(err).Fatal("failed to construct sql reporter")
+		}
+		if err := newController(mgr, sqlReporter, o.sqlWorkers, enablementChecker, crierOpts...); err != nil {
+			logrus.WithError(err).Fatal("failed to construct sql reporter controller")
+		}
+	}
+
+	if o.kafkaWorkers > 0 {
+		hasReporter = true
+		if cfg().KafkaReporterConfigs == nil {
+			logrus.Fatal("kafkareporter is enabled but has no config")
+		}
+		kafkaConfig := func(refs *prowapi.Refs) config.KafkaReporter {
+			return cfg().KafkaReporterConfigs.GetKafkaReporter(refs)
+		}
+		var credentialsGenerator func() []byte
+		if o.kafkaCredentialsFile != "" {
+			if err := secret.Add(o.kafkaCredentialsFile); err != nil {
+				logrus.WithError(err).Fatal("could not read kafka credentials file")
+			}
+			credentialsGenerator = secret.GetTokenGenerator(o.kafkaCredentialsFile)
+		}
+		var caPEM []byte
+		if o.kafkaCAFile != "" {
+			if caPEM, err = os.ReadFile(o.kafkaCAFile); err != nil {
+				logrus.WithError(err).Fatal("could not read kafka CA file")
+			}
+		}
+		kafkaReporter := kafkareporter.New(kafkaConfig, cfg, o.dryrun, credentialsGenerator, caPEM, kafkareporter.DefaultMaxProduceAttempts)
+		if err := newController(mgr, kafkaReporter, o.kafkaWorkers, enablementChecker, crierOpts...); err != nil {
+			logrus.WithError(err).Fatal("failed to construct kafka reporter controller")
+		}
+	}
+
+	if o.datadogWorkers > 0 {
+		hasReporter = true
+		if cfg().DatadogReporterConfigs == nil {
+			logrus.Fatal("datadogreporter is enabled but has no config")
+		}
+		datadogConfig := func(refs *prowapi.Refs) config.DatadogReporter {
+			return cfg().DatadogReporterConfigs.GetDatadogReporter(refs)
+		}
+		if err := secret.Add(o.datadogAPIKeyFile); err != nil {
+			logrus.WithError(err).Fatal("could not read datadog API key file")
+		}
+		datadogReporter := datadogreporter.New(datadogConfig, o.dryrun, secret.GetTokenGenerator(o.datadogAPIKeyFile), reportClient)
+		if err := newController(mgr, datadogReporter, o.datadogWorkers, enablementChecker, crierOpts...); err != nil {
+			logrus.WithError(err).Fatal("failed to construct datadog reporter controller")
+		}
+	}
+
+	if o.redisWorkers > 0 {
+		hasReporter = true
+		if cfg().RedisReporterConfigs == nil {
+			logrus.Fatal("redisreporter is enabled but has no config")
+		}
+		redisConfig := func(refs *prowapi.Refs) config.RedisReporter {
+			return cfg().RedisReporterConfigs.GetRedisReporter(refs)
+		}
+		var credentialsGenerator func() []byte
+		if o.redisCredentialsFile != "" {
+			if err := secret.Add(o.redisCredentialsFile); err != nil {
+				logrus.WithError(err).Fatal("could not read redis credentials file")
+			}
+			credentialsGenerator = secret.GetTokenGenerator(o.redisCredentialsFile)
+		}
+		redisReporter := redisreporter.New(redisConfig, o.dryrun, credentialsGenerator)
+		if err := newController(mgr, redisReporter, o.redisWorkers, enablementChecker, crierOpts...); err != nil {
+			logrus.WithError(err).Fatal("failed to construct redis reporter controller")
+		}
+	}
+
+	if o.bitbucketWorkers > 0 {
+		hasReporter = true
+		if cfg().BitbucketReporterConfigs == nil {
+			logrus.Fatal("bitbucketreporter is enabled but has no config")
+		}
+		bitbucketConfig := func(refs *prowapi.Refs) config.BitbucketReporter {
+			return cfg().BitbucketReporterConfigs.GetBitbucketReporter(refs)
+		}
+		if err := secret.Add(o.bitbucketTokenFile); err != nil {
+			logrus.WithError(err).Fatal("could not read bitbucket token file")
+		}
+		bitbucketReporter := bitbucketreporter.New(bitbucketConfig, o.dryrun, secret.GetTokenGenerator(o.bitbucketTokenFile), reportClient)
+		if err := newController(mgr, bitbucketReporter, o.bitbucketWorkers, enablementChecker, crierOpts...); err != nil {
+			logrus.WithError(err).Fatal("failed to construct bitbucket reporter controller")
+		}
+	}
+
+	if o.gitlabWorkers > 0 {
+		hasReporter = true
+		if cfg().GitLabReporterConfigs == nil {
+			logrus.Fatal("gitlabreporter is enabled but has no config")
+		}
+		gitlabConfig := func(refs *prowapi.Refs) config.GitLabReporter {
+			return cfg().GitLabReporterConfigs.GetGitLabReporter(refs)
+		}
+		if err := secret.Add(o.gitlabTokenFile); err != nil {
+			logrus.WithError(err).Fatal("could not read gitlab token file")
+		}
+		gitlabReporter := gitlabreporter.New(gitlabConfig, o.dryrun, secret.GetTokenGenerator(o.gitlabTokenFile), reportClient)
+		if err := newController(mgr, gitlabReporter, o.gitlabWorkers, enablementChecker, crierOpts...); err != nil {
+			logrus.WithError(err).Fatal("failed to construct gitlab reporter controller")
+		}
+	}
+
+	if o.reporterPluginDir != "" {
+		pluginReporters, err := reporterplugin.Load(o.reporterPluginDir, reporterplugin.Options{
+			Config: cfg,
+			Client: mgr.GetClient(),
+			DryRun: o.dryrun,
+		})
+		if err != nil {
+			logrus.WithError(err).Fatal("failed to load reporter plugins")
+		}
+		for _, reporter := range pluginReporters {
+			// Plugins have no flags, so their workers must be configured.
+			workers := cfg().Crier.Workers[reporter.GetName()]
+			if workers <= 0 {
+				logrus.WithField("reporter", reporter.GetName()).Fatal("reporter plugin has no workers set in crier.workers")
+			}
+			hasReporter = true
+			if err := newController(mgr, reporter, workers, enablementChecker, crierOpts...); err != nil {
+				logrus.WithError(err).WithField("reporter", reporter.GetName()).Fatal("failed to construct reporter plugin controller")
+			}
+		}
+	}
+
 	if !hasReporter {
 		logrus.Fatalf("should have at least one controller to start crier.")
 	}
 
+	if o.replayFrom != "" {
+		if err := replayJobs(o, mgr.GetAPIReader(), collectedReporters, enablementChecker); err != nil {
+			logrus.WithError(err).Fatal("Failed to replay jobs")
+		}
+		return
+	}
+
+	if o.validateConfigAndExit {
+		if err := crier.ValidateReporters(context.Background(), os.Stdout, collectedReporters); err != nil {
+			logrus.WithError(err).Fatal("Reporter config is invalid")
+		}
+		return
+	}
+
+	if err := readiness.Validate(); err != nil {
+		logrus.WithError(err).Fatal("Invalid --readiness-critical-reporters")
+	}
+
 	// Push metrics to the configured prometheus pushgateway endpoint or serve them
 	metrics.ExposeMetrics("crier", cfg().PushGateway, o.instrumentationOptions.MetricsPort)
 
+	health := pjutil.NewHealthOnPort(o.instrumentationOptions.HealthPort)
+	health.ServeReady(readiness.Ready)
+
 	interrupts.Run(func(ctx context.Context) {
 		if err := mgr.Start(ctx); err != nil {
 			logrus.WithError(err).Fatal("Controller manager exited with error.")
 		}
 	})
-	interrupts.WaitForGracefulShutdown()
+	interrupts.WaitForGracefulShutdownWithTimeout(max(time.Minute, shutdownTimeout+10*time.Second))
 	logrus.Info("Ended gracefully")
 }
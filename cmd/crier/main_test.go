@@ -17,14 +17,21 @@ limitations under the License.
 package main
 
 import (
+	"encoding/pem"
 	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
 	"sigs.k8s.io/prow/pkg/flagutil"
 	configflagutil "sigs.k8s.io/prow/pkg/flagutil/config"
+	"sigs.k8s.io/prow/pkg/resultstore"
 )
 
 func TestOptions(t *testing.T) {
@@ -60,16 +67,1202 @@ func TestOptions(t *testing.T) {
 					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
 					InRepoConfigCacheSize:                 200,
 				},
-				github:                 defaultGitHubOptions,
-				k8sReportFraction:      1.0,
-				instrumentationOptions: flagutil.DefaultInstrumentationOptions(),
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		{
+			name: "gerrit missing --cookiefile",
+			args: []string{"--gerrit-workers=5", "--config-path=foo"},
+			expected: &options{
+				gerritWorkers: 5,
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		//PubSub Reporter
+		{
+			name: "pubsub workers, sets workers",
+			args: []string{"--pubsub-workers=7", "--config-path=baz"},
+			expected: &options{
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "baz",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				pubsubWorkers:            7,
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		{
+			name: "pubsub workers set to negative, rejects",
+			args: []string{"--pubsub-workers=-3", "--config-path=foo"},
+		},
+		{
+			name: "pubsub with zero max publish attempts, rejects",
+			args: []string{"--pubsub-workers=1", "--pubsub-max-publish-attempts=0", "--config-path=foo"},
+		},
+		//Slack Reporter
+		{
+			name: "slack workers, sets workers",
+			args: []string{"--slack-workers=13", "--slack-token-file=/bar/baz", "--config-path=foo"},
+			expected: &options{
+				slackWorkers:   13,
+				slackTokenFile: "/bar/baz",
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		{
+			name: "slack missing --slack-token, rejects",
+			args: []string{"--slack-workers=1", "--config-path=foo"},
+		},
+		{
+			name: "slack token from secret, sets",
+			args: []string{"--slack-workers=13", "--slack-token-secret=prow/slack/token", "--config-path=foo"},
+			expected: &options{
+				slackWorkers:     13,
+				slackTokenSecret: "prow/slack/token",
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		{
+			name: "slack token secret without key, rejects",
+			args: []string{"--slack-workers=1", "--slack-token-secret=prow/slack", "--config-path=foo"},
+		},
+		{
+			name: "slack token file and secret, rejects",
+			args: []string{"--slack-workers=1", "--slack-token-file=/bar/baz", "--slack-token-secret=prow/slack/token", "--config-path=foo"},
+		},
+		{
+			name: "slack with --dry-run, sets",
+			args: []string{"--slack-workers=13", "--slack-token-file=/bar/baz", "--config-path=foo", "--dry-run"},
+			expected: &options{
+				slackWorkers:   13,
+				slackTokenFile: "/bar/baz",
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				dryrun:                   true,
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		//DingTalk Reporter
+		{
+			name: "dingTalk workers, sets workers",
+			args: []string{"--dingtalk-workers=13", "--config-path=foo"},
+			expected: &options{
+				dingTalkWorkers: 13,
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		{
+			name: "dingTalk with --dry-run, sets",
+			args: []string{"--dingtalk-workers=13", "--config-path=foo", "--dry-run"},
+			expected: &options{
+				dingTalkWorkers: 13,
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				dryrun:                   true,
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		//Teams Reporter
+		{
+			name: "teams workers, sets workers",
+			args: []string{"--teams-workers=3", "--teams-webhook-file=/etc/teams/webhooks.yaml", "--config-path=foo"},
+			expected: &options{
+				teamsWorkers:     3,
+				teamsWebhookFile: "/etc/teams/webhooks.yaml",
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		{
+			name: "teams missing --teams-webhook-file, rejects",
+			args: []string{"--teams-workers=3", "--config-path=foo"},
+		},
+		//Discord Reporter
+		{
+			name: "discord workers, sets workers",
+			args: []string{"--discord-workers=3", "--discord-webhook-file=/etc/discord/webhooks.yaml", "--config-path=foo"},
+			expected: &options{
+				discordWorkers:     3,
+				discordWebhookFile: "/etc/discord/webhooks.yaml",
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		{
+			name: "discord missing --discord-webhook-file, rejects",
+			args: []string{"--discord-workers=3", "--config-path=foo"},
+		},
+		//Webhook Reporter
+		{
+			name: "webhook workers, sets workers and token file",
+			args: []string{"--webhook-workers=2", "--webhook-token-file=/etc/webhook/token", "--config-path=foo"},
+			expected: &options{
+				webhookWorkers:   2,
+				webhookTokenFile: "/etc/webhook/token",
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		//Email Reporter
+		{
+			name: "email workers, sets workers and server",
+			args: []string{"--email-workers=2", "--email-smtp-host=smtp.example.com", "--email-smtp-port=465", "--email-smtp-implicit-tls", "--email-from=prow@example.com", "--email-credentials-file=/etc/email/credentials", "--config-path=foo"},
+			expected: &options{
+				emailWorkers:           2,
+				emailSMTPHost:          "smtp.example.com",
+				emailSMTPPort:          465,
+				k8sUploadConcurrency:   4,
+				exitOnKubeconfigChange: true,
+				gcsUploadRetries:       3,
+				slackChannelCacheTTL:   time.Hour,
+				replayLimit:            50,
+				emailSMTPImplicitTLS:   true,
+				emailFrom:              "prow@example.com",
+				emailCredentialsFile:   "/etc/email/credentials",
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+			},
+		},
+		{
+			name: "email missing --email-from, rejects",
+			args: []string{"--email-workers=2", "--email-smtp-host=smtp.example.com", "--config-path=foo"},
+		},
+		//Jira Reporter
+		{
+			name: "jira workers with invalid jira options, rejects",
+			args: []string{"--jira-workers=1", "--jira-endpoint=https://jira.example.com", "--jira-username=prow", "--config-path=foo"},
+		},
+		//PagerDuty Reporter
+		{
+			name: "pagerduty workers, sets workers",
+			args: []string{"--pagerduty-workers=1", "--config-path=foo"},
+			expected: &options{
+				pagerDutyWorkers: 1,
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		//Telegram Reporter
+		{
+			name: "telegram workers, sets workers",
+			args: []string{"--telegram-workers=2", "--telegram-token-file=/etc/telegram/token", "--config-path=foo"},
+			expected: &options{
+				telegramWorkers:   2,
+				telegramTokenFile: "/etc/telegram/token",
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		{
+			name: "telegram missing --telegram-token-file, rejects",
+			args: []string{"--telegram-workers=2", "--config-path=foo"},
+		},
+		{
+			name: "pubsub at most once, sets option",
+			args: []string{"--pubsub-workers=1", "--pubsub-at-most-once", "--config-path=foo"},
+			expected: &options{
+				pubsubWorkers:    1,
+				pubsubAtMostOnce: true,
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		//Matrix Reporter
+		{
+			name: "matrix workers, sets workers",
+			args: []string{"--matrix-workers=2", "--matrix-token-file=/etc/matrix/token", "--config-path=foo"},
+			expected: &options{
+				matrixWorkers:   2,
+				matrixTokenFile: "/etc/matrix/token",
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		{
+			name: "matrix missing --matrix-token-file, rejects",
+			args: []string{"--matrix-workers=2", "--config-path=foo"},
+		},
+		//Bitbucket Reporter
+		{
+			name: "bitbucket workers, sets workers",
+			args: []string{"--bitbucket-workers=2", "--bitbucket-token-file=/etc/bitbucket/token", "--config-path=foo"},
+			expected: &options{
+				bitbucketWorkers:   2,
+				bitbucketTokenFile: "/etc/bitbucket/token",
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		{
+			name: "bitbucket missing --bitbucket-token-file, rejects",
+			args: []string{"--bitbucket-workers=2", "--config-path=foo"},
+		},
+		//Replay
+		{
+			name: "replay from storage, sets path",
+			args: []string{"--slack-workers=1", "--slack-token-file=/bar/baz", "--replay-from=gs://bucket/logs/my-job", "--replay-limit=10", "--dry-run", "--config-path=foo"},
+			expected: &options{
+				slackWorkers:   1,
+				slackTokenFile: "/bar/baz",
+				replayFrom:     "gs://bucket/logs/my-job",
+				replayLimit:    10,
+				dryrun:         true,
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+			},
+		},
+		{
+			name: "replay without --dry-run, rejects",
+			args: []string{"--slack-workers=1", "--slack-token-file=/bar/baz", "--replay-from=prow-jobs", "--config-path=foo"},
+		},
+		{
+			name: "replay with pubsub reporter, rejects",
+			args: []string{"--pubsub-workers=1", "--replay-from=prow-jobs", "--dry-run", "--config-path=foo"},
+		},
+		{
+			name: "replay with zero limit, rejects",
+			args: []string{"--slack-workers=1", "--slack-token-file=/bar/baz", "--replay-from=prow-jobs", "--replay-limit=0", "--dry-run", "--config-path=foo"},
+		},
+		{
+			name: "slack channel cache ttl, sets ttl",
+			args: []string{"--slack-workers=1", "--slack-token-file=/bar/baz", "--slack-channel-cache-ttl=10m", "--config-path=foo"},
+			expected: &options{
+				slackWorkers:         1,
+				slackTokenFile:       "/bar/baz",
+				slackChannelCacheTTL: 10 * time.Minute,
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				replayLimit:              50,
+			},
+		},
+		{
+			name: "zero slack channel cache ttl, rejects",
+			args: []string{"--slack-workers=1", "--slack-token-file=/bar/baz", "--slack-channel-cache-ttl=0", "--config-path=foo"},
+		},
+		{
+			name: "replay with config validation, rejects",
+			args: []string{"--slack-workers=1", "--slack-token-file=/bar/baz", "--replay-from=prow-jobs", "--dry-run", "--validate-config-and-exit", "--config-path=foo"},
+		},
+		//Config validation
+		{
+			name: "validate config and exit, sets validation",
+			args: []string{"--slack-workers=1", "--slack-token-file=/bar/baz", "--validate-config-and-exit", "--config-path=foo"},
+			expected: &options{
+				slackWorkers:          1,
+				slackTokenFile:        "/bar/baz",
+				validateConfigAndExit: true,
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		//GitLab Reporter
+		{
+			name: "gitlab workers, sets workers",
+			args: []string{"--gitlab-workers=2", "--gitlab-token-file=/etc/gitlab/token", "--config-path=foo"},
+			expected: &options{
+				gitlabWorkers:   2,
+				gitlabTokenFile: "/etc/gitlab/token",
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		{
+			name: "gitlab missing --gitlab-token-file, rejects",
+			args: []string{"--gitlab-workers=2", "--config-path=foo"},
+		},
+		//SNS Reporter
+		{
+			name: "sns workers, sets workers",
+			args: []string{"--sns-workers=2", "--config-path=foo"},
+			expected: &options{
+				snsWorkers: 2,
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		//Google Chat Reporter
+		{
+			name: "googlechat workers, sets workers",
+			args: []string{"--googlechat-workers=2", "--googlechat-webhook-file=/etc/googlechat/webhooks", "--config-path=foo"},
+			expected: &options{
+				googleChatWorkers:     2,
+				googleChatWebhookFile: "/etc/googlechat/webhooks",
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		{
+			name: "googlechat missing --googlechat-webhook-file, rejects",
+			args: []string{"--googlechat-workers=2", "--config-path=foo"},
+		},
+		//Pushgateway Reporter
+		{
+			name: "pushgateway workers, sets workers",
+			args: []string{"--pushgateway-reporter-workers=2", "--pushgateway-reporter-url=http://pushgateway:9091", "--config-path=foo"},
+			expected: &options{
+				pushgatewayWorkers: 2,
+				pushgatewayURL:     "http://pushgateway:9091",
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		{
+			name: "pushgateway missing --pushgateway-reporter-url, rejects",
+			args: []string{"--pushgateway-reporter-workers=2", "--config-path=foo"},
+		},
+		{
+			name: "pushgateway invalid --pushgateway-reporter-url, rejects",
+			args: []string{"--pushgateway-reporter-workers=2", "--pushgateway-reporter-url=pushgateway", "--config-path=foo"},
+		},
+		//SQL Reporter
+		{
+			name: "sql workers, sets workers",
+			args: []string{"--sql-workers=2", "--sql-driver=postgres", "--sql-dsn-file=/etc/sql/dsn", "--config-path=foo"},
+			expected: &options{
+				sqlWorkers: 2,
+				sqlDriver:  "postgres",
+				sqlDSNFile: "/etc/sql/dsn",
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		{
+			name: "sql missing --sql-dsn-file, rejects",
+			args: []string{"--sql-workers=2", "--sql-driver=postgres", "--config-path=foo"},
+		},
+		{
+			name: "sql unsupported --sql-driver, rejects",
+			args: []string{"--sql-workers=2", "--sql-driver=sqlite3", "--sql-dsn-file=/etc/sql/dsn", "--config-path=foo"},
+		},
+		//Kafka Reporter
+		{
+			name: "kafka workers, sets workers",
+			args: []string{"--kafka-workers=2", "--kafka-credentials-file=/etc/kafka/credentials", "--kafka-ca-file=/etc/kafka/ca.pem", "--config-path=foo"},
+			expected: &options{
+				kafkaWorkers:         2,
+				kafkaCredentialsFile: "/etc/kafka/credentials",
+				kafkaCAFile:          "/etc/kafka/ca.pem",
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		//Datadog Reporter
+		{
+			name: "datadog workers, sets workers",
+			args: []string{"--datadog-workers=2", "--datadog-api-key-file=/etc/datadog/api-key", "--config-path=foo"},
+			expected: &options{
+				datadogWorkers:    2,
+				datadogAPIKeyFile: "/etc/datadog/api-key",
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		{
+			name: "datadog missing --datadog-api-key-file, rejects",
+			args: []string{"--datadog-workers=2", "--config-path=foo"},
+		},
+		//Redis Reporter
+		{
+			name: "redis workers, sets workers",
+			args: []string{"--redis-workers=2", "--redis-credentials-file=/etc/redis/credentials", "--config-path=foo"},
+			expected: &options{
+				redisWorkers:         2,
+				redisCredentialsFile: "/etc/redis/credentials",
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		//Kubeconfig changes
+		{
+			name: "exit on kubeconfig change disabled, sets it",
+			args: []string{"--pubsub-workers=1", "--exit-on-kubeconfig-change=false", "--config-path=foo"},
+			expected: &options{
+				pubsubWorkers: 1,
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		//Report CA file
+		{
+			name: "missing --report-ca-file, rejects",
+			args: []string{"--pubsub-workers=1", "--report-ca-file=/nonexistent/ca.pem", "--config-path=foo"},
+		},
+		//Reporter plugins
+		{
+			name: "reporter plugin dir without other reporters, sets dir",
+			args: []string{"--reporter-plugin-dir=/etc/crier/plugins", "--config-path=foo"},
+			expected: &options{
+				reporterPluginDir: "/etc/crier/plugins",
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		//Drain timeout
+		{
+			name: "drain timeout, sets drain timeout",
+			args: []string{"--pubsub-workers=1", "--drain-timeout=2m", "--config-path=foo"},
+			expected: &options{
+				pubsubWorkers:   1,
+				drainTimeout:    2 * time.Minute,
+				reportRetryBase: time.Second,
+				reportRetryMax:  5 * time.Minute,
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		{
+			name: "negative drain timeout, rejects",
+			args: []string{"--pubsub-workers=1", "--drain-timeout=-1s", "--config-path=foo"},
+		},
+		//Report timeout
+		{
+			name: "report timeout, sets report timeout",
+			args: []string{"--pubsub-workers=1", "--report-timeout=1m", "--config-path=foo"},
+			expected: &options{
+				pubsubWorkers: 1,
+				reportTimeout: time.Minute,
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		{
+			name: "negative report timeout, rejects",
+			args: []string{"--pubsub-workers=1", "--report-timeout=-1s", "--config-path=foo"},
+		},
+		//ProwJob namespaces
+		{
+			name: "prowjob namespaces, sets namespaces",
+			args: []string{"--pubsub-workers=1", "--prowjob-namespaces=a", "--prowjob-namespaces=b", "--config-path=foo"},
+			expected: &options{
+				pubsubWorkers:     1,
+				prowjobNamespaces: flagutil.NewStringsBeenSet("a", "b"),
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		{
+			name: "empty prowjob namespace, rejects",
+			args: []string{"--pubsub-workers=1", "--prowjob-namespaces=", "--config-path=foo"},
+		},
+		//Report proxy
+		{
+			name: "report proxy, sets proxy and no-proxy hosts",
+			args: []string{"--pubsub-workers=1", "--report-http-proxy=http://proxy:3128", "--report-no-proxy=internal.example.com", "--config-path=foo"},
+			expected: &options{
+				pubsubWorkers:   1,
+				reportHTTPProxy: "http://proxy:3128",
+				reportNoProxy:   "internal.example.com",
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		{
+			name: "report proxy without host, rejects",
+			args: []string{"--pubsub-workers=1", "--report-http-proxy=proxy", "--config-path=foo"},
+		},
+		//Tracing
+		{
+			name: "otel endpoint, sets endpoint",
+			args: []string{"--pubsub-workers=1", "--otel-endpoint=otel-collector:4317", "--config-path=foo"},
+			expected: &options{
+				pubsubWorkers: 1,
+				otelEndpoint:  "otel-collector:4317",
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
 			},
 		},
 		{
-			name: "gerrit missing --cookiefile",
-			args: []string{"--gerrit-workers=5", "--config-path=foo"},
+			name: "otel endpoint without port, rejects",
+			args: []string{"--pubsub-workers=1", "--otel-endpoint=otel-collector", "--config-path=foo"},
+		},
+		//Readiness
+		{
+			name: "readiness critical reporters, sets reporters",
+			args: []string{"--slack-workers=1", "--slack-token-file=/bar/baz", "--readiness-critical-reporters=slackreporter", "--config-path=foo"},
 			expected: &options{
-				gerritWorkers: 5,
+				slackWorkers:               1,
+				slackTokenFile:             "/bar/baz",
+				readinessCriticalReporters: flagutil.NewStringsBeenSet("slackreporter"),
 				config: configflagutil.ConfigOptions{
 					ConfigPathFlagName:                    "config-path",
 					JobConfigPathFlagName:                 "job-config-path",
@@ -77,40 +1270,94 @@ func TestOptions(t *testing.T) {
 					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
 					InRepoConfigCacheSize:                 200,
 				},
-				github:                 defaultGitHubOptions,
-				k8sReportFraction:      1.0,
-				instrumentationOptions: flagutil.DefaultInstrumentationOptions(),
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
 			},
 		},
-		//PubSub Reporter
+		//Skip reported jobs
 		{
-			name: "pubsub workers, sets workers",
-			args: []string{"--pubsub-workers=7", "--config-path=baz"},
+			name: "skip reported jobs, sets skipReportedJobs",
+			args: []string{"--slack-workers=1", "--slack-token-file=/bar/baz", "--skip-reported-jobs", "--config-path=foo"},
 			expected: &options{
+				slackWorkers:     1,
+				slackTokenFile:   "/bar/baz",
+				skipReportedJobs: true,
 				config: configflagutil.ConfigOptions{
 					ConfigPathFlagName:                    "config-path",
 					JobConfigPathFlagName:                 "job-config-path",
-					ConfigPath:                            "baz",
+					ConfigPath:                            "foo",
 					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
 					InRepoConfigCacheSize:                 200,
 				},
-				pubsubWorkers:          7,
-				github:                 defaultGitHubOptions,
-				k8sReportFraction:      1.0,
-				instrumentationOptions: flagutil.DefaultInstrumentationOptions(),
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
 			},
 		},
+		//Live report state check
 		{
-			name: "pubsub workers set to negative, rejects",
-			args: []string{"--pubsub-workers=-3", "--config-path=foo"},
+			name: "live report state check, sets liveReportStateCheck",
+			args: []string{"--slack-workers=1", "--slack-token-file=/bar/baz", "--live-report-state-check", "--config-path=foo"},
+			expected: &options{
+				slackWorkers:         1,
+				slackTokenFile:       "/bar/baz",
+				liveReportStateCheck: true,
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
 		},
-		//Slack Reporter
+		//ProwJob selector
 		{
-			name: "slack workers, sets workers",
-			args: []string{"--slack-workers=13", "--slack-token-file=/bar/baz", "--config-path=foo"},
+			name: "prowjob selector, sets selector",
+			args: []string{"--pubsub-workers=1", "--prowjob-selector=reporter!=pipeline", "--config-path=foo"},
 			expected: &options{
-				slackWorkers:   13,
-				slackTokenFile: "/bar/baz",
+				pubsubWorkers:   1,
+				prowjobSelector: "reporter!=pipeline",
 				config: configflagutil.ConfigOptions{
 					ConfigPathFlagName:                    "config-path",
 					JobConfigPathFlagName:                 "job-config-path",
@@ -118,21 +1365,35 @@ func TestOptions(t *testing.T) {
 					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
 					InRepoConfigCacheSize:                 200,
 				},
-				github:                 defaultGitHubOptions,
-				k8sReportFraction:      1.0,
-				instrumentationOptions: flagutil.DefaultInstrumentationOptions(),
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
 			},
 		},
 		{
-			name: "slack missing --slack-token, rejects",
-			args: []string{"--slack-workers=1", "--config-path=foo"},
+			name: "invalid prowjob selector, rejects",
+			args: []string{"--pubsub-workers=1", "--prowjob-selector=reporter in (", "--config-path=foo"},
 		},
+		//Report retry backoff
 		{
-			name: "slack with --dry-run, sets",
-			args: []string{"--slack-workers=13", "--slack-token-file=/bar/baz", "--config-path=foo", "--dry-run"},
+			name: "report retry backoff, sets base and max",
+			args: []string{"--pubsub-workers=1", "--report-retry-base=2s", "--report-retry-max=1h", "--config-path=foo"},
 			expected: &options{
-				slackWorkers:   13,
-				slackTokenFile: "/bar/baz",
+				pubsubWorkers:   1,
+				reportRetryBase: 2 * time.Second,
+				reportRetryMax:  time.Hour,
 				config: configflagutil.ConfigOptions{
 					ConfigPathFlagName:                    "config-path",
 					JobConfigPathFlagName:                 "job-config-path",
@@ -140,18 +1401,36 @@ func TestOptions(t *testing.T) {
 					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
 					InRepoConfigCacheSize:                 200,
 				},
-				dryrun:                 true,
-				github:                 defaultGitHubOptions,
-				k8sReportFraction:      1.0,
-				instrumentationOptions: flagutil.DefaultInstrumentationOptions(),
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
 			},
 		},
-		//DingTalk Reporter
 		{
-			name: "dingTalk workers, sets workers",
-			args: []string{"--dingtalk-workers=13", "--config-path=foo"},
+			name: "report retry max below base, rejects",
+			args: []string{"--pubsub-workers=1", "--report-retry-base=1m", "--report-retry-max=1s", "--config-path=foo"},
+		},
+		{
+			name: "zero report retry base, rejects",
+			args: []string{"--pubsub-workers=1", "--report-retry-base=0", "--config-path=foo"},
+		},
+		//Report audit log
+		{
+			name: "report audit log, enables audit log",
+			args: []string{"--pubsub-workers=1", "--report-audit-log", "--config-path=foo"},
 			expected: &options{
-				dingTalkWorkers: 13,
+				pubsubWorkers:  1,
+				reportAuditLog: true,
 				config: configflagutil.ConfigOptions{
 					ConfigPathFlagName:                    "config-path",
 					JobConfigPathFlagName:                 "job-config-path",
@@ -159,16 +1438,248 @@ func TestOptions(t *testing.T) {
 					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
 					InRepoConfigCacheSize:                 200,
 				},
-				github:                 defaultGitHubOptions,
-				k8sReportFraction:      1.0,
-				instrumentationOptions: flagutil.DefaultInstrumentationOptions(),
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
 			},
 		},
+		//Prioritize recent
 		{
-			name: "dingTalk with --dry-run, sets",
-			args: []string{"--dingtalk-workers=13", "--config-path=foo", "--dry-run"},
+			name: "prioritize recent, enables prioritization",
+			args: []string{"--pubsub-workers=1", "--prioritize-recent", "--config-path=foo"},
 			expected: &options{
-				dingTalkWorkers: 13,
+				pubsubWorkers:    1,
+				prioritizeRecent: true,
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		//GitHub rate limit
+		{
+			name: "github report rate limit, sets qps and burst",
+			args: []string{"--github-workers=1", "--github-report-qps=0.5", "--github-report-burst=5", "--config-path=foo"},
+			expected: &options{
+				githubWorkers:     1,
+				githubReportQPS:   0.5,
+				githubReportBurst: 5,
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		{
+			name: "github report rate limit with zero burst, rejects",
+			args: []string{"--github-workers=1", "--github-report-qps=0.5", "--github-report-burst=0", "--config-path=foo"},
+		},
+		//GitHub status deduplication
+		{
+			name: "github status dedup ttl, sets ttl",
+			args: []string{"--github-workers=1", "--github-status-dedup-ttl=5m", "--config-path=foo"},
+			expected: &options{
+				githubWorkers:        1,
+				githubStatusDedupTTL: 5 * time.Minute,
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		{
+			name: "negative github status dedup ttl, rejects",
+			args: []string{"--github-workers=1", "--github-status-dedup-ttl=-1m", "--config-path=foo"},
+		},
+		//Report throttle
+		{
+			name: "max reports per second, sets throttle",
+			args: []string{"--pubsub-workers=1", "--max-reports-per-second=2.5", "--config-path=foo"},
+			expected: &options{
+				pubsubWorkers:       1,
+				maxReportsPerSecond: 2.5,
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		{
+			name: "negative max reports per second, rejects",
+			args: []string{"--pubsub-workers=1", "--max-reports-per-second=-1", "--config-path=foo"},
+		},
+		//Max report attempts
+		{
+			name: "max report attempts, sets attempts",
+			args: []string{"--pubsub-workers=1", "--max-report-attempts=10", "--config-path=foo"},
+			expected: &options{
+				pubsubWorkers:     1,
+				maxReportAttempts: 10,
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		{
+			name: "negative max report attempts, rejects",
+			args: []string{"--pubsub-workers=1", "--max-report-attempts=-1", "--config-path=foo"},
+		},
+		//Dead-letter sink
+		{
+			name: "dead-letter url, sets url",
+			args: []string{"--pubsub-workers=1", "--dead-letter-url=https://dead-letter.example.com/crier", "--config-path=foo"},
+			expected: &options{
+				pubsubWorkers: 1,
+				deadLetterURL: "https://dead-letter.example.com/crier",
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		{
+			name: "dead-letter url without scheme, rejects",
+			args: []string{"--pubsub-workers=1", "--dead-letter-url=dead-letter.example.com", "--config-path=foo"},
+		},
+		//Circuit breaker
+		{
+			name: "circuit breaker, sets threshold and cool down",
+			args: []string{"--pubsub-workers=1", "--circuit-breaker-failure-threshold=5", "--circuit-breaker-cool-down=2m", "--config-path=foo"},
+			expected: &options{
+				pubsubWorkers:                  1,
+				circuitBreakerFailureThreshold: 5,
+				circuitBreakerCoolDown:         2 * time.Minute,
+				pubsubMaxPublishAttempts:       3,
+				githubReportBurst:              1,
+				drainTimeout:                   30 * time.Second,
+				reportRetryBase:                time.Second,
+				reportRetryMax:                 5 * time.Minute,
+				emailSMTPPort:                  587,
+				k8sUploadConcurrency:           4,
+				exitOnKubeconfigChange:         true,
+				gcsUploadRetries:               3,
+				slackChannelCacheTTL:           time.Hour,
+				replayLimit:                    50,
 				config: configflagutil.ConfigOptions{
 					ConfigPathFlagName:                    "config-path",
 					JobConfigPathFlagName:                 "job-config-path",
@@ -176,12 +1687,15 @@ func TestOptions(t *testing.T) {
 					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
 					InRepoConfigCacheSize:                 200,
 				},
-				dryrun:                 true,
 				github:                 defaultGitHubOptions,
 				k8sReportFraction:      1.0,
 				instrumentationOptions: flagutil.DefaultInstrumentationOptions(),
 			},
 		},
+		{
+			name: "circuit breaker with negative threshold, rejects",
+			args: []string{"--pubsub-workers=1", "--circuit-breaker-failure-threshold=-1", "--config-path=foo"},
+		},
 		{
 			name: "k8s-gcs enables k8s-gcs",
 			args: []string{"--kubernetes-blob-storage-workers=3", "--config-path=foo"},
@@ -194,9 +1708,21 @@ func TestOptions(t *testing.T) {
 					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
 					InRepoConfigCacheSize:                 200,
 				},
-				github:                 defaultGitHubOptions,
-				k8sReportFraction:      1.0,
-				instrumentationOptions: flagutil.DefaultInstrumentationOptions(),
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
 			},
 		},
 		{
@@ -211,9 +1737,21 @@ func TestOptions(t *testing.T) {
 					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
 					InRepoConfigCacheSize:                 200,
 				},
-				github:                 defaultGitHubOptions,
-				k8sReportFraction:      0.5,
-				instrumentationOptions: flagutil.DefaultInstrumentationOptions(),
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        0.5,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
 			},
 		},
 		{
@@ -224,6 +1762,73 @@ func TestOptions(t *testing.T) {
 			name: "k8s-gcs with negative report fraction rejects",
 			args: []string{"--kubernetes-blob-storage-workers=3", "--config-path=foo", "--kubernetes-report-fraction=-1.2"},
 		},
+		{
+			name: "k8s-gcs with upload concurrency and container logs sets them",
+			args: []string{"--kubernetes-blob-storage-workers=3", "--config-path=foo", "--k8s-upload-concurrency=8", "--k8s-upload-container-logs"},
+			expected: &options{
+				k8sBlobStorageWorkers: 3,
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     8,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+				k8sUploadContainerLogs:   true,
+			},
+		},
+		{
+			name: "k8s-gcs with zero upload concurrency rejects",
+			args: []string{"--kubernetes-blob-storage-workers=3", "--config-path=foo", "--k8s-upload-concurrency=0"},
+		},
+		{
+			name: "gcs with upload retries sets them",
+			args: []string{"--blob-storage-workers=3", "--config-path=foo", "--gcs-upload-retries=5"},
+			expected: &options{
+				blobStorageWorkers: 3,
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         5,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		{
+			name: "gcs with negative upload retries rejects",
+			args: []string{"--blob-storage-workers=3", "--config-path=foo", "--gcs-upload-retries=-1"},
+		},
 		{
 			name: "resultstore workers, sets workers",
 			args: []string{"--resultstore-workers=3", "--config-path=foo"},
@@ -236,9 +1841,89 @@ func TestOptions(t *testing.T) {
 					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
 					InRepoConfigCacheSize:                 200,
 				},
-				github:                 defaultGitHubOptions,
-				k8sReportFraction:      1.0,
-				instrumentationOptions: flagutil.DefaultInstrumentationOptions(),
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		{
+			name: "resultstore endpoint, sets connect options",
+			args: []string{"--resultstore-workers=3", "--resultstore-endpoint=localhost:8080", "--resultstore-insecure", "--config-path=foo"},
+			expected: &options{
+				resultStoreWorkers: 3,
+				resultstoreConnect: resultstore.ConnectOptions{Endpoint: "localhost:8080", Insecure: true},
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
+			},
+		},
+		{
+			name: "resultstore endpoint without port, rejects",
+			args: []string{"--resultstore-workers=3", "--resultstore-endpoint=staging-resultstore.googleapis.com", "--config-path=foo"},
+		},
+		{
+			name: "resultstore CA file with insecure connection, rejects",
+			args: []string{"--resultstore-workers=3", "--resultstore-endpoint=localhost:8080", "--resultstore-insecure", "--resultstore-ca-file=/etc/ca.pem", "--config-path=foo"},
+		},
+		{
+			name: "resultstore upload coverage, sets upload coverage",
+			args: []string{"--resultstore-workers=3", "--resultstore-upload-coverage", "--config-path=foo"},
+			expected: &options{
+				resultStoreWorkers:        3,
+				resultstoreUploadCoverage: true,
+				config: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "config-path",
+					JobConfigPathFlagName:                 "job-config-path",
+					ConfigPath:                            "foo",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+					InRepoConfigCacheSize:                 200,
+				},
+				github:                   defaultGitHubOptions,
+				k8sReportFraction:        1.0,
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				circuitBreakerCoolDown:   time.Minute,
+				pubsubMaxPublishAttempts: 3,
+				githubReportBurst:        1,
+				drainTimeout:             30 * time.Second,
+				reportRetryBase:          time.Second,
+				reportRetryMax:           5 * time.Minute,
+				emailSMTPPort:            587,
+				k8sUploadConcurrency:     4,
+				exitOnKubeconfigChange:   true,
+				gcsUploadRetries:         3,
+				slackChannelCacheTTL:     time.Hour,
+				replayLimit:              50,
 			},
 		},
 	}
@@ -303,3 +1988,168 @@ func TestGitHubOptions(t *testing.T) {
 		}
 	}
 }
+
+func TestConfigureReportProxy(t *testing.T) {
+	testCases := []struct {
+		name          string
+		envProxy      string
+		envNoProxy    string
+		httpProxy     string
+		noProxy       string
+		url           string
+		expectedProxy string
+	}{
+		{
+			name:          "proxy flag is used for HTTPS requests",
+			httpProxy:     "http://proxy:3128",
+			url:           "https://hooks.slack.com/services/abc",
+			expectedProxy: "http://proxy:3128",
+		},
+		{
+			name:          "proxy flag is used for HTTP requests",
+			httpProxy:     "http://proxy:3128",
+			url:           "http://webhook.example.com/report",
+			expectedProxy: "http://proxy:3128",
+		},
+		{
+			name:          "proxy flag overrides the environment",
+			envProxy:      "http://env-proxy:8080",
+			httpProxy:     "http://proxy:3128",
+			url:           "https://api.github.com/repos",
+			expectedProxy: "http://proxy:3128",
+		},
+		{
+			name:          "environment is used without flags",
+			envProxy:      "http://env-proxy:8080",
+			url:           "https://api.github.com/repos",
+			expectedProxy: "http://env-proxy:8080",
+		},
+		{
+			name:      "no-proxy hosts are reached directly",
+			httpProxy: "http://proxy:3128",
+			noProxy:   "internal.example.com",
+			url:       "https://internal.example.com/hook",
+		},
+		{
+			name:          "no-proxy flag overrides the environment",
+			envProxy:      "http://env-proxy:8080",
+			envNoProxy:    "api.github.com",
+			noProxy:       "internal.example.com",
+			url:           "https://api.github.com/repos",
+			expectedProxy: "http://env-proxy:8080",
+		},
+		{
+			name: "no proxy configured",
+			url:  "https://hooks.slack.com/services/abc",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, env := range []string{"HTTP_PROXY", "HTTPS_PROXY", "http_proxy", "https_proxy", "NO_PROXY", "no_proxy", "REQUEST_METHOD"} {
+				t.Setenv(env, "")
+			}
+			t.Setenv("HTTP_PROXY", tc.envProxy)
+			t.Setenv("HTTPS_PROXY", tc.envProxy)
+			t.Setenv("NO_PROXY", tc.envNoProxy)
+
+			transport := &http.Transport{}
+			configureReportProxy(transport, tc.httpProxy, tc.noProxy)
+			req, err := http.NewRequest(http.MethodPost, tc.url, nil)
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			proxy, err := transport.Proxy(req)
+			if err != nil {
+				t.Fatalf("failed to get proxy: %v", err)
+			}
+			var got string
+			if proxy != nil {
+				got = proxy.String()
+			}
+			if got != tc.expectedProxy {
+				t.Errorf("expected proxy %q, got %q", tc.expectedProxy, got)
+			}
+		})
+	}
+}
+
+func TestConfigureReportCAs(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600); err != nil {
+		t.Fatalf("failed to write CA file: %v", err)
+	}
+	invalidFile := filepath.Join(dir, "invalid.pem")
+	if err := os.WriteFile(invalidFile, []byte("not a certificate"), 0600); err != nil {
+		t.Fatalf("failed to write invalid CA file: %v", err)
+	}
+
+	// The certificate of the test server isn't trusted by default.
+	if resp, err := (&http.Client{Transport: &http.Transport{}}).Get(server.URL); err == nil {
+		resp.Body.Close()
+		t.Fatal("expected the certificate of the test server not to be trusted without the CA file")
+	}
+
+	transport := &http.Transport{}
+	if err := configureReportCAs(transport, caFile); err != nil {
+		t.Fatalf("failed to configure CA file: %v", err)
+	}
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected the certificate of the test server to be trusted with the CA file: %v", err)
+	}
+	resp.Body.Close()
+
+	if err := configureReportCAs(&http.Transport{}, invalidFile); err == nil {
+		t.Error("expected a file without certificates to be rejected")
+	}
+	o := options{reportCAFile: invalidFile}
+	if err := o.validate(); err == nil {
+		t.Error("expected validation to reject a file without certificates")
+	}
+}
+
+func TestNewReportHTTPClient(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600); err != nil {
+		t.Fatalf("failed to write CA file: %v", err)
+	}
+
+	client, err := newReportHTTPClient(options{reportHTTPProxy: "http://proxy.example.com:3128", reportCAFile: caFile})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if client.Timeout != reportRequestTimeout {
+		t.Errorf("expected the reporter client to time out after %s, got %s", reportRequestTimeout, client.Timeout)
+	}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected the certificate of the test server to be trusted by the reporter client: %v", err)
+	}
+	resp.Body.Close()
+
+	// The default transport, which is used by other clients, is not changed.
+	if resp, err := http.Get(server.URL); err == nil {
+		resp.Body.Close()
+		t.Error("expected the certificate of the test server not to be trusted by the default client")
+	}
+	req, err := http.NewRequest(http.MethodGet, "https://api.github.com", nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	if proxy, err := client.Transport.(*http.Transport).Proxy(req); err != nil || proxy == nil || proxy.Host != "proxy.example.com:3128" {
+		t.Errorf("expected the reporter client to use the proxy, got %v, %v", proxy, err)
+	}
+	if proxy, _ := http.DefaultTransport.(*http.Transport).Proxy(req); proxy != nil && proxy.Host == "proxy.example.com:3128" {
+		t.Error("expected the default transport not to use the proxy")
+	}
+}
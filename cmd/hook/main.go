@@ -196,7 +196,7 @@ func main() {
 	var slackClient *slack.Client
 	if !o.dryRun && string(secret.GetSecret(o.slackTokenFile)) != "" {
 		logrus.Info("Using real slack client.")
-		slackClient = slack.NewClient(secret.GetTokenGenerator(o.slackTokenFile))
+		slackClient = slack.NewClient(secret.GetTokenGenerator(o.slackTokenFile), nil)
 	}
 	if slackClient == nil {
 		logrus.Info("Using fake slack client.")
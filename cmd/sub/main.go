@@ -119,7 +119,7 @@ func main() {
 		ConfigAgent:   configAgent,
 		Metrics:       promMetrics,
 		ProwJobClient: prowjobClient,
-		Reporter:      pubsub.NewReporter(configAgent.Config), // reuse crier reporter
+		Reporter:      pubsub.NewReporter(configAgent.Config, pubsub.DefaultMaxPublishAttempts), // reuse crier reporter
 	}
 
 	if o.config.MoonrakerAddress != "" {
@@ -0,0 +1,140 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bitbucket provides a client for posting build statuses to
+// Bitbucket Server through its build status REST API.
+package bitbucket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// The states a build status can be in.
+const (
+	InProgress = "INPROGRESS"
+	Successful = "SUCCESSFUL"
+	Failed     = "FAILED"
+)
+
+// BuildStatus is the status of a build of a commit. Statuses with the same
+// key replace each other, so a key is usually the name of the build.
+type BuildStatus struct {
+	State       string `json:"state"`
+	Key         string `json:"key"`
+	Name        string `json:"name,omitempty"`
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+// Logger provides an interface to log debug messages.
+type Logger interface {
+	Debugf(s string, v ...interface{})
+}
+
+type errorResponse struct {
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// Client allows you to post build statuses to Bitbucket Server.
+type Client struct {
+	// If logger is non-nil, log all method calls with it.
+	logger Logger
+
+	httpClient     *http.Client
+	tokenGenerator func() []byte
+	fake           bool
+}
+
+// NewClient creates a Bitbucket Server client. The tokenGenerator must
+// return an HTTP access token with write permission on the repositories the
+// statuses are posted to. If httpClient is nil, http.DefaultClient is used.
+func NewClient(tokenGenerator func() []byte, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{
+		logger:         logrus.WithField("client", "bitbucket"),
+		httpClient:     httpClient,
+		tokenGenerator: tokenGenerator,
+	}
+}
+
+// NewFakeClient returns a client that takes no actions.
+func NewFakeClient() *Client {
+	return &Client{
+		fake: true,
+	}
+}
+
+func (c *Client) log(methodName string, args ...interface{}) {
+	if c.logger == nil {
+		return
+	}
+	var as []string
+	for _, arg := range args {
+		as = append(as, fmt.Sprintf("%v", arg))
+	}
+	c.logger.Debugf("%s(%s)", methodName, strings.Join(as, ", "))
+}
+
+// SetBuildStatus posts the build status of the commit to the given server.
+func (c *Client) SetBuildStatus(ctx context.Context, server, sha string, status BuildStatus) error {
+	c.log("SetBuildStatus", server, sha, status.Key, status.State)
+	if c.fake {
+		return nil
+	}
+
+	b, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf("%s/rest/build-status/1.0/commits/%s", strings.TrimSuffix(server, "/"), url.PathEscape(sha))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(c.tokenGenerator())))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to set build status of %s: %w", sha, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var errResp errorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil || len(errResp.Errors) == 0 {
+			return fmt.Errorf("failed to set build status of %s: status %d", sha, resp.StatusCode)
+		}
+		var messages []string
+		for _, e := range errResp.Errors {
+			messages = append(messages, e.Message)
+		}
+		return fmt.Errorf("failed to set build status of %s: status %d: %s", sha, resp.StatusCode, strings.Join(messages, "; "))
+	}
+	return nil
+}
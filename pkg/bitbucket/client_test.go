@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSetBuildStatus(t *testing.T) {
+	var received BuildStatus
+	var path, method, auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		method = r.Method
+		auth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		if strings.HasSuffix(path, "/unknown") {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"errors":[{"message":"The commit unknown does not exist."}]}`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	c := NewClient(func() []byte { return []byte("secret-token\n") }, nil)
+
+	status := BuildStatus{State: Successful, Key: "pull-test", Name: "pull-test", URL: "https://prow.example.com/view/1", Description: "Job succeeded."}
+	if err := c.SetBuildStatus(context.Background(), server.URL+"/", "abc123", status); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if method != http.MethodPost {
+		t.Errorf("unexpected method %q", method)
+	}
+	if expected := "/rest/build-status/1.0/commits/abc123"; path != expected {
+		t.Errorf("expected path %q, got %q", expected, path)
+	}
+	if auth != "Bearer secret-token" {
+		t.Errorf("unexpected authorization header %q", auth)
+	}
+	if received != status {
+		t.Errorf("unexpected status received: %+v", received)
+	}
+
+	err := c.SetBuildStatus(context.Background(), server.URL, "unknown", status)
+	if err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("expected error about the unknown commit, got %v", err)
+	}
+}
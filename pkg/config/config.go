@@ -23,13 +23,19 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	htmltemplate "html/template"
 	"io"
+	"maps"
+	"mime"
+	"net"
+	"net/mail"
 	"net/url"
 	"os"
 	"path"
 	"path/filepath"
 	"regexp"
 	"runtime/debug"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -142,18 +148,34 @@ type JobConfig struct {
 // ProwConfig is config for all prow controllers.
 type ProwConfig struct {
 	// The git sha from which this config was generated.
-	ConfigVersionSHA        string                  `json:"config_version_sha,omitempty"`
-	Tide                    Tide                    `json:"tide,omitempty"`
-	Plank                   Plank                   `json:"plank,omitempty"`
-	Sinker                  Sinker                  `json:"sinker,omitempty"`
-	Deck                    Deck                    `json:"deck,omitempty"`
-	BranchProtection        BranchProtection        `json:"branch-protection"`
-	Gerrit                  Gerrit                  `json:"gerrit"`
-	GitHubReporter          GitHubReporter          `json:"github_reporter"`
-	Horologium              Horologium              `json:"horologium"`
-	SlackReporterConfigs    SlackReporterConfigs    `json:"slack_reporter_configs,omitempty"`
-	DingTalkReporterConfigs DingTalkReporterConfigs `json:"dingtalk_reporter_configs,omitempty"`
-	InRepoConfig            InRepoConfig            `json:"in_repo_config"`
+	ConfigVersionSHA          string                    `json:"config_version_sha,omitempty"`
+	Tide                      Tide                      `json:"tide,omitempty"`
+	Plank                     Plank                     `json:"plank,omitempty"`
+	Sinker                    Sinker                    `json:"sinker,omitempty"`
+	Crier                     Crier                     `json:"crier,omitempty"`
+	Deck                      Deck                      `json:"deck,omitempty"`
+	BranchProtection          BranchProtection          `json:"branch-protection"`
+	Gerrit                    Gerrit                    `json:"gerrit"`
+	GitHubReporter            GitHubReporter            `json:"github_reporter"`
+	Horologium                Horologium                `json:"horologium"`
+	SlackReporterConfigs      SlackReporterConfigs      `json:"slack_reporter_configs,omitempty"`
+	DingTalkReporterConfigs   DingTalkReporterConfigs   `json:"dingtalk_reporter_configs,omitempty"`
+	TeamsReporterConfigs      TeamsReporterConfigs      `json:"teams_reporter_configs,omitempty"`
+	DiscordReporterConfigs    DiscordReporterConfigs    `json:"discord_reporter_configs,omitempty"`
+	WebhookReporterConfigs    WebhookReporterConfigs    `json:"webhook_reporter_configs,omitempty"`
+	EmailReporterConfigs      EmailReporterConfigs      `json:"email_reporter_configs,omitempty"`
+	JiraReporterConfigs       JiraReporterConfigs       `json:"jira_reporter_configs,omitempty"`
+	PagerDutyReporterConfigs  PagerDutyReporterConfigs  `json:"pagerduty_reporter_configs,omitempty"`
+	TelegramReporterConfigs   TelegramReporterConfigs   `json:"telegram_reporter_configs,omitempty"`
+	MatrixReporterConfigs     MatrixReporterConfigs     `json:"matrix_reporter_configs,omitempty"`
+	SNSReporterConfigs        SNSReporterConfigs        `json:"sns_reporter_configs,omitempty"`
+	GoogleChatReporterConfigs GoogleChatReporterConfigs `json:"googlechat_reporter_configs,omitempty"`
+	BitbucketReporterConfigs  BitbucketReporterConfigs  `json:"bitbucket_reporter_configs,omitempty"`
+	GitLabReporterConfigs     GitLabReporterConfigs     `json:"gitlab_reporter_configs,omitempty"`
+	KafkaReporterConfigs      KafkaReporterConfigs      `json:"kafka_reporter_configs,omitempty"`
+	DatadogReporterConfigs    DatadogReporterConfigs    `json:"datadog_reporter_configs,omitempty"`
+	RedisReporterConfigs      RedisReporterConfigs      `json:"redis_reporter_configs,omitempty"`
+	InRepoConfig              InRepoConfig              `json:"in_repo_config"`
 
 	// Gangway contains configurations needed by the the Prow API server of the
 	// same name. It encodes an allowlist of API clients and what kinds of Prow
@@ -1010,6 +1032,25 @@ func (g *Gerrit) DefaultAndValidate() error {
 		return fmt.Errorf("failed to compile regex for allowed presubmit triggers: %s", err.Error())
 	}
 	g.AllowedPresubmitTriggerRe = &CopyableRegexp{re}
+
+	if g.OrgReposConfig != nil {
+		for _, orgConfig := range *g.OrgReposConfig {
+			if orgConfig.ReportLabel != nil {
+				if err := orgConfig.ReportLabel.validate(); err != nil {
+					return fmt.Errorf("invalid report_label for %s: %w", orgConfig.Org, err)
+				}
+			}
+			if orgConfig.Message != "" {
+				tmpl, err := template.New("").Parse(orgConfig.Message)
+				if err != nil {
+					return fmt.Errorf("failed to parse message template for %s: %w", orgConfig.Org, err)
+				}
+				if err := tmpl.Execute(&bytes.Buffer{}, &prowapi.ProwJob{}); err != nil {
+					return fmt.Errorf("failed to execute message template for %s: %w", orgConfig.Org, err)
+				}
+			}
+		}
+	}
 	return nil
 }
 
@@ -1035,6 +1076,47 @@ type GerritOrgRepoConfig struct {
 	// Filters are used for limiting the scope of querying the Gerrit server.
 	// Currently supports branches and excluded branches.
 	Filters *GerritQueryFilter `json:"filters,omitempty"`
+	// ReportLabel is the label crier's Gerrit reporter votes on for changes
+	// of these repos, and the values it votes. Defaults to voting +1 on
+	// Code-Review when all jobs passed and -1 otherwise.
+	ReportLabel *GerritReportLabel `json:"report_label,omitempty"`
+	// Message is a Go text/template rendered against the reported ProwJob
+	// and added to the review messages crier's Gerrit reporter posts on
+	// changes of these repos, e.g. `Logs: {{.Status.URL}}`.
+	Message string `json:"message,omitempty"`
+}
+
+// GerritReportLabel is the label crier's Gerrit reporter votes on.
+type GerritReportLabel struct {
+	// Name is the name of the label. Jobs setting the
+	// prow.k8s.io/gerrit-report-label label vote on that label instead.
+	// Defaults to Code-Review.
+	Name string `json:"name,omitempty"`
+	// Values maps the state of the reported jobs, `success` when all of them
+	// passed and `failure` otherwise, to the value voted. Defaults to +1 for
+	// success and -1 for failure. Postsubmits and merged changes are never
+	// voted below 0.
+	Values map[prowapi.ProwJobState]int `json:"values,omitempty"`
+}
+
+// Value returns the value to vote for the given state of the reported jobs.
+func (l *GerritReportLabel) Value(state prowapi.ProwJobState) int {
+	if v, ok := l.Values[state]; ok {
+		return v
+	}
+	if state == prowapi.SuccessState {
+		return 1
+	}
+	return -1
+}
+
+func (l *GerritReportLabel) validate() error {
+	for state := range l.Values {
+		if state != prowapi.SuccessState && state != prowapi.FailureState {
+			return fmt.Errorf("invalid state %q in values, only %q and %q are supported", state, prowapi.SuccessState, prowapi.FailureState)
+		}
+	}
+	return nil
 }
 
 type GerritQueryFilter struct {
@@ -1061,6 +1143,44 @@ func (goc *GerritOrgRepoConfigs) AllRepos() map[string]map[string]*GerritQueryFi
 	return res
 }
 
+// ReportLabel returns the report label configured for a repo of a Gerrit
+// instance, or nil if there is none.
+func (goc *GerritOrgRepoConfigs) ReportLabel(instance, repo string) *GerritReportLabel {
+	if goc == nil {
+		return nil
+	}
+	for _, orgConfig := range *goc {
+		if orgConfig.Org != instance || orgConfig.ReportLabel == nil {
+			continue
+		}
+		for _, r := range orgConfig.Repos {
+			if r == repo {
+				return orgConfig.ReportLabel
+			}
+		}
+	}
+	return nil
+}
+
+// Message returns the message template configured for a repo of a Gerrit
+// instance, or an empty string if there is none.
+func (goc *GerritOrgRepoConfigs) Message(instance, repo string) string {
+	if goc == nil {
+		return ""
+	}
+	for _, orgConfig := range *goc {
+		if orgConfig.Org != instance || orgConfig.Message == "" {
+			continue
+		}
+		for _, r := range orgConfig.Repos {
+			if r == repo {
+				return orgConfig.Message
+			}
+		}
+	}
+	return ""
+}
+
 func (goc *GerritOrgRepoConfigs) OptOutHelpRepos() map[string]sets.Set[string] {
 	var res map[string]sets.Set[string]
 	for _, orgConfig := range *goc {
@@ -1112,6 +1232,104 @@ type GitHubReporter struct {
 	// comments is only sent when all jobs from current SHA are finished. Status
 	// contexts will still be written.
 	SummaryCommentRepos []string `json:"summary_comment_repos,omitempty"`
+	// CheckRunRepos is a list of orgs and org/repos for which jobs are reported
+	// as check runs through the Checks API instead of as status contexts. The
+	// Checks API is only available to GitHub Apps.
+	CheckRunRepos []string `json:"check_run_repos,omitempty"`
+	// CheckRunLogLines is the number of lines at the end of the build log that
+	// are included in the output of completed check runs. Defaults to 0, which
+	// doesn't include the build log.
+	CheckRunLogLines int `json:"check_run_log_lines,omitempty"`
+	// CheckRunJUnitGlob makes the check runs of failed presubmits annotate
+	// the failed tests of the JUnit files matching this glob, e.g.
+	// `artifacts/junit*.xml`. Test cases with `file` and `line` attributes
+	// are annotated inline on the pull request, others are listed in the
+	// summary. The glob is relative to the job's directory and uses the
+	// syntax of Go's path.Match. No JUnit files are read when unset.
+	CheckRunJUnitGlob string `json:"check_run_junit_glob,omitempty"`
+	// AppendClusterToContext appends the build cluster of the job to its
+	// status context, so that statuses of the same job running on multiple
+	// build clusters can be told apart. Note that Tide and branch protection
+	// match on the full context, including the cluster name.
+	AppendClusterToContext bool `json:"append_cluster_to_context,omitempty"`
+	// ClusterContextSeparator separates the context from the cluster name
+	// when AppendClusterToContext is set. Defaults to "@".
+	ClusterContextSeparator string `json:"cluster_context_separator,omitempty"`
+	// PostArtifactsComment makes the reporter maintain a comment on pull
+	// requests with links to the artifacts and the Spyglass view of failed
+	// presubmits. Each job keeps a single comment that is updated on reruns.
+	PostArtifactsComment bool `json:"post_artifacts_comment,omitempty"`
+	// SignedURLExpiry makes the artifacts comment link to the build log with
+	// a signed URL that expires after the given duration, so that reviewers
+	// without access to a private bucket can read it. Storage backends or
+	// credentials that can't sign URLs fall back to the plain URL. Must not
+	// exceed 7 days, the longest expiry GCS accepts.
+	SignedURLExpiry *metav1.Duration `json:"signed_url_expiry,omitempty"`
+	// RollupRepos is a list of orgs and org/repos for which a single rollup
+	// status is reported per pull request commit, with the worst state of
+	// the latest runs of all its presubmits.
+	RollupRepos []string `json:"rollup_repos,omitempty"`
+	// RollupContext is the context of the rollup status. Defaults to "prow".
+	RollupContext string `json:"rollup_context,omitempty"`
+	// RollupOnly reports only the rollup status for RollupRepos, instead of
+	// also reporting the status or check run of every presubmit. Tide and
+	// branch protection must then require the rollup context instead of the
+	// contexts of the jobs.
+	RollupOnly bool `json:"rollup_only,omitempty"`
+}
+
+// DefaultRollupContext is the context of the rollup status if none is
+// configured.
+const DefaultRollupContext = "prow"
+
+// MaxSignedURLExpiry is the longest expiry of a signed URL that GCS accepts.
+const MaxSignedURLExpiry = 7 * 24 * time.Hour
+
+// StatusContext returns the status context to report for a job with the
+// given context that ran on the given build cluster. If the cluster name is
+// appended, the context is truncated to fit GitHub's length limit while the
+// cluster name is kept, so contexts stay unique across clusters.
+func (g *GitHubReporter) StatusContext(context, cluster string) string {
+	if !g.AppendClusterToContext || cluster == "" {
+		return context
+	}
+	suffix := g.ClusterContextSeparator + cluster
+	if len(suffix)+len(elide) >= statusContextMaxLen {
+		return truncate(context+suffix, statusContextMaxLen)
+	}
+	return truncate(context, statusContextMaxLen-len(suffix)) + suffix
+}
+
+// ReportsCheckRuns returns whether jobs of the given repo are reported as
+// check runs.
+func (g *GitHubReporter) ReportsCheckRuns(org, repo string) bool {
+	fullRepo := fmt.Sprintf("%s/%s", org, repo)
+	for _, ident := range g.CheckRunRepos {
+		if ident == org || ident == fullRepo {
+			return true
+		}
+	}
+	return false
+}
+
+// ReportsRollup returns whether a rollup status is reported for pull
+// requests of the given repo.
+func (g *GitHubReporter) ReportsRollup(org, repo string) bool {
+	fullRepo := fmt.Sprintf("%s/%s", org, repo)
+	for _, ident := range g.RollupRepos {
+		if ident == org || ident == fullRepo {
+			return true
+		}
+	}
+	return false
+}
+
+// RollupStatusContext returns the context of the rollup status.
+func (g *GitHubReporter) RollupStatusContext() string {
+	if g.RollupContext == "" {
+		return DefaultRollupContext
+	}
+	return g.RollupContext
 }
 
 // Sinker is config for the sinker controller.
@@ -1133,6 +1351,548 @@ type Sinker struct {
 	ExcludeClusters []string `json:"exclude_clusters,omitempty"`
 }
 
+// Crier is config for the crier controller.
+type Crier struct {
+	// Workers overrides the number of report workers of a reporter, keyed
+	// by reporter name, e.g. `slackreporter`. Changes take effect without
+	// restarting crier. Reporters that are not listed use the number of
+	// workers passed to crier via flags. Only reporters enabled through
+	// flags are started, and at most 100 workers are used per reporter.
+	// Reporters loaded from plugins via --reporter-plugin-dir must be
+	// listed here, as they have no flags.
+	Workers map[string]int `json:"workers,omitempty"`
+	// ReportTimeouts overrides how long a single report of a reporter may
+	// take, keyed by reporter name, e.g. `slackreporter`. Changes take
+	// effect without restarting crier. Reporters that are not listed use the
+	// timeout passed to crier via the --report-timeout flag.
+	ReportTimeouts map[string]metav1.Duration `json:"report_timeouts,omitempty"`
+	// GCSPathTemplateString overrides the directory, relative to the bucket,
+	// that the GCS reporter writes started.json, finished.json and
+	// prowjob.json to. It is a Go template executed against the ProwJob,
+	// e.g. `custom/{{.Spec.Job}}/{{.Status.BuildID}}`, and must render a
+	// distinct path for every build. When unset, the path derived from the
+	// job's GCS path strategy is used.
+	GCSPathTemplateString string `json:"gcs_path_template,omitempty"`
+	// GCSPathTemplate is compiled at load time from GCSPathTemplateString.
+	GCSPathTemplate *template.Template `json:"-"`
+	// GCSObjectMetadata is custom metadata set on the objects the GCS
+	// reporter uploads, which GCS serves as `x-goog-meta-<key>` headers.
+	// Values are Go templates executed against the ProwJob, e.g.
+	// `{{.Status.BuildID}}`. Keys must be valid HTTP header names and must
+	// not include the `x-goog-meta-` prefix.
+	GCSObjectMetadata map[string]string `json:"gcs_object_metadata,omitempty"`
+	// GCSObjectMetadataTemplates are compiled at load time from
+	// GCSObjectMetadata.
+	GCSObjectMetadataTemplates map[string]*template.Template `json:"-"`
+	// GCSPredefinedACL is the predefined ACL, e.g. `publicRead`, set on the
+	// objects the GCS reporter uploads. It is ignored by storage providers
+	// other than GCS, and rejected by buckets with uniform bucket-level
+	// access.
+	GCSPredefinedACL string `json:"gcs_predefined_acl,omitempty"`
+	// GCSContentTypes overrides the content types of the objects the GCS
+	// reporters upload, keyed by file extension without the leading dot,
+	// e.g. `log: text/plain; charset=utf-8`. Objects with other extensions
+	// get the content type of common artifacts, or the one the system
+	// associates with their extension.
+	GCSContentTypes map[string]string `json:"gcs_content_types,omitempty"`
+	// GCSRetention sets a retention class derived from the job as metadata
+	// on the objects the GCS reporter uploads, so that bucket lifecycle
+	// rules can expire the objects of some jobs sooner than others.
+	GCSRetention *GCSRetention `json:"gcs_retention,omitempty"`
+	// JUnitSummaryGlob makes the GCS reporter write a summary.json with the
+	// aggregated test results of the JUnit files matching this glob, e.g.
+	// `artifacts/junit*.xml`, once a job completed. The glob is relative to
+	// the job's directory and uses the syntax of Go's path.Match. No summary
+	// is written when unset.
+	JUnitSummaryGlob string `json:"junit_summary_glob,omitempty"`
+	// PubSubReporter configures the messages published by the Pub/Sub
+	// reporter.
+	PubSubReporter *PubSubReporter `json:"pubsub_reporter,omitempty"`
+	// SQLReporter configures the table and connections of the SQL reporter.
+	SQLReporter *SQLReporter `json:"sql_reporter,omitempty"`
+	// SkipLabels are labels, e.g. `report: none`, of jobs that no reporter
+	// reports. A job is skipped if any of its labels has the listed value,
+	// or any value if the listed value is empty. Changes take effect
+	// without restarting crier.
+	SkipLabels map[string]string `json:"skip_labels,omitempty"`
+	// SkipAnnotations are annotations of jobs that no reporter reports,
+	// matched like SkipLabels.
+	SkipAnnotations map[string]string `json:"skip_annotations,omitempty"`
+	// SuppressRetryableFailures makes the notification reporters, i.e. the
+	// chat and email reporters, skip failed or errored jobs that are going
+	// to be retried automatically, so that only the outcome of the final
+	// attempt is announced. Status reporters like GitHub and Gerrit still
+	// report every attempt. Prow doesn't retry jobs itself; the tool that
+	// does marks an attempt that it is going to retry with the
+	// `prow.k8s.io/will-retry: "true"` annotation, or with the
+	// `prow.k8s.io/retry-attempt` and `prow.k8s.io/max-retries` annotations.
+	SuppressRetryableFailures bool `json:"suppress_retryable_failures,omitempty"`
+	// ReporterEnablement restricts reporters, keyed by reporter name, e.g.
+	// `slackreporter`, to jobs of some orgs and repos. It applies on top
+	// of the orgs and repos crier is enabled for via flags. Reporters that
+	// are not listed report jobs of all repos. Changes take effect without
+	// restarting crier.
+	ReporterEnablement map[string]ReporterEnablement `json:"reporter_enablement,omitempty"`
+	// ResultStoreProperties are custom properties the ResultStore reporter
+	// adds to invocations, which can be used to filter invocations in the
+	// ResultStore UI. Values are Go templates executed against the ProwJob,
+	// e.g. `{{.Spec.Refs.Org}}` or `{{index .Labels "prow.k8s.io/type"}}`.
+	// Properties whose template renders an empty value or fails, e.g.
+	// because a periodic has no refs, are left out.
+	ResultStoreProperties map[string]string `json:"resultstore_properties,omitempty"`
+	// ResultStorePropertyTemplates are compiled at load time from
+	// ResultStoreProperties.
+	ResultStorePropertyTemplates map[string]*template.Template `json:"-"`
+}
+
+// ReporterEnablement lists the orgs and repos a reporter reports jobs of.
+// Jobs without refs, e.g. most periodics, are always reported.
+type ReporterEnablement struct {
+	// EnabledOrgs are the orgs whose jobs are reported. If EnabledOrgs or
+	// EnabledRepos is set, jobs of all other orgs and repos are not
+	// reported.
+	EnabledOrgs []string `json:"enabled_orgs,omitempty"`
+	// EnabledRepos are the repos, in org/repo format, whose jobs are
+	// reported.
+	EnabledRepos []string `json:"enabled_repos,omitempty"`
+	// DisabledOrgs are the orgs whose jobs are not reported.
+	DisabledOrgs []string `json:"disabled_orgs,omitempty"`
+	// DisabledRepos are the repos, in org/repo format, whose jobs are not
+	// reported. They take precedence over EnabledOrgs.
+	DisabledRepos []string `json:"disabled_repos,omitempty"`
+}
+
+// Enabled tells whether jobs of the given repo should be reported.
+func (e *ReporterEnablement) Enabled(org, repo string) bool {
+	fullName := org + "/" + repo
+	if len(e.EnabledOrgs) > 0 || len(e.EnabledRepos) > 0 {
+		if !sets.New(e.EnabledOrgs...).Has(org) && !sets.New(e.EnabledRepos...).Has(fullName) {
+			return false
+		}
+	}
+	return !sets.New(e.DisabledOrgs...).Has(org) && !sets.New(e.DisabledRepos...).Has(fullName)
+}
+
+// ReportTimeoutFor returns the timeout of a single report of the named
+// reporter, falling back to def if ReportTimeouts doesn't list it.
+func (c *Crier) ReportTimeoutFor(reporter string, def time.Duration) time.Duration {
+	if timeout, ok := c.ReportTimeouts[reporter]; ok {
+		return timeout.Duration
+	}
+	return def
+}
+
+// ReporterEnabled tells whether the named reporter should report jobs of
+// the given repo according to ReporterEnablement.
+func (c *Crier) ReporterEnabled(reporter, org, repo string) bool {
+	enablement, ok := c.ReporterEnablement[reporter]
+	if !ok {
+		return true
+	}
+	return enablement.Enabled(org, repo)
+}
+
+func (c *Crier) validateReporterEnablement() error {
+	for reporter, enablement := range c.ReporterEnablement {
+		for _, repo := range append(append([]string{}, enablement.EnabledRepos...), enablement.DisabledRepos...) {
+			if parts := strings.Split(repo, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+				return fmt.Errorf("crier.reporter_enablement[%s]: repo %q is not in org/repo format", reporter, repo)
+			}
+		}
+	}
+	return nil
+}
+
+// PubSubReporter holds the settings of the Pub/Sub reporter.
+type PubSubReporter struct {
+	// EnableOrderingKey sets the ProwJob's name as the ordering key of the
+	// published messages, so subscribers with message ordering enabled
+	// receive the state transitions of a job in the order they happened.
+	// The topic's subscriptions must have message ordering enabled for
+	// this to take effect.
+	EnableOrderingKey bool `json:"enable_ordering_key,omitempty"`
+	// AdditionalTopics are topics every reported job is also published to,
+	// on top of the topic set by its pubsub annotations, e.g. to fan out
+	// events to a central topic. The messages are identical on all topics.
+	AdditionalTopics []PubSubTopic `json:"additional_topics,omitempty"`
+	// JobStatesToReport are the states whose transitions are published,
+	// e.g. `[pending, success, failure]` to also tell subscribers when a job
+	// starts running. Transitions to all states are published if unset.
+	JobStatesToReport []prowapi.ProwJobState `json:"job_states_to_report,omitempty"`
+}
+
+// ReportsState tells whether transitions of jobs to the state are published.
+func (r *PubSubReporter) ReportsState(state prowapi.ProwJobState) bool {
+	if r == nil || len(r.JobStatesToReport) == 0 {
+		return true
+	}
+	for _, s := range r.JobStatesToReport {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+// SkipsReporting tells whether the labels or annotations of the job opt it
+// out of being reported by any reporter.
+func (c *Crier) SkipsReporting(pj *prowapi.ProwJob) bool {
+	return matchesAny(c.SkipLabels, pj.Labels) || matchesAny(c.SkipAnnotations, pj.Annotations)
+}
+
+// matchesAny tells whether any of the rules matches the values. A rule with
+// an empty value matches any value of its key.
+func matchesAny(rules, values map[string]string) bool {
+	for key, want := range rules {
+		if got, ok := values[key]; ok && (want == "" || got == want) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Crier) validateSkipRules() error {
+	for field, rules := range map[string]map[string]string{"skip_labels": c.SkipLabels, "skip_annotations": c.SkipAnnotations} {
+		for key := range rules {
+			if errs := validation.IsQualifiedName(key); len(errs) != 0 {
+				return fmt.Errorf("crier.%s: invalid key %q: %s", field, key, strings.Join(errs, "; "))
+			}
+		}
+	}
+	return nil
+}
+
+// DefaultSQLReporterTable is the table the SQL reporter writes to if none
+// is configured.
+const DefaultSQLReporterTable = "prowjobs"
+
+var sqlTableRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// SQLReporter holds the settings of the SQL reporter.
+type SQLReporter struct {
+	// Table is the table, optionally qualified by its schema, e.g.
+	// `ci.prowjobs`, that a row is upserted into for every completed job.
+	// Defaults to `prowjobs`. The table must have a unique constraint on
+	// its `name` column.
+	Table string `json:"table,omitempty"`
+	// MaxOpenConnections limits the number of connections opened to the
+	// database. 0 means unlimited. Changes take effect after restarting
+	// crier.
+	MaxOpenConnections int `json:"max_open_connections,omitempty"`
+}
+
+// TableName returns the table the SQL reporter writes to.
+func (r *SQLReporter) TableName() string {
+	if r == nil || r.Table == "" {
+		return DefaultSQLReporterTable
+	}
+	return r.Table
+}
+
+// MaxOpenConns returns the maximum number of connections the SQL reporter
+// opens to the database, 0 meaning unlimited.
+func (r *SQLReporter) MaxOpenConns() int {
+	if r == nil {
+		return 0
+	}
+	return r.MaxOpenConnections
+}
+
+func (c *Crier) validateSQLReporter() error {
+	if c.SQLReporter == nil {
+		return nil
+	}
+	if c.SQLReporter.Table != "" && !sqlTableRegex.MatchString(c.SQLReporter.Table) {
+		return fmt.Errorf("crier.sql_reporter.table: %q is not a valid table name", c.SQLReporter.Table)
+	}
+	if c.SQLReporter.MaxOpenConnections < 0 {
+		return fmt.Errorf("crier.sql_reporter.max_open_connections: must not be negative, got %d", c.SQLReporter.MaxOpenConnections)
+	}
+	return nil
+}
+
+// PubSubTopic identifies a Pub/Sub topic.
+type PubSubTopic struct {
+	// Project is the GCP project of the topic.
+	Project string `json:"project"`
+	// Topic is the ID of the topic.
+	Topic string `json:"topic"`
+}
+
+func (c *Crier) validatePubSubReporter() error {
+	if c.PubSubReporter == nil {
+		return nil
+	}
+	for i, topic := range c.PubSubReporter.AdditionalTopics {
+		if topic.Project == "" || topic.Topic == "" {
+			return fmt.Errorf("crier.pubsub_reporter.additional_topics[%d]: project and topic must be set", i)
+		}
+	}
+	validStates := sets.New(prowapi.GetAllProwJobStates()...)
+	for _, state := range c.PubSubReporter.JobStatesToReport {
+		if !validStates.Has(state) {
+			return fmt.Errorf("crier.pubsub_reporter.job_states_to_report: invalid job state %q", state)
+		}
+	}
+	return nil
+}
+
+// GCSPath renders the GCS reporter path template for the given job. It
+// returns an empty string if no template is configured.
+func (c *Crier) GCSPath(pj *prowapi.ProwJob) (string, error) {
+	if c.GCSPathTemplate == nil {
+		return "", nil
+	}
+	var b bytes.Buffer
+	if err := c.GCSPathTemplate.Execute(&b, pj); err != nil {
+		return "", fmt.Errorf("failed to execute GCS path template: %w", err)
+	}
+	return strings.Trim(path.Clean("/"+b.String()), "/"), nil
+}
+
+// GCSObjectMetadataFor renders the metadata of the objects the GCS reporter
+// uploads for the job, including its retention class. It returns nil if no
+// metadata is configured.
+func (c *Crier) GCSObjectMetadataFor(pj *prowapi.ProwJob) (map[string]string, error) {
+	retentionClass := c.GCSRetention.ClassFor(pj)
+	if len(c.GCSObjectMetadataTemplates) == 0 && retentionClass == "" {
+		return nil, nil
+	}
+	metadata := make(map[string]string, len(c.GCSObjectMetadataTemplates)+1)
+	for key, tmpl := range c.GCSObjectMetadataTemplates {
+		var b bytes.Buffer
+		if err := tmpl.Execute(&b, pj); err != nil {
+			return nil, fmt.Errorf("failed to execute GCS object metadata template of %q: %w", key, err)
+		}
+		metadata[key] = b.String()
+	}
+	if retentionClass != "" {
+		metadata[c.GCSRetention.MetadataKey] = retentionClass
+	}
+	return metadata, nil
+}
+
+// DefaultGCSRetentionMetadataKey is the metadata key the retention class is
+// set under if gcs_retention doesn't set one.
+const DefaultGCSRetentionMetadataKey = "retention-class"
+
+// GCSRetention maps jobs to the retention class set as metadata on the
+// objects the GCS reporter uploads for them.
+type GCSRetention struct {
+	// MetadataKey is the metadata key the retention class is set under.
+	// Defaults to `retention-class`.
+	MetadataKey string `json:"metadata_key,omitempty"`
+	// AllowedClasses are the retention classes the rules and the default
+	// class may use, typically those the bucket lifecycle rules match on.
+	AllowedClasses []string `json:"allowed_classes"`
+	// Rules map jobs to retention classes. The class of the first rule that
+	// matches the job is used.
+	Rules []GCSRetentionRule `json:"rules,omitempty"`
+	// DefaultClass is the retention class of jobs that no rule matches. No
+	// retention class is set for them if it is unset.
+	DefaultClass string `json:"default_class,omitempty"`
+}
+
+// GCSRetentionRule matches jobs by their type and labels.
+type GCSRetentionRule struct {
+	// JobTypes are the types of the jobs the rule matches. It matches jobs
+	// of all types if empty.
+	JobTypes []prowapi.ProwJobType `json:"job_types,omitempty"`
+	// Labels are labels the jobs the rule matches must have, with the same
+	// values.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Class is the retention class of the jobs the rule matches.
+	Class string `json:"class"`
+}
+
+// ClassFor returns the retention class of the job, or an empty string if
+// none applies.
+func (r *GCSRetention) ClassFor(pj *prowapi.ProwJob) string {
+	if r == nil {
+		return ""
+	}
+	for _, rule := range r.Rules {
+		if rule.matches(pj) {
+			return rule.Class
+		}
+	}
+	return r.DefaultClass
+}
+
+func (r *GCSRetentionRule) matches(pj *prowapi.ProwJob) bool {
+	if len(r.JobTypes) > 0 && !slices.Contains(r.JobTypes, pj.Spec.Type) {
+		return false
+	}
+	for key, value := range r.Labels {
+		if actual, ok := pj.Labels[key]; !ok || actual != value {
+			return false
+		}
+	}
+	return true
+}
+
+// DefaultAndValidate defaults the metadata key and makes sure that it is a
+// valid metadata key and that only allowed classes are used.
+func (r *GCSRetention) DefaultAndValidate() error {
+	if r.MetadataKey == "" {
+		r.MetadataKey = DefaultGCSRetentionMetadataKey
+	}
+	if !gcsMetadataKeyRegex.MatchString(r.MetadataKey) || strings.HasPrefix(strings.ToLower(r.MetadataKey), "x-goog-meta-") {
+		return fmt.Errorf("metadata_key %q must be a valid HTTP header name without the x-goog-meta- prefix", r.MetadataKey)
+	}
+	if len(r.AllowedClasses) == 0 {
+		return errors.New("allowed_classes must be set")
+	}
+	allowed := sets.New(r.AllowedClasses...)
+	if allowed.Has("") {
+		return errors.New("allowed_classes must not contain empty values")
+	}
+	if r.DefaultClass != "" && !allowed.Has(r.DefaultClass) {
+		return fmt.Errorf("default_class %q is not one of the allowed_classes", r.DefaultClass)
+	}
+	validTypes := sets.New(prowapi.PresubmitJob, prowapi.PostsubmitJob, prowapi.PeriodicJob, prowapi.BatchJob)
+	for i, rule := range r.Rules {
+		if !allowed.Has(rule.Class) {
+			return fmt.Errorf("rules[%d]: class %q is not one of the allowed_classes", i, rule.Class)
+		}
+		for _, jobType := range rule.JobTypes {
+			if !validTypes.Has(jobType) {
+				return fmt.Errorf("rules[%d]: invalid job type %q", i, jobType)
+			}
+		}
+	}
+	return nil
+}
+
+// ResultStorePropertiesFor renders the custom ResultStore invocation
+// properties of the job. Properties that render empty are left out.
+func (c *Crier) ResultStorePropertiesFor(pj *prowapi.ProwJob) map[string]string {
+	properties := map[string]string{}
+	for key, tmpl := range c.ResultStorePropertyTemplates {
+		var b bytes.Buffer
+		if err := tmpl.Execute(&b, pj); err != nil || b.Len() == 0 {
+			continue
+		}
+		properties[key] = b.String()
+	}
+	return properties
+}
+
+// validateResultStoreProperties compiles the ResultStore property templates.
+func (c *Crier) validateResultStoreProperties() error {
+	if len(c.ResultStoreProperties) == 0 {
+		return nil
+	}
+	c.ResultStorePropertyTemplates = make(map[string]*template.Template, len(c.ResultStoreProperties))
+	for key, value := range c.ResultStoreProperties {
+		if strings.TrimSpace(key) == "" {
+			return errors.New("crier.resultstore_properties: keys must not be empty")
+		}
+		tmpl, err := template.New(key).Parse(value)
+		if err != nil {
+			return fmt.Errorf("crier.resultstore_properties: parsing template of %q: %w", key, err)
+		}
+		c.ResultStorePropertyTemplates[key] = tmpl
+	}
+	return nil
+}
+
+// gcsMetadataKeyRegex matches the token characters allowed in HTTP header
+// names by RFC 7230, which GCS requires for custom metadata keys.
+var gcsMetadataKeyRegex = regexp.MustCompile("^[A-Za-z0-9!#$%&'*+.^_`|~-]+$")
+
+// gcsPredefinedACLs are the predefined ACLs supported by GCS.
+var gcsPredefinedACLs = sets.New("authenticatedRead", "bucketOwnerFullControl", "bucketOwnerRead", "private", "projectPrivate", "publicRead")
+
+// validateGCSObjects compiles the GCS object metadata templates and
+// validates the metadata keys, the retention classes, the predefined ACL and
+// the content types.
+func (c *Crier) validateGCSObjects() error {
+	if c.GCSPredefinedACL != "" && !gcsPredefinedACLs.Has(c.GCSPredefinedACL) {
+		return fmt.Errorf("crier.gcs_predefined_acl must be one of %s, got %q", strings.Join(sets.List(gcsPredefinedACLs), ", "), c.GCSPredefinedACL)
+	}
+	if c.GCSRetention != nil {
+		if err := c.GCSRetention.DefaultAndValidate(); err != nil {
+			return fmt.Errorf("crier.gcs_retention: %w", err)
+		}
+		if _, ok := c.GCSObjectMetadata[c.GCSRetention.MetadataKey]; ok {
+			return fmt.Errorf("crier.gcs_retention: metadata_key %q is also set in crier.gcs_object_metadata", c.GCSRetention.MetadataKey)
+		}
+	}
+	for ext, contentType := range c.GCSContentTypes {
+		if ext == "" || strings.ContainsAny(ext, "./") {
+			return fmt.Errorf("crier.gcs_content_types: key %q must be a file extension without the leading dot", ext)
+		}
+		if _, _, err := mime.ParseMediaType(contentType); err != nil {
+			return fmt.Errorf("crier.gcs_content_types: invalid content type %q of %q: %w", contentType, ext, err)
+		}
+	}
+	if len(c.GCSObjectMetadata) == 0 {
+		return nil
+	}
+	c.GCSObjectMetadataTemplates = make(map[string]*template.Template, len(c.GCSObjectMetadata))
+	for key, value := range c.GCSObjectMetadata {
+		if !gcsMetadataKeyRegex.MatchString(key) {
+			return fmt.Errorf("crier.gcs_object_metadata: key %q must be a valid HTTP header name", key)
+		}
+		if strings.HasPrefix(strings.ToLower(key), "x-goog-meta-") {
+			return fmt.Errorf("crier.gcs_object_metadata: key %q must not include the x-goog-meta- prefix", key)
+		}
+		tmpl, err := template.New(key).Parse(value)
+		if err != nil {
+			return fmt.Errorf("crier.gcs_object_metadata: parsing template of %q: %w", key, err)
+		}
+		c.GCSObjectMetadataTemplates[key] = tmpl
+	}
+	return nil
+}
+
+// validateGCSPathTemplate compiles the GCS path template and makes sure it
+// renders a unique, non-empty path for every build by executing it against
+// jobs that differ only in their name or build ID.
+func (c *Crier) validateGCSPathTemplate() error {
+	if c.GCSPathTemplateString == "" {
+		return nil
+	}
+	tmpl, err := template.New("GCSPath").Parse(c.GCSPathTemplateString)
+	if err != nil {
+		return fmt.Errorf("crier.gcs_path_template: parsing template: %w", err)
+	}
+	c.GCSPathTemplate = tmpl
+
+	sample := func(job, buildID string) *prowapi.ProwJob {
+		return &prowapi.ProwJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "sample"},
+			Spec: prowapi.ProwJobSpec{
+				Type: prowapi.PresubmitJob,
+				Job:  job,
+				Refs: &prowapi.Refs{
+					Org:     "org",
+					Repo:    "repo",
+					BaseRef: "main",
+					Pulls:   []prowapi.Pull{{Number: 1}},
+				},
+			},
+			Status: prowapi.ProwJobStatus{BuildID: buildID},
+		}
+	}
+	seen := map[string]bool{}
+	for _, pj := range []*prowapi.ProwJob{sample("job-a", "1"), sample("job-a", "2"), sample("job-b", "1")} {
+		p, err := c.GCSPath(pj)
+		if err != nil {
+			return fmt.Errorf("crier.gcs_path_template: %w", err)
+		}
+		if p == "" {
+			return errors.New("crier.gcs_path_template must not render an empty path")
+		}
+		if seen[p] {
+			return fmt.Errorf("crier.gcs_path_template renders the same path %q for different builds, it must reference both the job name and the build ID", p)
+		}
+		seen[p] = true
+	}
+	return nil
+}
+
 // LensConfig names a specific lens, and optionally provides some configuration for it.
 type LensConfig struct {
 	// Name is the name of the lens.
@@ -1619,10 +2379,153 @@ type ManagedWebhooks struct {
 // SlackReporter represents the config for the Slack reporter. The channel can be overridden
 // on the job via the .reporter_config.slack.channel property.
 type SlackReporter struct {
-	JobTypesToReport            []prowapi.ProwJobType `json:"job_types_to_report,omitempty"`
+	JobTypesToReport []prowapi.ProwJobType `json:"job_types_to_report,omitempty"`
+	// Channels are additional channels the message is sent to. They are
+	// ignored if the job overrides the channel.
+	Channels []string `json:"channels,omitempty"`
+	// CoalesceWindow enables coalescing the reports of jobs that run against
+	// the same pull request. Reports are collected for this long after the
+	// first one and then posted as a single summary message, with the
+	// individual reports in its thread. Jobs that don't run against a pull
+	// request are reported right away.
+	CoalesceWindow *metav1.Duration `json:"coalesce_window,omitempty"`
+	// ReplyInThread posts the reports of later states of a job as replies in
+	// the thread of the job's first report instead of as new messages. It
+	// doesn't apply to coalesced reports.
+	ReplyInThread bool `json:"reply_in_thread,omitempty"`
+	// JobStatesToMessages maps job states to Go text/templates rendered
+	// against the ProwJob, so that every state can be reported with its own
+	// message. States without a message use ReportTemplate. A report_template
+	// set in the job's reporter_config takes precedence over these.
+	JobStatesToMessages map[prowapi.ProwJobState]string `json:"job_states_to_messages,omitempty"`
+	// MentionsOnFailure are Slack user IDs (U... or W...) and user group IDs
+	// (S...) that are mentioned in the reports of jobs that ended in the
+	// failure or error state.
+	MentionsOnFailure []string `json:"mentions_on_failure,omitempty"`
+	// UseBlockKit sends reports as Block Kit messages showing the job's
+	// name, state and duration and a button linking to its logs. The
+	// rendered report template is kept as the text of the message, which is
+	// shown in notifications. Coalesced reports are always sent as text.
+	UseBlockKit bool `json:"use_block_kit,omitempty"`
+	// ChannelTopics sets the topic of channels, keyed by channel, to the
+	// result of the last completed run of some jobs, so that the channel
+	// shows their status at all times. Topics are set for all completed
+	// runs of these jobs, regardless of the job types and states reported.
+	ChannelTopics map[string]SlackChannelTopic `json:"channel_topics,omitempty"`
+	// StateEmojis prefixes reports with an emoji for the state of the job,
+	// e.g. `:fire:` for failure. Setting it enables the prefix, using the
+	// emojis of DefaultSlackStateEmojis for states it doesn't map.
+	StateEmojis map[prowapi.ProwJobState]string `json:"state_emojis,omitempty"`
+	// QuietHours is a daily window during which only the reports of jobs
+	// that ended in the failure or error state are sent, e.g. to not post
+	// successes at night. Channel topics are updated regardless.
+	QuietHours *SlackQuietHours `json:"quiet_hours,omitempty"`
+	// GroupingAnnotation is the key of a ProwJob annotation, e.g.
+	// `example.com/workflow-run`, whose value groups related jobs, e.g. the
+	// run of the release pipeline they are part of. Reports of jobs with the
+	// annotation show its value, in a context block with Block Kit.
+	GroupingAnnotation          string `json:"grouping_annotation,omitempty"`
 	prowapi.SlackReporterConfig `json:",inline"`
 }
 
+// SlackQuietHours is a daily window of wall clock time in a time zone.
+type SlackQuietHours struct {
+	// TimeZone is the IANA name of the time zone of Start and End, e.g.
+	// `Europe/Berlin`. Defaults to UTC.
+	TimeZone string `json:"time_zone,omitempty"`
+	// Start is the time of day the quiet hours start at, as HH:MM.
+	Start string `json:"start"`
+	// End is the time of day the quiet hours end at, as HH:MM. It may be
+	// before Start for quiet hours spanning midnight, e.g. 20:00 to 08:00.
+	End string `json:"end"`
+}
+
+const slackQuietHoursLayout = "15:04"
+
+// Validate returns an error if the time zone is unknown or Start and End
+// aren't valid times of day.
+func (q *SlackQuietHours) Validate() error {
+	if _, err := time.LoadLocation(q.TimeZone); err != nil {
+		return fmt.Errorf("invalid time_zone %q: %w", q.TimeZone, err)
+	}
+	start, err := time.Parse(slackQuietHoursLayout, q.Start)
+	if err != nil {
+		return fmt.Errorf("start %q is not a time of day as HH:MM", q.Start)
+	}
+	end, err := time.Parse(slackQuietHoursLayout, q.End)
+	if err != nil {
+		return fmt.Errorf("end %q is not a time of day as HH:MM", q.End)
+	}
+	if start.Equal(end) {
+		return errors.New("start and end must differ")
+	}
+	return nil
+}
+
+// Contains returns whether t falls into the quiet hours. The wall clock time
+// of t in the time zone is compared, so that the quiet hours keep starting
+// and ending at the same local time across daylight saving time changes.
+// It returns false if the quiet hours are invalid.
+func (q *SlackQuietHours) Contains(t time.Time) bool {
+	loc, err := time.LoadLocation(q.TimeZone)
+	if err != nil {
+		return false
+	}
+	start, err := time.Parse(slackQuietHoursLayout, q.Start)
+	if err != nil {
+		return false
+	}
+	end, err := time.Parse(slackQuietHoursLayout, q.End)
+	if err != nil {
+		return false
+	}
+	local := t.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	startMinute, endMinute := start.Hour()*60+start.Minute(), end.Hour()*60+end.Minute()
+	if startMinute < endMinute {
+		return startMinute <= minute && minute < endMinute
+	}
+	return minute >= startMinute || minute < endMinute
+}
+
+// DefaultSlackStateEmojis are the emojis reports are prefixed with for the
+// states state_emojis doesn't map.
+var DefaultSlackStateEmojis = map[prowapi.ProwJobState]string{
+	prowapi.TriggeredState: ":arrow_forward:",
+	prowapi.PendingState:   ":hourglass_flowing_sand:",
+	prowapi.SuccessState:   ":white_check_mark:",
+	prowapi.FailureState:   ":x:",
+	prowapi.ErrorState:     ":warning:",
+	prowapi.AbortedState:   ":white_circle:",
+}
+
+// EmojiFor returns the emoji reports of jobs in the given state are
+// prefixed with, or an empty string if state_emojis isn't set.
+func (cfg *SlackReporter) EmojiFor(state prowapi.ProwJobState) string {
+	if len(cfg.StateEmojis) == 0 {
+		return ""
+	}
+	if emoji, ok := cfg.StateEmojis[state]; ok {
+		return emoji
+	}
+	return DefaultSlackStateEmojis[state]
+}
+
+// SlackChannelTopic configures the topic of a Slack channel.
+type SlackChannelTopic struct {
+	// Jobs are the names of the jobs whose results are shown in the topic.
+	Jobs []string `json:"jobs"`
+	// Template is a Go text/template rendered against the ProwJob of the
+	// last completed run. Defaults to a check mark, a cross or, for aborted
+	// jobs, a white circle followed by the job name and state, e.g.
+	// `:white_check_mark: periodic-main: success`.
+	Template string `json:"template,omitempty"`
+}
+
+// DefaultSlackChannelTopicTemplate is the template of channel topics that
+// don't set one.
+const DefaultSlackChannelTopicTemplate = `{{if eq .Status.State "success"}}:white_check_mark:{{else if eq .Status.State "aborted"}}:white_circle:{{else}}:x:{{end}} {{.Spec.Job}}: {{.Status.State}}`
+
 // SlackReporterConfigs represents the config for the Slack reporter(s).
 // Use `org/repo`, `org` or `*` as key and an `SlackReporter` struct as value.
 type SlackReporterConfigs map[string]SlackReporter
@@ -1632,132 +2535,1277 @@ func (cfg SlackReporterConfigs) mergeFrom(additional *SlackReporterConfigs) erro
 		return nil
 	}
 
-	var errs []error
-	for orgOrRepo, slackReporter := range *additional {
-		if _, alreadyConfigured := cfg[orgOrRepo]; alreadyConfigured {
-			errs = append(errs, fmt.Errorf("config for org or repo %s passed more than once", orgOrRepo))
-			continue
+	var errs []error
+	for orgOrRepo, slackReporter := range *additional {
+		if _, alreadyConfigured := cfg[orgOrRepo]; alreadyConfigured {
+			errs = append(errs, fmt.Errorf("config for org or repo %s passed more than once", orgOrRepo))
+			continue
+		}
+		cfg[orgOrRepo] = slackReporter
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// GetSlackReporter returns the config of the repo of the refs merged over the
+// config of its org, merged over the `*` config, so that repos and orgs only
+// need to set what they change.
+func (cfg SlackReporterConfigs) GetSlackReporter(refs *prowapi.Refs) SlackReporter {
+	if refs == nil {
+		return cfg.merged("*")
+	}
+	return cfg.merged(fmt.Sprintf("%s/%s", refs.Org, refs.Repo))
+}
+
+// merged returns the config of the `org/repo`, `org` or `*` key merged over
+// the configs of the keys it inherits from, with defaults applied. It is
+// empty if none of them are configured.
+func (cfg SlackReporterConfigs) merged(orgOrRepo string) SlackReporter {
+	keys := []string{"*"}
+	if org, _, isRepo := strings.Cut(orgOrRepo, "/"); isRepo {
+		keys = append(keys, org)
+	}
+	if orgOrRepo != "*" {
+		keys = append(keys, orgOrRepo)
+	}
+
+	var merged *SlackReporter
+	for _, key := range keys {
+		if slack, ok := cfg[key]; ok {
+			merged = slack.ApplyDefault(merged)
+		}
+	}
+	if merged == nil {
+		return SlackReporter{}
+	}
+	merged.applyDefaults()
+	return *merged
+}
+
+// ApplyDefault returns the config with the fields it doesn't set taken from
+// def. Maps are merged, the entries of the config taking precedence. As
+// false can't be told apart from unset, booleans enabled in def can't be
+// disabled.
+func (src SlackReporter) ApplyDefault(def *SlackReporter) *SlackReporter {
+	merged := src
+	if def == nil {
+		return &merged
+	}
+
+	if merged.JobTypesToReport == nil {
+		merged.JobTypesToReport = def.JobTypesToReport
+	}
+	if merged.Channels == nil {
+		merged.Channels = def.Channels
+	}
+	if merged.CoalesceWindow == nil {
+		merged.CoalesceWindow = def.CoalesceWindow
+	}
+	merged.ReplyInThread = merged.ReplyInThread || def.ReplyInThread
+	merged.JobStatesToMessages = mergeMaps(def.JobStatesToMessages, merged.JobStatesToMessages)
+	if merged.MentionsOnFailure == nil {
+		merged.MentionsOnFailure = def.MentionsOnFailure
+	}
+	merged.UseBlockKit = merged.UseBlockKit || def.UseBlockKit
+	merged.ChannelTopics = mergeMaps(def.ChannelTopics, merged.ChannelTopics)
+	merged.StateEmojis = mergeMaps(def.StateEmojis, merged.StateEmojis)
+	if merged.QuietHours == nil {
+		merged.QuietHours = def.QuietHours
+	}
+	if merged.GroupingAnnotation == "" {
+		merged.GroupingAnnotation = def.GroupingAnnotation
+	}
+	merged.SlackReporterConfig = *merged.SlackReporterConfig.ApplyDefault(&def.SlackReporterConfig)
+	return &merged
+}
+
+// mergeMaps returns a new map with the entries of both maps, those of
+// override taking precedence, or nil if both are empty.
+func mergeMaps[K comparable, V any](base, override map[K]V) map[K]V {
+	if len(base) == 0 && len(override) == 0 {
+		return nil
+	}
+	merged := make(map[K]V, len(base)+len(override))
+	maps.Copy(merged, base)
+	maps.Copy(merged, override)
+	return merged
+}
+
+func (cfg SlackReporterConfigs) HasGlobalConfig() bool {
+	_, exists := cfg["*"]
+	return exists
+}
+
+func (cfg *SlackReporter) applyDefaults() {
+	if cfg.ReportTemplate == "" {
+		cfg.ReportTemplate = `Job {{.Spec.Job}} of type {{.Spec.Type}} ended with state {{.Status.State}}. <{{.Status.URL}}|View logs>`
+	}
+}
+
+func (cfg *SlackReporter) DefaultAndValidate() error {
+	cfg.applyDefaults()
+
+	if cfg.Channel == "" && len(cfg.Channels) == 0 {
+		return errors.New("channel or channels must be set")
+	}
+	for _, channel := range cfg.Channels {
+		if channel == "" {
+			return errors.New("channels must not contain empty values")
+		}
+	}
+	if cfg.CoalesceWindow != nil && cfg.CoalesceWindow.Duration < 0 {
+		return errors.New("coalesce_window must not be negative")
+	}
+
+	// Validate ReportTemplate.
+	tmpl, err := template.New("").Parse(cfg.ReportTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+	if err := tmpl.Execute(&bytes.Buffer{}, &prowapi.ProwJob{}); err != nil {
+		return fmt.Errorf("failed to execute report_template: %w", err)
+	}
+
+	// Validate JobStatesToMessages.
+	validStates := sets.New(prowapi.GetAllProwJobStates()...)
+	for state, message := range cfg.JobStatesToMessages {
+		if !validStates.Has(state) {
+			return fmt.Errorf("job_states_to_messages: invalid job state %q", state)
+		}
+		tmpl, err := template.New("").Parse(message)
+		if err != nil {
+			return fmt.Errorf("failed to parse job_states_to_messages template for state %s: %w", state, err)
+		}
+		if err := tmpl.Execute(&bytes.Buffer{}, &prowapi.ProwJob{}); err != nil {
+			return fmt.Errorf("failed to execute job_states_to_messages template for state %s: %w", state, err)
+		}
+	}
+
+	for state, emoji := range cfg.StateEmojis {
+		if !validStates.Has(state) {
+			return fmt.Errorf("state_emojis: invalid job state %q", state)
+		}
+		if strings.TrimSpace(emoji) == "" {
+			return fmt.Errorf("state_emojis: emoji of state %s must not be empty", state)
+		}
+	}
+
+	for _, mention := range cfg.MentionsOnFailure {
+		if len(mention) < 2 || !strings.ContainsAny(mention[:1], "UWS") {
+			return fmt.Errorf("mentions_on_failure: %q is neither a user ID nor a user group ID", mention)
+		}
+	}
+
+	for channel, topic := range cfg.ChannelTopics {
+		if channel == "" {
+			return errors.New("channel_topics must not contain empty channels")
+		}
+		if len(topic.Jobs) == 0 {
+			return fmt.Errorf("channel_topics: jobs of channel %s must be set", channel)
+		}
+		if _, err := template.New("").Parse(topic.TemplateOrDefault()); err != nil {
+			return fmt.Errorf("failed to parse channel_topics template of channel %s: %w", channel, err)
+		}
+	}
+
+	if cfg.QuietHours != nil {
+		if err := cfg.QuietHours.Validate(); err != nil {
+			return fmt.Errorf("quiet_hours: %w", err)
+		}
+	}
+
+	if cfg.GroupingAnnotation != "" {
+		if errs := validation.IsQualifiedName(cfg.GroupingAnnotation); len(errs) != 0 {
+			return fmt.Errorf("grouping_annotation: invalid key %q: %s", cfg.GroupingAnnotation, strings.Join(errs, "; "))
+		}
+	}
+
+	return nil
+}
+
+// TemplateOrDefault returns the template of the topic, or the default one
+// if it doesn't set one.
+func (t SlackChannelTopic) TemplateOrDefault() string {
+	if t.Template == "" {
+		return DefaultSlackChannelTopicTemplate
+	}
+	return t.Template
+}
+
+// ChannelTopicsOf returns the channels whose topics show the result of the
+// job.
+func (cfg *SlackReporter) ChannelTopicsOf(job string) []string {
+	var channels []string
+	for channel, topic := range cfg.ChannelTopics {
+		if sets.New(topic.Jobs...).Has(job) {
+			channels = append(channels, channel)
+		}
+	}
+	sort.Strings(channels)
+	return channels
+}
+
+// ReportTemplateFor returns the report template for a job in the given state,
+// given the job's own reporter config, which may be nil.
+func (cfg *SlackReporter) ReportTemplateFor(state prowapi.ProwJobState, jobConfig *prowapi.SlackReporterConfig) string {
+	if jobConfig != nil && jobConfig.ReportTemplate != "" {
+		return jobConfig.ReportTemplate
+	}
+	if message, ok := cfg.JobStatesToMessages[state]; ok {
+		return message
+	}
+	return cfg.ReportTemplate
+}
+
+// DingTalkReporter represents the config for the DingTalk reporter. The token can be overridden
+// on the job via the .reporter_config.ding_talk.token property.
+type DingTalkReporter struct {
+	JobTypesToReport []prowapi.ProwJobType `json:"job_types_to_report,omitempty"`
+	// SecretFile is the path of a file in crier's pod holding the secret
+	// of the bot's "Additional Signature" security setting, with which
+	// messages are signed. Crier needs to be restarted to pick up secret
+	// files that are added to the config.
+	SecretFile                     string `json:"secret_file,omitempty"`
+	prowapi.DingTalkReporterConfig `json:",inline"`
+}
+
+// DingTalkReporterConfigs represents the config for the Slack reporter(s).
+// Use `org/repo`, `org` or `*` as key and an `DingTalkReporter` struct as value.
+type DingTalkReporterConfigs map[string]DingTalkReporter
+
+func (cfg DingTalkReporterConfigs) mergeFrom(additional *DingTalkReporterConfigs) error {
+	if additional == nil {
+		return nil
+	}
+
+	var errs []error
+	for orgOrRepo, dingTalkReporter := range *additional {
+		if _, alreadyConfigured := cfg[orgOrRepo]; alreadyConfigured {
+			errs = append(errs, fmt.Errorf("config for org or repo %s passed more than once", orgOrRepo))
+			continue
+		}
+		cfg[orgOrRepo] = dingTalkReporter
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+func (cfg DingTalkReporterConfigs) GetDingTalkReporter(refs *prowapi.Refs) DingTalkReporter {
+	if refs == nil {
+		return cfg["*"]
+	}
+
+	if dingtalk, ok := cfg[fmt.Sprintf("%s/%s", refs.Org, refs.Repo)]; ok {
+		return dingtalk
+	}
+
+	if dingtalk, ok := cfg[refs.Org]; ok {
+		return dingtalk
+	}
+
+	return cfg["*"]
+}
+
+func (cfg DingTalkReporterConfigs) HasGlobalConfig() bool {
+	_, exists := cfg["*"]
+	return exists
+}
+
+func (cfg *DingTalkReporter) DefaultAndValidate() error {
+	// Default ReportTemplate.
+	if cfg.ReportTemplate == "" {
+		cfg.ReportTemplate = `{{ $repo := "" }}{{with .Spec.Refs}}{{$repo = .Repo}}{{end}}{{if eq $repo ""}}{{if .Spec.ExtraRefs}}{{with index .Spec.ExtraRefs 0}}{{$repo = .Repo}}{{end}}{{end}}{{end}}## Repo: {{ $repo }}
+---
+- Job: {{.Spec.Job}}
+- Type: {{.Spec.Type}}
+- State: {{if eq .Status.State "triggered"}}<font color="orange">**{{.Status.State}}**</font>{{end}}{{if eq .Status.State "pending"}}<font color="yellow">**{{.Status.State}}**</font>{{end}}{{if eq .Status.State "success"}}<font color="green">**{{.Status.State}}**</font>{{end}}{{if eq .Status.State "failure"}}<font color="red">**{{.Status.State}}**</font>{{end}}{{if eq .Status.State "aborted"}}<font color="gray">**{{.Status.State}}**</font>{{end}}{{if eq .Status.State "error"}}<font color="red">**{{.Status.State}}**</font>{{end}}
+- Log: [View logs]({{.Status.URL}})`
+	}
+
+	if cfg.Token == "" {
+		return errors.New("token must be set")
+	}
+
+	// Validate ReportTemplate.
+	tmpl, err := template.New("").Parse(cfg.ReportTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+	if err := tmpl.Execute(&bytes.Buffer{}, &prowapi.ProwJob{}); err != nil {
+		return fmt.Errorf("failed to execute report_template: %w", err)
+	}
+
+	return nil
+}
+
+// TeamsReporter represents the config for the Microsoft Teams reporter.
+type TeamsReporter struct {
+	JobTypesToReport  []prowapi.ProwJobType  `json:"job_types_to_report,omitempty"`
+	JobStatesToReport []prowapi.ProwJobState `json:"job_states_to_report,omitempty"`
+	// Channel is the name of the incoming webhook to post to. The webhook
+	// URLs are secret and are looked up by this name in the file passed to
+	// crier via --teams-webhook-file.
+	Channel string `json:"channel,omitempty"`
+	// ReportTemplate is a Go text/template rendered against the ProwJob and
+	// used as the text of the MessageCard.
+	ReportTemplate string `json:"report_template,omitempty"`
+}
+
+// TeamsReporterConfigs represents the config for the Microsoft Teams reporter(s).
+// Use `org/repo`, `org` or `*` as key and an `TeamsReporter` struct as value.
+type TeamsReporterConfigs map[string]TeamsReporter
+
+func (cfg TeamsReporterConfigs) GetTeamsReporter(refs *prowapi.Refs) TeamsReporter {
+	if refs == nil {
+		return cfg["*"]
+	}
+
+	if teams, ok := cfg[fmt.Sprintf("%s/%s", refs.Org, refs.Repo)]; ok {
+		return teams
+	}
+
+	if teams, ok := cfg[refs.Org]; ok {
+		return teams
+	}
+
+	return cfg["*"]
+}
+
+func (cfg *TeamsReporter) DefaultAndValidate() error {
+	// Default ReportTemplate.
+	if cfg.ReportTemplate == "" {
+		cfg.ReportTemplate = `Job {{.Spec.Job}} of type {{.Spec.Type}} ended with state {{.Status.State}}.`
+	}
+
+	if cfg.Channel == "" {
+		return errors.New("channel must be set")
+	}
+
+	// Validate ReportTemplate.
+	tmpl, err := template.New("").Parse(cfg.ReportTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+	if err := tmpl.Execute(&bytes.Buffer{}, &prowapi.ProwJob{}); err != nil {
+		return fmt.Errorf("failed to execute report_template: %w", err)
+	}
+
+	return nil
+}
+
+// DiscordReporter represents the config for the Discord reporter.
+type DiscordReporter struct {
+	JobTypesToReport  []prowapi.ProwJobType  `json:"job_types_to_report,omitempty"`
+	JobStatesToReport []prowapi.ProwJobState `json:"job_states_to_report,omitempty"`
+	// Channel is the name of the webhook to post to. The webhook URLs are
+	// secret and are looked up by this name in the file passed to crier via
+	// --discord-webhook-file.
+	Channel string `json:"channel,omitempty"`
+	// ReportTemplate is a Go text/template rendered against the ProwJob and
+	// used as the content of the message. Content longer than 2000 characters
+	// is truncated.
+	ReportTemplate string `json:"report_template,omitempty"`
+}
+
+// DiscordReporterConfigs represents the config for the Discord reporter(s).
+// Use `org/repo`, `org` or `*` as key and an `DiscordReporter` struct as value.
+type DiscordReporterConfigs map[string]DiscordReporter
+
+func (cfg DiscordReporterConfigs) GetDiscordReporter(refs *prowapi.Refs) DiscordReporter {
+	if refs == nil {
+		return cfg["*"]
+	}
+
+	if discord, ok := cfg[fmt.Sprintf("%s/%s", refs.Org, refs.Repo)]; ok {
+		return discord
+	}
+
+	if discord, ok := cfg[refs.Org]; ok {
+		return discord
+	}
+
+	return cfg["*"]
+}
+
+func (cfg *DiscordReporter) DefaultAndValidate() error {
+	// Default ReportTemplate.
+	if cfg.ReportTemplate == "" {
+		cfg.ReportTemplate = `Job {{.Spec.Job}} of type {{.Spec.Type}} ended with state {{.Status.State}}.`
+	}
+
+	if cfg.Channel == "" {
+		return errors.New("channel must be set")
+	}
+
+	// Validate ReportTemplate.
+	tmpl, err := template.New("").Parse(cfg.ReportTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+	if err := tmpl.Execute(&bytes.Buffer{}, &prowapi.ProwJob{}); err != nil {
+		return fmt.Errorf("failed to execute report_template: %w", err)
+	}
+
+	return nil
+}
+
+// WebhookReporterFields are the top level ProwJob fields that can be sent
+// by the webhook reporter.
+var WebhookReporterFields = sets.New[string]("apiVersion", "kind", "metadata", "spec", "status")
+
+// WebhookReporter represents the config for the generic HTTP webhook reporter.
+type WebhookReporter struct {
+	JobTypesToReport  []prowapi.ProwJobType  `json:"job_types_to_report,omitempty"`
+	JobStatesToReport []prowapi.ProwJobState `json:"job_states_to_report,omitempty"`
+	// URL is the endpoint the ProwJob is POSTed to.
+	URL string `json:"url,omitempty"`
+	// Headers are static headers added to every request. A bearer token can
+	// be added by passing --webhook-token-file to crier instead of putting
+	// it here.
+	Headers map[string]string `json:"headers,omitempty"`
+	// Fields limits the payload to the given top level fields of the ProwJob,
+	// e.g. `spec` and `status`. The whole ProwJob is sent if unset.
+	Fields []string `json:"fields,omitempty"`
+	// Timeout is the timeout of a single request. Defaults to 10s.
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// MaxRetries is how many times a failed request is retried with
+	// exponential backoff. Requests are not retried if unset.
+	MaxRetries int `json:"max_retries,omitempty"`
+}
+
+// WebhookReporterConfigs represents the config for the webhook reporter(s).
+// Use `org/repo`, `org` or `*` as key and an `WebhookReporter` struct as value.
+type WebhookReporterConfigs map[string]WebhookReporter
+
+func (cfg WebhookReporterConfigs) GetWebhookReporter(refs *prowapi.Refs) WebhookReporter {
+	if refs == nil {
+		return cfg["*"]
+	}
+
+	if webhook, ok := cfg[fmt.Sprintf("%s/%s", refs.Org, refs.Repo)]; ok {
+		return webhook
+	}
+
+	if webhook, ok := cfg[refs.Org]; ok {
+		return webhook
+	}
+
+	return cfg["*"]
+}
+
+func (cfg *WebhookReporter) DefaultAndValidate() error {
+	if cfg.Timeout == nil {
+		cfg.Timeout = &metav1.Duration{Duration: 10 * time.Second}
+	}
+
+	if cfg.URL == "" {
+		return errors.New("url must be set")
+	}
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return fmt.Errorf("failed to parse url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("url scheme must be http or https, got %q", u.Scheme)
+	}
+	if cfg.MaxRetries < 0 {
+		return fmt.Errorf("max_retries must not be negative, got %d", cfg.MaxRetries)
+	}
+	for _, field := range cfg.Fields {
+		if !WebhookReporterFields.Has(field) {
+			return fmt.Errorf("unknown field %q, must be one of %v", field, sets.List(WebhookReporterFields))
+		}
+	}
+
+	return nil
+}
+
+// EmailReporter represents the config for the email reporter.
+type EmailReporter struct {
+	JobTypesToReport  []prowapi.ProwJobType  `json:"job_types_to_report,omitempty"`
+	JobStatesToReport []prowapi.ProwJobState `json:"job_states_to_report,omitempty"`
+	// Recipients are the addresses the email is sent to.
+	Recipients []string `json:"recipients,omitempty"`
+	// SubjectTemplate is a Go text/template rendered against the ProwJob and
+	// used as the subject of the email.
+	SubjectTemplate string `json:"subject_template,omitempty"`
+	// ReportTemplate is a Go html/template rendered against the ProwJob and
+	// used as the HTML body of the email.
+	ReportTemplate string `json:"report_template,omitempty"`
+}
+
+// EmailReporterConfigs represents the config for the email reporter(s).
+// Use `org/repo`, `org` or `*` as key and an `EmailReporter` struct as value.
+type EmailReporterConfigs map[string]EmailReporter
+
+func (cfg EmailReporterConfigs) GetEmailReporter(refs *prowapi.Refs) EmailReporter {
+	if refs == nil {
+		return cfg["*"]
+	}
+
+	if email, ok := cfg[fmt.Sprintf("%s/%s", refs.Org, refs.Repo)]; ok {
+		return email
+	}
+
+	if email, ok := cfg[refs.Org]; ok {
+		return email
+	}
+
+	return cfg["*"]
+}
+
+func (cfg *EmailReporter) DefaultAndValidate() error {
+	// Default SubjectTemplate and ReportTemplate.
+	if cfg.SubjectTemplate == "" {
+		cfg.SubjectTemplate = `[Prow] Job {{.Spec.Job}} ended with state {{.Status.State}}`
+	}
+	if cfg.ReportTemplate == "" {
+		cfg.ReportTemplate = `<p>Job <b>{{.Spec.Job}}</b> of type {{.Spec.Type}} ended with state <b>{{.Status.State}}</b>.</p>{{if .Status.URL}}<p><a href="{{.Status.URL}}">View logs</a></p>{{end}}`
+	}
+
+	if len(cfg.Recipients) == 0 {
+		return errors.New("recipients must be set")
+	}
+	for _, recipient := range cfg.Recipients {
+		if _, err := mail.ParseAddress(recipient); err != nil {
+			return fmt.Errorf("invalid recipient %q: %w", recipient, err)
+		}
+	}
+
+	// Validate SubjectTemplate and ReportTemplate.
+	subjectTmpl, err := template.New("").Parse(cfg.SubjectTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse subject_template: %w", err)
+	}
+	if err := subjectTmpl.Execute(&bytes.Buffer{}, &prowapi.ProwJob{}); err != nil {
+		return fmt.Errorf("failed to execute subject_template: %w", err)
+	}
+	reportTmpl, err := htmltemplate.New("").Parse(cfg.ReportTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+	if err := reportTmpl.Execute(&bytes.Buffer{}, &prowapi.ProwJob{}); err != nil {
+		return fmt.Errorf("failed to execute report_template: %w", err)
+	}
+
+	return nil
+}
+
+// DefaultJiraIssuesAnnotation is the default ProwJob annotation the Jira
+// reporter reads issue keys from.
+const DefaultJiraIssuesAnnotation = "prow.k8s.io/jira-issues"
+
+// JiraReporter represents the config for the Jira reporter, which comments on
+// the Jira issues a ProwJob is linked to.
+type JiraReporter struct {
+	// JobTypesToReport limits reporting to the given job types. All job types
+	// are reported if unset.
+	JobTypesToReport []prowapi.ProwJobType `json:"job_types_to_report,omitempty"`
+	// JobStatesToReport defaults to failure.
+	JobStatesToReport []prowapi.ProwJobState `json:"job_states_to_report,omitempty"`
+	// IssuesAnnotation is the ProwJob annotation holding the keys of the
+	// linked issues, e.g. `PROJ-123, PROJ-456`. Defaults to
+	// `prow.k8s.io/jira-issues`.
+	IssuesAnnotation string `json:"issues_annotation,omitempty"`
+	// ReportTemplate is a Go text/template rendered against the ProwJob and
+	// used as the comment, in Jira markup.
+	ReportTemplate string `json:"report_template,omitempty"`
+}
+
+// JiraReporterConfigs represents the config for the Jira reporter(s).
+// Use `org/repo`, `org` or `*` as key and an `JiraReporter` struct as value.
+type JiraReporterConfigs map[string]JiraReporter
+
+func (cfg JiraReporterConfigs) GetJiraReporter(refs *prowapi.Refs) JiraReporter {
+	if refs == nil {
+		return cfg["*"]
+	}
+
+	if jira, ok := cfg[fmt.Sprintf("%s/%s", refs.Org, refs.Repo)]; ok {
+		return jira
+	}
+
+	if jira, ok := cfg[refs.Org]; ok {
+		return jira
+	}
+
+	return cfg["*"]
+}
+
+func (cfg *JiraReporter) DefaultAndValidate() error {
+	if cfg.JobStatesToReport == nil {
+		cfg.JobStatesToReport = []prowapi.ProwJobState{prowapi.FailureState}
+	}
+	if cfg.IssuesAnnotation == "" {
+		cfg.IssuesAnnotation = DefaultJiraIssuesAnnotation
+	}
+	if cfg.ReportTemplate == "" {
+		cfg.ReportTemplate = `Job {{.Spec.Job}} of type {{.Spec.Type}} ended with state {{.Status.State}}.{{if .Status.URL}} [View logs|{{.Status.URL}}]{{end}}`
+	}
+
+	// Validate ReportTemplate.
+	tmpl, err := template.New("").Parse(cfg.ReportTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+	if err := tmpl.Execute(&bytes.Buffer{}, &prowapi.ProwJob{}); err != nil {
+		return fmt.Errorf("failed to execute report_template: %w", err)
+	}
+
+	return nil
+}
+
+// PagerDutyReporter represents the config for the PagerDuty reporter, which
+// triggers an alert when a job fails and resolves it once the job succeeds
+// again.
+type PagerDutyReporter struct {
+	// JobTypesToReport defaults to periodic.
+	JobTypesToReport []prowapi.ProwJobType `json:"job_types_to_report,omitempty"`
+	// JobStatesToReport are the states that trigger an alert. Defaults to
+	// failure and error. Alerts are always resolved once the job succeeds.
+	JobStatesToReport []prowapi.ProwJobState `json:"job_states_to_report,omitempty"`
+	// RoutingKey is the integration key of the PagerDuty service the alerts
+	// are sent to.
+	RoutingKey string `json:"routing_key,omitempty"`
+	// Severity of the triggered alerts, one of critical, error, warning or
+	// info. Defaults to critical.
+	Severity string `json:"severity,omitempty"`
+	// ReportTemplate is a Go text/template rendered against the ProwJob and
+	// used as the summary of the alert. Summaries longer than 1024
+	// characters are truncated.
+	ReportTemplate string `json:"report_template,omitempty"`
+}
+
+// PagerDutyReporterConfigs represents the config for the PagerDuty reporter(s).
+// Use `org/repo`, `org` or `*` as key and an `PagerDutyReporter` struct as value.
+type PagerDutyReporterConfigs map[string]PagerDutyReporter
+
+func (cfg PagerDutyReporterConfigs) GetPagerDutyReporter(refs *prowapi.Refs) PagerDutyReporter {
+	if refs == nil {
+		return cfg["*"]
+	}
+
+	if pagerDuty, ok := cfg[fmt.Sprintf("%s/%s", refs.Org, refs.Repo)]; ok {
+		return pagerDuty
+	}
+
+	if pagerDuty, ok := cfg[refs.Org]; ok {
+		return pagerDuty
+	}
+
+	return cfg["*"]
+}
+
+var pagerDutySeverities = sets.New[string]("critical", "error", "warning", "info")
+
+func (cfg *PagerDutyReporter) DefaultAndValidate() error {
+	if cfg.JobTypesToReport == nil {
+		cfg.JobTypesToReport = []prowapi.ProwJobType{prowapi.PeriodicJob}
+	}
+	if cfg.JobStatesToReport == nil {
+		cfg.JobStatesToReport = []prowapi.ProwJobState{prowapi.FailureState, prowapi.ErrorState}
+	}
+	if cfg.Severity == "" {
+		cfg.Severity = "critical"
+	}
+	if cfg.ReportTemplate == "" {
+		cfg.ReportTemplate = `Job {{.Spec.Job}} ended with state {{.Status.State}}`
+	}
+
+	if cfg.RoutingKey == "" {
+		return errors.New("routing_key must be set")
+	}
+	if !pagerDutySeverities.Has(cfg.Severity) {
+		return fmt.Errorf("severity %q is invalid, must be one of %v", cfg.Severity, sets.List(pagerDutySeverities))
+	}
+	for _, state := range cfg.JobStatesToReport {
+		if state == prowapi.SuccessState {
+			return errors.New("job_states_to_report must not contain success, alerts are resolved when a job succeeds")
+		}
+	}
+
+	// Validate ReportTemplate.
+	tmpl, err := template.New("").Parse(cfg.ReportTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+	if err := tmpl.Execute(&bytes.Buffer{}, &prowapi.ProwJob{}); err != nil {
+		return fmt.Errorf("failed to execute report_template: %w", err)
+	}
+
+	return nil
+}
+
+// TelegramReporter represents the config for the Telegram reporter.
+type TelegramReporter struct {
+	JobTypesToReport  []prowapi.ProwJobType  `json:"job_types_to_report,omitempty"`
+	JobStatesToReport []prowapi.ProwJobState `json:"job_states_to_report,omitempty"`
+	// ChatID is the chat the messages are sent to, either a numeric chat ID
+	// or the `@username` of a public channel. The bot token is passed to
+	// crier via --telegram-token-file.
+	ChatID string `json:"chat_id,omitempty"`
+	// ReportTemplate is a Go text/template rendered against the ProwJob and
+	// shown below the job name. Its output is escaped, so it is shown as
+	// plain text.
+	ReportTemplate string `json:"report_template,omitempty"`
+}
+
+// TelegramReporterConfigs represents the config for the Telegram reporter(s).
+// Use `org/repo`, `org` or `*` as key and an `TelegramReporter` struct as value.
+type TelegramReporterConfigs map[string]TelegramReporter
+
+func (cfg TelegramReporterConfigs) GetTelegramReporter(refs *prowapi.Refs) TelegramReporter {
+	if refs == nil {
+		return cfg["*"]
+	}
+
+	if telegram, ok := cfg[fmt.Sprintf("%s/%s", refs.Org, refs.Repo)]; ok {
+		return telegram
+	}
+
+	if telegram, ok := cfg[refs.Org]; ok {
+		return telegram
+	}
+
+	return cfg["*"]
+}
+
+func (cfg *TelegramReporter) DefaultAndValidate() error {
+	// Default ReportTemplate.
+	if cfg.ReportTemplate == "" {
+		cfg.ReportTemplate = `Job {{.Spec.Job}} of type {{.Spec.Type}} ended with state {{.Status.State}}.`
+	}
+
+	if cfg.ChatID == "" {
+		return errors.New("chat_id must be set")
+	}
+
+	// Validate ReportTemplate.
+	tmpl, err := template.New("").Parse(cfg.ReportTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+	if err := tmpl.Execute(&bytes.Buffer{}, &prowapi.ProwJob{}); err != nil {
+		return fmt.Errorf("failed to execute report_template: %w", err)
+	}
+
+	return nil
+}
+
+// MatrixReporter represents the config for the Matrix reporter.
+type MatrixReporter struct {
+	JobTypesToReport  []prowapi.ProwJobType  `json:"job_types_to_report,omitempty"`
+	JobStatesToReport []prowapi.ProwJobState `json:"job_states_to_report,omitempty"`
+	// Homeserver is the base URL of the homeserver the client-server API is
+	// called on, e.g. `https://matrix.example.com`. The access token is
+	// passed to crier via --matrix-token-file.
+	Homeserver string `json:"homeserver,omitempty"`
+	// RoomID is the ID of the room the messages are sent to, e.g.
+	// `!abcdef:example.com`. The user of the access token must have joined
+	// the room.
+	RoomID string `json:"room_id,omitempty"`
+	// ReportTemplate is a Go text/template rendered against the ProwJob and
+	// shown below the job name. Its output is escaped, so it is shown as
+	// plain text.
+	ReportTemplate string `json:"report_template,omitempty"`
+}
+
+// MatrixReporterConfigs represents the config for the Matrix reporter(s).
+// Use `org/repo`, `org` or `*` as key and an `MatrixReporter` struct as value.
+type MatrixReporterConfigs map[string]MatrixReporter
+
+func (cfg MatrixReporterConfigs) GetMatrixReporter(refs *prowapi.Refs) MatrixReporter {
+	if refs == nil {
+		return cfg["*"]
+	}
+
+	if matrix, ok := cfg[fmt.Sprintf("%s/%s", refs.Org, refs.Repo)]; ok {
+		return matrix
+	}
+
+	if matrix, ok := cfg[refs.Org]; ok {
+		return matrix
+	}
+
+	return cfg["*"]
+}
+
+func (cfg *MatrixReporter) DefaultAndValidate() error {
+	// Default ReportTemplate.
+	if cfg.ReportTemplate == "" {
+		cfg.ReportTemplate = `Job {{.Spec.Job}} of type {{.Spec.Type}} ended with state {{.Status.State}}.`
+	}
+
+	if cfg.Homeserver == "" {
+		return errors.New("homeserver must be set")
+	}
+	u, err := url.Parse(cfg.Homeserver)
+	if err != nil {
+		return fmt.Errorf("failed to parse homeserver: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("homeserver %q must be an absolute http(s) URL", cfg.Homeserver)
+	}
+	cfg.Homeserver = strings.TrimSuffix(cfg.Homeserver, "/")
+
+	if !strings.HasPrefix(cfg.RoomID, "!") {
+		return fmt.Errorf("room_id %q must be a room ID starting with '!', aliases are not supported", cfg.RoomID)
+	}
+
+	// Validate ReportTemplate.
+	tmpl, err := template.New("").Parse(cfg.ReportTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+	if err := tmpl.Execute(&bytes.Buffer{}, &prowapi.ProwJob{}); err != nil {
+		return fmt.Errorf("failed to execute report_template: %w", err)
+	}
+
+	return nil
+}
+
+// SNSReporter represents the config for the Amazon SNS reporter.
+type SNSReporter struct {
+	JobTypesToReport  []prowapi.ProwJobType  `json:"job_types_to_report,omitempty"`
+	JobStatesToReport []prowapi.ProwJobState `json:"job_states_to_report,omitempty"`
+	// TopicARN is the ARN of the topic the messages are published to, e.g.
+	// `arn:aws:sns:us-east-1:123456789012:prow`. The region of the topic is
+	// taken from the ARN. Credentials are looked up through the default AWS
+	// credential chain.
+	TopicARN string `json:"topic_arn,omitempty"`
+}
+
+// SNSReporterConfigs represents the config for the Amazon SNS reporter(s).
+// Use `org/repo`, `org` or `*` as key and an `SNSReporter` struct as value.
+type SNSReporterConfigs map[string]SNSReporter
+
+func (cfg SNSReporterConfigs) GetSNSReporter(refs *prowapi.Refs) SNSReporter {
+	if refs == nil {
+		return cfg["*"]
+	}
+
+	if sns, ok := cfg[fmt.Sprintf("%s/%s", refs.Org, refs.Repo)]; ok {
+		return sns
+	}
+
+	if sns, ok := cfg[refs.Org]; ok {
+		return sns
+	}
+
+	return cfg["*"]
+}
+
+func (cfg *SNSReporter) DefaultAndValidate() error {
+	// Like the Pub/Sub reporter, report every transition of every job by default.
+	if len(cfg.JobTypesToReport) == 0 {
+		cfg.JobTypesToReport = []prowapi.ProwJobType{prowapi.PresubmitJob, prowapi.PostsubmitJob, prowapi.PeriodicJob, prowapi.BatchJob}
+	}
+	if len(cfg.JobStatesToReport) == 0 {
+		cfg.JobStatesToReport = prowapi.GetAllProwJobStates()
+	}
+
+	if cfg.TopicARN == "" {
+		return errors.New("topic_arn must be set")
+	}
+	if _, err := SNSTopicRegion(cfg.TopicARN); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SNSTopicRegion returns the region of the SNS topic with the given ARN.
+func SNSTopicRegion(topicARN string) (string, error) {
+	// arn:partition:sns:region:account-id:topic-name
+	parts := strings.Split(topicARN, ":")
+	if len(parts) != 6 || parts[0] != "arn" || parts[2] != "sns" || parts[3] == "" || parts[4] == "" || parts[5] == "" {
+		return "", fmt.Errorf("topic_arn %q is not a valid SNS topic ARN", topicARN)
+	}
+	return parts[3], nil
+}
+
+// GoogleChatReporter represents the config for the Google Chat reporter.
+type GoogleChatReporter struct {
+	JobTypesToReport  []prowapi.ProwJobType  `json:"job_types_to_report,omitempty"`
+	JobStatesToReport []prowapi.ProwJobState `json:"job_states_to_report,omitempty"`
+	// Space is the name of the incoming webhook to post to. The webhook
+	// URLs are secret and are looked up by this name in the file passed to
+	// crier via --googlechat-webhook-file.
+	Space string `json:"space,omitempty"`
+	// ReportTemplate is a Go text/template rendered against the ProwJob and
+	// used as the text of the card.
+	ReportTemplate string `json:"report_template,omitempty"`
+	// ThreadKeyTemplate is an optional Go text/template rendered against the
+	// ProwJob. Messages with the same thread key are posted as replies in
+	// the same thread, e.g. `{{.Spec.Job}}` groups all runs of a job.
+	// Messages are posted as new threads if unset.
+	ThreadKeyTemplate string `json:"thread_key_template,omitempty"`
+}
+
+// GoogleChatReporterConfigs represents the config for the Google Chat reporter(s).
+// Use `org/repo`, `org` or `*` as key and an `GoogleChatReporter` struct as value.
+type GoogleChatReporterConfigs map[string]GoogleChatReporter
+
+func (cfg GoogleChatReporterConfigs) GetGoogleChatReporter(refs *prowapi.Refs) GoogleChatReporter {
+	if refs == nil {
+		return cfg["*"]
+	}
+
+	if googleChat, ok := cfg[fmt.Sprintf("%s/%s", refs.Org, refs.Repo)]; ok {
+		return googleChat
+	}
+
+	if googleChat, ok := cfg[refs.Org]; ok {
+		return googleChat
+	}
+
+	return cfg["*"]
+}
+
+func (cfg *GoogleChatReporter) DefaultAndValidate() error {
+	// Default ReportTemplate.
+	if cfg.ReportTemplate == "" {
+		cfg.ReportTemplate = `Job {{.Spec.Job}} of type {{.Spec.Type}} ended with state {{.Status.State}}.`
+	}
+
+	if cfg.Space == "" {
+		return errors.New("space must be set")
+	}
+
+	// Validate ReportTemplate.
+	tmpl, err := template.New("").Parse(cfg.ReportTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+	if err := tmpl.Execute(&bytes.Buffer{}, &prowapi.ProwJob{}); err != nil {
+		return fmt.Errorf("failed to execute report_template: %w", err)
+	}
+
+	// Validate ThreadKeyTemplate.
+	if cfg.ThreadKeyTemplate != "" {
+		tmpl, err := template.New("").Parse(cfg.ThreadKeyTemplate)
+		if err != nil {
+			return fmt.Errorf("failed to parse thread_key_template: %w", err)
+		}
+		if err := tmpl.Execute(&bytes.Buffer{}, &prowapi.ProwJob{}); err != nil {
+			return fmt.Errorf("failed to execute thread_key_template: %w", err)
 		}
-		cfg[orgOrRepo] = slackReporter
 	}
 
-	return utilerrors.NewAggregate(errs)
+	return nil
 }
 
-func (cfg SlackReporterConfigs) GetSlackReporter(refs *prowapi.Refs) SlackReporter {
+// BitbucketReporter represents the config for the Bitbucket Server reporter.
+type BitbucketReporter struct {
+	JobTypesToReport []prowapi.ProwJobType `json:"job_types_to_report,omitempty"`
+	// Server is the base URL of the Bitbucket Server instance the build
+	// statuses are posted to, e.g. `https://bitbucket.example.com`. The
+	// access token is passed to crier via --bitbucket-token-file.
+	Server string `json:"server,omitempty"`
+}
+
+// BitbucketReporterConfigs represents the config for the Bitbucket Server reporter(s).
+// Use `org/repo`, `org` or `*` as key and a `BitbucketReporter` struct as value.
+type BitbucketReporterConfigs map[string]BitbucketReporter
+
+func (cfg BitbucketReporterConfigs) GetBitbucketReporter(refs *prowapi.Refs) BitbucketReporter {
 	if refs == nil {
 		return cfg["*"]
 	}
 
-	if slack, ok := cfg[fmt.Sprintf("%s/%s", refs.Org, refs.Repo)]; ok {
-		return slack
+	if bitbucket, ok := cfg[fmt.Sprintf("%s/%s", refs.Org, refs.Repo)]; ok {
+		return bitbucket
 	}
 
-	if slack, ok := cfg[refs.Org]; ok {
-		return slack
+	if bitbucket, ok := cfg[refs.Org]; ok {
+		return bitbucket
 	}
 
 	return cfg["*"]
 }
 
-func (cfg SlackReporterConfigs) HasGlobalConfig() bool {
-	_, exists := cfg["*"]
-	return exists
+func (cfg *BitbucketReporter) DefaultAndValidate() error {
+	// Like the GitHub reporter, report the jobs that run against commits by default.
+	if len(cfg.JobTypesToReport) == 0 {
+		cfg.JobTypesToReport = []prowapi.ProwJobType{prowapi.PresubmitJob, prowapi.PostsubmitJob}
+	}
+
+	if cfg.Server == "" {
+		return errors.New("server must be set")
+	}
+	u, err := url.Parse(cfg.Server)
+	if err != nil {
+		return fmt.Errorf("failed to parse server: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("server %q must be an absolute http(s) URL", cfg.Server)
+	}
+	cfg.Server = strings.TrimSuffix(cfg.Server, "/")
+
+	return nil
 }
 
-func (cfg *SlackReporter) DefaultAndValidate() error {
-	// Default ReportTemplate.
-	if cfg.ReportTemplate == "" {
-		cfg.ReportTemplate = `Job {{.Spec.Job}} of type {{.Spec.Type}} ended with state {{.Status.State}}. <{{.Status.URL}}|View logs>`
+// DefaultGitLabServer is the GitLab instance the GitLab reporter reports to
+// if its config doesn't set one.
+const DefaultGitLabServer = "https://gitlab.com"
+
+// GitLabReporter represents the config for the GitLab reporter.
+type GitLabReporter struct {
+	JobTypesToReport []prowapi.ProwJobType `json:"job_types_to_report,omitempty"`
+	// Server is the base URL of the GitLab instance the commit statuses are
+	// posted to. Defaults to `https://gitlab.com`. The access token is
+	// passed to crier via --gitlab-token-file.
+	Server string `json:"server,omitempty"`
+	// CommentOnFailure additionally comments on the merge request of
+	// presubmits that ended in the failure or error state.
+	CommentOnFailure bool `json:"comment_on_failure,omitempty"`
+}
+
+// GitLabReporterConfigs represents the config for the GitLab reporter(s).
+// Use `org/repo`, `org` or `*` as key and a `GitLabReporter` struct as value.
+type GitLabReporterConfigs map[string]GitLabReporter
+
+func (cfg GitLabReporterConfigs) GetGitLabReporter(refs *prowapi.Refs) GitLabReporter {
+	if refs == nil {
+		return cfg["*"]
 	}
 
-	if cfg.Channel == "" {
-		return errors.New("channel must be set")
+	if gitlab, ok := cfg[fmt.Sprintf("%s/%s", refs.Org, refs.Repo)]; ok {
+		return gitlab
 	}
 
-	// Validate ReportTemplate.
-	tmpl, err := template.New("").Parse(cfg.ReportTemplate)
+	if gitlab, ok := cfg[refs.Org]; ok {
+		return gitlab
+	}
+
+	return cfg["*"]
+}
+
+func (cfg *GitLabReporter) DefaultAndValidate() error {
+	// Like the GitHub reporter, report the jobs that run against commits by default.
+	if len(cfg.JobTypesToReport) == 0 {
+		cfg.JobTypesToReport = []prowapi.ProwJobType{prowapi.PresubmitJob, prowapi.PostsubmitJob}
+	}
+
+	if cfg.Server == "" {
+		cfg.Server = DefaultGitLabServer
+	}
+	u, err := url.Parse(cfg.Server)
 	if err != nil {
-		return fmt.Errorf("failed to parse template: %w", err)
+		return fmt.Errorf("failed to parse server: %w", err)
 	}
-	if err := tmpl.Execute(&bytes.Buffer{}, &prowapi.ProwJob{}); err != nil {
-		return fmt.Errorf("failed to execute report_template: %w", err)
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("server %q must be an absolute http(s) URL", cfg.Server)
 	}
+	cfg.Server = strings.TrimSuffix(cfg.Server, "/")
 
 	return nil
 }
 
-// DingTalkReporter represents the config for the DingTalk reporter. The token can be overridden
-// on the job via the .reporter_config.ding_talk.token property.
-type DingTalkReporter struct {
-	JobTypesToReport               []prowapi.ProwJobType `json:"job_types_to_report,omitempty"`
-	prowapi.DingTalkReporterConfig `json:",inline"`
-}
+// Kafka SASL mechanisms supported by the Kafka reporter.
+const (
+	KafkaSASLPlain       = "PLAIN"
+	KafkaSASLScramSHA256 = "SCRAM-SHA-256"
+	KafkaSASLScramSHA512 = "SCRAM-SHA-512"
+)
 
-// DingTalkReporterConfigs represents the config for the Slack reporter(s).
-// Use `org/repo`, `org` or `*` as key and an `DingTalkReporter` struct as value.
-type DingTalkReporterConfigs map[string]DingTalkReporter
+// KafkaReporter represents the config for the Kafka reporter.
+type KafkaReporter struct {
+	JobTypesToReport  []prowapi.ProwJobType  `json:"job_types_to_report,omitempty"`
+	JobStatesToReport []prowapi.ProwJobState `json:"job_states_to_report,omitempty"`
+	// Brokers are the host:port addresses of the brokers used to discover
+	// the cluster.
+	Brokers []string `json:"brokers,omitempty"`
+	// Topic is the topic the messages are produced to.
+	Topic string `json:"topic,omitempty"`
+	// TLS connects to the brokers with TLS. The certificates of the brokers
+	// are verified with the CA file passed to crier via --kafka-ca-file, or
+	// the system cert pool if unset.
+	TLS bool `json:"tls,omitempty"`
+	// SASLMechanism is the SASL mechanism used to authenticate with the
+	// brokers, one of PLAIN, SCRAM-SHA-256 and SCRAM-SHA-512, with the
+	// credentials of the file passed to crier via --kafka-credentials-file.
+	// No authentication is done if unset.
+	SASLMechanism string `json:"sasl_mechanism,omitempty"`
+}
+
+// KafkaReporterConfigs represents the config for the Kafka reporter(s).
+// Use `org/repo`, `org` or `*` as key and a `KafkaReporter` struct as value.
+type KafkaReporterConfigs map[string]KafkaReporter
+
+func (cfg KafkaReporterConfigs) GetKafkaReporter(refs *prowapi.Refs) KafkaReporter {
+	if refs == nil {
+		return cfg["*"]
+	}
 
-func (cfg DingTalkReporterConfigs) mergeFrom(additional *DingTalkReporterConfigs) error {
-	if additional == nil {
-		return nil
+	if kafka, ok := cfg[fmt.Sprintf("%s/%s", refs.Org, refs.Repo)]; ok {
+		return kafka
 	}
 
-	var errs []error
-	for orgOrRepo, dingTalkReporter := range *additional {
-		if _, alreadyConfigured := cfg[orgOrRepo]; alreadyConfigured {
-			errs = append(errs, fmt.Errorf("config for org or repo %s passed more than once", orgOrRepo))
-			continue
+	if kafka, ok := cfg[refs.Org]; ok {
+		return kafka
+	}
+
+	return cfg["*"]
+}
+
+func (cfg *KafkaReporter) DefaultAndValidate() error {
+	// Like the Pub/Sub reporter, report every transition of every job by default.
+	if len(cfg.JobTypesToReport) == 0 {
+		cfg.JobTypesToReport = []prowapi.ProwJobType{prowapi.PresubmitJob, prowapi.PostsubmitJob, prowapi.PeriodicJob, prowapi.BatchJob}
+	}
+	if len(cfg.JobStatesToReport) == 0 {
+		cfg.JobStatesToReport = prowapi.GetAllProwJobStates()
+	}
+
+	if len(cfg.Brokers) == 0 {
+		return errors.New("brokers must be set")
+	}
+	for _, broker := range cfg.Brokers {
+		if _, port, err := net.SplitHostPort(broker); err != nil || port == "" {
+			return fmt.Errorf("broker %q must be a host:port address", broker)
 		}
-		cfg[orgOrRepo] = dingTalkReporter
+	}
+	if cfg.Topic == "" {
+		return errors.New("topic must be set")
+	}
+	switch cfg.SASLMechanism {
+	case "", KafkaSASLPlain, KafkaSASLScramSHA256, KafkaSASLScramSHA512:
+	default:
+		return fmt.Errorf("sasl_mechanism %q must be one of %s, %s and %s", cfg.SASLMechanism, KafkaSASLPlain, KafkaSASLScramSHA256, KafkaSASLScramSHA512)
 	}
 
-	return utilerrors.NewAggregate(errs)
+	return nil
 }
 
-func (cfg DingTalkReporterConfigs) GetDingTalkReporter(refs *prowapi.Refs) DingTalkReporter {
+// DatadogReporter represents the config for the Datadog reporter.
+type DatadogReporter struct {
+	// JobTypesToReport defaults to all job types.
+	JobTypesToReport []prowapi.ProwJobType `json:"job_types_to_report,omitempty"`
+	// JobStatesToReport defaults to the states of completed jobs.
+	JobStatesToReport []prowapi.ProwJobState `json:"job_states_to_report,omitempty"`
+	// Site is the Datadog site the events are posted to, e.g.
+	// `datadoghq.eu`. Defaults to datadoghq.com.
+	Site string `json:"site,omitempty"`
+	// Tags are added to the repo, job, type and state tags of every event,
+	// e.g. `env:ci`.
+	Tags []string `json:"tags,omitempty"`
+	// MaxRetries is how many times a request that failed with a server
+	// error or was rate limited is retried with exponential backoff.
+	// Defaults to 3.
+	MaxRetries int `json:"max_retries,omitempty"`
+}
+
+// DatadogReporterConfigs represents the config for the Datadog reporter(s).
+// Use `org/repo`, `org` or `*` as key and a `DatadogReporter` struct as value.
+type DatadogReporterConfigs map[string]DatadogReporter
+
+func (cfg DatadogReporterConfigs) GetDatadogReporter(refs *prowapi.Refs) DatadogReporter {
 	if refs == nil {
 		return cfg["*"]
 	}
 
-	if dingtalk, ok := cfg[fmt.Sprintf("%s/%s", refs.Org, refs.Repo)]; ok {
-		return dingtalk
+	if datadog, ok := cfg[fmt.Sprintf("%s/%s", refs.Org, refs.Repo)]; ok {
+		return datadog
 	}
 
-	if dingtalk, ok := cfg[refs.Org]; ok {
-		return dingtalk
+	if datadog, ok := cfg[refs.Org]; ok {
+		return datadog
 	}
 
 	return cfg["*"]
 }
 
-func (cfg DingTalkReporterConfigs) HasGlobalConfig() bool {
-	_, exists := cfg["*"]
-	return exists
+func (cfg *DatadogReporter) DefaultAndValidate() error {
+	if len(cfg.JobTypesToReport) == 0 {
+		cfg.JobTypesToReport = []prowapi.ProwJobType{prowapi.PresubmitJob, prowapi.PostsubmitJob, prowapi.PeriodicJob, prowapi.BatchJob}
+	}
+	if len(cfg.JobStatesToReport) == 0 {
+		cfg.JobStatesToReport = []prowapi.ProwJobState{prowapi.SuccessState, prowapi.FailureState, prowapi.ErrorState, prowapi.AbortedState}
+	}
+	if cfg.Site == "" {
+		cfg.Site = "datadoghq.com"
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = 3
+	}
+
+	if strings.Contains(cfg.Site, "/") {
+		return fmt.Errorf("site %q must be a host name like datadoghq.com, without scheme or path", cfg.Site)
+	}
+	if cfg.MaxRetries < 0 {
+		return fmt.Errorf("max_retries must not be negative, got %d", cfg.MaxRetries)
+	}
+	for _, tag := range cfg.Tags {
+		if tag == "" {
+			return errors.New("tags must not be empty")
+		}
+	}
+
+	return nil
 }
 
-func (cfg *DingTalkReporter) DefaultAndValidate() error {
-	// Default ReportTemplate.
-	if cfg.ReportTemplate == "" {
-		cfg.ReportTemplate = `{{ $repo := "" }}{{with .Spec.Refs}}{{$repo = .Repo}}{{end}}{{if eq $repo ""}}{{if .Spec.ExtraRefs}}{{with index .Spec.ExtraRefs 0}}{{$repo = .Repo}}{{end}}{{end}}{{end}}## Repo: {{ $repo }}
----
-- Job: {{.Spec.Job}}
-- Type: {{.Spec.Type}}
-- State: {{if eq .Status.State "triggered"}}<font color="orange">**{{.Status.State}}**</font>{{end}}{{if eq .Status.State "pending"}}<font color="yellow">**{{.Status.State}}**</font>{{end}}{{if eq .Status.State "success"}}<font color="green">**{{.Status.State}}**</font>{{end}}{{if eq .Status.State "failure"}}<font color="red">**{{.Status.State}}**</font>{{end}}{{if eq .Status.State "aborted"}}<font color="gray">**{{.Status.State}}**</font>{{end}}{{if eq .Status.State "error"}}<font color="red">**{{.Status.State}}**</font>{{end}}
-- Log: [View logs]({{.Status.URL}})`
+// RedisReporter represents the config for the Redis reporter.
+type RedisReporter struct {
+	JobTypesToReport  []prowapi.ProwJobType  `json:"job_types_to_report,omitempty"`
+	JobStatesToReport []prowapi.ProwJobState `json:"job_states_to_report,omitempty"`
+	// Address is the host:port address of the Redis server.
+	Address string `json:"address,omitempty"`
+	// DB is the number of the database the stream is in. Defaults to 0.
+	DB int `json:"db,omitempty"`
+	// Stream is the key of the stream the entries are added to. It is
+	// created if it doesn't exist.
+	Stream string `json:"stream,omitempty"`
+	// MaxLen trims the stream to about this many entries when adding one.
+	// The stream isn't trimmed if unset.
+	MaxLen int64 `json:"max_len,omitempty"`
+	// TLS connects to the server with TLS.
+	TLS bool `json:"tls,omitempty"`
+}
+
+// RedisReporterConfigs represents the config for the Redis reporter(s).
+// Use `org/repo`, `org` or `*` as key and a `RedisReporter` struct as value.
+type RedisReporterConfigs map[string]RedisReporter
+
+func (cfg RedisReporterConfigs) GetRedisReporter(refs *prowapi.Refs) RedisReporter {
+	if refs == nil {
+		return cfg["*"]
 	}
 
-	if cfg.Token == "" {
-		return errors.New("token must be set")
+	if redis, ok := cfg[fmt.Sprintf("%s/%s", refs.Org, refs.Repo)]; ok {
+		return redis
 	}
 
-	// Validate ReportTemplate.
-	tmpl, err := template.New("").Parse(cfg.ReportTemplate)
-	if err != nil {
-		return fmt.Errorf("failed to parse template: %w", err)
+	if redis, ok := cfg[refs.Org]; ok {
+		return redis
 	}
-	if err := tmpl.Execute(&bytes.Buffer{}, &prowapi.ProwJob{}); err != nil {
-		return fmt.Errorf("failed to execute report_template: %w", err)
+
+	return cfg["*"]
+}
+
+func (cfg *RedisReporter) DefaultAndValidate() error {
+	// Like the Kafka reporter, report every transition of every job by default.
+	if len(cfg.JobTypesToReport) == 0 {
+		cfg.JobTypesToReport = []prowapi.ProwJobType{prowapi.PresubmitJob, prowapi.PostsubmitJob, prowapi.PeriodicJob, prowapi.BatchJob}
+	}
+	if len(cfg.JobStatesToReport) == 0 {
+		cfg.JobStatesToReport = prowapi.GetAllProwJobStates()
+	}
+
+	if cfg.Address == "" {
+		return errors.New("address must be set")
+	}
+	if _, port, err := net.SplitHostPort(cfg.Address); err != nil || port == "" {
+		return fmt.Errorf("address %q must be a host:port address", cfg.Address)
+	}
+	if cfg.Stream == "" {
+		return errors.New("stream must be set")
+	}
+	if cfg.DB < 0 {
+		return fmt.Errorf("db must not be negative, got %d", cfg.DB)
+	}
+	if cfg.MaxLen < 0 {
+		return fmt.Errorf("max_len must not be negative, got %d", cfg.MaxLen)
 	}
 
 	return nil
@@ -2384,10 +4432,18 @@ func (c *Config) validateComponentConfig() error {
 	}
 
 	if c.SlackReporterConfigs != nil {
-		for k, config := range c.SlackReporterConfigs {
+		// Orgs and repos inherit what they don't set, so they are validated
+		// merged over the configs they inherit from.
+		templates := map[string]string{}
+		for k := range c.SlackReporterConfigs {
+			config := c.SlackReporterConfigs.merged(k)
 			if err := config.DefaultAndValidate(); err != nil {
 				return fmt.Errorf("failed to validate slackreporter config: %w", err)
 			}
+			templates[k] = config.ReportTemplate
+		}
+		for k, config := range c.SlackReporterConfigs {
+			config.ReportTemplate = templates[k]
 			c.SlackReporterConfigs[k] = config
 		}
 	}
@@ -2401,6 +4457,176 @@ func (c *Config) validateComponentConfig() error {
 		}
 	}
 
+	if c.TeamsReporterConfigs != nil {
+		for k, config := range c.TeamsReporterConfigs {
+			if err := config.DefaultAndValidate(); err != nil {
+				return fmt.Errorf("failed to validate teamsreporter config: %w", err)
+			}
+			c.TeamsReporterConfigs[k] = config
+		}
+	}
+
+	if c.DiscordReporterConfigs != nil {
+		for k, config := range c.DiscordReporterConfigs {
+			if err := config.DefaultAndValidate(); err != nil {
+				return fmt.Errorf("failed to validate discordreporter config: %w", err)
+			}
+			c.DiscordReporterConfigs[k] = config
+		}
+	}
+
+	if c.WebhookReporterConfigs != nil {
+		for k, config := range c.WebhookReporterConfigs {
+			if err := config.DefaultAndValidate(); err != nil {
+				return fmt.Errorf("failed to validate webhookreporter config: %w", err)
+			}
+			c.WebhookReporterConfigs[k] = config
+		}
+	}
+
+	if c.EmailReporterConfigs != nil {
+		for k, config := range c.EmailReporterConfigs {
+			if err := config.DefaultAndValidate(); err != nil {
+				return fmt.Errorf("failed to validate emailreporter config: %w", err)
+			}
+			c.EmailReporterConfigs[k] = config
+		}
+	}
+
+	if c.JiraReporterConfigs != nil {
+		for k, config := range c.JiraReporterConfigs {
+			if err := config.DefaultAndValidate(); err != nil {
+				return fmt.Errorf("failed to validate jirareporter config: %w", err)
+			}
+			c.JiraReporterConfigs[k] = config
+		}
+	}
+
+	for reporter, workers := range c.Crier.Workers {
+		if workers < 1 {
+			return fmt.Errorf("crier.workers[%s] must be at least 1, got %d", reporter, workers)
+		}
+	}
+	for reporter, timeout := range c.Crier.ReportTimeouts {
+		if timeout.Duration <= 0 {
+			return fmt.Errorf("crier.report_timeouts[%s] must be positive, got %s", reporter, timeout.Duration)
+		}
+	}
+	if err := c.Crier.validateGCSPathTemplate(); err != nil {
+		return err
+	}
+	if err := c.Crier.validateGCSObjects(); err != nil {
+		return err
+	}
+	if err := c.Crier.validateResultStoreProperties(); err != nil {
+		return err
+	}
+	if _, err := path.Match(c.Crier.JUnitSummaryGlob, ""); err != nil {
+		return fmt.Errorf("crier.junit_summary_glob: %w", err)
+	}
+	if err := c.Crier.validateReporterEnablement(); err != nil {
+		return err
+	}
+	if err := c.Crier.validatePubSubReporter(); err != nil {
+		return err
+	}
+	if err := c.Crier.validateSQLReporter(); err != nil {
+		return err
+	}
+	if err := c.Crier.validateSkipRules(); err != nil {
+		return err
+	}
+
+	if c.PagerDutyReporterConfigs != nil {
+		for k, config := range c.PagerDutyReporterConfigs {
+			if err := config.DefaultAndValidate(); err != nil {
+				return fmt.Errorf("failed to validate pagerdutyreporter config: %w", err)
+			}
+			c.PagerDutyReporterConfigs[k] = config
+		}
+	}
+
+	if c.TelegramReporterConfigs != nil {
+		for k, config := range c.TelegramReporterConfigs {
+			if err := config.DefaultAndValidate(); err != nil {
+				return fmt.Errorf("failed to validate telegramreporter config: %w", err)
+			}
+			c.TelegramReporterConfigs[k] = config
+		}
+	}
+
+	if c.MatrixReporterConfigs != nil {
+		for k, config := range c.MatrixReporterConfigs {
+			if err := config.DefaultAndValidate(); err != nil {
+				return fmt.Errorf("failed to validate matrixreporter config: %w", err)
+			}
+			c.MatrixReporterConfigs[k] = config
+		}
+	}
+
+	if c.SNSReporterConfigs != nil {
+		for k, config := range c.SNSReporterConfigs {
+			if err := config.DefaultAndValidate(); err != nil {
+				return fmt.Errorf("failed to validate snsreporter config: %w", err)
+			}
+			c.SNSReporterConfigs[k] = config
+		}
+	}
+
+	if c.GoogleChatReporterConfigs != nil {
+		for k, config := range c.GoogleChatReporterConfigs {
+			if err := config.DefaultAndValidate(); err != nil {
+				return fmt.Errorf("failed to validate googlechatreporter config: %w", err)
+			}
+			c.GoogleChatReporterConfigs[k] = config
+		}
+	}
+
+	if c.BitbucketReporterConfigs != nil {
+		for k, config := range c.BitbucketReporterConfigs {
+			if err := config.DefaultAndValidate(); err != nil {
+				return fmt.Errorf("failed to validate bitbucketreporter config: %w", err)
+			}
+			c.BitbucketReporterConfigs[k] = config
+		}
+	}
+
+	if c.GitLabReporterConfigs != nil {
+		for k, config := range c.GitLabReporterConfigs {
+			if err := config.DefaultAndValidate(); err != nil {
+				return fmt.Errorf("failed to validate gitlabreporter config: %w", err)
+			}
+			c.GitLabReporterConfigs[k] = config
+		}
+	}
+
+	if c.KafkaReporterConfigs != nil {
+		for k, config := range c.KafkaReporterConfigs {
+			if err := config.DefaultAndValidate(); err != nil {
+				return fmt.Errorf("failed to validate kafkareporter config: %w", err)
+			}
+			c.KafkaReporterConfigs[k] = config
+		}
+	}
+
+	if c.DatadogReporterConfigs != nil {
+		for k, config := range c.DatadogReporterConfigs {
+			if err := config.DefaultAndValidate(); err != nil {
+				return fmt.Errorf("failed to validate datadogreporter config: %w", err)
+			}
+			c.DatadogReporterConfigs[k] = config
+		}
+	}
+
+	if c.RedisReporterConfigs != nil {
+		for k, config := range c.RedisReporterConfigs {
+			if err := config.DefaultAndValidate(); err != nil {
+				return fmt.Errorf("failed to validate redisreporter config: %w", err)
+			}
+			c.RedisReporterConfigs[k] = config
+		}
+	}
+
 	if err := c.Deck.FinalizeDefaultRerunAuthConfigs(); err != nil {
 		return err
 	}
@@ -2472,6 +4698,9 @@ func (c Config) validateJobBase(v JobBase, jobType prowapi.ProwJobType) error {
 	if err := validateJobQueueName(v.JobQueueName, validJobQueueNames); err != nil {
 		return err
 	}
+	if err := validateReporterConfig(v.ReporterConfig); err != nil {
+		return err
+	}
 	if v.Spec == nil || len(v.Spec.Containers) == 0 {
 		return nil // jenkins jobs have no spec.
 	}
@@ -2764,6 +4993,24 @@ func parseProwConfig(c *Config) error {
 			return fmt.Errorf("invalid job_types_to_report: %v", t)
 		}
 	}
+	if c.GitHubReporter.CheckRunLogLines < 0 {
+		return fmt.Errorf("github_reporter.check_run_log_lines must not be negative, got %d", c.GitHubReporter.CheckRunLogLines)
+	}
+	if _, err := path.Match(c.GitHubReporter.CheckRunJUnitGlob, ""); err != nil {
+		return fmt.Errorf("github_reporter.check_run_junit_glob: %w", err)
+	}
+	if expiry := c.GitHubReporter.SignedURLExpiry; expiry != nil && (expiry.Duration <= 0 || expiry.Duration > MaxSignedURLExpiry) {
+		return fmt.Errorf("github_reporter.signed_url_expiry must be positive and at most %s, got %s", MaxSignedURLExpiry, expiry.Duration)
+	}
+	if c.GitHubReporter.AppendClusterToContext && c.GitHubReporter.ClusterContextSeparator == "" {
+		c.GitHubReporter.ClusterContextSeparator = "@"
+	}
+	if c.GitHubReporter.RollupOnly && len(c.GitHubReporter.RollupRepos) == 0 {
+		return errors.New("github_reporter.rollup_only requires github_reporter.rollup_repos to be set")
+	}
+	if len(c.GitHubReporter.RollupContext) > statusContextMaxLen {
+		return fmt.Errorf("github_reporter.rollup_context must be at most %d characters long, got %d", statusContextMaxLen, len(c.GitHubReporter.RollupContext))
+	}
 
 	// jenkins operator controller template functions.
 	// reference:
@@ -3130,6 +5377,23 @@ func validateJobQueueName(name string, validNames sets.Set[string]) error {
 	return nil
 }
 
+// validateReporterConfig makes sure a job level Slack report template can be
+// rendered, so a broken template is caught at config load time rather than
+// when crier tries to report the job.
+func validateReporterConfig(rc *prowapi.ReporterConfig) error {
+	if rc == nil || rc.Slack == nil || rc.Slack.ReportTemplate == "" {
+		return nil
+	}
+	tmpl, err := template.New("").Parse(rc.Slack.ReportTemplate)
+	if err != nil {
+		return fmt.Errorf("reporter_config.slack.report_template: failed to parse template: %w", err)
+	}
+	if err := tmpl.Execute(&bytes.Buffer{}, &prowapi.ProwJob{}); err != nil {
+		return fmt.Errorf("reporter_config.slack.report_template: failed to execute template: %w", err)
+	}
+	return nil
+}
+
 func validateAgent(v JobBase, podNamespace string) error {
 	k := string(prowapi.KubernetesAgent)
 	j := string(prowapi.JenkinsAgent)
@@ -3760,6 +6024,7 @@ const (
 	contextDescriptionBaseSHADelimiter           = " BaseSHA:"
 	contextDescriptionBaseSHADelimiterDeprecated = " Basesha:"
 	contextDescriptionMaxLen                     = 140 // https://developer.github.com/v3/repos/deployments/#parameters-2
+	statusContextMaxLen                          = 255 // https://docs.github.com/en/rest/commits/statuses#create-a-commit-status
 	elide                                        = " ... "
 )
 
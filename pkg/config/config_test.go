@@ -1266,6 +1266,96 @@ gerrit:
 				},
 			},
 		},
+		{
+			name:        "report-label",
+			expectError: false,
+			rawConfig: `
+gerrit:
+  org_repos_config:
+  - org: org-a
+    repos:
+    - repo-b
+    report_label:
+      name: Prow-Verified
+      values:
+        failure: -2
+`,
+			expected: Gerrit{
+				TickInterval: &metav1.Duration{Duration: time.Minute},
+				RateLimit:    5,
+				OrgReposConfig: &GerritOrgRepoConfigs{
+					{
+						Org:   "org-a",
+						Repos: []string{"repo-b"},
+						ReportLabel: &GerritReportLabel{
+							Name:   "Prow-Verified",
+							Values: map[prowapi.ProwJobState]int{prowapi.FailureState: -2},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:        "report-label-invalid-state",
+			expectError: true,
+			rawConfig: `
+gerrit:
+  org_repos_config:
+  - org: org-a
+    repos:
+    - repo-b
+    report_label:
+      values:
+        aborted: 0
+`,
+		},
+		{
+			name:        "message",
+			expectError: false,
+			rawConfig: `
+gerrit:
+  org_repos_config:
+  - org: org-a
+    repos:
+    - repo-b
+    message: "Logs: {{.Status.URL}}"
+`,
+			expected: Gerrit{
+				TickInterval: &metav1.Duration{Duration: time.Minute},
+				RateLimit:    5,
+				OrgReposConfig: &GerritOrgRepoConfigs{
+					{
+						Org:     "org-a",
+						Repos:   []string{"repo-b"},
+						Message: "Logs: {{.Status.URL}}",
+					},
+				},
+			},
+		},
+		{
+			name:        "message-invalid-template",
+			expectError: true,
+			rawConfig: `
+gerrit:
+  org_repos_config:
+  - org: org-a
+    repos:
+    - repo-b
+    message: "Logs: {{.Status.URL"
+`,
+		},
+		{
+			name:        "message-unknown-field",
+			expectError: true,
+			rawConfig: `
+gerrit:
+  org_repos_config:
+  - org: org-a
+    repos:
+    - repo-b
+    message: "Logs: {{.Status.Link}}"
+`,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -1279,9 +1369,12 @@ gerrit:
 			}
 
 			cfg, err := Load(prowConfig, "", nil, "")
-			if tc.expectError && err == nil {
-				t.Errorf("tc %s: Expect error, but got nil", tc.name)
-			} else if !tc.expectError && err != nil {
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("tc %s: Expect error, but got nil", tc.name)
+				}
+				return
+			} else if err != nil {
 				t.Fatalf("tc %s: Expect no error, but got error %v", tc.name, err)
 			}
 
@@ -2174,6 +2267,36 @@ func TestValidateJobBase(t *testing.T) {
 			},
 			pass: false,
 		},
+		{
+			name: "valid slack report template",
+			base: JobBase{
+				Name: "name",
+				ReporterConfig: &prowapi.ReporterConfig{
+					Slack: &prowapi.SlackReporterConfig{ReportTemplate: "{{.Spec.Job}} {{.Status.State}} {{.Status.URL}}"},
+				},
+			},
+			pass: true,
+		},
+		{
+			name: "unparsable slack report template",
+			base: JobBase{
+				Name: "name",
+				ReporterConfig: &prowapi.ReporterConfig{
+					Slack: &prowapi.SlackReporterConfig{ReportTemplate: "{{ if .Spec.Job }}"},
+				},
+			},
+			pass: false,
+		},
+		{
+			name: "slack report template accessing invalid field",
+			base: JobBase{
+				Name: "name",
+				ReporterConfig: &prowapi.ReporterConfig{
+					Slack: &prowapi.SlackReporterConfig{ReportTemplate: "{{.Undef}}"},
+				},
+			},
+			pass: false,
+		},
 	}
 
 	for _, tc := range cases {
@@ -2433,6 +2556,72 @@ func TestGerritAllRepos(t *testing.T) {
 	}
 }
 
+func TestGerritReportLabel(t *testing.T) {
+	label := &GerritReportLabel{Name: "Prow-Verified", Values: map[prowapi.ProwJobState]int{prowapi.FailureState: -2}}
+	configs := &GerritOrgRepoConfigs{
+		{Org: "https://gerrit-a", Repos: []string{"repo-1"}},
+		{Org: "https://gerrit-a", Repos: []string{"repo-2"}, ReportLabel: label},
+	}
+	tests := []struct {
+		name     string
+		in       *GerritOrgRepoConfigs
+		instance string
+		repo     string
+		want     *GerritReportLabel
+	}{
+		{
+			name:     "configured",
+			in:       configs,
+			instance: "https://gerrit-a",
+			repo:     "repo-2",
+			want:     label,
+		},
+		{
+			name:     "repo without label",
+			in:       configs,
+			instance: "https://gerrit-a",
+			repo:     "repo-1",
+		},
+		{
+			name:     "other instance",
+			in:       configs,
+			instance: "https://gerrit-b",
+			repo:     "repo-2",
+		},
+		{
+			name:     "nil",
+			instance: "https://gerrit-a",
+			repo:     "repo-2",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, tc.in.ReportLabel(tc.instance, tc.repo)); diff != "" {
+				t.Errorf("output mismatch. got(+), want(-):\n%s", diff)
+			}
+		})
+	}
+
+	messages := &GerritOrgRepoConfigs{
+		{Org: "https://gerrit-a", Repos: []string{"repo-1"}},
+		{Org: "https://gerrit-a", Repos: []string{"repo-2"}, Message: "Logs: {{.Status.URL}}"},
+	}
+	if got := messages.Message("https://gerrit-a", "repo-2"); got != "Logs: {{.Status.URL}}" {
+		t.Errorf("expected configured message, got %q", got)
+	}
+	if got := messages.Message("https://gerrit-a", "repo-1"); got != "" {
+		t.Errorf("expected no message for repo without one, got %q", got)
+	}
+
+	if got := label.Value(prowapi.SuccessState); got != 1 {
+		t.Errorf("expected default success value 1, got %d", got)
+	}
+	if got := label.Value(prowapi.FailureState); got != -2 {
+		t.Errorf("expected failure value -2, got %d", got)
+	}
+}
+
 func TestGerritOptOutHelpRepos(t *testing.T) {
 	tests := []struct {
 		name string
@@ -3917,6 +4106,54 @@ github_reporter:
 `,
 			expectTypes: []prowapi.ProwJobType{prowapi.PresubmitJob, prowapi.PostsubmitJob},
 		},
+		{
+			name: "reject negative check run log lines",
+			prowConfig: `
+github_reporter:
+  check_run_log_lines: -1
+`,
+			expectError: true,
+		},
+		{
+			name: "accept check run junit glob",
+			prowConfig: `
+github_reporter:
+  check_run_junit_glob: artifacts/junit*.xml
+`,
+			expectTypes: []prowapi.ProwJobType{prowapi.PresubmitJob, prowapi.PostsubmitJob},
+		},
+		{
+			name: "reject malformed check run junit glob",
+			prowConfig: `
+github_reporter:
+  check_run_junit_glob: artifacts/junit[.xml
+`,
+			expectError: true,
+		},
+		{
+			name: "accept signed url expiry",
+			prowConfig: `
+github_reporter:
+  signed_url_expiry: 24h
+`,
+			expectTypes: []prowapi.ProwJobType{prowapi.PresubmitJob, prowapi.PostsubmitJob},
+		},
+		{
+			name: "reject signed url expiry longer than 7 days",
+			prowConfig: `
+github_reporter:
+  signed_url_expiry: 192h
+`,
+			expectError: true,
+		},
+		{
+			name: "reject zero signed url expiry",
+			prowConfig: `
+github_reporter:
+  signed_url_expiry: 0s
+`,
+			expectError: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -3943,6 +4180,90 @@ github_reporter:
 	}
 }
 
+func TestGitHubReporterCheckRuns(t *testing.T) {
+	g := GitHubReporter{CheckRunRepos: []string{"org", "other/repo"}}
+	testCases := []struct {
+		org, repo string
+		expected  bool
+	}{
+		{org: "org", repo: "repo", expected: true},
+		{org: "other", repo: "repo", expected: true},
+		{org: "other", repo: "another", expected: false},
+		{org: "org2", repo: "repo", expected: false},
+	}
+	for _, tc := range testCases {
+		if actual := g.ReportsCheckRuns(tc.org, tc.repo); actual != tc.expected {
+			t.Errorf("%s/%s: expected %t, got %t", tc.org, tc.repo, tc.expected, actual)
+		}
+	}
+}
+
+func TestGitHubReporterStatusContext(t *testing.T) {
+	longContext := strings.Repeat("a", 300)
+	testCases := []struct {
+		name     string
+		reporter GitHubReporter
+		context  string
+		cluster  string
+		expected string
+	}{
+		{
+			name:     "cluster is not appended by default",
+			context:  "pull-test",
+			cluster:  "build01",
+			expected: "pull-test",
+		},
+		{
+			name:     "cluster is appended with the separator",
+			reporter: GitHubReporter{AppendClusterToContext: true, ClusterContextSeparator: "@"},
+			context:  "pull-test",
+			cluster:  "build01",
+			expected: "pull-test@build01",
+		},
+		{
+			name:     "empty cluster is not appended",
+			reporter: GitHubReporter{AppendClusterToContext: true, ClusterContextSeparator: "@"},
+			context:  "pull-test",
+			expected: "pull-test",
+		},
+		{
+			name:     "long context is truncated but keeps the cluster",
+			reporter: GitHubReporter{AppendClusterToContext: true, ClusterContextSeparator: " / "},
+			context:  longContext,
+			cluster:  "build01",
+			expected: longContext[:120] + elide + longContext[:120] + " / build01",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := tc.reporter.StatusContext(tc.context, tc.cluster)
+			if actual != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, actual)
+			}
+			if len(actual) > statusContextMaxLen {
+				t.Errorf("context of length %d exceeds the limit of %d", len(actual), statusContextMaxLen)
+			}
+		})
+	}
+}
+
+func TestGitHubReporterStatusContextUniqueAcrossClusters(t *testing.T) {
+	g := GitHubReporter{AppendClusterToContext: true, ClusterContextSeparator: "@"}
+	for _, context := range []string{"pull-test", strings.Repeat("a", 300)} {
+		seen := sets.New[string]()
+		for _, cluster := range []string{"default", "build01", "build02", strings.Repeat("b", 300)} {
+			actual := g.StatusContext(context, cluster)
+			if len(actual) > statusContextMaxLen {
+				t.Errorf("context of length %d exceeds the limit of %d", len(actual), statusContextMaxLen)
+			}
+			if seen.Has(actual) {
+				t.Errorf("context %q is reported for more than one cluster", actual)
+			}
+			seen.Insert(actual)
+		}
+	}
+}
+
 func TestRerunAuthConfigsGetRerunAuthConfig(t *testing.T) {
 	var testCases = []struct {
 		name     string
@@ -4886,85 +5207,1988 @@ func TestDingTalkReporterValidation(t *testing.T) {
 	}
 }
 
-func TestSlackReporterValidation(t *testing.T) {
+func TestTeamsReporterValidation(t *testing.T) {
 	testCases := []struct {
 		name            string
-		config          func() Config
+		config          TeamsReporterConfigs
 		successExpected bool
 	}{
 		{
-			name: "Valid config w/ wildcard slack_reporter_configs - no error",
-			config: func() Config {
-				slackCfg := map[string]SlackReporter{
-					"*": {
-						SlackReporterConfig: prowapi.SlackReporterConfig{
-							Channel: "my-channel",
-						},
-					},
-				}
-				return Config{
-					ProwConfig: ProwConfig{
-						SlackReporterConfigs: slackCfg,
-					},
-				}
+			name: "Valid config w/ wildcard teams_reporter_configs - no error",
+			config: TeamsReporterConfigs{
+				"*": {Channel: "oncall"},
 			},
 			successExpected: true,
 		},
 		{
-			name: "Valid config w/ org/repo slack_reporter_configs - no error",
-			config: func() Config {
-				slackCfg := map[string]SlackReporter{
-					"istio/proxy": {
-						SlackReporterConfig: prowapi.SlackReporterConfig{
-							Channel: "my-channel",
-						},
-					},
-				}
-				return Config{
-					ProwConfig: ProwConfig{
-						SlackReporterConfigs: slackCfg,
-					},
-				}
+			name: "Valid config w/ org/repo teams_reporter_configs - no error",
+			config: TeamsReporterConfigs{
+				"org/repo": {Channel: "oncall"},
 			},
 			successExpected: true,
 		},
 		{
-			name: "Valid config w/ repo slack_reporter_configs - no error",
-			config: func() Config {
-				slackCfg := map[string]SlackReporter{
-					"proxy": {
-						SlackReporterConfig: prowapi.SlackReporterConfig{
-							Channel: "my-channel",
-						},
-					},
-				}
-				return Config{
-					ProwConfig: ProwConfig{
-						SlackReporterConfigs: slackCfg,
-					},
-				}
+			name: "No channel w/ teams_reporter_configs - error",
+			config: TeamsReporterConfigs{
+				"*": {JobTypesToReport: []prowapi.ProwJobType{"presubmit"}},
 			},
+			successExpected: false,
+		},
+		{
+			name:            "Empty config - no error",
+			config:          TeamsReporterConfigs{},
 			successExpected: true,
 		},
 		{
-			name: "No channel w/ slack_reporter_configs - error",
-			config: func() Config {
-				slackCfg := map[string]SlackReporter{
-					"*": {
-						JobTypesToReport: []prowapi.ProwJobType{"presubmit"},
-					},
-				}
-				return Config{
-					ProwConfig: ProwConfig{
-						SlackReporterConfigs: slackCfg,
-					},
-				}
+			name: "Invalid template - error",
+			config: TeamsReporterConfigs{
+				"*": {Channel: "oncall", ReportTemplate: "{{ if .Spec.Name}}"},
 			},
 			successExpected: false,
 		},
-		{
-			name: "Empty config - no error",
-			config: func() Config {
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Config{ProwConfig: ProwConfig{TeamsReporterConfigs: tc.config}}
+			if err := cfg.validateComponentConfig(); (err == nil) != tc.successExpected {
+				t.Errorf("Expected success=%t but got err=%v", tc.successExpected, err)
+			}
+			if tc.successExpected {
+				for _, config := range cfg.TeamsReporterConfigs {
+					if config.ReportTemplate == "" {
+						t.Errorf("expected default ReportTemplate to be set")
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDiscordReporterValidation(t *testing.T) {
+	testCases := []struct {
+		name            string
+		config          DiscordReporterConfigs
+		successExpected bool
+	}{
+		{
+			name: "Valid config w/ wildcard discord_reporter_configs - no error",
+			config: DiscordReporterConfigs{
+				"*": {Channel: "oncall"},
+			},
+			successExpected: true,
+		},
+		{
+			name: "Valid config w/ org/repo discord_reporter_configs - no error",
+			config: DiscordReporterConfigs{
+				"org/repo": {Channel: "oncall"},
+			},
+			successExpected: true,
+		},
+		{
+			name: "No channel w/ discord_reporter_configs - error",
+			config: DiscordReporterConfigs{
+				"*": {JobTypesToReport: []prowapi.ProwJobType{"presubmit"}},
+			},
+			successExpected: false,
+		},
+		{
+			name:            "Empty config - no error",
+			config:          DiscordReporterConfigs{},
+			successExpected: true,
+		},
+		{
+			name: "Invalid template - error",
+			config: DiscordReporterConfigs{
+				"*": {Channel: "oncall", ReportTemplate: "{{ if .Spec.Name}}"},
+			},
+			successExpected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Config{ProwConfig: ProwConfig{DiscordReporterConfigs: tc.config}}
+			if err := cfg.validateComponentConfig(); (err == nil) != tc.successExpected {
+				t.Errorf("Expected success=%t but got err=%v", tc.successExpected, err)
+			}
+			if tc.successExpected {
+				for _, config := range cfg.DiscordReporterConfigs {
+					if config.ReportTemplate == "" {
+						t.Errorf("expected default ReportTemplate to be set")
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestWebhookReporterValidation(t *testing.T) {
+	testCases := []struct {
+		name            string
+		config          WebhookReporterConfigs
+		successExpected bool
+	}{
+		{
+			name: "Valid config w/ wildcard webhook_reporter_configs - no error",
+			config: WebhookReporterConfigs{
+				"*": {URL: "https://events.example.com/prow"},
+			},
+			successExpected: true,
+		},
+		{
+			name: "Valid config w/ fields and retries - no error",
+			config: WebhookReporterConfigs{
+				"org/repo": {URL: "http://events.example.com/prow", Fields: []string{"spec", "status"}, MaxRetries: 3},
+			},
+			successExpected: true,
+		},
+		{
+			name:            "Empty config - no error",
+			config:          WebhookReporterConfigs{},
+			successExpected: true,
+		},
+		{
+			name: "No url - error",
+			config: WebhookReporterConfigs{
+				"*": {JobTypesToReport: []prowapi.ProwJobType{"presubmit"}},
+			},
+			successExpected: false,
+		},
+		{
+			name: "Unsupported url scheme - error",
+			config: WebhookReporterConfigs{
+				"*": {URL: "ftp://events.example.com/prow"},
+			},
+			successExpected: false,
+		},
+		{
+			name: "Negative max_retries - error",
+			config: WebhookReporterConfigs{
+				"*": {URL: "https://events.example.com/prow", MaxRetries: -1},
+			},
+			successExpected: false,
+		},
+		{
+			name: "Unknown field - error",
+			config: WebhookReporterConfigs{
+				"*": {URL: "https://events.example.com/prow", Fields: []string{"secrets"}},
+			},
+			successExpected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Config{ProwConfig: ProwConfig{WebhookReporterConfigs: tc.config}}
+			if err := cfg.validateComponentConfig(); (err == nil) != tc.successExpected {
+				t.Errorf("Expected success=%t but got err=%v", tc.successExpected, err)
+			}
+			if tc.successExpected {
+				for _, config := range cfg.WebhookReporterConfigs {
+					if config.Timeout == nil {
+						t.Errorf("expected default Timeout to be set")
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEmailReporterValidation(t *testing.T) {
+	testCases := []struct {
+		name            string
+		config          EmailReporterConfigs
+		successExpected bool
+	}{
+		{
+			name: "Valid config w/ wildcard email_reporter_configs - no error",
+			config: EmailReporterConfigs{
+				"*": {Recipients: []string{"team@example.com"}},
+			},
+			successExpected: true,
+		},
+		{
+			name: "Valid config w/ named recipient and templates - no error",
+			config: EmailReporterConfigs{
+				"org/repo": {
+					Recipients:      []string{"Team <team@example.com>"},
+					SubjectTemplate: "{{.Spec.Job}}",
+					ReportTemplate:  "<p>{{.Status.State}}</p>",
+				},
+			},
+			successExpected: true,
+		},
+		{
+			name:            "Empty config - no error",
+			config:          EmailReporterConfigs{},
+			successExpected: true,
+		},
+		{
+			name: "No recipients - error",
+			config: EmailReporterConfigs{
+				"*": {JobTypesToReport: []prowapi.ProwJobType{"presubmit"}},
+			},
+			successExpected: false,
+		},
+		{
+			name: "Invalid recipient - error",
+			config: EmailReporterConfigs{
+				"*": {Recipients: []string{"not an address"}},
+			},
+			successExpected: false,
+		},
+		{
+			name: "Invalid subject template - error",
+			config: EmailReporterConfigs{
+				"*": {Recipients: []string{"team@example.com"}, SubjectTemplate: "{{ if .Spec.Job}}"},
+			},
+			successExpected: false,
+		},
+		{
+			name: "Invalid report template - error",
+			config: EmailReporterConfigs{
+				"*": {Recipients: []string{"team@example.com"}, ReportTemplate: "{{.Undef}}"},
+			},
+			successExpected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Config{ProwConfig: ProwConfig{EmailReporterConfigs: tc.config}}
+			if err := cfg.validateComponentConfig(); (err == nil) != tc.successExpected {
+				t.Errorf("Expected success=%t but got err=%v", tc.successExpected, err)
+			}
+			if tc.successExpected {
+				for _, config := range cfg.EmailReporterConfigs {
+					if config.SubjectTemplate == "" || config.ReportTemplate == "" {
+						t.Errorf("expected default SubjectTemplate and ReportTemplate to be set")
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestJiraReporterValidation(t *testing.T) {
+	testCases := []struct {
+		name            string
+		config          JiraReporterConfigs
+		expected        JiraReporterConfigs
+		successExpected bool
+	}{
+		{
+			name:   "Defaults are applied",
+			config: JiraReporterConfigs{"*": {}},
+			expected: JiraReporterConfigs{"*": {
+				JobStatesToReport: []prowapi.ProwJobState{prowapi.FailureState},
+				IssuesAnnotation:  DefaultJiraIssuesAnnotation,
+				ReportTemplate:    `Job {{.Spec.Job}} of type {{.Spec.Type}} ended with state {{.Status.State}}.{{if .Status.URL}} [View logs|{{.Status.URL}}]{{end}}`,
+			}},
+			successExpected: true,
+		},
+		{
+			name: "Explicit values are kept",
+			config: JiraReporterConfigs{"org/repo": {
+				JobStatesToReport: []prowapi.ProwJobState{prowapi.ErrorState},
+				IssuesAnnotation:  "example.com/issues",
+				ReportTemplate:    "{{.Spec.Job}}",
+			}},
+			expected: JiraReporterConfigs{"org/repo": {
+				JobStatesToReport: []prowapi.ProwJobState{prowapi.ErrorState},
+				IssuesAnnotation:  "example.com/issues",
+				ReportTemplate:    "{{.Spec.Job}}",
+			}},
+			successExpected: true,
+		},
+		{
+			name: "Invalid template - error",
+			config: JiraReporterConfigs{
+				"*": {ReportTemplate: "{{ if .Spec.Name}}"},
+			},
+			successExpected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Config{ProwConfig: ProwConfig{JiraReporterConfigs: tc.config}}
+			if err := cfg.validateComponentConfig(); (err == nil) != tc.successExpected {
+				t.Errorf("Expected success=%t but got err=%v", tc.successExpected, err)
+			}
+			if tc.successExpected {
+				if diff := cmp.Diff(tc.expected, cfg.JiraReporterConfigs); diff != "" {
+					t.Errorf("config differs from expected: %s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestPagerDutyReporterValidation(t *testing.T) {
+	testCases := []struct {
+		name            string
+		config          PagerDutyReporterConfigs
+		expected        PagerDutyReporterConfigs
+		successExpected bool
+	}{
+		{
+			name:   "Defaults are applied",
+			config: PagerDutyReporterConfigs{"*": {RoutingKey: "key"}},
+			expected: PagerDutyReporterConfigs{"*": {
+				JobTypesToReport:  []prowapi.ProwJobType{prowapi.PeriodicJob},
+				JobStatesToReport: []prowapi.ProwJobState{prowapi.FailureState, prowapi.ErrorState},
+				RoutingKey:        "key",
+				Severity:          "critical",
+				ReportTemplate:    `Job {{.Spec.Job}} ended with state {{.Status.State}}`,
+			}},
+			successExpected: true,
+		},
+		{
+			name: "Explicit values are kept",
+			config: PagerDutyReporterConfigs{"org/repo": {
+				JobTypesToReport:  []prowapi.ProwJobType{prowapi.PostsubmitJob},
+				JobStatesToReport: []prowapi.ProwJobState{prowapi.FailureState},
+				RoutingKey:        "key",
+				Severity:          "warning",
+				ReportTemplate:    "{{.Spec.Job}}",
+			}},
+			expected: PagerDutyReporterConfigs{"org/repo": {
+				JobTypesToReport:  []prowapi.ProwJobType{prowapi.PostsubmitJob},
+				JobStatesToReport: []prowapi.ProwJobState{prowapi.FailureState},
+				RoutingKey:        "key",
+				Severity:          "warning",
+				ReportTemplate:    "{{.Spec.Job}}",
+			}},
+			successExpected: true,
+		},
+		{
+			name:            "Missing routing key - error",
+			config:          PagerDutyReporterConfigs{"*": {}},
+			successExpected: false,
+		},
+		{
+			name:            "Invalid severity - error",
+			config:          PagerDutyReporterConfigs{"*": {RoutingKey: "key", Severity: "urgent"}},
+			successExpected: false,
+		},
+		{
+			name: "Success state - error",
+			config: PagerDutyReporterConfigs{"*": {
+				RoutingKey:        "key",
+				JobStatesToReport: []prowapi.ProwJobState{prowapi.SuccessState},
+			}},
+			successExpected: false,
+		},
+		{
+			name: "Invalid template - error",
+			config: PagerDutyReporterConfigs{
+				"*": {RoutingKey: "key", ReportTemplate: "{{ if .Spec.Name}}"},
+			},
+			successExpected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Config{ProwConfig: ProwConfig{PagerDutyReporterConfigs: tc.config}}
+			if err := cfg.validateComponentConfig(); (err == nil) != tc.successExpected {
+				t.Errorf("Expected success=%t but got err=%v", tc.successExpected, err)
+			}
+			if tc.successExpected {
+				if diff := cmp.Diff(tc.expected, cfg.PagerDutyReporterConfigs); diff != "" {
+					t.Errorf("config differs from expected: %s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestTelegramReporterValidation(t *testing.T) {
+	testCases := []struct {
+		name            string
+		config          TelegramReporterConfigs
+		expected        TelegramReporterConfigs
+		successExpected bool
+	}{
+		{
+			name:   "Defaults are applied",
+			config: TelegramReporterConfigs{"*": {ChatID: "@prow"}},
+			expected: TelegramReporterConfigs{"*": {
+				ChatID:         "@prow",
+				ReportTemplate: `Job {{.Spec.Job}} of type {{.Spec.Type}} ended with state {{.Status.State}}.`,
+			}},
+			successExpected: true,
+		},
+		{
+			name:            "Missing chat ID - error",
+			config:          TelegramReporterConfigs{"*": {}},
+			successExpected: false,
+		},
+		{
+			name: "Invalid template - error",
+			config: TelegramReporterConfigs{
+				"*": {ChatID: "-100123", ReportTemplate: "{{ if .Spec.Name}}"},
+			},
+			successExpected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Config{ProwConfig: ProwConfig{TelegramReporterConfigs: tc.config}}
+			if err := cfg.validateComponentConfig(); (err == nil) != tc.successExpected {
+				t.Errorf("Expected success=%t but got err=%v", tc.successExpected, err)
+			}
+			if tc.successExpected {
+				if diff := cmp.Diff(tc.expected, cfg.TelegramReporterConfigs); diff != "" {
+					t.Errorf("config differs from expected: %s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestMatrixReporterValidation(t *testing.T) {
+	testCases := []struct {
+		name            string
+		config          MatrixReporterConfigs
+		expected        MatrixReporterConfigs
+		successExpected bool
+	}{
+		{
+			name:   "Defaults are applied",
+			config: MatrixReporterConfigs{"*": {Homeserver: "https://matrix.example.com/", RoomID: "!abc:example.com"}},
+			expected: MatrixReporterConfigs{"*": {
+				Homeserver:     "https://matrix.example.com",
+				RoomID:         "!abc:example.com",
+				ReportTemplate: `Job {{.Spec.Job}} of type {{.Spec.Type}} ended with state {{.Status.State}}.`,
+			}},
+			successExpected: true,
+		},
+		{
+			name:            "Missing homeserver - error",
+			config:          MatrixReporterConfigs{"*": {RoomID: "!abc:example.com"}},
+			successExpected: false,
+		},
+		{
+			name:            "Relative homeserver - error",
+			config:          MatrixReporterConfigs{"*": {Homeserver: "matrix.example.com", RoomID: "!abc:example.com"}},
+			successExpected: false,
+		},
+		{
+			name:            "Missing room ID - error",
+			config:          MatrixReporterConfigs{"*": {Homeserver: "https://matrix.example.com"}},
+			successExpected: false,
+		},
+		{
+			name:            "Room alias - error",
+			config:          MatrixReporterConfigs{"*": {Homeserver: "https://matrix.example.com", RoomID: "#prow:example.com"}},
+			successExpected: false,
+		},
+		{
+			name: "Invalid template - error",
+			config: MatrixReporterConfigs{
+				"*": {Homeserver: "https://matrix.example.com", RoomID: "!abc:example.com", ReportTemplate: "{{ if .Spec.Name}}"},
+			},
+			successExpected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Config{ProwConfig: ProwConfig{MatrixReporterConfigs: tc.config}}
+			if err := cfg.validateComponentConfig(); (err == nil) != tc.successExpected {
+				t.Errorf("Expected success=%t but got err=%v", tc.successExpected, err)
+			}
+			if tc.successExpected {
+				if diff := cmp.Diff(tc.expected, cfg.MatrixReporterConfigs); diff != "" {
+					t.Errorf("config differs from expected: %s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestBitbucketReporterValidation(t *testing.T) {
+	testCases := []struct {
+		name            string
+		config          BitbucketReporterConfigs
+		expected        BitbucketReporterConfigs
+		successExpected bool
+	}{
+		{
+			name:   "Defaults are applied",
+			config: BitbucketReporterConfigs{"*": {Server: "https://bitbucket.example.com/"}},
+			expected: BitbucketReporterConfigs{"*": {
+				JobTypesToReport: []prowapi.ProwJobType{prowapi.PresubmitJob, prowapi.PostsubmitJob},
+				Server:           "https://bitbucket.example.com",
+			}},
+			successExpected: true,
+		},
+		{
+			name:   "Job types are kept",
+			config: BitbucketReporterConfigs{"org": {JobTypesToReport: []prowapi.ProwJobType{prowapi.PresubmitJob}, Server: "http://stash.example.com:7990"}},
+			expected: BitbucketReporterConfigs{"org": {
+				JobTypesToReport: []prowapi.ProwJobType{prowapi.PresubmitJob},
+				Server:           "http://stash.example.com:7990",
+			}},
+			successExpected: true,
+		},
+		{
+			name:            "Missing server - error",
+			config:          BitbucketReporterConfigs{"*": {}},
+			successExpected: false,
+		},
+		{
+			name:            "Relative server - error",
+			config:          BitbucketReporterConfigs{"*": {Server: "bitbucket.example.com"}},
+			successExpected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Config{ProwConfig: ProwConfig{BitbucketReporterConfigs: tc.config}}
+			if err := cfg.validateComponentConfig(); (err == nil) != tc.successExpected {
+				t.Errorf("Expected success=%t but got err=%v", tc.successExpected, err)
+			}
+			if tc.successExpected {
+				if diff := cmp.Diff(tc.expected, cfg.BitbucketReporterConfigs); diff != "" {
+					t.Errorf("config differs from expected: %s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestGitLabReporterValidation(t *testing.T) {
+	testCases := []struct {
+		name            string
+		config          GitLabReporterConfigs
+		expected        GitLabReporterConfigs
+		successExpected bool
+	}{
+		{
+			name:   "Defaults are applied",
+			config: GitLabReporterConfigs{"*": {}},
+			expected: GitLabReporterConfigs{"*": {
+				JobTypesToReport: []prowapi.ProwJobType{prowapi.PresubmitJob, prowapi.PostsubmitJob},
+				Server:           "https://gitlab.com",
+			}},
+			successExpected: true,
+		},
+		{
+			name:   "Settings are kept",
+			config: GitLabReporterConfigs{"org": {JobTypesToReport: []prowapi.ProwJobType{prowapi.PresubmitJob}, Server: "https://gitlab.example.com/", CommentOnFailure: true}},
+			expected: GitLabReporterConfigs{"org": {
+				JobTypesToReport: []prowapi.ProwJobType{prowapi.PresubmitJob},
+				Server:           "https://gitlab.example.com",
+				CommentOnFailure: true,
+			}},
+			successExpected: true,
+		},
+		{
+			name:            "Relative server - error",
+			config:          GitLabReporterConfigs{"*": {Server: "gitlab.example.com"}},
+			successExpected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Config{ProwConfig: ProwConfig{GitLabReporterConfigs: tc.config}}
+			if err := cfg.validateComponentConfig(); (err == nil) != tc.successExpected {
+				t.Errorf("Expected success=%t but got err=%v", tc.successExpected, err)
+			}
+			if tc.successExpected {
+				if diff := cmp.Diff(tc.expected, cfg.GitLabReporterConfigs); diff != "" {
+					t.Errorf("config differs from expected: %s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestKafkaReporterValidation(t *testing.T) {
+	testCases := []struct {
+		name            string
+		config          KafkaReporterConfigs
+		expected        KafkaReporterConfigs
+		successExpected bool
+	}{
+		{
+			name:   "Defaults are applied",
+			config: KafkaReporterConfigs{"*": {Brokers: []string{"kafka-0:9092"}, Topic: "prow"}},
+			expected: KafkaReporterConfigs{"*": {
+				JobTypesToReport:  []prowapi.ProwJobType{prowapi.PresubmitJob, prowapi.PostsubmitJob, prowapi.PeriodicJob, prowapi.BatchJob},
+				JobStatesToReport: prowapi.GetAllProwJobStates(),
+				Brokers:           []string{"kafka-0:9092"},
+				Topic:             "prow",
+			}},
+			successExpected: true,
+		},
+		{
+			name: "TLS and SCRAM",
+			config: KafkaReporterConfigs{"*": {
+				JobTypesToReport:  []prowapi.ProwJobType{prowapi.PeriodicJob},
+				JobStatesToReport: []prowapi.ProwJobState{prowapi.FailureState},
+				Brokers:           []string{"kafka-0:9093", "kafka-1:9093"},
+				Topic:             "prow",
+				TLS:               true,
+				SASLMechanism:     KafkaSASLScramSHA512,
+			}},
+			expected: KafkaReporterConfigs{"*": {
+				JobTypesToReport:  []prowapi.ProwJobType{prowapi.PeriodicJob},
+				JobStatesToReport: []prowapi.ProwJobState{prowapi.FailureState},
+				Brokers:           []string{"kafka-0:9093", "kafka-1:9093"},
+				Topic:             "prow",
+				TLS:               true,
+				SASLMechanism:     KafkaSASLScramSHA512,
+			}},
+			successExpected: true,
+		},
+		{
+			name:            "Missing brokers - error",
+			config:          KafkaReporterConfigs{"*": {Topic: "prow"}},
+			successExpected: false,
+		},
+		{
+			name:            "Broker without port - error",
+			config:          KafkaReporterConfigs{"*": {Brokers: []string{"kafka-0"}, Topic: "prow"}},
+			successExpected: false,
+		},
+		{
+			name:            "Missing topic - error",
+			config:          KafkaReporterConfigs{"*": {Brokers: []string{"kafka-0:9092"}}},
+			successExpected: false,
+		},
+		{
+			name:            "Unsupported SASL mechanism - error",
+			config:          KafkaReporterConfigs{"*": {Brokers: []string{"kafka-0:9092"}, Topic: "prow", SASLMechanism: "GSSAPI"}},
+			successExpected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Config{ProwConfig: ProwConfig{KafkaReporterConfigs: tc.config}}
+			if err := cfg.validateComponentConfig(); (err == nil) != tc.successExpected {
+				t.Errorf("Expected success=%t but got err=%v", tc.successExpected, err)
+			}
+			if tc.successExpected {
+				if diff := cmp.Diff(tc.expected, cfg.KafkaReporterConfigs); diff != "" {
+					t.Errorf("config differs from expected: %s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestDatadogReporterValidation(t *testing.T) {
+	testCases := []struct {
+		name            string
+		config          DatadogReporterConfigs
+		expected        DatadogReporterConfigs
+		successExpected bool
+	}{
+		{
+			name:   "Defaults are applied",
+			config: DatadogReporterConfigs{"*": {}},
+			expected: DatadogReporterConfigs{"*": {
+				JobTypesToReport:  []prowapi.ProwJobType{prowapi.PresubmitJob, prowapi.PostsubmitJob, prowapi.PeriodicJob, prowapi.BatchJob},
+				JobStatesToReport: []prowapi.ProwJobState{prowapi.SuccessState, prowapi.FailureState, prowapi.ErrorState, prowapi.AbortedState},
+				Site:              "datadoghq.com",
+				MaxRetries:        3,
+			}},
+			successExpected: true,
+		},
+		{
+			name: "Site, tags and retries",
+			config: DatadogReporterConfigs{"*": {
+				JobTypesToReport:  []prowapi.ProwJobType{prowapi.PeriodicJob},
+				JobStatesToReport: []prowapi.ProwJobState{prowapi.FailureState},
+				Site:              "datadoghq.eu",
+				Tags:              []string{"env:ci"},
+				MaxRetries:        5,
+			}},
+			expected: DatadogReporterConfigs{"*": {
+				JobTypesToReport:  []prowapi.ProwJobType{prowapi.PeriodicJob},
+				JobStatesToReport: []prowapi.ProwJobState{prowapi.FailureState},
+				Site:              "datadoghq.eu",
+				Tags:              []string{"env:ci"},
+				MaxRetries:        5,
+			}},
+			successExpected: true,
+		},
+		{
+			name:            "Site with scheme - error",
+			config:          DatadogReporterConfigs{"*": {Site: "https://api.datadoghq.com"}},
+			successExpected: false,
+		},
+		{
+			name:            "Negative retries - error",
+			config:          DatadogReporterConfigs{"*": {MaxRetries: -1}},
+			successExpected: false,
+		},
+		{
+			name:            "Empty tag - error",
+			config:          DatadogReporterConfigs{"*": {Tags: []string{""}}},
+			successExpected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Config{ProwConfig: ProwConfig{DatadogReporterConfigs: tc.config}}
+			if err := cfg.validateComponentConfig(); (err == nil) != tc.successExpected {
+				t.Errorf("Expected success=%t but got err=%v", tc.successExpected, err)
+			}
+			if tc.successExpected {
+				if diff := cmp.Diff(tc.expected, cfg.DatadogReporterConfigs); diff != "" {
+					t.Errorf("config differs from expected: %s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestRedisReporterValidation(t *testing.T) {
+	testCases := []struct {
+		name            string
+		config          RedisReporterConfigs
+		expected        RedisReporterConfigs
+		successExpected bool
+	}{
+		{
+			name:   "Defaults are applied",
+			config: RedisReporterConfigs{"*": {Address: "redis:6379", Stream: "prow"}},
+			expected: RedisReporterConfigs{"*": {
+				JobTypesToReport:  []prowapi.ProwJobType{prowapi.PresubmitJob, prowapi.PostsubmitJob, prowapi.PeriodicJob, prowapi.BatchJob},
+				JobStatesToReport: prowapi.GetAllProwJobStates(),
+				Address:           "redis:6379",
+				Stream:            "prow",
+			}},
+			successExpected: true,
+		},
+		{
+			name:            "Missing address - error",
+			config:          RedisReporterConfigs{"*": {Stream: "prow"}},
+			successExpected: false,
+		},
+		{
+			name:            "Address without port - error",
+			config:          RedisReporterConfigs{"*": {Address: "redis", Stream: "prow"}},
+			successExpected: false,
+		},
+		{
+			name:            "Missing stream - error",
+			config:          RedisReporterConfigs{"*": {Address: "redis:6379"}},
+			successExpected: false,
+		},
+		{
+			name:            "Negative max_len - error",
+			config:          RedisReporterConfigs{"*": {Address: "redis:6379", Stream: "prow", MaxLen: -1}},
+			successExpected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Config{ProwConfig: ProwConfig{RedisReporterConfigs: tc.config}}
+			if err := cfg.validateComponentConfig(); (err == nil) != tc.successExpected {
+				t.Errorf("Expected success=%t but got err=%v", tc.successExpected, err)
+			}
+			if tc.successExpected {
+				if diff := cmp.Diff(tc.expected, cfg.RedisReporterConfigs); diff != "" {
+					t.Errorf("config differs from expected: %s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestSNSReporterValidation(t *testing.T) {
+	testCases := []struct {
+		name            string
+		config          SNSReporterConfigs
+		expected        SNSReporterConfigs
+		successExpected bool
+	}{
+		{
+			name:   "Defaults are applied",
+			config: SNSReporterConfigs{"*": {TopicARN: "arn:aws:sns:us-east-1:123456789012:prow"}},
+			expected: SNSReporterConfigs{"*": {
+				JobTypesToReport:  []prowapi.ProwJobType{prowapi.PresubmitJob, prowapi.PostsubmitJob, prowapi.PeriodicJob, prowapi.BatchJob},
+				JobStatesToReport: prowapi.GetAllProwJobStates(),
+				TopicARN:          "arn:aws:sns:us-east-1:123456789012:prow",
+			}},
+			successExpected: true,
+		},
+		{
+			name:            "Missing topic ARN - error",
+			config:          SNSReporterConfigs{"*": {}},
+			successExpected: false,
+		},
+		{
+			name:            "Topic ARN without region - error",
+			config:          SNSReporterConfigs{"*": {TopicARN: "arn:aws:sns::123456789012:prow"}},
+			successExpected: false,
+		},
+		{
+			name:            "Non-SNS ARN - error",
+			config:          SNSReporterConfigs{"*": {TopicARN: "arn:aws:sqs:us-east-1:123456789012:prow"}},
+			successExpected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Config{ProwConfig: ProwConfig{SNSReporterConfigs: tc.config}}
+			if err := cfg.validateComponentConfig(); (err == nil) != tc.successExpected {
+				t.Errorf("Expected success=%t but got err=%v", tc.successExpected, err)
+			}
+			if tc.successExpected {
+				if diff := cmp.Diff(tc.expected, cfg.SNSReporterConfigs); diff != "" {
+					t.Errorf("config differs from expected: %s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestGoogleChatReporterValidation(t *testing.T) {
+	testCases := []struct {
+		name            string
+		config          GoogleChatReporterConfigs
+		successExpected bool
+	}{
+		{
+			name: "Valid config w/ wildcard googlechat_reporter_configs - no error",
+			config: GoogleChatReporterConfigs{
+				"*": {Space: "oncall"},
+			},
+			successExpected: true,
+		},
+		{
+			name: "Valid config w/ thread key template - no error",
+			config: GoogleChatReporterConfigs{
+				"org/repo": {Space: "oncall", ThreadKeyTemplate: "{{.Spec.Job}}"},
+			},
+			successExpected: true,
+		},
+		{
+			name: "No space w/ googlechat_reporter_configs - error",
+			config: GoogleChatReporterConfigs{
+				"*": {JobTypesToReport: []prowapi.ProwJobType{"presubmit"}},
+			},
+			successExpected: false,
+		},
+		{
+			name: "Invalid report template - error",
+			config: GoogleChatReporterConfigs{
+				"*": {Space: "oncall", ReportTemplate: "{{ if .Spec.Name}}"},
+			},
+			successExpected: false,
+		},
+		{
+			name: "Invalid thread key template - error",
+			config: GoogleChatReporterConfigs{
+				"*": {Space: "oncall", ThreadKeyTemplate: "{{ .Spec.Nope }}"},
+			},
+			successExpected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Config{ProwConfig: ProwConfig{GoogleChatReporterConfigs: tc.config}}
+			if err := cfg.validateComponentConfig(); (err == nil) != tc.successExpected {
+				t.Errorf("Expected success=%t but got err=%v", tc.successExpected, err)
+			}
+			if tc.successExpected {
+				for _, config := range cfg.GoogleChatReporterConfigs {
+					if config.ReportTemplate == "" {
+						t.Errorf("expected default ReportTemplate to be set")
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCrierWorkersValidation(t *testing.T) {
+	testCases := []struct {
+		name            string
+		workers         map[string]int
+		successExpected bool
+	}{
+		{
+			name:            "No overrides - no error",
+			successExpected: true,
+		},
+		{
+			name:            "Valid overrides - no error",
+			workers:         map[string]int{"slackreporter": 1, "githubreporter": 20},
+			successExpected: true,
+		},
+		{
+			name:            "Zero workers - error",
+			workers:         map[string]int{"slackreporter": 0},
+			successExpected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Config{ProwConfig: ProwConfig{Crier: Crier{Workers: tc.workers}}}
+			if err := cfg.validateComponentConfig(); (err == nil) != tc.successExpected {
+				t.Errorf("Expected success=%t but got err=%v", tc.successExpected, err)
+			}
+		})
+	}
+}
+
+func TestCrierReportTimeoutsValidation(t *testing.T) {
+	testCases := []struct {
+		name            string
+		timeouts        map[string]metav1.Duration
+		successExpected bool
+	}{
+		{
+			name:            "No overrides - no error",
+			successExpected: true,
+		},
+		{
+			name:            "Valid overrides - no error",
+			timeouts:        map[string]metav1.Duration{"slackreporter": {Duration: 10 * time.Second}},
+			successExpected: true,
+		},
+		{
+			name:            "Zero timeout - error",
+			timeouts:        map[string]metav1.Duration{"slackreporter": {}},
+			successExpected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Config{ProwConfig: ProwConfig{Crier: Crier{ReportTimeouts: tc.timeouts}}}
+			if err := cfg.validateComponentConfig(); (err == nil) != tc.successExpected {
+				t.Errorf("Expected success=%t but got err=%v", tc.successExpected, err)
+			}
+		})
+	}
+}
+
+func TestCrierReportTimeoutFor(t *testing.T) {
+	c := Crier{ReportTimeouts: map[string]metav1.Duration{"slackreporter": {Duration: 10 * time.Second}}}
+	if got := c.ReportTimeoutFor("slackreporter", time.Minute); got != 10*time.Second {
+		t.Errorf("expected the override of 10s, got %s", got)
+	}
+	if got := c.ReportTimeoutFor("pubsubreporter", time.Minute); got != time.Minute {
+		t.Errorf("expected the default of 1m, got %s", got)
+	}
+}
+
+func TestCrierReporterEnablementValidation(t *testing.T) {
+	testCases := []struct {
+		name            string
+		enablement      map[string]ReporterEnablement
+		successExpected bool
+	}{
+		{
+			name:            "No enablement - no error",
+			successExpected: true,
+		},
+		{
+			name: "Valid orgs and repos - no error",
+			enablement: map[string]ReporterEnablement{"slackreporter": {
+				EnabledOrgs:   []string{"org"},
+				DisabledRepos: []string{"org/repo"},
+			}},
+			successExpected: true,
+		},
+		{
+			name:            "Repo without org - error",
+			enablement:      map[string]ReporterEnablement{"slackreporter": {EnabledRepos: []string{"repo"}}},
+			successExpected: false,
+		},
+		{
+			name:            "Repo with too many parts - error",
+			enablement:      map[string]ReporterEnablement{"slackreporter": {DisabledRepos: []string{"org/repo/sub"}}},
+			successExpected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Config{ProwConfig: ProwConfig{Crier: Crier{ReporterEnablement: tc.enablement}}}
+			if err := cfg.validateComponentConfig(); (err == nil) != tc.successExpected {
+				t.Errorf("Expected success=%t but got err=%v", tc.successExpected, err)
+			}
+		})
+	}
+}
+
+func TestCrierReporterEnabled(t *testing.T) {
+	crier := Crier{ReporterEnablement: map[string]ReporterEnablement{
+		"slackreporter": {
+			EnabledOrgs:   []string{"org"},
+			EnabledRepos:  []string{"other/repo"},
+			DisabledRepos: []string{"org/private"},
+		},
+		"gcsreporter": {
+			DisabledOrgs: []string{"internal"},
+		},
+	}}
+	testCases := []struct {
+		name     string
+		reporter string
+		org      string
+		repo     string
+		expected bool
+	}{
+		{
+			name:     "unlisted reporter is enabled",
+			reporter: "githubreporter",
+			org:      "internal",
+			repo:     "repo",
+			expected: true,
+		},
+		{
+			name:     "enabled org",
+			reporter: "slackreporter",
+			org:      "org",
+			repo:     "repo",
+			expected: true,
+		},
+		{
+			name:     "enabled repo",
+			reporter: "slackreporter",
+			org:      "other",
+			repo:     "repo",
+			expected: true,
+		},
+		{
+			name:     "repo of an org that isn't enabled",
+			reporter: "slackreporter",
+			org:      "other",
+			repo:     "different",
+			expected: false,
+		},
+		{
+			name:     "disabled repo of an enabled org",
+			reporter: "slackreporter",
+			org:      "org",
+			repo:     "private",
+			expected: false,
+		},
+		{
+			name:     "disabled org",
+			reporter: "gcsreporter",
+			org:      "internal",
+			repo:     "repo",
+			expected: false,
+		},
+		{
+			name:     "other org with only disabled orgs",
+			reporter: "gcsreporter",
+			org:      "org",
+			repo:     "repo",
+			expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := crier.ReporterEnabled(tc.reporter, tc.org, tc.repo); actual != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCrierGCSPathTemplateValidation(t *testing.T) {
+	testCases := []struct {
+		name            string
+		template        string
+		successExpected bool
+	}{
+		{
+			name:            "No template - no error",
+			successExpected: true,
+		},
+		{
+			name:            "Job name and build ID - no error",
+			template:        "custom/{{.Spec.Job}}/{{.Status.BuildID}}",
+			successExpected: true,
+		},
+		{
+			name:            "Invalid template - error",
+			template:        "custom/{{.Spec.Job",
+			successExpected: false,
+		},
+		{
+			name:            "Missing build ID - error",
+			template:        "custom/{{.Spec.Job}}",
+			successExpected: false,
+		},
+		{
+			name:            "Missing job name - error",
+			template:        "custom/{{.Status.BuildID}}",
+			successExpected: false,
+		},
+		{
+			name:            "Empty path - error",
+			template:        "{{if false}}{{.Spec.Job}}{{end}}",
+			successExpected: false,
+		},
+		{
+			name:            "Unknown field - error",
+			template:        "{{.Spec.Nope}}/{{.Status.BuildID}}",
+			successExpected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Config{ProwConfig: ProwConfig{Crier: Crier{GCSPathTemplateString: tc.template}}}
+			if err := cfg.validateComponentConfig(); (err == nil) != tc.successExpected {
+				t.Errorf("Expected success=%t but got err=%v", tc.successExpected, err)
+			}
+		})
+	}
+}
+
+func TestCrierJUnitSummaryGlobValidation(t *testing.T) {
+	testCases := []struct {
+		name            string
+		glob            string
+		successExpected bool
+	}{
+		{
+			name:            "No glob - no error",
+			successExpected: true,
+		},
+		{
+			name:            "Valid glob - no error",
+			glob:            "artifacts/junit*.xml",
+			successExpected: true,
+		},
+		{
+			name:            "Malformed glob - error",
+			glob:            "artifacts/junit[.xml",
+			successExpected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Config{ProwConfig: ProwConfig{Crier: Crier{JUnitSummaryGlob: tc.glob}}}
+			if err := cfg.validateComponentConfig(); (err == nil) != tc.successExpected {
+				t.Errorf("Expected success=%t but got err=%v", tc.successExpected, err)
+			}
+		})
+	}
+}
+
+func TestCrierPubSubReporterValidation(t *testing.T) {
+	testCases := []struct {
+		name            string
+		reporter        *PubSubReporter
+		successExpected bool
+	}{
+		{
+			name:            "No config - no error",
+			successExpected: true,
+		},
+		{
+			name:            "Additional topics - no error",
+			reporter:        &PubSubReporter{AdditionalTopics: []PubSubTopic{{Project: "central", Topic: "prowjobs"}}},
+			successExpected: true,
+		},
+		{
+			name:            "Additional topic without project - error",
+			reporter:        &PubSubReporter{AdditionalTopics: []PubSubTopic{{Topic: "prowjobs"}}},
+			successExpected: false,
+		},
+		{
+			name:            "Additional topic without topic - error",
+			reporter:        &PubSubReporter{AdditionalTopics: []PubSubTopic{{Project: "central", Topic: "prowjobs"}, {Project: "team"}}},
+			successExpected: false,
+		},
+		{
+			name:            "Job states to report - no error",
+			reporter:        &PubSubReporter{JobStatesToReport: []prowapi.ProwJobState{prowapi.PendingState, prowapi.SuccessState}},
+			successExpected: true,
+		},
+		{
+			name:            "Invalid job state to report - error",
+			reporter:        &PubSubReporter{JobStatesToReport: []prowapi.ProwJobState{"running"}},
+			successExpected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Config{ProwConfig: ProwConfig{Crier: Crier{PubSubReporter: tc.reporter}}}
+			if err := cfg.validateComponentConfig(); (err == nil) != tc.successExpected {
+				t.Errorf("Expected success=%t but got err=%v", tc.successExpected, err)
+			}
+		})
+	}
+}
+
+func TestCrierSQLReporterValidation(t *testing.T) {
+	testCases := []struct {
+		name            string
+		reporter        *SQLReporter
+		successExpected bool
+	}{
+		{
+			name:            "No config - no error",
+			successExpected: true,
+		},
+		{
+			name:            "Table - no error",
+			reporter:        &SQLReporter{Table: "prow_jobs", MaxOpenConnections: 4},
+			successExpected: true,
+		},
+		{
+			name:            "Table with schema - no error",
+			reporter:        &SQLReporter{Table: "ci.prowjobs"},
+			successExpected: true,
+		},
+		{
+			name:            "Invalid table - error",
+			reporter:        &SQLReporter{Table: "prowjobs; DROP TABLE prowjobs"},
+			successExpected: false,
+		},
+		{
+			name:            "Negative max open connections - error",
+			reporter:        &SQLReporter{MaxOpenConnections: -1},
+			successExpected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Config{ProwConfig: ProwConfig{Crier: Crier{SQLReporter: tc.reporter}}}
+			if err := cfg.validateComponentConfig(); (err == nil) != tc.successExpected {
+				t.Errorf("Expected success=%t but got err=%v", tc.successExpected, err)
+			}
+		})
+	}
+}
+
+func TestCrierSkipRulesValidation(t *testing.T) {
+	testCases := []struct {
+		name            string
+		labels          map[string]string
+		annotations     map[string]string
+		successExpected bool
+	}{
+		{
+			name:            "No rules - no error",
+			successExpected: true,
+		},
+		{
+			name:            "Rules - no error",
+			labels:          map[string]string{"report": "none"},
+			annotations:     map[string]string{"example.com/no-report": ""},
+			successExpected: true,
+		},
+		{
+			name:            "Invalid label key - error",
+			labels:          map[string]string{"report none": "true"},
+			successExpected: false,
+		},
+		{
+			name:            "Invalid annotation key - error",
+			annotations:     map[string]string{"-report": "none"},
+			successExpected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Config{ProwConfig: ProwConfig{Crier: Crier{SkipLabels: tc.labels, SkipAnnotations: tc.annotations}}}
+			if err := cfg.validateComponentConfig(); (err == nil) != tc.successExpected {
+				t.Errorf("Expected success=%t but got err=%v", tc.successExpected, err)
+			}
+		})
+	}
+}
+
+func TestCrierSkipsReporting(t *testing.T) {
+	crier := Crier{
+		SkipLabels:      map[string]string{"report": "none"},
+		SkipAnnotations: map[string]string{"example.com/no-report": ""},
+	}
+	testCases := []struct {
+		name        string
+		labels      map[string]string
+		annotations map[string]string
+		expected    bool
+	}{
+		{
+			name: "no labels or annotations",
+		},
+		{
+			name:     "matching label",
+			labels:   map[string]string{"report": "none"},
+			expected: true,
+		},
+		{
+			name:   "label with other value",
+			labels: map[string]string{"report": "all"},
+		},
+		{
+			name:        "annotation matching any value",
+			annotations: map[string]string{"example.com/no-report": "true"},
+			expected:    true,
+		},
+		{
+			name:        "label key as annotation",
+			annotations: map[string]string{"report": "none"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pj := &prowapi.ProwJob{ObjectMeta: metav1.ObjectMeta{Labels: tc.labels, Annotations: tc.annotations}}
+			if skipped := crier.SkipsReporting(pj); skipped != tc.expected {
+				t.Errorf("expected skipped=%t, got %t", tc.expected, skipped)
+			}
+		})
+	}
+}
+
+func TestCrierGCSObjectsValidation(t *testing.T) {
+	testCases := []struct {
+		name            string
+		metadata        map[string]string
+		acl             string
+		retention       *GCSRetention
+		contentTypes    map[string]string
+		successExpected bool
+	}{
+		{
+			name:            "Nothing configured - no error",
+			successExpected: true,
+		},
+		{
+			name:            "Valid metadata and ACL - no error",
+			metadata:        map[string]string{"build-id": "{{.Status.BuildID}}", "Job_Type": "{{.Spec.Type}}"},
+			acl:             "publicRead",
+			successExpected: true,
+		},
+		{
+			name:            "Key with space - error",
+			metadata:        map[string]string{"build id": "{{.Status.BuildID}}"},
+			successExpected: false,
+		},
+		{
+			name:            "Key with prefix - error",
+			metadata:        map[string]string{"x-goog-meta-build-id": "{{.Status.BuildID}}"},
+			successExpected: false,
+		},
+		{
+			name:            "Malformed template - error",
+			metadata:        map[string]string{"build-id": "{{.Status.BuildID"},
+			successExpected: false,
+		},
+		{
+			name:            "Unknown ACL - error",
+			acl:             "public-read",
+			successExpected: false,
+		},
+		{
+			name: "Valid retention - no error",
+			retention: &GCSRetention{
+				AllowedClasses: []string{"short", "long"},
+				Rules:          []GCSRetentionRule{{JobTypes: []prowapi.ProwJobType{prowapi.PresubmitJob}, Class: "short"}},
+				DefaultClass:   "long",
+			},
+			successExpected: true,
+		},
+		{
+			name:            "Retention without allowed classes - error",
+			retention:       &GCSRetention{DefaultClass: "long"},
+			successExpected: false,
+		},
+		{
+			name: "Retention rule with class that isn't allowed - error",
+			retention: &GCSRetention{
+				AllowedClasses: []string{"short", "long"},
+				Rules:          []GCSRetentionRule{{Class: "forever"}},
+			},
+			successExpected: false,
+		},
+		{
+			name: "Retention default class that isn't allowed - error",
+			retention: &GCSRetention{
+				AllowedClasses: []string{"short", "long"},
+				DefaultClass:   "forever",
+			},
+			successExpected: false,
+		},
+		{
+			name: "Retention rule with invalid job type - error",
+			retention: &GCSRetention{
+				AllowedClasses: []string{"short"},
+				Rules:          []GCSRetentionRule{{JobTypes: []prowapi.ProwJobType{"nightly"}, Class: "short"}},
+			},
+			successExpected: false,
+		},
+		{
+			name:     "Retention key also set in metadata - error",
+			metadata: map[string]string{"retention-class": "{{.Spec.Type}}"},
+			retention: &GCSRetention{
+				AllowedClasses: []string{"short"},
+				DefaultClass:   "short",
+			},
+			successExpected: false,
+		},
+		{
+			name:            "Valid content types - no error",
+			contentTypes:    map[string]string{"log": "text/plain; charset=utf-8", "junit": "text/xml"},
+			successExpected: true,
+		},
+		{
+			name:            "Content type key with leading dot - error",
+			contentTypes:    map[string]string{".log": "text/plain"},
+			successExpected: false,
+		},
+		{
+			name:            "Malformed content type - error",
+			contentTypes:    map[string]string{"log": "text/plain; charset"},
+			successExpected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Config{ProwConfig: ProwConfig{Crier: Crier{GCSObjectMetadata: tc.metadata, GCSPredefinedACL: tc.acl, GCSRetention: tc.retention, GCSContentTypes: tc.contentTypes}}}
+			if err := cfg.validateComponentConfig(); (err == nil) != tc.successExpected {
+				t.Errorf("Expected success=%t but got err=%v", tc.successExpected, err)
+			}
+		})
+	}
+}
+
+func TestCrierGCSObjectMetadataFor(t *testing.T) {
+	cfg := Config{ProwConfig: ProwConfig{Crier: Crier{GCSObjectMetadata: map[string]string{
+		"build-id": "{{.Status.BuildID}}",
+		"job-type": "{{.Spec.Type}}",
+	}}}}
+	if err := cfg.validateComponentConfig(); err != nil {
+		t.Fatalf("Unexpected error validating config: %v", err)
+	}
+	pj := &prowapi.ProwJob{
+		Spec:   prowapi.ProwJobSpec{Type: prowapi.PeriodicJob},
+		Status: prowapi.ProwJobStatus{BuildID: "123"},
+	}
+	got, err := cfg.Crier.GCSObjectMetadataFor(pj)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(map[string]string{"build-id": "123", "job-type": "periodic"}, got); diff != "" {
+		t.Errorf("Unexpected metadata (-want +got):\n%s", diff)
+	}
+}
+
+func TestCrierGCSRetentionClass(t *testing.T) {
+	cfg := Config{ProwConfig: ProwConfig{Crier: Crier{GCSRetention: &GCSRetention{
+		AllowedClasses: []string{"short", "medium", "long"},
+		Rules: []GCSRetentionRule{
+			{Labels: map[string]string{"release": "true"}, Class: "long"},
+			{JobTypes: []prowapi.ProwJobType{prowapi.PresubmitJob, prowapi.BatchJob}, Class: "short"},
+		},
+		DefaultClass: "medium",
+	}}}}
+	if err := cfg.validateComponentConfig(); err != nil {
+		t.Fatalf("Unexpected error validating config: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		jobType  prowapi.ProwJobType
+		labels   map[string]string
+		expected map[string]string
+	}{
+		{
+			name:     "presubmit matches the job type",
+			jobType:  prowapi.PresubmitJob,
+			expected: map[string]string{"retention-class": "short"},
+		},
+		{
+			name:     "earlier rule matching the labels takes precedence",
+			jobType:  prowapi.PresubmitJob,
+			labels:   map[string]string{"release": "true"},
+			expected: map[string]string{"retention-class": "long"},
+		},
+		{
+			name:     "label with other value doesn't match",
+			jobType:  prowapi.PeriodicJob,
+			labels:   map[string]string{"release": "false"},
+			expected: map[string]string{"retention-class": "medium"},
+		},
+		{
+			name:     "unmatched job gets the default class",
+			jobType:  prowapi.PostsubmitJob,
+			expected: map[string]string{"retention-class": "medium"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pj := &prowapi.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{Labels: tc.labels},
+				Spec:       prowapi.ProwJobSpec{Type: tc.jobType},
+			}
+			got, err := cfg.Crier.GCSObjectMetadataFor(pj)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, got); diff != "" {
+				t.Errorf("Unexpected metadata (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	cfg.Crier.GCSRetention.DefaultClass = ""
+	got, err := cfg.Crier.GCSObjectMetadataFor(&prowapi.ProwJob{Spec: prowapi.ProwJobSpec{Type: prowapi.PeriodicJob}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got != nil {
+		t.Errorf("Expected no metadata without a default class, got %v", got)
+	}
+}
+
+func TestCrierResultStorePropertiesFor(t *testing.T) {
+	cfg := Config{ProwConfig: ProwConfig{Crier: Crier{ResultStoreProperties: map[string]string{
+		"Org":      "{{.Spec.Refs.Org}}",
+		"Pull":     "{{with .Spec.Refs}}{{range .Pulls}}{{.Number}}{{end}}{{end}}",
+		"Base_SHA": "{{.Spec.Refs.BaseSHA}}",
+		"Type":     `{{index .Labels "prow.k8s.io/type"}}`,
+	}}}}
+	if err := cfg.validateComponentConfig(); err != nil {
+		t.Fatalf("Unexpected error validating config: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		pj       *prowapi.ProwJob
+		expected map[string]string
+	}{
+		{
+			name: "presubmit",
+			pj: &prowapi.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"prow.k8s.io/type": "presubmit"}},
+				Spec: prowapi.ProwJobSpec{Refs: &prowapi.Refs{
+					Org:     "org",
+					BaseSHA: "abc",
+					Pulls:   []prowapi.Pull{{Number: 1}},
+				}},
+			},
+			expected: map[string]string{"Org": "org", "Pull": "1", "Base_SHA": "abc", "Type": "presubmit"},
+		},
+		{
+			name: "periodic without refs",
+			pj: &prowapi.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"prow.k8s.io/type": "periodic"}},
+			},
+			expected: map[string]string{"Type": "periodic"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, cfg.Crier.ResultStorePropertiesFor(tc.pj)); diff != "" {
+				t.Errorf("Unexpected properties (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCrierResultStorePropertiesValidation(t *testing.T) {
+	testCases := []struct {
+		name       string
+		properties map[string]string
+		expectErr  bool
+	}{
+		{
+			name:       "valid",
+			properties: map[string]string{"Org": "{{.Spec.Refs.Org}}"},
+		},
+		{
+			name:       "empty key",
+			properties: map[string]string{" ": "{{.Spec.Refs.Org}}"},
+			expectErr:  true,
+		},
+		{
+			name:       "invalid template",
+			properties: map[string]string{"Org": "{{.Spec.Refs.Org"},
+			expectErr:  true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Config{ProwConfig: ProwConfig{Crier: Crier{ResultStoreProperties: tc.properties}}}
+			if err := cfg.validateComponentConfig(); (err != nil) != tc.expectErr {
+				t.Errorf("Expected error: %t, got: %v", tc.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestCrierGCSPath(t *testing.T) {
+	cfg := Config{ProwConfig: ProwConfig{Crier: Crier{GCSPathTemplateString: "/custom//{{.Spec.Refs.Org}}/{{.Spec.Job}}/{{.Status.BuildID}}/"}}}
+	if err := cfg.validateComponentConfig(); err != nil {
+		t.Fatalf("Unexpected error validating config: %v", err)
+	}
+	pj := &prowapi.ProwJob{
+		Spec: prowapi.ProwJobSpec{
+			Job:  "my-job",
+			Refs: &prowapi.Refs{Org: "org", Repo: "repo"},
+		},
+		Status: prowapi.ProwJobStatus{BuildID: "123"},
+	}
+	got, err := cfg.Crier.GCSPath(pj)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := "custom/org/my-job/123"; got != expected {
+		t.Errorf("Expected path %q, got %q", expected, got)
+	}
+}
+
+func TestSlackReporterValidation(t *testing.T) {
+	testCases := []struct {
+		name            string
+		config          func() Config
+		successExpected bool
+	}{
+		{
+			name: "Valid config w/ wildcard slack_reporter_configs - no error",
+			config: func() Config {
+				slackCfg := map[string]SlackReporter{
+					"*": {
+						SlackReporterConfig: prowapi.SlackReporterConfig{
+							Channel: "my-channel",
+						},
+					},
+				}
+				return Config{
+					ProwConfig: ProwConfig{
+						SlackReporterConfigs: slackCfg,
+					},
+				}
+			},
+			successExpected: true,
+		},
+		{
+			name: "Valid config w/ org/repo slack_reporter_configs - no error",
+			config: func() Config {
+				slackCfg := map[string]SlackReporter{
+					"istio/proxy": {
+						SlackReporterConfig: prowapi.SlackReporterConfig{
+							Channel: "my-channel",
+						},
+					},
+				}
+				return Config{
+					ProwConfig: ProwConfig{
+						SlackReporterConfigs: slackCfg,
+					},
+				}
+			},
+			successExpected: true,
+		},
+		{
+			name: "Valid config w/ only channels - no error",
+			config: func() Config {
+				slackCfg := map[string]SlackReporter{
+					"*": {
+						Channels: []string{"team-channel", "dashboard-channel"},
+					},
+				}
+				return Config{
+					ProwConfig: ProwConfig{
+						SlackReporterConfigs: slackCfg,
+					},
+				}
+			},
+			successExpected: true,
+		},
+		{
+			name: "Empty value in channels - error",
+			config: func() Config {
+				slackCfg := map[string]SlackReporter{
+					"*": {
+						Channels: []string{"team-channel", ""},
+					},
+				}
+				return Config{
+					ProwConfig: ProwConfig{
+						SlackReporterConfigs: slackCfg,
+					},
+				}
+			},
+			successExpected: false,
+		},
+		{
+			name: "Valid job_states_to_messages - no error",
+			config: func() Config {
+				slackCfg := map[string]SlackReporter{
+					"*": {
+						Channels: []string{"team-channel"},
+						JobStatesToMessages: map[prowapi.ProwJobState]string{
+							prowapi.FailureState: ":x: {{.Spec.Job}} failed",
+						},
+					},
+				}
+				return Config{
+					ProwConfig: ProwConfig{
+						SlackReporterConfigs: slackCfg,
+					},
+				}
+			},
+			successExpected: true,
+		},
+		{
+			name: "Invalid state in job_states_to_messages - error",
+			config: func() Config {
+				slackCfg := map[string]SlackReporter{
+					"*": {
+						Channels: []string{"team-channel"},
+						JobStatesToMessages: map[prowapi.ProwJobState]string{
+							"failed": ":x: {{.Spec.Job}} failed",
+						},
+					},
+				}
+				return Config{
+					ProwConfig: ProwConfig{
+						SlackReporterConfigs: slackCfg,
+					},
+				}
+			},
+			successExpected: false,
+		},
+		{
+			name: "Invalid template in job_states_to_messages - error",
+			config: func() Config {
+				slackCfg := map[string]SlackReporter{
+					"*": {
+						Channels: []string{"team-channel"},
+						JobStatesToMessages: map[prowapi.ProwJobState]string{
+							prowapi.FailureState: "{{.Undef}}",
+						},
+					},
+				}
+				return Config{
+					ProwConfig: ProwConfig{
+						SlackReporterConfigs: slackCfg,
+					},
+				}
+			},
+			successExpected: false,
+		},
+		{
+			name: "Valid mentions_on_failure - no error",
+			config: func() Config {
+				slackCfg := map[string]SlackReporter{
+					"*": {
+						Channels:          []string{"team-channel"},
+						MentionsOnFailure: []string{"U123", "W456", "S789"},
+					},
+				}
+				return Config{
+					ProwConfig: ProwConfig{
+						SlackReporterConfigs: slackCfg,
+					},
+				}
+			},
+			successExpected: true,
+		},
+		{
+			name: "Invalid mention in mentions_on_failure - error",
+			config: func() Config {
+				slackCfg := map[string]SlackReporter{
+					"*": {
+						Channels:          []string{"team-channel"},
+						MentionsOnFailure: []string{"@oncall"},
+					},
+				}
+				return Config{
+					ProwConfig: ProwConfig{
+						SlackReporterConfigs: slackCfg,
+					},
+				}
+			},
+			successExpected: false,
+		},
+		{
+			name: "Valid state_emojis - no error",
+			config: func() Config {
+				slackCfg := map[string]SlackReporter{
+					"*": {
+						Channels:    []string{"team-channel"},
+						StateEmojis: map[prowapi.ProwJobState]string{prowapi.FailureState: ":fire:", prowapi.SuccessState: ":sunny:"},
+					},
+				}
+				return Config{
+					ProwConfig: ProwConfig{
+						SlackReporterConfigs: slackCfg,
+					},
+				}
+			},
+			successExpected: true,
+		},
+		{
+			name: "Empty emoji in state_emojis - error",
+			config: func() Config {
+				slackCfg := map[string]SlackReporter{
+					"*": {
+						Channels:    []string{"team-channel"},
+						StateEmojis: map[prowapi.ProwJobState]string{prowapi.FailureState: " "},
+					},
+				}
+				return Config{
+					ProwConfig: ProwConfig{
+						SlackReporterConfigs: slackCfg,
+					},
+				}
+			},
+			successExpected: false,
+		},
+		{
+			name: "Invalid state in state_emojis - error",
+			config: func() Config {
+				slackCfg := map[string]SlackReporter{
+					"*": {
+						Channels:    []string{"team-channel"},
+						StateEmojis: map[prowapi.ProwJobState]string{"broken": ":fire:"},
+					},
+				}
+				return Config{
+					ProwConfig: ProwConfig{
+						SlackReporterConfigs: slackCfg,
+					},
+				}
+			},
+			successExpected: false,
+		},
+		{
+			name: "Valid channel_topics - no error",
+			config: func() Config {
+				slackCfg := map[string]SlackReporter{
+					"*": {
+						Channels:      []string{"team-channel"},
+						ChannelTopics: map[string]SlackChannelTopic{"status": {Jobs: []string{"periodic-main"}, Template: "main: {{.Status.State}}"}},
+					},
+				}
+				return Config{
+					ProwConfig: ProwConfig{
+						SlackReporterConfigs: slackCfg,
+					},
+				}
+			},
+			successExpected: true,
+		},
+		{
+			name: "channel_topics without jobs - error",
+			config: func() Config {
+				slackCfg := map[string]SlackReporter{
+					"*": {
+						Channels:      []string{"team-channel"},
+						ChannelTopics: map[string]SlackChannelTopic{"status": {}},
+					},
+				}
+				return Config{
+					ProwConfig: ProwConfig{
+						SlackReporterConfigs: slackCfg,
+					},
+				}
+			},
+			successExpected: false,
+		},
+		{
+			name: "channel_topics with invalid template - error",
+			config: func() Config {
+				slackCfg := map[string]SlackReporter{
+					"*": {
+						Channels:      []string{"team-channel"},
+						ChannelTopics: map[string]SlackChannelTopic{"status": {Jobs: []string{"periodic-main"}, Template: "{{.Status.State"}},
+					},
+				}
+				return Config{
+					ProwConfig: ProwConfig{
+						SlackReporterConfigs: slackCfg,
+					},
+				}
+			},
+			successExpected: false,
+		},
+		{
+			name: "Negative coalesce_window - error",
+			config: func() Config {
+				slackCfg := map[string]SlackReporter{
+					"*": {
+						CoalesceWindow: &metav1.Duration{Duration: -time.Minute},
+						SlackReporterConfig: prowapi.SlackReporterConfig{
+							Channel: "my-channel",
+						},
+					},
+				}
+				return Config{
+					ProwConfig: ProwConfig{
+						SlackReporterConfigs: slackCfg,
+					},
+				}
+			},
+			successExpected: false,
+		},
+		{
+			name: "Valid config w/ repo slack_reporter_configs - no error",
+			config: func() Config {
+				slackCfg := map[string]SlackReporter{
+					"proxy": {
+						SlackReporterConfig: prowapi.SlackReporterConfig{
+							Channel: "my-channel",
+						},
+					},
+				}
+				return Config{
+					ProwConfig: ProwConfig{
+						SlackReporterConfigs: slackCfg,
+					},
+				}
+			},
+			successExpected: true,
+		},
+		{
+			name: "No channel w/ slack_reporter_configs - error",
+			config: func() Config {
+				slackCfg := map[string]SlackReporter{
+					"*": {
+						JobTypesToReport: []prowapi.ProwJobType{"presubmit"},
+					},
+				}
+				return Config{
+					ProwConfig: ProwConfig{
+						SlackReporterConfigs: slackCfg,
+					},
+				}
+			},
+			successExpected: false,
+		},
+		{
+			name: "Empty config - no error",
+			config: func() Config {
 				slackCfg := map[string]SlackReporter{}
 				return Config{
 					ProwConfig: ProwConfig{
@@ -5012,6 +7236,145 @@ func TestSlackReporterValidation(t *testing.T) {
 			},
 			successExpected: false,
 		},
+		{
+			name: "Repo inheriting the channel of the default - no error",
+			config: func() Config {
+				slackCfg := map[string]SlackReporter{
+					"*": {
+						SlackReporterConfig: prowapi.SlackReporterConfig{
+							Channel: "my-channel",
+						},
+					},
+					"istio/proxy": {
+						MentionsOnFailure: []string{"S123"},
+					},
+				}
+				return Config{
+					ProwConfig: ProwConfig{
+						SlackReporterConfigs: slackCfg,
+					},
+				}
+			},
+			successExpected: true,
+		},
+		{
+			name: "Valid quiet_hours spanning midnight - no error",
+			config: func() Config {
+				slackCfg := map[string]SlackReporter{
+					"*": {
+						Channels:   []string{"team-channel"},
+						QuietHours: &SlackQuietHours{TimeZone: "Europe/Berlin", Start: "20:00", End: "08:00"},
+					},
+				}
+				return Config{
+					ProwConfig: ProwConfig{
+						SlackReporterConfigs: slackCfg,
+					},
+				}
+			},
+			successExpected: true,
+		},
+		{
+			name: "Unknown time zone in quiet_hours - error",
+			config: func() Config {
+				slackCfg := map[string]SlackReporter{
+					"*": {
+						Channels:   []string{"team-channel"},
+						QuietHours: &SlackQuietHours{TimeZone: "Mars/Olympus_Mons", Start: "20:00", End: "08:00"},
+					},
+				}
+				return Config{
+					ProwConfig: ProwConfig{
+						SlackReporterConfigs: slackCfg,
+					},
+				}
+			},
+			successExpected: false,
+		},
+		{
+			name: "Invalid start in quiet_hours - error",
+			config: func() Config {
+				slackCfg := map[string]SlackReporter{
+					"*": {
+						Channels:   []string{"team-channel"},
+						QuietHours: &SlackQuietHours{Start: "8pm", End: "08:00"},
+					},
+				}
+				return Config{
+					ProwConfig: ProwConfig{
+						SlackReporterConfigs: slackCfg,
+					},
+				}
+			},
+			successExpected: false,
+		},
+		{
+			name: "Equal start and end in quiet_hours - error",
+			config: func() Config {
+				slackCfg := map[string]SlackReporter{
+					"*": {
+						Channels:   []string{"team-channel"},
+						QuietHours: &SlackQuietHours{Start: "08:00", End: "08:00"},
+					},
+				}
+				return Config{
+					ProwConfig: ProwConfig{
+						SlackReporterConfigs: slackCfg,
+					},
+				}
+			},
+			successExpected: false,
+		},
+		{
+			name: "Valid grouping_annotation - no error",
+			config: func() Config {
+				slackCfg := map[string]SlackReporter{
+					"*": {
+						Channels:           []string{"team-channel"},
+						GroupingAnnotation: "example.com/workflow-run",
+					},
+				}
+				return Config{
+					ProwConfig: ProwConfig{
+						SlackReporterConfigs: slackCfg,
+					},
+				}
+			},
+			successExpected: true,
+		},
+		{
+			name: "Invalid grouping_annotation - error",
+			config: func() Config {
+				slackCfg := map[string]SlackReporter{
+					"*": {
+						Channels:           []string{"team-channel"},
+						GroupingAnnotation: "workflow run",
+					},
+				}
+				return Config{
+					ProwConfig: ProwConfig{
+						SlackReporterConfigs: slackCfg,
+					},
+				}
+			},
+			successExpected: false,
+		},
+		{
+			name: "Org without channel and without default - error",
+			config: func() Config {
+				slackCfg := map[string]SlackReporter{
+					"istio": {
+						MentionsOnFailure: []string{"S123"},
+					},
+				}
+				return Config{
+					ProwConfig: ProwConfig{
+						SlackReporterConfigs: slackCfg,
+					},
+				}
+			},
+			successExpected: false,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -5021,18 +7384,249 @@ func TestSlackReporterValidation(t *testing.T) {
 				t.Errorf("Expected success=%t but got err=%v", tc.successExpected, err)
 			}
 			if tc.successExpected {
-				for _, config := range cfg.SlackReporterConfigs {
+				for k := range cfg.SlackReporterConfigs {
+					config := cfg.SlackReporterConfigs.merged(k)
 					if config.ReportTemplate == "" {
 						t.Errorf("expected default ReportTemplate to be set")
 					}
-					if config.Channel == "" {
-						t.Errorf("expected Channel to be required")
+					if config.Channel == "" && len(config.Channels) == 0 {
+						t.Errorf("expected Channel or Channels to be required")
 					}
 				}
 			}
 		})
 	}
 }
+
+func TestSlackQuietHoursContains(t *testing.T) {
+	berlin := &SlackQuietHours{TimeZone: "Europe/Berlin", Start: "20:00", End: "08:00"}
+	newYork := &SlackQuietHours{TimeZone: "America/New_York", Start: "01:30", End: "02:30"}
+	utc := &SlackQuietHours{Start: "09:00", End: "17:00"}
+	testCases := []struct {
+		name       string
+		quietHours *SlackQuietHours
+		time       time.Time
+		expected   bool
+	}{
+		{
+			name:       "UTC by default, inside",
+			quietHours: utc,
+			time:       time.Date(2024, time.June, 1, 9, 0, 0, 0, time.UTC),
+			expected:   true,
+		},
+		{
+			name:       "UTC by default, end is exclusive",
+			quietHours: utc,
+			time:       time.Date(2024, time.June, 1, 17, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "time in another zone is converted",
+			quietHours: utc,
+			time:       time.Date(2024, time.June, 1, 8, 30, 0, 0, time.FixedZone("UTC-1", -60*60)),
+			expected:   true,
+		},
+		{
+			name:       "spanning midnight, before midnight",
+			quietHours: berlin,
+			time:       time.Date(2024, time.January, 15, 22, 0, 0, 0, time.UTC),
+			expected:   true,
+		},
+		{
+			name:       "spanning midnight, after midnight",
+			quietHours: berlin,
+			time:       time.Date(2024, time.January, 16, 2, 0, 0, 0, time.UTC),
+			expected:   true,
+		},
+		{
+			name:       "spanning midnight, during the day",
+			quietHours: berlin,
+			time:       time.Date(2024, time.January, 16, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "same UTC time is quiet in winter time",
+			quietHours: berlin,
+			time:       time.Date(2024, time.January, 16, 6, 30, 0, 0, time.UTC),
+			expected:   true,
+		},
+		{
+			name:       "same UTC time is not quiet in summer time",
+			quietHours: berlin,
+			time:       time.Date(2024, time.July, 16, 6, 30, 0, 0, time.UTC),
+		},
+		{
+			name:       "UTC date differs from local date",
+			quietHours: berlin,
+			time:       time.Date(2024, time.July, 16, 18, 30, 0, 0, time.UTC),
+			expected:   true,
+		},
+		{
+			name:       "before the clocks spring forward",
+			quietHours: newYork,
+			time:       time.Date(2024, time.March, 10, 6, 59, 0, 0, time.UTC),
+			expected:   true,
+		},
+		{
+			name:       "after the clocks spring forward past the end",
+			quietHours: newYork,
+			time:       time.Date(2024, time.March, 10, 7, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "first 01:45 when the clocks fall back",
+			quietHours: newYork,
+			time:       time.Date(2024, time.November, 3, 5, 45, 0, 0, time.UTC),
+			expected:   true,
+		},
+		{
+			name:       "second 01:45 when the clocks fall back",
+			quietHours: newYork,
+			time:       time.Date(2024, time.November, 3, 6, 45, 0, 0, time.UTC),
+			expected:   true,
+		},
+		{
+			name:       "02:30 after the clocks fall back",
+			quietHours: newYork,
+			time:       time.Date(2024, time.November, 3, 7, 30, 0, 0, time.UTC),
+		},
+		{
+			name:       "invalid quiet hours never contain",
+			quietHours: &SlackQuietHours{TimeZone: "Mars/Olympus_Mons", Start: "00:00", End: "23:59"},
+			time:       time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := tc.quietHours.Contains(tc.time); actual != tc.expected {
+				t.Errorf("expected Contains(%s) to be %t, got %t", tc.time, tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestGetSlackReporter(t *testing.T) {
+	configs := SlackReporterConfigs{
+		"*": {
+			JobTypesToReport: []prowapi.ProwJobType{prowapi.PeriodicJob},
+			Channels:         []string{"dashboard"},
+			JobStatesToMessages: map[prowapi.ProwJobState]string{
+				prowapi.FailureState: "default failure",
+				prowapi.ErrorState:   "default error",
+			},
+			SlackReporterConfig: prowapi.SlackReporterConfig{
+				Channel:           "default-channel",
+				JobStatesToReport: []prowapi.ProwJobState{prowapi.FailureState},
+				ReportTemplate:    "default template",
+			},
+		},
+		"org": {
+			ReplyInThread: true,
+			JobStatesToMessages: map[prowapi.ProwJobState]string{
+				prowapi.FailureState: "org failure",
+			},
+			StateEmojis: map[prowapi.ProwJobState]string{
+				prowapi.FailureState: ":fire:",
+			},
+			SlackReporterConfig: prowapi.SlackReporterConfig{
+				Channel: "org-channel",
+			},
+		},
+		"org/repo": {
+			JobTypesToReport: []prowapi.ProwJobType{prowapi.PresubmitJob},
+			StateEmojis: map[prowapi.ProwJobState]string{
+				prowapi.SuccessState: ":sunny:",
+			},
+			SlackReporterConfig: prowapi.SlackReporterConfig{
+				Channel:        "repo-channel",
+				ReportTemplate: "repo template",
+			},
+		},
+	}
+	defaultConfig := SlackReporter{
+		JobTypesToReport: []prowapi.ProwJobType{prowapi.PeriodicJob},
+		Channels:         []string{"dashboard"},
+		JobStatesToMessages: map[prowapi.ProwJobState]string{
+			prowapi.FailureState: "default failure",
+			prowapi.ErrorState:   "default error",
+		},
+		SlackReporterConfig: prowapi.SlackReporterConfig{
+			Channel:           "default-channel",
+			JobStatesToReport: []prowapi.ProwJobState{prowapi.FailureState},
+			ReportTemplate:    "default template",
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		refs     *prowapi.Refs
+		expected SlackReporter
+	}{
+		{
+			name:     "no refs get the default",
+			expected: defaultConfig,
+		},
+		{
+			name:     "unconfigured org gets the default",
+			refs:     &prowapi.Refs{Org: "other", Repo: "repo"},
+			expected: defaultConfig,
+		},
+		{
+			name: "org is merged over the default",
+			refs: &prowapi.Refs{Org: "org", Repo: "other"},
+			expected: SlackReporter{
+				JobTypesToReport: []prowapi.ProwJobType{prowapi.PeriodicJob},
+				Channels:         []string{"dashboard"},
+				ReplyInThread:    true,
+				JobStatesToMessages: map[prowapi.ProwJobState]string{
+					prowapi.FailureState: "org failure",
+					prowapi.ErrorState:   "default error",
+				},
+				StateEmojis: map[prowapi.ProwJobState]string{
+					prowapi.FailureState: ":fire:",
+				},
+				SlackReporterConfig: prowapi.SlackReporterConfig{
+					Channel:           "org-channel",
+					JobStatesToReport: []prowapi.ProwJobState{prowapi.FailureState},
+					ReportTemplate:    "default template",
+				},
+			},
+		},
+		{
+			name: "repo is merged over the org and the default",
+			refs: &prowapi.Refs{Org: "org", Repo: "repo"},
+			expected: SlackReporter{
+				JobTypesToReport: []prowapi.ProwJobType{prowapi.PresubmitJob},
+				Channels:         []string{"dashboard"},
+				ReplyInThread:    true,
+				JobStatesToMessages: map[prowapi.ProwJobState]string{
+					prowapi.FailureState: "org failure",
+					prowapi.ErrorState:   "default error",
+				},
+				StateEmojis: map[prowapi.ProwJobState]string{
+					prowapi.FailureState: ":fire:",
+					prowapi.SuccessState: ":sunny:",
+				},
+				SlackReporterConfig: prowapi.SlackReporterConfig{
+					Channel:           "repo-channel",
+					JobStatesToReport: []prowapi.ProwJobState{prowapi.FailureState},
+					ReportTemplate:    "repo template",
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, configs.GetSlackReporter(tc.refs)); diff != "" {
+				t.Errorf("config differs from expected (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if len(configs["org"].JobStatesToMessages) != 1 {
+		t.Errorf("merging modified the config of the org: %v", configs["org"].JobStatesToMessages)
+	}
+}
+
 func TestManagedHmacEntityValidation(t *testing.T) {
 	testCases := []struct {
 		name       string
@@ -8531,6 +11125,7 @@ branch-protection:
 			expectedProwConfig: `branch-protection:
   allow_disabled_job_policies: true
 config_version_sha: abc
+crier: {}
 deck:
   spyglass:
     gcs_browser_prefixes:
@@ -8615,6 +11210,7 @@ tide:
   merge_method:
     foo/bar: squash`},
 			expectedProwConfig: `branch-protection: {}
+crier: {}
 deck:
   spyglass:
     gcs_browser_prefixes:
@@ -8692,6 +11288,7 @@ tide:
     - another/repo
 `},
 			expectedProwConfig: `branch-protection: {}
+crier: {}
 deck:
   spyglass:
     gcs_browser_prefixes:
@@ -8774,6 +11371,7 @@ slack_reporter_configs:
 			},
 			expectedProwConfig: `branch-protection: {}
 config_version_sha: abc
+crier: {}
 deck:
   spyglass:
     gcs_browser_prefixes:
@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// kubernetesSecretResyncPeriod is how often Kubernetes Secrets are re-read.
+const kubernetesSecretResyncPeriod = 30 * time.Second
+
+// KubernetesSecretRef identifies a key of a Kubernetes Secret.
+type KubernetesSecretRef struct {
+	Namespace string
+	Name      string
+	Key       string
+}
+
+// ParseKubernetesSecretRef parses a reference written as namespace/name/key.
+func ParseKubernetesSecretRef(value string) (KubernetesSecretRef, error) {
+	parts := strings.Split(value, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return KubernetesSecretRef{}, fmt.Errorf("%q is not of the form namespace/name/key", value)
+	}
+	return KubernetesSecretRef{Namespace: parts[0], Name: parts[1], Key: parts[2]}, nil
+}
+
+// String returns the reference as namespace/name/key, which is also the key
+// its value is stored under in the agent.
+func (r KubernetesSecretRef) String() string {
+	return r.Namespace + "/" + r.Name + "/" + r.Key
+}
+
+// AddKubernetesSecret registers the key of a Kubernetes Secret to the agent.
+// The value is read through the client and re-read periodically, so that
+// changes to the Secret are picked up. A Secret that doesn't exist yet is
+// not an error, its value is empty until it is created. The value can be
+// read with GetSecret or GetTokenGenerator using ref.String().
+func AddKubernetesSecret(client ctrlruntimeclient.Reader, ref KubernetesSecretRef) error {
+	return secretAgent.add(ref.String(), &kubernetesSecretReloader{
+		client: client,
+		ref:    ref,
+	})
+}
+
+type kubernetesSecretReloader struct {
+	client ctrlruntimeclient.Reader
+	ref    KubernetesSecretRef
+
+	lock     sync.RWMutex
+	rawValue []byte
+}
+
+func (k *kubernetesSecretReloader) start(reloadCensor func()) error {
+	if _, err := k.refresh(context.Background()); err != nil {
+		return err
+	}
+	reloadCensor()
+
+	go func() {
+		for range time.Tick(kubernetesSecretResyncPeriod) {
+			changed, err := k.refresh(context.Background())
+			if err != nil {
+				logrus.WithField("secret", k.ref.String()).WithError(err).Error("Error loading secret.")
+				continue
+			}
+			if changed {
+				reloadCensor()
+			}
+		}
+	}()
+	return nil
+}
+
+// refresh reads the value from the Secret and returns whether it changed.
+// A missing Secret or key is logged and leaves the value empty.
+func (k *kubernetesSecretReloader) refresh(ctx context.Context) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	log := logrus.WithField("secret", k.ref.String())
+	var value []byte
+	var secret corev1.Secret
+	if err := k.client.Get(ctx, types.NamespacedName{Namespace: k.ref.Namespace, Name: k.ref.Name}, &secret); err != nil {
+		if !kerrors.IsNotFound(err) {
+			return false, fmt.Errorf("error reading secret %s: %w", k.ref, err)
+		}
+		log.Warn("Secret doesn't exist, waiting for it to be created.")
+	} else if data, ok := secret.Data[k.ref.Key]; !ok {
+		log.Warn("Secret doesn't have the key, waiting for it to be added.")
+	} else {
+		value = bytes.TrimSpace(data)
+	}
+
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	if bytes.Equal(k.rawValue, value) {
+		return false, nil
+	}
+	k.rawValue = value
+	return true, nil
+}
+
+func (k *kubernetesSecretReloader) getRaw() []byte {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+	return k.rawValue
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestParseKubernetesSecretRef(t *testing.T) {
+	testCases := []struct {
+		value     string
+		expected  KubernetesSecretRef
+		expectErr bool
+	}{
+		{
+			value:    "ns/slack/token",
+			expected: KubernetesSecretRef{Namespace: "ns", Name: "slack", Key: "token"},
+		},
+		{
+			value:     "slack/token",
+			expectErr: true,
+		},
+		{
+			value:     "ns//token",
+			expectErr: true,
+		},
+		{
+			value:     "ns/slack/token/extra",
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			ref, err := ParseKubernetesSecretRef(tc.value)
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("expected error: %t, got: %v", tc.expectErr, err)
+			}
+			if ref != tc.expected {
+				t.Errorf("expected %+v, got %+v", tc.expected, ref)
+			}
+			if err == nil && ref.String() != tc.value {
+				t.Errorf("expected %q to round-trip, got %q", tc.value, ref.String())
+			}
+		})
+	}
+}
+
+func TestKubernetesSecretReloader(t *testing.T) {
+	client := fakectrlruntimeclient.NewClientBuilder().Build()
+	ref := KubernetesSecretRef{Namespace: "ns", Name: "slack", Key: "token"}
+	reloader := &kubernetesSecretReloader{client: client, ref: ref}
+	ctx := context.Background()
+
+	// A missing secret isn't an error, so crier can start before it exists.
+	if err := reloader.start(func() {}); err != nil {
+		t.Fatalf("expected missing secret not to fail, got: %v", err)
+	}
+	if value := reloader.getRaw(); value != nil {
+		t.Errorf("expected empty value for missing secret, got %q", value)
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "slack"},
+		Data:       map[string][]byte{"other": []byte("value")},
+	}
+	if err := client.Create(ctx, secret); err != nil {
+		t.Fatalf("failed to create secret: %v", err)
+	}
+	if changed, err := reloader.refresh(ctx); err != nil || changed {
+		t.Errorf("expected missing key to leave the value unchanged, got changed=%t, err=%v", changed, err)
+	}
+
+	secret.Data["token"] = []byte("xoxb-1\n")
+	if err := client.Update(ctx, secret); err != nil {
+		t.Fatalf("failed to update secret: %v", err)
+	}
+	if changed, err := reloader.refresh(ctx); err != nil || !changed {
+		t.Errorf("expected added key to change the value, got changed=%t, err=%v", changed, err)
+	}
+	if value := string(reloader.getRaw()); value != "xoxb-1" {
+		t.Errorf("expected value %q, got %q", "xoxb-1", value)
+	}
+
+	secret.Data["token"] = []byte("xoxb-2")
+	if err := client.Update(ctx, secret); err != nil {
+		t.Fatalf("failed to update secret: %v", err)
+	}
+	if _, err := reloader.refresh(ctx); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if value := string(reloader.getRaw()); value != "xoxb-2" {
+		t.Errorf("expected rotated value %q, got %q", "xoxb-2", value)
+	}
+}
@@ -0,0 +1,37 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+)
+
+// WithReportAgent makes the controller only report jobs run by the given
+// agent, e.g. `kubernetes`, so that jobs of other agents can be left to
+// another crier. Jobs of other agents are skipped like with
+// WithSkipReporting. An empty agent reports the jobs of all agents.
+func WithReportAgent(agent prowv1.ProwJobAgent) Option {
+	return func(o *Options) {
+		o.ReportAgent = agent
+	}
+}
+
+// matchesAgent returns whether the job is run by the agent, which matches
+// all jobs if it is empty.
+func matchesAgent(agent prowv1.ProwJobAgent, pj *prowv1.ProwJob) bool {
+	return agent == "" || pj.Spec.Agent == agent
+}
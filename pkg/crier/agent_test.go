@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlruntime "sigs.k8s.io/controller-runtime"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	gcsreporter "sigs.k8s.io/prow/pkg/crier/reporters/gcs"
+	"sigs.k8s.io/prow/pkg/io/fakeopener"
+)
+
+func TestReconcileReportAgent(t *testing.T) {
+	testCases := []struct {
+		name           string
+		reportAgent    prowv1.ProwJobAgent
+		jobAgent       prowv1.ProwJobAgent
+		expectReported bool
+	}{
+		{
+			name:           "job of the agent is reported",
+			reportAgent:    prowv1.KubernetesAgent,
+			jobAgent:       prowv1.KubernetesAgent,
+			expectReported: true,
+		},
+		{
+			name:        "job of another agent is skipped",
+			reportAgent: prowv1.KubernetesAgent,
+			jobAgent:    prowv1.JenkinsAgent,
+		},
+		{
+			name:           "jobs of all agents are reported without an agent",
+			jobAgent:       prowv1.JenkinsAgent,
+			expectReported: true,
+		},
+	}
+
+	cfg := func() *config.Config {
+		return &config.Config{ProwConfig: config.ProwConfig{
+			Plank: config.Plank{
+				DefaultDecorationConfigs: config.DefaultDecorationMapToSliceTesting(
+					map[string]*prowv1.DecorationConfig{"*": {
+						GCSConfiguration: &prowv1.GCSConfiguration{
+							Bucket:       "bucket",
+							PathStrategy: prowv1.PathStrategyExplicit,
+						},
+					}}),
+			},
+		}}
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			job := &prowv1.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo"},
+				Spec: prowv1.ProwJobSpec{
+					Type:   prowv1.PeriodicJob,
+					Agent:  tc.jobAgent,
+					Job:    "my-job",
+					Report: true,
+				},
+				Status: prowv1.ProwJobStatus{
+					State:          prowv1.SuccessState,
+					StartTime:      metav1.Now(),
+					CompletionTime: &metav1.Time{},
+					BuildID:        "123",
+				},
+			}
+			client := fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(job).Build()
+			opener := &fakeopener.FakeOpener{}
+			r := &reconciler{
+				pjclientset:       client,
+				reporter:          gcsreporter.New(cfg, opener, false, 0),
+				enablementChecker: func(_, _ string) bool { return true },
+				reportAgent:       tc.reportAgent,
+			}
+			if _, err := r.Reconcile(context.Background(), ctrlruntime.Request{NamespacedName: types.NamespacedName{Name: "foo"}}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			_, reported := opener.Buffer["gs://bucket/logs/my-job/123/prowjob.json"]
+			if reported != tc.expectReported {
+				t.Errorf("expected reported=%t, got %t, wrote %d files", tc.expectReported, reported, len(opener.Buffer))
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+)
+
+// WithMaxReportAttempts gives up reporting a state of a job once the report
+// failed maxAttempts times in a row, e.g. because the backend permanently
+// rejects it without the reporter recognizing the error as permanent. The
+// state is not reported again, but the next state of the job is. Attempts
+// are counted in memory, so they start over when crier restarts.
+func WithMaxReportAttempts(maxAttempts int) Option {
+	return func(o *Options) {
+		o.MaxReportAttempts = maxAttempts
+	}
+}
+
+// reportAttempts counts the failed reports of the current state of jobs and
+// remembers the states that reporting was given up on. A max of 0 never
+// gives up after failed attempts.
+type reportAttempts struct {
+	max int
+
+	lock sync.Mutex
+	jobs map[types.NamespacedName]*jobAttempts
+}
+
+// jobAttempts are the failed reports of a state of a job.
+type jobAttempts struct {
+	state    prowv1.ProwJobState
+	failures int
+	// dropped is set once the report failed with a permanent error.
+	dropped bool
+}
+
+func newReportAttempts(maxAttempts int) *reportAttempts {
+	return &reportAttempts{
+		max:  maxAttempts,
+		jobs: map[types.NamespacedName]*jobAttempts{},
+	}
+}
+
+// current returns the attempts of the current state of the job, starting
+// over if the job changed state since the last failure.
+func (a *reportAttempts) current(pj *prowv1.ProwJob) *jobAttempts {
+	key := types.NamespacedName{Namespace: pj.Namespace, Name: pj.Name}
+	attempts, ok := a.jobs[key]
+	if !ok || attempts.state != pj.Status.State {
+		attempts = &jobAttempts{state: pj.Status.State}
+		a.jobs[key] = attempts
+	}
+	return attempts
+}
+
+// exhausted tells whether reporting the current state of the job was given
+// up on.
+func (a *reportAttempts) exhausted(pj *prowv1.ProwJob) bool {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	attempts := a.current(pj)
+	return attempts.dropped || (a.max > 0 && attempts.failures >= a.max)
+}
+
+// failed records a failed report of the current state of the job and
+// returns the number of failures so far and whether that is the last
+// attempt.
+func (a *reportAttempts) failed(pj *prowv1.ProwJob) (int, bool) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	attempts := a.current(pj)
+	attempts.failures++
+	return attempts.failures, a.max > 0 && attempts.failures >= a.max
+}
+
+// drop gives up reporting the current state of the job, e.g. because the
+// report failed with a permanent error.
+func (a *reportAttempts) drop(pj *prowv1.ProwJob) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.current(pj).dropped = true
+}
+
+// forget drops the attempts of the job, e.g. once it was reported or
+// deleted.
+func (a *reportAttempts) forget(key types.NamespacedName) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	delete(a.jobs, key)
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlruntime "sigs.k8s.io/controller-runtime"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/crier/reporters/criercommonlib"
+)
+
+func TestMaxReportAttempts(t *testing.T) {
+	const toReconcile = "foo"
+	pj := &prowv1.ProwJob{
+		ObjectMeta: v1.ObjectMeta{Name: toReconcile},
+		Spec:       prowv1.ProwJobSpec{Job: "foo"},
+		Status:     prowv1.ProwJobStatus{State: prowv1.PendingState},
+	}
+	cs := fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(pj).Build()
+	rp := &fakeReporter{
+		shouldReportFunc: func(*prowv1.ProwJob) bool { return true },
+		err:              errors.New("channel is archived"),
+	}
+	r := &reconciler{
+		pjclientset: cs,
+		reporter:    rp,
+		attempts:    newReportAttempts(3),
+	}
+	req := ctrlruntime.Request{NamespacedName: types.NamespacedName{Name: toReconcile}}
+	dropped := testutil.ToFloat64(crierMetrics.reportsDropped.WithLabelValues(reporterName))
+
+	for i := 1; i <= 5; i++ {
+		_, err := r.Reconcile(context.Background(), req)
+		// The first failures are retried, the last one is dropped and
+		// later reconciles of the same state don't report at all.
+		if expectErr := i < 3; (err != nil) != expectErr {
+			t.Errorf("reconcile %d: expected error: %t, got %v", i, expectErr, err)
+		}
+	}
+	if len(rp.reported) != 3 {
+		t.Errorf("expected 3 report attempts, got %d", len(rp.reported))
+	}
+	if got := testutil.ToFloat64(crierMetrics.reportsDropped.WithLabelValues(reporterName)) - dropped; got != 1 {
+		t.Errorf("expected one dropped report, got %v", got)
+	}
+
+	// The next state of the job gets its own attempts.
+	var updated prowv1.ProwJob
+	if err := cs.Get(context.Background(), req.NamespacedName, &updated); err != nil {
+		t.Fatalf("failed to get job: %v", err)
+	}
+	updated.Status.State = prowv1.SuccessState
+	if err := cs.Update(context.Background(), &updated); err != nil {
+		t.Fatalf("failed to update job: %v", err)
+	}
+	rp.err = nil
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rp.reported) != 4 {
+		t.Errorf("expected the new state to be reported, got %d report attempts", len(rp.reported))
+	}
+	if len(r.attempts.jobs) != 0 {
+		t.Errorf("expected attempts to be forgotten once reported, got %v", r.attempts.jobs)
+	}
+}
+
+func TestPermanentErrorIsNotReportedAgain(t *testing.T) {
+	const toReconcile = "foo"
+	pj := &prowv1.ProwJob{
+		ObjectMeta: v1.ObjectMeta{Name: toReconcile},
+		Spec:       prowv1.ProwJobSpec{Job: "foo"},
+		Status:     prowv1.ProwJobStatus{State: prowv1.FailureState},
+	}
+	cs := fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(pj).Build()
+	rp := &fakeReporter{
+		shouldReportFunc: func(*prowv1.ProwJob) bool { return true },
+		err:              criercommonlib.PermanentError(errors.New("channel_not_found")),
+	}
+	// Without a maximum of attempts, like crier's default.
+	r := &reconciler{
+		pjclientset: cs,
+		reporter:    rp,
+		attempts:    newReportAttempts(0),
+	}
+	req := ctrlruntime.Request{NamespacedName: types.NamespacedName{Name: toReconcile}}
+
+	// Later reconciles, e.g. because other reporters updated their report
+	// state, don't report the dropped state again.
+	for i := 1; i <= 3; i++ {
+		if _, err := r.Reconcile(context.Background(), req); err != nil {
+			t.Errorf("reconcile %d: unexpected error: %v", i, err)
+		}
+	}
+	if len(rp.reported) != 1 {
+		t.Errorf("expected 1 report attempt, got %d", len(rp.reported))
+	}
+
+	// The next state of the job is reported.
+	var updated prowv1.ProwJob
+	if err := cs.Get(context.Background(), req.NamespacedName, &updated); err != nil {
+		t.Fatalf("failed to get job: %v", err)
+	}
+	updated.Status.State = prowv1.ErrorState
+	if err := cs.Update(context.Background(), &updated); err != nil {
+		t.Fatalf("failed to update job: %v", err)
+	}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rp.reported) != 2 {
+		t.Errorf("expected the new state to be reported, got %d report attempts", len(rp.reported))
+	}
+}
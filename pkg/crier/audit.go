@@ -0,0 +1,72 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+)
+
+// ReportTargeter can be implemented by reporters to describe where they
+// report a job to, e.g. a Slack channel or a webhook URL. The target is
+// included in audit records. It must not contain secrets.
+type ReportTargeter interface {
+	ReportTarget(pj *prowv1.ProwJob) string
+}
+
+// ResultRequeued is the result of a report the reporter asked to retry
+// later without failing.
+const ResultRequeued = "REQUEUED"
+
+// WithAuditLog makes the controller log a structured audit record for every
+// report, whether it succeeded or not.
+func WithAuditLog() Option {
+	return func(o *Options) {
+		o.AuditLog = true
+	}
+}
+
+// auditRecord logs a report with a fixed set of fields, so that it can be
+// consumed by log pipelines. err is the error returned by the reporter.
+func (r *reconciler) auditRecord(pj *prowv1.ProwJob, prevState prowv1.ProwJobState, result string, duration time.Duration, err error) {
+	if !r.auditLog {
+		return
+	}
+	var target string
+	if targeter, ok := r.reporter.(ReportTargeter); ok {
+		target = targeter.ReportTarget(pj)
+	}
+	var errMsg string
+	if err != nil {
+		errMsg = err.Error()
+	}
+	logrus.WithFields(logrus.Fields{
+		"audit":           "report",
+		"reporter":        r.reporter.GetName(),
+		"prowjob":         pj.Name,
+		"job":             pj.Spec.Job,
+		"previousState":   prevState,
+		"state":           pj.Status.State,
+		"target":          target,
+		"result":          result,
+		"error":           errMsg,
+		"durationSeconds": duration.Seconds(),
+	}).Info("Report audit record.")
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlruntime "sigs.k8s.io/controller-runtime"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+)
+
+type targetingReporter struct {
+	fakeReporter
+}
+
+func (r *targetingReporter) ReportTarget(pj *prowv1.ProwJob) string {
+	return "#" + pj.Spec.Job
+}
+
+func TestReconcileAuditRecord(t *testing.T) {
+	testCases := []struct {
+		name      string
+		auditLog  bool
+		reportErr error
+		expected  []logrus.Fields
+	}{
+		{
+			name: "audit log disabled",
+		},
+		{
+			name:     "successful report",
+			auditLog: true,
+			expected: []logrus.Fields{{
+				"audit":         "report",
+				"reporter":      reporterName,
+				"prowjob":       "foo",
+				"job":           "my-job",
+				"previousState": prowv1.PendingState,
+				"state":         prowv1.SuccessState,
+				"target":        "#my-job",
+				"result":        ResultSuccess,
+				"error":         "",
+			}},
+		},
+		{
+			name:      "failed report",
+			auditLog:  true,
+			reportErr: errors.New("channel not found"),
+			expected: []logrus.Fields{{
+				"audit":         "report",
+				"reporter":      reporterName,
+				"prowjob":       "foo",
+				"job":           "my-job",
+				"previousState": prowv1.PendingState,
+				"state":         prowv1.SuccessState,
+				"target":        "#my-job",
+				"result":        ResultError,
+				"error":         "channel not found",
+			}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			job := &prowv1.ProwJob{
+				Spec: prowv1.ProwJobSpec{Job: "my-job", Report: true},
+				Status: prowv1.ProwJobStatus{
+					State:            prowv1.SuccessState,
+					PrevReportStates: map[string]prowv1.ProwJobState{reporterName: prowv1.PendingState},
+				},
+			}
+			job.Name = "foo"
+			r := &reconciler{
+				pjclientset: fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(job).Build(),
+				reporter: &targetingReporter{fakeReporter{
+					shouldReportFunc: func(*prowv1.ProwJob) bool { return true },
+					err:              tc.reportErr,
+				}},
+				auditLog: tc.auditLog,
+			}
+
+			hook := logrustest.NewGlobal()
+			defer logrus.StandardLogger().ReplaceHooks(logrus.LevelHooks{})
+			r.Reconcile(context.Background(), ctrlruntime.Request{NamespacedName: types.NamespacedName{Name: "foo"}})
+
+			var records []logrus.Fields
+			for _, entry := range hook.AllEntries() {
+				if entry.Data["audit"] == nil {
+					continue
+				}
+				if _, ok := entry.Data["durationSeconds"].(float64); !ok {
+					t.Errorf("expected durationSeconds to be set, got %v", entry.Data["durationSeconds"])
+				}
+				delete(entry.Data, "durationSeconds")
+				records = append(records, entry.Data)
+			}
+			if diff := cmp.Diff(tc.expected, records); diff != "" {
+				t.Errorf("audit records differ from expected: %s", diff)
+			}
+		})
+	}
+}
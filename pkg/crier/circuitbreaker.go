@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"sync"
+	"time"
+)
+
+// CircuitBreakerOptions configures the circuit breaker of a crier controller.
+type CircuitBreakerOptions struct {
+	// FailureThreshold is the number of consecutive failed reports after
+	// which the breaker opens.
+	FailureThreshold int
+	// CoolDown is how long the breaker stays open before a single probe
+	// report is let through.
+	CoolDown time.Duration
+}
+
+type breakerState int
+
+// The values are exposed through the crier_circuit_breaker_state gauge.
+const (
+	breakerClosed   breakerState = 0
+	breakerOpen     breakerState = 1
+	breakerHalfOpen breakerState = 2
+)
+
+// circuitBreaker stops a reporter from hammering a backend that keeps
+// failing. After FailureThreshold consecutive failures no reports are let
+// through until CoolDown has passed, after which one probe report is
+// attempted. A successful probe closes the breaker, a failed one opens it
+// again.
+type circuitBreaker struct {
+	reporter string
+	opts     CircuitBreakerOptions
+	now      func() time.Time
+
+	lock     sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+func newCircuitBreaker(reporter string, opts CircuitBreakerOptions) *circuitBreaker {
+	cb := &circuitBreaker{
+		reporter: reporter,
+		opts:     opts,
+		now:      time.Now,
+	}
+	cb.setState(breakerClosed)
+	return cb
+}
+
+// allow returns whether a report may be attempted. If not, it returns how
+// long to wait before trying again.
+func (cb *circuitBreaker) allow() (bool, time.Duration) {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	switch cb.state {
+	case breakerOpen:
+		if elapsed := cb.now().Sub(cb.openedAt); elapsed < cb.opts.CoolDown {
+			return false, cb.opts.CoolDown - elapsed
+		}
+		cb.setState(breakerHalfOpen)
+		return true, 0
+	case breakerHalfOpen:
+		// A probe is already in flight.
+		return false, cb.opts.CoolDown
+	default:
+		return true, 0
+	}
+}
+
+// record records the outcome of a report attempt.
+func (cb *circuitBreaker) record(success bool) {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	if success {
+		cb.failures = 0
+		cb.setState(breakerClosed)
+		return
+	}
+
+	cb.failures++
+	if cb.state == breakerHalfOpen || cb.failures >= cb.opts.FailureThreshold {
+		cb.openedAt = cb.now()
+		cb.setState(breakerOpen)
+	}
+}
+
+// abandon is called instead of record if a report that was allowed isn't
+// attempted after all. If it was the probe, the breaker goes back to open
+// without restarting the cool down, so the next report is the probe.
+func (cb *circuitBreaker) abandon() {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	if cb.state == breakerHalfOpen {
+		cb.setState(breakerOpen)
+	}
+}
+
+func (cb *circuitBreaker) setState(state breakerState) {
+	cb.state = state
+	crierMetrics.circuitBreakerState.WithLabelValues(cb.reporter).Set(float64(state))
+}
@@ -0,0 +1,232 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlruntime "sigs.k8s.io/controller-runtime"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	cb := newCircuitBreaker("test-breaker", CircuitBreakerOptions{FailureThreshold: 2, CoolDown: time.Minute})
+	cb.now = func() time.Time { return now }
+
+	assertAllowed := func(expected bool) {
+		t.Helper()
+		if allowed, _ := cb.allow(); allowed != expected {
+			t.Fatalf("expected allowed=%t, got %t", expected, allowed)
+		}
+	}
+	assertState := func(expected breakerState) {
+		t.Helper()
+		if cb.state != expected {
+			t.Fatalf("expected state %d, got %d", expected, cb.state)
+		}
+		if gauge := testutil.ToFloat64(crierMetrics.circuitBreakerState.WithLabelValues("test-breaker")); gauge != float64(expected) {
+			t.Fatalf("expected gauge %d, got %v", expected, gauge)
+		}
+	}
+
+	assertAllowed(true)
+	cb.record(false)
+	assertState(breakerClosed)
+	assertAllowed(true)
+	cb.record(false)
+	assertState(breakerOpen)
+
+	now = now.Add(30 * time.Second)
+	if allowed, retryAfter := cb.allow(); allowed || retryAfter != 30*time.Second {
+		t.Fatalf("expected to be told to retry after 30s, got allowed=%t retryAfter=%v", allowed, retryAfter)
+	}
+
+	// Cool down passed, a single probe is let through.
+	now = now.Add(30 * time.Second)
+	assertAllowed(true)
+	assertState(breakerHalfOpen)
+	assertAllowed(false)
+
+	// Failed probe opens the breaker again.
+	cb.record(false)
+	assertState(breakerOpen)
+	assertAllowed(false)
+
+	// Successful probe closes it.
+	now = now.Add(time.Minute)
+	assertAllowed(true)
+	cb.record(true)
+	assertState(breakerClosed)
+	assertAllowed(true)
+}
+
+func TestReconcileWithOpenCircuitBreaker(t *testing.T) {
+	const toReconcile = "foo"
+	job := &prowv1.ProwJob{
+		Spec: prowv1.ProwJobSpec{
+			Job:    "foo",
+			Report: true,
+		},
+		Status: prowv1.ProwJobStatus{
+			State: prowv1.TriggeredState,
+		},
+	}
+	job.Name = toReconcile
+
+	rp := fakeReporter{
+		shouldReportFunc: func(*prowv1.ProwJob) bool { return true },
+		err:              errors.New("backend is down"),
+	}
+	r := &reconciler{
+		pjclientset:    fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(job).Build(),
+		reporter:       &rp,
+		circuitBreaker: newCircuitBreaker(reporterName, CircuitBreakerOptions{FailureThreshold: 1, CoolDown: time.Hour}),
+	}
+	req := ctrlruntime.Request{NamespacedName: types.NamespacedName{Name: toReconcile}}
+
+	if _, err := r.Reconcile(context.Background(), req); err == nil {
+		t.Fatal("expected first reconcile to fail")
+	}
+	result, err := r.Reconcile(context.Background(), req)
+	if err != nil {
+		t.Fatalf("expected no error with open breaker, got %v", err)
+	}
+	if result.RequeueAfter <= 0 || result.RequeueAfter > time.Hour {
+		t.Errorf("expected requeue within the cool down, got %v", result.RequeueAfter)
+	}
+	if len(rp.reported) != 1 {
+		t.Errorf("expected reporter to be called once, got %d calls", len(rp.reported))
+	}
+}
+
+func TestReconcileSkippedByLiveCheckKeepsProbe(t *testing.T) {
+	const toReconcile = "foo"
+	job := &prowv1.ProwJob{
+		Spec: prowv1.ProwJobSpec{
+			Job:    "foo",
+			Report: true,
+		},
+		Status: prowv1.ProwJobStatus{
+			State: prowv1.PendingState,
+		},
+	}
+	job.Name = toReconcile
+	reported := job.DeepCopy()
+	reported.Status.PrevReportStates = map[string]prowv1.ProwJobState{reporterName: prowv1.PendingState}
+
+	cb := newCircuitBreaker(reporterName, CircuitBreakerOptions{FailureThreshold: 1, CoolDown: time.Minute})
+	cb.record(false)
+	cb.openedAt = cb.now().Add(-time.Hour)
+	rp := &fakeReporter{shouldReportFunc: func(*prowv1.ProwJob) bool { return true }}
+	r := &reconciler{
+		pjclientset:    fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(job).Build(),
+		apiReader:      fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(reported).Build(),
+		reporter:       rp,
+		circuitBreaker: cb,
+	}
+
+	if _, err := r.Reconcile(context.Background(), ctrlruntime.Request{NamespacedName: types.NamespacedName{Name: toReconcile}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The job wasn't reported, so the probe must still be available.
+	if allowed, _ := cb.allow(); !allowed {
+		t.Error("expected the breaker to let a probe through")
+	}
+}
+
+func TestReconcileThrottledKeepsProbe(t *testing.T) {
+	const toReconcile = "foo"
+	job := &prowv1.ProwJob{
+		Spec: prowv1.ProwJobSpec{
+			Job:    "foo",
+			Report: true,
+		},
+		Status: prowv1.ProwJobStatus{
+			State: prowv1.PendingState,
+		},
+	}
+	job.Name = toReconcile
+
+	cb := newCircuitBreaker(reporterName, CircuitBreakerOptions{FailureThreshold: 1, CoolDown: time.Minute})
+	cb.record(false)
+	cb.openedAt = cb.now().Add(-time.Hour)
+	throttle := NewReportThrottle(0.001)
+	if err := throttle.wait(context.Background(), reporterName); err != nil {
+		t.Fatalf("failed to take the only token of the throttle: %v", err)
+	}
+	rp := &fakeReporter{shouldReportFunc: func(*prowv1.ProwJob) bool { return true }}
+	r := &reconciler{
+		pjclientset:    fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(job).Build(),
+		reporter:       rp,
+		circuitBreaker: cb,
+		throttle:       throttle,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := r.Reconcile(ctx, ctrlruntime.Request{NamespacedName: types.NamespacedName{Name: toReconcile}}); err == nil {
+		t.Fatal("expected the throttle to fail")
+	}
+	// The job wasn't reported, so the probe must still be available.
+	if allowed, _ := cb.allow(); !allowed {
+		t.Error("expected the breaker to let a probe through")
+	}
+}
+
+func TestReconcileFailedClaimKeepsProbe(t *testing.T) {
+	const toReconcile = "foo"
+	job := &prowv1.ProwJob{
+		Spec: prowv1.ProwJobSpec{
+			Job:    "foo",
+			Report: true,
+		},
+		Status: prowv1.ProwJobStatus{
+			State: prowv1.PendingState,
+		},
+	}
+	job.Name = toReconcile
+
+	cb := newCircuitBreaker(reporterName, CircuitBreakerOptions{FailureThreshold: 1, CoolDown: time.Minute})
+	cb.record(false)
+	cb.openedAt = cb.now().Add(-time.Hour)
+	rp := &fakeReporter{shouldReportFunc: func(*prowv1.ProwJob) bool { return true }}
+	r := &reconciler{
+		pjclientset:    &crashingClient{Client: fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(job).Build()},
+		reporter:       rp,
+		circuitBreaker: cb,
+		atMostOnce:     true,
+	}
+
+	if _, err := r.Reconcile(context.Background(), ctrlruntime.Request{NamespacedName: types.NamespacedName{Name: toReconcile}}); err == nil {
+		t.Fatal("expected the claim to fail")
+	}
+	if len(rp.reported) != 0 {
+		t.Errorf("expected no report, got %d", len(rp.reported))
+	}
+	// The job wasn't reported, so the probe must still be available.
+	if allowed, _ := cb.allow(); !allowed {
+		t.Error("expected the breaker to let a probe through")
+	}
+}
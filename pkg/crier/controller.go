@@ -23,12 +23,16 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/ratelimiter"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
@@ -39,6 +43,8 @@ type ReportClient interface {
 	// Report reports a Prowjob. The provided logger is already populated with the
 	// prowjob name and the reporter name.
 	// If a reporter wants to defer reporting, it can return a reconcile.Result with a RequeueAfter
+	// The report state is persisted after Report succeeded, so Report may be called again for
+	// the same state if crier stops in between, unless the controller uses WithAtMostOnce.
 	Report(ctx context.Context, log *logrus.Entry, pj *prowv1.ProwJob) ([]*prowv1.ProwJob, *reconcile.Result, error)
 	GetName() string
 	// ShouldReport determines if a ProwJob should be reported. The provided logger
@@ -50,30 +56,272 @@ type ReportClient interface {
 // logging, client connectivity, informing (list and watching)
 // queueing, and handling of resource changes
 type reconciler struct {
-	pjclientset       ctrlruntimeclient.Client
+	pjclientset ctrlruntimeclient.Client
+	// apiReader reads jobs from the API server instead of the cache. It is
+	// only set with WithLiveReportStateCheck.
+	apiReader         ctrlruntimeclient.Reader
 	reporter          ReportClient
 	enablementChecker func(org, repo string) bool
+	circuitBreaker    *circuitBreaker
+	workers           *workerLimiter
+	atMostOnce        bool
+	drainTimeout      time.Duration
+	auditLog          bool
+	reportTimeout     func(reporter string) time.Duration
+	reportedJobs      *ReportedJobs
+	labelSelector     labels.Selector
+	skipReporting     func(*prowv1.ProwJob) bool
+	suppressRetryable func() bool
+	reportAgent       prowv1.ProwJobAgent
+	throttle          *ReportThrottle
+	attempts          *reportAttempts
+	deadLetterSink    *DeadLetterSink
 }
 
-// New constructs a new instance of the crier reconciler.
+// Options are optional settings of a crier controller.
+type Options struct {
+	// CircuitBreaker enables a circuit breaker around the reporter if set.
+	CircuitBreaker *CircuitBreakerOptions
+	// WorkerOverrides returns the number of workers per reporter name that
+	// should be used instead of the number passed to New. It is called on
+	// every reconcile, so that changes take effect without a restart.
+	WorkerOverrides func() map[string]int
+	// AtMostOnce marks a job state as reported before the reporter is
+	// called instead of afterwards. See WithAtMostOnce.
+	AtMostOnce bool
+	// DrainTimeout is how long in-flight reports may continue after the
+	// controller is stopped. See WithDrainTimeout.
+	DrainTimeout time.Duration
+	// RetryBackoff configures how failed reports are retried. See
+	// WithRetryBackoff.
+	RetryBackoff *RetryBackoffOptions
+	// AuditLog logs an audit record for every report. See WithAuditLog.
+	AuditLog bool
+	// Readiness checks the connectivity of the reporter. See WithReadiness.
+	Readiness *Readiness
+	// ReportTimeout returns how long a Report call of the named reporter may
+	// take. See WithReportTimeout.
+	ReportTimeout func(reporter string) time.Duration
+	// ReportedJobs tracks which reporters are done with completed jobs. See
+	// WithSkipReportedJobs.
+	ReportedJobs *ReportedJobs
+	// LabelSelector restricts the jobs that are reconciled. See
+	// WithLabelSelector.
+	LabelSelector labels.Selector
+	// SkipReporting tells whether a job is skipped by the reporter. See
+	// WithSkipReporting.
+	SkipReporting func(*prowv1.ProwJob) bool
+	// SuppressRetryableFailures tells whether failed jobs that are going
+	// to be retried are skipped. See WithSuppressRetryableFailures.
+	SuppressRetryableFailures func() bool
+	// ReportAgent restricts the jobs that are reported to those of an
+	// agent. See WithReportAgent.
+	ReportAgent prowv1.ProwJobAgent
+	// ReportThrottle limits the rate of reports across reporters. See
+	// WithReportThrottle.
+	ReportThrottle *ReportThrottle
+	// MaxReportAttempts is how often the report of a state of a job may
+	// fail before it is given up. See WithMaxReportAttempts.
+	MaxReportAttempts int
+	// DeadLetterSink captures the reports that are dropped. See
+	// WithDeadLetterSink.
+	DeadLetterSink *DeadLetterSink
+	// PrioritizeRecent reconciles the most recently completed jobs first.
+	// See WithPrioritizeRecent.
+	PrioritizeRecent bool
+	// LiveReportStateCheck reads the report state of a job from the API
+	// server before reporting it. See WithLiveReportStateCheck.
+	LiveReportStateCheck bool
+}
+
+// RetryBackoffOptions configure the exponential backoff between retries of
+// a failed report.
+type RetryBackoffOptions struct {
+	// Base is the delay before the first retry, which doubles with every
+	// consecutive failure of the same job.
+	Base time.Duration
+	// Max caps the delay between retries.
+	Max time.Duration
+}
+
+type Option func(*Options)
+
+// WithCircuitBreaker stops calling the reporter for opts.CoolDown after
+// opts.FailureThreshold consecutive reporting failures.
+func WithCircuitBreaker(opts CircuitBreakerOptions) Option {
+	return func(o *Options) {
+		o.CircuitBreaker = &opts
+	}
+}
+
+// WithWorkerOverrides makes the number of workers of the controller follow
+// the value returned by overrides for the reporter's name, falling back to
+// the number of workers passed to New if the reporter isn't listed.
+func WithWorkerOverrides(overrides func() map[string]int) Option {
+	return func(o *Options) {
+		o.WorkerOverrides = overrides
+	}
+}
+
+// WithAtMostOnce makes the controller report every job state at most once.
+// By default, the report state is only persisted after the reporter
+// succeeded, so a crash in between leads to the state being reported again
+// after a restart. With this option, the state is claimed before the
+// reporter is called and released again if reporting fails, so a crash in
+// between leads to the state not being reported at all.
+func WithAtMostOnce() Option {
+	return func(o *Options) {
+		o.AtMostOnce = true
+	}
+}
+
+// WithLiveReportStateCheck makes the controller read the job from the API
+// server before reporting it, and skip the report if the state was reported
+// since the cached job was last updated. This guards against reporting a
+// state twice while the cache lags behind, at the cost of a GET request per
+// report of every reporter.
+func WithLiveReportStateCheck() Option {
+	return func(o *Options) {
+		o.LiveReportStateCheck = true
+	}
+}
+
+// WithDrainTimeout lets reports that are in flight when the controller is
+// stopped continue for up to timeout before their context is cancelled, so
+// that slow reporters aren't cut off mid-report during shutdown. The
+// manager's graceful shutdown timeout must be longer than timeout for the
+// reports to complete.
+func WithDrainTimeout(timeout time.Duration) Option {
+	return func(o *Options) {
+		o.DrainTimeout = timeout
+	}
+}
+
+// WithRetryBackoff retries reporting a job with an exponentially growing
+// delay, starting at opts.Base and capped at opts.Max, while the reporter
+// keeps failing for it. The delay is reset once the job was reported
+// successfully. Without this option, the first retries happen after a few
+// milliseconds, which hammers a dependency that is down.
+func WithRetryBackoff(opts RetryBackoffOptions) Option {
+	return func(o *Options) {
+		o.RetryBackoff = &opts
+	}
+}
+
+// retryRateLimiter returns the rate limiter of the controller's workqueue,
+// which determines the delay before a job whose reconciliation failed is
+// reconciled again.
+func retryRateLimiter(opts *RetryBackoffOptions) workqueue.RateLimiter {
+	if opts == nil {
+		return workqueue.DefaultControllerRateLimiter()
+	}
+	return workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(opts.Base, opts.Max),
+		// Limits the overall retry rate, like the default rate limiter.
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+	)
+}
+
+// controllerOptions returns the options of the controller of a reporter.
+// Every reporter gets its own controller and with it its own workqueue and
+// rate limiter, so a backlog of jobs for one reporter doesn't delay the
+// others. MaxConcurrentReconciles is always set, as controller-runtime would
+// otherwise fall back to the concurrency configured for ProwJobs on the
+// manager, which is shared by all reporters.
+func controllerOptions(numWorkers int, retry *RetryBackoffOptions) controller.Options {
+	return controller.Options{
+		MaxConcurrentReconciles: max(numWorkers, 1),
+		RateLimiter:             retryRateLimiter(retry),
+	}
+}
+
+// EnablementChecker tells whether the named reporter should report jobs of
+// the given repo.
+type EnablementChecker func(reporter, org, repo string) bool
+
+// New constructs a new instance of the crier reconciler. Jobs are only
+// reported if enablementChecker allows the reporter to report them.
 func New(
 	mgr manager.Manager,
 	reporter ReportClient,
 	numWorkers int,
-	enablementChecker func(org, repo string) bool,
+	enablementChecker EnablementChecker,
+	opts ...Option,
 ) error {
+	o := Options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	reporterEnabled := func(org, repo string) bool {
+		return enablementChecker(reporter.GetName(), org, repo)
+	}
+	r := &reconciler{
+		pjclientset:       mgr.GetClient(),
+		reporter:          reporter,
+		enablementChecker: reporterEnabled,
+		atMostOnce:        o.AtMostOnce,
+		drainTimeout:      o.DrainTimeout,
+		auditLog:          o.AuditLog,
+		reportTimeout:     o.ReportTimeout,
+		reportedJobs:      o.ReportedJobs,
+		labelSelector:     o.LabelSelector,
+		skipReporting:     o.SkipReporting,
+		suppressRetryable: o.SuppressRetryableFailures,
+		reportAgent:       o.ReportAgent,
+		throttle:          o.ReportThrottle,
+		deadLetterSink:    o.DeadLetterSink,
+	}
+	if o.LiveReportStateCheck {
+		r.apiReader = mgr.GetAPIReader()
+	}
+	if o.CircuitBreaker != nil {
+		r.circuitBreaker = newCircuitBreaker(reporter.GetName(), *o.CircuitBreaker)
+	}
+	if o.Readiness != nil {
+		o.Readiness.register(reporter)
+	}
+	// Attempts are always tracked, so that states dropped after a
+	// permanent error aren't reported again on the next update of the job.
+	r.attempts = newReportAttempts(o.MaxReportAttempts)
+	if o.WorkerOverrides != nil {
+		r.workers = newWorkerLimiter(reporter.GetName(), numWorkers, o.WorkerOverrides)
+		numWorkers = r.workers.max
+	}
+
+	var forOpts []builder.ForOption
+	if o.ReportedJobs != nil {
+		o.ReportedJobs.register(reporter.GetName())
+		forOpts = append(forOpts, builder.WithPredicates(notFullyReported))
+	}
+	if o.LabelSelector != nil {
+		forOpts = append(forOpts, builder.WithPredicates(selectorPredicate(o.LabelSelector)))
+	}
+
+	ctrlOpts := controllerOptions(numWorkers, o.RetryBackoff)
+	newQueue := func(name string, rateLimiter ratelimiter.RateLimiter) workqueue.RateLimitingInterface {
+		// The default of controller-runtime.
+		return workqueue.NewRateLimitingQueueWithConfig(rateLimiter, workqueue.RateLimitingQueueConfig{Name: name})
+	}
+	if o.PrioritizeRecent {
+		recency := recencyFromClient(mgr.GetClient())
+		newQueue = func(_ string, rateLimiter ratelimiter.RateLimiter) workqueue.RateLimitingInterface {
+			return newRecencyQueue(rateLimiter, recency)
+		}
+	}
+	ctrlOpts.NewQueue = func(name string, rateLimiter ratelimiter.RateLimiter) workqueue.RateLimitingInterface {
+		queue := newQueue(name, rateLimiter)
+		crierMetrics.queueDepth.register(reporter.GetName(), queue)
+		return queue
+	}
+
 	if err := builder.
 		ControllerManagedBy(mgr).
 		// Is used for metrics, hence must be unique per controller instance
 		Named(fmt.Sprintf("crier_%s", reporter.GetName())).
-		For(&prowv1.ProwJob{}).
-		WithOptions(controller.Options{MaxConcurrentReconciles: numWorkers,
-			RateLimiter: workqueue.DefaultControllerRateLimiter()}).
-		Complete(&reconciler{
-			pjclientset:       mgr.GetClient(),
-			reporter:          reporter,
-			enablementChecker: enablementChecker,
-		}); err != nil {
+		For(&prowv1.ProwJob{}, forOpts...).
+		WithOptions(ctrlOpts).
+		Complete(r); err != nil {
 		return fmt.Errorf("failed to construct controller: %w", err)
 	}
 
@@ -85,7 +333,21 @@ func New(
 func (r *reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
 	log := logrus.WithField("reporter", r.reporter.GetName()).WithField("key", req.String()).WithField("prowjob", req.Name)
 	log.Debug("processing next key")
+	ctx, span := tracer().Start(ctx, "crier.Reconcile", trace.WithAttributes(
+		reporterAttribute.String(r.reporter.GetName()),
+		prowJobAttribute.String(req.Name),
+	))
+	if r.workers != nil {
+		r.workers.acquire()
+		defer r.workers.release()
+	}
+	if r.drainTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = drainContext(ctx, r.drainTimeout)
+		defer cancel()
+	}
 	result, err := r.reconcile(ctx, log, req)
+	endSpan(span, err)
 	if err != nil {
 		if criercommonlib.IsUserError(err) {
 			log.WithError(err).Debug("Reconciliation failed")
@@ -99,6 +361,23 @@ func (r *reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 	return *result, err
 }
 
+// drainContext returns a context that is only cancelled timeout after ctx
+// is, giving work that is in flight at that point time to complete.
+func drainContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	drainCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, func() {
+		select {
+		case <-time.After(timeout):
+			cancel()
+		case <-drainCtx.Done():
+		}
+	})
+	return drainCtx, func() {
+		stop()
+		cancel()
+	}
+}
+
 func (r *reconciler) reconcile(ctx context.Context, log *logrus.Entry, req reconcile.Request) (*reconcile.Result, error) {
 	// Limit reconciliation time to 30 minutes. This should more than enough time
 	// for any reasonable reporter. Most reporters should set a stricter timeout
@@ -110,22 +389,31 @@ func (r *reconciler) reconcile(ctx context.Context, log *logrus.Entry, req recon
 	if err := r.pjclientset.Get(ctx, req.NamespacedName, &pj); err != nil {
 		if errors.IsNotFound(err) {
 			log.Debug("object no longer exist")
+			if r.attempts != nil {
+				r.attempts.forget(req.NamespacedName)
+			}
+			if r.reportedJobs != nil {
+				r.reportedJobs.forget(req.NamespacedName)
+			}
 			return nil, nil
 		}
 
 		return nil, fmt.Errorf("failed to get prowjob %s: %w", req.String(), err)
 	}
 
-	if !r.shouldHandle(&pj) {
+	if !matchesSelector(r.labelSelector, &pj) {
+		log.Trace("Job doesn't match the label selector")
 		return nil, nil
 	}
 
-	log = log.WithField("jobName", pj.Spec.Job)
-
-	if !r.reporter.ShouldReport(ctx, log, &pj) {
-		return nil, nil
+	if !r.shouldHandle(&pj) || !matchesAgent(r.reportAgent, &pj) || (r.skipReporting != nil && r.skipReporting(&pj)) || r.suppressedRetry(&pj) {
+		crierMetrics.reportsSkipped.WithLabelValues(r.reporter.GetName()).Inc()
+		return nil, r.reportDone(ctx, log, &pj)
 	}
 
+	log = log.WithField("jobName", pj.Spec.Job)
+	trace.SpanFromContext(ctx).SetAttributes(jobAttributes(&pj)...)
+
 	// we set omitempty on PrevReportStates, so here we need to init it if is nil
 	if pj.Status.PrevReportStates == nil {
 		pj.Status.PrevReportStates = map[string]prowv1.ProwJobState{}
@@ -134,12 +422,75 @@ func (r *reconciler) reconcile(ctx context.Context, log *logrus.Entry, req recon
 	// already reported current state
 	if pj.Status.PrevReportStates[r.reporter.GetName()] == pj.Status.State {
 		log.Trace("Already reported")
-		return nil, nil
+		return nil, r.reportDone(ctx, log, &pj)
+	}
+
+	if !r.reporter.ShouldReport(ctx, log, &pj) {
+		crierMetrics.reportsSkipped.WithLabelValues(r.reporter.GetName()).Inc()
+		return nil, r.reportDone(ctx, log, &pj)
 	}
 
 	log = log.WithField("jobStatus", pj.Status.State)
+	if r.attempts != nil && r.attempts.exhausted(&pj) {
+		log.Debug("Gave up reporting the state of the job")
+		return nil, nil
+	}
+	if r.apiReader != nil {
+		reported, err := r.reportedSinceCached(ctx, &pj)
+		if err != nil {
+			return nil, err
+		}
+		if reported {
+			log.Debug("Already reported, the cached job is outdated")
+			return nil, nil
+		}
+	}
+	if r.throttle != nil {
+		if err := r.throttle.wait(ctx, r.reporter.GetName()); err != nil {
+			return nil, err
+		}
+	}
+	if r.circuitBreaker != nil {
+		if allowed, retryAfter := r.circuitBreaker.allow(); !allowed {
+			log.WithField("retryAfter", retryAfter).Debug("Circuit breaker is open, not reporting")
+			return &reconcile.Result{RequeueAfter: retryAfter}, nil
+		}
+	}
+	// The job is claimed on a copy, so that the reporter still sees the
+	// report states from before the claim.
+	var claimed *prowv1.ProwJob
+	prevState := pj.Status.PrevReportStates[r.reporter.GetName()]
+	if r.atMostOnce {
+		claimed = pj.DeepCopy()
+		if err := criercommonlib.ClaimReportState(ctx, claimed, log, r.pjclientset, r.reporter.GetName()); err != nil {
+			// Most likely the job changed since it was read, it is
+			// reconciled again with its latest version.
+			if r.circuitBreaker != nil {
+				r.circuitBreaker.abandon()
+			}
+			return nil, err
+		}
+	}
+	release := func() {
+		if claimed == nil {
+			return
+		}
+		if err := criercommonlib.ReleaseReportState(ctx, claimed, log, r.pjclientset, r.reporter.GetName(), prevState); err != nil {
+			log.WithError(err).Error("Failed to release report state, the job state will not be reported")
+		}
+	}
+
 	log.Info("Will report state")
-	pjs, requeue, err := r.reporter.Report(ctx, log, &pj)
+	start := time.Now()
+	pjs, requeue, err := r.tracedReport(ctx, log, &pj)
+	duration := time.Since(start)
+	crierMetrics.reportDuration.WithLabelValues(r.reporter.GetName(), string(pj.Status.State)).Observe(duration.Seconds())
+	if r.circuitBreaker != nil {
+		// User and permanent errors are caused by the job or crier config
+		// rather than by the backend, so they don't count towards opening
+		// the breaker.
+		r.circuitBreaker.record(err == nil || criercommonlib.IsUserError(err) || criercommonlib.IsPermanentError(err))
+	}
 	if err != nil {
 		if criercommonlib.IsUserError(err) {
 			log.WithError(err).Debug("Failed to report job.")
@@ -147,13 +498,46 @@ func (r *reconciler) reconcile(ctx context.Context, log *logrus.Entry, req recon
 			log.WithError(err).Error("Failed to report job.")
 		}
 		crierMetrics.reportingResults.WithLabelValues(r.reporter.GetName(), ResultError).Inc()
+		crierMetrics.reportErrors.WithLabelValues(r.reporter.GetName(), criercommonlib.ErrorClass(err)).Inc()
+		r.auditRecord(&pj, prevState, ResultError, duration, err)
+		release()
+		if criercommonlib.IsPermanentError(err) {
+			// Retrying can't succeed, the state is reported again only
+			// once the job changes state.
+			log.Info("Not retrying the report, the error is permanent.")
+			if r.attempts != nil {
+				r.attempts.drop(&pj)
+			}
+			r.deadLetter(ctx, log, &pj, err)
+			return nil, nil
+		}
+		if r.attempts != nil {
+			if failures, last := r.attempts.failed(&pj); last {
+				log.WithError(err).WithField("attempts", failures).Error("Giving up reporting the state of the job, all attempts failed.")
+				crierMetrics.reportsDropped.WithLabelValues(r.reporter.GetName()).Inc()
+				r.deadLetter(ctx, log, &pj, err)
+				return nil, nil
+			}
+		}
+		if requeue != nil {
+			// The reporter asked to be retried after a specific delay
+			// rather than with the rate limiter's backoff, which would
+			// ignore the result.
+			return requeue, nil
+		}
 		return nil, fmt.Errorf("failed to report job: %w", err)
 	}
 	if requeue != nil {
+		r.auditRecord(&pj, prevState, ResultRequeued, duration, nil)
+		release()
 		return requeue, nil
 	}
 
 	crierMetrics.reportingResults.WithLabelValues(r.reporter.GetName(), ResultSuccess).Inc()
+	r.auditRecord(&pj, prevState, ResultSuccess, duration, nil)
+	if r.attempts != nil {
+		r.attempts.forget(ctrlruntimeclient.ObjectKeyFromObject(&pj))
+	}
 	log.WithField("job-count", len(pjs)).Info("Reported job(s), now will update pj(s).")
 	var lastErr error
 	for _, pjob := range pjs {
@@ -172,10 +556,40 @@ func (r *reconciler) reconcile(ctx context.Context, log *logrus.Entry, req recon
 		log.WithField("latency", latency).Debug("Report latency.")
 	}
 
+	if lastErr == nil {
+		lastErr = r.reportDone(ctx, log, &pj)
+	}
 	return nil, lastErr
 }
 
+// reportedSinceCached tells whether the current state of the job was reported
+// since the cached copy of the job was last updated. The report state written
+// after a report takes a moment to reach the cache, during which updates of
+// the job made by others would otherwise cause it to be reported again.
+func (r *reconciler) reportedSinceCached(ctx context.Context, pj *prowv1.ProwJob) (bool, error) {
+	var live prowv1.ProwJob
+	if err := r.apiReader.Get(ctx, ctrlruntimeclient.ObjectKeyFromObject(pj), &live); err != nil {
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to get prowjob %s: %w", pj.Name, err)
+	}
+	return live.Status.PrevReportStates[r.reporter.GetName()] == pj.Status.State, nil
+}
+
+// suppressedRetry tells whether the job is skipped because it failed and is
+// going to be retried.
+func (r *reconciler) suppressedRetry(pj *prowv1.ProwJob) bool {
+	return r.suppressRetryable != nil && r.suppressRetryable() && willBeRetried(pj)
+}
+
 func (r *reconciler) shouldHandle(pj *prowv1.ProwJob) bool {
+	return enabledForJob(pj, r.enablementChecker)
+}
+
+// enabledForJob returns whether reporting is enabled for any of the repos of
+// the job, or true if the job has no repos.
+func enabledForJob(pj *prowv1.ProwJob, enablementChecker func(org, repo string) bool) bool {
 	refs := pj.Spec.ExtraRefs
 	if pj.Spec.Refs != nil {
 		refs = append(refs, *pj.Spec.Refs)
@@ -189,7 +603,7 @@ func (r *reconciler) shouldHandle(pj *prowv1.ProwJob) bool {
 	// better than not reporting at all.
 	var enabled bool
 	for _, ref := range refs {
-		if r.enablementChecker(ref.Org, ref.Repo) {
+		if enablementChecker(ref.Org, ref.Repo) {
 			enabled = true
 			break
 		}
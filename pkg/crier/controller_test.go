@@ -21,19 +21,25 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/sirupsen/logrus"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
 	ctrlruntime "sigs.k8s.io/controller-runtime"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/crier/reporters/criercommonlib"
 )
 
 const reporterName = "fakeReporter"
@@ -263,6 +269,23 @@ func TestReconcile(t *testing.T) {
 			expectResult: reconcile.Result{RequeueAfter: time.Minute},
 			expectReport: true,
 		},
+		{
+			name: "error with *reconcile.Result is requeued, prowjob is not updated",
+			job: &prowv1.ProwJob{
+				Spec: prowv1.ProwJobSpec{
+					Job:    "foo",
+					Report: true,
+				},
+				Status: prowv1.ProwJobStatus{
+					State: prowv1.TriggeredState,
+				},
+			},
+			shouldReport: true,
+			result:       &reconcile.Result{RequeueAfter: time.Minute},
+			reportErr:    errors.New("some-err"),
+			expectResult: reconcile.Result{RequeueAfter: time.Minute},
+			expectReport: true,
+		},
 	}
 
 	for _, test := range tests {
@@ -328,3 +351,484 @@ func (c *patchTrackingClient) Patch(ctx context.Context, obj ctrlruntimeclient.O
 	c.patches++
 	return c.Client.Patch(ctx, obj, patch, opts...)
 }
+
+// crashingClient simulates crier stopping before it can persist anything
+// after the first allowedPatches patches.
+type crashingClient struct {
+	ctrlruntimeclient.Client
+	allowedPatches int
+}
+
+func (c *crashingClient) Patch(ctx context.Context, obj ctrlruntimeclient.Object, patch ctrlruntimeclient.Patch, opts ...ctrlruntimeclient.PatchOption) error {
+	if c.allowedPatches == 0 {
+		return errors.New("crier stopped")
+	}
+	c.allowedPatches--
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func TestReconcileRestartBetweenReportAndStatusWrite(t *testing.T) {
+	const toReconcile = "foo"
+	tests := []struct {
+		name           string
+		atMostOnce     bool
+		allowedPatches int
+		expectReports  int
+	}{
+		{
+			name:          "default is at least once, reports again after restart",
+			expectReports: 2,
+		},
+		{
+			name:           "at most once does not report again after restart",
+			atMostOnce:     true,
+			allowedPatches: 1,
+			expectReports:  1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			job := &prowv1.ProwJob{
+				Spec: prowv1.ProwJobSpec{
+					Job:    "foo",
+					Report: true,
+				},
+				Status: prowv1.ProwJobStatus{
+					State: prowv1.SuccessState,
+				},
+			}
+			job.Name = toReconcile
+			cs := fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(job).Build()
+			rp := &fakeReporter{shouldReportFunc: func(*prowv1.ProwJob) bool { return true }}
+			req := ctrlruntime.Request{NamespacedName: types.NamespacedName{Name: toReconcile}}
+
+			crashing := &reconciler{
+				pjclientset: &crashingClient{Client: cs, allowedPatches: test.allowedPatches},
+				reporter:    rp,
+				atMostOnce:  test.atMostOnce,
+			}
+			_, _ = crashing.Reconcile(context.Background(), req)
+
+			restarted := &reconciler{
+				pjclientset: cs,
+				reporter:    rp,
+				atMostOnce:  test.atMostOnce,
+			}
+			if _, err := restarted.Reconcile(context.Background(), req); err != nil {
+				t.Fatalf("unexpected error after restart: %v", err)
+			}
+
+			if len(rp.reported) != test.expectReports {
+				t.Errorf("expected %d reports, got %d", test.expectReports, len(rp.reported))
+			}
+		})
+	}
+}
+
+func TestReconcileSameStateTwiceReportsOnce(t *testing.T) {
+	const toReconcile = "foo"
+	job := &prowv1.ProwJob{
+		Spec: prowv1.ProwJobSpec{
+			Job:    "foo",
+			Report: true,
+		},
+		Status: prowv1.ProwJobStatus{
+			State: prowv1.PendingState,
+		},
+	}
+	job.Name = toReconcile
+	cs := &patchTrackingClient{Client: fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(job).Build()}
+	rp := &fakeReporter{shouldReportFunc: func(*prowv1.ProwJob) bool { return true }}
+	r := &reconciler{
+		pjclientset: cs,
+		reporter:    rp,
+	}
+	req := ctrlruntime.Request{NamespacedName: types.NamespacedName{Name: toReconcile}}
+
+	for i := 0; i < 2; i++ {
+		if _, err := r.Reconcile(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(rp.reported) != 1 {
+		t.Errorf("expected 1 report, got %d", len(rp.reported))
+	}
+	if cs.patches != 1 {
+		t.Errorf("expected 1 status write, got %d", cs.patches)
+	}
+}
+
+func TestReconcileOutdatedCacheDoesNotReportAgain(t *testing.T) {
+	const toReconcile = "foo"
+	job := &prowv1.ProwJob{
+		Spec: prowv1.ProwJobSpec{
+			Job:    "foo",
+			Report: true,
+		},
+		Status: prowv1.ProwJobStatus{
+			State: prowv1.PendingState,
+		},
+	}
+	job.Name = toReconcile
+	reported := job.DeepCopy()
+	reported.Status.PrevReportStates = map[string]prowv1.ProwJobState{reporterName: prowv1.PendingState}
+
+	// The cache doesn't have the report state written after the last report yet.
+	cache := &patchTrackingClient{Client: fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(job).Build()}
+	apiServer := fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(reported).Build()
+	rp := &fakeReporter{shouldReportFunc: func(*prowv1.ProwJob) bool { return true }}
+	r := &reconciler{
+		pjclientset: cache,
+		apiReader:   apiServer,
+		reporter:    rp,
+	}
+
+	if _, err := r.Reconcile(context.Background(), ctrlruntime.Request{NamespacedName: types.NamespacedName{Name: toReconcile}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rp.reported) != 0 {
+		t.Errorf("expected no report, got %d", len(rp.reported))
+	}
+	if cache.patches != 0 {
+		t.Errorf("expected no status write, got %d", cache.patches)
+	}
+}
+
+func TestReconcileAtMostOnceReleasesOnFailure(t *testing.T) {
+	const toReconcile = "foo"
+	job := &prowv1.ProwJob{
+		Spec: prowv1.ProwJobSpec{
+			Job:    "foo",
+			Report: true,
+		},
+		Status: prowv1.ProwJobStatus{
+			State:            prowv1.SuccessState,
+			PrevReportStates: map[string]prowv1.ProwJobState{reporterName: prowv1.PendingState},
+		},
+	}
+	job.Name = toReconcile
+	cs := fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(job).Build()
+	rp := &fakeReporter{
+		shouldReportFunc: func(*prowv1.ProwJob) bool { return true },
+		err:              errors.New("some-err"),
+	}
+	r := &reconciler{
+		pjclientset: cs,
+		reporter:    rp,
+		atMostOnce:  true,
+	}
+	req := ctrlruntime.Request{NamespacedName: types.NamespacedName{Name: toReconcile}}
+
+	if _, err := r.Reconcile(context.Background(), req); err == nil {
+		t.Fatal("expected error")
+	}
+	var pj prowv1.ProwJob
+	if err := cs.Get(context.Background(), req.NamespacedName, &pj); err != nil {
+		t.Fatalf("failed to get prowjob: %v", err)
+	}
+	if state := pj.Status.PrevReportStates[reporterName]; state != prowv1.PendingState {
+		t.Errorf("expected report state to be released to %q, got %q", prowv1.PendingState, state)
+	}
+
+	rp.err = nil
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rp.reported) != 2 {
+		t.Errorf("expected the job to be reported again, got %d reports", len(rp.reported))
+	}
+}
+
+func TestReconcileObservesReportDuration(t *testing.T) {
+	const toReconcile = "foo"
+	job := &prowv1.ProwJob{
+		Spec: prowv1.ProwJobSpec{
+			Job:    "foo",
+			Report: true,
+		},
+		Status: prowv1.ProwJobStatus{
+			State: prowv1.AbortedState,
+		},
+	}
+	job.Name = toReconcile
+
+	sampleCount := func() uint64 {
+		m := &dto.Metric{}
+		if err := crierMetrics.reportDuration.WithLabelValues(reporterName, string(prowv1.AbortedState)).(prometheus.Histogram).Write(m); err != nil {
+			t.Fatalf("failed to read histogram: %v", err)
+		}
+		return m.GetHistogram().GetSampleCount()
+	}
+	before := sampleCount()
+
+	r := &reconciler{
+		pjclientset: fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(job).Build(),
+		reporter:    &fakeReporter{shouldReportFunc: func(*prowv1.ProwJob) bool { return true }},
+	}
+	if _, err := r.Reconcile(context.Background(), ctrlruntime.Request{NamespacedName: types.NamespacedName{Name: toReconcile}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if after := sampleCount(); after != before+1 {
+		t.Errorf("expected one report duration to be observed, got %d", after-before)
+	}
+}
+
+func TestReconcileCountsSkippedReports(t *testing.T) {
+	const toReconcile = "foo"
+	testCases := []struct {
+		name            string
+		refs            *prowv1.Refs
+		shouldReport    bool
+		enabled         bool
+		expectedSkipped float64
+	}{
+		{
+			name:         "reported",
+			shouldReport: true,
+		},
+		{
+			name:            "reporter decides not to report",
+			expectedSkipped: 1,
+		},
+		{
+			name:            "reporter not enabled for the repo",
+			refs:            &prowv1.Refs{Org: "org", Repo: "repo"},
+			shouldReport:    true,
+			expectedSkipped: 1,
+		},
+		{
+			name:         "reporter enabled for the repo",
+			refs:         &prowv1.Refs{Org: "org", Repo: "repo"},
+			shouldReport: true,
+			enabled:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			job := &prowv1.ProwJob{
+				Spec: prowv1.ProwJobSpec{
+					Job:    "foo",
+					Refs:   tc.refs,
+					Report: true,
+				},
+				Status: prowv1.ProwJobStatus{
+					State: prowv1.SuccessState,
+				},
+			}
+			job.Name = toReconcile
+			rp := &fakeReporter{shouldReportFunc: func(*prowv1.ProwJob) bool { return tc.shouldReport }}
+			r := &reconciler{
+				pjclientset:       fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(job).Build(),
+				reporter:          rp,
+				enablementChecker: func(_, _ string) bool { return tc.enabled },
+			}
+			before := testutil.ToFloat64(crierMetrics.reportsSkipped.WithLabelValues(reporterName))
+			if _, err := r.Reconcile(context.Background(), ctrlruntime.Request{NamespacedName: types.NamespacedName{Name: toReconcile}}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if skipped := testutil.ToFloat64(crierMetrics.reportsSkipped.WithLabelValues(reporterName)) - before; skipped != tc.expectedSkipped {
+				t.Errorf("expected %v skipped reports, got %v", tc.expectedSkipped, skipped)
+			}
+			if expectedReports := 1 - int(tc.expectedSkipped); len(rp.reported) != expectedReports {
+				t.Errorf("expected %d reports, got %d", expectedReports, len(rp.reported))
+			}
+		})
+	}
+}
+
+func TestReconcileClassifiesReportErrors(t *testing.T) {
+	const toReconcile = "foo"
+	testCases := []struct {
+		name          string
+		err           error
+		expectedClass string
+		expectRetry   bool
+	}{
+		{
+			name:          "transient errors are retried",
+			err:           criercommonlib.TransientError(errors.New("503 Service Unavailable")),
+			expectedClass: criercommonlib.ErrorClassTransient,
+			expectRetry:   true,
+		},
+		{
+			name:          "permanent errors are dropped",
+			err:           fmt.Errorf("failed to report: %w", criercommonlib.PermanentError(errors.New("channel_not_found"))),
+			expectedClass: criercommonlib.ErrorClassPermanent,
+		},
+		{
+			name:          "unclassified errors are retried",
+			err:           errors.New("some-err"),
+			expectedClass: criercommonlib.ErrorClassUnclassified,
+			expectRetry:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			job := &prowv1.ProwJob{
+				Spec: prowv1.ProwJobSpec{
+					Job:    "foo",
+					Report: true,
+				},
+				Status: prowv1.ProwJobStatus{
+					State: prowv1.FailureState,
+				},
+			}
+			job.Name = toReconcile
+			cs := fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(job).Build()
+			r := &reconciler{
+				pjclientset: cs,
+				reporter: &fakeReporter{
+					shouldReportFunc: func(*prowv1.ProwJob) bool { return true },
+					err:              tc.err,
+				},
+			}
+			before := testutil.ToFloat64(crierMetrics.reportErrors.WithLabelValues(reporterName, tc.expectedClass))
+			req := ctrlruntime.Request{NamespacedName: types.NamespacedName{Name: toReconcile}}
+			_, err := r.Reconcile(context.Background(), req)
+			if retried := err != nil; retried != tc.expectRetry {
+				t.Errorf("expected retry to be %t, got error %v", tc.expectRetry, err)
+			}
+			if count := testutil.ToFloat64(crierMetrics.reportErrors.WithLabelValues(reporterName, tc.expectedClass)) - before; count != 1 {
+				t.Errorf("expected one %s error to be counted, got %v", tc.expectedClass, count)
+			}
+
+			var pj prowv1.ProwJob
+			if err := cs.Get(context.Background(), req.NamespacedName, &pj); err != nil {
+				t.Fatalf("failed to get prowjob: %v", err)
+			}
+			if state, reported := pj.Status.PrevReportStates[reporterName]; reported {
+				t.Errorf("expected the failed report not to be recorded, got state %q", state)
+			}
+		})
+	}
+}
+
+func TestDrainContext(t *testing.T) {
+	parent, cancelParent := context.WithCancel(context.Background())
+	ctx, cancel := drainContext(parent, 100*time.Millisecond)
+	defer cancel()
+
+	cancelParent()
+	select {
+	case <-ctx.Done():
+		t.Fatal("context was cancelled right after its parent, expected it to drain first")
+	case <-time.After(20 * time.Millisecond):
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("context wasn't cancelled after the drain timeout")
+	}
+
+	// Cancelling the drain context itself must not wait for the parent.
+	ctx, cancel = drainContext(context.Background(), time.Hour)
+	cancel()
+	if ctx.Err() == nil {
+		t.Error("expected context to be cancelled")
+	}
+}
+
+func TestRetryRateLimiter(t *testing.T) {
+	limiter := retryRateLimiter(&RetryBackoffOptions{Base: time.Second, Max: 5 * time.Second})
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "job"}}
+	other := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "other"}}
+
+	var delays []time.Duration
+	for i := 0; i < 5; i++ {
+		delays = append(delays, limiter.When(req))
+	}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	if diff := cmp.Diff(expected, delays); diff != "" {
+		t.Errorf("delays of consecutive failures differ from expected: %s", diff)
+	}
+
+	if delay := limiter.When(other); delay != time.Second {
+		t.Errorf("expected failures of other jobs to start at the base delay, got %v", delay)
+	}
+
+	limiter.Forget(req)
+	if delay := limiter.When(req); delay != time.Second {
+		t.Errorf("expected delay to be reset after success, got %v", delay)
+	}
+}
+
+func TestControllerOptions(t *testing.T) {
+	testCases := []struct {
+		name       string
+		numWorkers int
+		expected   int
+	}{
+		{
+			name:       "workers of the reporter are used",
+			numWorkers: 5,
+			expected:   5,
+		},
+		{
+			name:       "max workers are used if workers can be changed at runtime",
+			numWorkers: newWorkerLimiter("test-reporter", 5, func() map[string]int { return nil }).max,
+			expected:   maxWorkers,
+		},
+		{
+			name:     "at least one worker is used",
+			expected: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if workers := controllerOptions(tc.numWorkers, nil).MaxConcurrentReconciles; workers != tc.expected {
+				t.Errorf("expected %d concurrent reconciles, got %d", tc.expected, workers)
+			}
+		})
+	}
+}
+
+func TestControllerOptionsRateLimitersAreIndependent(t *testing.T) {
+	retry := &RetryBackoffOptions{Base: time.Second, Max: time.Minute}
+	github := controllerOptions(1, retry).RateLimiter
+	slack := controllerOptions(1, retry).RateLimiter
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "job"}}
+
+	for i := 0; i < 3; i++ {
+		github.When(req)
+	}
+	if delay := slack.When(req); delay != time.Second {
+		t.Errorf("expected failures of one reporter not to back off another, got delay %v", delay)
+	}
+}
+
+func TestQueueDepthCollector(t *testing.T) {
+	collector := newQueueDepthCollector()
+	slackQueue := workqueue.New()
+	defer slackQueue.ShutDown()
+	gcsQueue := workqueue.New()
+	defer gcsQueue.ShutDown()
+	collector.register("slackreporter", slackQueue)
+	collector.register("gcsreporter", gcsQueue)
+
+	slackQueue.Add("job-a")
+	slackQueue.Add("job-b")
+	gcsQueue.Add("job-a")
+
+	expected := `
+# HELP crier_workqueue_depth Number of jobs waiting in the workqueue of a reporter, by reporter.
+# TYPE crier_workqueue_depth gauge
+crier_workqueue_depth{reporter="gcsreporter"} 1
+crier_workqueue_depth{reporter="slackreporter"} 2
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+
+	item, _ := slackQueue.Get()
+	slackQueue.Done(item)
+	expected = strings.Replace(expected, `{reporter="slackreporter"} 2`, `{reporter="slackreporter"} 1`, 1)
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected)); err != nil {
+		t.Errorf("after a job was processed: %v", err)
+	}
+}
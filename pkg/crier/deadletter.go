@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	stdio "io"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+)
+
+// DeadLetterTimeout is how long delivering a record to the dead-letter sink
+// may take.
+const DeadLetterTimeout = 10 * time.Second
+
+// DeadLetterRecord is the JSON body posted to the dead-letter sink for every
+// report that is dropped.
+type DeadLetterRecord struct {
+	// Job is the name of the job.
+	Job string `json:"job"`
+	// ProwJob is the name of the ProwJob.
+	ProwJob string `json:"prowjob"`
+	// Reporter is the name of the reporter that failed to report the job.
+	Reporter string `json:"reporter"`
+	// State is the state of the job that wasn't reported.
+	State prowv1.ProwJobState `json:"state"`
+	// Error is the error of the last report.
+	Error string `json:"error"`
+	// Payload is the job as it was passed to the reporter.
+	Payload *prowv1.ProwJob `json:"payload"`
+}
+
+// DeadLetterSink captures reports that are dropped, because their error is
+// permanent or all attempts failed, by posting them to an HTTP endpoint, e.g.
+// a queue that they can be recovered from.
+type DeadLetterSink struct {
+	url     string
+	client  *http.Client
+	timeout time.Duration
+}
+
+// NewDeadLetterSink returns a sink that posts a DeadLetterRecord to url for
+// every dropped report.
+func NewDeadLetterSink(url string) *DeadLetterSink {
+	return &DeadLetterSink{
+		url:     url,
+		client:  &http.Client{},
+		timeout: DeadLetterTimeout,
+	}
+}
+
+// WithDeadLetterSink makes the controller post every report it drops to the
+// sink. Delivery is best-effort: a record that can't be delivered is logged
+// and counted, but not retried.
+func WithDeadLetterSink(sink *DeadLetterSink) Option {
+	return func(o *Options) {
+		o.DeadLetterSink = sink
+	}
+}
+
+// deadLetter delivers the dropped report of the job to the sink of the
+// controller, if any. err is the error of the last report.
+func (r *reconciler) deadLetter(ctx context.Context, log *logrus.Entry, pj *prowv1.ProwJob, err error) {
+	if r.deadLetterSink == nil {
+		return
+	}
+	record := DeadLetterRecord{
+		Job:      pj.Spec.Job,
+		ProwJob:  pj.Name,
+		Reporter: r.reporter.GetName(),
+		State:    pj.Status.State,
+		Error:    err.Error(),
+		Payload:  pj,
+	}
+	if err := r.deadLetterSink.send(ctx, record); err != nil {
+		log.WithError(err).Error("Failed to deliver the dropped report to the dead-letter sink.")
+		crierMetrics.deadLetterFailures.WithLabelValues(r.reporter.GetName()).Inc()
+	}
+}
+
+func (s *DeadLetterSink) send(ctx context.Context, record DeadLetterRecord) error {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead-letter record: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create dead-letter request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post dead-letter record: %w", err)
+	}
+	defer resp.Body.Close()
+	// Drain the body so that the connection can be reused.
+	_, _ = stdio.Copy(stdio.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("dead-letter sink responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
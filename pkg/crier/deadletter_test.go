@@ -0,0 +1,127 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlruntime "sigs.k8s.io/controller-runtime"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/crier/reporters/criercommonlib"
+)
+
+func TestDeadLetterSink(t *testing.T) {
+	testCases := []struct {
+		name          string
+		err           error
+		maxAttempts   int
+		status        int
+		expectRecords int
+		expectFailure bool
+	}{
+		{
+			name:          "permanent error is dead-lettered",
+			err:           criercommonlib.PermanentError(errors.New("channel_not_found")),
+			status:        http.StatusOK,
+			expectRecords: 1,
+		},
+		{
+			name:          "report dropped after all attempts is dead-lettered",
+			err:           errors.New("channel is archived"),
+			maxAttempts:   1,
+			status:        http.StatusOK,
+			expectRecords: 1,
+		},
+		{
+			name:   "retried error is not dead-lettered",
+			err:    errors.New("connection reset"),
+			status: http.StatusOK,
+		},
+		{
+			name:          "failing sink is counted",
+			err:           criercommonlib.PermanentError(errors.New("channel_not_found")),
+			status:        http.StatusInternalServerError,
+			expectRecords: 1,
+			expectFailure: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var records []DeadLetterRecord
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var record DeadLetterRecord
+				if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+					t.Errorf("failed to decode record: %v", err)
+				}
+				records = append(records, record)
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			pj := &prowv1.ProwJob{
+				ObjectMeta: v1.ObjectMeta{Name: "foo"},
+				Spec:       prowv1.ProwJobSpec{Job: "my-job"},
+				Status:     prowv1.ProwJobStatus{State: prowv1.FailureState},
+			}
+			r := &reconciler{
+				pjclientset: fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(pj).Build(),
+				reporter: &fakeReporter{
+					shouldReportFunc: func(*prowv1.ProwJob) bool { return true },
+					err:              tc.err,
+				},
+				deadLetterSink: NewDeadLetterSink(server.URL),
+			}
+			if tc.maxAttempts > 0 {
+				r.attempts = newReportAttempts(tc.maxAttempts)
+			}
+			failures := testutil.ToFloat64(crierMetrics.deadLetterFailures.WithLabelValues(reporterName))
+
+			req := ctrlruntime.Request{NamespacedName: types.NamespacedName{Name: "foo"}}
+			_, _ = r.Reconcile(context.Background(), req)
+
+			if len(records) != tc.expectRecords {
+				t.Fatalf("expected %d dead-letter records, got %d", tc.expectRecords, len(records))
+			}
+			for _, record := range records {
+				if record.Job != "my-job" || record.ProwJob != "foo" || record.Reporter != reporterName || record.State != prowv1.FailureState {
+					t.Errorf("unexpected record: %+v", record)
+				}
+				if record.Error != tc.err.Error() {
+					t.Errorf("expected error %q, got %q", tc.err.Error(), record.Error)
+				}
+				if record.Payload == nil || record.Payload.Name != "foo" {
+					t.Errorf("expected the job as payload, got %+v", record.Payload)
+				}
+			}
+			gotFailure := testutil.ToFloat64(crierMetrics.deadLetterFailures.WithLabelValues(reporterName)) > failures
+			if gotFailure != tc.expectFailure {
+				t.Errorf("expected dead-letter failure to be counted: %t, got %t", tc.expectFailure, gotFailure)
+			}
+		})
+	}
+}
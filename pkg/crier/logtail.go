@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	stdio "io"
+	"path"
+	"strings"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/crier/reporters/gcs/util"
+	"sigs.k8s.io/prow/pkg/io"
+	"sigs.k8s.io/prow/pkg/io/providers"
+)
+
+const (
+	// BuildLogName is the name of the build log in the storage directory of
+	// a job.
+	BuildLogName = "build-log.txt"
+	// DefaultLogTailBytes is the number of bytes at the end of the build log
+	// that are read if LogTailOptions.MaxBytes is unset.
+	DefaultLogTailBytes = 64 * 1024
+)
+
+// LogTailOptions bounds the excerpt of the build log returned by
+// BuildLogTail.
+type LogTailOptions struct {
+	// Lines is the number of lines at the end of the build log to return.
+	Lines int
+	// MaxBytes caps how much of the end of the build log is read, and so
+	// the size of the excerpt. Defaults to DefaultLogTailBytes.
+	MaxBytes int64
+}
+
+// BuildLogTail returns the last lines of the build log the job uploaded, for
+// reporters that include a failure excerpt in their notifications. Only the
+// end of the log is read, so that huge logs don't have to be downloaded. A
+// job without a build log, e.g. because it never started, has an empty tail.
+func BuildLogTail(ctx context.Context, cfg config.Getter, opener io.Opener, pj *prowv1.ProwJob, opts LogTailOptions) (string, error) {
+	if opts.Lines <= 0 {
+		return "", nil
+	}
+	maxBytes := opts.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultLogTailBytes
+	}
+
+	bucket, dir, err := util.GetJobDestination(cfg, pj)
+	if err != nil {
+		return "", err
+	}
+	logPath, err := providers.StoragePath(bucket, path.Join(dir, BuildLogName))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve build log path: %w", err)
+	}
+	attrs, err := opener.Attributes(ctx, logPath)
+	if err != nil {
+		if io.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get build log attributes: %w", err)
+	}
+	if attrs.Size == 0 {
+		return "", nil
+	}
+	offset := max(attrs.Size-maxBytes, 0)
+	r, err := opener.RangeReader(ctx, logPath, offset, attrs.Size-offset)
+	if err != nil {
+		return "", fmt.Errorf("failed to read build log: %w", err)
+	}
+	defer r.Close()
+	content, err := stdio.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read build log: %w", err)
+	}
+
+	content = bytes.TrimRight(content, "\n")
+	logLines := strings.Split(string(content), "\n")
+	if offset > 0 && len(logLines) > 1 {
+		// The first line is most likely cut off.
+		logLines = logLines[1:]
+	}
+	if len(logLines) > opts.Lines {
+		logLines = logLines[len(logLines)-opts.Lines:]
+	}
+	return strings.Join(logLines, "\n"), nil
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/io/fakeopener"
+)
+
+func TestBuildLogTail(t *testing.T) {
+	var log strings.Builder
+	for i := 0; log.Len() < DefaultLogTailBytes+100; i++ {
+		fmt.Fprintf(&log, "line %d\n", i)
+	}
+	// tailFrom returns the complete lines of the last n bytes of the log.
+	tailFrom := func(n int) string {
+		s := log.String()[log.Len()-n:]
+		return strings.TrimSuffix(s[strings.Index(s, "\n")+1:], "\n")
+	}
+	testCases := []struct {
+		name     string
+		log      *string
+		opts     LogTailOptions
+		expected string
+	}{
+		{
+			name:     "short log is returned completely",
+			log:      ptr("one\ntwo\n"),
+			opts:     LogTailOptions{Lines: 5},
+			expected: "one\ntwo",
+		},
+		{
+			name:     "last lines of a short log",
+			log:      ptr("one\ntwo\nthree"),
+			opts:     LogTailOptions{Lines: 2},
+			expected: "two\nthree",
+		},
+		{
+			name:     "long log drops the partial first line",
+			log:      ptr(log.String()),
+			opts:     LogTailOptions{Lines: 100000},
+			expected: tailFrom(DefaultLogTailBytes),
+		},
+		{
+			name:     "byte cap bounds the tail",
+			log:      ptr(log.String()),
+			opts:     LogTailOptions{Lines: 100000, MaxBytes: 100},
+			expected: tailFrom(100),
+		},
+		{
+			name: "missing log has an empty tail",
+			opts: LogTailOptions{Lines: 5},
+		},
+		{
+			name: "empty log has an empty tail",
+			log:  ptr(""),
+			opts: LogTailOptions{Lines: 5},
+		},
+		{
+			name: "no lines requested",
+			log:  ptr("one\ntwo\n"),
+		},
+	}
+
+	cfg := func() *config.Config {
+		return &config.Config{
+			ProwConfig: config.ProwConfig{
+				Plank: config.Plank{
+					DefaultDecorationConfigs: config.DefaultDecorationMapToSliceTesting(
+						map[string]*prowv1.DecorationConfig{"*": {
+							GCSConfiguration: &prowv1.GCSConfiguration{
+								Bucket:       "bucket",
+								PathStrategy: prowv1.PathStrategyExplicit,
+							},
+						}}),
+				},
+			},
+		}
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opener := &fakeopener.FakeOpener{Buffer: map[string]*bytes.Buffer{}}
+			if tc.log != nil {
+				opener.Buffer["gs://bucket/logs/my-job/123/build-log.txt"] = bytes.NewBufferString(*tc.log)
+			}
+			pj := &prowv1.ProwJob{
+				Spec:   prowv1.ProwJobSpec{Type: prowv1.PeriodicJob, Job: "my-job"},
+				Status: prowv1.ProwJobStatus{BuildID: "123"},
+			}
+			actual, err := BuildLogTail(context.Background(), cfg, opener, pj, tc.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
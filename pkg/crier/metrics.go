@@ -17,7 +17,11 @@ limitations under the License.
 // Package crier reports finished prowjob status to git providers.
 package crier
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
 
 const (
 	ResultError   = "ERROR"
@@ -28,8 +32,29 @@ const (
 var (
 	crierMetrics = struct {
 		latency *prometheus.HistogramVec
+		// Time spent in the Report call of each reporter.
+		reportDuration *prometheus.HistogramVec
 		// Count success/failures of reporting attempts.
 		reportingResults *prometheus.CounterVec
+		// State of the circuit breaker of each reporter.
+		circuitBreakerState *prometheus.GaugeVec
+		// Time spent waiting for the rate limiter of rate limited reporters.
+		rateLimiterWait *prometheus.HistogramVec
+		// Time spent waiting for the report throttle shared by all reporters.
+		reportThrottleWait *prometheus.HistogramVec
+		// Count of reports that were cancelled because they timed out.
+		reportTimeouts *prometheus.CounterVec
+		// Count of jobs that reporters decided not to report.
+		reportsSkipped *prometheus.CounterVec
+		// Count of failed reports by whether the error is transient or permanent.
+		reportErrors *prometheus.CounterVec
+		// Count of reports that were given up after too many failed attempts.
+		reportsDropped *prometheus.CounterVec
+		// Count of dropped reports that couldn't be delivered to the
+		// dead-letter sink.
+		deadLetterFailures *prometheus.CounterVec
+		// Number of jobs waiting in the workqueue of each reporter.
+		queueDepth *queueDepthCollector
 	}{
 		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
 			Name:    "crier_report_latency",
@@ -38,6 +63,14 @@ var (
 		}, []string{
 			"reporter",
 		}),
+		reportDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "crier_report_duration_seconds",
+			Help:    "Histogram of time spent in the Report call by reporter and job state.",
+			Buckets: []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 30},
+		}, []string{
+			"reporter",
+			"state",
+		}),
 		reportingResults: prometheus.NewCounterVec(prometheus.CounterOpts{
 			Name: "crier_reporting_results",
 			Help: "Count of successful and failed reporting attempts by reporter.",
@@ -45,10 +78,119 @@ var (
 			"reporter",
 			"result",
 		}),
+		circuitBreakerState: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "crier_circuit_breaker_state",
+			Help: "State of the circuit breaker by reporter: 0 is closed, 1 is open and 2 is half-open.",
+		}, []string{
+			"reporter",
+		}),
+		rateLimiterWait: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "crier_rate_limiter_wait_seconds",
+			Help:    "Histogram of time spent waiting for the rate limiter before reporting, by reporter.",
+			Buckets: []float64{0.001, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+		}, []string{
+			"reporter",
+		}),
+		reportThrottleWait: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "crier_report_throttle_wait_seconds",
+			Help:    "Histogram of time spent waiting for the report throttle shared by all reporters before reporting, by reporter.",
+			Buckets: []float64{0.001, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+		}, []string{
+			"reporter",
+		}),
+		reportTimeouts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "crier_report_timeouts_total",
+			Help: "Count of reports that were cancelled because they exceeded the report timeout, by reporter.",
+		}, []string{
+			"reporter",
+		}),
+		reportsSkipped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "crier_reports_skipped_total",
+			Help: "Count of job updates that were not reported because the reporter isn't enabled for the repo or decided not to report them, by reporter.",
+		}, []string{
+			"reporter",
+		}),
+		reportErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "crier_report_errors_total",
+			Help: "Count of failed reports by reporter and error class: transient errors are retried, permanent errors are dropped and unclassified errors are retried.",
+		}, []string{
+			"reporter",
+			"class",
+		}),
+		reportsDropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "crier_reports_dropped_total",
+			Help: "Count of job states whose report was given up after failing the maximum number of attempts, by reporter.",
+		}, []string{
+			"reporter",
+		}),
+		deadLetterFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "crier_dead_letter_failures_total",
+			Help: "Count of dropped reports that couldn't be delivered to the dead-letter sink, by reporter.",
+		}, []string{
+			"reporter",
+		}),
+		queueDepth: newQueueDepthCollector(),
 	}
 )
 
 func init() {
 	prometheus.MustRegister(crierMetrics.latency)
+	prometheus.MustRegister(crierMetrics.reportDuration)
 	prometheus.MustRegister(crierMetrics.reportingResults)
+	prometheus.MustRegister(crierMetrics.circuitBreakerState)
+	prometheus.MustRegister(crierMetrics.rateLimiterWait)
+	prometheus.MustRegister(crierMetrics.reportThrottleWait)
+	prometheus.MustRegister(crierMetrics.reportTimeouts)
+	prometheus.MustRegister(crierMetrics.reportsSkipped)
+	prometheus.MustRegister(crierMetrics.reportErrors)
+	prometheus.MustRegister(crierMetrics.reportsDropped)
+	prometheus.MustRegister(crierMetrics.deadLetterFailures)
+	prometheus.MustRegister(crierMetrics.queueDepth)
+}
+
+// queueLen is the part of a workqueue the queue depth is read from.
+type queueLen interface {
+	Len() int
+}
+
+// queueDepthCollector exposes the depth of the workqueue of every reporter's
+// controller. The workqueue metrics of controller-runtime are registered
+// with its own registry, which crier doesn't serve, and aren't exported by
+// the queue used with --prioritize-recent, so crier reads the depth of the
+// queues itself whenever the metrics are scraped.
+type queueDepthCollector struct {
+	desc   *prometheus.Desc
+	lock   sync.Mutex
+	queues map[string]queueLen
+}
+
+func newQueueDepthCollector() *queueDepthCollector {
+	return &queueDepthCollector{
+		desc: prometheus.NewDesc(
+			"crier_workqueue_depth",
+			"Number of jobs waiting in the workqueue of a reporter, by reporter.",
+			[]string{"reporter"}, nil,
+		),
+		queues: map[string]queueLen{},
+	}
+}
+
+// register makes the collector expose the depth of the reporter's queue,
+// replacing the queue registered for it before.
+func (c *queueDepthCollector) register(reporter string, queue queueLen) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.queues[reporter] = queue
+}
+
+func (c *queueDepthCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *queueDepthCollector) Collect(ch chan<- prometheus.Metric) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for reporter, queue := range c.queues {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(queue.Len()), reporter)
+	}
 }
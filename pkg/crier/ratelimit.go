@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+)
+
+// RateLimitedReporter wraps a ReportClient so that its Report calls don't
+// exceed a given rate. Calls block until the limiter allows them, which is
+// useful for backends that punish bursts, e.g. GitHub's secondary rate
+// limits.
+type RateLimitedReporter struct {
+	ReportClient
+	limiter *rate.Limiter
+}
+
+// NewRateLimitedReporter returns a reporter that calls reporter.Report at
+// most qps times per second on average, with bursts of up to burst calls.
+func NewRateLimitedReporter(reporter ReportClient, qps float64, burst int) *RateLimitedReporter {
+	return &RateLimitedReporter{
+		ReportClient: reporter,
+		limiter:      rate.NewLimiter(rate.Limit(qps), burst),
+	}
+}
+
+func (r *RateLimitedReporter) Report(ctx context.Context, log *logrus.Entry, pj *prowv1.ProwJob) ([]*prowv1.ProwJob, *reconcile.Result, error) {
+	start := time.Now()
+	err := r.limiter.Wait(ctx)
+	crierMetrics.rateLimiterWait.WithLabelValues(r.GetName()).Observe(time.Since(start).Seconds())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed waiting for rate limiter: %w", err)
+	}
+	return r.ReportClient.Report(ctx, log, pj)
+}
+
+// ReportThrottle limits the rate of reports across all reporters whose
+// controllers it is passed to with WithReportThrottle. Unlike the workers of
+// a reporter, which bound how many of its reports are in flight, it bounds
+// how many reports start per second, which smooths the burst of reports of a
+// backlog that built up e.g. while crier was down.
+type ReportThrottle struct {
+	limiter *rate.Limiter
+}
+
+// NewReportThrottle returns a throttle that allows at most reportsPerSecond
+// reports per second. Reports aren't allowed in bursts, so the rate holds
+// for any window of time.
+func NewReportThrottle(reportsPerSecond float64) *ReportThrottle {
+	return &ReportThrottle{
+		limiter: rate.NewLimiter(rate.Limit(reportsPerSecond), 1),
+	}
+}
+
+// WithReportThrottle makes the controller wait for the throttle before every
+// report. Passing the same throttle to the controllers of all reporters caps
+// the rate of reports of crier as a whole. Jobs that don't need reporting
+// aren't throttled.
+func WithReportThrottle(throttle *ReportThrottle) Option {
+	return func(o *Options) {
+		o.ReportThrottle = throttle
+	}
+}
+
+// wait blocks until the named reporter may report.
+func (t *ReportThrottle) wait(ctx context.Context, reporter string) error {
+	start := time.Now()
+	err := t.limiter.Wait(ctx)
+	crierMetrics.reportThrottleWait.WithLabelValues(reporter).Observe(time.Since(start).Seconds())
+	if err != nil {
+		return fmt.Errorf("failed waiting for report throttle: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlruntime "sigs.k8s.io/controller-runtime"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+)
+
+func TestRateLimitedReporter(t *testing.T) {
+	rp := &fakeReporter{}
+	r := NewRateLimitedReporter(rp, 0.001, 1)
+	log := logrus.NewEntry(logrus.StandardLogger())
+	pj := &prowv1.ProwJob{Spec: prowv1.ProwJobSpec{Job: "foo"}}
+
+	if r.GetName() != reporterName {
+		t.Errorf("expected name of the wrapped reporter, got %q", r.GetName())
+	}
+
+	// The burst allows the first report right away.
+	if _, _, err := r.Report(context.Background(), log, pj); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The next one has to wait much longer than the context allows.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, _, err := r.Report(ctx, log, pj); err == nil {
+		t.Error("expected error when the context expires before the limiter allows reporting")
+	}
+
+	if len(rp.reported) != 1 {
+		t.Errorf("expected reporter to be called once, got %d calls", len(rp.reported))
+	}
+	if count := testutil.CollectAndCount(crierMetrics.rateLimiterWait); count != 1 {
+		t.Errorf("expected wait time to be observed for one reporter, got %d", count)
+	}
+}
+
+// timedReporter records when it reports, across all its workers.
+type timedReporter struct {
+	name string
+	lock *sync.Mutex
+	at   *[]time.Time
+}
+
+func (r *timedReporter) Report(_ context.Context, _ *logrus.Entry, pj *prowv1.ProwJob) ([]*prowv1.ProwJob, *reconcile.Result, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	*r.at = append(*r.at, time.Now())
+	return []*prowv1.ProwJob{pj}, nil, nil
+}
+
+func (r *timedReporter) GetName() string {
+	return r.name
+}
+
+func (r *timedReporter) ShouldReport(_ context.Context, _ *logrus.Entry, _ *prowv1.ProwJob) bool {
+	return true
+}
+
+func TestReportThrottle(t *testing.T) {
+	const (
+		reportsPerSecond = 20
+		jobs             = 6
+	)
+	var objects []ctrlruntimeclient.Object
+	for i := 0; i < jobs; i++ {
+		objects = append(objects, &prowv1.ProwJob{
+			ObjectMeta: v1.ObjectMeta{Name: fmt.Sprintf("job-%d", i)},
+			Status:     prowv1.ProwJobStatus{State: prowv1.SuccessState},
+		})
+	}
+	cs := fakectrlruntimeclient.NewClientBuilder().WithObjects(objects...).Build()
+
+	// Two reporters with a worker per job report all jobs at once, which
+	// the shared throttle spreads out.
+	throttle := NewReportThrottle(reportsPerSecond)
+	var lock sync.Mutex
+	var reportedAt []time.Time
+	var wg sync.WaitGroup
+	start := time.Now()
+	for _, name := range []string{"first", "second"} {
+		r := &reconciler{
+			pjclientset: cs,
+			reporter:    &timedReporter{name: name, lock: &lock, at: &reportedAt},
+			throttle:    throttle,
+		}
+		for i := 0; i < jobs; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				req := ctrlruntime.Request{NamespacedName: types.NamespacedName{Name: fmt.Sprintf("job-%d", i)}}
+				if _, err := r.Reconcile(context.Background(), req); err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			}(i)
+		}
+	}
+	wg.Wait()
+
+	if len(reportedAt) != 2*jobs {
+		t.Fatalf("expected %d reports, got %d", 2*jobs, len(reportedAt))
+	}
+	sort.Slice(reportedAt, func(i, j int) bool { return reportedAt[i].Before(reportedAt[j]) })
+	// Single intervals between reports jitter with the scheduling of the
+	// workers, but the throttle can't let the last report start before
+	// every other report got its share of time. Allow for some imprecision
+	// of the timer the limiter waits with.
+	minDuration := time.Second / reportsPerSecond * time.Duration(len(reportedAt)-1) * 9 / 10
+	if duration := reportedAt[len(reportedAt)-1].Sub(start); duration < minDuration {
+		t.Errorf("%d reports took %s, expected at least %s", len(reportedAt), duration, minDuration)
+	}
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// ConnectivityChecker is implemented by reporters that can cheaply check
+// whether the backend they report to is reachable.
+type ConnectivityChecker interface {
+	// CheckConnectivity returns an error if the backend can't be reached.
+	CheckConnectivity(ctx context.Context) error
+}
+
+const (
+	// readinessCheckTimeout bounds how long the checks of a probe may take.
+	readinessCheckTimeout = 10 * time.Second
+	// readinessCheckInterval is how long the result of the checks is
+	// reused, so that frequent probes don't hammer the backends.
+	readinessCheckInterval = 30 * time.Second
+)
+
+// Readiness tells whether the reporters that are considered critical can
+// reach their backends. Reporters are registered with WithReadiness and
+// only take part if they implement ConnectivityChecker. Failed checks of
+// reporters that aren't critical are logged but don't fail readiness.
+type Readiness struct {
+	critical sets.Set[string]
+	now      func() time.Time
+
+	lock      sync.Mutex
+	reporters sets.Set[string]
+	checkers  map[string]ConnectivityChecker
+	checkedAt time.Time
+	ready     bool
+}
+
+// NewReadiness returns a Readiness that fails if any of the named reporters
+// can't reach its backend.
+func NewReadiness(criticalReporters []string) *Readiness {
+	return &Readiness{
+		critical:  sets.New(criticalReporters...),
+		now:       time.Now,
+		reporters: sets.New[string](),
+		checkers:  map[string]ConnectivityChecker{},
+	}
+}
+
+// WithReadiness registers the reporter with readiness, which runs its
+// connectivity check if it implements ConnectivityChecker.
+func WithReadiness(readiness *Readiness) Option {
+	return func(o *Options) {
+		o.Readiness = readiness
+	}
+}
+
+func (r *Readiness) register(reporter ReportClient) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.reporters.Insert(reporter.GetName())
+	if checker, ok := reporter.(ConnectivityChecker); ok {
+		r.checkers[reporter.GetName()] = checker
+	}
+}
+
+// Validate returns an error if any of the critical reporters isn't
+// registered, e.g. because its name is misspelled or it isn't enabled, or
+// can't check its connectivity, as readiness would then never fail for it.
+// It must be called once all reporters are registered.
+func (r *Readiness) Validate() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var errs []string
+	for _, name := range sets.List(r.critical) {
+		if !r.reporters.Has(name) {
+			errs = append(errs, fmt.Sprintf("%s is not enabled", name))
+		} else if _, ok := r.checkers[name]; !ok {
+			errs = append(errs, fmt.Sprintf("%s doesn't check its connectivity", name))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid critical reporters: %s (enabled reporters: %s)", strings.Join(errs, ", "), strings.Join(sets.List(r.reporters), ", "))
+}
+
+// Ready runs the connectivity checks of all registered reporters, unless
+// they ran recently, and returns whether all critical ones succeeded. It
+// satisfies pjutil.ReadinessCheck.
+func (r *Readiness) Ready() bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if !r.checkedAt.IsZero() && r.now().Sub(r.checkedAt) < readinessCheckInterval {
+		return r.ready
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), readinessCheckTimeout)
+	defer cancel()
+	errs := make(map[string]error, len(r.checkers))
+	var errsLock sync.Mutex
+	var wg sync.WaitGroup
+	for name, checker := range r.checkers {
+		wg.Add(1)
+		go func(name string, checker ConnectivityChecker) {
+			defer wg.Done()
+			err := checker.CheckConnectivity(ctx)
+			errsLock.Lock()
+			errs[name] = err
+			errsLock.Unlock()
+		}(name, checker)
+	}
+	wg.Wait()
+
+	ready := true
+	for name, err := range errs {
+		if err == nil {
+			continue
+		}
+		log := logrus.WithError(err).WithField("reporter", name)
+		if r.critical.Has(name) {
+			log.Error("Critical reporter can't reach its backend")
+			ready = false
+		} else {
+			log.Warn("Reporter can't reach its backend")
+		}
+	}
+	r.ready = ready
+	r.checkedAt = r.now()
+	return ready
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+)
+
+type checkingReporter struct {
+	name   string
+	err    error
+	checks int
+}
+
+func (r *checkingReporter) Report(_ context.Context, _ *logrus.Entry, pj *prowv1.ProwJob) ([]*prowv1.ProwJob, *reconcile.Result, error) {
+	return []*prowv1.ProwJob{pj}, nil, nil
+}
+
+func (r *checkingReporter) GetName() string {
+	return r.name
+}
+
+func (r *checkingReporter) ShouldReport(_ context.Context, _ *logrus.Entry, _ *prowv1.ProwJob) bool {
+	return true
+}
+
+func (r *checkingReporter) CheckConnectivity(_ context.Context) error {
+	r.checks++
+	return r.err
+}
+
+func TestReadiness(t *testing.T) {
+	testCases := []struct {
+		name      string
+		critical  []string
+		reporters []ReportClient
+		expected  bool
+	}{
+		{
+			name:     "no reporters is ready",
+			expected: true,
+		},
+		{
+			name:      "all reporters reachable is ready",
+			critical:  []string{"a"},
+			reporters: []ReportClient{&checkingReporter{name: "a"}, &checkingReporter{name: "b"}},
+			expected:  true,
+		},
+		{
+			name:      "unreachable critical reporter is not ready",
+			critical:  []string{"a"},
+			reporters: []ReportClient{&checkingReporter{name: "a", err: errors.New("unreachable")}, &checkingReporter{name: "b"}},
+		},
+		{
+			name:      "unreachable non-critical reporter is ready",
+			critical:  []string{"a"},
+			reporters: []ReportClient{&checkingReporter{name: "a"}, &checkingReporter{name: "b", err: errors.New("unreachable")}},
+			expected:  true,
+		},
+		{
+			name:      "critical reporter without connectivity check is ready",
+			critical:  []string{reporterName},
+			reporters: []ReportClient{&fakeReporter{}},
+			expected:  true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			readiness := NewReadiness(tc.critical)
+			for _, reporter := range tc.reporters {
+				readiness.register(reporter)
+			}
+			if ready := readiness.Ready(); ready != tc.expected {
+				t.Errorf("expected ready to be %t, got %t", tc.expected, ready)
+			}
+		})
+	}
+}
+
+func TestReadinessReusesRecentResult(t *testing.T) {
+	reporter := &checkingReporter{name: "a", err: errors.New("unreachable")}
+	now := time.Now()
+	readiness := NewReadiness([]string{"a"})
+	readiness.now = func() time.Time { return now }
+	readiness.register(reporter)
+
+	if readiness.Ready() {
+		t.Fatal("expected not to be ready")
+	}
+	reporter.err = nil
+	if readiness.Ready() {
+		t.Error("expected the recent result to be reused")
+	}
+	if reporter.checks != 1 {
+		t.Errorf("expected one check, got %d", reporter.checks)
+	}
+
+	now = now.Add(readinessCheckInterval)
+	if !readiness.Ready() {
+		t.Error("expected to be ready after the interval passed")
+	}
+	if reporter.checks != 2 {
+		t.Errorf("expected two checks, got %d", reporter.checks)
+	}
+}
+
+func TestReadinessValidate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		critical  []string
+		reporters []ReportClient
+		expectErr bool
+	}{
+		{
+			name: "no critical reporters is valid",
+		},
+		{
+			name:      "critical reporter with connectivity check is valid",
+			critical:  []string{"a"},
+			reporters: []ReportClient{&checkingReporter{name: "a"}},
+		},
+		{
+			name:      "misspelled critical reporter is invalid",
+			critical:  []string{"slakreporter"},
+			reporters: []ReportClient{&checkingReporter{name: "a"}},
+			expectErr: true,
+		},
+		{
+			name:      "critical reporter without connectivity check is invalid",
+			critical:  []string{reporterName},
+			reporters: []ReportClient{&fakeReporter{}},
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			readiness := NewReadiness(tc.critical)
+			for _, reporter := range tc.reporters {
+				readiness.register(reporter)
+			}
+			if err := readiness.Validate(); (err != nil) != tc.expectErr {
+				t.Errorf("expected error: %t, got: %v", tc.expectErr, err)
+			}
+		})
+	}
+}
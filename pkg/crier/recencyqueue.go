@@ -0,0 +1,249 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+)
+
+// WithPrioritizeRecent makes the controller reconcile the jobs that
+// completed most recently first, so that the freshest results are reported
+// first when working through a backlog, e.g. after downtime. Jobs that
+// haven't completed yet are ordered by their start time. This is best
+// effort: the order is only decided among the jobs waiting in the queue,
+// and a job's recency is looked up when it's added.
+func WithPrioritizeRecent() Option {
+	return func(o *Options) {
+		o.PrioritizeRecent = true
+	}
+}
+
+// jobRecency returns the time the job completed, or started if it didn't
+// complete yet.
+func jobRecency(pj *prowv1.ProwJob) time.Time {
+	if pj.Status.CompletionTime != nil {
+		return pj.Status.CompletionTime.Time
+	}
+	return pj.Status.StartTime.Time
+}
+
+// recencyFromClient returns a function that looks up the recency of the job
+// of a queued request through the client, which should be backed by the
+// cache of the manager.
+func recencyFromClient(client ctrlruntimeclient.Reader) func(item interface{}) time.Time {
+	return func(item interface{}) time.Time {
+		req, ok := item.(reconcile.Request)
+		if !ok {
+			return time.Time{}
+		}
+		var pj prowv1.ProwJob
+		if err := client.Get(context.Background(), req.NamespacedName, &pj); err != nil {
+			return time.Time{}
+		}
+		return jobRecency(&pj)
+	}
+}
+
+// recencyQueue is a workqueue that hands out the most recent items first
+// instead of in the order they were added. Otherwise it follows the
+// semantics of client-go's workqueue: an item is queued at most once, and
+// an item that is added while it is processed is queued again once it is
+// done.
+type recencyQueue struct {
+	cond        *sync.Cond
+	recency     func(item interface{}) time.Time
+	rateLimiter workqueue.RateLimiter
+
+	items      recencyHeap
+	queued     map[interface{}]*recencyItem
+	dirty      map[interface{}]struct{}
+	processing map[interface{}]struct{}
+	seq        uint64
+
+	shuttingDown bool
+	drain        bool
+}
+
+var _ workqueue.RateLimitingInterface = &recencyQueue{}
+
+func newRecencyQueue(rateLimiter workqueue.RateLimiter, recency func(item interface{}) time.Time) *recencyQueue {
+	return &recencyQueue{
+		cond:        sync.NewCond(&sync.Mutex{}),
+		recency:     recency,
+		rateLimiter: rateLimiter,
+		queued:      map[interface{}]*recencyItem{},
+		dirty:       map[interface{}]struct{}{},
+		processing:  map[interface{}]struct{}{},
+	}
+}
+
+func (q *recencyQueue) Add(item interface{}) {
+	// Looked up before locking, as it may read from the cache.
+	recency := q.recency(item)
+
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	if q.shuttingDown {
+		return
+	}
+	if _, ok := q.processing[item]; ok {
+		q.dirty[item] = struct{}{}
+		return
+	}
+	if queued, ok := q.queued[item]; ok {
+		queued.recency = recency
+		heap.Fix(&q.items, queued.index)
+		return
+	}
+	q.seq++
+	queued := &recencyItem{item: item, recency: recency, seq: q.seq}
+	heap.Push(&q.items, queued)
+	q.queued[item] = queued
+	q.cond.Signal()
+}
+
+func (q *recencyQueue) Len() int {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	return len(q.items)
+}
+
+func (q *recencyQueue) Get() (interface{}, bool) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	for len(q.items) == 0 && !q.shuttingDown {
+		q.cond.Wait()
+	}
+	if len(q.items) == 0 {
+		return nil, true
+	}
+	next := heap.Pop(&q.items).(*recencyItem)
+	delete(q.queued, next.item)
+	q.processing[next.item] = struct{}{}
+	return next.item, false
+}
+
+func (q *recencyQueue) Done(item interface{}) {
+	q.cond.L.Lock()
+	delete(q.processing, item)
+	_, requeue := q.dirty[item]
+	delete(q.dirty, item)
+	q.cond.Broadcast()
+	q.cond.L.Unlock()
+	if requeue {
+		q.Add(item)
+	}
+}
+
+func (q *recencyQueue) ShutDown() {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	q.shuttingDown = true
+	q.drain = false
+	q.cond.Broadcast()
+}
+
+// ShutDownWithDrain shuts the queue down and waits for the items that are
+// processed to be done.
+func (q *recencyQueue) ShutDownWithDrain() {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	q.shuttingDown = true
+	q.drain = true
+	q.cond.Broadcast()
+	for len(q.processing) > 0 && q.drain {
+		q.cond.Wait()
+	}
+}
+
+func (q *recencyQueue) ShuttingDown() bool {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	return q.shuttingDown
+}
+
+func (q *recencyQueue) AddAfter(item interface{}, duration time.Duration) {
+	if q.ShuttingDown() {
+		return
+	}
+	if duration <= 0 {
+		q.Add(item)
+		return
+	}
+	time.AfterFunc(duration, func() { q.Add(item) })
+}
+
+func (q *recencyQueue) AddRateLimited(item interface{}) {
+	q.AddAfter(item, q.rateLimiter.When(item))
+}
+
+func (q *recencyQueue) Forget(item interface{}) {
+	q.rateLimiter.Forget(item)
+}
+
+func (q *recencyQueue) NumRequeues(item interface{}) int {
+	return q.rateLimiter.NumRequeues(item)
+}
+
+type recencyItem struct {
+	item    interface{}
+	recency time.Time
+	// seq orders items of the same recency by when they were added.
+	seq   uint64
+	index int
+}
+
+// recencyHeap implements heap.Interface with the most recent item first.
+type recencyHeap []*recencyItem
+
+func (h recencyHeap) Len() int { return len(h) }
+
+func (h recencyHeap) Less(i, j int) bool {
+	if !h[i].recency.Equal(h[j].recency) {
+		return h[i].recency.After(h[j].recency)
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h recencyHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *recencyHeap) Push(x any) {
+	item := x.(*recencyItem)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *recencyHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+)
+
+func newTestRecencyQueue(recency map[string]time.Time) *recencyQueue {
+	return newRecencyQueue(workqueue.DefaultControllerRateLimiter(), func(item interface{}) time.Time {
+		return recency[item.(string)]
+	})
+}
+
+// drain gets all queued items, marking them done.
+func drain(q *recencyQueue) []string {
+	var items []string
+	for q.Len() > 0 {
+		item, _ := q.Get()
+		items = append(items, item.(string))
+		q.Done(item)
+	}
+	return items
+}
+
+func TestRecencyQueueOrder(t *testing.T) {
+	now := time.Now()
+	q := newTestRecencyQueue(map[string]time.Time{
+		"old":    now.Add(-time.Hour),
+		"newest": now,
+		"newer":  now.Add(-time.Minute),
+	})
+	for _, item := range []string{"unknown-a", "old", "newest", "unknown-b", "newer", "old"} {
+		q.Add(item)
+	}
+
+	expected := []string{"newest", "newer", "old", "unknown-a", "unknown-b"}
+	if diff := cmp.Diff(expected, drain(q)); diff != "" {
+		t.Errorf("order differs from expected (-want +got):\n%s", diff)
+	}
+}
+
+func TestRecencyQueueUpdatesRecency(t *testing.T) {
+	now := time.Now()
+	recency := map[string]time.Time{"a": now.Add(-time.Hour), "b": now.Add(-time.Minute)}
+	q := newTestRecencyQueue(recency)
+	q.Add("a")
+	q.Add("b")
+	// a completed since it was queued.
+	recency["a"] = now
+	q.Add("a")
+
+	if diff := cmp.Diff([]string{"a", "b"}, drain(q)); diff != "" {
+		t.Errorf("order differs from expected (-want +got):\n%s", diff)
+	}
+}
+
+func TestRecencyQueueRequeuesItemAddedWhileProcessing(t *testing.T) {
+	q := newTestRecencyQueue(nil)
+	q.Add("a")
+	item, _ := q.Get()
+	q.Add("a")
+	if q.Len() != 0 {
+		t.Fatalf("expected an item that is processed not to be queued, got %d queued items", q.Len())
+	}
+	q.Done(item)
+	if diff := cmp.Diff([]string{"a"}, drain(q)); diff != "" {
+		t.Errorf("items differ from expected (-want +got):\n%s", diff)
+	}
+}
+
+func TestRecencyQueueShutDown(t *testing.T) {
+	q := newTestRecencyQueue(nil)
+	q.Add("a")
+	q.ShutDown()
+	q.Add("b")
+
+	if item, shutdown := q.Get(); item != "a" || shutdown {
+		t.Errorf("expected queued items to be handed out after shutdown, got %v, %t", item, shutdown)
+	}
+	if _, shutdown := q.Get(); !shutdown {
+		t.Error("expected Get to report the shutdown once the queue is empty")
+	}
+}
+
+func TestRecencyQueueShutDownWithDrain(t *testing.T) {
+	q := newTestRecencyQueue(nil)
+	q.Add("a")
+	item, _ := q.Get()
+
+	drained := make(chan struct{})
+	go func() {
+		q.ShutDownWithDrain()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		t.Fatal("expected ShutDownWithDrain to wait for the processed item")
+	case <-time.After(10 * time.Millisecond):
+	}
+	q.Done(item)
+	select {
+	case <-drained:
+	case <-time.After(time.Second):
+		t.Fatal("expected ShutDownWithDrain to return once the item is done")
+	}
+}
+
+func TestRecencyFromClient(t *testing.T) {
+	completion := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	start := completion.Add(-time.Hour)
+	completed := &prowv1.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "completed", Namespace: "prowjobs"},
+		Status:     prowv1.ProwJobStatus{StartTime: metav1.NewTime(start), CompletionTime: &metav1.Time{Time: completion}},
+	}
+	running := &prowv1.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "running", Namespace: "prowjobs"},
+		Status:     prowv1.ProwJobStatus{StartTime: metav1.NewTime(start)},
+	}
+	recency := recencyFromClient(fakectrlruntimeclient.NewClientBuilder().WithObjects(completed, running).Build())
+
+	request := func(name string) reconcile.Request {
+		return reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "prowjobs", Name: name}}
+	}
+	if got := recency(request("completed")); !got.Equal(completion) {
+		t.Errorf("expected the completion time %v for a completed job, got %v", completion, got)
+	}
+	if got := recency(request("running")); !got.Equal(start) {
+		t.Errorf("expected the start time %v for a running job, got %v", start, got)
+	}
+	if got := recency(request("deleted")); !got.IsZero() {
+		t.Errorf("expected no recency for a missing job, got %v", got)
+	}
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	stdio "io"
+	"path"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/io"
+	"sigs.k8s.io/prow/pkg/io/providers"
+)
+
+// replayedJobFile is the name of the file holding a job in its artifacts.
+const replayedJobFile = "prowjob.json"
+
+// Replay runs the jobs through the reporters the way the controllers would,
+// except that it ignores which states were reported already. It is meant
+// for trying out a reporter config against past jobs, so the reporters are
+// expected to run in dry-run mode and log what they would send. Failed
+// reports are logged and counted in the returned error.
+func Replay(ctx context.Context, log *logrus.Entry, reporters []ReportClient, enablementChecker EnablementChecker, pjs []prowv1.ProwJob) error {
+	var failed int
+	for _, pj := range pjs {
+		for _, reporter := range reporters {
+			log := log.WithFields(logrus.Fields{
+				"reporter": reporter.GetName(),
+				"jobName":  pj.Spec.Job,
+				"prowjob":  pj.Name,
+			})
+			enabled := func(org, repo string) bool {
+				return enablementChecker(reporter.GetName(), org, repo)
+			}
+			if !enabledForJob(&pj, enabled) {
+				log.Info("Reporter is not enabled for the repos of the job")
+				continue
+			}
+			pj := pj.DeepCopy()
+			if !reporter.ShouldReport(ctx, log, pj) {
+				log.Info("Reporter would not report the job")
+				continue
+			}
+			if _, _, err := reporter.Report(ctx, log, pj); err != nil {
+				log.WithError(err).Error("Failed to report the job")
+				failed++
+				continue
+			}
+			log.WithField("jobStatus", pj.Status.State).Info("Reported the job")
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d reports failed", failed)
+	}
+	return nil
+}
+
+// LoadJobsFromStorage reads the jobs from the prowjob.json files below the
+// storage path, e.g. gs://bucket/logs/my-job. Only completed jobs are
+// returned, at most limit of them, the most recently completed first.
+func LoadJobsFromStorage(ctx context.Context, log *logrus.Entry, opener io.Opener, storagePath string, limit int) ([]prowv1.ProwJob, error) {
+	storageProvider, bucket, _, err := providers.ParseStoragePath(storagePath)
+	if err != nil {
+		return nil, err
+	}
+	bucketName := fmt.Sprintf("%s://%s", storageProvider, bucket)
+
+	it, err := opener.Iterator(ctx, storagePath, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", storagePath, err)
+	}
+	var pjs []prowv1.ProwJob
+	for {
+		attrs, err := it.Next(ctx)
+		if err == stdio.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", storagePath, err)
+		}
+		if attrs.IsDir || path.Base(attrs.Name) != replayedJobFile {
+			continue
+		}
+		filePath, err := providers.StoragePath(bucketName, attrs.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s path: %w", attrs.Name, err)
+		}
+		content, err := io.ReadContent(ctx, log, opener, filePath)
+		if err != nil {
+			log.WithError(err).WithField("path", filePath).Warn("Failed to read job, skipping it")
+			continue
+		}
+		var pj prowv1.ProwJob
+		if err := json.Unmarshal(content, &pj); err != nil {
+			log.WithError(err).WithField("path", filePath).Warn("Failed to parse job, skipping it")
+			continue
+		}
+		pjs = append(pjs, pj)
+	}
+	return mostRecentlyCompleted(pjs, limit), nil
+}
+
+// LoadJobsFromCluster lists the jobs in the namespace. Only completed jobs
+// are returned, at most limit of them, the most recently completed first.
+func LoadJobsFromCluster(ctx context.Context, reader ctrlruntimeclient.Reader, namespace string, limit int) ([]prowv1.ProwJob, error) {
+	var pjs prowv1.ProwJobList
+	if err := reader.List(ctx, &pjs, ctrlruntimeclient.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list jobs in namespace %s: %w", namespace, err)
+	}
+	return mostRecentlyCompleted(pjs.Items, limit), nil
+}
+
+func mostRecentlyCompleted(pjs []prowv1.ProwJob, limit int) []prowv1.ProwJob {
+	var completed []prowv1.ProwJob
+	for _, pj := range pjs {
+		if pj.Complete() {
+			completed = append(completed, pj)
+		}
+	}
+	sort.SliceStable(completed, func(i, j int) bool {
+		return completed[j].Status.CompletionTime.Before(completed[i].Status.CompletionTime)
+	})
+	if limit > 0 && len(completed) > limit {
+		completed = completed[:limit]
+	}
+	return completed
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	stdio "io"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/io"
+	"sigs.k8s.io/prow/pkg/io/fakeopener"
+)
+
+func replayedJob(name string, state prowv1.ProwJobState, completedMinutesAgo int) prowv1.ProwJob {
+	pj := prowv1.ProwJob{
+		ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "prowjobs"},
+		Spec: prowv1.ProwJobSpec{
+			Job:  name,
+			Refs: &prowv1.Refs{Org: "org", Repo: "repo"},
+		},
+		Status: prowv1.ProwJobStatus{State: state},
+	}
+	if completedMinutesAgo >= 0 {
+		pj.Status.CompletionTime = &v1.Time{Time: time.Now().Add(-time.Duration(completedMinutesAgo) * time.Minute)}
+	}
+	return pj
+}
+
+func TestReplay(t *testing.T) {
+	reported := replayedJob("reported", prowv1.SuccessState, 1)
+	// Replaying ignores which states were reported already.
+	reported.Status.PrevReportStates = map[string]prowv1.ProwJobState{reporterName: prowv1.SuccessState}
+	skipped := replayedJob("skipped", prowv1.FailureState, 2)
+	disabled := replayedJob("disabled", prowv1.FailureState, 3)
+	disabled.Spec.Refs.Repo = "disabled"
+
+	reporter := &fakeReporter{shouldReportFunc: func(pj *prowv1.ProwJob) bool {
+		return pj.Name != "skipped"
+	}}
+	enablementChecker := func(_, _, repo string) bool {
+		return repo != "disabled"
+	}
+	pjs := []prowv1.ProwJob{reported, skipped, disabled}
+	if err := Replay(context.Background(), logrus.NewEntry(logrus.New()), []ReportClient{reporter}, enablementChecker, pjs); err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{"reported"}, reporter.reported); diff != "" {
+		t.Errorf("reported jobs differ from expected (-want +got):\n%s", diff)
+	}
+
+	reporter = &fakeReporter{
+		shouldReportFunc: func(*prowv1.ProwJob) bool { return true },
+		err:              errors.New("boom"),
+	}
+	err := Replay(context.Background(), logrus.NewEntry(logrus.New()), []ReportClient{reporter}, enablementChecker, pjs)
+	if err == nil || err.Error() != "2 reports failed" {
+		t.Errorf("expected error about 2 failed reports, got %v", err)
+	}
+	if diff := cmp.Diff([]string{"reported", "skipped"}, reporter.reported); diff != "" {
+		t.Errorf("reported jobs differ from expected (-want +got):\n%s", diff)
+	}
+}
+
+// iterableOpener lists the files of the fake opener, which are all in the
+// gs://bucket bucket.
+type iterableOpener struct {
+	*fakeopener.FakeOpener
+}
+
+func (o *iterableOpener) Iterator(_ context.Context, prefix, _ string) (io.ObjectIterator, error) {
+	var names []string
+	for p := range o.Buffer {
+		if strings.HasPrefix(p, prefix) {
+			names = append(names, strings.TrimPrefix(p, "gs://bucket/"))
+		}
+	}
+	sort.Strings(names)
+	return &listIterator{names: names}, nil
+}
+
+type listIterator struct {
+	names []string
+}
+
+func (it *listIterator) Next(_ context.Context) (io.ObjectAttributes, error) {
+	if len(it.names) == 0 {
+		return io.ObjectAttributes{}, stdio.EOF
+	}
+	name := it.names[0]
+	it.names = it.names[1:]
+	return io.ObjectAttributes{Name: name}, nil
+}
+
+func TestLoadJobsFromStorage(t *testing.T) {
+	buffer := map[string]*bytes.Buffer{
+		"gs://bucket/logs/job/1/build-log.txt":  bytes.NewBufferString("log"),
+		"gs://bucket/logs/job/5/prowjob.json":   bytes.NewBufferString("not json"),
+		"gs://bucket/logs/other/1/prowjob.json": bytes.NewBufferString("{}"),
+	}
+	for i, pj := range []prowv1.ProwJob{
+		replayedJob("older", prowv1.SuccessState, 10),
+		replayedJob("newest", prowv1.FailureState, 1),
+		replayedJob("running", prowv1.PendingState, -1),
+		replayedJob("newer", prowv1.SuccessState, 5),
+	} {
+		raw, err := json.Marshal(pj)
+		if err != nil {
+			t.Fatalf("failed to marshal job: %v", err)
+		}
+		buffer[fmt.Sprintf("gs://bucket/logs/job/%d/prowjob.json", i+1)] = bytes.NewBuffer(raw)
+	}
+	opener := &iterableOpener{FakeOpener: &fakeopener.FakeOpener{Buffer: buffer}}
+
+	pjs, err := LoadJobsFromStorage(context.Background(), logrus.NewEntry(logrus.New()), opener, "gs://bucket/logs/job/", 2)
+	if err != nil {
+		t.Fatalf("LoadJobsFromStorage failed: %v", err)
+	}
+	var names []string
+	for _, pj := range pjs {
+		names = append(names, pj.Name)
+	}
+	if diff := cmp.Diff([]string{"newest", "newer"}, names); diff != "" {
+		t.Errorf("loaded jobs differ from expected (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoadJobsFromCluster(t *testing.T) {
+	older := replayedJob("older", prowv1.SuccessState, 10)
+	newer := replayedJob("newer", prowv1.FailureState, 1)
+	running := replayedJob("running", prowv1.PendingState, -1)
+	elsewhere := replayedJob("elsewhere", prowv1.SuccessState, 1)
+	elsewhere.Namespace = "other"
+	reader := fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(&older, &newer, &running, &elsewhere).Build()
+
+	pjs, err := LoadJobsFromCluster(context.Background(), reader, "prowjobs", 0)
+	if err != nil {
+		t.Fatalf("LoadJobsFromCluster failed: %v", err)
+	}
+	var names []string
+	for _, pj := range pjs {
+		names = append(names, pj.Name)
+	}
+	if diff := cmp.Diff([]string{"newer", "older"}, names); diff != "" {
+		t.Errorf("loaded jobs differ from expected (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package reporterplugin loads crier reporters from Go plugins, so that
+// reporters can be added to crier without patching it.
+//
+// A reporter plugin is a Go package `main` built with
+// `go build -buildmode=plugin` that exports a constructor named
+// NewReporter with the signature of Constructor:
+//
+//	func NewReporter(opts reporterplugin.Options) (crier.ReportClient, error)
+//
+// Go plugins are only loaded if the plugin was built with the same Go
+// toolchain, build flags and versions of all packages it shares with crier,
+// including this module, as the crier binary loading it.
+package reporterplugin
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"plugin"
+	"sort"
+
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/crier"
+)
+
+// ConstructorSymbol is the name of the constructor a reporter plugin must
+// export.
+const ConstructorSymbol = "NewReporter"
+
+// Options are passed to the constructor of a reporter plugin.
+type Options struct {
+	// Config returns the current Prow config.
+	Config config.Getter
+	// Client reads and writes ProwJobs and other objects in the namespace
+	// of the ProwJobs.
+	Client ctrlruntimeclient.Client
+	// DryRun is set if crier runs with --dry-run, in which case the
+	// reporter must not change anything.
+	DryRun bool
+}
+
+// Constructor is the signature of the constructor a reporter plugin exports
+// as ConstructorSymbol. Only its ReportClient methods are used by crier,
+// besides the optional interfaces crier checks for, like
+// crier.ConfigValidator.
+type Constructor func(Options) (crier.ReportClient, error)
+
+// symbolLookup is the part of *plugin.Plugin used by the loader.
+type symbolLookup interface {
+	Lookup(symName string) (plugin.Symbol, error)
+}
+
+// open opens a plugin, replaced in tests as building plugins needs cgo.
+var open = func(path string) (symbolLookup, error) {
+	return plugin.Open(path)
+}
+
+// Load opens every `.so` file in the directory as a reporter plugin, in
+// lexical order, and constructs its reporter. Every reporter must have a
+// distinct name.
+func Load(dir string, opts Options) ([]crier.ReportClient, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.so"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reporter plugins: %w", err)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no reporter plugins found in %s", dir)
+	}
+	sort.Strings(paths)
+
+	var reporters []crier.ReportClient
+	names := map[string]string{}
+	for _, path := range paths {
+		reporter, err := load(path, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load reporter plugin %s: %w", path, err)
+		}
+		name := reporter.GetName()
+		if name == "" {
+			return nil, fmt.Errorf("reporter of plugin %s has no name", path)
+		}
+		if other, ok := names[name]; ok {
+			return nil, fmt.Errorf("reporters of plugins %s and %s are both named %s", other, path, name)
+		}
+		names[name] = path
+		reporters = append(reporters, reporter)
+	}
+	return reporters, nil
+}
+
+func load(path string, opts Options) (crier.ReportClient, error) {
+	p, err := open(path)
+	if err != nil {
+		return nil, err
+	}
+	sym, err := p.Lookup(ConstructorSymbol)
+	if err != nil {
+		return nil, err
+	}
+	// Exported functions are looked up as values of their unnamed
+	// function type, not as Constructor.
+	constructor, ok := sym.(func(Options) (crier.ReportClient, error))
+	if !ok {
+		return nil, fmt.Errorf("%s has type %T instead of %T", ConstructorSymbol, sym, Constructor(nil))
+	}
+	reporter, err := constructor(opts)
+	if err != nil {
+		return nil, err
+	}
+	if reporter == nil {
+		return nil, errors.New("constructor returned no reporter")
+	}
+	return reporter, nil
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reporterplugin
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"plugin"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/crier"
+)
+
+type fakeReporter struct {
+	name   string
+	dryRun bool
+}
+
+func (r *fakeReporter) Report(context.Context, *logrus.Entry, *prowapi.ProwJob) ([]*prowapi.ProwJob, *reconcile.Result, error) {
+	return nil, nil, nil
+}
+
+func (r *fakeReporter) GetName() string {
+	return r.name
+}
+
+func (r *fakeReporter) ShouldReport(context.Context, *logrus.Entry, *prowapi.ProwJob) bool {
+	return true
+}
+
+type fakePlugin map[string]plugin.Symbol
+
+func (p fakePlugin) Lookup(name string) (plugin.Symbol, error) {
+	if sym, ok := p[name]; ok {
+		return sym, nil
+	}
+	return nil, errors.New("symbol not found")
+}
+
+func constructorOf(name string) func(Options) (crier.ReportClient, error) {
+	return func(opts Options) (crier.ReportClient, error) {
+		return &fakeReporter{name: name, dryRun: opts.DryRun}, nil
+	}
+}
+
+func TestLoad(t *testing.T) {
+	testCases := []struct {
+		name          string
+		plugins       map[string]fakePlugin
+		expected      []string
+		expectedError bool
+	}{
+		{
+			name: "plugins are loaded in lexical order",
+			plugins: map[string]fakePlugin{
+				"b.so": {ConstructorSymbol: constructorOf("second")},
+				"a.so": {ConstructorSymbol: constructorOf("first")},
+			},
+			expected: []string{"first", "second"},
+		},
+		{
+			name:          "no plugins",
+			expectedError: true,
+		},
+		{
+			name: "missing constructor",
+			plugins: map[string]fakePlugin{
+				"a.so": {"New": constructorOf("first")},
+			},
+			expectedError: true,
+		},
+		{
+			name: "constructor of the wrong type",
+			plugins: map[string]fakePlugin{
+				"a.so": {ConstructorSymbol: func() crier.ReportClient { return &fakeReporter{name: "first"} }},
+			},
+			expectedError: true,
+		},
+		{
+			name: "constructor fails",
+			plugins: map[string]fakePlugin{
+				"a.so": {ConstructorSymbol: func(Options) (crier.ReportClient, error) { return nil, errors.New("injected") }},
+			},
+			expectedError: true,
+		},
+		{
+			name: "reporter without a name",
+			plugins: map[string]fakePlugin{
+				"a.so": {ConstructorSymbol: constructorOf("")},
+			},
+			expectedError: true,
+		},
+		{
+			name: "reporters with the same name",
+			plugins: map[string]fakePlugin{
+				"a.so": {ConstructorSymbol: constructorOf("first")},
+				"b.so": {ConstructorSymbol: constructorOf("first")},
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name := range tc.plugins {
+				if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// Files without the .so extension are not plugins.
+			if err := os.WriteFile(filepath.Join(dir, "README.md"), nil, 0644); err != nil {
+				t.Fatal(err)
+			}
+			origOpen := open
+			defer func() { open = origOpen }()
+			open = func(path string) (symbolLookup, error) {
+				p, ok := tc.plugins[filepath.Base(path)]
+				if !ok {
+					t.Fatalf("unexpected plugin %s opened", path)
+				}
+				return p, nil
+			}
+
+			reporters, err := Load(dir, Options{DryRun: true})
+			if (err != nil) != tc.expectedError {
+				t.Fatalf("expected error: %t, got %v", tc.expectedError, err)
+			}
+			var names []string
+			for _, r := range reporters {
+				names = append(names, r.GetName())
+				if !r.(*fakeReporter).dryRun {
+					t.Errorf("reporter %s was not constructed with the options", r.GetName())
+				}
+			}
+			if diff := cmp.Diff(tc.expected, names); diff != "" {
+				t.Errorf("reporters differ from expected (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bitbucket contains a reporter that posts the state of jobs as
+// build statuses of the commits they ran against on Bitbucket Server.
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	bitbucketclient "sigs.k8s.io/prow/pkg/bitbucket"
+	"sigs.k8s.io/prow/pkg/config"
+)
+
+const (
+	reporterName = "bitbucketreporter"
+)
+
+// buildStates maps job states to the state of the build status.
+var buildStates = map[prowapi.ProwJobState]string{
+	prowapi.TriggeredState: bitbucketclient.InProgress,
+	prowapi.PendingState:   bitbucketclient.InProgress,
+	prowapi.SuccessState:   bitbucketclient.Successful,
+	prowapi.FailureState:   bitbucketclient.Failed,
+	prowapi.ErrorState:     bitbucketclient.Failed,
+	// The build status API has no cancelled state and a successful status
+	// would let the pull request merge, so unlike in other reporters aborted
+	// jobs are failures. The description of the status tells them apart.
+	prowapi.AbortedState: bitbucketclient.Failed,
+}
+
+// abortedDescription describes aborted jobs that don't have a description.
+const abortedDescription = "Job was aborted."
+
+type bitbucketClient interface {
+	SetBuildStatus(ctx context.Context, server, sha string, status bitbucketclient.BuildStatus) error
+}
+
+type bitbucketReporter struct {
+	client bitbucketClient
+	config func(*prowapi.Refs) config.BitbucketReporter
+	dryRun bool
+}
+
+func (br *bitbucketReporter) Report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) ([]*prowapi.ProwJob, *reconcile.Result, error) {
+	return []*prowapi.ProwJob{pj}, nil, br.report(ctx, log, pj)
+}
+
+func (br *bitbucketReporter) report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) error {
+	cfg := br.config(pj.Spec.Refs)
+	sha := commitSHA(pj.Spec.Refs)
+	status := buildStatus(pj)
+
+	log = log.WithFields(logrus.Fields{"server": cfg.Server, "sha": sha, "state": status.State})
+	if br.dryRun {
+		log.Debug("Skipping reporting because dry-run is enabled")
+		return nil
+	}
+	if err := br.client.SetBuildStatus(ctx, cfg.Server, sha, status); err != nil {
+		log.WithError(err).Error("failed to set Bitbucket build status")
+		return fmt.Errorf("failed to set Bitbucket build status: %w", err)
+	}
+	return nil
+}
+
+// commitSHA returns the commit the job ran against: the head of the pull
+// request for presubmits and the base commit for jobs without pulls, like
+// postsubmits. Batches test several pull requests at once, so there is no
+// single commit to report their status on and an empty string is returned.
+func commitSHA(refs *prowapi.Refs) string {
+	if refs == nil {
+		return ""
+	}
+	switch len(refs.Pulls) {
+	case 0:
+		return refs.BaseSHA
+	case 1:
+		return refs.Pulls[0].SHA
+	default:
+		return ""
+	}
+}
+
+// buildStatus returns the build status of the job. The key is the job's
+// context, so that every run of a job replaces the status of the last one.
+func buildStatus(pj *prowapi.ProwJob) bitbucketclient.BuildStatus {
+	key := pj.Spec.Context
+	if key == "" {
+		key = pj.Spec.Job
+	}
+	description := pj.Status.Description
+	if description == "" && pj.Status.State == prowapi.AbortedState {
+		description = abortedDescription
+	}
+	return bitbucketclient.BuildStatus{
+		State:       buildStates[pj.Status.State],
+		Key:         key,
+		Name:        key,
+		URL:         pj.Status.URL,
+		Description: description,
+	}
+}
+
+func (br *bitbucketReporter) GetName() string {
+	return reporterName
+}
+
+func (br *bitbucketReporter) ShouldReport(_ context.Context, logger *logrus.Entry, pj *prowapi.ProwJob) bool {
+	cfg := br.config(pj.Spec.Refs)
+
+	// Bitbucket requires a URL on every build status, which jobs only get
+	// once they are scheduled.
+	_, knownState := buildStates[pj.Status.State]
+	shouldReport := slices.Contains(cfg.JobTypesToReport, pj.Spec.Type) && knownState && cfg.Server != "" && commitSHA(pj.Spec.Refs) != "" && pj.Status.URL != ""
+	logger.WithField("reporting", shouldReport).Debug("Determined should report")
+	return shouldReport
+}
+
+func New(cfg func(refs *prowapi.Refs) config.BitbucketReporter, dryRun bool, tokenGenerator func() []byte, httpClient *http.Client) *bitbucketReporter {
+	return &bitbucketReporter{
+		client: bitbucketclient.NewClient(tokenGenerator, httpClient),
+		config: cfg,
+		dryRun: dryRun,
+	}
+}
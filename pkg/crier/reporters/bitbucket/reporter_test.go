@@ -0,0 +1,225 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bitbucket
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+
+	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	bitbucketclient "sigs.k8s.io/prow/pkg/bitbucket"
+	"sigs.k8s.io/prow/pkg/config"
+)
+
+func TestShouldReport(t *testing.T) {
+	cfg := config.BitbucketReporter{
+		JobTypesToReport: []v1.ProwJobType{v1.PresubmitJob, v1.PostsubmitJob},
+		Server:           "https://bitbucket.example.com",
+	}
+	testCases := []struct {
+		name     string
+		config   config.BitbucketReporter
+		pj       *v1.ProwJob
+		expected bool
+	}{
+		{
+			name:   "presubmit with a single pull should report",
+			config: cfg,
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PresubmitJob, Refs: &v1.Refs{BaseSHA: "base", Pulls: []v1.Pull{{Number: 1, SHA: "head"}}}},
+				Status: v1.ProwJobStatus{State: v1.PendingState, URL: "https://prow.example.com/view/1"},
+			},
+			expected: true,
+		},
+		{
+			name:   "postsubmit should report",
+			config: cfg,
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PostsubmitJob, Refs: &v1.Refs{BaseSHA: "base"}},
+				Status: v1.ProwJobStatus{State: v1.SuccessState, URL: "https://prow.example.com/view/1"},
+			},
+			expected: true,
+		},
+		{
+			name:   "wrong job type should not report",
+			config: cfg,
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PeriodicJob, Refs: &v1.Refs{BaseSHA: "base"}},
+				Status: v1.ProwJobStatus{State: v1.SuccessState, URL: "https://prow.example.com/view/1"},
+			},
+			expected: false,
+		},
+		{
+			name:   "job without URL should not report",
+			config: cfg,
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PostsubmitJob, Refs: &v1.Refs{BaseSHA: "base"}},
+				Status: v1.ProwJobStatus{State: v1.TriggeredState},
+			},
+			expected: false,
+		},
+		{
+			name:   "job without refs should not report",
+			config: cfg,
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PostsubmitJob},
+				Status: v1.ProwJobStatus{State: v1.SuccessState, URL: "https://prow.example.com/view/1"},
+			},
+			expected: false,
+		},
+		{
+			name: "batch should not report",
+			config: config.BitbucketReporter{
+				JobTypesToReport: []v1.ProwJobType{v1.BatchJob},
+				Server:           "https://bitbucket.example.com",
+			},
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.BatchJob, Refs: &v1.Refs{BaseSHA: "base", Pulls: []v1.Pull{{Number: 1, SHA: "a"}, {Number: 2, SHA: "b"}}}},
+				Status: v1.ProwJobStatus{State: v1.SuccessState, URL: "https://prow.example.com/view/1"},
+			},
+			expected: false,
+		},
+		{
+			name: "empty config should not report",
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PostsubmitJob, Refs: &v1.Refs{BaseSHA: "base"}},
+				Status: v1.ProwJobStatus{State: v1.SuccessState, URL: "https://prow.example.com/view/1"},
+			},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reporter := &bitbucketReporter{
+				config: func(*v1.Refs) config.BitbucketReporter { return tc.config },
+			}
+			if result := reporter.ShouldReport(context.Background(), logrus.NewEntry(logrus.StandardLogger()), tc.pj); result != tc.expected {
+				t.Errorf("expected result to be %t but was %t", tc.expected, result)
+			}
+		})
+	}
+}
+
+type setStatus struct {
+	server string
+	sha    string
+	status bitbucketclient.BuildStatus
+}
+
+type fakeBitbucketClient struct {
+	statuses []setStatus
+}
+
+func (fbc *fakeBitbucketClient) SetBuildStatus(_ context.Context, server, sha string, status bitbucketclient.BuildStatus) error {
+	fbc.statuses = append(fbc.statuses, setStatus{server: server, sha: sha, status: status})
+	return nil
+}
+
+var _ bitbucketClient = &fakeBitbucketClient{}
+
+func TestReport(t *testing.T) {
+	testCases := []struct {
+		name     string
+		pj       *v1.ProwJob
+		dryRun   bool
+		expected []setStatus
+	}{
+		{
+			name: "pending presubmit is reported in progress on the head of the pull",
+			pj: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Job:     "pull-test",
+					Context: "ci/test",
+					Type:    v1.PresubmitJob,
+					Refs:    &v1.Refs{Org: "PROJ", Repo: "repo", BaseSHA: "base", Pulls: []v1.Pull{{Number: 1, SHA: "head"}}},
+				},
+				Status: v1.ProwJobStatus{State: v1.PendingState, URL: "https://prow.example.com/view/1", Description: "Job triggered."},
+			},
+			expected: []setStatus{{
+				server: "https://bitbucket.example.com",
+				sha:    "head",
+				status: bitbucketclient.BuildStatus{
+					State:       bitbucketclient.InProgress,
+					Key:         "ci/test",
+					Name:        "ci/test",
+					URL:         "https://prow.example.com/view/1",
+					Description: "Job triggered.",
+				},
+			}},
+		},
+		{
+			name: "aborted postsubmit is reported failed with a description on the base commit",
+			pj: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Job:  "post-test",
+					Type: v1.PostsubmitJob,
+					Refs: &v1.Refs{Org: "PROJ", Repo: "other", BaseSHA: "base"},
+				},
+				Status: v1.ProwJobStatus{State: v1.AbortedState, URL: "https://prow.example.com/view/2"},
+			},
+			expected: []setStatus{{
+				server: "https://stash.example.com",
+				sha:    "base",
+				status: bitbucketclient.BuildStatus{
+					State:       bitbucketclient.Failed,
+					Key:         "post-test",
+					Name:        "post-test",
+					URL:         "https://prow.example.com/view/2",
+					Description: "Job was aborted.",
+				},
+			}},
+		},
+		{
+			name:   "dry-run does not report",
+			dryRun: true,
+			pj: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Job:  "post-test",
+					Type: v1.PostsubmitJob,
+					Refs: &v1.Refs{Org: "PROJ", Repo: "repo", BaseSHA: "base"},
+				},
+				Status: v1.ProwJobStatus{State: v1.SuccessState, URL: "https://prow.example.com/view/3"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fbc := &fakeBitbucketClient{}
+			reporter := &bitbucketReporter{
+				client: fbc,
+				config: func(r *v1.Refs) config.BitbucketReporter {
+					return config.BitbucketReporterConfigs{
+						"*":         {Server: "https://stash.example.com"},
+						"PROJ/repo": {Server: "https://bitbucket.example.com"},
+					}.GetBitbucketReporter(r)
+				},
+				dryRun: tc.dryRun,
+			}
+			if _, _, err := reporter.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), tc.pj); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, fbc.statuses, cmp.AllowUnexported(setStatus{})); diff != "" {
+				t.Errorf("unexpected statuses (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package criercommonlib
+
+import (
+	"sort"
+	"strings"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+)
+
+// RefsForConfigKeys returns refs that resolve to the configs of the given
+// `org/repo`, `org` or `*` keys of a reporter config, so that reporters can
+// check all of their configs through the getter they report with. The `*`
+// key is resolved with nil refs.
+func RefsForConfigKeys(keys []string) []*prowapi.Refs {
+	keys = append([]string(nil), keys...)
+	sort.Strings(keys)
+	var refs []*prowapi.Refs
+	for _, key := range keys {
+		if key == "*" {
+			refs = append(refs, nil)
+			continue
+		}
+		org, repo, _ := strings.Cut(key, "/")
+		refs = append(refs, &prowapi.Refs{Org: org, Repo: repo})
+	}
+	return refs
+}
@@ -0,0 +1,32 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package criercommonlib
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+)
+
+func TestRefsForConfigKeys(t *testing.T) {
+	expected := []*prowapi.Refs{nil, {Org: "org"}, {Org: "org", Repo: "repo"}}
+	if diff := cmp.Diff(expected, RefsForConfigKeys([]string{"org/repo", "*", "org"})); diff != "" {
+		t.Errorf("refs differ from expected (-want +got):\n%s", diff)
+	}
+}
@@ -44,3 +44,65 @@ func UserError(err error) error {
 func IsUserError(err error) bool {
 	return errors.Is(err, userError{})
 }
+
+// ReportError is an error that knows whether reporting the job again can
+// succeed. Crier retries reports that failed with a retryable error and
+// drops the others, e.g. reports to a channel that doesn't exist, which
+// would fail on every retry until the config is fixed.
+type ReportError interface {
+	error
+	Retryable() bool
+}
+
+type reportError struct {
+	err       error
+	retryable bool
+}
+
+func (re *reportError) Error() string {
+	return re.err.Error()
+}
+
+func (re *reportError) Unwrap() error {
+	return re.err
+}
+
+func (re *reportError) Retryable() bool {
+	return re.retryable
+}
+
+// TransientError wraps an error in a ReportError that is retried.
+func TransientError(err error) error {
+	return &reportError{err: err, retryable: true}
+}
+
+// PermanentError wraps an error in a ReportError that isn't retried.
+func PermanentError(err error) error {
+	return &reportError{err: err, retryable: false}
+}
+
+// The classes of errors returned by ErrorClass.
+const (
+	ErrorClassTransient    = "transient"
+	ErrorClassPermanent    = "permanent"
+	ErrorClassUnclassified = "unclassified"
+)
+
+// ErrorClass returns whether the error is transient or permanent, or
+// unclassified if it doesn't wrap a ReportError.
+func ErrorClass(err error) string {
+	var reportErr ReportError
+	if !errors.As(err, &reportErr) {
+		return ErrorClassUnclassified
+	}
+	if reportErr.Retryable() {
+		return ErrorClassTransient
+	}
+	return ErrorClassPermanent
+}
+
+// IsPermanentError checks whether the error wraps a ReportError that isn't
+// retryable. Unclassified errors are retried.
+func IsPermanentError(err error) bool {
+	return ErrorClass(err) == ErrorClassPermanent
+}
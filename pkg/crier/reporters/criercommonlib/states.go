@@ -0,0 +1,37 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package criercommonlib
+
+import (
+	"slices"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+)
+
+// IsTerminalNonFailure tells whether the job finished without failing, i.e.
+// it succeeded or was aborted. Aborted jobs were usually superseded by a
+// newer run or cancelled on purpose, so reporters render them as neutral
+// rather than as failures and don't alert on them.
+func IsTerminalNonFailure(state prowapi.ProwJobState) bool {
+	return state == prowapi.SuccessState || state == prowapi.AbortedState
+}
+
+// ShouldReportJob tells whether the job has one of the types and one of the
+// states to report.
+func ShouldReportJob(pj *prowapi.ProwJob, typesToReport []prowapi.ProwJobType, statesToReport []prowapi.ProwJobState) bool {
+	return slices.Contains(typesToReport, pj.Spec.Type) && slices.Contains(statesToReport, pj.Status.State)
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package criercommonlib
+
+import (
+	"testing"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+)
+
+func TestIsTerminalNonFailure(t *testing.T) {
+	expected := map[prowapi.ProwJobState]bool{
+		prowapi.TriggeredState: false,
+		prowapi.PendingState:   false,
+		prowapi.SuccessState:   true,
+		prowapi.AbortedState:   true,
+		prowapi.FailureState:   false,
+		prowapi.ErrorState:     false,
+	}
+	for state, want := range expected {
+		if got := IsTerminalNonFailure(state); got != want {
+			t.Errorf("IsTerminalNonFailure(%q) = %t, want %t", state, got, want)
+		}
+	}
+}
+
+func TestShouldReportJob(t *testing.T) {
+	types := []prowapi.ProwJobType{prowapi.PeriodicJob, prowapi.PostsubmitJob}
+	states := []prowapi.ProwJobState{prowapi.FailureState}
+	testCases := []struct {
+		name     string
+		jobType  prowapi.ProwJobType
+		state    prowapi.ProwJobState
+		expected bool
+	}{
+		{
+			name:     "type and state to report",
+			jobType:  prowapi.PostsubmitJob,
+			state:    prowapi.FailureState,
+			expected: true,
+		},
+		{
+			name:    "type not to report",
+			jobType: prowapi.PresubmitJob,
+			state:   prowapi.FailureState,
+		},
+		{
+			name:    "state not to report",
+			jobType: prowapi.PeriodicJob,
+			state:   prowapi.SuccessState,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pj := &prowapi.ProwJob{
+				Spec:   prowapi.ProwJobSpec{Type: tc.jobType},
+				Status: prowapi.ProwJobStatus{State: tc.state},
+			}
+			if got := ShouldReportJob(pj, types, states); got != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, got)
+			}
+		})
+	}
+}
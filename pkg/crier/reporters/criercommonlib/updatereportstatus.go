@@ -32,7 +32,7 @@ import (
 	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
 )
 
-func updateReportState(ctx context.Context, pj *prowv1.ProwJob, log *logrus.Entry, reportedState prowv1.ProwJobState, pjclientset ctrlruntimeclient.Client, reporterName string) error {
+func updateReportState(ctx context.Context, pj *prowv1.ProwJob, log *logrus.Entry, reportedState prowv1.ProwJobState, pjclientset ctrlruntimeclient.Client, reporterName string, opts ...ctrlruntimeclient.MergeFromOption) error {
 	// update pj report status
 	newpj := pj.DeepCopy()
 	// we set omitempty on PrevReportStates, so here we need to init it if is nil
@@ -41,7 +41,7 @@ func updateReportState(ctx context.Context, pj *prowv1.ProwJob, log *logrus.Entr
 	}
 	newpj.Status.PrevReportStates[reporterName] = reportedState
 
-	if err := pjclientset.Patch(ctx, newpj, ctrlruntimeclient.MergeFrom(pj)); err != nil {
+	if err := pjclientset.Patch(ctx, newpj, ctrlruntimeclient.MergeFromWithOptions(pj, opts...)); err != nil {
 		return fmt.Errorf("failed to patch: %w", err)
 	}
 
@@ -91,3 +91,27 @@ func UpdateReportStateWithRetries(ctx context.Context, pj *prowv1.ProwJob, log *
 	log.Info("Successfully updated report state on prowjob")
 	return nil
 }
+
+// ClaimReportState marks the current state of pj as reported by reporterName
+// before it is actually reported. The patch fails with a conflict if pj was
+// changed since it was read, e.g. because another worker already claimed it,
+// so at most one caller can claim a state. If the process dies after
+// claiming, the state is never reported.
+func ClaimReportState(ctx context.Context, pj *prowv1.ProwJob, log *logrus.Entry, pjclientset ctrlruntimeclient.Client, reporterName string) error {
+	if err := updateReportState(ctx, pj, log, pj.Status.State, pjclientset, reporterName, ctrlruntimeclient.MergeFromWithOptimisticLock{}); err != nil {
+		return fmt.Errorf("failed to claim report state on prowjob: %w", err)
+	}
+	return nil
+}
+
+// ReleaseReportState resets the report state of reporterName on pj to
+// prevState, so that a state claimed through ClaimReportState whose report
+// failed is reported again. pj must be the job as claimed. It is not read
+// again, because the cache may not contain the claim yet, which would make
+// the patch empty and leave the claim in place.
+func ReleaseReportState(ctx context.Context, pj *prowv1.ProwJob, log *logrus.Entry, pjclientset ctrlruntimeclient.Client, reporterName string, prevState prowv1.ProwJobState) error {
+	if err := updateReportState(ctx, pj, log, prevState, pjclientset, reporterName); err != nil {
+		return fmt.Errorf("failed to release report state on prowjob: %w", err)
+	}
+	return nil
+}
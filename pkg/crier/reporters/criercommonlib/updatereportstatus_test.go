@@ -0,0 +1,69 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package criercommonlib
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+)
+
+// staleClient returns a fixed version of the job on Get, like a cache that
+// hasn't seen the latest writes yet.
+type staleClient struct {
+	ctrlruntimeclient.Client
+	stale *prowv1.ProwJob
+}
+
+func (c *staleClient) Get(_ context.Context, _ types.NamespacedName, obj ctrlruntimeclient.Object, _ ...ctrlruntimeclient.GetOption) error {
+	c.stale.DeepCopyInto(obj.(*prowv1.ProwJob))
+	return nil
+}
+
+func TestReleaseReportStateWithStaleCache(t *testing.T) {
+	const reporter = "test-reporter"
+	job := &prowv1.ProwJob{
+		Status: prowv1.ProwJobStatus{
+			State:            prowv1.FailureState,
+			PrevReportStates: map[string]prowv1.ProwJobState{reporter: prowv1.PendingState},
+		},
+	}
+	job.Name = "foo"
+	claimed := job.DeepCopy()
+	claimed.Status.PrevReportStates[reporter] = prowv1.FailureState
+
+	apiServer := fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(claimed.DeepCopy()).Build()
+	cs := &staleClient{Client: apiServer, stale: job}
+
+	if err := ReleaseReportState(context.Background(), claimed, logrus.NewEntry(logrus.StandardLogger()), cs, reporter, prowv1.PendingState); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var pj prowv1.ProwJob
+	if err := apiServer.Get(context.Background(), types.NamespacedName{Name: "foo"}, &pj); err != nil {
+		t.Fatalf("failed to get prowjob: %v", err)
+	}
+	if state := pj.Status.PrevReportStates[reporter]; state != prowv1.PendingState {
+		t.Errorf("expected report state to be released to %q, got %q", prowv1.PendingState, state)
+	}
+}
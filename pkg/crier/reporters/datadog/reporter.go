@@ -0,0 +1,246 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package datadog contains a crier reporter that posts an event to the
+// Datadog Events API for every completed job.
+package datadog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/crier/reporters/criercommonlib"
+)
+
+const (
+	reporterName = "datadogreporter"
+
+	// defaultRetryBase is the delay before the first retry of a request,
+	// doubled with every further retry up to maxRetryDelay.
+	defaultRetryBase = time.Second
+	maxRetryDelay    = time.Minute
+)
+
+// permanentError is returned for requests that must not be retried.
+type permanentError struct {
+	error
+}
+
+// rateLimitError is returned for requests that were rate limited. reset is
+// how long until the rate limit resets, if Datadog told.
+type rateLimitError struct {
+	error
+	reset time.Duration
+}
+
+// event is the payload of the Datadog Events API, see
+// https://docs.datadoghq.com/api/latest/events/#post-an-event.
+type event struct {
+	Title          string   `json:"title"`
+	Text           string   `json:"text"`
+	AlertType      string   `json:"alert_type"`
+	AggregationKey string   `json:"aggregation_key,omitempty"`
+	SourceTypeName string   `json:"source_type_name"`
+	DateHappened   int64    `json:"date_happened,omitempty"`
+	Tags           []string `json:"tags"`
+}
+
+type datadogReporter struct {
+	config    func(*prowapi.Refs) config.DatadogReporter
+	apiKey    func() []byte
+	client    *http.Client
+	retryBase time.Duration
+	// baseURL overrides the URL derived from the configured site in tests.
+	baseURL string
+	dryRun  bool
+}
+
+func (dr *datadogReporter) getConfig(pj *prowapi.ProwJob) config.DatadogReporter {
+	refs := pj.Spec.Refs
+	if refs == nil && len(pj.Spec.ExtraRefs) > 0 {
+		refs = &pj.Spec.ExtraRefs[0]
+	}
+	return dr.config(refs)
+}
+
+func (dr *datadogReporter) Report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) ([]*prowapi.ProwJob, *reconcile.Result, error) {
+	return []*prowapi.ProwJob{pj}, nil, dr.report(ctx, log, pj)
+}
+
+func (dr *datadogReporter) report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) error {
+	cfg := dr.getConfig(pj)
+
+	payload, err := json.Marshal(eventFor(pj, cfg))
+	if err != nil {
+		return fmt.Errorf("failed to marshal Datadog event: %w", err)
+	}
+	if dr.dryRun {
+		log.WithField("site", cfg.Site).WithField("event", string(payload)).Debug("Skipping reporting because dry-run is enabled")
+		return nil
+	}
+
+	delay := dr.retryBase
+	for attempt := 0; ; attempt++ {
+		err := dr.post(ctx, cfg, payload)
+		if err == nil {
+			return nil
+		}
+		if _, ok := err.(permanentError); ok || attempt >= cfg.MaxRetries {
+			log.WithError(err).Error("failed to post Datadog event")
+			return fmt.Errorf("failed to post Datadog event: %w", err)
+		}
+		wait := delay
+		if rateLimited, ok := err.(rateLimitError); ok && rateLimited.reset > wait {
+			wait = rateLimited.reset
+		}
+		log.WithError(err).WithField("attempt", attempt+1).WithField("delay", wait).Debug("Failed to post Datadog event, retrying")
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("failed to post Datadog event: %w", err)
+		case <-time.After(wait):
+		}
+		delay = min(2*delay, maxRetryDelay)
+	}
+}
+
+func (dr *datadogReporter) post(ctx context.Context, cfg config.DatadogReporter, payload []byte) error {
+	baseURL := dr.baseURL
+	if baseURL == "" {
+		baseURL = "https://api." + cfg.Site
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/api/v1/events", bytes.NewReader(payload))
+	if err != nil {
+		return permanentError{err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("DD-API-KEY", strings.TrimSpace(string(dr.apiKey())))
+
+	resp, err := dr.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	body, _ := io.ReadAll(resp.Body)
+	err = fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(body))
+	if resp.StatusCode == http.StatusTooManyRequests {
+		// Datadog sends the seconds until the rate limit resets.
+		reset, _ := strconv.Atoi(resp.Header.Get("X-RateLimit-Reset"))
+		return rateLimitError{error: err, reset: time.Duration(reset) * time.Second}
+	}
+	// Other client errors, e.g. an invalid API key, won't go away by retrying.
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+		return permanentError{err}
+	}
+	return err
+}
+
+// alertType returns the Datadog alert type of a job in the given state.
+// Jobs that ended without failing but didn't succeed, e.g. aborted ones,
+// are informational so that monitors don't alert on them.
+func alertType(state prowapi.ProwJobState) string {
+	switch {
+	case state == prowapi.SuccessState:
+		return "success"
+	case criercommonlib.IsTerminalNonFailure(state):
+		return "info"
+	case state == prowapi.FailureState || state == prowapi.ErrorState:
+		return "error"
+	default:
+		return "info"
+	}
+}
+
+// eventFor returns the event reporting the job, tagged by its repo, name,
+// type and state. Events of the same job share an aggregation key, so
+// Datadog groups the runs of a job.
+func eventFor(pj *prowapi.ProwJob, cfg config.DatadogReporter) *event {
+	tags := []string{
+		"job:" + pj.Spec.Job,
+		"job_type:" + string(pj.Spec.Type),
+		"state:" + string(pj.Status.State),
+	}
+	refs := pj.Spec.Refs
+	if refs == nil && len(pj.Spec.ExtraRefs) > 0 {
+		refs = &pj.Spec.ExtraRefs[0]
+	}
+	if refs != nil {
+		tags = append(tags, "repo:"+refs.Org+"/"+refs.Repo)
+	}
+	tags = append(tags, cfg.Tags...)
+
+	text := pj.Status.Description
+	if pj.Status.URL != "" {
+		text = strings.TrimSpace(text + "\n" + pj.Status.URL)
+	}
+	e := &event{
+		Title:          fmt.Sprintf("Prow job %s ended with %s", pj.Spec.Job, pj.Status.State),
+		Text:           text,
+		AlertType:      alertType(pj.Status.State),
+		AggregationKey: "prow/" + pj.Spec.Job,
+		SourceTypeName: "prow",
+		Tags:           tags,
+	}
+	if pj.Status.CompletionTime != nil {
+		e.DateHappened = pj.Status.CompletionTime.Unix()
+	}
+	return e
+}
+
+func (dr *datadogReporter) GetName() string {
+	return reporterName
+}
+
+func (dr *datadogReporter) ShouldReport(_ context.Context, logger *logrus.Entry, pj *prowapi.ProwJob) bool {
+	cfg := dr.getConfig(pj)
+
+	shouldReport := criercommonlib.ShouldReportJob(pj, cfg.JobTypesToReport, cfg.JobStatesToReport)
+	logger.WithField("reporting", shouldReport).Debug("Determined should report")
+	return shouldReport
+}
+
+// New returns a Datadog reporter that authenticates with the API key
+// returned by apiKey. Requests are sent with the transport of httpClient, or
+// of http.DefaultClient if nil, and time out after 10s.
+func New(cfg func(refs *prowapi.Refs) config.DatadogReporter, dryRun bool, apiKey func() []byte, httpClient *http.Client) *datadogReporter {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	client := *httpClient
+	client.Timeout = 10 * time.Second
+	return &datadogReporter{
+		config:    cfg,
+		apiKey:    apiKey,
+		client:    &client,
+		retryBase: defaultRetryBase,
+		dryRun:    dryRun,
+	}
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datadog
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+)
+
+func TestShouldReport(t *testing.T) {
+	testCases := []struct {
+		name     string
+		config   config.DatadogReporter
+		pj       *v1.ProwJob
+		expected bool
+	}{
+		{
+			name: "matching type and state should report",
+			config: config.DatadogReporter{
+				JobTypesToReport:  []v1.ProwJobType{v1.PeriodicJob},
+				JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+			},
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PeriodicJob},
+				Status: v1.ProwJobStatus{State: v1.FailureState},
+			},
+			expected: true,
+		},
+		{
+			name: "wrong job type should not report",
+			config: config.DatadogReporter{
+				JobTypesToReport:  []v1.ProwJobType{v1.PeriodicJob},
+				JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+			},
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PresubmitJob},
+				Status: v1.ProwJobStatus{State: v1.FailureState},
+			},
+			expected: false,
+		},
+		{
+			name: "wrong state should not report",
+			config: config.DatadogReporter{
+				JobTypesToReport:  []v1.ProwJobType{v1.PeriodicJob},
+				JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+			},
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PeriodicJob},
+				Status: v1.ProwJobStatus{State: v1.PendingState},
+			},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reporter := &datadogReporter{
+				config: func(*v1.Refs) config.DatadogReporter { return tc.config },
+			}
+			if result := reporter.ShouldReport(context.Background(), logrus.NewEntry(logrus.StandardLogger()), tc.pj); result != tc.expected {
+				t.Errorf("expected result to be %t but was %t", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestEventFor(t *testing.T) {
+	completion := metav1.NewTime(time.Date(2024, time.May, 1, 10, 0, 0, 0, time.UTC))
+	testCases := []struct {
+		name     string
+		pj       *v1.ProwJob
+		expected *event
+	}{
+		{
+			name: "failed postsubmit",
+			pj: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Job:  "post-build",
+					Type: v1.PostsubmitJob,
+					Refs: &v1.Refs{Org: "org", Repo: "repo"},
+				},
+				Status: v1.ProwJobStatus{
+					State:          v1.FailureState,
+					Description:    "Job failed.",
+					URL:            "https://prow.example.com/view/post-build/1",
+					CompletionTime: &completion,
+				},
+			},
+			expected: &event{
+				Title:          "Prow job post-build ended with failure",
+				Text:           "Job failed.\nhttps://prow.example.com/view/post-build/1",
+				AlertType:      "error",
+				AggregationKey: "prow/post-build",
+				SourceTypeName: "prow",
+				DateHappened:   completion.Unix(),
+				Tags:           []string{"job:post-build", "job_type:postsubmit", "state:failure", "repo:org/repo", "env:ci"},
+			},
+		},
+		{
+			name: "aborted periodic without refs",
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Job: "nightly", Type: v1.PeriodicJob},
+				Status: v1.ProwJobStatus{State: v1.AbortedState},
+			},
+			expected: &event{
+				Title:          "Prow job nightly ended with aborted",
+				AlertType:      "info",
+				AggregationKey: "prow/nightly",
+				SourceTypeName: "prow",
+				Tags:           []string{"job:nightly", "job_type:periodic", "state:aborted", "env:ci"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := eventFor(tc.pj, config.DatadogReporter{Tags: []string{"env:ci"}})
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("event differs from expected (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReport(t *testing.T) {
+	testCases := []struct {
+		name           string
+		maxRetries     int
+		dryRun         bool
+		statusCodes    []int
+		rateLimitReset string
+		expectErr      bool
+		expectRequests int
+	}{
+		{
+			name:           "event is posted",
+			statusCodes:    []int{http.StatusAccepted},
+			expectRequests: 1,
+		},
+		{
+			name:           "server errors are retried",
+			maxRetries:     2,
+			statusCodes:    []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusAccepted},
+			expectRequests: 3,
+		},
+		{
+			name:           "rate limited requests are retried",
+			maxRetries:     1,
+			statusCodes:    []int{http.StatusTooManyRequests, http.StatusAccepted},
+			rateLimitReset: "0",
+			expectRequests: 2,
+		},
+		{
+			name:           "retries are exhausted",
+			maxRetries:     1,
+			statusCodes:    []int{http.StatusTooManyRequests, http.StatusTooManyRequests},
+			expectErr:      true,
+			expectRequests: 2,
+		},
+		{
+			name:           "invalid API key is not retried",
+			maxRetries:     3,
+			statusCodes:    []int{http.StatusForbidden},
+			expectErr:      true,
+			expectRequests: 1,
+		},
+		{
+			name:   "dry-run does not send",
+			dryRun: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if r.URL.Path != "/api/v1/events" {
+					t.Errorf("expected request to /api/v1/events, got %s", r.URL.Path)
+				}
+				if key := r.Header.Get("DD-API-KEY"); key != "secret" {
+					t.Errorf("expected API key %q, got %q", "secret", key)
+				}
+				var e event
+				if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+					t.Errorf("failed to decode event: %v", err)
+				}
+				if e.AlertType != "error" {
+					t.Errorf("expected alert type error, got %q", e.AlertType)
+				}
+				if tc.rateLimitReset != "" {
+					w.Header().Set("X-RateLimit-Reset", tc.rateLimitReset)
+				}
+				w.WriteHeader(tc.statusCodes[requests-1])
+			}))
+			defer server.Close()
+
+			reporter := &datadogReporter{
+				config: func(*v1.Refs) config.DatadogReporter {
+					return config.DatadogReporter{Site: "datadoghq.com", MaxRetries: tc.maxRetries}
+				},
+				apiKey:    func() []byte { return []byte("secret\n") },
+				client:    server.Client(),
+				retryBase: time.Millisecond,
+				baseURL:   server.URL,
+				dryRun:    tc.dryRun,
+			}
+
+			pj := &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Job: "my-job", Type: v1.PeriodicJob},
+				Status: v1.ProwJobStatus{State: v1.FailureState},
+			}
+			_, _, err := reporter.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj)
+			if tc.expectErr != (err != nil) {
+				t.Errorf("expected error: %t, got: %v", tc.expectErr, err)
+			}
+			if requests != tc.expectRequests {
+				t.Errorf("expected %d requests, got %d", tc.expectRequests, requests)
+			}
+		})
+	}
+}
@@ -22,12 +22,15 @@ import (
 	"errors"
 	"fmt"
 	"html/template"
+	"net/http"
 
 	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
 	"sigs.k8s.io/prow/pkg/config"
 
 	"github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/prow/pkg/crier/reporters/criercommonlib"
 	dingtalkclient "sigs.k8s.io/prow/pkg/dingtalk"
 )
 
@@ -36,12 +39,19 @@ const (
 )
 
 type dingTalkClient interface {
-	WriteMessage(msg, token string) error
+	WriteMessage(ctx context.Context, msg, token string) error
+	WriteSignedMessage(ctx context.Context, msg, token string, secret []byte) error
 }
 
 type dingTalkReporter struct {
 	client dingTalkClient
 	config func(*prowapi.Refs) config.DingTalkReporter
+	// configKeys returns the `org/repo`, `org` and `*` keys of the config,
+	// so that all of it can be validated.
+	configKeys func() []string
+	// secret returns the content of a secret file loaded at startup, or nil
+	// when the file isn't known.
+	secret func(path string) []byte
 	dryRun bool
 }
 
@@ -59,14 +69,14 @@ func (sr *dingTalkReporter) getConfig(pj *prowapi.ProwJob) (*config.DingTalkRepo
 }
 
 func (sr *dingTalkReporter) Report(
-	_ context.Context,
+	ctx context.Context,
 	log *logrus.Entry,
 	pj *prowapi.ProwJob,
 ) ([]*prowapi.ProwJob, *reconcile.Result, error) {
-	return []*prowapi.ProwJob{pj}, nil, sr.report(log, pj)
+	return []*prowapi.ProwJob{pj}, nil, sr.report(ctx, log, pj)
 }
 
-func (sr *dingTalkReporter) report(log *logrus.Entry, pj *prowapi.ProwJob) error {
+func (sr *dingTalkReporter) report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) error {
 	globalDingTalkConfig, jobDingTalkConfig := sr.getConfig(pj)
 	if globalDingTalkConfig != nil {
 		jobDingTalkConfig = jobDingTalkConfig.ApplyDefault(&globalDingTalkConfig.DingTalkReporterConfig)
@@ -89,13 +99,61 @@ func (sr *dingTalkReporter) report(log *logrus.Entry, pj *prowapi.ProwJob) error
 		log.WithField("messagejson", b.String()).Debug("Skipping reporting because dry-run is enabled")
 		return nil
 	}
-	if err := sr.client.WriteMessage(b.String(), jobDingTalkConfig.Token); err != nil {
+	if globalDingTalkConfig.SecretFile != "" {
+		err = sr.writeSignedMessage(ctx, b.String(), jobDingTalkConfig.Token, globalDingTalkConfig.SecretFile)
+	} else {
+		err = sr.client.WriteMessage(ctx, b.String(), jobDingTalkConfig.Token)
+	}
+	if err != nil {
 		log.WithError(err).Error("failed to write DingTalk message")
 		return fmt.Errorf("failed to write DingTalk message: %w", err)
 	}
 	return nil
 }
 
+func (sr *dingTalkReporter) writeSignedMessage(ctx context.Context, msg, token, secretFile string) error {
+	var secret []byte
+	if sr.secret != nil {
+		secret = sr.secret(secretFile)
+	}
+	if len(secret) == 0 {
+		return fmt.Errorf("secret file %q is not loaded, crier needs to be restarted to pick up new secret files", secretFile)
+	}
+	return sr.client.WriteSignedMessage(ctx, msg, token, secret)
+}
+
+// Validate checks that the configs of all orgs and repos that report jobs
+// have a token and a report template that parses, and that their secret
+// files are loaded. DingTalk has no API to check tokens with, so they
+// aren't sent anywhere.
+func (sr *dingTalkReporter) Validate(_ context.Context) error {
+	var keys []string
+	if sr.configKeys != nil {
+		keys = sr.configKeys()
+	}
+	var errs []error
+	for _, refs := range criercommonlib.RefsForConfigKeys(keys) {
+		cfg := sr.config(refs)
+		name := "*"
+		if refs != nil {
+			name = refs.Org
+			if refs.Repo != "" {
+				name += "/" + refs.Repo
+			}
+		}
+		if len(cfg.JobTypesToReport) > 0 && cfg.Token == "" {
+			errs = append(errs, fmt.Errorf("%s: reports job types %v but has no token", name, cfg.JobTypesToReport))
+		}
+		if _, err := template.New("").Parse(cfg.ReportTemplate); err != nil {
+			errs = append(errs, fmt.Errorf("%s: failed to parse template: %w", name, err))
+		}
+		if cfg.SecretFile != "" && (sr.secret == nil || len(sr.secret(cfg.SecretFile)) == 0) {
+			errs = append(errs, fmt.Errorf("%s: secret file %q is not loaded", name, cfg.SecretFile))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
 func (sr *dingTalkReporter) GetName() string {
 	return reporterName
 }
@@ -146,10 +204,14 @@ func (sr *dingTalkReporter) ShouldReport(_ context.Context, logger *logrus.Entry
 	return shouldReport
 }
 
-func New(cfg func(refs *prowapi.Refs) config.DingTalkReporter, dryRun bool) *dingTalkReporter {
+// New returns a DingTalk reporter. configKeys returns the keys of the
+// config that cfg resolves, which are checked by Validate.
+func New(cfg func(refs *prowapi.Refs) config.DingTalkReporter, configKeys func() []string, secret func(path string) []byte, dryRun bool, httpClient *http.Client) *dingTalkReporter {
 	return &dingTalkReporter{
-		client: dingtalkclient.NewClient(),
-		config: cfg,
-		dryRun: dryRun,
+		client:     dingtalkclient.NewClient(httpClient),
+		config:     cfg,
+		configKeys: configKeys,
+		secret:     secret,
+		dryRun:     dryRun,
 	}
 }
@@ -23,7 +23,9 @@ import (
 	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
 	"sigs.k8s.io/prow/pkg/config"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 func TestShouldReport(t *testing.T) {
@@ -631,9 +633,10 @@ func TestShouldReportDefaultsToExtraRefs(t *testing.T) {
 
 type fakeDingTalkClient struct {
 	messages map[string]string
+	secrets  map[string]string
 }
 
-func (fsc *fakeDingTalkClient) WriteMessage(msg, token string) error {
+func (fsc *fakeDingTalkClient) WriteMessage(_ context.Context, msg, token string) error {
 	if fsc.messages == nil {
 		fsc.messages = map[string]string{}
 	}
@@ -641,6 +644,14 @@ func (fsc *fakeDingTalkClient) WriteMessage(msg, token string) error {
 	return nil
 }
 
+func (fsc *fakeDingTalkClient) WriteSignedMessage(ctx context.Context, msg, token string, secret []byte) error {
+	if fsc.secrets == nil {
+		fsc.secrets = map[string]string{}
+	}
+	fsc.secrets[token] = string(secret)
+	return fsc.WriteMessage(ctx, msg, token)
+}
+
 var _ dingTalkClient = &fakeDingTalkClient{}
 
 func TestReportDefaultsToExtraRefs(t *testing.T) {
@@ -691,3 +702,126 @@ func TestReportDefaultsToExtraRefs(t *testing.T) {
 		)
 	}
 }
+
+func TestReportSignsMessages(t *testing.T) {
+	secrets := map[string][]byte{"/etc/dingtalk/secret": []byte("SEC0123456789abcdef")}
+	testCases := []struct {
+		name        string
+		secretFile  string
+		wantSecrets map[string]string
+		wantErr     bool
+	}{
+		{
+			name: "no secret file, message is not signed",
+		},
+		{
+			name:        "secret file, message is signed",
+			secretFile:  "/etc/dingtalk/secret",
+			wantSecrets: map[string]string{"token": "SEC0123456789abcdef"},
+		},
+		{
+			name:       "secret file not loaded, error",
+			secretFile: "/etc/dingtalk/other",
+			wantErr:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			job := &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Type: v1.PeriodicJob,
+				},
+				Status: v1.ProwJobStatus{
+					State: v1.SuccessState,
+				},
+			}
+			fsc := &fakeDingTalkClient{}
+			sr := dingTalkReporter{
+				config: func(*v1.Refs) config.DingTalkReporter {
+					return config.DingTalkReporter{
+						SecretFile: tc.secretFile,
+						DingTalkReporterConfig: v1.DingTalkReporterConfig{
+							Token:          "token",
+							ReportTemplate: "{{.Status.State}}",
+						},
+					}
+				},
+				secret: func(path string) []byte { return secrets[path] },
+				client: fsc,
+			}
+			_, _, err := sr.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), job)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error: %t, got: %v", tc.wantErr, err)
+			}
+			if diff := cmp.Diff(tc.wantSecrets, fsc.secrets); diff != "" {
+				t.Errorf("unexpected signing secrets (-want +got):\n%s", diff)
+			}
+			if !tc.wantErr && fsc.messages["token"] != "success" {
+				t.Errorf("expected message %q, got messages: %v", "success", fsc.messages)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secrets := map[string][]byte{"/etc/dingtalk/secret": []byte("SEC0123456789abcdef")}
+	testCases := []struct {
+		name        string
+		configs     config.DingTalkReporterConfigs
+		expectedErr string
+	}{
+		{
+			name: "valid configs",
+			configs: config.DingTalkReporterConfigs{
+				"*": {
+					JobTypesToReport: []v1.ProwJobType{v1.PeriodicJob},
+					SecretFile:       "/etc/dingtalk/secret",
+					DingTalkReporterConfig: v1.DingTalkReporterConfig{
+						Token:          "token",
+						ReportTemplate: "{{.Status.State}}",
+					},
+				},
+				"org": {},
+			},
+		},
+		{
+			name: "invalid configs",
+			configs: config.DingTalkReporterConfigs{
+				"*": {
+					JobTypesToReport: []v1.ProwJobType{v1.PeriodicJob},
+					DingTalkReporterConfig: v1.DingTalkReporterConfig{
+						ReportTemplate: "{{.Status.State}",
+					},
+				},
+				"org/repo": {
+					SecretFile: "/etc/dingtalk/other",
+					DingTalkReporterConfig: v1.DingTalkReporterConfig{
+						Token: "token",
+					},
+				},
+			},
+			expectedErr: `[*: reports job types [periodic] but has no token, *: failed to parse template: template: :1: bad character U+007D '}', org/repo: secret file "/etc/dingtalk/other" is not loaded]`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sr := dingTalkReporter{
+				config: tc.configs.GetDingTalkReporter,
+				configKeys: func() []string {
+					return sets.List(sets.KeySet(tc.configs))
+				},
+				secret: func(path string) []byte { return secrets[path] },
+			}
+			err := sr.Validate(context.Background())
+			var got string
+			if err != nil {
+				got = err.Error()
+			}
+			if got != tc.expectedErr {
+				t.Errorf("expected error %q, got %q", tc.expectedErr, got)
+			}
+		})
+	}
+}
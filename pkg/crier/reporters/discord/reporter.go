@@ -0,0 +1,127 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discord
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"text/template"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/crier/reporters/criercommonlib"
+	discordclient "sigs.k8s.io/prow/pkg/discord"
+)
+
+const (
+	reporterName = "discordreporter"
+)
+
+// embedColor returns the color of the embed of a job in the given state.
+// Jobs that ended without failing but didn't succeed, e.g. aborted ones,
+// are gray.
+func embedColor(state prowapi.ProwJobState) int {
+	switch {
+	case state == prowapi.SuccessState:
+		return 0x2EB886
+	case criercommonlib.IsTerminalNonFailure(state):
+		return 0x808080
+	case state == prowapi.FailureState || state == prowapi.ErrorState:
+		return 0xA30200
+	default:
+		return 0xDAA038
+	}
+}
+
+type discordClient interface {
+	WriteMessage(ctx context.Context, msg *discordclient.Message, channel string) error
+}
+
+type discordReporter struct {
+	client discordClient
+	config func(*prowapi.Refs) config.DiscordReporter
+	dryRun bool
+}
+
+func (dr *discordReporter) getConfig(pj *prowapi.ProwJob) config.DiscordReporter {
+	refs := pj.Spec.Refs
+	if refs == nil && len(pj.Spec.ExtraRefs) > 0 {
+		refs = &pj.Spec.ExtraRefs[0]
+	}
+	return dr.config(refs)
+}
+
+func (dr *discordReporter) Report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) ([]*prowapi.ProwJob, *reconcile.Result, error) {
+	return []*prowapi.ProwJob{pj}, nil, dr.report(ctx, log, pj)
+}
+
+func (dr *discordReporter) report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) error {
+	cfg := dr.getConfig(pj)
+
+	b := &bytes.Buffer{}
+	tmpl, err := template.New("").Parse(cfg.ReportTemplate)
+	if err != nil {
+		log.WithError(err).Error("failed to parse template")
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+	if err := tmpl.Execute(b, pj); err != nil {
+		log.WithError(err).Error("failed to execute report template")
+		return fmt.Errorf("failed to execute report template: %w", err)
+	}
+
+	msg := discordclient.NewMessage(b.String(), discordclient.Embed{
+		Title: fmt.Sprintf("%s: %s", pj.Spec.Job, pj.Status.State),
+		URL:   pj.Status.URL,
+		Color: embedColor(pj.Status.State),
+	})
+	if dr.dryRun {
+		payload, _ := json.Marshal(msg)
+		log.WithField("message", string(payload)).Debug("Skipping reporting because dry-run is enabled")
+		return nil
+	}
+	if err := dr.client.WriteMessage(ctx, msg, cfg.Channel); err != nil {
+		log.WithError(err).Error("failed to write Discord message")
+		return fmt.Errorf("failed to write Discord message: %w", err)
+	}
+	return nil
+}
+
+func (dr *discordReporter) GetName() string {
+	return reporterName
+}
+
+func (dr *discordReporter) ShouldReport(_ context.Context, logger *logrus.Entry, pj *prowapi.ProwJob) bool {
+	cfg := dr.getConfig(pj)
+
+	shouldReport := criercommonlib.ShouldReportJob(pj, cfg.JobTypesToReport, cfg.JobStatesToReport)
+	logger.WithField("reporting", shouldReport).Debug("Determined should report")
+	return shouldReport
+}
+
+func New(cfg func(refs *prowapi.Refs) config.DiscordReporter, dryRun bool, webhooksGenerator func() []byte, httpClient *http.Client) *discordReporter {
+	return &discordReporter{
+		client: discordclient.NewClient(webhooksGenerator, httpClient),
+		config: cfg,
+		dryRun: dryRun,
+	}
+}
@@ -0,0 +1,232 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discord
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+
+	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	discordclient "sigs.k8s.io/prow/pkg/discord"
+)
+
+func TestShouldReport(t *testing.T) {
+	testCases := []struct {
+		name     string
+		config   config.DiscordReporter
+		pj       *v1.ProwJob
+		expected bool
+	}{
+		{
+			name: "matching type and state should report",
+			config: config.DiscordReporter{
+				JobTypesToReport:  []v1.ProwJobType{v1.PostsubmitJob},
+				JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+			},
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PostsubmitJob},
+				Status: v1.ProwJobStatus{State: v1.FailureState},
+			},
+			expected: true,
+		},
+		{
+			name: "wrong job type should not report",
+			config: config.DiscordReporter{
+				JobTypesToReport:  []v1.ProwJobType{v1.PostsubmitJob},
+				JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+			},
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PresubmitJob},
+				Status: v1.ProwJobStatus{State: v1.FailureState},
+			},
+			expected: false,
+		},
+		{
+			name: "wrong state should not report",
+			config: config.DiscordReporter{
+				JobTypesToReport:  []v1.ProwJobType{v1.PostsubmitJob},
+				JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+			},
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PostsubmitJob},
+				Status: v1.ProwJobStatus{State: v1.SuccessState},
+			},
+			expected: false,
+		},
+		{
+			name: "empty config should not report",
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PostsubmitJob},
+				Status: v1.ProwJobStatus{State: v1.SuccessState},
+			},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reporter := &discordReporter{
+				config: func(*v1.Refs) config.DiscordReporter { return tc.config },
+			}
+			if result := reporter.ShouldReport(context.Background(), logrus.NewEntry(logrus.StandardLogger()), tc.pj); result != tc.expected {
+				t.Errorf("expected result to be %t but was %t", tc.expected, result)
+			}
+		})
+	}
+}
+
+type fakeDiscordClient struct {
+	messages map[string]*discordclient.Message
+}
+
+func (fdc *fakeDiscordClient) WriteMessage(_ context.Context, msg *discordclient.Message, channel string) error {
+	if fdc.messages == nil {
+		fdc.messages = map[string]*discordclient.Message{}
+	}
+	fdc.messages[channel] = msg
+	return nil
+}
+
+var _ discordClient = &fakeDiscordClient{}
+
+func TestReport(t *testing.T) {
+	testCases := []struct {
+		name     string
+		pj       *v1.ProwJob
+		template string
+		dryRun   bool
+		expected map[string]*discordclient.Message
+	}{
+		{
+			name: "failed job is reported with red embed and link",
+			pj: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Job:       "my-job",
+					Type:      v1.PeriodicJob,
+					ExtraRefs: []v1.Refs{{Org: "org"}},
+				},
+				Status: v1.ProwJobStatus{
+					State: v1.FailureState,
+					URL:   "https://prow.k8s.io/view/my-job/1",
+				},
+			},
+			template: "{{.Spec.Job}} ended with {{.Status.State}}",
+			expected: map[string]*discordclient.Message{
+				"oncall": {
+					Content: "my-job ended with failure",
+					Embeds: []discordclient.Embed{{
+						Title: "my-job: failure",
+						URL:   "https://prow.k8s.io/view/my-job/1",
+						Color: 0xA30200,
+					}},
+				},
+			},
+		},
+		{
+			name: "long body is truncated",
+			pj: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Job:  "my-job",
+					Type: v1.PeriodicJob,
+					Refs: &v1.Refs{Org: "org"},
+				},
+				Status: v1.ProwJobStatus{
+					State: v1.SuccessState,
+				},
+			},
+			template: strings.Repeat("a", discordclient.MaxContentLength+100),
+			expected: map[string]*discordclient.Message{
+				"oncall": {
+					Content: strings.Repeat("a", discordclient.MaxContentLength-1) + "…",
+					Embeds: []discordclient.Embed{{
+						Title: "my-job: success",
+						Color: 0x2EB886,
+					}},
+				},
+			},
+		},
+		{
+			name: "aborted job is reported with gray embed",
+			pj: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Job:  "my-job",
+					Type: v1.PeriodicJob,
+					Refs: &v1.Refs{Org: "org"},
+				},
+				Status: v1.ProwJobStatus{
+					State: v1.AbortedState,
+				},
+			},
+			template: "{{.Spec.Job}} ended with {{.Status.State}}",
+			expected: map[string]*discordclient.Message{
+				"oncall": {
+					Content: "my-job ended with aborted",
+					Embeds: []discordclient.Embed{{
+						Title: "my-job: aborted",
+						Color: 0x808080,
+					}},
+				},
+			},
+		},
+		{
+			name:   "dry-run does not send",
+			dryRun: true,
+			pj: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Job:  "my-job",
+					Type: v1.PeriodicJob,
+					Refs: &v1.Refs{Org: "org"},
+				},
+				Status: v1.ProwJobStatus{
+					State: v1.SuccessState,
+				},
+			},
+			template: "{{.Spec.Job}} ended with {{.Status.State}}",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fdc := &fakeDiscordClient{}
+			reporter := &discordReporter{
+				client: fdc,
+				config: func(r *v1.Refs) config.DiscordReporter {
+					if r != nil && r.Org == "org" {
+						return config.DiscordReporter{
+							Channel:        "oncall",
+							ReportTemplate: tc.template,
+						}
+					}
+					return config.DiscordReporter{}
+				},
+				dryRun: tc.dryRun,
+			}
+
+			if _, _, err := reporter.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), tc.pj); err != nil {
+				t.Fatalf("reporting failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, fdc.messages); diff != "" {
+				t.Errorf("messages differ from expected: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package email
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	htmltemplate "html/template"
+	"text/template"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/crier/reporters/criercommonlib"
+	emailclient "sigs.k8s.io/prow/pkg/email"
+)
+
+const (
+	reporterName = "emailreporter"
+	// connectionFailureRequeueAfter is how long crier waits before trying
+	// to report a job again if the SMTP server could not be reached.
+	connectionFailureRequeueAfter = time.Minute
+)
+
+type emailClient interface {
+	Send(ctx context.Context, msg *emailclient.Message) error
+	From() string
+}
+
+type emailReporter struct {
+	client emailClient
+	config func(*prowapi.Refs) config.EmailReporter
+	dryRun bool
+}
+
+func (er *emailReporter) getConfig(pj *prowapi.ProwJob) config.EmailReporter {
+	refs := pj.Spec.Refs
+	if refs == nil && len(pj.Spec.ExtraRefs) > 0 {
+		refs = &pj.Spec.ExtraRefs[0]
+	}
+	return er.config(refs)
+}
+
+func (er *emailReporter) Report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) ([]*prowapi.ProwJob, *reconcile.Result, error) {
+	if err := er.report(ctx, log, pj); err != nil {
+		if emailclient.IsConnectionError(err) {
+			return nil, &reconcile.Result{RequeueAfter: connectionFailureRequeueAfter}, err
+		}
+		return nil, nil, err
+	}
+	return []*prowapi.ProwJob{pj}, nil, nil
+}
+
+func (er *emailReporter) report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) error {
+	cfg := er.getConfig(pj)
+
+	subject := &bytes.Buffer{}
+	subjectTmpl, err := template.New("").Parse(cfg.SubjectTemplate)
+	if err != nil {
+		log.WithError(err).Error("failed to parse subject template")
+		return fmt.Errorf("failed to parse subject template: %w", err)
+	}
+	if err := subjectTmpl.Execute(subject, pj); err != nil {
+		log.WithError(err).Error("failed to execute subject template")
+		return fmt.Errorf("failed to execute subject template: %w", err)
+	}
+
+	body := &bytes.Buffer{}
+	reportTmpl, err := htmltemplate.New("").Parse(cfg.ReportTemplate)
+	if err != nil {
+		log.WithError(err).Error("failed to parse template")
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+	if err := reportTmpl.Execute(body, pj); err != nil {
+		log.WithError(err).Error("failed to execute report template")
+		return fmt.Errorf("failed to execute report template: %w", err)
+	}
+
+	msg := &emailclient.Message{
+		To:       cfg.Recipients,
+		Subject:  subject.String(),
+		HTMLBody: body.String(),
+	}
+	if er.dryRun {
+		log.WithField("email", string(msg.Bytes(er.client.From()))).Debug("Skipping reporting because dry-run is enabled")
+		return nil
+	}
+	if err := er.client.Send(ctx, msg); err != nil {
+		log.WithError(err).Error("failed to send email")
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+func (er *emailReporter) GetName() string {
+	return reporterName
+}
+
+func (er *emailReporter) ShouldReport(_ context.Context, logger *logrus.Entry, pj *prowapi.ProwJob) bool {
+	cfg := er.getConfig(pj)
+
+	shouldReport := criercommonlib.ShouldReportJob(pj, cfg.JobTypesToReport, cfg.JobStatesToReport)
+	logger.WithField("reporting", shouldReport).Debug("Determined should report")
+	return shouldReport
+}
+
+func New(cfg func(refs *prowapi.Refs) config.EmailReporter, dryRun bool, serverOpts emailclient.ServerOptions, credentialsGenerator func() []byte) *emailReporter {
+	return &emailReporter{
+		client: emailclient.NewClient(serverOpts, credentialsGenerator),
+		config: cfg,
+		dryRun: dryRun,
+	}
+}
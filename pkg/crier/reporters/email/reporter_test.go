@@ -0,0 +1,195 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package email
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	emailclient "sigs.k8s.io/prow/pkg/email"
+)
+
+func TestShouldReport(t *testing.T) {
+	testCases := []struct {
+		name     string
+		config   config.EmailReporter
+		pj       *v1.ProwJob
+		expected bool
+	}{
+		{
+			name: "matching type and state should report",
+			config: config.EmailReporter{
+				JobTypesToReport:  []v1.ProwJobType{v1.PostsubmitJob},
+				JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+			},
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PostsubmitJob},
+				Status: v1.ProwJobStatus{State: v1.FailureState},
+			},
+			expected: true,
+		},
+		{
+			name: "wrong job type should not report",
+			config: config.EmailReporter{
+				JobTypesToReport:  []v1.ProwJobType{v1.PostsubmitJob},
+				JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+			},
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PresubmitJob},
+				Status: v1.ProwJobStatus{State: v1.FailureState},
+			},
+			expected: false,
+		},
+		{
+			name: "wrong state should not report",
+			config: config.EmailReporter{
+				JobTypesToReport:  []v1.ProwJobType{v1.PostsubmitJob},
+				JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+			},
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PostsubmitJob},
+				Status: v1.ProwJobStatus{State: v1.SuccessState},
+			},
+			expected: false,
+		},
+		{
+			name: "empty config should not report",
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PostsubmitJob},
+				Status: v1.ProwJobStatus{State: v1.SuccessState},
+			},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reporter := &emailReporter{
+				config: func(*v1.Refs) config.EmailReporter { return tc.config },
+			}
+			if result := reporter.ShouldReport(context.Background(), logrus.NewEntry(logrus.StandardLogger()), tc.pj); result != tc.expected {
+				t.Errorf("expected result to be %t but was %t", tc.expected, result)
+			}
+		})
+	}
+}
+
+type fakeEmailClient struct {
+	sent []*emailclient.Message
+	err  error
+}
+
+func (fec *fakeEmailClient) Send(_ context.Context, msg *emailclient.Message) error {
+	if fec.err != nil {
+		return fec.err
+	}
+	fec.sent = append(fec.sent, msg)
+	return nil
+}
+
+func (fec *fakeEmailClient) From() string {
+	return "prow@example.com"
+}
+
+var _ emailClient = &fakeEmailClient{}
+
+func TestReport(t *testing.T) {
+	pj := &v1.ProwJob{
+		Spec: v1.ProwJobSpec{
+			Job:  "my-job",
+			Type: v1.PeriodicJob,
+			Refs: &v1.Refs{Org: "org"},
+		},
+		Status: v1.ProwJobStatus{
+			State: v1.FailureState,
+			URL:   "https://prow.k8s.io/view/my-job/1?a=b&c=d",
+		},
+	}
+	testCases := []struct {
+		name          string
+		dryRun        bool
+		sendErr       error
+		expected      []*emailclient.Message
+		expectResult  *reconcile.Result
+		expectErr     bool
+		expectReports bool
+	}{
+		{
+			name: "email is rendered and sent",
+			expected: []*emailclient.Message{{
+				To:       []string{"team@example.com"},
+				Subject:  "my-job: failure",
+				HTMLBody: `<a href="https://prow.k8s.io/view/my-job/1?a=b&amp;c=d">my-job</a>`,
+			}},
+			expectReports: true,
+		},
+		{
+			name:          "dry-run does not send",
+			dryRun:        true,
+			expectReports: true,
+		},
+		{
+			name:         "connection failures are requeued",
+			sendErr:      &emailclient.ConnectionError{},
+			expectResult: &reconcile.Result{RequeueAfter: connectionFailureRequeueAfter},
+			expectErr:    true,
+		},
+		{
+			name:      "other failures are returned",
+			sendErr:   errors.New("recipient rejected"),
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fec := &fakeEmailClient{err: tc.sendErr}
+			reporter := &emailReporter{
+				client: fec,
+				config: func(*v1.Refs) config.EmailReporter {
+					return config.EmailReporter{
+						Recipients:      []string{"team@example.com"},
+						SubjectTemplate: "{{.Spec.Job}}: {{.Status.State}}",
+						ReportTemplate:  `<a href="{{.Status.URL}}">{{.Spec.Job}}</a>`,
+					}
+				},
+				dryRun: tc.dryRun,
+			}
+
+			pjs, result, err := reporter.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj)
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("expected error: %t, got: %v", tc.expectErr, err)
+			}
+			if diff := cmp.Diff(tc.expectResult, result); diff != "" {
+				t.Errorf("result differs from expected: %s", diff)
+			}
+			if (len(pjs) > 0) != tc.expectReports {
+				t.Errorf("expected reported jobs: %t, got %v", tc.expectReports, pjs)
+			}
+			if diff := cmp.Diff(tc.expected, fec.sent); diff != "" {
+				t.Errorf("sent emails differ from expected: %s", diff)
+			}
+		})
+	}
+}
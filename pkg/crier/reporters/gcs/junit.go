@@ -0,0 +1,162 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	stdio "io"
+	"path"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/testgrid/metadata/junit"
+	"github.com/sirupsen/logrus"
+	"k8s.io/utils/ptr"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/crier/reporters/gcs/util"
+	"sigs.k8s.io/prow/pkg/io"
+	"sigs.k8s.io/prow/pkg/io/providers"
+)
+
+// SummaryFile is the name of the file the aggregated JUnit results of a job
+// are written to.
+const SummaryFile = "summary.json"
+
+// JUnitSummary holds the aggregated results of the JUnit files of a job.
+type JUnitSummary struct {
+	// Files is the number of JUnit files that were aggregated.
+	Files   int `json:"files"`
+	Tests   int `json:"tests"`
+	Passed  int `json:"passed"`
+	Failed  int `json:"failed"`
+	Errored int `json:"errored"`
+	Skipped int `json:"skipped"`
+}
+
+func (s *JUnitSummary) addSuites(suites []junit.Suite) {
+	for _, suite := range suites {
+		s.addSuites(suite.Suites)
+		for _, result := range suite.Results {
+			s.Tests++
+			switch {
+			case result.Failure != nil:
+				s.Failed++
+			case result.Errored != nil:
+				s.Errored++
+			case result.Skipped != nil:
+				s.Skipped++
+			default:
+				s.Passed++
+			}
+		}
+	}
+}
+
+// reportJUnitSummary writes a summary of the JUnit files matching the
+// configured glob next to finished.json. Nothing is written if no glob is
+// configured or no JUnit files exist.
+func (gr *gcsReporter) reportJUnitSummary(ctx context.Context, log *logrus.Entry, pj *prowv1.ProwJob) error {
+	glob := gr.cfg().Crier.JUnitSummaryGlob
+	if glob == "" || !pj.Complete() {
+		return nil
+	}
+
+	// The artifacts are uploaded by the pod utilities, which don't honour
+	// crier's GCS path template.
+	artifactsBucket, artifactsDir, err := util.GetJobDestination(gr.cfg, pj)
+	if err != nil {
+		return fmt.Errorf("failed to get job destination: %w", err)
+	}
+	summary, err := gr.summarizeJUnit(ctx, log, artifactsBucket, artifactsDir, glob)
+	if err != nil {
+		return err
+	}
+	if summary.Files == 0 {
+		log.WithField("glob", glob).Debug("No JUnit files found, not writing a summary")
+		return nil
+	}
+
+	bucketName, dir, err := gr.jobDestination(pj)
+	if err != nil {
+		return fmt.Errorf("failed to get job destination: %w", err)
+	}
+	if gr.dryRun {
+		log.WithFields(logrus.Fields{"bucketName": bucketName, "dir": dir, "summary": summary}).Debug("Would upload JUnit summary")
+		return nil
+	}
+	output, err := json.MarshalIndent(summary, "", "\t")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JUnit summary: %w", err)
+	}
+	summaryPath, err := providers.StoragePath(bucketName, path.Join(dir, SummaryFile))
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s path: %w", SummaryFile, err)
+	}
+	objectOpts, err := gr.objectOptions(pj)
+	if err != nil {
+		return err
+	}
+	return gr.upload(ctx, log, summaryPath, output, append(objectOpts, io.WriterOptions{PreconditionDoesNotExist: ptr.To(false)})...)
+}
+
+// summarizeJUnit aggregates the results of the JUnit files below dir whose
+// path relative to dir matches glob. Files that can't be read or parsed are
+// skipped.
+func (gr *gcsReporter) summarizeJUnit(ctx context.Context, log *logrus.Entry, bucketName, dir, glob string) (*JUnitSummary, error) {
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+	prefixPath, err := providers.StoragePath(bucketName, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s path: %w", prefix, err)
+	}
+	it, err := gr.opener.Iterator(ctx, prefixPath, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", prefixPath, err)
+	}
+
+	summary := &JUnitSummary{}
+	for {
+		attrs, err := it.Next(ctx)
+		if err == stdio.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", prefixPath, err)
+		}
+		if matched, _ := path.Match(glob, strings.TrimPrefix(attrs.Name, prefix)); !matched {
+			continue
+		}
+		filePath, err := providers.StoragePath(bucketName, attrs.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s path: %w", attrs.Name, err)
+		}
+		content, err := io.ReadContent(ctx, log, gr.opener, filePath)
+		if err != nil {
+			log.WithError(err).WithField("path", filePath).Warn("Failed to read JUnit file, skipping it")
+			continue
+		}
+		suites, err := junit.Parse(content)
+		if err != nil {
+			log.WithError(err).WithField("path", filePath).Warn("Failed to parse JUnit file, skipping it")
+			continue
+		}
+		summary.Files++
+		summary.addSuites(suites.Suites)
+	}
+	return summary, nil
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stdio "io"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/io"
+	"sigs.k8s.io/prow/pkg/io/fakeopener"
+)
+
+// iterableOpener lists the files of the fake opener, which are all in the
+// gs://kubernetes-jenkins bucket.
+type iterableOpener struct {
+	*fakeopener.FakeOpener
+}
+
+func (o *iterableOpener) Iterator(_ context.Context, prefix, _ string) (io.ObjectIterator, error) {
+	var names []string
+	for p := range o.Buffer {
+		if strings.HasPrefix(p, prefix) {
+			names = append(names, strings.TrimPrefix(p, "gs://kubernetes-jenkins/"))
+		}
+	}
+	sort.Strings(names)
+	return &listIterator{names: names}, nil
+}
+
+type listIterator struct {
+	names []string
+}
+
+func (it *listIterator) Next(_ context.Context) (io.ObjectAttributes, error) {
+	if len(it.names) == 0 {
+		return io.ObjectAttributes{}, stdio.EOF
+	}
+	name := it.names[0]
+	it.names = it.names[1:]
+	return io.ObjectAttributes{Name: name}, nil
+}
+
+const (
+	junitPassedAndFailed = `<testsuite name="a">
+	<testcase name="passed"></testcase>
+	<testcase name="failed"><failure message="boom"></failure></testcase>
+</testsuite>`
+	junitNested = `<testsuites>
+	<testsuite name="b">
+		<testsuite name="c">
+			<testcase name="skipped"><skipped></skipped></testcase>
+		</testsuite>
+		<testcase name="errored"><error message="oops"></error></testcase>
+		<testcase name="passed"></testcase>
+	</testsuite>
+</testsuites>`
+)
+
+func TestReportJUnitSummary(t *testing.T) {
+	const dir = "gs://kubernetes-jenkins/logs/my-little-job/123/"
+	testCases := []struct {
+		name     string
+		glob     string
+		files    map[string]string
+		expected *JUnitSummary
+	}{
+		{
+			name: "results of matching files are aggregated",
+			glob: "artifacts/junit*.xml",
+			files: map[string]string{
+				"artifacts/junit_01.xml":     junitPassedAndFailed,
+				"artifacts/junit_02.xml":     junitNested,
+				"artifacts/other.xml":        junitPassedAndFailed,
+				"artifacts/nested/junit.xml": junitPassedAndFailed,
+			},
+			expected: &JUnitSummary{Files: 2, Tests: 5, Passed: 2, Failed: 1, Errored: 1, Skipped: 1},
+		},
+		{
+			name: "invalid files are skipped",
+			glob: "artifacts/junit*.xml",
+			files: map[string]string{
+				"artifacts/junit_01.xml": junitPassedAndFailed,
+				"artifacts/junit_02.xml": "<testsuite><testcase>",
+			},
+			expected: &JUnitSummary{Files: 1, Tests: 2, Passed: 1, Failed: 1},
+		},
+		{
+			name: "no summary without JUnit files",
+			glob: "artifacts/junit*.xml",
+			files: map[string]string{
+				"artifacts/other.xml": junitPassedAndFailed,
+			},
+		},
+		{
+			name: "no summary without glob",
+			files: map[string]string{
+				"artifacts/junit_01.xml": junitPassedAndFailed,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := fca{c: config.Config{
+				ProwConfig: config.ProwConfig{
+					Crier: config.Crier{JUnitSummaryGlob: tc.glob},
+					Plank: config.Plank{
+						DefaultDecorationConfigs: config.DefaultDecorationMapToSliceTesting(
+							map[string]*prowv1.DecorationConfig{"*": {
+								GCSConfiguration: &prowv1.GCSConfiguration{
+									Bucket:       "kubernetes-jenkins",
+									PathStrategy: prowv1.PathStrategyExplicit,
+								},
+							}}),
+					},
+				},
+			}}.Config
+			opener := &iterableOpener{FakeOpener: &fakeopener.FakeOpener{Buffer: map[string]*bytes.Buffer{}}}
+			for name, content := range tc.files {
+				opener.Buffer[dir+name] = bytes.NewBufferString(content)
+			}
+			reporter := New(cfg, opener, false, 0)
+			pj := &prowv1.ProwJob{
+				Spec: prowv1.ProwJobSpec{
+					Type:  prowv1.PeriodicJob,
+					Agent: prowv1.KubernetesAgent,
+					Job:   "my-little-job",
+				},
+				Status: prowv1.ProwJobStatus{
+					State:          prowv1.SuccessState,
+					BuildID:        "123",
+					CompletionTime: &metav1.Time{Time: time.Now()},
+				},
+			}
+
+			if err := reporter.reportJUnitSummary(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var summary *JUnitSummary
+			if b, ok := opener.Buffer[dir+SummaryFile]; ok {
+				summary = &JUnitSummary{}
+				if err := json.Unmarshal(b.Bytes(), summary); err != nil {
+					t.Fatalf("Couldn't unmarshal %s: %v", SummaryFile, err)
+				}
+			}
+			if diff := cmp.Diff(tc.expected, summary); diff != "" {
+				t.Errorf("summary differs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -25,12 +25,14 @@ import (
 	"math"
 	"path"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes/scheme"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -46,12 +48,28 @@ import (
 	"sigs.k8s.io/prow/pkg/io/providers"
 )
 
+// SkipPodReportAnnotation is the ProwJob annotation that, when set to
+// "true", keeps the pod info of the job from being uploaded regardless of
+// the report fraction.
+const SkipPodReportAnnotation = "prow.k8s.io/skip-pod-report"
+
 type gcsK8sReporter struct {
 	cfg            config.Getter
 	dryRun         bool
 	opener         io.Opener
 	rg             resourceGetter
 	reportFraction float32
+	upload         UploadOptions
+}
+
+// UploadOptions configure what the reporter uploads for a job and how.
+type UploadOptions struct {
+	// Concurrency is the number of artifacts of a job that are uploaded in
+	// parallel. Values below 1 upload them one after another.
+	Concurrency int
+	// ContainerLogs additionally uploads the logs of all containers of the
+	// pod to podlogs/<container>.txt.
+	ContainerLogs bool
 }
 
 type PodReport struct {
@@ -62,6 +80,7 @@ type PodReport struct {
 type resourceGetter interface {
 	GetPod(ctx context.Context, cluster, namespace, name string) (*v1.Pod, error)
 	GetEvents(cluster, namespace string, pod *v1.Pod) ([]v1.Event, error)
+	GetLogs(ctx context.Context, cluster, namespace, name, container string) ([]byte, error)
 	PatchPod(ctx context.Context, cluster, namespace, name string, pt types.PatchType, data []byte) error
 }
 
@@ -89,6 +108,13 @@ func (rg k8sResourceGetter) PatchPod(ctx context.Context, cluster, namespace, na
 	return err
 }
 
+func (rg k8sResourceGetter) GetLogs(ctx context.Context, cluster, namespace, name, container string) ([]byte, error) {
+	if _, ok := rg.podClientSets[cluster]; !ok {
+		return nil, fmt.Errorf("couldn't find cluster %q", cluster)
+	}
+	return rg.podClientSets[cluster].Pods(namespace).GetLogs(name, &v1.PodLogOptions{Container: container}).DoRaw(ctx)
+}
+
 func (rg k8sResourceGetter) GetEvents(cluster, namespace string, pod *v1.Pod) ([]v1.Event, error) {
 	if _, ok := rg.podClientSets[cluster]; !ok {
 		return nil, fmt.Errorf("couldn't find cluster %q", cluster)
@@ -216,12 +242,15 @@ func (gr *gcsK8sReporter) reportPodInfo(ctx context.Context, log *logrus.Entry,
 		return nil
 	}
 
-	overWriteOpts := io.WriterOptions{PreconditionDoesNotExist: ptr.To(false)}
-	podInfoPath, err := providers.StoragePath(bucketName, path.Join(dir, "podinfo.json"))
-	if err != nil {
-		return fmt.Errorf("failed to resolve podinfo.json path: %v", err)
+	artifacts := []artifact{{
+		name:     "podinfo.json",
+		content:  func(context.Context) ([]byte, error) { return output, nil },
+		required: true,
+	}}
+	if gr.upload.ContainerLogs && pod != nil {
+		artifacts = append(artifacts, gr.containerLogs(pj.Spec.Cluster, pod)...)
 	}
-	if err := io.WriteContent(ctx, log, gr.opener, podInfoPath, output, overWriteOpts); err != nil {
+	if err := gr.uploadArtifacts(ctx, log, pj, bucketName, dir, artifacts); err != nil {
 		return fmt.Errorf("failed to upload pod manifest to object storage: %w", err)
 	}
 
@@ -236,6 +265,85 @@ func (gr *gcsK8sReporter) reportPodInfo(ctx context.Context, log *logrus.Entry,
 	return nil
 }
 
+// artifact is a file uploaded for a job. The report only fails if a required
+// artifact can't be uploaded.
+type artifact struct {
+	name     string
+	content  func(ctx context.Context) ([]byte, error)
+	required bool
+}
+
+// containerLogs returns an artifact for the logs of every container of the
+// pod.
+func (gr *gcsK8sReporter) containerLogs(cluster string, pod *v1.Pod) []artifact {
+	var artifacts []artifact
+	for _, containers := range [][]v1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for _, container := range containers {
+			container := container.Name
+			artifacts = append(artifacts, artifact{
+				name: path.Join("podlogs", container+".txt"),
+				content: func(ctx context.Context) ([]byte, error) {
+					return gr.rg.GetLogs(ctx, cluster, pod.Namespace, pod.Name, container)
+				},
+			})
+		}
+	}
+	return artifacts
+}
+
+// uploadArtifacts uploads the artifacts to dir with up to
+// UploadOptions.Concurrency uploads in parallel. Failed uploads of artifacts
+// that aren't required are only logged.
+func (gr *gcsK8sReporter) uploadArtifacts(ctx context.Context, log *logrus.Entry, pj *prowv1.ProwJob, bucketName, dir string, artifacts []artifact) error {
+	errs := make([]error, len(artifacts))
+	sem := make(chan struct{}, max(gr.upload.Concurrency, 1))
+	var wg sync.WaitGroup
+	for i := range artifacts {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = gr.uploadArtifact(ctx, log, pj, bucketName, dir, artifacts[i])
+		}(i)
+	}
+	wg.Wait()
+
+	var requiredErrs []error
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		if artifacts[i].required {
+			requiredErrs = append(requiredErrs, err)
+		} else {
+			log.WithError(err).WithField("artifact", artifacts[i].name).Warn("Failed to upload optional artifact")
+		}
+	}
+	return utilerrors.NewAggregate(requiredErrs)
+}
+
+func (gr *gcsK8sReporter) uploadArtifact(ctx context.Context, log *logrus.Entry, pj *prowv1.ProwJob, bucketName, dir string, a artifact) error {
+	content, err := a.content(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %w", a.name, err)
+	}
+	content, opts, err := util.CompressContent(gr.cfg, pj, a.name, content)
+	if err != nil {
+		return err
+	}
+	opts = append([]io.WriterOptions{{ContentType: ptr.To(util.ContentType(a.name, gr.cfg().Crier.GCSContentTypes))}}, opts...)
+	opts = append(opts, io.WriterOptions{PreconditionDoesNotExist: ptr.To(false)})
+	artifactPath, err := providers.StoragePath(bucketName, path.Join(dir, a.name))
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s path: %w", a.name, err)
+	}
+	if err := io.WriteContent(ctx, log, gr.opener, artifactPath, content, opts...); err != nil {
+		return fmt.Errorf("failed to upload %s: %w", a.name, err)
+	}
+	return nil
+}
+
 func (gr *gcsK8sReporter) removeFinalizer(ctx context.Context, cluster string, pod *v1.Pod) error {
 	finalizers := sets.New[string](pod.Finalizers...)
 	if !finalizers.Has(kubernetesreporterapi.FinalizerName) {
@@ -269,6 +377,10 @@ func (gr *gcsK8sReporter) ShouldReport(_ context.Context, _ *logrus.Entry, pj *p
 		return false
 	}
 
+	if pj.Annotations[SkipPodReportAnnotation] == "true" {
+		return false
+	}
+
 	// For ramp-up purposes, we can report only on a subset of jobs.
 	if gr.reportFraction < 1.0 {
 		// Assume the names are opaque and take the CRC-32C checksum of it.
@@ -282,12 +394,13 @@ func (gr *gcsK8sReporter) ShouldReport(_ context.Context, _ *logrus.Entry, pj *p
 	return true
 }
 
-func New(cfg config.Getter, opener io.Opener, rg resourceGetter, reportFraction float32, dryRun bool) *gcsK8sReporter {
+func New(cfg config.Getter, opener io.Opener, rg resourceGetter, reportFraction float32, upload UploadOptions, dryRun bool) *gcsK8sReporter {
 	return &gcsK8sReporter{
 		cfg:            cfg,
 		dryRun:         dryRun,
 		opener:         opener,
 		rg:             rg,
 		reportFraction: reportFraction,
+		upload:         upload,
 	}
 }
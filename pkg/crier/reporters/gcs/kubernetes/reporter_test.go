@@ -22,6 +22,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
 	"testing"
 	"time"
 
@@ -33,7 +37,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/prow/pkg/config"
 
+	pkgio "sigs.k8s.io/prow/pkg/io"
 	"sigs.k8s.io/prow/pkg/io/fakeopener"
+	"sigs.k8s.io/prow/pkg/io/fakes3"
 
 	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
 )
@@ -53,6 +59,7 @@ func TestShouldReport(t *testing.T) {
 		isComplete            bool
 		hasNoPendingTimestamp bool
 		hasBuildID            bool
+		skipAnnotation        string
 		shouldReport          bool
 	}{
 		{
@@ -98,6 +105,22 @@ func TestShouldReport(t *testing.T) {
 			hasBuildID:   false,
 			shouldReport: false,
 		},
+		{
+			name:           "jobs annotated to skip the pod report are not reported",
+			agent:          prowv1.KubernetesAgent,
+			isComplete:     true,
+			hasBuildID:     true,
+			skipAnnotation: "true",
+			shouldReport:   false,
+		},
+		{
+			name:           "jobs with the skip annotation not set to true are reported",
+			agent:          prowv1.KubernetesAgent,
+			isComplete:     true,
+			hasBuildID:     true,
+			skipAnnotation: "false",
+			shouldReport:   true,
+		},
 	}
 
 	for _, tc := range tests {
@@ -111,6 +134,9 @@ func TestShouldReport(t *testing.T) {
 					StartTime: metav1.Time{Time: time.Now()},
 				},
 			}
+			if tc.skipAnnotation != "" {
+				pj.Annotations = map[string]string{SkipPodReportAnnotation: tc.skipAnnotation}
+			}
 			if tc.isComplete {
 				pj.Status.State = prowv1.SuccessState
 				pj.Status.CompletionTime = &metav1.Time{Time: time.Now()}
@@ -122,7 +148,7 @@ func TestShouldReport(t *testing.T) {
 				pj.Status.PendingTime = &metav1.Time{}
 			}
 
-			kgr := New(fca{}.Config, nil, nil, 1.0, false)
+			kgr := New(fca{}.Config, nil, nil, 1.0, UploadOptions{}, false)
 			shouldReport := kgr.ShouldReport(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj)
 			if shouldReport != tc.shouldReport {
 				t.Errorf("Expected ShouldReport() to return %v, but got %v", tc.shouldReport, shouldReport)
@@ -139,6 +165,8 @@ type testResourceGetter struct {
 	patchData string
 	patchType types.PatchType
 	patchErr  error
+	// logs are the logs of the pod's containers by name.
+	logs map[string]string
 }
 
 func (rg testResourceGetter) GetPod(_ context.Context, cluster, namespace, name string) (*v1.Pod, error) {
@@ -173,6 +201,17 @@ func (rg testResourceGetter) GetEvents(cluster, namespace string, pod *v1.Pod) (
 	return rg.events, nil
 }
 
+func (rg testResourceGetter) GetLogs(_ context.Context, cluster, namespace, name, container string) ([]byte, error) {
+	if _, err := rg.GetPod(context.Background(), cluster, namespace, name); err != nil {
+		return nil, err
+	}
+	logs, ok := rg.logs[container]
+	if !ok {
+		return nil, fmt.Errorf("no logs for container %q", container)
+	}
+	return []byte(logs), nil
+}
+
 func (rg testResourceGetter) PatchPod(ctx context.Context, cluster, namespace, name string, pt types.PatchType, data []byte) error {
 	if rg.patchErr != nil {
 		return rg.patchErr
@@ -404,7 +443,7 @@ func TestReportPodInfo(t *testing.T) {
 				patchType: types.MergePatchType,
 			}
 			fakeOpener := &fakeopener.FakeOpener{}
-			reporter := New(fca.Config, fakeOpener, rg, 1.0, tc.dryRun)
+			reporter := New(fca.Config, fakeOpener, rg, 1.0, UploadOptions{}, tc.dryRun)
 			reconcileResult, err := reporter.report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj)
 
 			if tc.expectErr {
@@ -452,3 +491,216 @@ func TestReportPodInfo(t *testing.T) {
 		})
 	}
 }
+
+// syncOpener makes the fake opener safe for concurrent uploads and
+// optionally slows them down.
+type syncOpener struct {
+	lock sync.Mutex
+	*fakeopener.FakeOpener
+	latency time.Duration
+}
+
+func (so *syncOpener) Writer(ctx context.Context, path string, opts ...pkgio.WriterOptions) (pkgio.WriteCloser, error) {
+	time.Sleep(so.latency)
+	so.lock.Lock()
+	defer so.lock.Unlock()
+	return so.FakeOpener.Writer(ctx, path, opts...)
+}
+
+func testPodWithContainers(n int) *v1.Pod {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ba123965-4fd4-421f-8509-7590c129ab69",
+			Namespace: "test-pods",
+		},
+	}
+	for i := 0; i < n; i++ {
+		pod.Spec.Containers = append(pod.Spec.Containers, v1.Container{Name: fmt.Sprintf("container-%d", i)})
+	}
+	return pod
+}
+
+func TestReportPodInfoContainerLogs(t *testing.T) {
+	tests := []struct {
+		name          string
+		logs          map[string]string
+		writeErr      error
+		expectErr     bool
+		expectedFiles []string
+	}{
+		{
+			name: "logs of all containers are uploaded",
+			logs: map[string]string{"container-0": "zero", "container-1": "one"},
+			expectedFiles: []string{
+				"gs://kubernetes-jenkins/some-prefix/logs/12345/podinfo.json",
+				"gs://kubernetes-jenkins/some-prefix/logs/12345/podlogs/container-0.txt",
+				"gs://kubernetes-jenkins/some-prefix/logs/12345/podlogs/container-1.txt",
+			},
+		},
+		{
+			name: "missing logs of a container don't fail the report",
+			logs: map[string]string{"container-1": "one"},
+			expectedFiles: []string{
+				"gs://kubernetes-jenkins/some-prefix/logs/12345/podinfo.json",
+				"gs://kubernetes-jenkins/some-prefix/logs/12345/podlogs/container-1.txt",
+			},
+		},
+		{
+			name:      "failing to upload podinfo.json fails the report",
+			logs:      map[string]string{"container-0": "zero", "container-1": "one"},
+			writeErr:  errors.New("injected error"),
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pj := &prowv1.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "ba123965-4fd4-421f-8509-7590c129ab69"},
+				Spec: prowv1.ProwJobSpec{
+					Agent:   prowv1.KubernetesAgent,
+					Cluster: "the-build-cluster",
+					Type:    prowv1.PeriodicJob,
+				},
+				Status: prowv1.ProwJobStatus{
+					State:          prowv1.SuccessState,
+					StartTime:      metav1.Time{Time: time.Now()},
+					CompletionTime: &metav1.Time{Time: time.Now()},
+					BuildID:        "12345",
+				},
+			}
+			fca := fca{c: config.Config{ProwConfig: config.ProwConfig{
+				PodNamespace: "test-pods",
+				Plank: config.Plank{
+					DefaultDecorationConfigs: config.DefaultDecorationMapToSliceTesting(
+						map[string]*prowv1.DecorationConfig{"*": {
+							GCSConfiguration: &prowv1.GCSConfiguration{
+								Bucket:       "kubernetes-jenkins",
+								PathPrefix:   "some-prefix",
+								PathStrategy: prowv1.PathStrategyLegacy,
+								DefaultOrg:   "kubernetes",
+								DefaultRepo:  "kubernetes",
+							},
+						}}),
+				},
+			}}}
+			rg := testResourceGetter{
+				namespace: "test-pods",
+				cluster:   "the-build-cluster",
+				pod:       testPodWithContainers(2),
+				logs:      tc.logs,
+			}
+			opener := &syncOpener{FakeOpener: &fakeopener.FakeOpener{WriteError: tc.writeErr}}
+			reporter := New(fca.Config, opener, rg, 1.0, UploadOptions{Concurrency: 2, ContainerLogs: true}, false)
+
+			err := reporter.reportPodInfo(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj)
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("expected error: %t, got: %v", tc.expectErr, err)
+			}
+			var files []string
+			for path := range opener.Buffer {
+				files = append(files, path)
+			}
+			sort.Strings(files)
+			if diff := cmp.Diff(tc.expectedFiles, files); diff != "" {
+				t.Errorf("uploaded files differ (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReportPodInfoToS3(t *testing.T) {
+	ctx := context.Background()
+	s3 := fakes3.NewServer()
+	defer s3.Close()
+	credentialsFile := filepath.Join(t.TempDir(), "s3-credentials.json")
+	if err := os.WriteFile(credentialsFile, s3.Credentials(), 0600); err != nil {
+		t.Fatalf("Failed to write credentials: %v", err)
+	}
+	opener, err := pkgio.NewOpener(ctx, "", credentialsFile)
+	if err != nil {
+		t.Fatalf("Failed to create opener: %v", err)
+	}
+	pj := &prowv1.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "ba123965-4fd4-421f-8509-7590c129ab69"},
+		Spec: prowv1.ProwJobSpec{
+			Agent:   prowv1.KubernetesAgent,
+			Cluster: "the-build-cluster",
+			Type:    prowv1.PeriodicJob,
+			Job:     "my-little-job",
+		},
+		Status: prowv1.ProwJobStatus{
+			State:          prowv1.SuccessState,
+			StartTime:      metav1.Time{Time: time.Now()},
+			CompletionTime: &metav1.Time{Time: time.Now()},
+			BuildID:        "12345",
+		},
+	}
+	fca := fca{c: config.Config{ProwConfig: config.ProwConfig{
+		PodNamespace: "test-pods",
+		Plank: config.Plank{
+			DefaultDecorationConfigs: config.DefaultDecorationMapToSliceTesting(
+				map[string]*prowv1.DecorationConfig{"*": {
+					GCSConfiguration: &prowv1.GCSConfiguration{
+						Bucket:       "s3://prow-artifacts",
+						PathStrategy: prowv1.PathStrategyExplicit,
+					},
+				}}),
+		},
+	}}}
+	rg := testResourceGetter{
+		namespace: "test-pods",
+		cluster:   "the-build-cluster",
+		pod:       testPodWithContainers(1),
+		logs:      map[string]string{"container-0": "zero"},
+	}
+	reporter := New(fca.Config, opener, rg, 1.0, UploadOptions{Concurrency: 2, ContainerLogs: true}, false)
+
+	if err := reporter.reportPodInfo(ctx, logrus.NewEntry(logrus.StandardLogger()), pj); err != nil {
+		t.Fatalf("Failed to report pod info: %v", err)
+	}
+
+	expected := map[string]string{
+		"logs/my-little-job/12345/podinfo.json":            "application/json",
+		"logs/my-little-job/12345/podlogs/container-0.txt": "text/plain; charset=utf-8",
+	}
+	actual := map[string]string{}
+	for _, key := range s3.Keys("prow-artifacts") {
+		object, _ := s3.Object("prow-artifacts", key)
+		actual[key] = object.ContentType
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("uploaded objects and their content types differ (-want +got):\n%s", diff)
+	}
+	if logs, _ := s3.Object("prow-artifacts", "logs/my-little-job/12345/podlogs/container-0.txt"); string(logs.Content) != "zero" {
+		t.Errorf("expected the logs of container-0 to be uploaded, got %q", logs.Content)
+	}
+}
+
+// BenchmarkUploadArtifacts uploads the logs of a pod with many containers to
+// an object storage with some latency per upload, which shows how the wall
+// clock time goes down with the upload concurrency.
+func BenchmarkUploadArtifacts(b *testing.B) {
+	const containers = 16
+	pod := testPodWithContainers(containers)
+	logs := map[string]string{}
+	for _, container := range pod.Spec.Containers {
+		logs[container.Name] = "some log output"
+	}
+	rg := testResourceGetter{namespace: "test-pods", cluster: "the-build-cluster", pod: pod, logs: logs}
+	pj := &prowv1.ProwJob{}
+	log := logrus.NewEntry(logrus.StandardLogger())
+
+	for _, concurrency := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("concurrency-%d", concurrency), func(b *testing.B) {
+			reporter := New(fca{}.Config, nil, rg, 1.0, UploadOptions{Concurrency: concurrency, ContainerLogs: true}, false)
+			artifacts := reporter.containerLogs("the-build-cluster", pod)
+			for i := 0; i < b.N; i++ {
+				reporter.opener = &syncOpener{FakeOpener: &fakeopener.FakeOpener{}, latency: time.Millisecond}
+				if err := reporter.uploadArtifacts(context.Background(), log, pj, "gs://bucket", "logs", artifacts); err != nil {
+					b.Fatalf("failed to upload artifacts: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -20,12 +20,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	stdio "io"
 	"path"
 	"time"
 
 	"github.com/GoogleCloudPlatform/testgrid/metadata"
 	"github.com/sirupsen/logrus"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -44,21 +46,43 @@ type gcsReporter struct {
 	cfg    config.Getter
 	dryRun bool
 	opener io.Opener
+
+	uploadRetries   int
+	uploadRetryBase time.Duration
 }
 
 func (gr *gcsReporter) Report(ctx context.Context, log *logrus.Entry, pj *prowv1.ProwJob) ([]*prowv1.ProwJob, *reconcile.Result, error) {
 	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
 	defer cancel()
 
-	_, _, err := util.GetJobDestination(gr.cfg, pj)
+	_, _, err := gr.jobDestination(pj)
 	if err != nil {
 		log.WithError(err).Info("Not uploading prowjob because we couldn't find a destination")
 		return []*prowv1.ProwJob{pj}, nil, nil
 	}
 	stateErr := gr.reportJobState(ctx, log, pj)
 	prowjobErr := gr.reportProwjob(ctx, log, pj)
+	summaryErr := gr.reportJUnitSummary(ctx, log, pj)
+
+	return []*prowv1.ProwJob{pj}, nil, utilerrors.NewAggregate([]error{stateErr, prowjobErr, summaryErr})
+}
 
-	return []*prowv1.ProwJob{pj}, nil, utilerrors.NewAggregate([]error{stateErr, prowjobErr})
+// jobDestination returns where the job's metadata is uploaded to. The
+// directory honours crier's GCS path template when one is configured.
+func (gr *gcsReporter) jobDestination(pj *prowv1.ProwJob) (bucket, dir string, err error) {
+	bucket, dir, err = util.GetJobDestination(gr.cfg, pj)
+	if err != nil {
+		return "", "", err
+	}
+	crierCfg := gr.cfg().Crier
+	custom, err := crierCfg.GCSPath(pj)
+	if err != nil {
+		return "", "", err
+	}
+	if custom != "" {
+		dir = custom
+	}
+	return bucket, dir, nil
 }
 
 func (gr *gcsReporter) reportJobState(ctx context.Context, log *logrus.Entry, pj *prowv1.ProwJob) error {
@@ -74,7 +98,7 @@ func (gr *gcsReporter) reportJobState(ctx context.Context, log *logrus.Entry, pj
 // happen before the pod itself gets to upload one, at which point the pod will
 // upload its own. If for some reason one already exists, it will not be overwritten.
 func (gr *gcsReporter) reportStartedJob(ctx context.Context, log *logrus.Entry, pj *prowv1.ProwJob) error {
-	bucketName, dir, err := util.GetJobDestination(gr.cfg, pj)
+	bucketName, dir, err := gr.jobDestination(pj)
 	if err != nil {
 		return fmt.Errorf("failed to get job destination: %w", err)
 	}
@@ -155,7 +179,11 @@ func (gr *gcsReporter) reportStartedJob(ctx context.Context, log *logrus.Entry,
 	// Add a new var for better readability.
 	overwrite := existing
 	overwriteOpt := io.WriterOptions{PreconditionDoesNotExist: ptr.To(!overwrite)}
-	return io.WriteContent(ctx, log, gr.opener, startedFilePath, output, overwriteOpt)
+	objectOpts, err := gr.objectOptions(pj)
+	if err != nil {
+		return err
+	}
+	return gr.upload(ctx, log, startedFilePath, output, append(objectOpts, overwriteOpt)...)
 }
 
 // reportFinishedJob uploads a finished.json for the job, iff one did not already exist.
@@ -165,7 +193,7 @@ func (gr *gcsReporter) reportFinishedJob(ctx context.Context, log *logrus.Entry,
 		return fmt.Errorf("failed to marshal finished metadata: %w", err)
 	}
 
-	bucketName, dir, err := util.GetJobDestination(gr.cfg, pj)
+	bucketName, dir, err := gr.jobDestination(pj)
 	if err != nil {
 		return fmt.Errorf("failed to get job destination: %w", err)
 	}
@@ -180,7 +208,11 @@ func (gr *gcsReporter) reportFinishedJob(ctx context.Context, log *logrus.Entry,
 	if err != nil {
 		return fmt.Errorf("failed to resolve finished.json path: %v", err)
 	}
-	return io.WriteContent(ctx, log, gr.opener, finishedFilePath, output, overwriteOpt)
+	objectOpts, err := gr.objectOptions(pj)
+	if err != nil {
+		return err
+	}
+	return gr.upload(ctx, log, finishedFilePath, output, append(objectOpts, overwriteOpt)...)
 }
 
 func (gr *gcsReporter) reportProwjob(ctx context.Context, log *logrus.Entry, pj *prowv1.ProwJob) error {
@@ -190,7 +222,7 @@ func (gr *gcsReporter) reportProwjob(ctx context.Context, log *logrus.Entry, pj
 		return fmt.Errorf("failed to marshal ProwJob: %w", err)
 	}
 
-	bucketName, dir, err := util.GetJobDestination(gr.cfg, pj)
+	bucketName, dir, err := gr.jobDestination(pj)
 	if err != nil {
 		return fmt.Errorf("failed to get job destination: %w", err)
 	}
@@ -199,12 +231,80 @@ func (gr *gcsReporter) reportProwjob(ctx context.Context, log *logrus.Entry, pj
 		log.WithFields(logrus.Fields{"bucketName": bucketName, "dir": dir}).Debug("Would upload pod info")
 		return nil
 	}
-	overWriteOpts := io.WriterOptions{PreconditionDoesNotExist: ptr.To(false)}
+	output, opts, err := util.CompressContent(gr.cfg, pj, prowv1.ProwJobFile, output)
+	if err != nil {
+		return err
+	}
+	objectOpts, err := gr.objectOptions(pj)
+	if err != nil {
+		return err
+	}
+	opts = append(append(opts, objectOpts...), io.WriterOptions{PreconditionDoesNotExist: ptr.To(false)})
 	prowJobFilePath, err := providers.StoragePath(bucketName, path.Join(dir, prowv1.ProwJobFile))
 	if err != nil {
 		return fmt.Errorf("failed to resolve prowjob.json path: %v", err)
 	}
-	return io.WriteContent(ctx, log, gr.opener, prowJobFilePath, output, overWriteOpts)
+	return gr.upload(ctx, log, prowJobFilePath, output, opts...)
+}
+
+// objectOptions returns the writer options setting the metadata and the ACL
+// configured for the objects uploaded for the job.
+func (gr *gcsReporter) objectOptions(pj *prowv1.ProwJob) ([]io.WriterOptions, error) {
+	crierCfg := gr.cfg().Crier
+	metadata, err := crierCfg.GCSObjectMetadataFor(pj)
+	if err != nil {
+		return nil, err
+	}
+	var opts io.WriterOptions
+	if metadata != nil {
+		opts.Metadata = metadata
+	}
+	if crierCfg.GCSPredefinedACL != "" {
+		opts.PredefinedACL = ptr.To(crierCfg.GCSPredefinedACL)
+	}
+	return []io.WriterOptions{opts}, nil
+}
+
+// CheckConnectivity lists every bucket of the default decoration configs to
+// check that they can be reached. Buckets that only jobs configure inline
+// aren't checked.
+func (gr *gcsReporter) CheckConnectivity(ctx context.Context) error {
+	seen := sets.New[string]()
+	for _, entry := range gr.cfg().Plank.DefaultDecorationConfigs {
+		if entry.Config == nil || entry.Config.GCSConfiguration == nil {
+			continue
+		}
+		bucket := entry.Config.GCSConfiguration.Bucket
+		if bucket == "" || seen.Has(bucket) {
+			continue
+		}
+		seen.Insert(bucket)
+		if err := gr.checkBucket(ctx, bucket); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate checks that every bucket of the default decoration configs can
+// be listed.
+func (gr *gcsReporter) Validate(ctx context.Context) error {
+	return gr.CheckConnectivity(ctx)
+}
+
+func (gr *gcsReporter) checkBucket(ctx context.Context, bucket string) error {
+	bucketPath, err := providers.StoragePath(bucket, "")
+	if err != nil {
+		return fmt.Errorf("invalid default bucket %q: %w", bucket, err)
+	}
+	it, err := gr.opener.Iterator(ctx, bucketPath, "/")
+	if err != nil {
+		return fmt.Errorf("failed to list %s: %w", bucketPath, err)
+	}
+	if _, err := it.Next(ctx); err != nil && err != stdio.EOF {
+		return fmt.Errorf("failed to list %s: %w", bucketPath, err)
+	}
+	return nil
 }
 
 func (gr *gcsReporter) GetName() string {
@@ -218,10 +318,15 @@ func (gr *gcsReporter) ShouldReport(_ context.Context, _ *logrus.Entry, pj *prow
 	return pj.Status.BuildID != ""
 }
 
-func New(cfg config.Getter, opener io.Opener, dryRun bool) *gcsReporter {
+// New returns a reporter that uploads the metadata of jobs to their storage
+// bucket. Uploads that fail with a transient error are retried up to
+// uploadRetries times.
+func New(cfg config.Getter, opener io.Opener, dryRun bool, uploadRetries int) *gcsReporter {
 	return &gcsReporter{
-		cfg:    cfg,
-		dryRun: dryRun,
-		opener: opener,
+		cfg:             cfg,
+		dryRun:          dryRun,
+		opener:          opener,
+		uploadRetries:   uploadRetries,
+		uploadRetryBase: uploadRetryBase,
 	}
 }
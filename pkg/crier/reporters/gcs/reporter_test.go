@@ -17,13 +17,17 @@ limitations under the License.
 package gcs
 
 import (
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
 	stdio "io"
+	"os"
 	"path"
+	"sort"
 	"strings"
 	"testing"
+	"text/template"
 	"time"
 
 	"github.com/GoogleCloudPlatform/testgrid/metadata"
@@ -35,6 +39,7 @@ import (
 	"sigs.k8s.io/prow/pkg/config"
 	"sigs.k8s.io/prow/pkg/io"
 	"sigs.k8s.io/prow/pkg/io/fakeopener"
+	"sigs.k8s.io/prow/pkg/io/fakes3"
 	"sigs.k8s.io/prow/pkg/io/providers"
 	"sigs.k8s.io/prow/pkg/pod-utils/clone"
 )
@@ -101,7 +106,7 @@ func TestReportJobFinished(t *testing.T) {
 				},
 			}}.Config
 			fakeOpener := &fakeopener.FakeOpener{}
-			reporter := New(cfg, fakeOpener, false)
+			reporter := New(cfg, fakeOpener, false, 0)
 
 			pj := &prowv1.ProwJob{
 				Spec: prowv1.ProwJobSpec{
@@ -391,7 +396,7 @@ func TestReportJobStarted(t *testing.T) {
 				}
 			}
 
-			reporter := New(cfg, opener, false)
+			reporter := New(cfg, opener, false, 0)
 
 			pj := &prowv1.ProwJob{
 				Spec: prowv1.ProwJobSpec{
@@ -454,7 +459,7 @@ func TestReportProwJob(t *testing.T) {
 		},
 	}}.Config
 	fakeOpener := &fakeopener.FakeOpener{}
-	reporter := New(cfg, fakeOpener, false)
+	reporter := New(cfg, fakeOpener, false, 0)
 
 	pj := &prowv1.ProwJob{
 		Spec: prowv1.ProwJobSpec{
@@ -501,6 +506,340 @@ func TestReportProwJob(t *testing.T) {
 	}
 }
 
+func TestReportProwJobCompressed(t *testing.T) {
+	ctx := context.Background()
+	cfg := fca{c: config.Config{
+		ProwConfig: config.ProwConfig{
+			Plank: config.Plank{
+				DefaultDecorationConfigs: config.DefaultDecorationMapToSliceTesting(
+					map[string]*prowv1.DecorationConfig{"*": {
+						GCSConfiguration: &prowv1.GCSConfiguration{
+							Bucket:            "kubernetes-jenkins",
+							PathStrategy:      prowv1.PathStrategyExplicit,
+							CompressFileTypes: []string{"json"},
+						},
+					}}),
+			},
+		},
+	}}.Config
+	fakeOpener := &fakeopener.FakeOpener{}
+	reporter := New(cfg, fakeOpener, false, 0)
+
+	pj := &prowv1.ProwJob{
+		Spec: prowv1.ProwJobSpec{
+			Type:  prowv1.PeriodicJob,
+			Agent: prowv1.KubernetesAgent,
+			Job:   "my-little-job",
+		},
+		Status: prowv1.ProwJobStatus{
+			State:       prowv1.SuccessState,
+			Description: strings.Repeat("Job succeeded. ", 100),
+			BuildID:     "123",
+		},
+	}
+
+	if err := reporter.reportProwjob(ctx, logrus.NewEntry(logrus.StandardLogger()), pj); err != nil {
+		t.Fatalf("Unexpected error calling reportProwjob: %v", err)
+	}
+
+	b, ok := fakeOpener.Buffer["gs://kubernetes-jenkins/logs/my-little-job/123/"+prowv1.ProwJobFile]
+	if !ok {
+		t.Fatalf("%s was not uploaded, got %v", prowv1.ProwJobFile, fakeOpener.Buffer)
+	}
+	zr, err := gzip.NewReader(b)
+	if err != nil {
+		t.Fatalf("%s is not gzipped: %v", prowv1.ProwJobFile, err)
+	}
+	var result prowv1.ProwJob
+	if err := json.NewDecoder(zr).Decode(&result); err != nil {
+		t.Fatalf("Couldn't decode %s: %v", prowv1.ProwJobFile, err)
+	}
+	if diff := cmp.Diff(*pj, result); diff != "" {
+		t.Errorf("Input prowjob mismatches output prowjob: %s", diff)
+	}
+}
+
+func TestReportProwJobCustomPath(t *testing.T) {
+	ctx := context.Background()
+	c := config.Config{
+		ProwConfig: config.ProwConfig{
+			Crier: config.Crier{GCSPathTemplateString: "custom/{{.Spec.Job}}/{{.Status.BuildID}}"},
+			Plank: config.Plank{
+				DefaultDecorationConfigs: config.DefaultDecorationMapToSliceTesting(
+					map[string]*prowv1.DecorationConfig{"*": {
+						GCSConfiguration: &prowv1.GCSConfiguration{
+							Bucket:       "kubernetes-jenkins",
+							PathPrefix:   "some-prefix",
+							PathStrategy: prowv1.PathStrategyLegacy,
+							DefaultOrg:   "kubernetes",
+							DefaultRepo:  "kubernetes",
+						},
+					}}),
+			},
+		},
+	}
+	tmpl, err := template.New("GCSPath").Parse(c.Crier.GCSPathTemplateString)
+	if err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+	c.Crier.GCSPathTemplate = tmpl
+	fakeOpener := &fakeopener.FakeOpener{}
+	reporter := New(fca{c: c}.Config, fakeOpener, false, 0)
+
+	pj := &prowv1.ProwJob{
+		Spec: prowv1.ProwJobSpec{
+			Type: prowv1.PeriodicJob,
+			Job:  "my-little-job",
+		},
+		Status: prowv1.ProwJobStatus{
+			State:   prowv1.PendingState,
+			BuildID: "123",
+		},
+	}
+
+	if err := reporter.reportProwjob(ctx, logrus.NewEntry(logrus.StandardLogger()), pj); err != nil {
+		t.Fatalf("Unexpected error calling reportProwjob: %v", err)
+	}
+
+	expected := "gs://kubernetes-jenkins/custom/my-little-job/123/" + prowv1.ProwJobFile
+	if _, ok := fakeOpener.Buffer[expected]; !ok {
+		var paths []string
+		for p := range fakeOpener.Buffer {
+			paths = append(paths, p)
+		}
+		t.Errorf("Expected %s to be written, got %v", expected, paths)
+	}
+}
+
+func TestReportProwJobBucketPerRepo(t *testing.T) {
+	ctx := context.Background()
+	cfg := fca{c: config.Config{
+		ProwConfig: config.ProwConfig{
+			Plank: config.Plank{
+				DefaultDecorationConfigs: config.DefaultDecorationMapToSliceTesting(
+					map[string]*prowv1.DecorationConfig{
+						"*": {
+							GCSConfiguration: &prowv1.GCSConfiguration{
+								Bucket:       "kubernetes-jenkins",
+								PathStrategy: prowv1.PathStrategyExplicit,
+							},
+						},
+						"team-a/repo": {GCSConfiguration: &prowv1.GCSConfiguration{Bucket: "team-a-bucket"}},
+						"team-b/repo": {GCSConfiguration: &prowv1.GCSConfiguration{Bucket: "gs://team-b-bucket"}},
+					}),
+			},
+		},
+	}}.Config
+	fakeOpener := &fakeopener.FakeOpener{}
+	reporter := New(cfg, fakeOpener, false, 0)
+
+	for _, org := range []string{"team-a", "team-b"} {
+		pj := &prowv1.ProwJob{
+			Spec: prowv1.ProwJobSpec{
+				Type:  prowv1.PostsubmitJob,
+				Refs:  &prowv1.Refs{Org: org, Repo: "repo", BaseRef: "main"},
+				Agent: prowv1.KubernetesAgent,
+				Job:   org + "-job",
+			},
+			Status: prowv1.ProwJobStatus{
+				State:   prowv1.PendingState,
+				BuildID: "123",
+			},
+		}
+		if err := reporter.reportProwjob(ctx, logrus.NewEntry(logrus.StandardLogger()), pj); err != nil {
+			t.Fatalf("Unexpected error calling reportProwjob for %s: %v", org, err)
+		}
+	}
+
+	var paths []string
+	for p := range fakeOpener.Buffer {
+		paths = append(paths, p)
+	}
+	expected := []string{
+		"gs://team-a-bucket/logs/team-a-job/123/" + prowv1.ProwJobFile,
+		"gs://team-b-bucket/logs/team-b-job/123/" + prowv1.ProwJobFile,
+	}
+	sort.Strings(paths)
+	if diff := cmp.Diff(expected, paths); diff != "" {
+		t.Errorf("uploaded paths differ (-want +got):\n%s", diff)
+	}
+}
+
+func TestReportToLocalDirectory(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	cfg := fca{c: config.Config{
+		ProwConfig: config.ProwConfig{
+			Plank: config.Plank{
+				DefaultDecorationConfigs: config.DefaultDecorationMapToSliceTesting(
+					map[string]*prowv1.DecorationConfig{"*": {
+						GCSConfiguration: &prowv1.GCSConfiguration{
+							Bucket:       "file://" + dir,
+							PathStrategy: prowv1.PathStrategyExplicit,
+						},
+					}}),
+			},
+		},
+	}}.Config
+	opener, err := io.NewOpener(ctx, "", "")
+	if err != nil {
+		t.Fatalf("Failed to create opener: %v", err)
+	}
+	reporter := New(cfg, opener, false, 0)
+
+	pj := &prowv1.ProwJob{
+		Spec: prowv1.ProwJobSpec{
+			Type: prowv1.PresubmitJob,
+			Refs: &prowv1.Refs{
+				Org:   "kubernetes",
+				Repo:  "test-infra",
+				Pulls: []prowv1.Pull{{Number: 12345, SHA: "abc"}},
+			},
+			Agent: prowv1.KubernetesAgent,
+			Job:   "my-little-job",
+		},
+		Status: prowv1.ProwJobStatus{
+			State:     prowv1.PendingState,
+			StartTime: metav1.Time{Time: time.Date(2010, 10, 10, 18, 30, 0, 0, time.UTC)},
+			PodName:   "some-pod",
+			BuildID:   "123",
+		},
+	}
+	if _, _, err := reporter.Report(ctx, logrus.NewEntry(logrus.StandardLogger()), pj); err != nil {
+		t.Fatalf("Failed to report pending job: %v", err)
+	}
+	pj.Status.State = prowv1.SuccessState
+	pj.Status.CompletionTime = &metav1.Time{Time: time.Date(2010, 10, 10, 19, 00, 0, 0, time.UTC)}
+	if _, _, err := reporter.Report(ctx, logrus.NewEntry(logrus.StandardLogger()), pj); err != nil {
+		t.Fatalf("Failed to report finished job: %v", err)
+	}
+
+	jobDir := path.Join(dir, "pr-logs", "pull", "kubernetes_test-infra", "12345", "my-little-job", "123")
+	for _, file := range []string{prowv1.StartedStatusFile, prowv1.FinishedStatusFile, prowv1.ProwJobFile} {
+		if _, err := os.Stat(path.Join(jobDir, file)); err != nil {
+			t.Errorf("Expected %s to be written: %v", file, err)
+		}
+	}
+	entries, err := os.ReadDir(jobDir)
+	if err != nil {
+		t.Fatalf("Failed to list %s: %v", jobDir, err)
+	}
+	if len(entries) != 3 {
+		t.Errorf("Expected only the three metadata files to be written, got %v", entries)
+	}
+	content, err := os.ReadFile(path.Join(jobDir, prowv1.ProwJobFile))
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", prowv1.ProwJobFile, err)
+	}
+	var result prowv1.ProwJob
+	if err := json.Unmarshal(content, &result); err != nil {
+		t.Fatalf("Couldn't unmarshal %s: %v", prowv1.ProwJobFile, err)
+	}
+	if diff := cmp.Diff(*pj, result); diff != "" {
+		t.Errorf("Written prowjob differs from reported prowjob:\n%s", diff)
+	}
+}
+
+func TestReportToS3(t *testing.T) {
+	ctx := context.Background()
+	s3 := fakes3.NewServer()
+	defer s3.Close()
+	credentialsFile := path.Join(t.TempDir(), "s3-credentials.json")
+	if err := os.WriteFile(credentialsFile, s3.Credentials(), 0600); err != nil {
+		t.Fatalf("Failed to write credentials: %v", err)
+	}
+	cfg := fca{c: config.Config{
+		ProwConfig: config.ProwConfig{
+			Crier: config.Crier{JUnitSummaryGlob: "artifacts/junit*.xml"},
+			Plank: config.Plank{
+				DefaultDecorationConfigs: config.DefaultDecorationMapToSliceTesting(
+					map[string]*prowv1.DecorationConfig{"*": {
+						GCSConfiguration: &prowv1.GCSConfiguration{
+							Bucket:            "s3://prow-artifacts",
+							PathStrategy:      prowv1.PathStrategyExplicit,
+							CompressFileTypes: []string{"*"},
+						},
+					}}),
+			},
+		},
+	}}.Config
+	opener, err := io.NewOpener(ctx, "", credentialsFile)
+	if err != nil {
+		t.Fatalf("Failed to create opener: %v", err)
+	}
+	reporter := New(cfg, opener, false, 0)
+
+	pj := &prowv1.ProwJob{
+		Spec: prowv1.ProwJobSpec{
+			Type: prowv1.PresubmitJob,
+			Refs: &prowv1.Refs{
+				Org:   "kubernetes",
+				Repo:  "test-infra",
+				Pulls: []prowv1.Pull{{Number: 12345, SHA: "abc"}},
+			},
+			Agent: prowv1.KubernetesAgent,
+			Job:   "my-little-job",
+		},
+		Status: prowv1.ProwJobStatus{
+			State:     prowv1.PendingState,
+			StartTime: metav1.Time{Time: time.Date(2010, 10, 10, 18, 30, 0, 0, time.UTC)},
+			PodName:   "some-pod",
+			BuildID:   "123",
+		},
+	}
+	jobDir := "pr-logs/pull/kubernetes_test-infra/12345/my-little-job/123/"
+	s3.Put("prow-artifacts", jobDir+"artifacts/junit_01.xml", []byte(junitPassedAndFailed))
+	if _, _, err := reporter.Report(ctx, logrus.NewEntry(logrus.StandardLogger()), pj); err != nil {
+		t.Fatalf("Failed to report pending job: %v", err)
+	}
+	// The pod utilities replace started.json once the job runs, which
+	// crier must not overwrite.
+	podStarted := []byte(`{"timestamp": 1286735400, "repo-commit": "abc"}`)
+	s3.Put("prow-artifacts", jobDir+prowv1.StartedStatusFile, podStarted)
+	pj.Status.State = prowv1.SuccessState
+	pj.Status.CompletionTime = &metav1.Time{Time: time.Date(2010, 10, 10, 19, 00, 0, 0, time.UTC)}
+	for i := 0; i < 2; i++ {
+		if _, _, err := reporter.Report(ctx, logrus.NewEntry(logrus.StandardLogger()), pj); err != nil {
+			t.Fatalf("Failed to report finished job: %v", err)
+		}
+	}
+
+	expectedKeys := []string{
+		jobDir + "artifacts/junit_01.xml",
+		jobDir + prowv1.FinishedStatusFile,
+		jobDir + prowv1.ProwJobFile,
+		jobDir + prowv1.StartedStatusFile,
+		jobDir + SummaryFile,
+	}
+	if diff := cmp.Diff(expectedKeys, s3.Keys("prow-artifacts")); diff != "" {
+		t.Errorf("Uploaded objects differ (-want +got):\n%s", diff)
+	}
+	for _, file := range []string{SummaryFile, prowv1.FinishedStatusFile, prowv1.ProwJobFile} {
+		object, _ := s3.Object("prow-artifacts", jobDir+file)
+		if object.ContentType != "application/json" {
+			t.Errorf("Expected %s to be uploaded as application/json, got %q", file, object.ContentType)
+		}
+		if object.ContentEncoding != "" {
+			t.Errorf("Expected %s not to be compressed, got content encoding %q", file, object.ContentEncoding)
+		}
+	}
+	if started, _ := s3.Object("prow-artifacts", jobDir+prowv1.StartedStatusFile); string(started.Content) != string(podStarted) {
+		t.Errorf("Expected %s of the pod to be kept, got %s", prowv1.StartedStatusFile, started.Content)
+	}
+	prowJob, _ := s3.Object("prow-artifacts", jobDir+prowv1.ProwJobFile)
+	var result prowv1.ProwJob
+	if err := json.Unmarshal(prowJob.Content, &result); err != nil {
+		t.Fatalf("Couldn't unmarshal %s: %v", prowv1.ProwJobFile, err)
+	}
+	if diff := cmp.Diff(*pj, result); diff != "" {
+		t.Errorf("Written prowjob differs from reported prowjob:\n%s", diff)
+	}
+	summary, _ := s3.Object("prow-artifacts", jobDir+SummaryFile)
+	if !strings.Contains(string(summary.Content), `"files": 1`) {
+		t.Errorf("Expected the summary to count the JUnit file, got %s", summary.Content)
+	}
+}
+
 func TestShouldReport(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -532,7 +871,7 @@ func TestShouldReport(t *testing.T) {
 					BuildID:   tc.buildID,
 				},
 			}
-			gr := New(fca{}.Config, nil, false)
+			gr := New(fca{}.Config, nil, false, 0)
 			result := gr.ShouldReport(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj)
 			if result != tc.shouldReport {
 				t.Errorf("Got ShouldReport() returned %v, but expected %v", result, tc.shouldReport)
@@ -540,3 +879,178 @@ func TestShouldReport(t *testing.T) {
 		})
 	}
 }
+
+type listingOpener struct {
+	io.Opener
+	listed []string
+	err    error
+}
+
+func (lo *listingOpener) Iterator(_ context.Context, prefix, _ string) (io.ObjectIterator, error) {
+	lo.listed = append(lo.listed, prefix)
+	return emptyIterator{err: lo.err}, nil
+}
+
+type emptyIterator struct {
+	err error
+}
+
+func (it emptyIterator) Next(_ context.Context) (io.ObjectAttributes, error) {
+	if it.err != nil {
+		return io.ObjectAttributes{}, it.err
+	}
+	return io.ObjectAttributes{}, stdio.EOF
+}
+
+func TestCheckConnectivity(t *testing.T) {
+	testCases := []struct {
+		name           string
+		bucket         string
+		repoBuckets    map[string]string
+		listErr        error
+		expectedListed []string
+		expectedErr    bool
+	}{
+		{
+			name:           "default bucket is listed",
+			bucket:         "kubernetes-jenkins",
+			expectedListed: []string{"gs://kubernetes-jenkins/"},
+		},
+		{
+			name:           "unreachable default bucket fails",
+			bucket:         "gs://kubernetes-jenkins",
+			listErr:        fmt.Errorf("permission denied"),
+			expectedListed: []string{"gs://kubernetes-jenkins/"},
+			expectedErr:    true,
+		},
+		{
+			name: "no default bucket is not checked",
+		},
+		{
+			name:           "buckets of repos are listed once",
+			bucket:         "kubernetes-jenkins",
+			repoBuckets:    map[string]string{"team-a/repo": "team-bucket", "team-b/repo": "team-bucket"},
+			expectedListed: []string{"gs://kubernetes-jenkins/", "gs://team-bucket/"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			decorationConfigs := map[string]*prowv1.DecorationConfig{"*": {
+				GCSConfiguration: &prowv1.GCSConfiguration{
+					Bucket: tc.bucket,
+				},
+			}}
+			for repo, bucket := range tc.repoBuckets {
+				decorationConfigs[repo] = &prowv1.DecorationConfig{GCSConfiguration: &prowv1.GCSConfiguration{Bucket: bucket}}
+			}
+			cfg := fca{c: config.Config{
+				ProwConfig: config.ProwConfig{
+					Plank: config.Plank{
+						DefaultDecorationConfigs: config.DefaultDecorationMapToSliceTesting(decorationConfigs),
+					},
+				},
+			}}.Config
+			opener := &listingOpener{err: tc.listErr}
+			reporter := New(cfg, opener, false, 0)
+
+			err := reporter.CheckConnectivity(context.Background())
+			if (err != nil) != tc.expectedErr {
+				t.Errorf("expected error: %t, got: %v", tc.expectedErr, err)
+			}
+			if diff := cmp.Diff(tc.expectedListed, opener.listed); diff != "" {
+				t.Errorf("listed paths differ (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// optionsRecordingOpener records the options objects were written with.
+type optionsRecordingOpener struct {
+	*fakeopener.FakeOpener
+	options map[string]io.WriterOptions
+}
+
+func (o *optionsRecordingOpener) Writer(ctx context.Context, path string, opts ...io.WriterOptions) (io.WriteCloser, error) {
+	var merged io.WriterOptions
+	for _, opt := range opts {
+		opt.Apply(&merged)
+	}
+	o.options[path] = merged
+	return o.FakeOpener.Writer(ctx, path, opts...)
+}
+
+func TestReportObjectMetadataAndACL(t *testing.T) {
+	ctx := context.Background()
+	cfg := fca{c: config.Config{
+		ProwConfig: config.ProwConfig{
+			Plank: config.Plank{
+				DefaultDecorationConfigs: config.DefaultDecorationMapToSliceTesting(
+					map[string]*prowv1.DecorationConfig{"*": {
+						GCSConfiguration: &prowv1.GCSConfiguration{
+							Bucket:       "kubernetes-jenkins",
+							PathStrategy: prowv1.PathStrategyExplicit,
+						},
+					}}),
+			},
+			Crier: config.Crier{
+				GCSObjectMetadataTemplates: map[string]*template.Template{
+					"build-id": template.Must(template.New("build-id").Parse("{{.Status.BuildID}}")),
+					"job-type": template.Must(template.New("job-type").Parse("{{.Spec.Type}}")),
+				},
+				GCSPredefinedACL: "publicRead",
+				GCSRetention: &config.GCSRetention{
+					MetadataKey:    "retention-class",
+					AllowedClasses: []string{"short", "long"},
+					Rules:          []config.GCSRetentionRule{{JobTypes: []prowv1.ProwJobType{prowv1.PresubmitJob}, Class: "short"}},
+					DefaultClass:   "long",
+				},
+			},
+		},
+	}}.Config
+	opener := &optionsRecordingOpener{FakeOpener: &fakeopener.FakeOpener{}, options: map[string]io.WriterOptions{}}
+	reporter := New(cfg, opener, false, 0)
+
+	pj := &prowv1.ProwJob{
+		Spec: prowv1.ProwJobSpec{
+			Type: prowv1.PresubmitJob,
+			Refs: &prowv1.Refs{
+				Org:   "kubernetes",
+				Repo:  "test-infra",
+				Pulls: []prowv1.Pull{{Number: 12345}},
+			},
+			Agent: prowv1.KubernetesAgent,
+			Job:   "my-little-job",
+		},
+		Status: prowv1.ProwJobStatus{
+			State:          prowv1.SuccessState,
+			StartTime:      metav1.Time{Time: time.Date(2010, 10, 10, 18, 30, 0, 0, time.UTC)},
+			CompletionTime: &metav1.Time{Time: time.Date(2010, 10, 10, 19, 00, 0, 0, time.UTC)},
+			PodName:        "some-pod",
+			BuildID:        "123",
+		},
+	}
+
+	if _, _, err := reporter.Report(ctx, logrus.NewEntry(logrus.StandardLogger()), pj); err != nil {
+		t.Fatalf("Unexpected error calling Report: %v", err)
+	}
+
+	expectedMetadata := map[string]string{"build-id": "123", "job-type": "presubmit", "retention-class": "short"}
+	for _, file := range []string{prowv1.StartedStatusFile, prowv1.FinishedStatusFile, prowv1.ProwJobFile} {
+		var found bool
+		for p, opts := range opener.options {
+			if !strings.HasSuffix(p, "/"+file) {
+				continue
+			}
+			found = true
+			if diff := cmp.Diff(expectedMetadata, opts.Metadata); diff != "" {
+				t.Errorf("unexpected metadata of %s (-want +got):\n%s", file, diff)
+			}
+			if opts.PredefinedACL == nil || *opts.PredefinedACL != "publicRead" {
+				t.Errorf("expected predefined ACL publicRead on %s, got %v", file, opts.PredefinedACL)
+			}
+		}
+		if !found {
+			t.Errorf("expected %s to be written", file)
+		}
+	}
+}
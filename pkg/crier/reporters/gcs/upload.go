@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcs
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gocloud.dev/gcerrors"
+	"google.golang.org/api/googleapi"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/prow/pkg/crier/reporters/gcs/util"
+	"sigs.k8s.io/prow/pkg/io"
+)
+
+const (
+	// DefaultUploadRetries is how often an upload that failed with a
+	// transient error is retried by default.
+	DefaultUploadRetries = 3
+	// uploadRetryBase is the delay before the first retry of an upload,
+	// doubled with every further retry.
+	uploadRetryBase = 500 * time.Millisecond
+)
+
+// upload writes the content to the path, retrying uploads that failed with
+// a transient error with an exponential backoff. Before every retry, the
+// checksum of the object, if it exists, is compared with the content, so
+// that an upload that went through despite reporting an error isn't
+// written again. The content type is set from the extension of the path,
+// honouring crier's GCS content type overrides, unless opts set one.
+func (gr *gcsReporter) upload(ctx context.Context, log *logrus.Entry, path string, content []byte, opts ...io.WriterOptions) error {
+	opts = append([]io.WriterOptions{{ContentType: ptr.To(util.ContentType(path, gr.cfg().Crier.GCSContentTypes))}}, opts...)
+	delay := gr.uploadRetryBase
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && gr.uploaded(ctx, path, content) {
+			log.WithField("path", path).Debug("Object was uploaded by a failed attempt, skipping retry")
+			return nil
+		}
+		err := io.WriteContent(ctx, log, gr.opener, path, content, opts...)
+		if err == nil || attempt >= gr.uploadRetries || !isTransient(err) {
+			return err
+		}
+		log.WithError(err).WithField("path", path).WithField("attempt", attempt+1).Info("Upload failed, retrying")
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// uploaded returns whether the object at the path exists with the content.
+func (gr *gcsReporter) uploaded(ctx context.Context, path string, content []byte) bool {
+	attrs, err := gr.opener.Attributes(ctx, path)
+	if err != nil || attrs.MD5 == nil {
+		return false
+	}
+	sum := md5.Sum(content)
+	return bytes.Equal(attrs.MD5, sum[:])
+}
+
+// isTransient returns whether the upload that failed with the error may
+// succeed when retried.
+func isTransient(err error) bool {
+	var agg utilerrors.Aggregate
+	if errors.As(err, &agg) {
+		for _, err := range agg.Errors() {
+			if isTransient(err) {
+				return true
+			}
+		}
+		return false
+	}
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == http.StatusRequestTimeout || apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= 500
+	}
+	switch gcerrors.Code(err) {
+	case gcerrors.ResourceExhausted, gcerrors.Internal:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcs
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/api/googleapi"
+
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/io"
+	"sigs.k8s.io/prow/pkg/io/fakeopener"
+)
+
+// flakyOpener fails the first failWrites writes before writing anything,
+// and the first failCloses writes after writing the content, with
+// errorCode.
+type flakyOpener struct {
+	*fakeopener.FakeOpener
+	errorCode  int
+	failWrites int
+	failCloses int
+	writes     int
+}
+
+type failingCloser struct {
+	io.WriteCloser
+	err error
+}
+
+func (f *failingCloser) Close() error {
+	if err := f.WriteCloser.Close(); err != nil {
+		return err
+	}
+	return f.err
+}
+
+func (f *flakyOpener) Writer(ctx context.Context, path string, opts ...io.WriterOptions) (io.WriteCloser, error) {
+	f.writes++
+	if f.failWrites > 0 {
+		f.failWrites--
+		return nil, &googleapi.Error{Code: f.errorCode}
+	}
+	w, err := f.FakeOpener.Writer(ctx, path, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if f.failCloses > 0 {
+		f.failCloses--
+		return &failingCloser{WriteCloser: w, err: &googleapi.Error{Code: f.errorCode}}, nil
+	}
+	return w, nil
+}
+
+func TestUpload(t *testing.T) {
+	const path = "gs://bucket/logs/job/1/prowjob.json"
+	testCases := []struct {
+		name           string
+		retries        int
+		errorCode      int
+		failWrites     int
+		failCloses     int
+		expectErr      bool
+		expectedWrites int
+	}{
+		{
+			name:           "transient failure followed by success",
+			retries:        3,
+			errorCode:      http.StatusServiceUnavailable,
+			failWrites:     1,
+			expectedWrites: 2,
+		},
+		{
+			name:           "upload that went through despite failing isn't retried",
+			retries:        3,
+			errorCode:      http.StatusServiceUnavailable,
+			failCloses:     1,
+			expectedWrites: 1,
+		},
+		{
+			name:           "retries exhausted",
+			retries:        2,
+			errorCode:      http.StatusServiceUnavailable,
+			failWrites:     5,
+			expectErr:      true,
+			expectedWrites: 3,
+		},
+		{
+			name:           "permanent failure isn't retried",
+			retries:        3,
+			errorCode:      http.StatusForbidden,
+			failWrites:     1,
+			expectErr:      true,
+			expectedWrites: 1,
+		},
+		{
+			name:           "retries disabled",
+			errorCode:      http.StatusServiceUnavailable,
+			failWrites:     1,
+			expectErr:      true,
+			expectedWrites: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opener := &flakyOpener{
+				FakeOpener: &fakeopener.FakeOpener{},
+				errorCode:  tc.errorCode,
+				failWrites: tc.failWrites,
+				failCloses: tc.failCloses,
+			}
+			gr := New(fca{}.Config, opener, false, tc.retries)
+			gr.uploadRetryBase = time.Millisecond
+
+			err := gr.upload(context.Background(), logrus.WithField("test", tc.name), path, []byte("content"))
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("expected error: %t, got %v", tc.expectErr, err)
+			}
+			if opener.writes != tc.expectedWrites {
+				t.Errorf("expected %d writes, got %d", tc.expectedWrites, opener.writes)
+			}
+			if !tc.expectErr {
+				if content := opener.Buffer[path].String(); content != "content" {
+					t.Errorf("expected the object to have the content, got %q", content)
+				}
+			}
+		})
+	}
+}
+
+// recordingOpener records the content type objects are written with.
+type recordingOpener struct {
+	*fakeopener.FakeOpener
+	contentTypes map[string]string
+}
+
+func (r *recordingOpener) Writer(ctx context.Context, path string, opts ...io.WriterOptions) (io.WriteCloser, error) {
+	var options io.WriterOptions
+	for _, opt := range opts {
+		opt.Apply(&options)
+	}
+	if options.ContentType != nil {
+		r.contentTypes[path] = *options.ContentType
+	}
+	return r.FakeOpener.Writer(ctx, path, opts...)
+}
+
+func TestUploadContentType(t *testing.T) {
+	testCases := []struct {
+		name      string
+		path      string
+		overrides map[string]string
+		expected  string
+	}{
+		{
+			name:     "json artifact",
+			path:     "gs://bucket/logs/job/1/artifacts/result.json",
+			expected: "application/json",
+		},
+		{
+			name:     "artifact without extension",
+			path:     "gs://bucket/logs/job/1/artifacts/result",
+			expected: "text/plain; charset=utf-8",
+		},
+		{
+			name:      "overridden extension",
+			path:      "gs://bucket/logs/job/1/artifacts/result.json",
+			overrides: map[string]string{"json": "text/plain; charset=utf-8"},
+			expected:  "text/plain; charset=utf-8",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opener := &recordingOpener{FakeOpener: &fakeopener.FakeOpener{}, contentTypes: map[string]string{}}
+			cfg := fca{c: config.Config{ProwConfig: config.ProwConfig{Crier: config.Crier{GCSContentTypes: tc.overrides}}}}
+			gr := New(cfg.Config, opener, false, 0)
+
+			if err := gr.upload(context.Background(), logrus.WithField("test", tc.name), tc.path, []byte("{}")); err != nil {
+				t.Fatalf("upload failed: %v", err)
+			}
+			if actual := opener.contentTypes[tc.path]; actual != tc.expected {
+				t.Errorf("expected content type %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -17,18 +17,29 @@ limitations under the License.
 package util
 
 import (
+	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"mime"
+	"path"
 	"strings"
 
 	"github.com/GoogleCloudPlatform/testgrid/metadata"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/ptr"
 	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
 	"sigs.k8s.io/prow/pkg/config"
 	"sigs.k8s.io/prow/pkg/gcsupload"
+	"sigs.k8s.io/prow/pkg/io"
 	"sigs.k8s.io/prow/pkg/pod-utils/downwardapi"
 )
 
+// minCompressSize is the size below which content isn't compressed, as for
+// the artifacts uploaded by the sidecar.
+const minCompressSize = 1024
+
 func GetJobDestination(cfg config.Getter, pj *prowv1.ProwJob) (bucket, dir string, err error) {
 	// We can't divine a destination for jobs that don't have a build ID, so don't try.
 	gc, err := gcsConfig(cfg, pj)
@@ -58,14 +69,16 @@ func gcsConfig(cfg config.Getter, pj *prowv1.ProwJob) (*prowv1.GCSConfiguration,
 		return nil, errors.New("cannot get job destination for job with no BuildID")
 	}
 
-	if pj.Spec.DecorationConfig != nil && pj.Spec.DecorationConfig.GCSConfiguration != nil {
-		return pj.Spec.DecorationConfig.GCSConfiguration, nil
+	if dc := pj.Spec.DecorationConfig; dc != nil && dc.GCSConfiguration != nil && dc.GCSConfiguration.Bucket != "" {
+		return dc.GCSConfiguration, nil
 	}
 
 	// The decoration config is always provided for decorated jobs, but many
 	// jobs are not decorated, so we guess that we should use the default location
 	// for those jobs. This assumption is usually (but not always) correct.
-	// The TestGrid configurator uses the same assumption.
+	// The TestGrid configurator uses the same assumption. The defaults are
+	// resolved for the org/repo of the job, so that teams can have their own
+	// bucket, and whatever GCS configuration the job has takes precedence.
 	repo := ""
 	if pj.Spec.Refs != nil {
 		repo = pj.Spec.Refs.Org + "/" + pj.Spec.Refs.Repo
@@ -73,8 +86,8 @@ func gcsConfig(cfg config.Getter, pj *prowv1.ProwJob) (*prowv1.GCSConfiguration,
 		repo = fmt.Sprintf("%s/%s", pj.Spec.ExtraRefs[0].Org, pj.Spec.ExtraRefs[0].Repo)
 	}
 
-	ddc := cfg().Plank.GuessDefaultDecorationConfig(repo, pj.Spec.Cluster)
-	if ddc != nil && ddc.GCSConfiguration != nil {
+	ddc := cfg().Plank.GuessDefaultDecorationConfigWithJobDC(repo, pj.Spec.Cluster, pj.Spec.DecorationConfig)
+	if ddc != nil && ddc.GCSConfiguration != nil && ddc.GCSConfiguration.Bucket != "" {
 		return ddc.GCSConfiguration, nil
 	}
 	return nil, fmt.Errorf("couldn't figure out a GCS config for %q", pj.Spec.Job)
@@ -100,3 +113,66 @@ func MarshalFinishedJSON(pj *prowv1.ProwJob) ([]byte, error) {
 	}
 	return json.MarshalIndent(f, "", "\t")
 }
+
+// CompressContent gzips the content of the named file if the job's GCS
+// configuration lists its file type in compress_file_types, like the sidecar
+// does for the job's own artifacts. Compressed content is uploaded with the
+// gzip content encoding, so GCS transparently decompresses it for readers
+// that don't ask for the compressed form. Only GCS transcodes content, so
+// uploads to other storage providers are never compressed. It returns the
+// content to upload and the writer options describing its encoding.
+func CompressContent(cfg config.Getter, pj *prowv1.ProwJob, name string, content []byte) ([]byte, []io.WriterOptions, error) {
+	if len(content) <= minCompressSize || !IsGCSDestination(cfg, pj) {
+		return content, nil, nil
+	}
+	gc, err := gcsConfig(cfg, pj)
+	if err != nil {
+		return content, nil, nil
+	}
+	fileTypes := sets.New(gc.CompressFileTypes...)
+	if !fileTypes.Has("*") && !fileTypes.Has(strings.TrimPrefix(path.Ext(name), ".")) {
+		return content, nil, nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(content); err != nil {
+		return nil, nil, fmt.Errorf("failed to compress %s: %w", name, err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, nil, fmt.Errorf("failed to compress %s: %w", name, err)
+	}
+	return buf.Bytes(), []io.WriterOptions{{ContentType: ptr.To(ContentType(name, cfg().Crier.GCSContentTypes)), ContentEncoding: ptr.To("gzip")}}, nil
+}
+
+// defaultContentTypes are the content types of common artifacts, which
+// the system's MIME types may lack, e.g. in minimal container images.
+var defaultContentTypes = map[string]string{
+	"html": "text/html; charset=utf-8",
+	"json": "application/json",
+	"log":  "text/plain; charset=utf-8",
+	"txt":  "text/plain; charset=utf-8",
+	"xml":  "text/xml; charset=utf-8",
+	"yaml": "text/plain; charset=utf-8",
+	"yml":  "text/plain; charset=utf-8",
+}
+
+// ContentType returns the content type of the named file, e.g.
+// application/json for JSON files, falling back to plain text. Uploads need
+// to set it explicitly, as storage providers otherwise detect it from the
+// content, which e.g. S3 serves JSON files as plain text with. Overrides are
+// content types keyed by file extension without the leading dot, e.g. `log`,
+// that take precedence over the detected ones.
+func ContentType(name string, overrides map[string]string) string {
+	ext := strings.TrimPrefix(path.Ext(name), ".")
+	if contentType, ok := overrides[ext]; ok {
+		return contentType
+	}
+	if contentType, ok := defaultContentTypes[strings.ToLower(ext)]; ok {
+		return contentType
+	}
+	if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+		return contentType
+	}
+	return "text/plain; charset=utf-8"
+}
@@ -17,6 +17,11 @@ limitations under the License.
 package util
 
 import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
 	"testing"
 	"time"
 
@@ -120,6 +125,32 @@ func TestGetJobDestination(t *testing.T) {
 			expectBucket:     "kubernetes-jenkins",
 			expectDir:        "some-prefix/logs/my-little-job/123",
 		},
+		{
+			name: "undecorated prowjob uses the config of its repo",
+			defaultGcsConfigs: map[string]*prowv1.GCSConfiguration{
+				"*":                     standardGcsConfig,
+				"kubernetes/test-infra": {Bucket: "test-infra-bucket"},
+			},
+			prowjobGcsConfig: nil,
+			prowjobType:      prowv1.PeriodicJob,
+			prowjobRefs:      standardRefs,
+			buildID:          "123",
+			expectBucket:     "test-infra-bucket",
+			expectDir:        "some-prefix/logs/my-little-job/123",
+		},
+		{
+			name: "prowjob without a bucket inherits the bucket of its repo",
+			defaultGcsConfigs: map[string]*prowv1.GCSConfiguration{
+				"*":                     standardGcsConfig,
+				"kubernetes/test-infra": {Bucket: "test-infra-bucket"},
+			},
+			prowjobGcsConfig: &prowv1.GCSConfiguration{PathStrategy: prowv1.PathStrategyExplicit},
+			prowjobType:      prowv1.PeriodicJob,
+			prowjobRefs:      standardRefs,
+			buildID:          "123",
+			expectBucket:     "test-infra-bucket",
+			expectDir:        "some-prefix/logs/my-little-job/123",
+		},
 		{
 			name:             "prowjob type is respected",
 			prowjobGcsConfig: standardGcsConfig,
@@ -184,3 +215,145 @@ func TestGetJobDestination(t *testing.T) {
 		})
 	}
 }
+
+func TestCompressContent(t *testing.T) {
+	large := []byte(strings.Repeat(`{"kind": "ProwJob"}`, 100))
+	testCases := []struct {
+		name              string
+		bucket            string
+		compressFileTypes []string
+		file              string
+		content           []byte
+		expectCompressed  bool
+	}{
+		{
+			name:    "nothing is compressed by default",
+			bucket:  "gs://bucket",
+			file:    "prowjob.json",
+			content: large,
+		},
+		{
+			name:              "matching file type is compressed",
+			bucket:            "gs://bucket",
+			compressFileTypes: []string{"json"},
+			file:              "prowjob.json",
+			content:           large,
+			expectCompressed:  true,
+		},
+		{
+			name:              "wildcard compresses all file types",
+			bucket:            "bucket",
+			compressFileTypes: []string{"*"},
+			file:              "prowjob.json",
+			content:           large,
+			expectCompressed:  true,
+		},
+		{
+			name:              "other file types are not compressed",
+			bucket:            "gs://bucket",
+			compressFileTypes: []string{"txt"},
+			file:              "prowjob.json",
+			content:           large,
+		},
+		{
+			name:              "small content is not compressed",
+			bucket:            "gs://bucket",
+			compressFileTypes: []string{"*"},
+			file:              "prowjob.json",
+			content:           []byte(`{}`),
+		},
+		{
+			name:              "non-GCS destinations are not compressed",
+			bucket:            "s3://bucket",
+			compressFileTypes: []string{"*"},
+			file:              "prowjob.json",
+			content:           large,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pj := &prowv1.ProwJob{
+				Spec: prowv1.ProwJobSpec{
+					DecorationConfig: &prowv1.DecorationConfig{GCSConfiguration: &prowv1.GCSConfiguration{
+						Bucket:            tc.bucket,
+						CompressFileTypes: tc.compressFileTypes,
+					}},
+				},
+				Status: prowv1.ProwJobStatus{BuildID: "123"},
+			}
+			content, opts, err := CompressContent(fca{}.Config, pj, tc.file, tc.content)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tc.expectCompressed {
+				if !bytes.Equal(content, tc.content) || len(opts) != 0 {
+					t.Errorf("expected content to be uploaded as is, got %d bytes with options %v", len(content), opts)
+				}
+				return
+			}
+			if len(opts) != 1 || opts[0].ContentEncoding == nil || *opts[0].ContentEncoding != "gzip" {
+				t.Errorf("expected gzip content encoding, got options %v", opts)
+			}
+			zr, err := gzip.NewReader(bytes.NewReader(content))
+			if err != nil {
+				t.Fatalf("content is not gzipped: %v", err)
+			}
+			decompressed, err := io.ReadAll(zr)
+			if err != nil {
+				t.Fatalf("failed to decompress content: %v", err)
+			}
+			if !bytes.Equal(decompressed, tc.content) {
+				t.Error("decompressed content differs from the original")
+			}
+		})
+	}
+}
+
+// BenchmarkCompressContent reports how much smaller a build-log-like
+// upload gets when compressed.
+func TestContentType(t *testing.T) {
+	overrides := map[string]string{"log": "text/x-log"}
+	testCases := map[string]string{
+		"prowjob.json":                "application/json",
+		"artifacts/junit_01.xml":      "text/xml; charset=utf-8",
+		"artifacts/report.HTML":       "text/html; charset=utf-8",
+		"podlogs/container-0.txt":     "text/plain; charset=utf-8",
+		"artifacts/build.log":         "text/x-log",
+		"artifacts/without-extension": "text/plain; charset=utf-8",
+	}
+	for name, expected := range testCases {
+		if actual := ContentType(name, overrides); actual != expected {
+			t.Errorf("expected content type of %s to be %q, got %q", name, expected, actual)
+		}
+	}
+}
+
+func BenchmarkCompressContent(b *testing.B) {
+	var log bytes.Buffer
+	for i := 0; log.Len() < 10<<20; i++ {
+		fmt.Fprintf(&log, "I1015 12:%02d:%02d.%06d    1234 reporter.go:123] Reporting job step %d of my-little-job\n", i/60%60, i%60, i, i)
+	}
+	pj := &prowv1.ProwJob{
+		Spec: prowv1.ProwJobSpec{
+			DecorationConfig: &prowv1.DecorationConfig{GCSConfiguration: &prowv1.GCSConfiguration{
+				Bucket:            "gs://bucket",
+				CompressFileTypes: []string{"txt"},
+			}},
+		},
+		Status: prowv1.ProwJobStatus{BuildID: "123"},
+	}
+
+	b.SetBytes(int64(log.Len()))
+	b.ResetTimer()
+	var compressed []byte
+	for i := 0; i < b.N; i++ {
+		var err error
+		compressed, _, err = CompressContent(fca{}.Config, pj, "build-log.txt", log.Bytes())
+		if err != nil {
+			b.Fatalf("unexpected error: %v", err)
+		}
+	}
+	b.ReportMetric(float64(len(compressed)), "uploaded-bytes")
+	b.ReportMetric(float64(len(compressed))/float64(log.Len()), "size-ratio")
+}
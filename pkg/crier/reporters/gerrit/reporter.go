@@ -18,13 +18,16 @@ limitations under the License.
 package gerrit
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -109,9 +112,11 @@ type gerritClient interface {
 
 // Client is a gerrit reporter client
 type Client struct {
-	gc          gerritClient
-	pjclientset ctrlruntimeclient.Client
-	prLocks     *criercommonlib.ShardedLock
+	gc                  gerritClient
+	orgRepoConfigGetter func() *config.GerritOrgRepoConfigs
+	pjclientset         ctrlruntimeclient.Client
+	prLocks             *criercommonlib.ShardedLock
+	dryRun              bool
 }
 
 // Job is the view of a prowjob scoped for a report
@@ -131,8 +136,10 @@ type JobReport struct {
 	Header  string
 }
 
-// NewReporter returns a reporter client
-func NewReporter(orgRepoConfigGetter func() *config.GerritOrgRepoConfigs, cookiefilePath string, pjclientset ctrlruntimeclient.Client, maxQPS, maxBurst int) (*Client, error) {
+// NewReporter returns a reporter client. When dryRun is set, the review that
+// would be posted is logged instead of being sent to Gerrit. Changes are still
+// read from Gerrit so that the logged vote matches what would be posted.
+func NewReporter(orgRepoConfigGetter func() *config.GerritOrgRepoConfigs, cookiefilePath string, pjclientset ctrlruntimeclient.Client, maxQPS, maxBurst int, dryRun bool) (*Client, error) {
 	// Initialize an empty client, the orgs/repos will be filled in by
 	// ApplyGlobalConfig later.
 	gc, err := client.NewClient(nil, maxQPS, maxBurst)
@@ -152,9 +159,11 @@ func NewReporter(orgRepoConfigGetter func() *config.GerritOrgRepoConfigs, cookie
 	gc.Authenticate(cookiefilePath, "")
 
 	c := &Client{
-		gc:          gc,
-		pjclientset: pjclientset,
-		prLocks:     criercommonlib.NewShardedLock(),
+		gc:                  gc,
+		orgRepoConfigGetter: orgRepoConfigGetter,
+		pjclientset:         pjclientset,
+		prLocks:             criercommonlib.NewShardedLock(),
+		dryRun:              dryRun,
 	}
 
 	c.prLocks.RunCleanup()
@@ -357,6 +366,11 @@ func (c *Client) Report(ctx context.Context, logger *logrus.Entry, pj *v1.ProwJo
 	}
 	report := GenerateReport(toReportJobs, 0)
 	message := report.Header + report.Message
+	if custom := c.customMessage(logger, pj); custom != "" {
+		// The custom message goes before the report, as the lines after
+		// its header are parsed as job results, see ParseReport.
+		message = custom + "\n\n" + message
+	}
 	// report back
 	gerritID := pj.ObjectMeta.Annotations[clientGerritID]
 	gerritInstance := pj.ObjectMeta.Annotations[clientGerritInstance]
@@ -365,9 +379,12 @@ func (c *Client) Report(ctx context.Context, logger *logrus.Entry, pj *v1.ProwJo
 		"instance": gerritInstance,
 		"id":       gerritID,
 	})
+	labelConfig := c.reportLabelConfig(pj)
 	var reportLabel string
 	if val, ok := pj.ObjectMeta.Labels[kube.GerritReportLabel]; ok {
 		reportLabel = val
+	} else if labelConfig != nil && labelConfig.Name != "" {
+		reportLabel = labelConfig.Name
 	} else {
 		reportLabel = codeReview
 	}
@@ -387,10 +404,16 @@ func (c *Client) Report(ctx context.Context, logger *logrus.Entry, pj *v1.ProwJo
 		switch {
 		case report.Success == report.Total:
 			vote = lgtm
+			if labelConfig != nil {
+				vote = formatVote(labelConfig.Value(v1.SuccessState))
+			}
 		case pj.Spec.Type == v1.PresubmitJob:
 			//https://gerrit-documentation.storage.googleapis.com/Documentation/3.1.4/config-labels.html#label_allowPostSubmit
 			// If presubmit and failure vote -1...
 			vote = lbtm
+			if labelConfig != nil {
+				vote = formatVote(labelConfig.Value(v1.FailureState))
+			}
 
 			change, err = c.gc.GetChange(gerritInstance, gerritID)
 			if err != nil {
@@ -409,10 +432,33 @@ func (c *Client) Report(ctx context.Context, logger *logrus.Entry, pj *v1.ProwJo
 			vote = lztm
 		}
 		reviewLabels = map[string]string{reportLabel: vote}
+
+		// Configured values may not fit the label's range, which is only
+		// known when the change is fetched with its detailed labels.
+		if labelConfig != nil && len(labelConfig.Values) > 0 && vote != lztm {
+			detailed, err := c.gc.GetChange(gerritInstance, gerritID, "DETAILED_LABELS")
+			if err != nil {
+				logger.WithError(err).Warn("Unable to get labels of change, not checking the range of the vote")
+			} else if detailed != nil && !voteInRange(detailed, reportLabel, vote) {
+				logger.WithFields(logrus.Fields{"label": reportLabel, "vote": vote}).Error("Configured vote is out of the range of the label.")
+				message = fmt.Sprintf("[NOTICE]: Prow Bot cannot vote %s on %s label, which is out of the label's range!\n%s", vote, reportLabel, message)
+				reviewLabels = nil
+			}
+		}
 	}
 
 	logger.Infof("Reporting to instance %s on id %s with message %s", gerritInstance, gerritID, message)
-	if err := c.gc.SetReview(gerritInstance, gerritID, gerritRevision, message, reviewLabels); err != nil {
+	if c.dryRun {
+		review, err := json.Marshal(&gerrit.ReviewInput{Message: message, Labels: reviewLabels})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal review input: %w", err)
+		}
+		logger.WithFields(logrus.Fields{
+			"change":   changeNumber(pj),
+			"revision": gerritRevision,
+			"review":   string(review),
+		}).Info("Skipping reporting because dry-run is enabled")
+	} else if err := c.gc.SetReview(gerritInstance, gerritID, gerritRevision, message, reviewLabels); err != nil {
 		logger.WithError(err).WithField("gerrit_id", gerritID).WithField("label", reportLabel).Info("Failed to set review.")
 
 		// It could be that the commit is deleted by the time we want to report.
@@ -491,6 +537,77 @@ func (c *Client) Report(ctx context.Context, logger *logrus.Entry, pj *v1.ProwJo
 	return nil, nil, err
 }
 
+// reportLabelConfig returns the report label configured for the repo of the
+// job, or nil if there is none.
+func (c *Client) reportLabelConfig(pj *v1.ProwJob) *config.GerritReportLabel {
+	if c.orgRepoConfigGetter == nil || pj.Spec.Refs == nil {
+		return nil
+	}
+	return c.orgRepoConfigGetter().ReportLabel(pj.ObjectMeta.Annotations[kube.GerritInstance], pj.Spec.Refs.Repo)
+}
+
+// customMessage renders the message template configured for the repo of the
+// job, or returns an empty string if there is none. The template is
+// validated when the config is loaded, a job it fails for is still reported,
+// just without the custom message.
+func (c *Client) customMessage(logger *logrus.Entry, pj *v1.ProwJob) string {
+	if c.orgRepoConfigGetter == nil || pj.Spec.Refs == nil {
+		return ""
+	}
+	message := c.orgRepoConfigGetter().Message(pj.ObjectMeta.Annotations[kube.GerritInstance], pj.Spec.Refs.Repo)
+	if message == "" {
+		return ""
+	}
+	tmpl, err := template.New("").Parse(message)
+	if err != nil {
+		logger.WithError(err).Error("Failed to parse message template")
+		return ""
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, pj); err != nil {
+		logger.WithError(err).Error("Failed to execute message template")
+		return ""
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// formatVote formats a vote the way Gerrit displays it, e.g. +1, 0 or -1.
+func formatVote(value int) string {
+	if value > 0 {
+		return "+" + strconv.Itoa(value)
+	}
+	return strconv.Itoa(value)
+}
+
+// voteInRange returns whether vote is one of the values of the label on the
+// change. Votes on labels whose values aren't known are assumed to be valid.
+func voteInRange(change *gerrit.ChangeInfo, label, vote string) bool {
+	info, ok := change.Labels[label]
+	if !ok || len(info.Values) == 0 {
+		return true
+	}
+	want, err := strconv.Atoi(vote)
+	if err != nil {
+		return false
+	}
+	for value := range info.Values {
+		// Gerrit pads the values, e.g. " 0".
+		if got, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && got == want {
+			return true
+		}
+	}
+	return false
+}
+
+// changeNumber returns the numeric id of the change the job ran against, or
+// the Gerrit change id if the number isn't known.
+func changeNumber(pj *v1.ProwJob) string {
+	if pj.Spec.Refs != nil && len(pj.Spec.Refs.Pulls) > 0 {
+		return strconv.Itoa(pj.Spec.Refs.Pulls[0].Number)
+	}
+	return pj.ObjectMeta.Annotations[kube.GerritID]
+}
+
 func jobNames(jobs []*v1.ProwJob) []string {
 	names := make([]string, len(jobs))
 	for i, job := range jobs {
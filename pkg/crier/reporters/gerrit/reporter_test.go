@@ -33,9 +33,11 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/diff"
+	"k8s.io/utils/ptr"
 	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
 	"sigs.k8s.io/prow/pkg/crier/reporters/criercommonlib"
 	"sigs.k8s.io/prow/pkg/kube"
 )
@@ -2475,3 +2477,247 @@ func TestReportStability(t *testing.T) {
 		t.Errorf(diff.ObjectReflectDiff(&expected, actual))
 	}
 }
+
+func TestReportDryRun(t *testing.T) {
+	pj := &v1.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				kube.GerritRevision:   "abc",
+				kube.ProwJobTypeLabel: presubmit,
+			},
+			Annotations: map[string]string{
+				kube.GerritID:       "123-abc",
+				kube.GerritInstance: "gerrit",
+			},
+			Name:      "ci-foo",
+			Namespace: "test-pods",
+		},
+		Spec: v1.ProwJobSpec{
+			Type: v1.PresubmitJob,
+			Refs: &v1.Refs{
+				Repo:  "foo",
+				Pulls: []v1.Pull{{Number: 123}},
+			},
+			Job:    "ci-foo",
+			Report: true,
+		},
+		Status: v1.ProwJobStatus{
+			State: v1.SuccessState,
+			URL:   "guber/foo",
+		},
+	}
+	fgc := &fgc{instance: "gerrit", changes: map[string][]*gerrit.ChangeInfo{
+		"gerrit": {{ID: "123-abc", Status: "NEW", Revisions: map[string]gerrit.RevisionInfo{"abc": {}}}},
+	}}
+	reporter := &Client{
+		gc:          fgc,
+		pjclientset: fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(pj).Build(),
+		prLocks:     criercommonlib.NewShardedLock(),
+		dryRun:      true,
+	}
+
+	if _, _, err := reporter.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fgc.count != 0 {
+		t.Errorf("Expected no review to be set in dry-run mode, got %d", fgc.count)
+	}
+}
+
+func TestReportCustomLabel(t *testing.T) {
+	changes := map[string][]*gerrit.ChangeInfo{
+		"gerrit": {{
+			ID:        "123-abc",
+			Status:    "NEW",
+			Revisions: map[string]gerrit.RevisionInfo{"abc": {}},
+			Labels: map[string]gerrit.LabelInfo{
+				"Prow-Verified": {Values: map[string]string{"-2": "Broken", "-1": "Fails", " 0": "No score", "+1": "Works"}},
+			},
+		}},
+	}
+	testcases := []struct {
+		name          string
+		state         v1.ProwJobState
+		reportLabel   *string
+		labelConfig   *config.GerritReportLabel
+		expectLabel   map[string]string
+		reportInclude []string
+	}{
+		{
+			name:        "no config, default label and values",
+			state:       v1.FailureState,
+			expectLabel: map[string]string{codeReview: lbtm},
+		},
+		{
+			name:        "configured name, default values",
+			state:       v1.SuccessState,
+			labelConfig: &config.GerritReportLabel{Name: "Prow-Verified"},
+			expectLabel: map[string]string{"Prow-Verified": lgtm},
+		},
+		{
+			name:  "configured name and values",
+			state: v1.FailureState,
+			labelConfig: &config.GerritReportLabel{
+				Name:   "Prow-Verified",
+				Values: map[v1.ProwJobState]int{v1.SuccessState: 1, v1.FailureState: -2},
+			},
+			expectLabel: map[string]string{"Prow-Verified": "-2"},
+		},
+		{
+			name:        "label of the job takes precedence over the configured name",
+			state:       v1.SuccessState,
+			reportLabel: ptr.To("Other"),
+			labelConfig: &config.GerritReportLabel{
+				Name:   "Prow-Verified",
+				Values: map[v1.ProwJobState]int{v1.SuccessState: 2},
+			},
+			expectLabel: map[string]string{"Other": "+2"},
+		},
+		{
+			name:  "configured value out of the label's range, not voting",
+			state: v1.SuccessState,
+			labelConfig: &config.GerritReportLabel{
+				Name:   "Prow-Verified",
+				Values: map[v1.ProwJobState]int{v1.SuccessState: 2},
+			},
+			reportInclude: []string{"cannot vote +2 on Prow-Verified label"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			pj := &v1.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						kube.GerritRevision:   "abc",
+						kube.ProwJobTypeLabel: presubmit,
+					},
+					Annotations: map[string]string{
+						kube.GerritID:       "123-abc",
+						kube.GerritInstance: "gerrit",
+					},
+					Name:      "ci-foo",
+					Namespace: "test-pods",
+				},
+				Spec: v1.ProwJobSpec{
+					Type: v1.PresubmitJob,
+					Refs: &v1.Refs{
+						Repo:  "foo",
+						Pulls: []v1.Pull{{Number: 123}},
+					},
+					Job:    "ci-foo",
+					Report: true,
+				},
+				Status: v1.ProwJobStatus{
+					State: tc.state,
+					URL:   "guber/foo",
+				},
+			}
+			if tc.reportLabel != nil {
+				pj.ObjectMeta.Labels[kube.GerritReportLabel] = *tc.reportLabel
+			}
+			orgRepoConfigs := &config.GerritOrgRepoConfigs{{Org: "gerrit", Repos: []string{"foo"}, ReportLabel: tc.labelConfig}}
+			fgc := &fgc{instance: "gerrit", changes: changes}
+			reporter := &Client{
+				gc:                  fgc,
+				orgRepoConfigGetter: func() *config.GerritOrgRepoConfigs { return orgRepoConfigs },
+				pjclientset:         fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(pj).Build(),
+				prLocks:             criercommonlib.NewShardedLock(),
+			}
+
+			if _, _, err := reporter.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tc.expectLabel, fgc.reportLabel) {
+				t.Errorf("labels: got %v, want %v", fgc.reportLabel, tc.expectLabel)
+			}
+			for _, include := range tc.reportInclude {
+				if !strings.Contains(fgc.reportMessage, include) {
+					t.Errorf("message: got %q, does not contain %s", fgc.reportMessage, include)
+				}
+			}
+		})
+	}
+}
+
+func TestReportCustomMessage(t *testing.T) {
+	changes := map[string][]*gerrit.ChangeInfo{
+		"gerrit": {{ID: "123-abc", Status: "NEW", Revisions: map[string]gerrit.RevisionInfo{"abc": {}}}},
+	}
+	testcases := []struct {
+		name          string
+		message       string
+		expectPrefix  string
+		expectNoExtra bool
+	}{
+		{
+			name:         "message links to spyglass",
+			message:      "Logs of {{.Spec.Job}}: {{.Status.URL}}",
+			expectPrefix: "Logs of ci-foo: https://prow.example.com/view/gs/bucket/ci-foo/1\n\n",
+		},
+		{
+			name:          "no message",
+			expectNoExtra: true,
+		},
+		{
+			name:          "failing template doesn't keep the job from being reported",
+			message:       "{{.Status.URL.Host}}",
+			expectNoExtra: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			pj := &v1.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						kube.GerritRevision:   "abc",
+						kube.ProwJobTypeLabel: presubmit,
+					},
+					Annotations: map[string]string{
+						kube.GerritID:       "123-abc",
+						kube.GerritInstance: "gerrit",
+					},
+					Name:      "ci-foo",
+					Namespace: "test-pods",
+				},
+				Spec: v1.ProwJobSpec{
+					Type: v1.PresubmitJob,
+					Refs: &v1.Refs{
+						Repo:  "foo",
+						Pulls: []v1.Pull{{Number: 123}},
+					},
+					Job:    "ci-foo",
+					Report: true,
+				},
+				Status: v1.ProwJobStatus{
+					State: v1.FailureState,
+					URL:   "https://prow.example.com/view/gs/bucket/ci-foo/1",
+				},
+			}
+			orgRepoConfigs := &config.GerritOrgRepoConfigs{{Org: "gerrit", Repos: []string{"foo"}, Message: tc.message}}
+			fgc := &fgc{instance: "gerrit", changes: changes}
+			reporter := &Client{
+				gc:                  fgc,
+				orgRepoConfigGetter: func() *config.GerritOrgRepoConfigs { return orgRepoConfigs },
+				pjclientset:         fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(pj).Build(),
+				prLocks:             criercommonlib.NewShardedLock(),
+			}
+
+			if _, _, err := reporter.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if tc.expectNoExtra && !isHeaderMessageLine(fgc.reportMessage) {
+				t.Errorf("message: expected the report only, got %q", fgc.reportMessage)
+			}
+			if !strings.HasPrefix(fgc.reportMessage, tc.expectPrefix) {
+				t.Errorf("message: got %q, expected prefix %q", fgc.reportMessage, tc.expectPrefix)
+			}
+			// The custom message must not keep the report from being parsed.
+			report := ParseReport(fgc.reportMessage)
+			if report == nil || len(report.Jobs) != 1 || report.Jobs[0].Name != "ci-foo" || report.Jobs[0].State != v1.FailureState {
+				t.Errorf("expected the report of the failed job to be parsed, got %+v", report)
+			}
+		})
+	}
+}
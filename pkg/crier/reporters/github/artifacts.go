@@ -0,0 +1,162 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/crier"
+	gcsutil "sigs.k8s.io/prow/pkg/crier/reporters/gcs/util"
+	"sigs.k8s.io/prow/pkg/github/report"
+	"sigs.k8s.io/prow/pkg/io"
+)
+
+// artifactsCommentMarker identifies the artifacts comment of a job, so that
+// a single comment per job is kept up to date across reruns.
+const artifactsCommentMarker = "<!-- prow artifacts: %s -->"
+
+// reportArtifactsComment creates or updates the comment linking to the
+// artifacts and the Spyglass view of a failed presubmit.
+func (c *Client) reportArtifactsComment(ctx context.Context, log *logrus.Entry, pj *v1.ProwJob) error {
+	cfg := c.config()
+	if !cfg.GitHubReporter.PostArtifactsComment || pj.Spec.Type != v1.PresubmitJob {
+		return nil
+	}
+	if pj.Status.State != v1.FailureState && pj.Status.State != v1.ErrorState {
+		return nil
+	}
+	if !report.ShouldReport(*pj, cfg.GitHubReporter.JobTypesToReport) {
+		return nil
+	}
+	refs := pj.Spec.Refs
+	if refs == nil || len(refs.Pulls) != 1 {
+		return nil
+	}
+
+	body := c.artifactsComment(ctx, log, pj)
+	if body == "" {
+		return nil
+	}
+
+	ics, err := c.gc.ListIssueCommentsWithContext(ctx, refs.Org, refs.Repo, refs.Pulls[0].Number)
+	if err != nil {
+		return fmt.Errorf("error listing comments: %w", err)
+	}
+	isBot, err := c.gc.BotUserCheckerWithContext(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting bot name checker: %w", err)
+	}
+	marker := fmt.Sprintf(artifactsCommentMarker, pj.Spec.Job)
+	for _, ic := range ics {
+		if !isBot(ic.User.Login) || !strings.Contains(ic.Body, marker) {
+			continue
+		}
+		if ic.Body == body {
+			return nil
+		}
+		if err := c.gc.EditCommentWithContext(ctx, refs.Org, refs.Repo, ic.ID, body); err != nil {
+			return fmt.Errorf("error editing artifacts comment: %w", err)
+		}
+		return nil
+	}
+	if err := c.gc.CreateCommentWithContext(ctx, refs.Org, refs.Repo, refs.Pulls[0].Number, body); err != nil {
+		return fmt.Errorf("error creating artifacts comment: %w", err)
+	}
+	return nil
+}
+
+// artifactsComment returns the body of the artifacts comment of the job, or
+// an empty string if there is nothing to link to.
+func (c *Client) artifactsComment(ctx context.Context, log *logrus.Entry, pj *v1.ProwJob) string {
+	var links []string
+	if pj.Status.URL != "" {
+		links = append(links, fmt.Sprintf("- [Spyglass view](%s)", pj.Status.URL))
+	}
+	if url := c.artifactsURL(log, pj); url != "" {
+		links = append(links, fmt.Sprintf("- [Artifacts](%s)", url))
+	}
+	if url := c.buildLogURL(ctx, log, pj); url != "" {
+		links = append(links, fmt.Sprintf("- [Build log](%s)", url))
+	}
+	if len(links) == 0 {
+		return ""
+	}
+	refs := pj.Spec.Refs
+	var b strings.Builder
+	fmt.Fprintf(&b, artifactsCommentMarker+"\n", pj.Spec.Job)
+	fmt.Fprintf(&b, "`%s` %s for commit %s:\n\n", pj.Spec.Job, pj.Status.State, refs.Pulls[0].SHA)
+	b.WriteString(strings.Join(links, "\n"))
+	b.WriteString("\n")
+	return b.String()
+}
+
+// artifactsURL returns a link to the artifacts of the job in the GCS browser
+// configured for Spyglass, or an empty string if there is none.
+func (c *Client) artifactsURL(log *logrus.Entry, pj *v1.ProwJob) string {
+	if !gcsutil.IsGCSDestination(c.config, pj) {
+		return ""
+	}
+	bucket, dir, err := gcsutil.GetJobDestination(c.config, pj)
+	if err != nil {
+		log.WithError(err).Debug("Could not determine artifacts location")
+		return ""
+	}
+	bucket = strings.TrimPrefix(bucket, "gs://")
+	prefix := c.config().Deck.Spyglass.GetGCSBrowserPrefix(pj.Spec.Refs.Org, pj.Spec.Refs.Repo, bucket)
+	if prefix == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s%s/%s/", prefix, bucket, dir)
+}
+
+// buildLogURL returns a signed URL of the build log of the job if
+// signed_url_expiry is set, or an empty string otherwise. If the URL can't be
+// signed, e.g. because the credentials can't sign or there are none, the
+// plain URL of the build log is returned instead.
+func (c *Client) buildLogURL(ctx context.Context, log *logrus.Entry, pj *v1.ProwJob) string {
+	expiry := c.config().GitHubReporter.SignedURLExpiry
+	if expiry == nil || !gcsutil.IsGCSDestination(c.config, pj) {
+		return ""
+	}
+	bucket, dir, err := gcsutil.GetJobDestination(c.config, pj)
+	if err != nil {
+		log.WithError(err).Debug("Could not determine artifacts location")
+		return ""
+	}
+	bucket = strings.TrimPrefix(bucket, "gs://")
+	logPath := path.Join(dir, crier.BuildLogName)
+	if c.opener != nil {
+		signed, err := c.opener.SignedURL(ctx, fmt.Sprintf("gs://%s/%s", bucket, logPath), io.SignedURLOptions{Expiry: expiry.Duration})
+		if err == nil {
+			return signed
+		}
+		log.WithError(err).Debug("Could not sign build log URL, falling back to the plain URL")
+	}
+	plain := &url.URL{
+		Scheme: "https",
+		Host:   io.GSAnonHost,
+		Path:   path.Join(bucket, logPath),
+	}
+	return plain.String()
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/github/fakegithub"
+	"sigs.k8s.io/prow/pkg/io"
+)
+
+func TestReportArtifactsComment(t *testing.T) {
+	const comment = "<!-- prow artifacts: pull-unit -->\n" +
+		"`pull-unit` failure for commit abc:\n\n" +
+		"- [Spyglass view](https://prow.example.com/view/gs/bucket/pr-logs/pull/org_repo/1/pull-unit/42)\n" +
+		"- [Artifacts](https://gcsweb.example.com/gcs/bucket/pr-logs/pull/org_repo/1/pull-unit/42/)\n"
+
+	testCases := []struct {
+		name     string
+		disabled bool
+		state    v1.ProwJobState
+		existing []github.IssueComment
+		added    []string
+		edited   []string
+	}{
+		{
+			name:  "failed job gets a comment",
+			state: v1.FailureState,
+			added: []string{"org/repo#1:" + comment},
+		},
+		{
+			name:     "disabled",
+			disabled: true,
+			state:    v1.FailureState,
+		},
+		{
+			name:  "successful job gets no comment",
+			state: v1.SuccessState,
+		},
+		{
+			name:  "existing comment is updated",
+			state: v1.ErrorState,
+			existing: []github.IssueComment{{
+				ID:   7,
+				Body: "<!-- prow artifacts: pull-unit -->\nold",
+				User: github.User{Login: "k8s-ci-robot"},
+			}},
+			edited: []string{"org/repo#7:" +
+				"<!-- prow artifacts: pull-unit -->\n" +
+				"`pull-unit` error for commit abc:\n\n" +
+				"- [Spyglass view](https://prow.example.com/view/gs/bucket/pr-logs/pull/org_repo/1/pull-unit/42)\n" +
+				"- [Artifacts](https://gcsweb.example.com/gcs/bucket/pr-logs/pull/org_repo/1/pull-unit/42/)\n"},
+		},
+		{
+			name:  "unchanged comment is left alone",
+			state: v1.FailureState,
+			existing: []github.IssueComment{{
+				ID:   7,
+				Body: comment,
+				User: github.User{Login: "k8s-ci-robot"},
+			}},
+		},
+		{
+			name:  "comments of other users are ignored",
+			state: v1.FailureState,
+			existing: []github.IssueComment{{
+				ID:   7,
+				Body: "<!-- prow artifacts: pull-unit -->\nold",
+				User: github.User{Login: "someone"},
+			}},
+			added: []string{"org/repo#1:" + comment},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fghc := fakegithub.NewFakeClient()
+			fghc.IssueComments[1] = tc.existing
+			c := Client{
+				gc: fghc,
+				config: func() *config.Config {
+					cfg := &config.Config{}
+					cfg.GitHubReporter.JobTypesToReport = []v1.ProwJobType{v1.PresubmitJob}
+					cfg.GitHubReporter.PostArtifactsComment = !tc.disabled
+					cfg.Deck.Spyglass.GCSBrowserPrefixesByRepo = config.GCSBrowserPrefixes{"*": "https://gcsweb.example.com/gcs/"}
+					return cfg
+				},
+			}
+			pj := &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Type:   v1.PresubmitJob,
+					Job:    "pull-unit",
+					Report: true,
+					Refs: &v1.Refs{
+						Org:   "org",
+						Repo:  "repo",
+						Pulls: []v1.Pull{{Number: 1, SHA: "abc"}},
+					},
+					DecorationConfig: &v1.DecorationConfig{
+						GCSConfiguration: &v1.GCSConfiguration{
+							Bucket:       "gs://bucket",
+							PathStrategy: v1.PathStrategyExplicit,
+						},
+					},
+				},
+				Status: v1.ProwJobStatus{
+					State:          tc.state,
+					CompletionTime: &metav1.Time{},
+					BuildID:        "42",
+					URL:            "https://prow.example.com/view/gs/bucket/pr-logs/pull/org_repo/1/pull-unit/42",
+				},
+			}
+
+			if err := c.reportArtifactsComment(context.Background(), logrus.WithField("test", tc.name), pj); err != nil {
+				t.Fatalf("reportArtifactsComment: %v", err)
+			}
+			if diff := cmp.Diff(tc.added, fghc.IssueCommentsAdded); diff != "" {
+				t.Errorf("added comments differ from expected (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.edited, fghc.IssueCommentsEdited); diff != "" {
+				t.Errorf("edited comments differ from expected (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// signingOpener signs URLs by appending the expiry, or fails to sign them.
+type signingOpener struct {
+	io.Opener
+	err error
+}
+
+func (o *signingOpener) SignedURL(_ context.Context, p string, opts io.SignedURLOptions) (string, error) {
+	if o.err != nil {
+		return "", o.err
+	}
+	return p + "?expires=" + opts.Expiry.String(), nil
+}
+
+func TestBuildLogURL(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expiry   *metav1.Duration
+		opener   io.Opener
+		expected string
+	}{
+		{
+			name:   "no expiry has no build log link",
+			opener: &signingOpener{},
+		},
+		{
+			name:     "signed URL",
+			expiry:   &metav1.Duration{Duration: 24 * time.Hour},
+			opener:   &signingOpener{},
+			expected: "gs://bucket/pr-logs/pull/org_repo/1/pull-unit/42/build-log.txt?expires=24h0m0s",
+		},
+		{
+			name:     "backend that can't sign falls back to the plain URL",
+			expiry:   &metav1.Duration{Duration: 24 * time.Hour},
+			opener:   &signingOpener{err: errors.New("only service_account GCS auth is supported")},
+			expected: "https://storage.googleapis.com/bucket/pr-logs/pull/org_repo/1/pull-unit/42/build-log.txt",
+		},
+		{
+			name:     "no opener falls back to the plain URL",
+			expiry:   &metav1.Duration{Duration: 24 * time.Hour},
+			expected: "https://storage.googleapis.com/bucket/pr-logs/pull/org_repo/1/pull-unit/42/build-log.txt",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := Client{
+				config: func() *config.Config {
+					cfg := &config.Config{}
+					cfg.GitHubReporter.SignedURLExpiry = tc.expiry
+					return cfg
+				},
+				opener: tc.opener,
+			}
+			pj := &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Type: v1.PresubmitJob,
+					Job:  "pull-unit",
+					Refs: &v1.Refs{
+						Org:   "org",
+						Repo:  "repo",
+						Pulls: []v1.Pull{{Number: 1, SHA: "abc"}},
+					},
+					DecorationConfig: &v1.DecorationConfig{
+						GCSConfiguration: &v1.GCSConfiguration{
+							Bucket:       "gs://bucket",
+							PathStrategy: v1.PathStrategyExplicit,
+						},
+					},
+				},
+				Status: v1.ProwJobStatus{BuildID: "42"},
+			}
+
+			if actual := c.buildLogURL(context.Background(), logrus.WithField("test", tc.name), pj); actual != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/crier"
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/github/report"
+)
+
+const (
+	// maxCheckRunOutputLength is the maximum length GitHub accepts for the
+	// summary and the text of a check run output.
+	maxCheckRunOutputLength = 65535
+)
+
+// checkRunClient is the part of the GitHub client used to report check runs.
+type checkRunClient interface {
+	ListCheckRuns(org, repo, ref string) (*github.CheckRunList, error)
+	CreateCheckRun(org, repo string, checkRun github.CheckRun) (int64, error)
+	UpdateCheckRun(org, repo string, checkRunId int64, checkRun github.CheckRun) error
+}
+
+// GitHubClient is the GitHub client used by the reporter.
+type GitHubClient interface {
+	report.GitHubClient
+	checkRunClient
+}
+
+// reportCheckRun creates or updates the check run of the job. Check runs
+// are matched to jobs through their external ID, which is the name of the
+// ProwJob, so that every run of a job gets its own check run.
+func (c *Client) reportCheckRun(ctx context.Context, log *logrus.Entry, pj *v1.ProwJob) error {
+	cfg := c.config().GitHubReporter
+	if !report.ShouldReport(*pj, cfg.JobTypesToReport) {
+		return nil
+	}
+	refs := pj.Spec.Refs
+	// we are not reporting for batch jobs, like with status contexts
+	if len(refs.Pulls) > 1 {
+		return nil
+	}
+	sha := refs.BaseSHA
+	if len(refs.Pulls) > 0 {
+		sha = refs.Pulls[0].SHA
+	}
+
+	checkRun := c.checkRunForJob(ctx, log, pj, cfg)
+	// GitHub only accepts a limited number of annotations per request, the
+	// rest are added by updating the check run, which appends them.
+	annotations := checkRun.Output.Annotations
+	batch := min(len(annotations), maxAnnotationsPerRequest)
+	checkRun.Output.Annotations = annotations[:batch]
+	id, err := c.createOrUpdateCheckRun(refs.Org, refs.Repo, sha, pj.Name, checkRun)
+	if err != nil {
+		return err
+	}
+	for remaining := annotations[batch:]; len(remaining) > 0; remaining = remaining[batch:] {
+		batch = min(len(remaining), maxAnnotationsPerRequest)
+		output := checkRun.Output
+		output.Annotations = remaining[:batch]
+		if err := c.gc.UpdateCheckRun(refs.Org, refs.Repo, id, github.CheckRun{Output: output}); err != nil {
+			return fmt.Errorf("error adding annotations to check run: %w", err)
+		}
+	}
+	return nil
+}
+
+// createOrUpdateCheckRun updates the check run of the job if it exists and
+// creates it otherwise. It returns the ID of the check run.
+func (c *Client) createOrUpdateCheckRun(org, repo, sha, externalID string, checkRun github.CheckRun) (int64, error) {
+	runs, err := c.gc.ListCheckRuns(org, repo, sha)
+	if err != nil {
+		return 0, fmt.Errorf("error listing check runs: %w", err)
+	}
+	for _, existing := range runs.CheckRuns {
+		if existing.ExternalID != externalID || existing.Name != checkRun.Name {
+			continue
+		}
+		if err := c.gc.UpdateCheckRun(org, repo, existing.ID, checkRun); err != nil {
+			return 0, fmt.Errorf("error updating check run: %w", err)
+		}
+		return existing.ID, nil
+	}
+
+	checkRun.HeadSHA = sha
+	id, err := c.gc.CreateCheckRun(org, repo, checkRun)
+	if err != nil {
+		return 0, fmt.Errorf("error creating check run: %w", err)
+	}
+	return id, nil
+}
+
+// checkRunForJob builds the check run payload for the current state of the
+// job. The head SHA is not included, as it can't be updated.
+func (c *Client) checkRunForJob(ctx context.Context, log *logrus.Entry, pj *v1.ProwJob, cfg config.GitHubReporter) github.CheckRun {
+	checkRun := github.CheckRun{
+		Name:       pj.Spec.Context,
+		ExternalID: pj.Name,
+		DetailsURL: pj.Status.URL,
+		Status:     "queued",
+	}
+	if !pj.Status.StartTime.IsZero() {
+		checkRun.StartedAt = pj.Status.StartTime.UTC().Format(time.RFC3339)
+	}
+	if pj.Status.State == v1.PendingState {
+		checkRun.Status = "in_progress"
+	}
+
+	// GitHub requires a summary whenever an output is set.
+	summary := pj.Status.Description
+	if summary == "" {
+		summary = fmt.Sprintf("Job %s.", pj.Status.State)
+	}
+	if pj.Status.URL != "" {
+		summary = fmt.Sprintf("%s\n\n[View job on Prow](%s)", summary, pj.Status.URL)
+	}
+	checkRun.Output = github.CheckRunOutput{
+		Title:   fmt.Sprintf("%s %s", pj.Spec.Job, pj.Status.State),
+		Summary: truncate(summary, maxCheckRunOutputLength),
+	}
+
+	if !pj.Complete() {
+		return checkRun
+	}
+	checkRun.Status = "completed"
+	checkRun.Conclusion = checkRunConclusion(pj.Status.State)
+	if pj.Status.CompletionTime != nil {
+		checkRun.CompletedAt = pj.Status.CompletionTime.UTC().Format(time.RFC3339)
+	}
+	if logLines := cfg.CheckRunLogLines; logLines > 0 && c.opener != nil {
+		tail, err := crier.BuildLogTail(ctx, c.config, c.opener, pj, crier.LogTailOptions{Lines: logLines})
+		if err != nil {
+			// The check run is still useful without the log.
+			log.WithError(err).Debug("Failed to read build log for check run output")
+		} else if tail != "" {
+			header := fmt.Sprintf("Last %d lines of the build log:\n\n```\n", logLines)
+			footer := "\n```"
+			checkRun.Output.Text = header + truncate(tail, maxCheckRunOutputLength-len(header)-len(footer)) + footer
+		}
+	}
+	if cfg.CheckRunJUnitGlob != "" && c.opener != nil && pj.Spec.Type == v1.PresubmitJob && pj.Status.State == v1.FailureState {
+		tests, err := c.failedTests(ctx, log, pj, cfg.CheckRunJUnitGlob)
+		if err != nil {
+			// The check run is still useful without the failed tests.
+			log.WithError(err).Debug("Failed to read JUnit files for check run annotations")
+		} else {
+			annotateFailedTests(&checkRun.Output, tests)
+		}
+	}
+	return checkRun
+}
+
+// checkRunConclusion maps the state of a completed job to the conclusion of
+// its check run.
+func checkRunConclusion(state v1.ProwJobState) string {
+	switch state {
+	case v1.SuccessState:
+		return "success"
+	case v1.AbortedState:
+		return "cancelled"
+	default:
+		return "failure"
+	}
+}
+
+// truncate shortens s to at most n bytes, keeping its end, which is where
+// failures are usually logged.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return "..." + s[len(s)-n+3:]
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/github/fakegithub"
+	"sigs.k8s.io/prow/pkg/io/fakeopener"
+)
+
+func checkRunTestConfig() *config.Config {
+	return &config.Config{
+		ProwConfig: config.ProwConfig{
+			GitHubReporter: config.GitHubReporter{
+				JobTypesToReport: []v1.ProwJobType{v1.PresubmitJob},
+				NoCommentRepos:   []string{"org"},
+				CheckRunRepos:    []string{"org/repo"},
+				CheckRunLogLines: 2,
+			},
+			Plank: config.Plank{
+				DefaultDecorationConfigs: config.DefaultDecorationMapToSliceTesting(
+					map[string]*v1.DecorationConfig{"*": {
+						GCSConfiguration: &v1.GCSConfiguration{
+							Bucket:       "bucket",
+							PathStrategy: v1.PathStrategyExplicit,
+						},
+					}}),
+			},
+		},
+	}
+}
+
+func TestReportCheckRun(t *testing.T) {
+	fghc := fakegithub.NewFakeClient()
+	opener := &fakeopener.FakeOpener{Buffer: map[string]*bytes.Buffer{
+		"gs://bucket/pr-logs/pull/org_repo/1/my-job/123/build-log.txt": bytes.NewBufferString("one\ntwo\nthree\n"),
+	}}
+	c := &Client{
+		gc:      fghc,
+		config:  checkRunTestConfig,
+		prLocks: nil,
+		opener:  opener,
+	}
+	start := metav1.NewTime(time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC))
+	pj := &v1.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "abc"},
+		Spec: v1.ProwJobSpec{
+			Type:    v1.PresubmitJob,
+			Job:     "my-job",
+			Context: "my-context",
+			Report:  true,
+			Refs: &v1.Refs{
+				Org:   "org",
+				Repo:  "repo",
+				Pulls: []v1.Pull{{Number: 1, SHA: "sha"}},
+			},
+		},
+		Status: v1.ProwJobStatus{
+			State:       v1.PendingState,
+			StartTime:   start,
+			Description: "Job triggered.",
+			URL:         "https://prow.example.com/view/1",
+			BuildID:     "123",
+		},
+	}
+
+	if err := c.reportCheckRun(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj); err != nil {
+		t.Fatalf("reporting pending job failed: %v", err)
+	}
+	expected := []github.CheckRun{{
+		ID:         1,
+		HeadSHA:    "sha",
+		ExternalID: "abc",
+		DetailsURL: "https://prow.example.com/view/1",
+		Status:     "in_progress",
+		StartedAt:  "2024-01-01T10:00:00Z",
+		Name:       "my-context",
+		Output: github.CheckRunOutput{
+			Title:   "my-job pending",
+			Summary: "Job triggered.\n\n[View job on Prow](https://prow.example.com/view/1)",
+		},
+	}}
+	if diff := cmp.Diff(expected, fghc.CheckRuns["org/repo"]); diff != "" {
+		t.Fatalf("check runs differ from expected after pending report: %s", diff)
+	}
+
+	pj.Status.State = v1.FailureState
+	pj.Status.Description = "Job failed."
+	completion := metav1.NewTime(start.Add(time.Hour))
+	pj.Status.CompletionTime = &completion
+	if err := c.reportCheckRun(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj); err != nil {
+		t.Fatalf("reporting failed job failed: %v", err)
+	}
+	expected[0].Status = "completed"
+	expected[0].Conclusion = "failure"
+	expected[0].CompletedAt = "2024-01-01T11:00:00Z"
+	expected[0].Output = github.CheckRunOutput{
+		Title:   "my-job failure",
+		Summary: "Job failed.\n\n[View job on Prow](https://prow.example.com/view/1)",
+		Text:    "Last 2 lines of the build log:\n\n```\ntwo\nthree\n```",
+	}
+	if diff := cmp.Diff(expected, fghc.CheckRuns["org/repo"]); diff != "" {
+		t.Errorf("check runs differ from expected after failure report: %s", diff)
+	}
+	if len(fghc.CreatedStatuses) != 0 {
+		t.Errorf("expected no status contexts, got %v", fghc.CreatedStatuses)
+	}
+
+	// A new run of the job gets its own check run.
+	pj.Name = "def"
+	if err := c.reportCheckRun(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj); err != nil {
+		t.Fatalf("reporting new run failed: %v", err)
+	}
+	if n := len(fghc.CheckRuns["org/repo"]); n != 2 {
+		t.Errorf("expected 2 check runs, got %d", n)
+	}
+}
+
+func TestReportUsesStatusesOutsideCheckRunRepos(t *testing.T) {
+	fghc := fakegithub.NewFakeClient()
+	c := NewReporter(fghc, checkRunTestConfig, "", nil, nil)
+	pj := &v1.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "abc"},
+		Spec: v1.ProwJobSpec{
+			Type:    v1.PresubmitJob,
+			Context: "my-context",
+			Report:  true,
+			Refs: &v1.Refs{
+				Org:   "org",
+				Repo:  "other",
+				Pulls: []v1.Pull{{Number: 1, SHA: "sha"}},
+			},
+		},
+		Status: v1.ProwJobStatus{State: v1.PendingState},
+	}
+	if _, _, err := c.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj); err != nil {
+		t.Fatalf("reporting failed: %v", err)
+	}
+	if len(fghc.CheckRuns) != 0 {
+		t.Errorf("expected no check runs, got %v", fghc.CheckRuns)
+	}
+	if len(fghc.CreatedStatuses["sha"]) != 1 {
+		t.Errorf("expected one status context, got %v", fghc.CreatedStatuses)
+	}
+}
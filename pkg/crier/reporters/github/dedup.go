@@ -0,0 +1,122 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"sigs.k8s.io/prow/pkg/github"
+)
+
+var skippedStatuses = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "crier_github_reporter_redundant_statuses_skipped",
+	Help: "Count of status updates the github reporter skipped because they didn't change the status of the commit.",
+})
+
+func init() {
+	prometheus.MustRegister(skippedStatuses)
+}
+
+// StatusDeduplicator is a GitHubClient that skips creating a status if the
+// last status it created for the same commit and context within the TTL has
+// the same state, description and target URL. GitHub keeps every status it
+// is sent, so identical consecutive updates only cost API calls and count
+// against the limit of statuses per commit and context.
+//
+// Statuses created by anyone else aren't seen, so the TTL should be short.
+type StatusDeduplicator struct {
+	GitHubClient
+
+	lock      sync.Mutex
+	ttl       time.Duration
+	now       func() time.Time
+	statuses  map[statusKey]cachedStatus
+	lastPrune time.Time
+}
+
+type statusKey struct {
+	org, repo, sha, context string
+}
+
+type cachedStatus struct {
+	state, description, targetURL string
+	created                       time.Time
+}
+
+// NewStatusDeduplicator wraps the client to skip redundant status updates
+// for the given TTL.
+func NewStatusDeduplicator(gc GitHubClient, ttl time.Duration) *StatusDeduplicator {
+	return &StatusDeduplicator{
+		GitHubClient: gc,
+		ttl:          ttl,
+		now:          time.Now,
+		statuses:     map[statusKey]cachedStatus{},
+	}
+}
+
+// CreateStatusWithContext creates the status unless it is the same as the
+// last one created for the commit and context.
+func (d *StatusDeduplicator) CreateStatusWithContext(ctx context.Context, org, repo, ref string, s github.Status) error {
+	key := statusKey{org: org, repo: repo, sha: ref, context: s.Context}
+	status := cachedStatus{state: s.State, description: s.Description, targetURL: s.TargetURL}
+
+	d.lock.Lock()
+	cached, ok := d.statuses[key]
+	d.lock.Unlock()
+	if ok && d.now().Sub(cached.created) < d.ttl {
+		cached.created = time.Time{}
+		if cached == status {
+			skippedStatuses.Inc()
+			return nil
+		}
+	}
+
+	if err := d.GitHubClient.CreateStatusWithContext(ctx, org, repo, ref, s); err != nil {
+		// The status GitHub has is unknown now, so the next update
+		// must not be skipped.
+		d.lock.Lock()
+		delete(d.statuses, key)
+		d.lock.Unlock()
+		return err
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	now := d.now()
+	status.created = now
+	d.statuses[key] = status
+	d.prune(now)
+	return nil
+}
+
+// prune drops the expired statuses at most once per TTL, so that the cache
+// doesn't grow with every commit ever reported.
+func (d *StatusDeduplicator) prune(now time.Time) {
+	if now.Sub(d.lastPrune) < d.ttl {
+		return
+	}
+	for key, status := range d.statuses {
+		if now.Sub(status.created) >= d.ttl {
+			delete(d.statuses, key)
+		}
+	}
+	d.lastPrune = now
+}
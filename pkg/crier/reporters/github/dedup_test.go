@@ -0,0 +1,177 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/github/fakegithub"
+)
+
+// countingStatusClient counts the statuses that are sent to GitHub.
+type countingStatusClient struct {
+	*fakegithub.FakeClient
+	created int
+	err     error
+}
+
+func (c *countingStatusClient) CreateStatusWithContext(ctx context.Context, org, repo, ref string, s github.Status) error {
+	c.created++
+	if c.err != nil {
+		return c.err
+	}
+	return c.FakeClient.CreateStatusWithContext(ctx, org, repo, ref, s)
+}
+
+func TestStatusDeduplicator(t *testing.T) {
+	status := github.Status{State: github.StatusPending, Description: "Job triggered.", Context: "unit", TargetURL: "https://prow.example.com/unit-1"}
+	testCases := []struct {
+		name     string
+		second   github.Status
+		sha      string
+		elapsed  time.Duration
+		failWith error
+		expected int
+	}{
+		{
+			name:     "unchanged status is skipped",
+			second:   status,
+			sha:      "abc",
+			elapsed:  time.Minute,
+			expected: 1,
+		},
+		{
+			name:     "changed state is created",
+			second:   github.Status{State: github.StatusSuccess, Description: "Job triggered.", Context: "unit", TargetURL: "https://prow.example.com/unit-1"},
+			sha:      "abc",
+			expected: 2,
+		},
+		{
+			name:     "changed description is created",
+			second:   github.Status{State: github.StatusPending, Description: "Job running.", Context: "unit", TargetURL: "https://prow.example.com/unit-1"},
+			sha:      "abc",
+			expected: 2,
+		},
+		{
+			name:     "changed target URL of a rerun is created",
+			second:   github.Status{State: github.StatusPending, Description: "Job triggered.", Context: "unit", TargetURL: "https://prow.example.com/unit-2"},
+			sha:      "abc",
+			expected: 2,
+		},
+		{
+			name:     "other context is created",
+			second:   github.Status{State: github.StatusPending, Description: "Job triggered.", Context: "lint", TargetURL: "https://prow.example.com/unit-1"},
+			sha:      "abc",
+			expected: 2,
+		},
+		{
+			name:     "other commit is created",
+			second:   status,
+			sha:      "def",
+			expected: 2,
+		},
+		{
+			name:     "unchanged status is created after the TTL",
+			second:   status,
+			sha:      "abc",
+			elapsed:  5 * time.Minute,
+			expected: 2,
+		},
+		{
+			name:     "unchanged status is created after a failure",
+			second:   status,
+			sha:      "abc",
+			failWith: errors.New("injected"),
+			expected: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			now := time.Now()
+			client := &countingStatusClient{FakeClient: fakegithub.NewFakeClient()}
+			d := NewStatusDeduplicator(client, 5*time.Minute)
+			d.now = func() time.Time { return now }
+			ctx := context.Background()
+
+			if err := d.CreateStatusWithContext(ctx, "org", "repo", "abc", status); err != nil {
+				t.Fatalf("CreateStatusWithContext: %v", err)
+			}
+			if tc.failWith != nil {
+				client.err = tc.failWith
+				if err := d.CreateStatusWithContext(ctx, "org", "repo", "abc", github.Status{State: github.StatusSuccess, Context: "unit"}); err == nil {
+					t.Fatal("expected an error")
+				}
+				client.err = nil
+			}
+			now = now.Add(tc.elapsed)
+			if err := d.CreateStatusWithContext(ctx, "org", "repo", tc.sha, tc.second); err != nil {
+				t.Fatalf("CreateStatusWithContext: %v", err)
+			}
+			if client.created != tc.expected {
+				t.Errorf("expected %d statuses to be created, got %d", tc.expected, client.created)
+			}
+		})
+	}
+}
+
+func TestReportSkipsUnchangedStatus(t *testing.T) {
+	pj := &v1.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "unit-1"},
+		Spec: v1.ProwJobSpec{
+			Type:    v1.PresubmitJob,
+			Job:     "unit",
+			Context: "unit",
+			Report:  true,
+			Refs: &v1.Refs{
+				Org:   "org",
+				Repo:  "repo",
+				Pulls: []v1.Pull{{Number: 1, SHA: "abc"}},
+			},
+		},
+		Status: v1.ProwJobStatus{
+			State:       v1.PendingState,
+			Description: "Job triggered.",
+			URL:         "https://prow.example.com/unit-1",
+		},
+	}
+	client := &countingStatusClient{FakeClient: fakegithub.NewFakeClient()}
+	reporter := NewReporter(NewStatusDeduplicator(client, time.Minute), func() *config.Config {
+		return &config.Config{ProwConfig: config.ProwConfig{GitHubReporter: config.GitHubReporter{
+			JobTypesToReport: []v1.ProwJobType{v1.PresubmitJob},
+			NoCommentRepos:   []string{"org"},
+		}}}
+	}, "", nil, nil)
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := reporter.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj); err != nil {
+			t.Fatalf("Report: %v", err)
+		}
+	}
+	if client.created != 1 {
+		t.Errorf("expected the unchanged status to be created once, got %d", client.created)
+	}
+}
@@ -0,0 +1,259 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	stdio "io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	gcsutil "sigs.k8s.io/prow/pkg/crier/reporters/gcs/util"
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/io"
+	"sigs.k8s.io/prow/pkg/io/providers"
+)
+
+const (
+	// maxAnnotationsPerRequest is the maximum number of annotations GitHub
+	// accepts per create or update of a check run.
+	maxAnnotationsPerRequest = 50
+	// maxJUnitAnnotations caps the annotations of a check run, so that jobs
+	// with thousands of failed tests don't need hundreds of requests.
+	maxJUnitAnnotations = 500
+	// maxListedFailures is the number of failed tests listed in the summary
+	// of a check run.
+	maxListedFailures = 20
+	// maxListedMessageLength is the length failure messages are cut to when
+	// listed in the summary.
+	maxListedMessageLength = 200
+	// maxAnnotationMessageLength is the maximum length GitHub accepts for
+	// the message and raw details of an annotation.
+	maxAnnotationMessageLength = 64 * 1024
+)
+
+// junitSuite is a JUnit test suite, or a list of them. Unlike the JUnit
+// types of testgrid it keeps the file and line of test cases, which only
+// some tools, e.g. pytest, write.
+type junitSuite struct {
+	Suites []junitSuite `xml:"testsuite"`
+	Cases  []junitCase  `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr"`
+	Line      string        `xml:"line,attr"`
+	Failure   *junitFailure `xml:"failure"`
+	Error     *junitFailure `xml:"error"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Details string `xml:",chardata"`
+}
+
+// failedTest is a failed or errored test case of a JUnit file. The file is
+// relative to the root of the repo and empty if the location is unknown.
+type failedTest struct {
+	name    string
+	file    string
+	line    int
+	message string
+	details string
+}
+
+// failedTests returns the failed tests of the JUnit files below the job's
+// directory whose path relative to it matches glob. Files that can't be
+// read or parsed are skipped.
+func (c *Client) failedTests(ctx context.Context, log *logrus.Entry, pj *v1.ProwJob, glob string) ([]failedTest, error) {
+	bucket, dir, err := gcsutil.GetJobDestination(c.config, pj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job destination: %w", err)
+	}
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+	prefixPath, err := providers.StoragePath(bucket, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s path: %w", prefix, err)
+	}
+	it, err := c.opener.Iterator(ctx, prefixPath, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", prefixPath, err)
+	}
+
+	var tests []failedTest
+	for {
+		attrs, err := it.Next(ctx)
+		if err == stdio.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", prefixPath, err)
+		}
+		if matched, _ := path.Match(glob, strings.TrimPrefix(attrs.Name, prefix)); !matched {
+			continue
+		}
+		filePath, err := providers.StoragePath(bucket, attrs.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s path: %w", attrs.Name, err)
+		}
+		content, err := io.ReadContent(ctx, log, c.opener, filePath)
+		if err != nil {
+			log.WithError(err).WithField("path", filePath).Warn("Failed to read JUnit file, skipping it")
+			continue
+		}
+		var suite junitSuite
+		if err := xml.Unmarshal(content, &suite); err != nil {
+			log.WithError(err).WithField("path", filePath).Warn("Failed to parse JUnit file, skipping it")
+			continue
+		}
+		tests = appendFailedTests(tests, suite, pj.Spec.Refs)
+	}
+	return tests, nil
+}
+
+func appendFailedTests(tests []failedTest, suite junitSuite, refs *v1.Refs) []failedTest {
+	for _, s := range suite.Suites {
+		tests = appendFailedTests(tests, s, refs)
+	}
+	for _, tc := range suite.Cases {
+		failure := tc.Failure
+		if failure == nil {
+			failure = tc.Error
+		}
+		if failure == nil {
+			continue
+		}
+		test := failedTest{
+			name:    tc.Name,
+			message: strings.TrimSpace(failure.Message),
+			details: strings.TrimSpace(failure.Details),
+		}
+		if tc.ClassName != "" {
+			test.name = tc.ClassName + "." + tc.Name
+		}
+		if line, err := strconv.Atoi(tc.Line); err == nil && line > 0 {
+			if file, ok := repoRelativePath(tc.File, refs); ok {
+				test.file = file
+				test.line = line
+			}
+		}
+		tests = append(tests, test)
+	}
+	return tests
+}
+
+// repoRelativePath returns the path of the file relative to the root of the
+// repo, which is what GitHub annotates. Absolute paths are only known to be
+// in the repo if they contain its org and name, as checkouts of the pod
+// utilities do, e.g. /home/prow/go/src/github.com/org/repo/pkg/foo.go.
+func repoRelativePath(file string, refs *v1.Refs) (string, bool) {
+	if file == "" {
+		return "", false
+	}
+	if !path.IsAbs(file) {
+		file = path.Clean(file)
+		if file == ".." || strings.HasPrefix(file, "../") {
+			return "", false
+		}
+		return file, true
+	}
+	if refs == nil {
+		return "", false
+	}
+	repoDir := "/" + refs.Org + "/" + refs.Repo + "/"
+	if refs.PathAlias != "" {
+		repoDir = "/" + refs.PathAlias + "/"
+	}
+	i := strings.LastIndex(file, repoDir)
+	if i < 0 {
+		return "", false
+	}
+	return file[i+len(repoDir):], true
+}
+
+// annotateFailedTests adds annotations for the failed tests with a location
+// to the output and lists all failed tests in its summary, starting with the
+// ones that couldn't be annotated.
+func annotateFailedTests(output *github.CheckRunOutput, tests []failedTest) {
+	if len(tests) == 0 {
+		return
+	}
+	for _, test := range tests {
+		if test.file == "" || len(output.Annotations) == maxJUnitAnnotations {
+			continue
+		}
+		message := test.message
+		if message == "" {
+			message = "Test failed."
+		}
+		output.Annotations = append(output.Annotations, github.CheckRunAnnotation{
+			Path:            test.file,
+			StartLine:       test.line,
+			EndLine:         test.line,
+			AnnotationLevel: "failure",
+			Title:           test.name,
+			Message:         truncate(message, maxAnnotationMessageLength),
+			RawDetails:      truncate(test.details, maxAnnotationMessageLength),
+		})
+	}
+
+	var summary strings.Builder
+	fmt.Fprintf(&summary, "\n\n%d failed tests", len(tests))
+	if n := len(output.Annotations); n > 0 {
+		fmt.Fprintf(&summary, ", %d of them annotated in the changed files", n)
+	}
+	summary.WriteString(":\n")
+	listed := make([]failedTest, 0, len(tests))
+	for _, test := range tests {
+		if test.file == "" {
+			listed = append(listed, test)
+		}
+	}
+	for _, test := range tests {
+		if test.file != "" {
+			listed = append(listed, test)
+		}
+	}
+	for i, test := range listed {
+		if i == maxListedFailures {
+			fmt.Fprintf(&summary, "* and %d more\n", len(tests)-i)
+			break
+		}
+		fmt.Fprintf(&summary, "* `%s`", test.name)
+		if test.file != "" {
+			fmt.Fprintf(&summary, " at `%s:%d`", test.file, test.line)
+		}
+		if message := strings.Join(strings.Fields(test.message), " "); message != "" {
+			if len(message) > maxListedMessageLength {
+				message = message[:maxListedMessageLength-3] + "..."
+			}
+			fmt.Fprintf(&summary, ": %s", message)
+		}
+		summary.WriteString("\n")
+	}
+	if len(output.Summary)+summary.Len() <= maxCheckRunOutputLength {
+		output.Summary += strings.TrimSuffix(summary.String(), "\n")
+	}
+}
@@ -0,0 +1,269 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	stdio "io"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/github/fakegithub"
+	"sigs.k8s.io/prow/pkg/io"
+	"sigs.k8s.io/prow/pkg/io/fakeopener"
+)
+
+// iterableOpener lists the files of the fake opener, which are all in the
+// gs://bucket bucket.
+type iterableOpener struct {
+	*fakeopener.FakeOpener
+}
+
+func (o *iterableOpener) Iterator(_ context.Context, prefix, _ string) (io.ObjectIterator, error) {
+	var names []string
+	for p := range o.Buffer {
+		if strings.HasPrefix(p, prefix) {
+			names = append(names, strings.TrimPrefix(p, "gs://bucket/"))
+		}
+	}
+	sort.Strings(names)
+	return &listIterator{names: names}, nil
+}
+
+type listIterator struct {
+	names []string
+}
+
+func (it *listIterator) Next(_ context.Context) (io.ObjectAttributes, error) {
+	if len(it.names) == 0 {
+		return io.ObjectAttributes{}, stdio.EOF
+	}
+	name := it.names[0]
+	it.names = it.names[1:]
+	return io.ObjectAttributes{Name: name}, nil
+}
+
+// annotationBatchClient records the number of annotations sent per request.
+type annotationBatchClient struct {
+	*fakegithub.FakeClient
+	batches []int
+}
+
+func (c *annotationBatchClient) CreateCheckRun(org, repo string, checkRun github.CheckRun) (int64, error) {
+	c.batches = append(c.batches, len(checkRun.Output.Annotations))
+	return c.FakeClient.CreateCheckRun(org, repo, checkRun)
+}
+
+func (c *annotationBatchClient) UpdateCheckRun(org, repo string, id int64, checkRun github.CheckRun) error {
+	c.batches = append(c.batches, len(checkRun.Output.Annotations))
+	return c.FakeClient.UpdateCheckRun(org, repo, id, checkRun)
+}
+
+func TestRepoRelativePath(t *testing.T) {
+	refs := &v1.Refs{Org: "org", Repo: "repo"}
+	testCases := []struct {
+		name       string
+		file       string
+		refs       *v1.Refs
+		expected   string
+		expectedOK bool
+	}{
+		{
+			name: "no file",
+			refs: refs,
+		},
+		{
+			name:       "relative path",
+			file:       "./pkg/foo_test.py",
+			refs:       refs,
+			expected:   "pkg/foo_test.py",
+			expectedOK: true,
+		},
+		{
+			name: "relative path outside the repo",
+			file: "../other/foo_test.py",
+			refs: refs,
+		},
+		{
+			name:       "absolute path in the checkout",
+			file:       "/home/prow/go/src/github.com/org/repo/pkg/foo_test.go",
+			refs:       refs,
+			expected:   "pkg/foo_test.go",
+			expectedOK: true,
+		},
+		{
+			name:       "absolute path in the checkout of a path alias",
+			file:       "/home/prow/go/src/k8s.io/repo/pkg/foo_test.go",
+			refs:       &v1.Refs{Org: "org", Repo: "repo", PathAlias: "k8s.io/repo"},
+			expected:   "pkg/foo_test.go",
+			expectedOK: true,
+		},
+		{
+			name: "absolute path outside the checkout",
+			file: "/usr/lib/go/src/testing/testing.go",
+			refs: refs,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			file, ok := repoRelativePath(tc.file, tc.refs)
+			if file != tc.expected || ok != tc.expectedOK {
+				t.Errorf("expected (%q, %t), got (%q, %t)", tc.expected, tc.expectedOK, file, ok)
+			}
+		})
+	}
+}
+
+func TestReportCheckRunAnnotations(t *testing.T) {
+	var located strings.Builder
+	for i := 1; i <= 60; i++ {
+		fmt.Fprintf(&located, `<testcase classname="pkg" name="test_%d" file="pkg/foo_test.py" line="%d"><failure message="assert %d == 0">details</failure></testcase>`, i, i, i)
+	}
+	dir := "gs://bucket/pr-logs/pull/org_repo/1/my-job/123/"
+	opener := &iterableOpener{FakeOpener: &fakeopener.FakeOpener{Buffer: map[string]*bytes.Buffer{
+		dir + "build-log.txt":             bytes.NewBufferString("one\ntwo\nthree\n"),
+		dir + "artifacts/junit_1.xml":     bytes.NewBufferString(`<testsuite name="a">` + located.String() + `<testcase name="passed"></testcase></testsuite>`),
+		dir + "artifacts/junit_2.xml":     bytes.NewBufferString(`<testsuites><testsuite name="b"><testcase name="TestGo"><error message="panic: boom"></error></testcase></testsuite></testsuites>`),
+		dir + "artifacts/junit_bad.xml":   bytes.NewBufferString(`<testsuite`),
+		dir + "artifacts/other/junit.xml": bytes.NewBufferString(`<testsuite><testcase name="ignored"><failure></failure></testcase></testsuite>`),
+	}}}
+	cfg := func() *config.Config {
+		c := checkRunTestConfig()
+		c.GitHubReporter.CheckRunLogLines = 0
+		c.GitHubReporter.CheckRunJUnitGlob = "artifacts/junit*.xml"
+		return c
+	}
+	fghc := &annotationBatchClient{FakeClient: fakegithub.NewFakeClient()}
+	c := &Client{gc: fghc, config: cfg, opener: opener}
+	completion := metav1.NewTime(time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC))
+	pj := &v1.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "abc"},
+		Spec: v1.ProwJobSpec{
+			Type:    v1.PresubmitJob,
+			Job:     "my-job",
+			Context: "my-context",
+			Report:  true,
+			Refs: &v1.Refs{
+				Org:   "org",
+				Repo:  "repo",
+				Pulls: []v1.Pull{{Number: 1, SHA: "sha"}},
+			},
+		},
+		Status: v1.ProwJobStatus{
+			State:          v1.FailureState,
+			Description:    "Job failed.",
+			CompletionTime: &completion,
+			BuildID:        "123",
+		},
+	}
+
+	if err := c.reportCheckRun(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj); err != nil {
+		t.Fatalf("reporting failed job failed: %v", err)
+	}
+	if diff := cmp.Diff([]int{50, 10}, fghc.batches); diff != "" {
+		t.Errorf("annotations per request differ from expected (-want +got):\n%s", diff)
+	}
+	runs := fghc.CheckRuns["org/repo"]
+	if len(runs) != 1 {
+		t.Fatalf("expected one check run, got %d", len(runs))
+	}
+	annotations := runs[0].Output.Annotations
+	if len(annotations) != 60 {
+		t.Fatalf("expected 60 annotations, got %d", len(annotations))
+	}
+	expectedFirst := github.CheckRunAnnotation{
+		Path:            "pkg/foo_test.py",
+		StartLine:       1,
+		EndLine:         1,
+		AnnotationLevel: "failure",
+		Title:           "pkg.test_1",
+		Message:         "assert 1 == 0",
+		RawDetails:      "details",
+	}
+	if diff := cmp.Diff(expectedFirst, annotations[0]); diff != "" {
+		t.Errorf("first annotation differs from expected (-want +got):\n%s", diff)
+	}
+	if last := annotations[59]; last.Title != "pkg.test_60" || last.StartLine != 60 {
+		t.Errorf("expected the last annotation for pkg.test_60 at line 60, got %+v", last)
+	}
+
+	summary := runs[0].Output.Summary
+	for _, expected := range []string{
+		"Job failed.\n\n61 failed tests, 60 of them annotated in the changed files:\n",
+		"* `pkg.test_1` at `pkg/foo_test.py:1`: assert 1 == 0\n",
+		"* `TestGo`: panic: boom\n",
+		"* and 41 more",
+	} {
+		if !strings.Contains(summary, expected) {
+			t.Errorf("expected summary to contain %q, got:\n%s", expected, summary)
+		}
+	}
+	if strings.Contains(summary, "ignored") {
+		t.Errorf("expected JUnit files not matching the glob to be ignored, got:\n%s", summary)
+	}
+}
+
+func TestReportCheckRunAnnotationsOnlyForFailures(t *testing.T) {
+	opener := &iterableOpener{FakeOpener: &fakeopener.FakeOpener{Buffer: map[string]*bytes.Buffer{
+		"gs://bucket/pr-logs/pull/org_repo/1/my-job/123/artifacts/junit.xml": bytes.NewBufferString(`<testsuite><testcase name="flaky" file="foo.py" line="1"><failure></failure></testcase></testsuite>`),
+	}}}
+	cfg := func() *config.Config {
+		c := checkRunTestConfig()
+		c.GitHubReporter.CheckRunLogLines = 0
+		c.GitHubReporter.CheckRunJUnitGlob = "artifacts/junit*.xml"
+		return c
+	}
+	fghc := fakegithub.NewFakeClient()
+	c := &Client{gc: fghc, config: cfg, opener: opener}
+	pj := &v1.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "abc"},
+		Spec: v1.ProwJobSpec{
+			Type:    v1.PresubmitJob,
+			Job:     "my-job",
+			Context: "my-context",
+			Report:  true,
+			Refs: &v1.Refs{
+				Org:   "org",
+				Repo:  "repo",
+				Pulls: []v1.Pull{{Number: 1, SHA: "sha"}},
+			},
+		},
+		Status: v1.ProwJobStatus{
+			State:          v1.SuccessState,
+			CompletionTime: &metav1.Time{Time: time.Now()},
+			BuildID:        "123",
+		},
+	}
+	if err := c.reportCheckRun(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj); err != nil {
+		t.Fatalf("reporting job failed: %v", err)
+	}
+	if output := fghc.CheckRuns["org/repo"][0].Output; len(output.Annotations) != 0 || strings.Contains(output.Summary, "failed tests") {
+		t.Errorf("expected no failed tests for a successful job, got %+v", output)
+	}
+}
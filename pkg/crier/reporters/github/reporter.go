@@ -22,6 +22,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
@@ -32,7 +33,9 @@ import (
 	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
 	"sigs.k8s.io/prow/pkg/config"
 	"sigs.k8s.io/prow/pkg/crier/reporters/criercommonlib"
+	"sigs.k8s.io/prow/pkg/github"
 	"sigs.k8s.io/prow/pkg/github/report"
+	"sigs.k8s.io/prow/pkg/io"
 	"sigs.k8s.io/prow/pkg/kube"
 )
 
@@ -43,21 +46,26 @@ const (
 
 // Client is a github reporter client
 type Client struct {
-	gc          report.GitHubClient
+	gc          GitHubClient
 	config      config.Getter
 	reportAgent v1.ProwJobAgent
 	prLocks     *criercommonlib.ShardedLock
 	lister      ctrlruntimeclient.Reader
+	// opener is used to read build logs for check run outputs and to sign
+	// build log URLs, it may be nil.
+	opener io.Opener
 }
 
-// NewReporter returns a reporter client
-func NewReporter(gc report.GitHubClient, cfg config.Getter, reportAgent v1.ProwJobAgent, lister ctrlruntimeclient.Reader) *Client {
+// NewReporter returns a reporter client. The opener is used to include the
+// end of build logs in check runs and to sign build log URLs, and may be nil.
+func NewReporter(gc GitHubClient, cfg config.Getter, reportAgent v1.ProwJobAgent, lister ctrlruntimeclient.Reader, opener io.Opener) *Client {
 	c := &Client{
 		gc:          gc,
 		config:      cfg,
 		reportAgent: reportAgent,
 		prLocks:     criercommonlib.NewShardedLock(),
 		lister:      lister,
+		opener:      opener,
 	}
 	c.prLocks.RunCleanup()
 	return c
@@ -68,6 +76,19 @@ func (c *Client) GetName() string {
 	return GitHubReporterName
 }
 
+// ReportTarget returns the repo and commit the job is reported on.
+func (c *Client) ReportTarget(pj *v1.ProwJob) string {
+	if pj.Spec.Refs == nil {
+		return ""
+	}
+	refs := pj.Spec.Refs
+	sha := refs.BaseSHA
+	if len(refs.Pulls) > 0 {
+		sha = refs.Pulls[0].SHA
+	}
+	return fmt.Sprintf("%s/%s@%s", refs.Org, refs.Repo, sha)
+}
+
 // ShouldReport returns if this prowjob should be reported by the github reporter
 func (c *Client) ShouldReport(_ context.Context, _ *logrus.Entry, pj *v1.ProwJob) bool {
 	if !pj.Spec.Report {
@@ -88,11 +109,43 @@ func (c *Client) ShouldReport(_ context.Context, _ *logrus.Entry, pj *v1.ProwJob
 
 // Report will report via reportlib
 func (c *Client) Report(ctx context.Context, log *logrus.Entry, pj *v1.ProwJob) ([]*v1.ProwJob, *reconcile.Result, error) {
+	pjs, result, err := c.report(ctx, log, pj)
+	return pjs, result, classifyError(err)
+}
+
+// classifyError tells crier whether retrying a failed report can succeed.
+// Requests GitHub rejected as invalid fail the same way on every retry, while
+// server errors and rate limits usually go away.
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch code := github.StatusCode(err); {
+	case code == http.StatusBadRequest, code == http.StatusNotFound, code == http.StatusGone, code == http.StatusUnprocessableEntity:
+		return criercommonlib.PermanentError(err)
+	case code == http.StatusTooManyRequests, code >= 500:
+		return criercommonlib.TransientError(err)
+	}
+	return err
+}
+
+func (c *Client) report(ctx context.Context, log *logrus.Entry, pj *v1.ProwJob) ([]*v1.ProwJob, *reconcile.Result, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
 	defer cancel()
 
 	// TODO(krzyzacy): ditch ReportTemplate, and we can drop reference to config.Getter
-	err := report.ReportStatusContext(ctx, c.gc, *pj, c.config().GitHubReporter)
+	var err error
+	rollup := c.reportsRollup(pj)
+	switch {
+	case rollup && c.config().GitHubReporter.RollupOnly:
+	case c.config().GitHubReporter.ReportsCheckRuns(pj.Spec.Refs.Org, pj.Spec.Refs.Repo):
+		err = c.reportCheckRun(ctx, log, pj)
+	default:
+		err = report.ReportStatusContext(ctx, c.gc, *pj, c.config().GitHubReporter)
+	}
+	if err == nil && rollup {
+		err = c.reportRollup(ctx, log, pj)
+	}
 	if err != nil {
 		if strings.Contains(err.Error(), "This SHA and context has reached the maximum number of statuses") {
 			// This is completely unrecoverable, so just swallow the error to make sure we wont retry, even when crier gets restarted.
@@ -135,6 +188,9 @@ func (c *Client) Report(ctx context.Context, log *logrus.Entry, pj *v1.ProwJob)
 			return []*v1.ProwJob{pj}, nil, nil
 		}
 	}
+	if err := c.reportArtifactsComment(ctx, log, pj); err != nil {
+		return []*v1.ProwJob{pj}, nil, err
+	}
 	// Check if this org or repo has opted out of failure report comments
 	toReport := []v1.ProwJob{*pj}
 	var mustCreateComment bool
@@ -19,6 +19,7 @@ package github
 import (
 	"context"
 	"errors"
+	"net/http"
 	"sync"
 	"testing"
 	"time"
@@ -30,6 +31,8 @@ import (
 
 	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
 	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/crier/reporters/criercommonlib"
+	"sigs.k8s.io/prow/pkg/github"
 	"sigs.k8s.io/prow/pkg/github/fakegithub"
 	"sigs.k8s.io/prow/pkg/kube"
 
@@ -101,7 +104,7 @@ func TestShouldReport(t *testing.T) {
 
 	for _, tc := range testcases {
 		t.Run(tc.name, func(t *testing.T) {
-			c := NewReporter(nil, nil, tc.reportAgent, nil)
+			c := NewReporter(nil, nil, tc.reportAgent, nil, nil)
 			if r := c.ShouldReport(context.Background(), logrus.NewEntry(logrus.StandardLogger()), &tc.pj); r == tc.report {
 				return
 			}
@@ -132,6 +135,7 @@ func TestPresumitReportingLocks(t *testing.T) {
 		},
 		v1.ProwJobAgent(""),
 		nil,
+		nil,
 	)
 
 	pj := &v1.ProwJob{
@@ -175,6 +179,7 @@ func TestReport(t *testing.T) {
 		createStatusContextError          error
 		listIssueCommentsWithContextError error
 		expectedError                     string
+		expectedErrorClass                string
 	}{
 		{
 			name: "Success",
@@ -215,6 +220,19 @@ func TestReport(t *testing.T) {
 			name:                              "Comment error_Other error get returned",
 			listIssueCommentsWithContextError: errors.New("something went wrong :("),
 			expectedError:                     "error listing comments: something went wrong :(",
+			expectedErrorClass:                criercommonlib.ErrorClassUnclassified,
+		},
+		{
+			name:                     "Invalid request is permanent",
+			createStatusContextError: github.NewRequestError(http.StatusUnprocessableEntity, "validation failed"),
+			expectedError:            "error setting status: validation failed",
+			expectedErrorClass:       criercommonlib.ErrorClassPermanent,
+		},
+		{
+			name:                              "Server error is transient",
+			listIssueCommentsWithContextError: github.NewRequestError(http.StatusBadGateway, "bad gateway"),
+			expectedError:                     "error listing comments: bad gateway",
+			expectedErrorClass:                criercommonlib.ErrorClassTransient,
 		},
 	}
 
@@ -259,6 +277,11 @@ func TestReport(t *testing.T) {
 			if errMsg != tc.expectedError {
 				t.Errorf("expected error %q got error %q", tc.expectedError, errMsg)
 			}
+			if tc.expectedErrorClass != "" {
+				if class := criercommonlib.ErrorClass(err); class != tc.expectedErrorClass {
+					t.Errorf("expected error class %q got %q", tc.expectedErrorClass, class)
+				}
+			}
 		})
 	}
 }
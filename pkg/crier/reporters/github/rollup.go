@@ -0,0 +1,102 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/github/report"
+	"sigs.k8s.io/prow/pkg/kube"
+)
+
+// reportsRollup returns whether the job is rolled up into the rollup status
+// of its pull request.
+func (c *Client) reportsRollup(pj *v1.ProwJob) bool {
+	cfg := c.config().GitHubReporter
+	return pj.Spec.Type == v1.PresubmitJob &&
+		len(pj.Spec.Refs.Pulls) == 1 &&
+		report.ShouldReport(*pj, cfg.JobTypesToReport) &&
+		cfg.ReportsRollup(pj.Spec.Refs.Org, pj.Spec.Refs.Repo)
+}
+
+// reportRollup updates the rollup status of the pull request commit the job
+// ran for. The status is computed and written while holding the lock of the
+// pull request, so that reports of jobs finishing at the same time don't
+// overwrite it with an outdated state.
+func (c *Client) reportRollup(ctx context.Context, log *logrus.Entry, pj *v1.ProwJob) error {
+	key, err := lockKeyForPJ(pj)
+	if err != nil {
+		return fmt.Errorf("failed to get lockkey for job: %w", err)
+	}
+	lock, err := c.prLocks.GetLock(ctx, *key)
+	if err != nil {
+		return err
+	}
+	if err := lock.Acquire(ctx, 1); err != nil {
+		return err
+	}
+	defer lock.Release(1)
+
+	pjs, err := rollupJobs(ctx, c.lister, pj)
+	if err != nil {
+		return err
+	}
+	log.WithField("jobs", len(pjs)).Debug("Reporting rollup status")
+	return report.ReportRollupStatus(ctx, c.gc, pjs, c.config().GitHubReporter)
+}
+
+// rollupJobs returns the latest run of every reported presubmit of the
+// pull request commit the job ran for, sorted by job name. The job itself
+// is taken as passed rather than from the lister, whose cache may not have
+// caught up with it yet.
+func rollupJobs(ctx context.Context, lister ctrlruntimeclient.Reader, pj *v1.ProwJob) ([]v1.ProwJob, error) {
+	selector := map[string]string{}
+	for _, l := range []string{kube.OrgLabel, kube.RepoLabel, kube.PullLabel} {
+		selector[l] = pj.ObjectMeta.Labels[l]
+	}
+	var pjs v1.ProwJobList
+	if err := lister.List(ctx, &pjs, ctrlruntimeclient.MatchingLabels(selector)); err != nil {
+		return nil, fmt.Errorf("failed to list prowjobs with selector %v: %w", selector, err)
+	}
+
+	sha := pj.Spec.Refs.Pulls[0].SHA
+	latest := map[string]v1.ProwJob{pj.Spec.Job: *pj}
+	for _, sibling := range pjs.Items {
+		if sibling.Name == pj.Name || !sibling.Spec.Report || sibling.Spec.Type != v1.PresubmitJob {
+			continue
+		}
+		if refs := sibling.Spec.Refs; refs == nil || len(refs.Pulls) != 1 || refs.Pulls[0].SHA != sha {
+			continue
+		}
+		if existing, ok := latest[sibling.Spec.Job]; !ok || sibling.CreationTimestamp.After(existing.CreationTimestamp.Time) {
+			latest[sibling.Spec.Job] = sibling
+		}
+	}
+
+	toRollup := make([]v1.ProwJob, 0, len(latest))
+	for _, job := range latest {
+		toRollup = append(toRollup, job)
+	}
+	sort.Slice(toRollup, func(i, j int) bool { return toRollup[i].Spec.Job < toRollup[j].Spec.Job })
+	return toRollup, nil
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/github/fakegithub"
+	"sigs.k8s.io/prow/pkg/kube"
+)
+
+func TestReportRollup(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	presubmit := func(name, job, sha string, state v1.ProwJobState, created time.Time) *v1.ProwJob {
+		return &v1.ProwJob{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				CreationTimestamp: metav1.NewTime(created),
+				Labels: map[string]string{
+					kube.OrgLabel:  "org",
+					kube.RepoLabel: "repo",
+					kube.PullLabel: "1",
+				},
+			},
+			Spec: v1.ProwJobSpec{
+				Type:    v1.PresubmitJob,
+				Job:     job,
+				Context: job,
+				Report:  true,
+				Refs: &v1.Refs{
+					Org:   "org",
+					Repo:  "repo",
+					Pulls: []v1.Pull{{Number: 1, SHA: sha}},
+				},
+			},
+			Status: v1.ProwJobStatus{State: state, URL: "https://prow.example.com/" + name},
+		}
+	}
+
+	testCases := []struct {
+		name        string
+		reporterCfg config.GitHubReporter
+		siblings    []ctrlruntimeclient.Object
+		expected    []github.Status
+	}{
+		{
+			name: "rollup in addition to the job status",
+			reporterCfg: config.GitHubReporter{
+				RollupRepos: []string{"org"},
+			},
+			siblings: []ctrlruntimeclient.Object{
+				// Superseded by the rerun below.
+				presubmit("lint-1", "lint", "abc", v1.FailureState, now.Add(-time.Hour)),
+				presubmit("lint-2", "lint", "abc", v1.SuccessState, now),
+				presubmit("e2e-1", "e2e", "abc", v1.PendingState, now),
+				// Ran for an older commit.
+				presubmit("e2e-0", "e2e", "old", v1.ErrorState, now.Add(-time.Hour)),
+			},
+			expected: []github.Status{
+				{State: github.StatusSuccess, Context: "unit", TargetURL: "https://prow.example.com/unit-1"},
+				{State: github.StatusPending, Description: "1/3 jobs pending", Context: "prow", TargetURL: "https://prow.example.com/e2e-1"},
+			},
+		},
+		{
+			name: "rollup only with custom context",
+			reporterCfg: config.GitHubReporter{
+				RollupRepos:   []string{"org/repo"},
+				RollupContext: "ci/summary",
+				RollupOnly:    true,
+			},
+			siblings: []ctrlruntimeclient.Object{
+				presubmit("lint-1", "lint", "abc", v1.FailureState, now),
+			},
+			expected: []github.Status{
+				{State: github.StatusFailure, Description: "1/2 jobs failed", Context: "ci/summary", TargetURL: "https://prow.example.com/lint-1"},
+			},
+		},
+		{
+			name: "no rollup for other repos",
+			reporterCfg: config.GitHubReporter{
+				RollupRepos: []string{"org/other"},
+			},
+			siblings: []ctrlruntimeclient.Object{
+				presubmit("lint-1", "lint", "abc", v1.FailureState, now),
+			},
+			expected: []github.Status{
+				{State: github.StatusSuccess, Context: "unit", TargetURL: "https://prow.example.com/unit-1"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pj := presubmit("unit-1", "unit", "abc", v1.SuccessState, now)
+			pj.Status.CompletionTime = &metav1.Time{Time: now}
+			tc.reporterCfg.JobTypesToReport = []v1.ProwJobType{v1.PresubmitJob}
+			tc.reporterCfg.NoCommentRepos = []string{"org"}
+
+			fghc := fakegithub.NewFakeClient()
+			lister := fakectrlruntimeclient.NewClientBuilder().WithObjects(tc.siblings...).Build()
+			reporter := NewReporter(fghc, func() *config.Config {
+				return &config.Config{ProwConfig: config.ProwConfig{GitHubReporter: tc.reporterCfg}}
+			}, "", lister, nil)
+			if _, _, err := reporter.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj); err != nil {
+				t.Fatalf("Report: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, fghc.CreatedStatuses["abc"]); diff != "" {
+				t.Errorf("statuses differ from expected (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
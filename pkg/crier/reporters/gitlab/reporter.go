@@ -0,0 +1,170 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gitlab contains a reporter that posts the state of jobs as
+// commit statuses of the commits they ran against on GitLab, and optionally
+// comments on the merge requests of failed presubmits.
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	gitlabclient "sigs.k8s.io/prow/pkg/gitlab"
+)
+
+const (
+	reporterName = "gitlabreporter"
+)
+
+// commitStates maps job states to the state of the commit status.
+var commitStates = map[prowapi.ProwJobState]string{
+	prowapi.TriggeredState: gitlabclient.Pending,
+	prowapi.PendingState:   gitlabclient.Running,
+	prowapi.SuccessState:   gitlabclient.Success,
+	prowapi.FailureState:   gitlabclient.Failed,
+	prowapi.ErrorState:     gitlabclient.Failed,
+	prowapi.AbortedState:   gitlabclient.Canceled,
+}
+
+type gitlabClient interface {
+	SetCommitStatus(ctx context.Context, server, project, sha string, status gitlabclient.CommitStatus) error
+	CreateMergeRequestNote(ctx context.Context, server, project string, iid int, body string) error
+}
+
+type gitlabReporter struct {
+	client gitlabClient
+	config func(*prowapi.Refs) config.GitLabReporter
+	dryRun bool
+}
+
+func (gr *gitlabReporter) Report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) ([]*prowapi.ProwJob, *reconcile.Result, error) {
+	return []*prowapi.ProwJob{pj}, nil, gr.report(ctx, log, pj)
+}
+
+func (gr *gitlabReporter) report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) error {
+	cfg := gr.config(pj.Spec.Refs)
+	project := projectPath(pj.Spec.Refs)
+	sha := commitSHA(pj.Spec.Refs)
+	status := commitStatus(pj)
+	note := failureNote(cfg, pj)
+
+	log = log.WithFields(logrus.Fields{"server": cfg.Server, "project": project, "sha": sha, "state": status.State})
+	if gr.dryRun {
+		log.WithField("note", note).Debug("Skipping reporting because dry-run is enabled")
+		return nil
+	}
+	if err := gr.client.SetCommitStatus(ctx, cfg.Server, project, sha, status); err != nil {
+		log.WithError(err).Error("failed to set GitLab commit status")
+		return fmt.Errorf("failed to set GitLab commit status: %w", err)
+	}
+	if note == "" {
+		return nil
+	}
+	// The status is set again if commenting fails and the report is
+	// retried, which is harmless as it replaces itself.
+	if err := gr.client.CreateMergeRequestNote(ctx, cfg.Server, project, pj.Spec.Refs.Pulls[0].Number, note); err != nil {
+		log.WithError(err).Error("failed to comment on GitLab merge request")
+		return fmt.Errorf("failed to comment on GitLab merge request: %w", err)
+	}
+	return nil
+}
+
+// projectPath returns the path of the GitLab project of the refs, which
+// mirrors the org and repo of the job.
+func projectPath(refs *prowapi.Refs) string {
+	if refs == nil || refs.Org == "" || refs.Repo == "" {
+		return ""
+	}
+	return refs.Org + "/" + refs.Repo
+}
+
+// commitSHA returns the commit the job ran against: the head of the merge
+// request for presubmits and the base commit for jobs without pulls, like
+// postsubmits. Batches test several merge requests at once, so there is no
+// single commit to report their status on and an empty string is returned.
+func commitSHA(refs *prowapi.Refs) string {
+	if refs == nil {
+		return ""
+	}
+	switch len(refs.Pulls) {
+	case 0:
+		return refs.BaseSHA
+	case 1:
+		return refs.Pulls[0].SHA
+	default:
+		return ""
+	}
+}
+
+// commitStatus returns the commit status of the job. The name is the job's
+// context, so that every run of a job replaces the status of the last one.
+func commitStatus(pj *prowapi.ProwJob) gitlabclient.CommitStatus {
+	name := pj.Spec.Context
+	if name == "" {
+		name = pj.Spec.Job
+	}
+	return gitlabclient.CommitStatus{
+		State:       commitStates[pj.Status.State],
+		Name:        name,
+		TargetURL:   pj.Status.URL,
+		Description: pj.Status.Description,
+	}
+}
+
+// failureNote returns the comment for the merge request of a presubmit that
+// failed, or an empty string if no comment should be posted.
+func failureNote(cfg config.GitLabReporter, pj *prowapi.ProwJob) string {
+	if !cfg.CommentOnFailure || pj.Spec.Type != prowapi.PresubmitJob || pj.Spec.Refs == nil || len(pj.Spec.Refs.Pulls) != 1 {
+		return ""
+	}
+	if pj.Status.State != prowapi.FailureState && pj.Status.State != prowapi.ErrorState {
+		return ""
+	}
+	note := fmt.Sprintf("Job `%s` ended with state `%s` on commit %s.", pj.Spec.Job, pj.Status.State, pj.Spec.Refs.Pulls[0].SHA)
+	if pj.Status.URL != "" {
+		note += fmt.Sprintf(" [View logs](%s)", pj.Status.URL)
+	}
+	return note
+}
+
+func (gr *gitlabReporter) GetName() string {
+	return reporterName
+}
+
+func (gr *gitlabReporter) ShouldReport(_ context.Context, logger *logrus.Entry, pj *prowapi.ProwJob) bool {
+	cfg := gr.config(pj.Spec.Refs)
+
+	_, knownState := commitStates[pj.Status.State]
+	shouldReport := slices.Contains(cfg.JobTypesToReport, pj.Spec.Type) && knownState && cfg.Server != "" && projectPath(pj.Spec.Refs) != "" && commitSHA(pj.Spec.Refs) != ""
+	logger.WithField("reporting", shouldReport).Debug("Determined should report")
+	return shouldReport
+}
+
+func New(cfg func(refs *prowapi.Refs) config.GitLabReporter, dryRun bool, tokenGenerator func() []byte, httpClient *http.Client) *gitlabReporter {
+	return &gitlabReporter{
+		client: gitlabclient.NewClient(tokenGenerator, httpClient),
+		config: cfg,
+		dryRun: dryRun,
+	}
+}
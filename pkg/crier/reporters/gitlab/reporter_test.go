@@ -0,0 +1,309 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+
+	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	gitlabclient "sigs.k8s.io/prow/pkg/gitlab"
+)
+
+func TestShouldReport(t *testing.T) {
+	cfg := config.GitLabReporter{
+		JobTypesToReport: []v1.ProwJobType{v1.PresubmitJob, v1.PostsubmitJob},
+		Server:           "https://gitlab.example.com",
+	}
+	testCases := []struct {
+		name     string
+		config   config.GitLabReporter
+		pj       *v1.ProwJob
+		expected bool
+	}{
+		{
+			name:   "presubmit with a single pull should report",
+			config: cfg,
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PresubmitJob, Refs: &v1.Refs{Org: "group", Repo: "project", BaseSHA: "base", Pulls: []v1.Pull{{Number: 1, SHA: "head"}}}},
+				Status: v1.ProwJobStatus{State: v1.TriggeredState},
+			},
+			expected: true,
+		},
+		{
+			name:   "postsubmit should report",
+			config: cfg,
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PostsubmitJob, Refs: &v1.Refs{Org: "group", Repo: "project", BaseSHA: "base"}},
+				Status: v1.ProwJobStatus{State: v1.SuccessState},
+			},
+			expected: true,
+		},
+		{
+			name:   "wrong job type should not report",
+			config: cfg,
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PeriodicJob, Refs: &v1.Refs{Org: "group", Repo: "project", BaseSHA: "base"}},
+				Status: v1.ProwJobStatus{State: v1.SuccessState},
+			},
+			expected: false,
+		},
+		{
+			name:   "job without refs should not report",
+			config: cfg,
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PostsubmitJob},
+				Status: v1.ProwJobStatus{State: v1.SuccessState},
+			},
+			expected: false,
+		},
+		{
+			name: "batch should not report",
+			config: config.GitLabReporter{
+				JobTypesToReport: []v1.ProwJobType{v1.BatchJob},
+				Server:           "https://gitlab.example.com",
+			},
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.BatchJob, Refs: &v1.Refs{Org: "group", Repo: "project", BaseSHA: "base", Pulls: []v1.Pull{{Number: 1, SHA: "a"}, {Number: 2, SHA: "b"}}}},
+				Status: v1.ProwJobStatus{State: v1.SuccessState},
+			},
+			expected: false,
+		},
+		{
+			name: "empty config should not report",
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PostsubmitJob, Refs: &v1.Refs{Org: "group", Repo: "project", BaseSHA: "base"}},
+				Status: v1.ProwJobStatus{State: v1.SuccessState},
+			},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reporter := &gitlabReporter{
+				config: func(*v1.Refs) config.GitLabReporter { return tc.config },
+			}
+			if result := reporter.ShouldReport(context.Background(), logrus.NewEntry(logrus.StandardLogger()), tc.pj); result != tc.expected {
+				t.Errorf("expected result to be %t but was %t", tc.expected, result)
+			}
+		})
+	}
+}
+
+type setStatus struct {
+	server  string
+	project string
+	sha     string
+	status  gitlabclient.CommitStatus
+}
+
+type createdNote struct {
+	server  string
+	project string
+	iid     int
+	body    string
+}
+
+type fakeGitLabClient struct {
+	statuses []setStatus
+	notes    []createdNote
+	noteErr  error
+}
+
+func (fgc *fakeGitLabClient) SetCommitStatus(_ context.Context, server, project, sha string, status gitlabclient.CommitStatus) error {
+	fgc.statuses = append(fgc.statuses, setStatus{server: server, project: project, sha: sha, status: status})
+	return nil
+}
+
+func (fgc *fakeGitLabClient) CreateMergeRequestNote(_ context.Context, server, project string, iid int, body string) error {
+	if fgc.noteErr != nil {
+		return fgc.noteErr
+	}
+	fgc.notes = append(fgc.notes, createdNote{server: server, project: project, iid: iid, body: body})
+	return nil
+}
+
+var _ gitlabClient = &fakeGitLabClient{}
+
+func TestReport(t *testing.T) {
+	testCases := []struct {
+		name             string
+		pj               *v1.ProwJob
+		dryRun           bool
+		noteErr          error
+		expectedStatuses []setStatus
+		expectedNotes    []createdNote
+		expectErr        bool
+	}{
+		{
+			name: "pending presubmit is reported running on the head of the merge request",
+			pj: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Job:     "pull-test",
+					Context: "ci/test",
+					Type:    v1.PresubmitJob,
+					Refs:    &v1.Refs{Org: "group", Repo: "project", BaseSHA: "base", Pulls: []v1.Pull{{Number: 7, SHA: "head"}}},
+				},
+				Status: v1.ProwJobStatus{State: v1.PendingState, URL: "https://prow.example.com/view/1", Description: "Job triggered."},
+			},
+			expectedStatuses: []setStatus{{
+				server:  "https://gitlab.example.com",
+				project: "group/project",
+				sha:     "head",
+				status: gitlabclient.CommitStatus{
+					State:       gitlabclient.Running,
+					Name:        "ci/test",
+					TargetURL:   "https://prow.example.com/view/1",
+					Description: "Job triggered.",
+				},
+			}},
+		},
+		{
+			name: "failed presubmit is reported and commented on",
+			pj: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Job:  "pull-test",
+					Type: v1.PresubmitJob,
+					Refs: &v1.Refs{Org: "group", Repo: "project", BaseSHA: "base", Pulls: []v1.Pull{{Number: 7, SHA: "head"}}},
+				},
+				Status: v1.ProwJobStatus{State: v1.FailureState, URL: "https://prow.example.com/view/2"},
+			},
+			expectedStatuses: []setStatus{{
+				server:  "https://gitlab.example.com",
+				project: "group/project",
+				sha:     "head",
+				status: gitlabclient.CommitStatus{
+					State:     gitlabclient.Failed,
+					Name:      "pull-test",
+					TargetURL: "https://prow.example.com/view/2",
+				},
+			}},
+			expectedNotes: []createdNote{{
+				server:  "https://gitlab.example.com",
+				project: "group/project",
+				iid:     7,
+				body:    "Job `pull-test` ended with state `failure` on commit head. [View logs](https://prow.example.com/view/2)",
+			}},
+		},
+		{
+			name: "failed presubmit of a repo without comments is not commented on",
+			pj: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Job:  "pull-test",
+					Type: v1.PresubmitJob,
+					Refs: &v1.Refs{Org: "group", Repo: "other", BaseSHA: "base", Pulls: []v1.Pull{{Number: 7, SHA: "head"}}},
+				},
+				Status: v1.ProwJobStatus{State: v1.ErrorState},
+			},
+			expectedStatuses: []setStatus{{
+				server:  "https://gitlab.com",
+				project: "group/other",
+				sha:     "head",
+				status: gitlabclient.CommitStatus{
+					State: gitlabclient.Failed,
+					Name:  "pull-test",
+				},
+			}},
+		},
+		{
+			name: "aborted postsubmit is reported canceled on the base commit",
+			pj: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Job:  "post-test",
+					Type: v1.PostsubmitJob,
+					Refs: &v1.Refs{Org: "group", Repo: "project", BaseSHA: "base"},
+				},
+				Status: v1.ProwJobStatus{State: v1.AbortedState},
+			},
+			expectedStatuses: []setStatus{{
+				server:  "https://gitlab.example.com",
+				project: "group/project",
+				sha:     "base",
+				status: gitlabclient.CommitStatus{
+					State: gitlabclient.Canceled,
+					Name:  "post-test",
+				},
+			}},
+		},
+		{
+			name:    "failing to comment fails the report",
+			noteErr: errors.New("boom"),
+			pj: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Job:  "pull-test",
+					Type: v1.PresubmitJob,
+					Refs: &v1.Refs{Org: "group", Repo: "project", BaseSHA: "base", Pulls: []v1.Pull{{Number: 7, SHA: "head"}}},
+				},
+				Status: v1.ProwJobStatus{State: v1.FailureState},
+			},
+			expectedStatuses: []setStatus{{
+				server:  "https://gitlab.example.com",
+				project: "group/project",
+				sha:     "head",
+				status: gitlabclient.CommitStatus{
+					State: gitlabclient.Failed,
+					Name:  "pull-test",
+				},
+			}},
+			expectErr: true,
+		},
+		{
+			name:   "dry-run does not report",
+			dryRun: true,
+			pj: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Job:  "pull-test",
+					Type: v1.PresubmitJob,
+					Refs: &v1.Refs{Org: "group", Repo: "project", BaseSHA: "base", Pulls: []v1.Pull{{Number: 7, SHA: "head"}}},
+				},
+				Status: v1.ProwJobStatus{State: v1.FailureState},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fgc := &fakeGitLabClient{noteErr: tc.noteErr}
+			reporter := &gitlabReporter{
+				client: fgc,
+				config: func(r *v1.Refs) config.GitLabReporter {
+					return config.GitLabReporterConfigs{
+						"*":             {Server: "https://gitlab.com"},
+						"group/project": {Server: "https://gitlab.example.com", CommentOnFailure: true},
+					}.GetGitLabReporter(r)
+				},
+				dryRun: tc.dryRun,
+			}
+			_, _, err := reporter.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), tc.pj)
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("expected error: %t, got %v", tc.expectErr, err)
+			}
+			if diff := cmp.Diff(tc.expectedStatuses, fgc.statuses, cmp.AllowUnexported(setStatus{})); diff != "" {
+				t.Errorf("unexpected statuses (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedNotes, fgc.notes, cmp.AllowUnexported(createdNote{})); diff != "" {
+				t.Errorf("unexpected notes (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package googlechat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"text/template"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/crier/reporters/criercommonlib"
+	googlechatclient "sigs.k8s.io/prow/pkg/googlechat"
+)
+
+const (
+	reporterName = "googlechatreporter"
+)
+
+type googleChatClient interface {
+	WriteMessage(ctx context.Context, msg *googlechatclient.Message, space string) error
+}
+
+type googleChatReporter struct {
+	client googleChatClient
+	config func(*prowapi.Refs) config.GoogleChatReporter
+	dryRun bool
+}
+
+func (gr *googleChatReporter) getConfig(pj *prowapi.ProwJob) config.GoogleChatReporter {
+	refs := pj.Spec.Refs
+	if refs == nil && len(pj.Spec.ExtraRefs) > 0 {
+		refs = &pj.Spec.ExtraRefs[0]
+	}
+	return gr.config(refs)
+}
+
+func (gr *googleChatReporter) Report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) ([]*prowapi.ProwJob, *reconcile.Result, error) {
+	return []*prowapi.ProwJob{pj}, nil, gr.report(ctx, log, pj)
+}
+
+func (gr *googleChatReporter) report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) error {
+	cfg := gr.getConfig(pj)
+
+	text, err := render(cfg.ReportTemplate, pj)
+	if err != nil {
+		log.WithError(err).Error("failed to render report template")
+		return fmt.Errorf("failed to render report template: %w", err)
+	}
+	msg := message(pj, text)
+	if cfg.ThreadKeyTemplate != "" {
+		threadKey, err := render(cfg.ThreadKeyTemplate, pj)
+		if err != nil {
+			log.WithError(err).Error("failed to render thread key template")
+			return fmt.Errorf("failed to render thread key template: %w", err)
+		}
+		if threadKey != "" {
+			msg.Thread = &googlechatclient.Thread{ThreadKey: threadKey}
+		}
+	}
+
+	if gr.dryRun {
+		payload, _ := json.Marshal(msg)
+		log.WithField("message", string(payload)).Debug("Skipping reporting because dry-run is enabled")
+		return nil
+	}
+	if err := gr.client.WriteMessage(ctx, msg, cfg.Space); err != nil {
+		log.WithError(err).Error("failed to write Google Chat message")
+		return fmt.Errorf("failed to write Google Chat message: %w", err)
+	}
+	return nil
+}
+
+func render(tmplText string, pj *prowapi.ProwJob) (string, error) {
+	tmpl, err := template.New("").Parse(tmplText)
+	if err != nil {
+		return "", err
+	}
+	b := &bytes.Buffer{}
+	if err := tmpl.Execute(b, pj); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func message(pj *prowapi.ProwJob, text string) *googlechatclient.Message {
+	widgets := []googlechatclient.Widget{{TextParagraph: &googlechatclient.TextParagraph{Text: text}}}
+	if pj.Status.URL != "" {
+		widgets = append(widgets, googlechatclient.Widget{ButtonList: &googlechatclient.ButtonList{
+			Buttons: []googlechatclient.Button{googlechatclient.NewLinkButton("View logs", pj.Status.URL)},
+		}})
+	}
+	return &googlechatclient.Message{
+		CardsV2: []googlechatclient.CardWithID{{
+			CardID: pj.Name,
+			Card: googlechatclient.Card{
+				Header:   &googlechatclient.CardHeader{Title: pj.Spec.Job, Subtitle: string(pj.Status.State)},
+				Sections: []googlechatclient.Section{{Widgets: widgets}},
+			},
+		}},
+	}
+}
+
+func (gr *googleChatReporter) GetName() string {
+	return reporterName
+}
+
+func (gr *googleChatReporter) ShouldReport(_ context.Context, logger *logrus.Entry, pj *prowapi.ProwJob) bool {
+	cfg := gr.getConfig(pj)
+
+	shouldReport := criercommonlib.ShouldReportJob(pj, cfg.JobTypesToReport, cfg.JobStatesToReport)
+	logger.WithField("reporting", shouldReport).Debug("Determined should report")
+	return shouldReport
+}
+
+func New(cfg func(refs *prowapi.Refs) config.GoogleChatReporter, dryRun bool, webhooksGenerator func() []byte, httpClient *http.Client) *googleChatReporter {
+	return &googleChatReporter{
+		client: googlechatclient.NewClient(webhooksGenerator, httpClient),
+		config: cfg,
+		dryRun: dryRun,
+	}
+}
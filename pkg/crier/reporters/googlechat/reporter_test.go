@@ -0,0 +1,221 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package googlechat
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	googlechatclient "sigs.k8s.io/prow/pkg/googlechat"
+)
+
+func TestShouldReport(t *testing.T) {
+	testCases := []struct {
+		name     string
+		config   config.GoogleChatReporter
+		pj       *v1.ProwJob
+		expected bool
+	}{
+		{
+			name: "matching type and state should report",
+			config: config.GoogleChatReporter{
+				JobTypesToReport:  []v1.ProwJobType{v1.PostsubmitJob},
+				JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+			},
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PostsubmitJob},
+				Status: v1.ProwJobStatus{State: v1.FailureState},
+			},
+			expected: true,
+		},
+		{
+			name: "wrong job type should not report",
+			config: config.GoogleChatReporter{
+				JobTypesToReport:  []v1.ProwJobType{v1.PostsubmitJob},
+				JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+			},
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PresubmitJob},
+				Status: v1.ProwJobStatus{State: v1.FailureState},
+			},
+			expected: false,
+		},
+		{
+			name: "wrong state should not report",
+			config: config.GoogleChatReporter{
+				JobTypesToReport:  []v1.ProwJobType{v1.PostsubmitJob},
+				JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+			},
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PostsubmitJob},
+				Status: v1.ProwJobStatus{State: v1.SuccessState},
+			},
+			expected: false,
+		},
+		{
+			name: "empty config should not report",
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PostsubmitJob},
+				Status: v1.ProwJobStatus{State: v1.SuccessState},
+			},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reporter := &googleChatReporter{
+				config: func(*v1.Refs) config.GoogleChatReporter { return tc.config },
+			}
+			if result := reporter.ShouldReport(context.Background(), logrus.NewEntry(logrus.StandardLogger()), tc.pj); result != tc.expected {
+				t.Errorf("expected result to be %t but was %t", tc.expected, result)
+			}
+		})
+	}
+}
+
+type fakeGoogleChatClient struct {
+	messages map[string]*googlechatclient.Message
+}
+
+func (fgc *fakeGoogleChatClient) WriteMessage(_ context.Context, msg *googlechatclient.Message, space string) error {
+	if fgc.messages == nil {
+		fgc.messages = map[string]*googlechatclient.Message{}
+	}
+	fgc.messages[space] = msg
+	return nil
+}
+
+var _ googleChatClient = &fakeGoogleChatClient{}
+
+func TestReport(t *testing.T) {
+	testCases := []struct {
+		name              string
+		pj                *v1.ProwJob
+		threadKeyTemplate string
+		dryRun            bool
+		expected          map[string]*googlechatclient.Message
+	}{
+		{
+			name: "failed job is reported with a link to the logs",
+			pj: &v1.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "pj-1"},
+				Spec: v1.ProwJobSpec{
+					Job:       "my-job",
+					Type:      v1.PeriodicJob,
+					ExtraRefs: []v1.Refs{{Org: "org"}},
+				},
+				Status: v1.ProwJobStatus{
+					State: v1.FailureState,
+					URL:   "https://prow.k8s.io/view/my-job/1",
+				},
+			},
+			expected: map[string]*googlechatclient.Message{
+				"oncall": {
+					CardsV2: []googlechatclient.CardWithID{{
+						CardID: "pj-1",
+						Card: googlechatclient.Card{
+							Header: &googlechatclient.CardHeader{Title: "my-job", Subtitle: "failure"},
+							Sections: []googlechatclient.Section{{Widgets: []googlechatclient.Widget{
+								{TextParagraph: &googlechatclient.TextParagraph{Text: "my-job ended with failure"}},
+								{ButtonList: &googlechatclient.ButtonList{Buttons: []googlechatclient.Button{
+									googlechatclient.NewLinkButton("View logs", "https://prow.k8s.io/view/my-job/1"),
+								}}},
+							}}},
+						},
+					}},
+				},
+			},
+		},
+		{
+			name: "thread key is rendered from the template",
+			pj: &v1.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "pj-1"},
+				Spec: v1.ProwJobSpec{
+					Job:  "my-job",
+					Type: v1.PeriodicJob,
+					Refs: &v1.Refs{Org: "org"},
+				},
+				Status: v1.ProwJobStatus{
+					State: v1.SuccessState,
+				},
+			},
+			threadKeyTemplate: "{{.Spec.Job}}",
+			expected: map[string]*googlechatclient.Message{
+				"oncall": {
+					CardsV2: []googlechatclient.CardWithID{{
+						CardID: "pj-1",
+						Card: googlechatclient.Card{
+							Header: &googlechatclient.CardHeader{Title: "my-job", Subtitle: "success"},
+							Sections: []googlechatclient.Section{{Widgets: []googlechatclient.Widget{
+								{TextParagraph: &googlechatclient.TextParagraph{Text: "my-job ended with success"}},
+							}}},
+						},
+					}},
+					Thread: &googlechatclient.Thread{ThreadKey: "my-job"},
+				},
+			},
+		},
+		{
+			name:   "dry-run does not send",
+			dryRun: true,
+			pj: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Job:  "my-job",
+					Type: v1.PeriodicJob,
+					Refs: &v1.Refs{Org: "org"},
+				},
+				Status: v1.ProwJobStatus{
+					State: v1.SuccessState,
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fgc := &fakeGoogleChatClient{}
+			reporter := &googleChatReporter{
+				client: fgc,
+				config: func(r *v1.Refs) config.GoogleChatReporter {
+					if r != nil && r.Org == "org" {
+						return config.GoogleChatReporter{
+							Space:             "oncall",
+							ReportTemplate:    "{{.Spec.Job}} ended with {{.Status.State}}",
+							ThreadKeyTemplate: tc.threadKeyTemplate,
+						}
+					}
+					return config.GoogleChatReporter{}
+				},
+				dryRun: tc.dryRun,
+			}
+
+			if _, _, err := reporter.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), tc.pj); err != nil {
+				t.Fatalf("reporting failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, fgc.messages); diff != "" {
+				t.Errorf("messages differ from expected: %s", diff)
+			}
+		})
+	}
+}
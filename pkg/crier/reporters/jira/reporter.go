@@ -0,0 +1,212 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package jira contains a crier reporter that comments on the Jira issues a
+// ProwJob is linked to.
+package jira
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/crier/reporters/criercommonlib"
+	prowjira "sigs.k8s.io/prow/pkg/jira"
+)
+
+const (
+	reporterName = "jirareporter"
+
+	// CommentedAnnotation stores the state of the job and the issues it was
+	// commented on, as JSON, if commenting on some other issues failed.
+	// Retries of the report only comment on the issues that failed.
+	CommentedAnnotation = "prow.k8s.io/jira-commented"
+)
+
+var issueKeyRegex = regexp.MustCompile(`\b[A-Za-z][A-Za-z0-9_]*-[0-9]+\b`)
+
+type jiraClient interface {
+	AddComment(issueID string, comment *jira.Comment) (*jira.Comment, error)
+}
+
+type jiraReporter struct {
+	client jiraClient
+	config func(*prowapi.Refs) config.JiraReporter
+	dryRun bool
+	// pjclient persists the issues jobs were commented on.
+	pjclient ctrlruntimeclient.Client
+}
+
+func (jr *jiraReporter) getConfig(pj *prowapi.ProwJob) config.JiraReporter {
+	refs := pj.Spec.Refs
+	if refs == nil && len(pj.Spec.ExtraRefs) > 0 {
+		refs = &pj.Spec.ExtraRefs[0]
+	}
+	return jr.config(refs)
+}
+
+// issueKeys returns the keys of the issues the job is linked to.
+func issueKeys(pj *prowapi.ProwJob, annotation string) []string {
+	if annotation == "" {
+		return nil
+	}
+	return issueKeyRegex.FindAllString(pj.Annotations[annotation], -1)
+}
+
+// commentedReport is the value of the CommentedAnnotation.
+type commentedReport struct {
+	State  prowapi.ProwJobState `json:"state"`
+	Issues []string             `json:"issues"`
+}
+
+// commentedIssues returns the issues the current state of the job was
+// already commented on.
+func commentedIssues(log *logrus.Entry, pj *prowapi.ProwJob) sets.Set[string] {
+	raw, ok := pj.Annotations[CommentedAnnotation]
+	if !ok {
+		return sets.New[string]()
+	}
+	var commented commentedReport
+	if err := json.Unmarshal([]byte(raw), &commented); err != nil {
+		log.WithError(err).Warn("Ignoring invalid Jira commented annotation")
+		return sets.New[string]()
+	}
+	if commented.State != pj.Status.State {
+		return sets.New[string]()
+	}
+	return sets.New(commented.Issues...)
+}
+
+func (jr *jiraReporter) storeCommented(ctx context.Context, pj *prowapi.ProwJob, issues sets.Set[string]) error {
+	raw, err := json.Marshal(commentedReport{State: pj.Status.State, Issues: sets.List(issues)})
+	if err != nil {
+		return err
+	}
+	newpj := pj.DeepCopy()
+	if newpj.Annotations == nil {
+		newpj.Annotations = map[string]string{}
+	}
+	newpj.Annotations[CommentedAnnotation] = string(raw)
+	if err := jr.pjclient.Patch(ctx, newpj, ctrlruntimeclient.MergeFrom(pj)); err != nil {
+		return fmt.Errorf("failed to patch prowjob: %w", err)
+	}
+	return nil
+}
+
+func (jr *jiraReporter) Report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) ([]*prowapi.ProwJob, *reconcile.Result, error) {
+	return []*prowapi.ProwJob{pj}, nil, jr.report(ctx, log, pj)
+}
+
+func (jr *jiraReporter) report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) error {
+	cfg := jr.getConfig(pj)
+
+	b := &bytes.Buffer{}
+	tmpl, err := template.New("").Parse(cfg.ReportTemplate)
+	if err != nil {
+		log.WithError(err).Error("failed to parse template")
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+	if err := tmpl.Execute(b, pj); err != nil {
+		log.WithError(err).Error("failed to execute report template")
+		return fmt.Errorf("failed to execute report template: %w", err)
+	}
+
+	keys := issueKeys(pj, cfg.IssuesAnnotation)
+	if jr.dryRun {
+		log.WithField("issues", keys).WithField("comment", b.String()).Debug("Skipping reporting because dry-run is enabled")
+		return nil
+	}
+
+	commented := commentedIssues(log, pj)
+	var newlyCommented bool
+	var failedIssues []string
+	var errs []error
+	allNotFound := true
+	for _, key := range keys {
+		if commented.Has(key) {
+			log.WithField("issue", key).Debug("Already commented on Jira issue")
+			continue
+		}
+		if _, err := jr.client.AddComment(key, &jira.Comment{Body: b.String()}); err != nil {
+			log.WithError(err).WithField("issue", key).Debug("Failed to comment on Jira issue")
+			failedIssues = append(failedIssues, key)
+			errs = append(errs, err)
+			allNotFound = allNotFound && prowjira.IsNotFound(err)
+			continue
+		}
+		commented.Insert(key)
+		newlyCommented = true
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	if newlyCommented {
+		if err := jr.storeCommented(ctx, pj, commented); err != nil {
+			log.WithError(err).Warn("Failed to store the Jira issues the job was commented on, retries comment on them again")
+		}
+	}
+	err = fmt.Errorf("failed to comment on Jira issue(s) %s: %w", strings.Join(failedIssues, ", "), utilerrors.NewAggregate(errs))
+	// Referencing issues that don't exist is a mistake in the job config,
+	// retrying won't help.
+	if allNotFound {
+		return criercommonlib.UserError(err)
+	}
+	return err
+}
+
+func (jr *jiraReporter) GetName() string {
+	return reporterName
+}
+
+func (jr *jiraReporter) ShouldReport(_ context.Context, logger *logrus.Entry, pj *prowapi.ProwJob) bool {
+	cfg := jr.getConfig(pj)
+
+	if len(issueKeys(pj, cfg.IssuesAnnotation)) == 0 {
+		logger.Debug("No Jira issues linked, not reporting")
+		return false
+	}
+
+	typesToReport := cfg.JobTypesToReport
+	if len(typesToReport) == 0 {
+		// An empty list means all job types are reported.
+		typesToReport = []prowapi.ProwJobType{pj.Spec.Type}
+	}
+	shouldReport := criercommonlib.ShouldReportJob(pj, typesToReport, cfg.JobStatesToReport)
+	logger.WithField("reporting", shouldReport).Debug("Determined should report")
+	return shouldReport
+}
+
+func New(cfg func(refs *prowapi.Refs) config.JiraReporter, dryRun bool, client jiraClient, pjclient ctrlruntimeclient.Client) *jiraReporter {
+	return &jiraReporter{
+		client:   client,
+		config:   cfg,
+		dryRun:   dryRun,
+		pjclient: pjclient,
+	}
+}
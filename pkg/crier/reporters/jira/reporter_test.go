@@ -0,0 +1,273 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jira
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/crier/reporters/criercommonlib"
+	prowjira "sigs.k8s.io/prow/pkg/jira"
+)
+
+func TestShouldReport(t *testing.T) {
+	testCases := []struct {
+		name        string
+		config      config.JiraReporter
+		annotations map[string]string
+		jobType     v1.ProwJobType
+		state       v1.ProwJobState
+		expected    bool
+	}{
+		{
+			name: "failed job with linked issue should report",
+			config: config.JiraReporter{
+				JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+				IssuesAnnotation:  config.DefaultJiraIssuesAnnotation,
+			},
+			annotations: map[string]string{config.DefaultJiraIssuesAnnotation: "PROJ-1"},
+			jobType:     v1.PeriodicJob,
+			state:       v1.FailureState,
+			expected:    true,
+		},
+		{
+			name: "job without linked issue should not report",
+			config: config.JiraReporter{
+				JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+				IssuesAnnotation:  config.DefaultJiraIssuesAnnotation,
+			},
+			jobType:  v1.PeriodicJob,
+			state:    v1.FailureState,
+			expected: false,
+		},
+		{
+			name: "successful job should not report",
+			config: config.JiraReporter{
+				JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+				IssuesAnnotation:  config.DefaultJiraIssuesAnnotation,
+			},
+			annotations: map[string]string{config.DefaultJiraIssuesAnnotation: "PROJ-1"},
+			jobType:     v1.PeriodicJob,
+			state:       v1.SuccessState,
+			expected:    false,
+		},
+		{
+			name: "wrong job type should not report",
+			config: config.JiraReporter{
+				JobTypesToReport:  []v1.ProwJobType{v1.PostsubmitJob},
+				JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+				IssuesAnnotation:  config.DefaultJiraIssuesAnnotation,
+			},
+			annotations: map[string]string{config.DefaultJiraIssuesAnnotation: "PROJ-1"},
+			jobType:     v1.PeriodicJob,
+			state:       v1.FailureState,
+			expected:    false,
+		},
+		{
+			name: "custom annotation is used",
+			config: config.JiraReporter{
+				JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+				IssuesAnnotation:  "example.com/issues",
+			},
+			annotations: map[string]string{"example.com/issues": "PROJ-1"},
+			jobType:     v1.PeriodicJob,
+			state:       v1.FailureState,
+			expected:    true,
+		},
+		{
+			name:        "empty config should not report",
+			annotations: map[string]string{config.DefaultJiraIssuesAnnotation: "PROJ-1"},
+			jobType:     v1.PeriodicJob,
+			state:       v1.FailureState,
+			expected:    false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reporter := &jiraReporter{
+				config: func(*v1.Refs) config.JiraReporter { return tc.config },
+			}
+			pj := &v1.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations},
+				Spec:       v1.ProwJobSpec{Type: tc.jobType},
+				Status:     v1.ProwJobStatus{State: tc.state},
+			}
+			if result := reporter.ShouldReport(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj); result != tc.expected {
+				t.Errorf("expected result to be %t but was %t", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestIssueKeys(t *testing.T) {
+	pj := &v1.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+			"issues": "PROJ-1, OTHER-22 [not an issue] REL_2-3",
+		}},
+	}
+	if diff := cmp.Diff([]string{"PROJ-1", "OTHER-22", "REL_2-3"}, issueKeys(pj, "issues")); diff != "" {
+		t.Errorf("issue keys differ from expected: %s", diff)
+	}
+}
+
+type fakeJiraClient struct {
+	comments map[string][]string
+	errors   map[string]error
+}
+
+func (fjc *fakeJiraClient) AddComment(issueID string, comment *jira.Comment) (*jira.Comment, error) {
+	if err := fjc.errors[issueID]; err != nil {
+		return nil, err
+	}
+	if fjc.comments == nil {
+		fjc.comments = map[string][]string{}
+	}
+	fjc.comments[issueID] = append(fjc.comments[issueID], comment.Body)
+	return comment, nil
+}
+
+var _ jiraClient = &fakeJiraClient{}
+
+func TestReport(t *testing.T) {
+	testCases := []struct {
+		name            string
+		errors          map[string]error
+		dryRun          bool
+		expected        map[string][]string
+		expectErr       string
+		expectUserError bool
+	}{
+		{
+			name: "all linked issues are commented on",
+			expected: map[string][]string{
+				"PROJ-1": {"my-job ended with failure"},
+				"PROJ-2": {"my-job ended with failure"},
+			},
+		},
+		{
+			name:   "failing issues are listed",
+			errors: map[string]error{"PROJ-1": errors.New("forbidden")},
+			expected: map[string][]string{
+				"PROJ-2": {"my-job ended with failure"},
+			},
+			expectErr: "failed to comment on Jira issue(s) PROJ-1: forbidden",
+		},
+		{
+			name: "missing issues are a user error",
+			errors: map[string]error{
+				"PROJ-1": prowjira.NewNotFoundError(errors.New("PROJ-1 not found")),
+				"PROJ-2": prowjira.NewNotFoundError(errors.New("PROJ-2 not found")),
+			},
+			expectErr:       "this is a user error: failed to comment on Jira issue(s) PROJ-1, PROJ-2: [PROJ-1 not found, PROJ-2 not found]",
+			expectUserError: true,
+		},
+		{
+			name:   "dry-run does not comment",
+			dryRun: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fjc := &fakeJiraClient{errors: tc.errors}
+			reporter := &jiraReporter{
+				client: fjc,
+				config: func(*v1.Refs) config.JiraReporter {
+					return config.JiraReporter{
+						IssuesAnnotation: config.DefaultJiraIssuesAnnotation,
+						ReportTemplate:   "{{.Spec.Job}} ended with {{.Status.State}}",
+					}
+				},
+				dryRun: tc.dryRun,
+			}
+			pj := &v1.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-job-1", Annotations: map[string]string{
+					config.DefaultJiraIssuesAnnotation: "PROJ-1,PROJ-2",
+				}},
+				Spec:   v1.ProwJobSpec{Job: "my-job", Type: v1.PeriodicJob},
+				Status: v1.ProwJobStatus{State: v1.FailureState},
+			}
+			reporter.pjclient = fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(pj.DeepCopy()).Build()
+
+			_, _, err := reporter.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj)
+			var errMsg string
+			if err != nil {
+				errMsg = err.Error()
+			}
+			if errMsg != tc.expectErr {
+				t.Errorf("expected error %q, got %q", tc.expectErr, errMsg)
+			}
+			if criercommonlib.IsUserError(err) != tc.expectUserError {
+				t.Errorf("expected user error: %t, got: %v", tc.expectUserError, err)
+			}
+			if diff := cmp.Diff(tc.expected, fjc.comments); diff != "" {
+				t.Errorf("comments differ from expected: %s", diff)
+			}
+		})
+	}
+}
+
+func TestReportRetryOnlyCommentsOnFailedIssues(t *testing.T) {
+	pj := &v1.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-job-1", Annotations: map[string]string{
+			config.DefaultJiraIssuesAnnotation: "PROJ-1,PROJ-2",
+		}},
+		Spec:   v1.ProwJobSpec{Job: "my-job", Type: v1.PeriodicJob},
+		Status: v1.ProwJobStatus{State: v1.FailureState},
+	}
+	pjclient := fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(pj.DeepCopy()).Build()
+	fjc := &fakeJiraClient{errors: map[string]error{"PROJ-2": errors.New("unavailable")}}
+	reporter := New(func(*v1.Refs) config.JiraReporter {
+		return config.JiraReporter{
+			IssuesAnnotation: config.DefaultJiraIssuesAnnotation,
+			ReportTemplate:   "{{.Spec.Job}} ended with {{.Status.State}}",
+		}
+	}, false, fjc, pjclient)
+	log := logrus.NewEntry(logrus.StandardLogger())
+
+	if _, _, err := reporter.Report(context.Background(), log, pj); err == nil {
+		t.Fatal("expected the first report to fail")
+	}
+
+	// The retry reports the job as stored by the first attempt.
+	if err := pjclient.Get(context.Background(), types.NamespacedName{Name: pj.Name}, pj); err != nil {
+		t.Fatalf("failed to get prowjob: %v", err)
+	}
+	delete(fjc.errors, "PROJ-2")
+	if _, _, err := reporter.Report(context.Background(), log, pj); err != nil {
+		t.Fatalf("unexpected error on retry: %v", err)
+	}
+
+	expected := map[string][]string{
+		"PROJ-1": {"my-job ended with failure"},
+		"PROJ-2": {"my-job ended with failure"},
+	}
+	if diff := cmp.Diff(expected, fjc.comments); diff != "" {
+		t.Errorf("comments differ from expected: %s", diff)
+	}
+}
@@ -0,0 +1,372 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kafka contains a reporter that produces prowjob statuses to Kafka
+// topics.
+package kafka
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+	"github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/yaml"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/crier/reporters/criercommonlib"
+	pubsubreporter "sigs.k8s.io/prow/pkg/crier/reporters/pubsub"
+)
+
+const (
+	reporterName = "kafkareporter"
+
+	// KafkaRunIDAnnotation is a user assigned ID of the run, it is passed
+	// through as the `runid` of the message.
+	KafkaRunIDAnnotation = "prow.k8s.io/kafka.runID"
+
+	// Headers set on every message, so that consumers can filter on them
+	// without parsing the message.
+	ProwJobNameHeader = "prowjob_name"
+	JobTypeHeader     = "job_type"
+	JobStateHeader    = "state"
+
+	// DefaultMaxProduceAttempts is the default number of times a message
+	// is produced before giving up.
+	DefaultMaxProduceAttempts = 3
+	// produceFailureRequeueAfter is how long crier waits before trying to
+	// report a job again once all produce attempts failed.
+	produceFailureRequeueAfter = time.Minute
+)
+
+// produceRetryBackoff is the backoff between produce attempts. Steps is set
+// from the configured max attempts.
+var produceRetryBackoff = wait.Backoff{
+	Duration: 500 * time.Millisecond,
+	Factor:   2,
+	Jitter:   0.1,
+}
+
+// Credentials are the credentials used to authenticate with the brokers
+// through SASL.
+type Credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type producer interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+type kafkaReporter struct {
+	config             func(*prowapi.Refs) config.KafkaReporter
+	prowCfg            config.Getter
+	dryRun             bool
+	maxProduceAttempts int
+	backoff            wait.Backoff
+	producerFor        func(cfg config.KafkaReporter) (producer, error)
+	checkTopic         func(ctx context.Context, cfg config.KafkaReporter) error
+}
+
+func (kr *kafkaReporter) getConfig(pj *prowapi.ProwJob) config.KafkaReporter {
+	refs := pj.Spec.Refs
+	if refs == nil && len(pj.Spec.ExtraRefs) > 0 {
+		refs = &pj.Spec.ExtraRefs[0]
+	}
+	return kr.config(refs)
+}
+
+// Report produces a message with the status of the job to the configured
+// topic. The message has the same schema as the one of the Pub/Sub
+// reporter, with the topic in place of the Pub/Sub topic. The job name is
+// the key of the message, so that all reports of a job go to the same
+// partition and are consumed in order. If producing keeps failing, the
+// returned result asks crier to try again later.
+func (kr *kafkaReporter) Report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) ([]*prowapi.ProwJob, *reconcile.Result, error) {
+	cfg := kr.getConfig(pj)
+	message := pubsubreporter.NewReportMessage(kr.prowCfg, pj, "", cfg.Topic, pj.Annotations[KafkaRunIDAnnotation])
+	b, err := json.Marshal(message)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not marshal kafka report: %w", err)
+	}
+
+	log = log.WithField("topic", cfg.Topic)
+	if kr.dryRun {
+		log.WithField("message", string(b)).Debug("Skipping reporting because dry-run is enabled")
+		return []*prowapi.ProwJob{pj}, nil, nil
+	}
+
+	p, err := kr.producerFor(cfg)
+	if err != nil {
+		return nil, nil, criercommonlib.UserError(fmt.Errorf("could not create kafka producer: %w", err))
+	}
+	msg := kafka.Message{
+		Key:   []byte(pj.Spec.Job),
+		Value: b,
+		Headers: []kafka.Header{
+			{Key: ProwJobNameHeader, Value: []byte(pj.Name)},
+			{Key: JobTypeHeader, Value: []byte(pj.Spec.Type)},
+			{Key: JobStateHeader, Value: []byte(pj.Status.State)},
+		},
+	}
+	if err := kr.produce(ctx, log, p, msg); err != nil {
+		err = fmt.Errorf("failed to produce kafka message to topic %q: %w", cfg.Topic, err)
+		if isUserError(err) {
+			return nil, nil, criercommonlib.UserError(err)
+		}
+		return nil, &reconcile.Result{RequeueAfter: produceFailureRequeueAfter}, err
+	}
+	return []*prowapi.ProwJob{pj}, nil, nil
+}
+
+// produce writes the message, retrying errors that aren't caused by the
+// configuration.
+func (kr *kafkaReporter) produce(ctx context.Context, log *logrus.Entry, p producer, msg kafka.Message) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	backoff := kr.backoff
+	backoff.Steps = kr.maxProduceAttempts
+	var produceErr error
+	retryErr := wait.ExponentialBackoffWithContext(ctx, backoff, func(ctx context.Context) (bool, error) {
+		if produceErr = p.WriteMessages(ctx, msg); produceErr != nil {
+			log.WithError(produceErr).Debug("Failed producing kafka message.")
+			if isUserError(produceErr) {
+				return false, produceErr
+			}
+			return false, nil
+		}
+		return true, nil
+	})
+	if retryErr == nil {
+		return nil
+	}
+	if produceErr == nil {
+		return retryErr
+	}
+	return produceErr
+}
+
+// isUserError returns whether the error is caused by the configuration,
+// e.g. a topic that doesn't exist, so that retrying doesn't help.
+func isUserError(err error) bool {
+	var writeErrs kafka.WriteErrors
+	if errors.As(err, &writeErrs) {
+		for _, err := range writeErrs {
+			if err != nil && !isUserError(err) {
+				return false
+			}
+		}
+		return writeErrs.Count() > 0
+	}
+	for _, userErr := range []kafka.Error{kafka.UnknownTopicOrPartition, kafka.TopicAuthorizationFailed, kafka.ClusterAuthorizationFailed, kafka.SASLAuthenticationFailed} {
+		if errors.Is(err, userErr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (kr *kafkaReporter) GetName() string {
+	return reporterName
+}
+
+func (kr *kafkaReporter) ShouldReport(_ context.Context, logger *logrus.Entry, pj *prowapi.ProwJob) bool {
+	cfg := kr.getConfig(pj)
+	if cfg.Topic == "" {
+		return false
+	}
+
+	shouldReport := criercommonlib.ShouldReportJob(pj, cfg.JobTypesToReport, cfg.JobStatesToReport)
+	logger.WithField("reporting", shouldReport).Debug("Determined should report")
+	return shouldReport
+}
+
+// Validate checks that the topics of the configs of all orgs and repos
+// exist on their brokers and can be described with crier's credentials.
+func (kr *kafkaReporter) Validate(ctx context.Context) error {
+	keys := sets.KeySet(kr.prowCfg().KafkaReporterConfigs)
+	seen := sets.New[string]()
+	var errs []error
+	for _, refs := range criercommonlib.RefsForConfigKeys(sets.List(keys)) {
+		cfg := kr.config(refs)
+		if cfg.Topic == "" || seen.Has(targetKey(cfg)) {
+			continue
+		}
+		seen.Insert(targetKey(cfg))
+		if err := kr.checkTopic(ctx, cfg); err != nil {
+			errs = append(errs, fmt.Errorf("topic %q on %s: %w", cfg.Topic, strings.Join(cfg.Brokers, ","), err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// targetKey identifies the brokers, topic and connection settings of a
+// config.
+func targetKey(cfg config.KafkaReporter) string {
+	return fmt.Sprintf("%s/%s/%t/%s", strings.Join(cfg.Brokers, ","), cfg.Topic, cfg.TLS, cfg.SASLMechanism)
+}
+
+type cachedProducer struct {
+	writer      *kafka.Writer
+	credentials string
+}
+
+// producerCache creates one producer per target. Producers are recreated
+// when the credentials change.
+type producerCache struct {
+	credentials func() []byte
+	caPEM       []byte
+	lock        sync.Mutex
+	producers   map[string]cachedProducer
+}
+
+func (c *producerCache) producerFor(cfg config.KafkaReporter) (producer, error) {
+	var credentials []byte
+	if cfg.SASLMechanism != "" && c.credentials != nil {
+		credentials = c.credentials()
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	key := targetKey(cfg)
+	if cached, ok := c.producers[key]; ok {
+		if cached.credentials == string(credentials) {
+			return cached.writer, nil
+		}
+		// Pending messages are flushed before the old writer is closed.
+		go cached.writer.Close()
+	}
+	transport, err := c.transport(cfg, credentials)
+	if err != nil {
+		return nil, err
+	}
+	writer := &kafka.Writer{
+		Addr:  kafka.TCP(cfg.Brokers...),
+		Topic: cfg.Topic,
+		// Partition like the Java client does, so that messages with the
+		// same key land on the same partition as those of other producers.
+		Balancer:     &kafka.Murmur2Balancer{},
+		RequiredAcks: kafka.RequireAll,
+		// Reports are written one at a time and retried by the reporter.
+		BatchSize:   1,
+		MaxAttempts: 1,
+		Transport:   transport,
+	}
+	c.producers[key] = cachedProducer{writer: writer, credentials: string(credentials)}
+	return writer, nil
+}
+
+func (c *producerCache) checkTopic(ctx context.Context, cfg config.KafkaReporter) error {
+	var credentials []byte
+	if cfg.SASLMechanism != "" && c.credentials != nil {
+		credentials = c.credentials()
+	}
+	transport, err := c.transport(cfg, credentials)
+	if err != nil {
+		return err
+	}
+	client := &kafka.Client{Addr: kafka.TCP(cfg.Brokers...), Transport: transport}
+	resp, err := client.Metadata(ctx, &kafka.MetadataRequest{Topics: []string{cfg.Topic}})
+	if err != nil {
+		return err
+	}
+	for _, topic := range resp.Topics {
+		if topic.Name == cfg.Topic {
+			return topic.Error
+		}
+	}
+	return kafka.UnknownTopicOrPartition
+}
+
+func (c *producerCache) transport(cfg config.KafkaReporter, credentials []byte) (*kafka.Transport, error) {
+	transport := &kafka.Transport{DialTimeout: 5 * time.Second}
+	if cfg.TLS {
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+		if len(c.caPEM) > 0 {
+			tlsConfig.RootCAs = x509.NewCertPool()
+			if !tlsConfig.RootCAs.AppendCertsFromPEM(c.caPEM) {
+				return nil, errors.New("no certificates found in the CA file")
+			}
+		}
+		transport.TLS = tlsConfig
+	}
+	if cfg.SASLMechanism != "" {
+		mechanism, err := saslMechanism(cfg.SASLMechanism, credentials)
+		if err != nil {
+			return nil, err
+		}
+		transport.SASL = mechanism
+	}
+	return transport, nil
+}
+
+func saslMechanism(name string, credentials []byte) (sasl.Mechanism, error) {
+	if len(credentials) == 0 {
+		return nil, fmt.Errorf("sasl_mechanism %s requires --kafka-credentials-file", name)
+	}
+	var creds Credentials
+	if err := yaml.Unmarshal(credentials, &creds); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials: %w", err)
+	}
+	switch name {
+	case config.KafkaSASLPlain:
+		return plain.Mechanism{Username: creds.Username, Password: creds.Password}, nil
+	case config.KafkaSASLScramSHA256:
+		return scram.Mechanism(scram.SHA256, creds.Username, creds.Password)
+	case config.KafkaSASLScramSHA512:
+		return scram.Mechanism(scram.SHA512, creds.Username, creds.Password)
+	default:
+		return nil, fmt.Errorf("unsupported sasl_mechanism %q", name)
+	}
+}
+
+// New returns a Kafka reporter. credentials returns the YAML or JSON
+// Credentials used by configs with a SASL mechanism and may be nil. caPEM
+// holds the certificates the brokers are verified with if TLS is enabled,
+// the system cert pool is used if it is empty. Messages are produced up to
+// maxProduceAttempts times.
+func New(cfg func(refs *prowapi.Refs) config.KafkaReporter, prowCfg config.Getter, dryRun bool, credentials func() []byte, caPEM []byte, maxProduceAttempts int) *kafkaReporter {
+	cache := &producerCache{
+		credentials: credentials,
+		caPEM:       caPEM,
+		producers:   map[string]cachedProducer{},
+	}
+	return &kafkaReporter{
+		config:             cfg,
+		prowCfg:            prowCfg,
+		dryRun:             dryRun,
+		maxProduceAttempts: maxProduceAttempts,
+		backoff:            produceRetryBackoff,
+		producerFor:        cache.producerFor,
+		checkTopic:         cache.checkTopic,
+	}
+}
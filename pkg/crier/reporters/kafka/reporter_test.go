@@ -0,0 +1,372 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/crier/reporters/criercommonlib"
+	pubsubreporter "sigs.k8s.io/prow/pkg/crier/reporters/pubsub"
+)
+
+func TestShouldReport(t *testing.T) {
+	testCases := []struct {
+		name     string
+		config   config.KafkaReporter
+		pj       *v1.ProwJob
+		expected bool
+	}{
+		{
+			name: "matching type and state should report",
+			config: config.KafkaReporter{
+				JobTypesToReport:  []v1.ProwJobType{v1.PostsubmitJob},
+				JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+				Topic:             "prow",
+			},
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PostsubmitJob},
+				Status: v1.ProwJobStatus{State: v1.FailureState},
+			},
+			expected: true,
+		},
+		{
+			name: "wrong job type should not report",
+			config: config.KafkaReporter{
+				JobTypesToReport:  []v1.ProwJobType{v1.PostsubmitJob},
+				JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+				Topic:             "prow",
+			},
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PresubmitJob},
+				Status: v1.ProwJobStatus{State: v1.FailureState},
+			},
+			expected: false,
+		},
+		{
+			name: "wrong state should not report",
+			config: config.KafkaReporter{
+				JobTypesToReport:  []v1.ProwJobType{v1.PostsubmitJob},
+				JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+				Topic:             "prow",
+			},
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PostsubmitJob},
+				Status: v1.ProwJobStatus{State: v1.SuccessState},
+			},
+			expected: false,
+		},
+		{
+			name: "no topic should not report",
+			config: config.KafkaReporter{
+				JobTypesToReport:  []v1.ProwJobType{v1.PostsubmitJob},
+				JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+			},
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PostsubmitJob},
+				Status: v1.ProwJobStatus{State: v1.FailureState},
+			},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reporter := &kafkaReporter{
+				config: func(*v1.Refs) config.KafkaReporter { return tc.config },
+			}
+			if result := reporter.ShouldReport(context.Background(), logrus.NewEntry(logrus.StandardLogger()), tc.pj); result != tc.expected {
+				t.Errorf("expected result to be %t but was %t", tc.expected, result)
+			}
+		})
+	}
+}
+
+// fakeProducer returns the errors in order, one per write, and records
+// the messages of successful writes.
+type fakeProducer struct {
+	errs     []error
+	writes   int
+	messages []kafka.Message
+}
+
+func (fp *fakeProducer) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	fp.writes++
+	if len(fp.errs) > 0 {
+		err := fp.errs[0]
+		fp.errs = fp.errs[1:]
+		if err != nil {
+			return err
+		}
+	}
+	fp.messages = append(fp.messages, msgs...)
+	return nil
+}
+
+type fca struct {
+	c config.Config
+}
+
+func (ca fca) Config() *config.Config {
+	return &ca.c
+}
+
+func testReporter(fp *fakeProducer, dryRun bool) *kafkaReporter {
+	return &kafkaReporter{
+		config: func(*v1.Refs) config.KafkaReporter {
+			return config.KafkaReporter{Brokers: []string{"kafka-0:9092"}, Topic: "prow"}
+		},
+		prowCfg:            fca{}.Config,
+		dryRun:             dryRun,
+		maxProduceAttempts: 3,
+		backoff:            wait.Backoff{Duration: time.Millisecond, Factor: 2},
+		producerFor:        func(config.KafkaReporter) (producer, error) { return fp, nil },
+	}
+}
+
+func TestReport(t *testing.T) {
+	pj := &v1.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "abc",
+			Annotations: map[string]string{KafkaRunIDAnnotation: "run-1"},
+		},
+		Spec: v1.ProwJobSpec{
+			Job:  "my-job",
+			Type: v1.PostsubmitJob,
+			Refs: &v1.Refs{Org: "org", Repo: "repo"},
+		},
+		Status: v1.ProwJobStatus{
+			State:       v1.FailureState,
+			Description: "Job failed.",
+		},
+	}
+
+	t.Run("message is produced with the job name as key", func(t *testing.T) {
+		fp := &fakeProducer{}
+		if _, _, err := testReporter(fp, false).Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj); err != nil {
+			t.Fatalf("reporting failed: %v", err)
+		}
+		if len(fp.messages) != 1 {
+			t.Fatalf("expected one message to be produced, got %d", len(fp.messages))
+		}
+		msg := fp.messages[0]
+		if string(msg.Key) != "my-job" {
+			t.Errorf("expected key my-job, got %q", msg.Key)
+		}
+		headers := map[string]string{}
+		for _, header := range msg.Headers {
+			headers[header.Key] = string(header.Value)
+		}
+		expectedHeaders := map[string]string{"prowjob_name": "abc", "job_type": "postsubmit", "state": "failure"}
+		if diff := cmp.Diff(expectedHeaders, headers); diff != "" {
+			t.Errorf("headers differ from expected: %s", diff)
+		}
+
+		var message pubsubreporter.ReportMessage
+		if err := json.Unmarshal(msg.Value, &message); err != nil {
+			t.Fatalf("failed to unmarshal message: %v", err)
+		}
+		expectedMessage := pubsubreporter.ReportMessage{
+			Topic:   "prow",
+			RunID:   "run-1",
+			Status:  v1.FailureState,
+			Refs:    []v1.Refs{{Org: "org", Repo: "repo"}},
+			JobType: v1.PostsubmitJob,
+			JobName: "my-job",
+			Message: "Job failed.",
+		}
+		if diff := cmp.Diff(expectedMessage, message); diff != "" {
+			t.Errorf("message differs from expected: %s", diff)
+		}
+	})
+
+	t.Run("dry-run does not produce", func(t *testing.T) {
+		fp := &fakeProducer{}
+		if _, _, err := testReporter(fp, true).Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj); err != nil {
+			t.Fatalf("reporting failed: %v", err)
+		}
+		if fp.writes != 0 {
+			t.Errorf("expected nothing to be produced, got %d writes", fp.writes)
+		}
+	})
+}
+
+func TestReportRetries(t *testing.T) {
+	transientErr := kafka.WriteErrors{kafka.LeaderNotAvailable}
+	testCases := []struct {
+		name            string
+		errs            []error
+		expectedWrites  int
+		expectResult    *reconcile.Result
+		expectErr       bool
+		expectUserError bool
+	}{
+		{
+			name:           "produced on first attempt",
+			expectedWrites: 1,
+		},
+		{
+			name:           "produced after transient failures",
+			errs:           []error{transientErr, errors.New("connection reset"), nil},
+			expectedWrites: 3,
+		},
+		{
+			name:           "all attempts fail, requeued",
+			errs:           []error{transientErr, transientErr, transientErr},
+			expectedWrites: 3,
+			expectResult:   &reconcile.Result{RequeueAfter: produceFailureRequeueAfter},
+			expectErr:      true,
+		},
+		{
+			name:            "topic doesn't exist, user error without retries and requeue",
+			errs:            []error{kafka.WriteErrors{kafka.UnknownTopicOrPartition}},
+			expectedWrites:  1,
+			expectErr:       true,
+			expectUserError: true,
+		},
+		{
+			name:            "failed authentication, user error without retries and requeue",
+			errs:            []error{kafka.SASLAuthenticationFailed},
+			expectedWrites:  1,
+			expectErr:       true,
+			expectUserError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fp := &fakeProducer{errs: tc.errs}
+			pj := &v1.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "abc"},
+				Spec:       v1.ProwJobSpec{Job: "my-job", Type: v1.PeriodicJob},
+				Status:     v1.ProwJobStatus{State: v1.SuccessState},
+			}
+			_, result, err := testReporter(fp, false).Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj)
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("expected error: %t, got: %v", tc.expectErr, err)
+			}
+			if criercommonlib.IsUserError(err) != tc.expectUserError {
+				t.Errorf("expected user error to be %t, got: %v", tc.expectUserError, err)
+			}
+			if !reflect.DeepEqual(result, tc.expectResult) {
+				t.Errorf("expected result %v, got %v", tc.expectResult, result)
+			}
+			if fp.writes != tc.expectedWrites {
+				t.Errorf("expected %d writes, got %d", tc.expectedWrites, fp.writes)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	configs := config.KafkaReporterConfigs{
+		"*":        {Brokers: []string{"kafka-0:9092"}, Topic: "prow"},
+		"org":      {Brokers: []string{"kafka-0:9092"}, Topic: "org"},
+		"org/repo": {Brokers: []string{"kafka-0:9092"}, Topic: "prow"},
+	}
+	testCases := []struct {
+		name        string
+		topics      sets.Set[string]
+		expectedErr bool
+	}{
+		{
+			name:   "all topics exist",
+			topics: sets.New("prow", "org"),
+		},
+		{
+			name:        "missing topic fails",
+			topics:      sets.New("prow"),
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var checked []string
+			reporter := &kafkaReporter{
+				config:  configs.GetKafkaReporter,
+				prowCfg: fca{c: config.Config{ProwConfig: config.ProwConfig{KafkaReporterConfigs: configs}}}.Config,
+				checkTopic: func(_ context.Context, cfg config.KafkaReporter) error {
+					checked = append(checked, cfg.Topic)
+					if !tc.topics.Has(cfg.Topic) {
+						return kafka.UnknownTopicOrPartition
+					}
+					return nil
+				},
+			}
+			if err := reporter.Validate(context.Background()); (err != nil) != tc.expectedErr {
+				t.Errorf("expected error: %t, got: %v", tc.expectedErr, err)
+			}
+			if diff := cmp.Diff([]string{"prow", "org"}, checked); diff != "" {
+				t.Errorf("every topic should be checked once (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestProducerFor(t *testing.T) {
+	credentials := []byte("username: prow\npassword: secret")
+	cache := &producerCache{
+		credentials: func() []byte { return credentials },
+		producers:   map[string]cachedProducer{},
+	}
+	cfg := config.KafkaReporter{Brokers: []string{"kafka-0:9093"}, Topic: "prow", TLS: true, SASLMechanism: config.KafkaSASLPlain}
+
+	p, err := cache.producerFor(cfg)
+	if err != nil {
+		t.Fatalf("failed to create producer: %v", err)
+	}
+	transport := p.(*kafka.Writer).Transport.(*kafka.Transport)
+	if transport.TLS == nil {
+		t.Error("expected TLS to be enabled")
+	}
+	if diff := cmp.Diff(plain.Mechanism{Username: "prow", Password: "secret"}, transport.SASL); diff != "" {
+		t.Errorf("SASL mechanism differs from expected: %s", diff)
+	}
+	if again, _ := cache.producerFor(cfg); again != p {
+		t.Error("expected the producer to be reused")
+	}
+
+	credentials = []byte("username: prow\npassword: rotated")
+	rotated, err := cache.producerFor(cfg)
+	if err != nil {
+		t.Fatalf("failed to create producer: %v", err)
+	}
+	if rotated == p {
+		t.Error("expected a new producer after the credentials changed")
+	}
+
+	cache.credentials = nil
+	if _, err := cache.producerFor(config.KafkaReporter{Brokers: []string{"kafka-0:9093"}, Topic: "other", SASLMechanism: config.KafkaSASLScramSHA256}); err == nil {
+		t.Error("expected an error for SASL without credentials")
+	}
+}
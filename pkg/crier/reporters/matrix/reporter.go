@@ -0,0 +1,144 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package matrix
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"text/template"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/crier/reporters/criercommonlib"
+	matrixclient "sigs.k8s.io/prow/pkg/matrix"
+)
+
+const (
+	reporterName = "matrixreporter"
+)
+
+// stateColor returns the color a job state is shown in. Jobs that ended
+// without failing but didn't succeed, e.g. aborted ones, are gray.
+func stateColor(state prowapi.ProwJobState) string {
+	switch {
+	case state == prowapi.SuccessState:
+		return "#1a7f37"
+	case criercommonlib.IsTerminalNonFailure(state):
+		return "#6e7781"
+	case state == prowapi.FailureState:
+		return "#cf222e"
+	case state == prowapi.ErrorState:
+		return "#bf8700"
+	case state == prowapi.PendingState:
+		return "#0969da"
+	default:
+		return "#6e7781"
+	}
+}
+
+type matrixClient interface {
+	SendMessage(ctx context.Context, homeserver, roomID, txnID string, msg matrixclient.Message) error
+}
+
+type matrixReporter struct {
+	client matrixClient
+	config func(*prowapi.Refs) config.MatrixReporter
+	dryRun bool
+}
+
+func (mr *matrixReporter) getConfig(pj *prowapi.ProwJob) config.MatrixReporter {
+	refs := pj.Spec.Refs
+	if refs == nil && len(pj.Spec.ExtraRefs) > 0 {
+		refs = &pj.Spec.ExtraRefs[0]
+	}
+	return mr.config(refs)
+}
+
+func (mr *matrixReporter) Report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) ([]*prowapi.ProwJob, *reconcile.Result, error) {
+	return []*prowapi.ProwJob{pj}, nil, mr.report(ctx, log, pj)
+}
+
+func (mr *matrixReporter) report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) error {
+	cfg := mr.getConfig(pj)
+
+	b := &bytes.Buffer{}
+	tmpl, err := template.New("").Parse(cfg.ReportTemplate)
+	if err != nil {
+		log.WithError(err).Error("failed to parse template")
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+	if err := tmpl.Execute(b, pj); err != nil {
+		log.WithError(err).Error("failed to execute report template")
+		return fmt.Errorf("failed to execute report template: %w", err)
+	}
+
+	msg := message(pj, b.String())
+	if mr.dryRun {
+		log.WithFields(logrus.Fields{"homeserver": cfg.Homeserver, "room": cfg.RoomID, "message": msg.Body}).Debug("Skipping reporting because dry-run is enabled")
+		return nil
+	}
+	// The transaction ID makes the homeserver drop the message if a report
+	// of the same state is retried after it was already sent.
+	txnID := fmt.Sprintf("prow-%s-%s", pj.Name, pj.Status.State)
+	if err := mr.client.SendMessage(ctx, cfg.Homeserver, cfg.RoomID, txnID, msg); err != nil {
+		log.WithError(err).Error("failed to send Matrix message")
+		return fmt.Errorf("failed to send Matrix message: %w", err)
+	}
+	return nil
+}
+
+// message formats the message both as plain text and as HTML with the
+// state shown in color.
+func message(pj *prowapi.ProwJob, text string) matrixclient.Message {
+	body := []string{fmt.Sprintf("%s: %s", pj.Spec.Job, pj.Status.State), text}
+	formatted := []string{
+		fmt.Sprintf(`<b>%s</b>: <font color="%s"><b>%s</b></font>`, html.EscapeString(pj.Spec.Job), stateColor(pj.Status.State), html.EscapeString(string(pj.Status.State))),
+		html.EscapeString(text),
+	}
+	if pj.Status.URL != "" {
+		body = append(body, pj.Status.URL)
+		formatted = append(formatted, fmt.Sprintf(`<a href="%s">View logs</a>`, html.EscapeString(pj.Status.URL)))
+	}
+	return matrixclient.NewHTMLMessage(strings.Join(body, "\n"), strings.Join(formatted, "<br>"))
+}
+
+func (mr *matrixReporter) GetName() string {
+	return reporterName
+}
+
+func (mr *matrixReporter) ShouldReport(_ context.Context, logger *logrus.Entry, pj *prowapi.ProwJob) bool {
+	cfg := mr.getConfig(pj)
+
+	shouldReport := criercommonlib.ShouldReportJob(pj, cfg.JobTypesToReport, cfg.JobStatesToReport)
+	logger.WithField("reporting", shouldReport).Debug("Determined should report")
+	return shouldReport
+}
+
+func New(cfg func(refs *prowapi.Refs) config.MatrixReporter, dryRun bool, tokenGenerator func() []byte, httpClient *http.Client) *matrixReporter {
+	return &matrixReporter{
+		client: matrixclient.NewClient(tokenGenerator, httpClient),
+		config: cfg,
+		dryRun: dryRun,
+	}
+}
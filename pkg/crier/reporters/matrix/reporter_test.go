@@ -0,0 +1,240 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package matrix
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	matrixclient "sigs.k8s.io/prow/pkg/matrix"
+)
+
+func TestShouldReport(t *testing.T) {
+	testCases := []struct {
+		name     string
+		config   config.MatrixReporter
+		pj       *v1.ProwJob
+		expected bool
+	}{
+		{
+			name: "matching type and state should report",
+			config: config.MatrixReporter{
+				JobTypesToReport:  []v1.ProwJobType{v1.PostsubmitJob},
+				JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+			},
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PostsubmitJob},
+				Status: v1.ProwJobStatus{State: v1.FailureState},
+			},
+			expected: true,
+		},
+		{
+			name: "wrong job type should not report",
+			config: config.MatrixReporter{
+				JobTypesToReport:  []v1.ProwJobType{v1.PostsubmitJob},
+				JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+			},
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PresubmitJob},
+				Status: v1.ProwJobStatus{State: v1.FailureState},
+			},
+			expected: false,
+		},
+		{
+			name: "wrong state should not report",
+			config: config.MatrixReporter{
+				JobTypesToReport:  []v1.ProwJobType{v1.PostsubmitJob},
+				JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+			},
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PostsubmitJob},
+				Status: v1.ProwJobStatus{State: v1.SuccessState},
+			},
+			expected: false,
+		},
+		{
+			name: "empty config should not report",
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PostsubmitJob},
+				Status: v1.ProwJobStatus{State: v1.SuccessState},
+			},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reporter := &matrixReporter{
+				config: func(*v1.Refs) config.MatrixReporter { return tc.config },
+			}
+			if result := reporter.ShouldReport(context.Background(), logrus.NewEntry(logrus.StandardLogger()), tc.pj); result != tc.expected {
+				t.Errorf("expected result to be %t but was %t", tc.expected, result)
+			}
+		})
+	}
+}
+
+type sentMessage struct {
+	homeserver string
+	roomID     string
+	txnID      string
+	msg        matrixclient.Message
+}
+
+type fakeMatrixClient struct {
+	messages []sentMessage
+}
+
+func (fmc *fakeMatrixClient) SendMessage(_ context.Context, homeserver, roomID, txnID string, msg matrixclient.Message) error {
+	fmc.messages = append(fmc.messages, sentMessage{homeserver: homeserver, roomID: roomID, txnID: txnID, msg: msg})
+	return nil
+}
+
+var _ matrixClient = &fakeMatrixClient{}
+
+func TestReport(t *testing.T) {
+	testCases := []struct {
+		name     string
+		pj       *v1.ProwJob
+		dryRun   bool
+		expected []sentMessage
+	}{
+		{
+			name: "failed job is reported escaped, colored and with link",
+			pj: &v1.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "abc"},
+				Spec: v1.ProwJobSpec{
+					Job:       "ci-<job>",
+					Type:      v1.PeriodicJob,
+					ExtraRefs: []v1.Refs{{Org: "org", Repo: "repo"}},
+				},
+				Status: v1.ProwJobStatus{
+					State: v1.FailureState,
+					URL:   "https://prow.k8s.io/view/job?a=1&b=2",
+				},
+			},
+			expected: []sentMessage{{
+				homeserver: "https://matrix.example.com",
+				roomID:     "!repo:example.com",
+				txnID:      "prow-abc-failure",
+				msg: matrixclient.NewHTMLMessage(
+					"ci-<job>: failure\nci-<job> ended with failure!\nhttps://prow.k8s.io/view/job?a=1&b=2",
+					`<b>ci-&lt;job&gt;</b>: <font color="#cf222e"><b>failure</b></font><br>`+
+						`ci-&lt;job&gt; ended with failure!<br>`+
+						`<a href="https://prow.k8s.io/view/job?a=1&amp;b=2">View logs</a>`,
+				),
+			}},
+		},
+		{
+			name: "successful job without URL is routed by org and has no link",
+			pj: &v1.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "def"},
+				Spec: v1.ProwJobSpec{
+					Job:  "my-job",
+					Type: v1.PeriodicJob,
+					Refs: &v1.Refs{Org: "org", Repo: "other"},
+				},
+				Status: v1.ProwJobStatus{
+					State: v1.SuccessState,
+				},
+			},
+			expected: []sentMessage{{
+				homeserver: "https://matrix.example.com",
+				roomID:     "!org:example.com",
+				txnID:      "prow-def-success",
+				msg: matrixclient.NewHTMLMessage(
+					"my-job: success\nmy-job ended with success!",
+					`<b>my-job</b>: <font color="#1a7f37"><b>success</b></font><br>my-job ended with success!`,
+				),
+			}},
+		},
+		{
+			name: "aborted job is reported gray",
+			pj: &v1.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "ghi"},
+				Spec: v1.ProwJobSpec{
+					Job:  "my-job",
+					Type: v1.PeriodicJob,
+					Refs: &v1.Refs{Org: "org", Repo: "other"},
+				},
+				Status: v1.ProwJobStatus{
+					State: v1.AbortedState,
+				},
+			},
+			expected: []sentMessage{{
+				homeserver: "https://matrix.example.com",
+				roomID:     "!org:example.com",
+				txnID:      "prow-ghi-aborted",
+				msg: matrixclient.NewHTMLMessage(
+					"my-job: aborted\nmy-job ended with aborted!",
+					`<b>my-job</b>: <font color="#6e7781"><b>aborted</b></font><br>my-job ended with aborted!`,
+				),
+			}},
+		},
+		{
+			name:   "dry-run does not send",
+			dryRun: true,
+			pj: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Job:  "my-job",
+					Type: v1.PeriodicJob,
+					Refs: &v1.Refs{Org: "org"},
+				},
+				Status: v1.ProwJobStatus{
+					State: v1.SuccessState,
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fmc := &fakeMatrixClient{}
+			reporter := &matrixReporter{
+				client: fmc,
+				config: func(r *v1.Refs) config.MatrixReporter {
+					return config.MatrixReporterConfigs{
+						"org": {
+							Homeserver:     "https://matrix.example.com",
+							RoomID:         "!org:example.com",
+							ReportTemplate: "{{.Spec.Job}} ended with {{.Status.State}}!",
+						},
+						"org/repo": {
+							Homeserver:     "https://matrix.example.com",
+							RoomID:         "!repo:example.com",
+							ReportTemplate: "{{.Spec.Job}} ended with {{.Status.State}}!",
+						},
+					}.GetMatrixReporter(r)
+				},
+				dryRun: tc.dryRun,
+			}
+
+			if _, _, err := reporter.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), tc.pj); err != nil {
+				t.Fatalf("reporting failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, fmc.messages, cmp.AllowUnexported(sentMessage{})); diff != "" {
+				t.Errorf("messages differ from expected: %s", diff)
+			}
+		})
+	}
+}
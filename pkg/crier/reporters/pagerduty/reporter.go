@@ -0,0 +1,151 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pagerduty contains a crier reporter that triggers PagerDuty alerts
+// for failing jobs and resolves them once the jobs succeed again.
+package pagerduty
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"text/template"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/crier/reporters/criercommonlib"
+	pagerdutyclient "sigs.k8s.io/prow/pkg/pagerduty"
+)
+
+const (
+	reporterName = "pagerdutyreporter"
+)
+
+type pagerDutyClient interface {
+	SendEvent(ctx context.Context, event *pagerdutyclient.Event) error
+}
+
+type pagerDutyReporter struct {
+	client pagerDutyClient
+	config func(*prowapi.Refs) config.PagerDutyReporter
+	dryRun bool
+}
+
+func (pr *pagerDutyReporter) getConfig(pj *prowapi.ProwJob) config.PagerDutyReporter {
+	refs := pj.Spec.Refs
+	if refs == nil && len(pj.Spec.ExtraRefs) > 0 {
+		refs = &pj.Spec.ExtraRefs[0]
+	}
+	return pr.config(refs)
+}
+
+// dedupKey groups all alerts of a job, so that a later successful run
+// resolves the alert triggered by a failed one.
+func dedupKey(pj *prowapi.ProwJob) string {
+	return "prow/" + pj.Spec.Job
+}
+
+func (pr *pagerDutyReporter) Report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) ([]*prowapi.ProwJob, *reconcile.Result, error) {
+	return []*prowapi.ProwJob{pj}, nil, pr.report(ctx, log, pj)
+}
+
+func (pr *pagerDutyReporter) report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) error {
+	cfg := pr.getConfig(pj)
+
+	var event *pagerdutyclient.Event
+	if pj.Status.State == prowapi.SuccessState {
+		event = pagerdutyclient.NewResolveEvent(cfg.RoutingKey, dedupKey(pj))
+	} else {
+		b := &bytes.Buffer{}
+		tmpl, err := template.New("").Parse(cfg.ReportTemplate)
+		if err != nil {
+			log.WithError(err).Error("failed to parse template")
+			return fmt.Errorf("failed to parse template: %w", err)
+		}
+		if err := tmpl.Execute(b, pj); err != nil {
+			log.WithError(err).Error("failed to execute report template")
+			return fmt.Errorf("failed to execute report template: %w", err)
+		}
+		event = triggerEvent(pj, cfg, b.String())
+	}
+
+	if pr.dryRun {
+		// Don't log the routing key.
+		redacted := *event
+		redacted.RoutingKey = ""
+		payload, _ := json.Marshal(redacted)
+		log.WithField("event", string(payload)).Debug("Skipping reporting because dry-run is enabled")
+		return nil
+	}
+	if err := pr.client.SendEvent(ctx, event); err != nil {
+		log.WithError(err).Error("failed to send PagerDuty event")
+		return fmt.Errorf("failed to send PagerDuty event: %w", err)
+	}
+	return nil
+}
+
+func triggerEvent(pj *prowapi.ProwJob, cfg config.PagerDutyReporter, summary string) *pagerdutyclient.Event {
+	event := pagerdutyclient.NewTriggerEvent(cfg.RoutingKey, dedupKey(pj), pagerdutyclient.Payload{
+		Summary:  summary,
+		Source:   pj.Spec.Job,
+		Severity: cfg.Severity,
+		CustomDetails: map[string]string{
+			"job":      pj.Spec.Job,
+			"type":     string(pj.Spec.Type),
+			"state":    string(pj.Status.State),
+			"build_id": pj.Status.BuildID,
+		},
+	})
+	if pj.Status.URL != "" {
+		event.Links = []pagerdutyclient.Link{{Href: pj.Status.URL, Text: "View logs"}}
+	}
+	return event
+}
+
+func (pr *pagerDutyReporter) GetName() string {
+	return reporterName
+}
+
+func (pr *pagerDutyReporter) ShouldReport(_ context.Context, logger *logrus.Entry, pj *prowapi.ProwJob) bool {
+	cfg := pr.getConfig(pj)
+
+	// Successful jobs are always reported to resolve the alert a previous
+	// run might have triggered. Aborted jobs neither trigger nor resolve an
+	// alert, even if they are listed in the states to report, as they were
+	// usually superseded by a newer run.
+	statesToReport := []prowapi.ProwJobState{prowapi.SuccessState}
+	for _, state := range cfg.JobStatesToReport {
+		if !criercommonlib.IsTerminalNonFailure(state) {
+			statesToReport = append(statesToReport, state)
+		}
+	}
+	shouldReport := criercommonlib.ShouldReportJob(pj, cfg.JobTypesToReport, statesToReport)
+	logger.WithField("reporting", shouldReport).Debug("Determined should report")
+	return shouldReport
+}
+
+func New(cfg func(refs *prowapi.Refs) config.PagerDutyReporter, dryRun bool, httpClient *http.Client) *pagerDutyReporter {
+	return &pagerDutyReporter{
+		client: pagerdutyclient.NewClient(httpClient),
+		config: cfg,
+		dryRun: dryRun,
+	}
+}
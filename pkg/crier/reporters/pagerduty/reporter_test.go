@@ -0,0 +1,218 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pagerduty
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+
+	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	pagerdutyclient "sigs.k8s.io/prow/pkg/pagerduty"
+)
+
+func TestShouldReport(t *testing.T) {
+	cfg := config.PagerDutyReporter{
+		JobTypesToReport:  []v1.ProwJobType{v1.PeriodicJob},
+		JobStatesToReport: []v1.ProwJobState{v1.FailureState, v1.ErrorState},
+	}
+	testCases := []struct {
+		name     string
+		config   config.PagerDutyReporter
+		pj       *v1.ProwJob
+		expected bool
+	}{
+		{
+			name:   "failed periodic should report",
+			config: cfg,
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PeriodicJob},
+				Status: v1.ProwJobStatus{State: v1.FailureState},
+			},
+			expected: true,
+		},
+		{
+			name:   "successful periodic should report to resolve",
+			config: cfg,
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PeriodicJob},
+				Status: v1.ProwJobStatus{State: v1.SuccessState},
+			},
+			expected: true,
+		},
+		{
+			name:   "aborted periodic should not report",
+			config: cfg,
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PeriodicJob},
+				Status: v1.ProwJobStatus{State: v1.AbortedState},
+			},
+			expected: false,
+		},
+		{
+			name: "aborted periodic should not report even if configured",
+			config: config.PagerDutyReporter{
+				JobTypesToReport:  []v1.ProwJobType{v1.PeriodicJob},
+				JobStatesToReport: []v1.ProwJobState{v1.FailureState, v1.AbortedState},
+			},
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PeriodicJob},
+				Status: v1.ProwJobStatus{State: v1.AbortedState},
+			},
+			expected: false,
+		},
+		{
+			name:   "wrong job type should not report",
+			config: cfg,
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PresubmitJob},
+				Status: v1.ProwJobStatus{State: v1.FailureState},
+			},
+			expected: false,
+		},
+		{
+			name: "empty config should not report",
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PeriodicJob},
+				Status: v1.ProwJobStatus{State: v1.SuccessState},
+			},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reporter := &pagerDutyReporter{
+				config: func(*v1.Refs) config.PagerDutyReporter { return tc.config },
+			}
+			if result := reporter.ShouldReport(context.Background(), logrus.NewEntry(logrus.StandardLogger()), tc.pj); result != tc.expected {
+				t.Errorf("expected result to be %t but was %t", tc.expected, result)
+			}
+		})
+	}
+}
+
+type fakePagerDutyClient struct {
+	events []*pagerdutyclient.Event
+}
+
+func (fpc *fakePagerDutyClient) SendEvent(_ context.Context, event *pagerdutyclient.Event) error {
+	fpc.events = append(fpc.events, event)
+	return nil
+}
+
+var _ pagerDutyClient = &fakePagerDutyClient{}
+
+func TestReport(t *testing.T) {
+	testCases := []struct {
+		name     string
+		pj       *v1.ProwJob
+		dryRun   bool
+		expected []*pagerdutyclient.Event
+	}{
+		{
+			name: "failed job triggers an alert",
+			pj: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Job:  "my-job",
+					Type: v1.PeriodicJob,
+				},
+				Status: v1.ProwJobStatus{
+					State:   v1.FailureState,
+					BuildID: "1",
+					URL:     "https://prow.k8s.io/view/my-job/1",
+				},
+			},
+			expected: []*pagerdutyclient.Event{{
+				RoutingKey:  "key",
+				EventAction: pagerdutyclient.EventActionTrigger,
+				DedupKey:    "prow/my-job",
+				Payload: &pagerdutyclient.Payload{
+					Summary:  "my-job ended with failure",
+					Source:   "my-job",
+					Severity: "critical",
+					CustomDetails: map[string]string{
+						"job":      "my-job",
+						"type":     "periodic",
+						"state":    "failure",
+						"build_id": "1",
+					},
+				},
+				Links: []pagerdutyclient.Link{{Href: "https://prow.k8s.io/view/my-job/1", Text: "View logs"}},
+			}},
+		},
+		{
+			name: "successful job resolves the alert",
+			pj: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Job:  "my-job",
+					Type: v1.PeriodicJob,
+				},
+				Status: v1.ProwJobStatus{
+					State:   v1.SuccessState,
+					BuildID: "2",
+					URL:     "https://prow.k8s.io/view/my-job/2",
+				},
+			},
+			expected: []*pagerdutyclient.Event{{
+				RoutingKey:  "key",
+				EventAction: pagerdutyclient.EventActionResolve,
+				DedupKey:    "prow/my-job",
+			}},
+		},
+		{
+			name:   "dry-run does not send",
+			dryRun: true,
+			pj: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Job:  "my-job",
+					Type: v1.PeriodicJob,
+				},
+				Status: v1.ProwJobStatus{
+					State: v1.ErrorState,
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fpc := &fakePagerDutyClient{}
+			reporter := &pagerDutyReporter{
+				client: fpc,
+				config: func(*v1.Refs) config.PagerDutyReporter {
+					return config.PagerDutyReporter{
+						RoutingKey:     "key",
+						Severity:       "critical",
+						ReportTemplate: "{{.Spec.Job}} ended with {{.Status.State}}",
+					}
+				},
+				dryRun: tc.dryRun,
+			}
+
+			if _, _, err := reporter.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), tc.pj); err != nil {
+				t.Fatalf("reporting failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, fpc.events); diff != "" {
+				t.Errorf("events differ from expected: %s", diff)
+			}
+		})
+	}
+}
@@ -22,11 +22,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"cloud.google.com/go/pubsub"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/api/option"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
@@ -43,8 +49,41 @@ const (
 	PubSubTopicLabel = "prow.k8s.io/pubsub.topic"
 	// PubSubRunIDLabel annotation
 	PubSubRunIDLabel = "prow.k8s.io/pubsub.runID"
+
+	// ProwJobNameAttribute is the message attribute holding the name of the
+	// reported ProwJob.
+	ProwJobNameAttribute = "prowjob_name"
+	// ReportSequenceAttribute is the message attribute holding the position
+	// of the reported state in the job's lifecycle: 0 for triggered, 1 for
+	// pending and 2 for a completed job. Subscribers can use it to discard
+	// reports that arrive after a later state of the same job.
+	ReportSequenceAttribute = "report_sequence"
+	// DedupKeyAttribute is the message attribute that is identical for all
+	// messages reporting the same state of the same job, so that
+	// subscribers can drop redelivered or republished messages.
+	DedupKeyAttribute = "dedup_key"
+
+	// DefaultMaxPublishAttempts is the default number of times a message is
+	// published before giving up.
+	DefaultMaxPublishAttempts = 3
+	// publishFailureRequeueAfter is how long crier waits before trying to
+	// report a job again once all publish attempts failed.
+	publishFailureRequeueAfter = time.Minute
+	// publishAttemptTimeout is how long a single publish attempt may take.
+	// The backoff between attempts isn't part of it, so all attempts take
+	// at most maxPublishAttempts times as long plus the backoff, unless the
+	// report itself times out earlier.
+	publishAttemptTimeout = 10 * time.Second
 )
 
+// publishRetryBackoff is the backoff between publish attempts. Steps is set
+// from the configured max attempts.
+var publishRetryBackoff = wait.Backoff{
+	Duration: 500 * time.Millisecond,
+	Factor:   2,
+	Jitter:   0.1,
+}
+
 // ReportMessage is a message structure used to pass a prowjob status to Pub/Sub topic.s
 type ReportMessage struct {
 	Project string               `json:"project"`
@@ -57,17 +96,35 @@ type ReportMessage struct {
 	JobType prowapi.ProwJobType  `json:"job_type"`
 	JobName string               `json:"job_name"`
 	Message string               `json:"message,omitempty"`
+	// PendingTime, StartTime and CompletionTime are the times the job
+	// started running, was created and completed. Times that aren't set
+	// yet are omitted.
+	PendingTime    *metav1.Time `json:"pending_time,omitempty"`
+	StartTime      *metav1.Time `json:"start_time,omitempty"`
+	CompletionTime *metav1.Time `json:"completion_time,omitempty"`
+	// Duration is the time between StartTime and CompletionTime in
+	// seconds. It is omitted for jobs that aren't complete.
+	Duration *float64 `json:"duration_seconds,omitempty"`
 }
 
 // Client is a reporter client fed to crier controller
 type Client struct {
-	config config.Getter
+	config             config.Getter
+	maxPublishAttempts int
+	backoff            wait.Backoff
+	attemptTimeout     time.Duration
+	// clientOptions are passed to the pubsub client, used for testing.
+	clientOptions []option.ClientOption
 }
 
-// NewReporter creates a new Pub/Sub reporter
-func NewReporter(cfg config.Getter) *Client {
+// NewReporter creates a new Pub/Sub reporter. Transient publish errors are
+// retried with exponential backoff up to maxPublishAttempts times.
+func NewReporter(cfg config.Getter, maxPublishAttempts int) *Client {
 	return &Client{
-		config: cfg,
+		config:             cfg,
+		maxPublishAttempts: maxPublishAttempts,
+		backoff:            publishRetryBackoff,
+		attemptTimeout:     publishAttemptTimeout,
 	}
 }
 
@@ -89,21 +146,33 @@ func findLabels(pj *prowapi.ProwJob, labels ...string) map[string]string {
 	return pubSubMap
 }
 
-// ShouldReport tells if a prowjob should be reported by this reporter
+// ReportTarget returns the project and topic the job is published to.
+func (c *Client) ReportTarget(pj *prowapi.ProwJob) string {
+	pubSubMap := findLabels(pj, PubSubProjectLabel, PubSubTopicLabel)
+	return pubSubMap[PubSubProjectLabel] + "/" + pubSubMap[PubSubTopicLabel]
+}
+
+// ShouldReport tells if a prowjob should be reported by this reporter. Jobs
+// with a topic are reported on every transition to a state of the
+// configured job_states_to_report. Crier doesn't report a state to the same
+// reporter twice, so every transition is only published once.
 func (c *Client) ShouldReport(_ context.Context, _ *logrus.Entry, pj *prowapi.ProwJob) bool {
 	pubSubMap := findLabels(pj, PubSubProjectLabel, PubSubTopicLabel)
-	return pubSubMap[PubSubProjectLabel] != "" && pubSubMap[PubSubTopicLabel] != ""
+	if pubSubMap[PubSubProjectLabel] == "" || pubSubMap[PubSubTopicLabel] == "" {
+		return false
+	}
+	return c.config().Crier.PubSubReporter.ReportsState(pj.Status.State)
 }
 
 // Report takes a prowjob, and generate a pubsub ReportMessage and publish to specific Pub/Sub topic
-// based on Pub/Sub related labels if they exist in this prowjob
+// based on Pub/Sub related labels if they exist in this prowjob, as well as to the configured
+// additional topics. A failure to publish to one topic doesn't keep the message from being
+// published to the others. If publishing keeps failing, the returned result asks crier to try
+// again later, which publishes to all topics again.
 func (c *Client) Report(ctx context.Context, l *logrus.Entry, pj *prowapi.ProwJob) ([]*prowapi.ProwJob, *reconcile.Result, error) {
-	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
-	defer cancel()
-
 	message := c.generateMessageFromPJ(pj)
 	// TODO: Consider caching the pubsub client.
-	client, err := pubsub.NewClient(ctx, message.Project)
+	client, err := pubsub.NewClient(ctx, message.Project, c.clientOptions...)
 	if err != nil {
 		return nil, nil, fmt.Errorf("could not create pubsub Client: %w", err)
 	}
@@ -111,44 +180,197 @@ func (c *Client) Report(ctx context.Context, l *logrus.Entry, pj *prowapi.ProwJo
 		logrus.WithError(client.Close()).Debug("Closed pubsub client.")
 	}()
 
-	l = l.WithFields(logrus.Fields{"project": message.Project, "topic": message.Topic, "run-id": message.RunID, "status": pj.Status.State})
-	l.Debug("Reporting prowjob status to pubsub.")
-	topic := client.Topic(message.Topic)
-	defer topic.Stop() // Sends remaining messages then stops goroutines.
-
+	l = l.WithFields(logrus.Fields{"run-id": message.RunID, "status": pj.Status.State})
 	d, err := json.Marshal(message)
 	if err != nil {
 		l.WithError(err).Debug("Failed marshalling pubsub message.")
 		return nil, nil, fmt.Errorf("could not marshal pubsub report: %w", err)
 	}
 
-	res := topic.Publish(ctx, &pubsub.Message{
-		Data: d,
+	cfg := c.config().Crier.PubSubReporter
+	var orderingKey string
+	if cfg != nil && cfg.EnableOrderingKey {
+		orderingKey = pj.Name
+	}
+	targets := []config.PubSubTopic{{Project: message.Project, Topic: message.Topic}}
+	if cfg != nil {
+		for _, target := range cfg.AdditionalTopics {
+			if target != targets[0] {
+				targets = append(targets, target)
+			}
+		}
+	}
+
+	msg := pubsub.Message{
+		Data:        d,
+		Attributes:  messageAttributes(pj),
+		OrderingKey: orderingKey,
+	}
+	var errs []error
+	onlyUserErrors := true
+	for _, target := range targets {
+		if err := c.publish(ctx, l, client, target, message.RunID, msg); err != nil {
+			errs = append(errs, err)
+			onlyUserErrors = onlyUserErrors && criercommonlib.IsUserError(err)
+		}
+	}
+	if len(errs) == 0 {
+		return []*prowapi.ProwJob{pj}, nil, nil
+	}
+	err = utilerrors.NewAggregate(errs)
+	if onlyUserErrors {
+		return nil, nil, criercommonlib.UserError(err)
+	}
+	return nil, &reconcile.Result{RequeueAfter: publishFailureRequeueAfter}, err
+}
+
+// publish publishes the message to the topic, retrying transient errors.
+// Every attempt gets its own timeout, so a topic that doesn't respond doesn't
+// keep the message from being published to the others, and a slow attempt
+// doesn't use up the time of the retries.
+func (c *Client) publish(ctx context.Context, l *logrus.Entry, client *pubsub.Client, target config.PubSubTopic, runID string, msg pubsub.Message) error {
+	l = l.WithFields(logrus.Fields{"project": target.Project, "topic": target.Topic})
+	l.Debug("Reporting prowjob status to pubsub.")
+	topic := client.TopicInProject(target.Topic, target.Project)
+	defer topic.Stop() // Sends remaining messages then stops goroutines.
+	topic.EnableMessageOrdering = msg.OrderingKey != ""
+	topic.PublishSettings.Timeout = c.attemptTimeout
+
+	backoff := c.backoff
+	backoff.Steps = c.maxPublishAttempts
+	var publishErr error
+	retryErr := wait.ExponentialBackoffWithContext(ctx, backoff, func(ctx context.Context) (bool, error) {
+		ctx, cancel := context.WithTimeout(ctx, c.attemptTimeout)
+		defer cancel()
+		res := topic.Publish(ctx, &pubsub.Message{
+			Data:        msg.Data,
+			Attributes:  msg.Attributes,
+			OrderingKey: msg.OrderingKey,
+		})
+		if _, publishErr = res.Get(ctx); publishErr != nil {
+			l.WithError(publishErr).Debug("Failed sending pubsub message.")
+			if msg.OrderingKey != "" {
+				// Publishing for an ordering key is paused after a failure
+				// until it is explicitly resumed.
+				topic.ResumePublish(msg.OrderingKey)
+			}
+			return false, nil
+		}
+		return true, nil
 	})
+	if retryErr == nil {
+		return nil
+	}
+	if publishErr == nil {
+		publishErr = retryErr
+	}
 
-	_, err = res.Get(ctx)
-	if err != nil {
-		wrappedError := fmt.Errorf(
-			"failed to publish pubsub message with run ID %q to topic: \"%s/%s\". %v",
-			message.RunID, message.Project, message.Topic, err)
-
-		// It would be a user error if the topic doesn't exist, return a user
-		// error in this case so that we can avoid logging on error level.
-		topicExist, existErr := topic.Exists(ctx)
-		if existErr == nil && !topicExist {
-			l.Debug("Pubsub topic doesn't exist.")
-			return nil, nil, criercommonlib.UserError(wrappedError)
+	wrappedError := fmt.Errorf(
+		"failed to publish pubsub message with run ID %q to topic: \"%s/%s\". %v",
+		runID, target.Project, target.Topic, publishErr)
+
+	// It would be a user error if the topic doesn't exist, return a user
+	// error in this case so that we can avoid logging on error level.
+	existsCtx, cancel := context.WithTimeout(ctx, c.attemptTimeout)
+	defer cancel()
+	topicExist, existErr := topic.Exists(existsCtx)
+	if existErr == nil && !topicExist {
+		l.Debug("Pubsub topic doesn't exist.")
+		return criercommonlib.UserError(wrappedError)
+	}
+	return wrappedError
+}
+
+// configuredTopics returns the additional topics and the topics set by the
+// pubsub labels or annotations of the configured jobs. Topics set on jobs
+// that are triggered otherwise, e.g. through the API, aren't known.
+func (c *Client) configuredTopics() []config.PubSubTopic {
+	cfg := c.config()
+	topics := sets.New[config.PubSubTopic]()
+	if cfg.Crier.PubSubReporter != nil {
+		topics.Insert(cfg.Crier.PubSubReporter.AdditionalTopics...)
+	}
+	var jobs []config.JobBase
+	for _, job := range cfg.AllStaticPresubmits(nil) {
+		jobs = append(jobs, job.JobBase)
+	}
+	for _, job := range cfg.AllStaticPostsubmits(nil) {
+		jobs = append(jobs, job.JobBase)
+	}
+	for _, job := range cfg.AllPeriodics() {
+		jobs = append(jobs, job.JobBase)
+	}
+	for _, job := range jobs {
+		pj := &prowapi.ProwJob{ObjectMeta: metav1.ObjectMeta{Labels: job.Labels, Annotations: job.Annotations}}
+		pubSubMap := findLabels(pj, PubSubProjectLabel, PubSubTopicLabel)
+		if pubSubMap[PubSubProjectLabel] != "" && pubSubMap[PubSubTopicLabel] != "" {
+			topics.Insert(config.PubSubTopic{Project: pubSubMap[PubSubProjectLabel], Topic: pubSubMap[PubSubTopicLabel]})
 		}
+	}
+	return topics.UnsortedList()
+}
 
-		l.WithError(err).Debug("Failed sending pubsub message.")
-		return nil, nil, wrappedError
+// CheckConnectivity checks that the additional topics and the topics of the
+// configured jobs exist.
+func (c *Client) CheckConnectivity(ctx context.Context) error {
+	clients := map[string]*pubsub.Client{}
+	defer func() {
+		for _, client := range clients {
+			client.Close()
+		}
+	}()
+	var errs []error
+	for _, target := range c.configuredTopics() {
+		client, ok := clients[target.Project]
+		if !ok {
+			var err error
+			if client, err = pubsub.NewClient(ctx, target.Project, c.clientOptions...); err != nil {
+				errs = append(errs, fmt.Errorf("could not create pubsub client for project %s: %w", target.Project, err))
+				continue
+			}
+			clients[target.Project] = client
+		}
+		exists, err := client.TopicInProject(target.Topic, target.Project).Exists(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("topic %s/%s: %w", target.Project, target.Topic, err))
+		} else if !exists {
+			errs = append(errs, fmt.Errorf("topic %s/%s doesn't exist", target.Project, target.Topic))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// messageAttributes returns the attributes that let subscribers deduplicate
+// and order the messages of a job. They only depend on the job's name and
+// state, so republishing a report yields the same attributes.
+func messageAttributes(pj *prowapi.ProwJob) map[string]string {
+	sequence := strconv.Itoa(reportSequence(pj.Status.State))
+	return map[string]string{
+		ProwJobNameAttribute:    pj.Name,
+		ReportSequenceAttribute: sequence,
+		DedupKeyAttribute:       pj.Name + "/" + sequence,
 	}
+}
 
-	return []*prowapi.ProwJob{pj}, nil, nil
+func reportSequence(state prowapi.ProwJobState) int {
+	switch state {
+	case prowapi.TriggeredState:
+		return 0
+	case prowapi.PendingState:
+		return 1
+	default:
+		return 2
+	}
 }
 
 func (c *Client) generateMessageFromPJ(pj *prowapi.ProwJob) *ReportMessage {
 	pubSubMap := findLabels(pj, PubSubProjectLabel, PubSubTopicLabel, PubSubRunIDLabel)
+	return NewReportMessage(c.config, pj, pubSubMap[PubSubProjectLabel], pubSubMap[PubSubTopicLabel], pubSubMap[PubSubRunIDLabel])
+}
+
+// NewReportMessage creates the ReportMessage of a prowjob. It is shared with
+// reporters for other message brokers so that they use the same schema.
+func NewReportMessage(cfg config.Getter, pj *prowapi.ProwJob, project, topic, runID string) *ReportMessage {
 	var refs []prowapi.Refs
 	if pj.Spec.Refs != nil {
 		refs = append(refs, *pj.Spec.Refs)
@@ -162,7 +384,7 @@ func (c *Client) generateMessageFromPJ(pj *prowapi.ProwJob) *ReportMessage {
 		// * pj.Status.URL: https://prow.k8s.io/view/gs/kubernetes-jenkins/logs/ci-benchmark-microbenchmarks/1258197944759226371
 		// * prefix: https://prow.k8s.io/view/
 		// * storageURLPath: gs/kubernetes-jenkins/logs/ci-benchmark-microbenchmarks/1258197944759226371
-		prefix := c.config().Plank.GetJobURLPrefix(pj)
+		prefix := cfg().Plank.GetJobURLPrefix(pj)
 
 		storageURLPath := strings.TrimPrefix(pj.Status.URL, prefix)
 		if strings.HasPrefix(storageURLPath, api.GCSKeyType) {
@@ -182,16 +404,27 @@ func (c *Client) generateMessageFromPJ(pj *prowapi.ProwJob) *ReportMessage {
 
 	}
 
-	return &ReportMessage{
-		Project: pubSubMap[PubSubProjectLabel],
-		Topic:   pubSubMap[PubSubTopicLabel],
-		RunID:   pubSubMap[PubSubRunIDLabel],
-		Status:  pj.Status.State,
-		URL:     pj.Status.URL,
-		GCSPath: storagePath,
-		Refs:    refs,
-		JobType: pj.Spec.Type,
-		JobName: pj.Spec.Job,
-		Message: pj.Status.Description,
+	message := &ReportMessage{
+		Project:        project,
+		Topic:          topic,
+		RunID:          runID,
+		Status:         pj.Status.State,
+		URL:            pj.Status.URL,
+		GCSPath:        storagePath,
+		Refs:           refs,
+		JobType:        pj.Spec.Type,
+		JobName:        pj.Spec.Job,
+		Message:        pj.Status.Description,
+		PendingTime:    pj.Status.PendingTime,
+		CompletionTime: pj.Status.CompletionTime,
+	}
+	if !pj.Status.StartTime.IsZero() {
+		startTime := pj.Status.StartTime
+		message.StartTime = &startTime
+		if pj.Status.CompletionTime != nil {
+			duration := pj.Status.CompletionTime.Sub(startTime.Time).Seconds()
+			message.Duration = &duration
+		}
 	}
+	return message
 }
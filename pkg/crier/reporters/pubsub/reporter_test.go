@@ -18,15 +18,28 @@ package pubsub
 
 import (
 	"context"
+	"encoding/json"
 	"reflect"
 	"sync"
 	"testing"
+	"time"
 
+	"cloud.google.com/go/pubsub"
+	"cloud.google.com/go/pubsub/apiv1/pubsubpb"
+	"cloud.google.com/go/pubsub/pstest"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
 	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/crier/reporters/criercommonlib"
 )
 
 const (
@@ -349,12 +362,137 @@ func TestGenerateMessageFromPJ(t *testing.T) {
 	}
 }
 
+func TestReportMessageTimes(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	testcases := []struct {
+		name     string
+		status   prowapi.ProwJobStatus
+		expected map[string]interface{}
+		omitted  []string
+	}{
+		{
+			name:    "no times set",
+			status:  prowapi.ProwJobStatus{State: prowapi.TriggeredState},
+			omitted: []string{"pending_time", "start_time", "completion_time", "duration_seconds"},
+		},
+		{
+			name: "pending job",
+			status: prowapi.ProwJobStatus{
+				State:       prowapi.PendingState,
+				StartTime:   metav1.NewTime(start),
+				PendingTime: &metav1.Time{Time: start.Add(30 * time.Second)},
+			},
+			expected: map[string]interface{}{
+				"start_time":   "2024-01-02T03:04:05Z",
+				"pending_time": "2024-01-02T03:04:35Z",
+			},
+			omitted: []string{"completion_time", "duration_seconds"},
+		},
+		{
+			name: "completed job",
+			status: prowapi.ProwJobStatus{
+				State:          prowapi.SuccessState,
+				StartTime:      metav1.NewTime(start),
+				PendingTime:    &metav1.Time{Time: start.Add(30 * time.Second)},
+				CompletionTime: &metav1.Time{Time: start.Add(90 * time.Second)},
+			},
+			expected: map[string]interface{}{
+				"start_time":       "2024-01-02T03:04:05Z",
+				"pending_time":     "2024-01-02T03:04:35Z",
+				"completion_time":  "2024-01-02T03:05:35Z",
+				"duration_seconds": float64(90),
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			pj := &prowapi.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "test1"},
+				Spec:       prowapi.ProwJobSpec{Type: prowapi.PeriodicJob, Job: "test1"},
+				Status:     tc.status,
+			}
+			fca := &fca{c: &config.Config{}}
+			message := NewReportMessage(fca.Config, pj, testPubSubProjectName, testPubSubTopicName, testPubSubRunID)
+			raw, err := json.Marshal(message)
+			if err != nil {
+				t.Fatalf("failed to marshal message: %v", err)
+			}
+			var got map[string]interface{}
+			if err := json.Unmarshal(raw, &got); err != nil {
+				t.Fatalf("failed to unmarshal message: %v", err)
+			}
+			for field, want := range tc.expected {
+				if got[field] != want {
+					t.Errorf("expected %s to be %v, got %v", field, want, got[field])
+				}
+			}
+			for _, field := range tc.omitted {
+				if value, ok := got[field]; ok {
+					t.Errorf("expected %s to be omitted, got %v", field, value)
+				}
+			}
+		})
+	}
+}
+
 func TestShouldReport(t *testing.T) {
 	var testcases = []struct {
 		name           string
 		pj             *prowapi.ProwJob
+		config         *config.PubSubReporter
 		expectedResult bool
 	}{
+		{
+			name: "Running transition is reported by default",
+			pj: &prowapi.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-running",
+					Annotations: map[string]string{
+						PubSubProjectLabel: testPubSubProjectName,
+						PubSubTopicLabel:   testPubSubTopicName,
+					},
+				},
+				Status: prowapi.ProwJobStatus{
+					State: prowapi.PendingState,
+				},
+			},
+			expectedResult: true,
+		},
+		{
+			name: "Running transition is reported if its state is allowed",
+			pj: &prowapi.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-running",
+					Annotations: map[string]string{
+						PubSubProjectLabel: testPubSubProjectName,
+						PubSubTopicLabel:   testPubSubTopicName,
+					},
+				},
+				Status: prowapi.ProwJobStatus{
+					State: prowapi.PendingState,
+				},
+			},
+			config:         &config.PubSubReporter{JobStatesToReport: []prowapi.ProwJobState{prowapi.PendingState, prowapi.SuccessState}},
+			expectedResult: true,
+		},
+		{
+			name: "Transition to a state that isn't allowed is not reported",
+			pj: &prowapi.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-triggered",
+					Annotations: map[string]string{
+						PubSubProjectLabel: testPubSubProjectName,
+						PubSubTopicLabel:   testPubSubTopicName,
+					},
+				},
+				Status: prowapi.ProwJobStatus{
+					State: prowapi.TriggeredState,
+				},
+			},
+			config:         &config.PubSubReporter{JobStatesToReport: []prowapi.ProwJobState{prowapi.PendingState, prowapi.SuccessState}},
+			expectedResult: false,
+		},
 		{
 			name: "Prowjob with all pubsub information labels should return",
 			pj: &prowapi.ProwJob{
@@ -455,10 +593,9 @@ func TestShouldReport(t *testing.T) {
 		},
 	}
 
-	var fakeConfigAgent fca
-	c := NewReporter(fakeConfigAgent.Config)
-
 	for _, tc := range testcases {
+		fakeConfigAgent := &fca{c: &config.Config{ProwConfig: config.ProwConfig{Crier: config.Crier{PubSubReporter: tc.config}}}}
+		c := NewReporter(fakeConfigAgent.Config, DefaultMaxPublishAttempts)
 		r := c.ShouldReport(context.Background(), logrus.NewEntry(logrus.StandardLogger()), tc.pj)
 
 		if r != tc.expectedResult {
@@ -467,3 +604,412 @@ func TestShouldReport(t *testing.T) {
 		}
 	}
 }
+
+func TestReportRetries(t *testing.T) {
+	publishErr := status.Error(codes.FailedPrecondition, "transient")
+	testcases := []struct {
+		name            string
+		createTopic     bool
+		publishErrors   []error
+		backoff         time.Duration
+		attemptTimeout  time.Duration
+		expectResult    *reconcile.Result
+		expectErr       bool
+		expectUserError bool
+	}{
+		{
+			name:          "published on first attempt",
+			createTopic:   true,
+			publishErrors: []error{nil},
+		},
+		{
+			name:          "published after transient failures",
+			createTopic:   true,
+			publishErrors: []error{publishErr, publishErr, nil},
+		},
+		{
+			name:           "retries aren't limited by the timeout of an attempt",
+			createTopic:    true,
+			publishErrors:  []error{publishErr, publishErr, nil},
+			backoff:        100 * time.Millisecond,
+			attemptTimeout: 150 * time.Millisecond,
+		},
+		{
+			name:          "all attempts fail, requeued",
+			createTopic:   true,
+			publishErrors: []error{publishErr, publishErr, publishErr},
+			expectResult:  &reconcile.Result{RequeueAfter: publishFailureRequeueAfter},
+			expectErr:     true,
+		},
+		{
+			name:            "topic doesn't exist, user error without requeue",
+			publishErrors:   []error{status.Error(codes.NotFound, "no topic"), status.Error(codes.NotFound, "no topic"), status.Error(codes.NotFound, "no topic")},
+			expectErr:       true,
+			expectUserError: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := pstest.NewServer()
+			defer srv.Close()
+			srv.SetAutoPublishResponse(false)
+			for i, err := range tc.publishErrors {
+				var resp *pubsubpb.PublishResponse
+				if err == nil {
+					resp = &pubsubpb.PublishResponse{MessageIds: []string{string(rune('a' + i))}}
+				}
+				srv.AddPublishResponse(resp, err)
+			}
+
+			conn, err := grpc.NewClient(srv.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+			if err != nil {
+				t.Fatalf("failed to connect to fake pubsub server: %v", err)
+			}
+			defer conn.Close()
+			opts := []option.ClientOption{option.WithGRPCConn(conn)}
+			if tc.createTopic {
+				client, err := pubsub.NewClient(context.Background(), testPubSubProjectName, opts...)
+				if err != nil {
+					t.Fatalf("failed to create pubsub client: %v", err)
+				}
+				if _, err := client.CreateTopic(context.Background(), testPubSubTopicName); err != nil {
+					t.Fatalf("failed to create topic: %v", err)
+				}
+			}
+
+			fakeConfigAgent := fca{c: &config.Config{}}
+			c := NewReporter(fakeConfigAgent.Config, 3)
+			c.backoff = wait.Backoff{Duration: time.Millisecond, Factor: 2}
+			if tc.backoff != 0 {
+				c.backoff.Duration = tc.backoff
+			}
+			if tc.attemptTimeout != 0 {
+				c.attemptTimeout = tc.attemptTimeout
+			}
+			c.clientOptions = opts
+
+			pj := &prowapi.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+					Annotations: map[string]string{
+						PubSubProjectLabel: testPubSubProjectName,
+						PubSubTopicLabel:   testPubSubTopicName,
+						PubSubRunIDLabel:   testPubSubRunID,
+					},
+				},
+				Status: prowapi.ProwJobStatus{
+					State: prowapi.SuccessState,
+				},
+			}
+			_, result, err := c.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj)
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("expected error: %t, got: %v", tc.expectErr, err)
+			}
+			if tc.expectUserError && !criercommonlib.IsUserError(err) {
+				t.Errorf("expected user error, got: %v", err)
+			}
+			if !reflect.DeepEqual(result, tc.expectResult) {
+				t.Errorf("expected result %v, got %v", tc.expectResult, result)
+			}
+		})
+	}
+}
+
+func TestReportMessageAttributes(t *testing.T) {
+	testcases := []struct {
+		name                string
+		enableOrderingKey   bool
+		expectedOrderingKey string
+	}{
+		{
+			name: "ordering key disabled",
+		},
+		{
+			name:                "ordering key enabled",
+			enableOrderingKey:   true,
+			expectedOrderingKey: "test",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := pstest.NewServer()
+			defer srv.Close()
+			// The reporter closes its client after every report, which
+			// also closes the connection, so each client gets its own.
+			clientOptions := func() []option.ClientOption {
+				conn, err := grpc.NewClient(srv.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+				if err != nil {
+					t.Fatalf("failed to connect to fake pubsub server: %v", err)
+				}
+				t.Cleanup(func() { conn.Close() })
+				return []option.ClientOption{option.WithGRPCConn(conn)}
+			}
+			client, err := pubsub.NewClient(context.Background(), testPubSubProjectName, clientOptions()...)
+			if err != nil {
+				t.Fatalf("failed to create pubsub client: %v", err)
+			}
+			if _, err := client.CreateTopic(context.Background(), testPubSubTopicName); err != nil {
+				t.Fatalf("failed to create topic: %v", err)
+			}
+
+			fakeConfigAgent := fca{c: &config.Config{ProwConfig: config.ProwConfig{Crier: config.Crier{
+				PubSubReporter: &config.PubSubReporter{EnableOrderingKey: tc.enableOrderingKey},
+			}}}}
+			c := NewReporter(fakeConfigAgent.Config, DefaultMaxPublishAttempts)
+
+			pj := &prowapi.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+					Annotations: map[string]string{
+						PubSubProjectLabel: testPubSubProjectName,
+						PubSubTopicLabel:   testPubSubTopicName,
+						PubSubRunIDLabel:   testPubSubRunID,
+					},
+				},
+				Status: prowapi.ProwJobStatus{
+					State: prowapi.PendingState,
+				},
+			}
+			// The pending state is reported twice, as happens when crier
+			// restarts before recording the report.
+			for _, state := range []prowapi.ProwJobState{prowapi.PendingState, prowapi.PendingState, prowapi.SuccessState} {
+				pj.Status.State = state
+				c.clientOptions = clientOptions()
+				if _, _, err := c.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj); err != nil {
+					t.Fatalf("failed to report job in state %s: %v", state, err)
+				}
+			}
+
+			messages := srv.Messages()
+			if len(messages) != 3 {
+				t.Fatalf("expected 3 messages, got %d", len(messages))
+			}
+			expected := []map[string]string{
+				{ProwJobNameAttribute: "test", ReportSequenceAttribute: "1", DedupKeyAttribute: "test/1"},
+				{ProwJobNameAttribute: "test", ReportSequenceAttribute: "1", DedupKeyAttribute: "test/1"},
+				{ProwJobNameAttribute: "test", ReportSequenceAttribute: "2", DedupKeyAttribute: "test/2"},
+			}
+			for i, m := range messages {
+				if !reflect.DeepEqual(m.Attributes, expected[i]) {
+					t.Errorf("message %d: expected attributes %v, got %v", i, expected[i], m.Attributes)
+				}
+				if m.OrderingKey != tc.expectedOrderingKey {
+					t.Errorf("message %d: expected ordering key %q, got %q", i, tc.expectedOrderingKey, m.OrderingKey)
+				}
+			}
+		})
+	}
+}
+
+func TestReportAdditionalTopics(t *testing.T) {
+	testcases := []struct {
+		name             string
+		additionalTopics []config.PubSubTopic
+		createTopics     []string
+		expectedTopics   []string
+		expectErr        bool
+		expectUserError  bool
+	}{
+		{
+			name: "published to the job's topic and the additional topics",
+			additionalTopics: []config.PubSubTopic{
+				{Project: "central-project", Topic: "prowjobs"},
+				{Project: testPubSubProjectName, Topic: "team-topic"},
+			},
+			createTopics:   []string{"projects/test-project/topics/test-topic", "projects/central-project/topics/prowjobs", "projects/test-project/topics/team-topic"},
+			expectedTopics: []string{"projects/test-project/topics/test-topic", "projects/central-project/topics/prowjobs", "projects/test-project/topics/team-topic"},
+		},
+		{
+			name:             "the job's topic isn't published to twice",
+			additionalTopics: []config.PubSubTopic{{Project: testPubSubProjectName, Topic: testPubSubTopicName}},
+			createTopics:     []string{"projects/test-project/topics/test-topic"},
+			expectedTopics:   []string{"projects/test-project/topics/test-topic"},
+		},
+		{
+			name: "missing additional topic doesn't block the others",
+			additionalTopics: []config.PubSubTopic{
+				{Project: "central-project", Topic: "missing"},
+				{Project: testPubSubProjectName, Topic: "team-topic"},
+			},
+			createTopics:    []string{"projects/test-project/topics/test-topic", "projects/test-project/topics/team-topic"},
+			expectedTopics:  []string{"projects/test-project/topics/test-topic", "projects/test-project/topics/team-topic"},
+			expectErr:       true,
+			expectUserError: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := pstest.NewServer()
+			defer srv.Close()
+			for _, topic := range tc.createTopics {
+				if _, err := srv.GServer.CreateTopic(context.Background(), &pubsubpb.Topic{Name: topic}); err != nil {
+					t.Fatalf("failed to create topic %s: %v", topic, err)
+				}
+			}
+			conn, err := grpc.NewClient(srv.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+			if err != nil {
+				t.Fatalf("failed to connect to fake pubsub server: %v", err)
+			}
+			defer conn.Close()
+
+			fakeConfigAgent := fca{c: &config.Config{ProwConfig: config.ProwConfig{Crier: config.Crier{
+				PubSubReporter: &config.PubSubReporter{AdditionalTopics: tc.additionalTopics},
+			}}}}
+			c := NewReporter(fakeConfigAgent.Config, 1)
+			c.clientOptions = []option.ClientOption{option.WithGRPCConn(conn)}
+
+			pj := &prowapi.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+					Annotations: map[string]string{
+						PubSubProjectLabel: testPubSubProjectName,
+						PubSubTopicLabel:   testPubSubTopicName,
+						PubSubRunIDLabel:   testPubSubRunID,
+					},
+				},
+				Status: prowapi.ProwJobStatus{State: prowapi.SuccessState},
+			}
+			_, _, err = c.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj)
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("expected error: %t, got: %v", tc.expectErr, err)
+			}
+			if tc.expectUserError && !criercommonlib.IsUserError(err) {
+				t.Errorf("expected user error, got: %v", err)
+			}
+
+			var topics []string
+			for _, m := range srv.Messages() {
+				topics = append(topics, m.Topic)
+			}
+			if !reflect.DeepEqual(topics, tc.expectedTopics) {
+				t.Errorf("expected messages on topics %v, got %v", tc.expectedTopics, topics)
+			}
+		})
+	}
+}
+
+func TestReportRunningTransition(t *testing.T) {
+	srv := pstest.NewServer()
+	defer srv.Close()
+	clientOptions := func() []option.ClientOption {
+		conn, err := grpc.NewClient(srv.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			t.Fatalf("failed to connect to fake pubsub server: %v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		return []option.ClientOption{option.WithGRPCConn(conn)}
+	}
+	client, err := pubsub.NewClient(context.Background(), testPubSubProjectName, clientOptions()...)
+	if err != nil {
+		t.Fatalf("failed to create pubsub client: %v", err)
+	}
+	if _, err := client.CreateTopic(context.Background(), testPubSubTopicName); err != nil {
+		t.Fatalf("failed to create topic: %v", err)
+	}
+
+	fakeConfigAgent := fca{c: &config.Config{ProwConfig: config.ProwConfig{Crier: config.Crier{
+		PubSubReporter: &config.PubSubReporter{JobStatesToReport: []prowapi.ProwJobState{prowapi.PendingState, prowapi.SuccessState}},
+	}}}}
+	c := NewReporter(fakeConfigAgent.Config, DefaultMaxPublishAttempts)
+
+	pj := &prowapi.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test",
+			Annotations: map[string]string{
+				PubSubProjectLabel: testPubSubProjectName,
+				PubSubTopicLabel:   testPubSubTopicName,
+				PubSubRunIDLabel:   testPubSubRunID,
+			},
+		},
+	}
+	// Like crier, only report states that weren't reported before, so the
+	// pending state that is seen twice is published once.
+	var reported prowapi.ProwJobState
+	for _, state := range []prowapi.ProwJobState{prowapi.TriggeredState, prowapi.PendingState, prowapi.PendingState, prowapi.SuccessState} {
+		pj.Status.State = state
+		if reported == state || !c.ShouldReport(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj) {
+			continue
+		}
+		c.clientOptions = clientOptions()
+		if _, _, err := c.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj); err != nil {
+			t.Fatalf("failed to report job in state %s: %v", state, err)
+		}
+		reported = state
+	}
+
+	var states []prowapi.ProwJobState
+	for _, m := range srv.Messages() {
+		var message ReportMessage
+		if err := json.Unmarshal(m.Data, &message); err != nil {
+			t.Fatalf("failed to unmarshal message: %v", err)
+		}
+		states = append(states, message.Status)
+	}
+	if expected := []prowapi.ProwJobState{prowapi.PendingState, prowapi.SuccessState}; !reflect.DeepEqual(states, expected) {
+		t.Errorf("expected states %v to be published, got %v", expected, states)
+	}
+}
+
+func TestCheckConnectivity(t *testing.T) {
+	testCases := []struct {
+		name             string
+		additionalTopics []config.PubSubTopic
+		jobAnnotations   map[string]string
+		createTopics     []string
+		expectErr        bool
+	}{
+		{
+			name: "no topics configured",
+		},
+		{
+			name:             "existing topics",
+			additionalTopics: []config.PubSubTopic{{Project: testPubSubProjectName, Topic: "team-topic"}},
+			jobAnnotations:   map[string]string{PubSubProjectLabel: testPubSubProjectName, PubSubTopicLabel: testPubSubTopicName},
+			createTopics:     []string{"projects/test-project/topics/test-topic", "projects/test-project/topics/team-topic"},
+		},
+		{
+			name:             "missing additional topic",
+			additionalTopics: []config.PubSubTopic{{Project: testPubSubProjectName, Topic: "team-topic"}},
+			createTopics:     []string{"projects/test-project/topics/test-topic"},
+			expectErr:        true,
+		},
+		{
+			name:           "missing topic of a job",
+			jobAnnotations: map[string]string{PubSubProjectLabel: testPubSubProjectName, PubSubTopicLabel: testPubSubTopicName},
+			expectErr:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := pstest.NewServer()
+			defer srv.Close()
+			for _, topic := range tc.createTopics {
+				if _, err := srv.GServer.CreateTopic(context.Background(), &pubsubpb.Topic{Name: topic}); err != nil {
+					t.Fatalf("failed to create topic %s: %v", topic, err)
+				}
+			}
+			conn, err := grpc.NewClient(srv.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+			if err != nil {
+				t.Fatalf("failed to connect to fake pubsub server: %v", err)
+			}
+			defer conn.Close()
+
+			fakeConfigAgent := fca{c: &config.Config{
+				JobConfig: config.JobConfig{Periodics: []config.Periodic{{JobBase: config.JobBase{Name: "periodic", Annotations: tc.jobAnnotations}}}},
+				ProwConfig: config.ProwConfig{Crier: config.Crier{
+					PubSubReporter: &config.PubSubReporter{AdditionalTopics: tc.additionalTopics},
+				}},
+			}}
+			c := NewReporter(fakeConfigAgent.Config, 1)
+			c.clientOptions = []option.ClientOption{option.WithGRPCConn(conn)}
+
+			if err := c.CheckConnectivity(context.Background()); (err != nil) != tc.expectErr {
+				t.Errorf("expected error: %t, got: %v", tc.expectErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pushgateway contains a reporter that pushes the result of every
+// completed prowjob to a Prometheus Pushgateway.
+package pushgateway
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+)
+
+const (
+	reporterName = "pushgatewayreporter"
+
+	// RepoGroupingKey is the grouping key, next to the job name, of the
+	// metrics pushed for a job.
+	RepoGroupingKey = "repo"
+)
+
+// stateValues are the values of the state metric, per job state.
+var stateValues = map[prowapi.ProwJobState]float64{
+	prowapi.SuccessState: 0,
+	prowapi.FailureState: 1,
+	prowapi.AbortedState: 2,
+	prowapi.ErrorState:   3,
+}
+
+type pushgatewayReporter struct {
+	url    string
+	client *http.Client
+	dryRun bool
+}
+
+// New returns a reporter that pushes the results of jobs to the
+// Pushgateway at the given URL. Requests are sent with the transport of
+// httpClient, or of http.DefaultClient if nil, and time out after 10s.
+func New(url string, dryRun bool, httpClient *http.Client) *pushgatewayReporter {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	client := *httpClient
+	client.Timeout = 10 * time.Second
+	return &pushgatewayReporter{
+		url:    url,
+		client: &client,
+		dryRun: dryRun,
+	}
+}
+
+func (pr *pushgatewayReporter) GetName() string {
+	return reporterName
+}
+
+func (pr *pushgatewayReporter) ShouldReport(_ context.Context, _ *logrus.Entry, pj *prowapi.ProwJob) bool {
+	return pj.Complete()
+}
+
+func (pr *pushgatewayReporter) Report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) ([]*prowapi.ProwJob, *reconcile.Result, error) {
+	return []*prowapi.ProwJob{pj}, nil, pr.report(ctx, log, pj)
+}
+
+// report pushes the state and duration of the job. The metrics are grouped
+// by the job name and repo, and pushed with PUT, so that every push replaces
+// the metrics of the previous run of the same job instead of adding to them.
+func (pr *pushgatewayReporter) report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) error {
+	value, ok := stateValues[pj.Status.State]
+	if !ok {
+		return nil
+	}
+	state := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "prowjob_state",
+		Help: "State of the last run of the job: 0 for success, 1 for failure, 2 for aborted and 3 for error.",
+	})
+	state.Set(value)
+	duration := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "prowjob_duration_seconds",
+		Help: "Duration of the last run of the job in seconds.",
+	})
+	duration.Set(jobDuration(pj).Seconds())
+
+	repo := jobRepo(pj)
+	log = log.WithFields(logrus.Fields{"job": pj.Spec.Job, RepoGroupingKey: repo})
+	if pr.dryRun {
+		log.WithField("state", pj.Status.State).Debug("Skipping reporting because dry-run is enabled")
+		return nil
+	}
+
+	if err := push.New(pr.url, pj.Spec.Job).
+		Client(pr.client).
+		Grouping(RepoGroupingKey, repo).
+		Collector(state).
+		Collector(duration).
+		PushContext(ctx); err != nil {
+		return fmt.Errorf("failed to push metrics of job %q to %s: %w", pj.Spec.Job, pr.url, err)
+	}
+	return nil
+}
+
+// jobRepo returns the org/repo the job ran for, or an empty string for jobs
+// without refs.
+func jobRepo(pj *prowapi.ProwJob) string {
+	refs := pj.Spec.Refs
+	if refs == nil && len(pj.Spec.ExtraRefs) > 0 {
+		refs = &pj.Spec.ExtraRefs[0]
+	}
+	if refs == nil {
+		return ""
+	}
+	return refs.Org + "/" + refs.Repo
+}
+
+func jobDuration(pj *prowapi.ProwJob) time.Duration {
+	if pj.Status.CompletionTime == nil {
+		return 0
+	}
+	return pj.Status.CompletionTime.Sub(pj.Status.StartTime.Time)
+}
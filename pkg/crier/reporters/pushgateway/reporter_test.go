@@ -0,0 +1,182 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pushgateway
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+)
+
+type pushedRequest struct {
+	method string
+	path   string
+	body   string
+}
+
+func TestReport(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name     string
+		pj       *prowapi.ProwJob
+		dryRun   bool
+		expected []pushedRequest
+	}{
+		{
+			name: "presubmit failure",
+			pj: &prowapi.ProwJob{
+				Spec: prowapi.ProwJobSpec{
+					Job:  "pull-unit",
+					Refs: &prowapi.Refs{Org: "org", Repo: "repo"},
+				},
+				Status: prowapi.ProwJobStatus{
+					State:          prowapi.FailureState,
+					StartTime:      metav1.NewTime(start),
+					CompletionTime: &metav1.Time{Time: start.Add(90 * time.Second)},
+				},
+			},
+			expected: []pushedRequest{{
+				method: http.MethodPut,
+				path:   "/metrics/job/pull-unit/repo@base64/b3JnL3JlcG8",
+				body:   "prowjob_duration_seconds 90\nprowjob_state 1\n",
+			}},
+		},
+		{
+			name: "periodic with extra refs",
+			pj: &prowapi.ProwJob{
+				Spec: prowapi.ProwJobSpec{
+					Job:       "periodic-e2e",
+					ExtraRefs: []prowapi.Refs{{Org: "org", Repo: "other"}},
+				},
+				Status: prowapi.ProwJobStatus{
+					State:          prowapi.SuccessState,
+					StartTime:      metav1.NewTime(start),
+					CompletionTime: &metav1.Time{Time: start.Add(time.Minute)},
+				},
+			},
+			expected: []pushedRequest{{
+				method: http.MethodPut,
+				path:   "/metrics/job/periodic-e2e/repo@base64/b3JnL290aGVy",
+				body:   "prowjob_duration_seconds 60\nprowjob_state 0\n",
+			}},
+		},
+		{
+			name: "periodic without refs",
+			pj: &prowapi.ProwJob{
+				Spec: prowapi.ProwJobSpec{Job: "periodic-cleanup"},
+				Status: prowapi.ProwJobStatus{
+					State:          prowapi.ErrorState,
+					StartTime:      metav1.NewTime(start),
+					CompletionTime: &metav1.Time{Time: start.Add(time.Second)},
+				},
+			},
+			expected: []pushedRequest{{
+				method: http.MethodPut,
+				path:   "/metrics/job/periodic-cleanup/repo@base64/=",
+				body:   "prowjob_duration_seconds 1\nprowjob_state 3\n",
+			}},
+		},
+		{
+			name: "dry run",
+			pj: &prowapi.ProwJob{
+				Spec: prowapi.ProwJobSpec{Job: "pull-unit"},
+				Status: prowapi.ProwJobStatus{
+					State:          prowapi.SuccessState,
+					CompletionTime: &metav1.Time{Time: start},
+				},
+			},
+			dryRun: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var pushed []pushedRequest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				pushed = append(pushed, pushedRequest{method: r.Method, path: r.URL.Path, body: samples(t, r)})
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			reporter := New(server.URL, tc.dryRun, nil)
+			if _, _, err := reporter.Report(context.Background(), logrus.WithField("test", tc.name), tc.pj); err != nil {
+				t.Fatalf("Report: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, pushed, cmp.AllowUnexported(pushedRequest{})); diff != "" {
+				t.Errorf("pushed requests differ from expected (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReportError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	pj := &prowapi.ProwJob{
+		Spec:   prowapi.ProwJobSpec{Job: "pull-unit"},
+		Status: prowapi.ProwJobStatus{State: prowapi.SuccessState, CompletionTime: &metav1.Time{}},
+	}
+	if _, _, err := New(server.URL, false, nil).Report(context.Background(), logrus.NewEntry(logrus.New()), pj); err == nil {
+		t.Error("expected an error when the push is rejected")
+	}
+}
+
+func TestShouldReport(t *testing.T) {
+	reporter := New("http://pushgateway", false, nil)
+	log := logrus.NewEntry(logrus.New())
+	if reporter.ShouldReport(context.Background(), log, &prowapi.ProwJob{Status: prowapi.ProwJobStatus{State: prowapi.PendingState}}) {
+		t.Error("expected pending jobs not to be reported")
+	}
+	if !reporter.ShouldReport(context.Background(), log, &prowapi.ProwJob{Status: prowapi.ProwJobStatus{State: prowapi.SuccessState, CompletionTime: &metav1.Time{}}}) {
+		t.Error("expected completed jobs to be reported")
+	}
+}
+
+// samples returns the name and value of the pushed samples, one per line.
+func samples(t *testing.T, r *http.Request) string {
+	decoder := expfmt.NewDecoder(r.Body, expfmt.ResponseFormat(r.Header))
+	var b strings.Builder
+	for {
+		var family dto.MetricFamily
+		if err := decoder.Decode(&family); err != nil {
+			if err != io.EOF {
+				t.Errorf("failed to decode pushed metrics: %v", err)
+			}
+			break
+		}
+		for _, m := range family.GetMetric() {
+			fmt.Fprintf(&b, "%s %v\n", family.GetName(), m.GetGauge().GetValue())
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package redis contains a reporter that adds prowjob summaries to Redis
+// streams.
+package redis
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/yaml"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/crier/reporters/criercommonlib"
+)
+
+const (
+	reporterName = "redisreporter"
+
+	// addFailureRequeueAfter is how long crier waits before trying to
+	// report a job again once adding the entry failed, e.g. because the
+	// server couldn't be reached.
+	addFailureRequeueAfter = time.Minute
+)
+
+// Credentials are the credentials used to authenticate with the server.
+// The username is only needed for users of Redis ACLs other than the
+// default user.
+type Credentials struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password"`
+}
+
+type streamClient interface {
+	XAdd(ctx context.Context, a *redis.XAddArgs) *redis.StringCmd
+}
+
+type redisReporter struct {
+	config    func(*prowapi.Refs) config.RedisReporter
+	dryRun    bool
+	clientFor func(cfg config.RedisReporter) (streamClient, error)
+}
+
+func (rr *redisReporter) getConfig(pj *prowapi.ProwJob) config.RedisReporter {
+	refs := pj.Spec.Refs
+	if refs == nil && len(pj.Spec.ExtraRefs) > 0 {
+		refs = &pj.Spec.ExtraRefs[0]
+	}
+	return rr.config(refs)
+}
+
+// entryFields returns the fields of the stream entry summarizing the job.
+func entryFields(pj *prowapi.ProwJob) map[string]interface{} {
+	fields := map[string]interface{}{
+		"job":          pj.Spec.Job,
+		"prowjob_name": pj.Name,
+		"job_type":     string(pj.Spec.Type),
+		"state":        string(pj.Status.State),
+		"build_id":     pj.Status.BuildID,
+		"url":          pj.Status.URL,
+	}
+	refs := pj.Spec.Refs
+	if refs == nil && len(pj.Spec.ExtraRefs) > 0 {
+		refs = &pj.Spec.ExtraRefs[0]
+	}
+	if refs != nil {
+		fields["repo"] = refs.Org + "/" + refs.Repo
+		if refs.BaseRef != "" {
+			fields["base_ref"] = refs.BaseRef
+		}
+		if len(refs.Pulls) > 0 {
+			fields["pull"] = refs.Pulls[0].Number
+		}
+	}
+	return fields
+}
+
+// Report adds an entry summarizing the job to the configured stream. If
+// the server can't be reached, the returned result asks crier to try again
+// later.
+func (rr *redisReporter) Report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) ([]*prowapi.ProwJob, *reconcile.Result, error) {
+	cfg := rr.getConfig(pj)
+	args := &redis.XAddArgs{
+		Stream: cfg.Stream,
+		MaxLen: cfg.MaxLen,
+		Approx: cfg.MaxLen > 0,
+		Values: entryFields(pj),
+	}
+
+	log = log.WithField("stream", cfg.Stream)
+	if rr.dryRun {
+		log.WithField("fields", args.Values).Debug("Skipping reporting because dry-run is enabled")
+		return []*prowapi.ProwJob{pj}, nil, nil
+	}
+
+	client, err := rr.clientFor(cfg)
+	if err != nil {
+		return nil, nil, criercommonlib.UserError(fmt.Errorf("could not create redis client: %w", err))
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if err := client.XAdd(ctx, args).Err(); err != nil {
+		err = fmt.Errorf("failed to add entry to redis stream %q: %w", cfg.Stream, err)
+		if isUserError(err) {
+			return nil, nil, criercommonlib.UserError(err)
+		}
+		return nil, &reconcile.Result{RequeueAfter: addFailureRequeueAfter}, err
+	}
+	return []*prowapi.ProwJob{pj}, nil, nil
+}
+
+// transientErrorPrefixes are the prefixes of errors replied by the server
+// that go away by themselves, e.g. while it loads its dataset.
+var transientErrorPrefixes = []string{"LOADING", "BUSY", "TRYAGAIN", "MASTERDOWN", "READONLY", "CLUSTERDOWN"}
+
+// isUserError returns whether the error is caused by the configuration,
+// e.g. rejected credentials or a key that isn't a stream, so that retrying
+// doesn't help. Errors replied by the server are caused by the
+// configuration unless they are transient, while connection errors are
+// not.
+func isUserError(err error) bool {
+	for _, prefix := range transientErrorPrefixes {
+		if redis.HasErrorPrefix(err, prefix) {
+			return false
+		}
+	}
+	var redisErr redis.Error
+	return errors.As(err, &redisErr)
+}
+
+func (rr *redisReporter) GetName() string {
+	return reporterName
+}
+
+func (rr *redisReporter) ShouldReport(_ context.Context, logger *logrus.Entry, pj *prowapi.ProwJob) bool {
+	cfg := rr.getConfig(pj)
+	if cfg.Stream == "" {
+		return false
+	}
+
+	shouldReport := criercommonlib.ShouldReportJob(pj, cfg.JobTypesToReport, cfg.JobStatesToReport)
+	logger.WithField("reporting", shouldReport).Debug("Determined should report")
+	return shouldReport
+}
+
+type cachedClient struct {
+	client      *redis.Client
+	credentials string
+}
+
+// clientCache creates one client per server and database. Clients are
+// recreated when the credentials change.
+type clientCache struct {
+	credentials func() []byte
+	lock        sync.Mutex
+	clients     map[string]cachedClient
+}
+
+func (c *clientCache) clientFor(cfg config.RedisReporter) (streamClient, error) {
+	var credentials []byte
+	if c.credentials != nil {
+		credentials = c.credentials()
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	key := fmt.Sprintf("%s/%d/%t", cfg.Address, cfg.DB, cfg.TLS)
+	if cached, ok := c.clients[key]; ok {
+		if cached.credentials == string(credentials) {
+			return cached.client, nil
+		}
+		go cached.client.Close()
+	}
+	opts := &redis.Options{
+		Addr: cfg.Address,
+		DB:   cfg.DB,
+		// Failed adds are retried by requeueing the job.
+		MaxRetries: -1,
+	}
+	if len(credentials) > 0 {
+		var creds Credentials
+		if err := yaml.Unmarshal(credentials, &creds); err != nil {
+			return nil, fmt.Errorf("failed to parse credentials: %w", err)
+		}
+		opts.Username = creds.Username
+		opts.Password = creds.Password
+	}
+	if cfg.TLS {
+		opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	client := redis.NewClient(opts)
+	c.clients[key] = cachedClient{client: client, credentials: string(credentials)}
+	return client, nil
+}
+
+// New returns a Redis reporter. credentials returns the YAML or JSON
+// Credentials the servers are authenticated with and may be nil.
+func New(cfg func(refs *prowapi.Refs) config.RedisReporter, dryRun bool, credentials func() []byte) *redisReporter {
+	cache := &clientCache{
+		credentials: credentials,
+		clients:     map[string]cachedClient{},
+	}
+	return &redisReporter{
+		config:    cfg,
+		dryRun:    dryRun,
+		clientFor: cache.clientFor,
+	}
+}
@@ -0,0 +1,244 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"context"
+	"syscall"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/crier/reporters/criercommonlib"
+)
+
+// redisReplyError is an error replied by the server.
+type redisReplyError string
+
+func (e redisReplyError) Error() string { return string(e) }
+
+func (redisReplyError) RedisError() {}
+
+type fakeClient struct {
+	err  error
+	adds []*redis.XAddArgs
+}
+
+func (f *fakeClient) XAdd(_ context.Context, a *redis.XAddArgs) *redis.StringCmd {
+	f.adds = append(f.adds, a)
+	return redis.NewStringResult("1-0", f.err)
+}
+
+func TestShouldReport(t *testing.T) {
+	testCases := []struct {
+		name     string
+		config   config.RedisReporter
+		pj       *prowapi.ProwJob
+		expected bool
+	}{
+		{
+			name: "matching type and state should report",
+			config: config.RedisReporter{
+				Stream:            "prow",
+				JobTypesToReport:  []prowapi.ProwJobType{prowapi.PeriodicJob},
+				JobStatesToReport: []prowapi.ProwJobState{prowapi.FailureState},
+			},
+			pj: &prowapi.ProwJob{
+				Spec:   prowapi.ProwJobSpec{Type: prowapi.PeriodicJob},
+				Status: prowapi.ProwJobStatus{State: prowapi.FailureState},
+			},
+			expected: true,
+		},
+		{
+			name: "wrong state should not report",
+			config: config.RedisReporter{
+				Stream:            "prow",
+				JobTypesToReport:  []prowapi.ProwJobType{prowapi.PeriodicJob},
+				JobStatesToReport: []prowapi.ProwJobState{prowapi.FailureState},
+			},
+			pj: &prowapi.ProwJob{
+				Spec:   prowapi.ProwJobSpec{Type: prowapi.PeriodicJob},
+				Status: prowapi.ProwJobStatus{State: prowapi.SuccessState},
+			},
+			expected: false,
+		},
+		{
+			name: "no stream should not report",
+			config: config.RedisReporter{
+				JobTypesToReport:  []prowapi.ProwJobType{prowapi.PeriodicJob},
+				JobStatesToReport: []prowapi.ProwJobState{prowapi.FailureState},
+			},
+			pj: &prowapi.ProwJob{
+				Spec:   prowapi.ProwJobSpec{Type: prowapi.PeriodicJob},
+				Status: prowapi.ProwJobStatus{State: prowapi.FailureState},
+			},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reporter := &redisReporter{
+				config: func(*prowapi.Refs) config.RedisReporter { return tc.config },
+			}
+			if result := reporter.ShouldReport(context.Background(), logrus.NewEntry(logrus.StandardLogger()), tc.pj); result != tc.expected {
+				t.Errorf("expected result to be %t but was %t", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestReport(t *testing.T) {
+	pj := &prowapi.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "abc-123"},
+		Spec: prowapi.ProwJobSpec{
+			Job:  "pull-unit",
+			Type: prowapi.PresubmitJob,
+			Refs: &prowapi.Refs{Org: "org", Repo: "repo", BaseRef: "main", Pulls: []prowapi.Pull{{Number: 42}}},
+		},
+		Status: prowapi.ProwJobStatus{
+			State:   prowapi.FailureState,
+			BuildID: "1",
+			URL:     "https://prow.example.com/view/pull-unit/1",
+		},
+	}
+	expectedFields := map[string]interface{}{
+		"job":          "pull-unit",
+		"prowjob_name": "abc-123",
+		"job_type":     "presubmit",
+		"state":        "failure",
+		"build_id":     "1",
+		"url":          "https://prow.example.com/view/pull-unit/1",
+		"repo":         "org/repo",
+		"base_ref":     "main",
+		"pull":         42,
+	}
+
+	testCases := []struct {
+		name            string
+		config          config.RedisReporter
+		dryRun          bool
+		err             error
+		expectAdds      []*redis.XAddArgs
+		expectErr       bool
+		expectUserError bool
+		expectResult    *reconcile.Result
+	}{
+		{
+			name:       "entry is added",
+			config:     config.RedisReporter{Stream: "prow"},
+			expectAdds: []*redis.XAddArgs{{Stream: "prow", Values: expectedFields}},
+		},
+		{
+			name:       "stream is trimmed",
+			config:     config.RedisReporter{Stream: "prow", MaxLen: 1000},
+			expectAdds: []*redis.XAddArgs{{Stream: "prow", MaxLen: 1000, Approx: true, Values: expectedFields}},
+		},
+		{
+			name:         "connection errors are requeued",
+			config:       config.RedisReporter{Stream: "prow"},
+			err:          syscall.ECONNREFUSED,
+			expectAdds:   []*redis.XAddArgs{{Stream: "prow", Values: expectedFields}},
+			expectErr:    true,
+			expectResult: &reconcile.Result{RequeueAfter: addFailureRequeueAfter},
+		},
+		{
+			name:         "transient server errors are requeued",
+			config:       config.RedisReporter{Stream: "prow"},
+			err:          redisReplyError("LOADING Redis is loading the dataset in memory"),
+			expectAdds:   []*redis.XAddArgs{{Stream: "prow", Values: expectedFields}},
+			expectErr:    true,
+			expectResult: &reconcile.Result{RequeueAfter: addFailureRequeueAfter},
+		},
+		{
+			name:            "rejected credentials are not requeued",
+			config:          config.RedisReporter{Stream: "prow"},
+			err:             redisReplyError("WRONGPASS invalid username-password pair or user is disabled."),
+			expectAdds:      []*redis.XAddArgs{{Stream: "prow", Values: expectedFields}},
+			expectErr:       true,
+			expectUserError: true,
+		},
+		{
+			name:   "dry-run does not add",
+			config: config.RedisReporter{Stream: "prow"},
+			dryRun: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &fakeClient{err: tc.err}
+			reporter := &redisReporter{
+				config:    func(*prowapi.Refs) config.RedisReporter { return tc.config },
+				dryRun:    tc.dryRun,
+				clientFor: func(config.RedisReporter) (streamClient, error) { return client, nil },
+			}
+
+			_, result, err := reporter.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj)
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("expected error: %t, got: %v", tc.expectErr, err)
+			}
+			if isUserErr := criercommonlib.IsUserError(err); isUserErr != tc.expectUserError {
+				t.Errorf("expected user error: %t, got %v", tc.expectUserError, err)
+			}
+			if diff := cmp.Diff(tc.expectResult, result); diff != "" {
+				t.Errorf("result differs from expected (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectAdds, client.adds); diff != "" {
+				t.Errorf("added entries differ from expected (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestClientFor(t *testing.T) {
+	credentials := "password: secret"
+	cache := &clientCache{
+		credentials: func() []byte { return []byte(credentials) },
+		clients:     map[string]cachedClient{},
+	}
+	cfg := config.RedisReporter{Address: "redis:6379", Stream: "prow"}
+
+	first, err := cache.clientFor(cfg)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if second, _ := cache.clientFor(cfg); second != first {
+		t.Error("expected the client to be reused")
+	}
+	if password := first.(*redis.Client).Options().Password; password != "secret" {
+		t.Errorf("expected password %q, got %q", "secret", password)
+	}
+
+	credentials = "password: rotated"
+	rotated, err := cache.clientFor(cfg)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if rotated == first {
+		t.Error("expected the client to be recreated after the credentials changed")
+	}
+	if password := rotated.(*redis.Client).Options().Password; password != "rotated" {
+		t.Errorf("expected password %q, got %q", "rotated", password)
+	}
+}
@@ -38,15 +38,18 @@ type Reporter struct {
 	opener   io.Opener
 	uploader *resultstore.Uploader
 	dirOnly  bool
+	coverage bool
 }
 
-// New returns a new Reporter.
-func New(cfg config.Getter, opener io.Opener, uploader *resultstore.Uploader, dirOnly bool) *Reporter {
+// New returns a new Reporter. If coverage is set, the coverage read from the
+// job's resultstore.CoverageFile artifact is reported as well.
+func New(cfg config.Getter, opener io.Opener, uploader *resultstore.Uploader, dirOnly, coverage bool) *Reporter {
 	return &Reporter{
 		cfg:      cfg,
 		opener:   opener,
 		uploader: uploader,
 		dirOnly:  dirOnly,
+		coverage: coverage,
 	}
 }
 
@@ -112,16 +115,43 @@ func (r *Reporter) Report(ctx context.Context, log *logrus.Entry, pj *v1.ProwJob
 		// Log and continue in case of errors.
 		log.WithError(err).Errorf("error reading artifact files from %q", path)
 	}
+	var coverage map[string]float64
+	if r.coverage {
+		coverage = readCoverageFile(ctx, log, r.opener, path)
+	}
 	err = r.uploader.Upload(ctx, log, &resultstore.Payload{
-		Job:       pj,
-		Started:   started,
-		Finished:  finished,
-		Files:     files,
-		ProjectID: projectID(pj),
+		Job:              pj,
+		Started:          started,
+		Finished:         finished,
+		Files:            files,
+		ProjectID:        projectID(pj),
+		Coverage:         coverage,
+		CustomProperties: r.cfg().Crier.ResultStorePropertiesFor(pj),
 	})
 	return []*v1.ProwJob{pj}, nil, err
 }
 
+// readCoverageFile returns the coverage of the job, or nil if the job has
+// no coverage artifact.
+func readCoverageFile(ctx context.Context, log *logrus.Entry, opener io.Opener, dir string) map[string]float64 {
+	n := dir + "/artifacts/" + resultstore.CoverageFile
+	bs, err := io.ReadContent(ctx, log, opener, n)
+	if err != nil {
+		if io.IsNotExist(err) {
+			log.Debugf("No coverage found at %q", n)
+		} else {
+			log.WithError(err).Warnf("Failed to read %q", n)
+		}
+		return nil
+	}
+	coverage, err := resultstore.ParseCoverage(bs)
+	if err != nil {
+		log.WithError(err).Warnf("Failed to parse %q", n)
+		return nil
+	}
+	return coverage
+}
+
 func readFinishedFile(ctx context.Context, log *logrus.Entry, opener io.Opener, dir string) *metadata.Finished {
 	n := dir + "/" + v1.FinishedStatusFile
 	bs, err := io.ReadContent(ctx, log, opener, n)
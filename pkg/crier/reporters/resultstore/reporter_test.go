@@ -17,10 +17,12 @@ limitations under the License.
 package resultstore
 
 import (
+	"bytes"
 	"context"
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
@@ -38,7 +40,7 @@ func (fcg fakeConfigGetter) Config() *config.Config {
 }
 
 func TestGetName(t *testing.T) {
-	gr := New(fakeConfigGetter{}.Config, &fakeopener.FakeOpener{}, &resultstore.Uploader{}, false)
+	gr := New(fakeConfigGetter{}.Config, &fakeopener.FakeOpener{}, &resultstore.Uploader{}, false, false)
 	want := "resultstorereporter"
 	if got := gr.GetName(); got != want {
 		t.Errorf("GetName() got %v, want %v", got, want)
@@ -272,7 +274,7 @@ func TestShouldReport(t *testing.T) {
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			gr := New(fakeConfigGetter{}.Config, &fakeopener.FakeOpener{}, &resultstore.Uploader{}, false)
+			gr := New(fakeConfigGetter{}.Config, &fakeopener.FakeOpener{}, &resultstore.Uploader{}, false, false)
 			result := gr.ShouldReport(context.Background(), logrus.NewEntry(logrus.StandardLogger()), tc.job)
 			if result != tc.shouldReport {
 				t.Errorf("ShouldReport() got %v, want %v", result, tc.shouldReport)
@@ -280,3 +282,37 @@ func TestShouldReport(t *testing.T) {
 		})
 	}
 }
+
+func TestReadCoverageFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content map[string]string
+		want    map[string]float64
+	}{
+		{
+			name:    "coverage is read from the artifacts dir",
+			content: map[string]string{"gs://bucket/logs/job/1/artifacts/coverage.json": `{"lines": 81.5}`},
+			want:    map[string]float64{"lines": 81.5},
+		},
+		{
+			name: "missing coverage is skipped",
+		},
+		{
+			name:    "invalid coverage is skipped",
+			content: map[string]string{"gs://bucket/logs/job/1/artifacts/coverage.json": `not json`},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			opener := &fakeopener.FakeOpener{Buffer: map[string]*bytes.Buffer{}}
+			for path, content := range tc.content {
+				opener.Buffer[path] = bytes.NewBufferString(content)
+			}
+			got := readCoverageFile(context.Background(), logrus.NewEntry(logrus.StandardLogger()), opener, "gs://bucket/logs/job/1")
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("coverage differs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	slackclient "sigs.k8s.io/prow/pkg/slack"
+)
+
+// DefaultChannelCacheTTL is how long the channel IDs of a host are reused
+// before they are looked up again.
+const DefaultChannelCacheTTL = time.Hour
+
+// channelCache resolves channel names to IDs, which some Slack APIs like
+// conversations.setTopic require. The channels of a host are listed with one
+// conversations.list call and reused until the TTL passed, a channel isn't
+// found among them or Slack doesn't find a channel by its cached ID.
+type channelCache struct {
+	lock  sync.Mutex
+	ttl   time.Duration
+	now   func() time.Time
+	hosts map[string]*hostChannels
+}
+
+type hostChannels struct {
+	// ids maps the names and IDs of channels to their IDs.
+	ids     map[string]string
+	fetched time.Time
+}
+
+func newChannelCache(ttl time.Duration) *channelCache {
+	return &channelCache{
+		ttl:   ttl,
+		now:   time.Now,
+		hosts: map[string]*hostChannels{},
+	}
+}
+
+// resolve returns the ID of the channel, which may be given by its name, with
+// or without the leading `#`, or its ID. It returns an APIError with the
+// `channel_not_found` code if the host has no such channel.
+func (c *channelCache) resolve(ctx context.Context, host string, client slackClient, channel string) (string, error) {
+	name := strings.TrimPrefix(channel, "#")
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	cached, ok := c.hosts[host]
+	if ok && c.now().Sub(cached.fetched) < c.ttl {
+		if id, ok := cached.ids[name]; ok {
+			return id, nil
+		}
+	}
+
+	ids, err := client.Channels(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up the ID of channel %s: %w", channel, err)
+	}
+	c.hosts[host] = &hostChannels{ids: ids, fetched: c.now()}
+	if id, ok := ids[name]; ok {
+		return id, nil
+	}
+	return "", &slackclient.APIError{StatusCode: http.StatusOK, Code: "channel_not_found"}
+}
+
+// invalidateOnNotFound drops the channels of the host if Slack didn't find a
+// channel, e.g. because it was recreated and has a new ID, so that they are
+// looked up again.
+func (c *channelCache) invalidateOnNotFound(host string, err error) {
+	var apiErr *slackclient.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "channel_not_found" {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.hosts, host)
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slack
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	slackclient "sigs.k8s.io/prow/pkg/slack"
+)
+
+func TestChannelCache(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := newChannelCache(time.Hour)
+	cache.now = func() time.Time { return now }
+	fsc := &fakeSlackClient{channels: map[string]string{"status": "C1", "C1": "C1"}}
+
+	resolve := func(channel, expectedID string, expectedLookups int) {
+		t.Helper()
+		id, err := cache.resolve(context.Background(), DefaultHostName, fsc, channel)
+		if err != nil {
+			t.Fatalf("resolve %s: %v", channel, err)
+		}
+		if id != expectedID {
+			t.Errorf("expected %s to resolve to %s, got %s", channel, expectedID, id)
+		}
+		if fsc.channelLookups != expectedLookups {
+			t.Errorf("expected %d lookups after resolving %s, got %d", expectedLookups, channel, fsc.channelLookups)
+		}
+	}
+
+	resolve("#status", "C1", 1)
+	resolve("status", "C1", 1)
+	resolve("C1", "C1", 1)
+
+	// A channel that was created since the lookup is looked up again.
+	fsc.channels = map[string]string{"status": "C1", "C1": "C1", "release": "C2", "C2": "C2"}
+	resolve("release", "C2", 2)
+
+	// The channels are looked up again once the TTL passed.
+	now = now.Add(time.Hour)
+	fsc.channels = map[string]string{"status": "C3", "C3": "C3"}
+	resolve("status", "C3", 3)
+
+	// A channel that doesn't exist isn't cached.
+	if _, err := cache.resolve(context.Background(), DefaultHostName, fsc, "missing"); !isPermanent(err) {
+		t.Errorf("expected a permanent error for a missing channel, got %v", err)
+	}
+	if fsc.channelLookups != 4 {
+		t.Errorf("expected the missing channel to be looked up, got %d lookups", fsc.channelLookups)
+	}
+
+	// Other errors don't invalidate the channels, channel_not_found does.
+	cache.invalidateOnNotFound(DefaultHostName, errors.New("connection reset"))
+	resolve("status", "C3", 4)
+	cache.invalidateOnNotFound(DefaultHostName, &slackclient.APIError{StatusCode: 200, Code: "channel_not_found"})
+	resolve("status", "C3", 5)
+}
@@ -0,0 +1,254 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slack
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+)
+
+// reportedTTL is how long jobs are remembered after their batch was posted.
+// All jobs of a batch are requeued when its window ends and may be handed
+// to the reporter again before crier's cache reflects that the batch already
+// reported them. It also bounds how long batches of jobs that never came
+// back are kept.
+const reportedTTL = 10 * time.Minute
+
+// batch collects the reports of the jobs of a pull request until its
+// deadline.
+type batch struct {
+	deadline time.Time
+	// jobs holds the latest version of each ProwJob by name, so that only
+	// the last state of a job is reported.
+	jobs     map[string]*prowapi.ProwJob
+	messages map[string]*message
+	// delivered are the channels the batch was already posted to by an
+	// attempt that failed for other channels.
+	delivered sets.Set[string]
+}
+
+type reportedJob struct {
+	state prowapi.ProwJobState
+	at    time.Time
+}
+
+// coalescer keeps track of the batches that are not posted yet. Jobs are not
+// marked as reported until their batch is posted, so nothing is lost if
+// crier restarts in the meantime: the jobs are simply reported again.
+type coalescer struct {
+	now func() time.Time
+
+	lock     sync.Mutex
+	batches  map[string]*batch
+	reported map[string]reportedJob
+}
+
+func newCoalescer() *coalescer {
+	return &coalescer{
+		now:      time.Now,
+		batches:  map[string]*batch{},
+		reported: map[string]reportedJob{},
+	}
+}
+
+// pullRequestKey identifies the pull request the job runs against. It is
+// empty for jobs that don't run against a pull request.
+func pullRequestKey(pj *prowapi.ProwJob) string {
+	refs := pj.Spec.Refs
+	if refs == nil || len(refs.Pulls) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s#%d", refs.Org, refs.Repo, refs.Pulls[0].Number)
+}
+
+// prune must be called with the lock held.
+func (c *coalescer) prune(now time.Time) {
+	for name, job := range c.reported {
+		if now.Sub(job.at) > reportedTTL {
+			delete(c.reported, name)
+		}
+	}
+	for key, b := range c.batches {
+		if now.Sub(b.deadline) > reportedTTL {
+			delete(c.batches, key)
+		}
+	}
+}
+
+// restore puts the jobs of a batch that failed to be posted back, so that
+// they are posted with the next attempt. Jobs that were added to a new batch
+// in the meantime are newer and take precedence. The merged batch differs
+// from the failed one, so it is posted to all channels again. Must be
+// called with the lock held.
+func (c *coalescer) restore(key string, b *batch) {
+	current, ok := c.batches[key]
+	if !ok {
+		c.batches[key] = b
+		return
+	}
+	for name, job := range b.jobs {
+		if _, ok := current.jobs[name]; !ok {
+			current.jobs[name] = job
+			current.messages[name] = b.messages[name]
+		}
+	}
+	current.deadline = b.deadline
+}
+
+// coalesce adds the job to the batch of its pull request. The batch is
+// posted by the first job that is reported after the batch's deadline,
+// which also returns all jobs of the batch so that crier marks them as
+// reported. Until then, crier is asked to requeue the job.
+func (sr *slackReporter) coalesce(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob, window time.Duration) ([]*prowapi.ProwJob, *reconcile.Result, error) {
+	msg, err := sr.render(log, pj)
+	if err != nil {
+		return nil, nil, err
+	}
+	prKey := pullRequestKey(pj)
+	key := strings.Join([]string{prKey, msg.host, strings.Join(msg.channels, ",")}, "|")
+
+	c := sr.coalescer
+	c.lock.Lock()
+	now := c.now()
+	c.prune(now)
+	if reported, ok := c.reported[pj.Name]; ok && reported.state == pj.Status.State {
+		c.lock.Unlock()
+		log.Debug("Job was already reported as part of a batch")
+		return []*prowapi.ProwJob{pj}, nil, nil
+	}
+	b, ok := c.batches[key]
+	if !ok {
+		b = &batch{
+			deadline:  now.Add(window),
+			jobs:      map[string]*prowapi.ProwJob{},
+			messages:  map[string]*message{},
+			delivered: sets.New[string](),
+		}
+		c.batches[key] = b
+	}
+	if prev, ok := b.jobs[pj.Name]; !ok || prev.Status.State != pj.Status.State {
+		// The batch changed, so it is posted to all channels again.
+		b.delivered = sets.New[string]()
+	}
+	b.jobs[pj.Name] = pj.DeepCopy()
+	b.messages[pj.Name] = msg
+	if now.Before(b.deadline) {
+		c.lock.Unlock()
+		log.WithField("batch", prKey).Debug("Coalescing report")
+		return nil, &reconcile.Result{RequeueAfter: b.deadline.Sub(now)}, nil
+	}
+	delete(c.batches, key)
+	c.lock.Unlock()
+
+	err = sr.postBatch(ctx, log, pj, msg, b)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if err != nil {
+		c.restore(key, b)
+		return nil, nil, err
+	}
+	var names []string
+	for name := range b.jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var pjs []*prowapi.ProwJob
+	for _, name := range names {
+		job := b.jobs[name]
+		c.reported[name] = reportedJob{state: job.Status.State, at: now}
+		pjs = append(pjs, job)
+	}
+	return pjs, nil, nil
+}
+
+// postBatch posts a summary of all jobs of the batch with the individual
+// reports in its thread. A batch of a single job is posted like a regular
+// report.
+func (sr *slackReporter) postBatch(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob, msg *message, b *batch) error {
+	var names []string
+	for name := range b.jobs {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if b.jobs[names[i]].Spec.Job != b.jobs[names[j]].Spec.Job {
+			return b.jobs[names[i]].Spec.Job < b.jobs[names[j]].Spec.Job
+		}
+		return names[i] < names[j]
+	})
+
+	client := sr.clients[msg.host]
+	if len(names) == 1 {
+		text := b.messages[names[0]].text
+		if sr.dryRun {
+			log.WithField("messagetext", text).WithField("channels", msg.channels).Debug("Skipping reporting because dry-run is enabled")
+			return nil
+		}
+		return b.deliver(log, msg.channels, func(channel string) error {
+			return client.WriteMessage(ctx, text, channel)
+		})
+	}
+
+	summary := []string{fmt.Sprintf("%d jobs finished for %s:", len(names), pullRequestLink(pj))}
+	var replies []string
+	for _, name := range names {
+		job := b.jobs[name]
+		summary = append(summary, fmt.Sprintf("• %s: %s", job.Spec.Job, job.Status.State))
+		replies = append(replies, b.messages[name].text)
+	}
+	text := strings.Join(summary, "\n")
+	if sr.dryRun {
+		log.WithField("messagetext", text).WithField("replies", replies).WithField("channels", msg.channels).Debug("Skipping reporting because dry-run is enabled")
+		return nil
+	}
+	return b.deliver(log, msg.channels, func(channel string) error {
+		return client.WriteThreadedMessage(ctx, text, replies, channel)
+	})
+}
+
+// deliver writes the batch to the channels it wasn't posted to yet and
+// remembers the channels that were written to.
+func (b *batch) deliver(log *logrus.Entry, channels []string, write func(channel string) error) error {
+	var pending []string
+	for _, channel := range channels {
+		if !b.delivered.Has(channel) {
+			pending = append(pending, channel)
+		}
+	}
+	written, err := writeToChannels(log, pending, write)
+	b.delivered.Insert(written...)
+	return err
+}
+
+func pullRequestLink(pj *prowapi.ProwJob) string {
+	pull := pj.Spec.Refs.Pulls[0]
+	name := fmt.Sprintf("%s/%s#%d", pj.Spec.Refs.Org, pj.Spec.Refs.Repo, pull.Number)
+	if pull.Link == "" {
+		return name
+	}
+	return fmt.Sprintf("<%s|%s>", pull.Link, name)
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slack
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+)
+
+func TestCoalesce(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	fsc := &fakeSlackClient{}
+	c := newCoalescer()
+	c.now = func() time.Time { return now }
+	sr := &slackReporter{
+		config: func(*v1.Refs) config.SlackReporter {
+			return config.SlackReporter{
+				CoalesceWindow: &metav1.Duration{Duration: time.Minute},
+				SlackReporterConfig: v1.SlackReporterConfig{
+					Channel:        "team",
+					ReportTemplate: "{{.Spec.Job}} ended with {{.Status.State}}",
+				},
+			}
+		},
+		clients:   map[string]slackClient{DefaultHostName: fsc},
+		coalescer: c,
+	}
+	log := logrus.NewEntry(logrus.StandardLogger())
+	job := func(name string, state v1.ProwJobState) *v1.ProwJob {
+		pj := &v1.ProwJob{
+			Spec: v1.ProwJobSpec{
+				Job:  name,
+				Type: v1.PresubmitJob,
+				Refs: &v1.Refs{Org: "org", Repo: "repo", Pulls: []v1.Pull{{Number: 1, Link: "https://github.com/org/repo/pull/1"}}},
+			},
+			Status: v1.ProwJobStatus{State: state},
+		}
+		pj.Name = name + "-id"
+		return pj
+	}
+	assertReport := func(pj *v1.ProwJob, expectedJobs []string, expectedRequeue *reconcile.Result) {
+		t.Helper()
+		pjs, requeue, err := sr.Report(context.Background(), log, pj)
+		if err != nil {
+			t.Fatalf("reporting failed: %v", err)
+		}
+		var jobs []string
+		for _, pj := range pjs {
+			jobs = append(jobs, pj.Name+":"+string(pj.Status.State))
+		}
+		if diff := cmp.Diff(expectedJobs, jobs); diff != "" {
+			t.Errorf("reported jobs differ from expected: %s", diff)
+		}
+		if diff := cmp.Diff(expectedRequeue, requeue); diff != "" {
+			t.Errorf("requeue differs from expected: %s", diff)
+		}
+	}
+
+	assertReport(job("unit", v1.SuccessState), nil, &reconcile.Result{RequeueAfter: time.Minute})
+	now = now.Add(20 * time.Second)
+	assertReport(job("e2e", v1.PendingState), nil, &reconcile.Result{RequeueAfter: 40 * time.Second})
+	// The job finishing within the window replaces its earlier state.
+	now = now.Add(20 * time.Second)
+	assertReport(job("e2e", v1.FailureState), nil, &reconcile.Result{RequeueAfter: 20 * time.Second})
+	if len(fsc.messages) != 0 {
+		t.Fatalf("expected no messages within the window, got %v", fsc.messages)
+	}
+
+	// The first job coming back after the window posts the batch.
+	now = now.Add(20 * time.Second)
+	assertReport(job("unit", v1.SuccessState), []string{"e2e-id:failure", "unit-id:success"}, nil)
+	expectedSummary := "2 jobs finished for <https://github.com/org/repo/pull/1|org/repo#1>:\n• e2e: failure\n• unit: success"
+	if fsc.messages["team"] != expectedSummary {
+		t.Errorf("expected summary %q, got %q", expectedSummary, fsc.messages["team"])
+	}
+	if diff := cmp.Diff([]string{"e2e ended with failure", "unit ended with success"}, fsc.threads["team"]); diff != "" {
+		t.Errorf("replies differ from expected: %s", diff)
+	}
+
+	// The other job of the batch isn't posted again.
+	fsc.messages = nil
+	assertReport(job("e2e", v1.FailureState), []string{"e2e-id:failure"}, nil)
+	if len(fsc.messages) != 0 {
+		t.Errorf("expected batch not to be posted again, got %v", fsc.messages)
+	}
+
+	// A batch of a single job is posted as a regular message.
+	assertReport(job("lint", v1.FailureState), nil, &reconcile.Result{RequeueAfter: time.Minute})
+	now = now.Add(time.Minute)
+	assertReport(job("lint", v1.FailureState), []string{"lint-id:failure"}, nil)
+	if fsc.messages["team"] != "lint ended with failure" {
+		t.Errorf("expected regular message, got %q", fsc.messages["team"])
+	}
+}
+
+func TestCoalesceRetriesFailedBatch(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	fsc := &fakeSlackClient{errors: map[string]error{"team": errors.New("slack is down")}}
+	c := newCoalescer()
+	c.now = func() time.Time { return now }
+	sr := &slackReporter{
+		config: func(*v1.Refs) config.SlackReporter {
+			return config.SlackReporter{
+				CoalesceWindow:      &metav1.Duration{Duration: time.Minute},
+				SlackReporterConfig: v1.SlackReporterConfig{Channel: "team", ReportTemplate: "{{.Spec.Job}}"},
+			}
+		},
+		clients:   map[string]slackClient{DefaultHostName: fsc},
+		coalescer: c,
+	}
+	log := logrus.NewEntry(logrus.StandardLogger())
+	refs := &v1.Refs{Org: "org", Repo: "repo", Pulls: []v1.Pull{{Number: 1}}}
+	unit := &v1.ProwJob{Spec: v1.ProwJobSpec{Job: "unit", Refs: refs}, Status: v1.ProwJobStatus{State: v1.SuccessState}}
+	unit.Name = "unit-id"
+	e2e := &v1.ProwJob{Spec: v1.ProwJobSpec{Job: "e2e", Refs: refs}, Status: v1.ProwJobStatus{State: v1.FailureState}}
+	e2e.Name = "e2e-id"
+
+	sr.Report(context.Background(), log, unit)
+	sr.Report(context.Background(), log, e2e)
+	now = now.Add(time.Minute)
+	if _, _, err := sr.Report(context.Background(), log, unit); err == nil {
+		t.Fatal("expected posting the batch to fail")
+	}
+
+	// The retry of any job of the batch posts the whole batch.
+	fsc.errors = nil
+	pjs, _, err := sr.Report(context.Background(), log, e2e)
+	if err != nil {
+		t.Fatalf("reporting failed: %v", err)
+	}
+	if len(pjs) != 2 {
+		t.Errorf("expected both jobs to be reported, got %d", len(pjs))
+	}
+	if expected := "2 jobs finished for org/repo#1:\n• e2e: failure\n• unit: success"; fsc.messages["team"] != expected {
+		t.Errorf("expected summary %q, got %q", expected, fsc.messages["team"])
+	}
+}
+
+func TestCoalesceRetryOnlyWritesToFailedChannels(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	fsc := &fakeSlackClient{errors: map[string]error{"team": errors.New("slack is down")}}
+	c := newCoalescer()
+	c.now = func() time.Time { return now }
+	sr := &slackReporter{
+		config: func(*v1.Refs) config.SlackReporter {
+			return config.SlackReporter{
+				CoalesceWindow:      &metav1.Duration{Duration: time.Minute},
+				Channels:            []string{"dashboard", "team"},
+				SlackReporterConfig: v1.SlackReporterConfig{ReportTemplate: "{{.Spec.Job}}"},
+			}
+		},
+		clients:   map[string]slackClient{DefaultHostName: fsc},
+		coalescer: c,
+	}
+	log := logrus.NewEntry(logrus.StandardLogger())
+	refs := &v1.Refs{Org: "org", Repo: "repo", Pulls: []v1.Pull{{Number: 1}}}
+	unit := &v1.ProwJob{Spec: v1.ProwJobSpec{Job: "unit", Refs: refs}, Status: v1.ProwJobStatus{State: v1.SuccessState}}
+	unit.Name = "unit-id"
+
+	sr.Report(context.Background(), log, unit)
+	now = now.Add(time.Minute)
+	if _, _, err := sr.Report(context.Background(), log, unit); err == nil {
+		t.Fatal("expected posting the batch to fail")
+	}
+	fsc.errors = nil
+	if _, _, err := sr.Report(context.Background(), log, unit); err != nil {
+		t.Fatalf("reporting failed: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"dashboard", "team"}, fsc.writes); diff != "" {
+		t.Errorf("writes differ from expected: %s", diff)
+	}
+}
+
+func TestCoalesceSkipsJobsWithoutPullRequest(t *testing.T) {
+	fsc := &fakeSlackClient{}
+	sr := &slackReporter{
+		config: func(*v1.Refs) config.SlackReporter {
+			return config.SlackReporter{
+				CoalesceWindow:      &metav1.Duration{Duration: time.Minute},
+				SlackReporterConfig: v1.SlackReporterConfig{Channel: "team", ReportTemplate: "{{.Spec.Job}}"},
+			}
+		},
+		clients:   map[string]slackClient{DefaultHostName: fsc},
+		coalescer: newCoalescer(),
+	}
+	pj := &v1.ProwJob{
+		Spec:   v1.ProwJobSpec{Job: "periodic", Type: v1.PeriodicJob},
+		Status: v1.ProwJobStatus{State: v1.FailureState},
+	}
+
+	pjs, requeue, err := sr.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj)
+	if err != nil {
+		t.Fatalf("reporting failed: %v", err)
+	}
+	if len(pjs) != 1 || requeue != nil {
+		t.Errorf("expected job to be reported right away, got %d jobs and requeue %v", len(pjs), requeue)
+	}
+	if fsc.messages["team"] != "periodic" {
+		t.Errorf("expected message to be posted, got %v", fsc.messages)
+	}
+}
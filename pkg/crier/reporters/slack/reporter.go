@@ -19,31 +19,63 @@ package slack
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"strings"
 	"text/template"
+	"time"
 
 	"github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
 	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/crier/reporters/criercommonlib"
 	slackclient "sigs.k8s.io/prow/pkg/slack"
 )
 
 const (
 	reporterName    = "slackreporter"
 	DefaultHostName = "*"
+
+	// ThreadsAnnotation stores the timestamps of the first message posted
+	// for a job by channel, as JSON. Reports of later states of the job are
+	// posted in the threads of these messages if reply_in_thread is set.
+	ThreadsAnnotation = "prow.k8s.io/slack-threads"
+	// DeliveredAnnotation stores the state of the job and the channels its
+	// report was posted to, as JSON, if posting to some other channels
+	// failed. Retries of the report only post to the channels that failed.
+	DeliveredAnnotation = "prow.k8s.io/slack-delivered"
 )
 
 type slackClient interface {
-	WriteMessage(text, channel string) error
+	WriteMessage(ctx context.Context, text, channel string) error
+	WriteThreadedMessage(ctx context.Context, text string, replies []string, channel string) error
+	PostMessage(ctx context.Context, text, channel, threadTS string) (string, error)
+	PostBlocks(ctx context.Context, text string, blocks []slackclient.Block, channel, threadTS string) (string, error)
+	SetTopic(ctx context.Context, channel, topic string) error
+	AuthTest(ctx context.Context) error
+	Channels(ctx context.Context) (map[string]string, error)
 }
 
 type slackReporter struct {
 	clients map[string]slackClient
 	config  func(*prowapi.Refs) config.SlackReporter
-	dryRun  bool
+	// configKeys returns the `org/repo`, `org` and `*` keys of the config,
+	// so that all of it can be validated.
+	configKeys func() []string
+	dryRun     bool
+	coalescer  *coalescer
+	// pjclient persists the threads of jobs.
+	pjclient ctrlruntimeclient.Client
+	topics   *topicThrottle
+	channels *channelCache
+	now      func() time.Time
 }
 
 func hostAndChannel(cfg *prowapi.SlackReporterConfig) (string, string) {
@@ -54,6 +86,26 @@ func hostAndChannel(cfg *prowapi.SlackReporterConfig) (string, string) {
 	return host, channel
 }
 
+// reportChannels returns the channels to report to. A channel set on the job
+// overrides all channels of the global config.
+func reportChannels(globalConfig *config.SlackReporter, jobConfig *prowapi.SlackReporterConfig, channel string) []string {
+	var result []string
+	if channel != "" {
+		result = append(result, channel)
+	}
+	if jobConfig != nil && jobConfig.Channel != "" {
+		return result
+	}
+	if globalConfig != nil {
+		for _, c := range globalConfig.Channels {
+			if c != channel {
+				result = append(result, c)
+			}
+		}
+	}
+	return result
+}
+
 func (sr *slackReporter) getConfig(pj *prowapi.ProwJob) (*config.SlackReporter, *prowapi.SlackReporterConfig) {
 	refs := pj.Spec.Refs
 	if refs == nil && len(pj.Spec.ExtraRefs) > 0 {
@@ -67,50 +119,421 @@ func (sr *slackReporter) getConfig(pj *prowapi.ProwJob) (*config.SlackReporter,
 	return &globalConfig, jobSlackConfig
 }
 
-func (sr *slackReporter) Report(_ context.Context, log *logrus.Entry, pj *prowapi.ProwJob) ([]*prowapi.ProwJob, *reconcile.Result, error) {
-	return []*prowapi.ProwJob{pj}, nil, sr.report(log, pj)
+func (sr *slackReporter) Report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) ([]*prowapi.ProwJob, *reconcile.Result, error) {
+	if err := sr.updateTopics(ctx, log, pj); err != nil {
+		return []*prowapi.ProwJob{pj}, nil, fmt.Errorf("failed to set channel topics: %w", err)
+	}
+	globalSlackConfig, _ := sr.getConfig(pj)
+	// Jobs whose result is shown in a channel topic are reported even if
+	// no message should be posted for them.
+	if tracksTopic(globalSlackConfig, pj) && !sr.shouldReportMessage(log, pj) {
+		return []*prowapi.ProwJob{pj}, nil, nil
+	}
+	if globalSlackConfig.CoalesceWindow != nil && globalSlackConfig.CoalesceWindow.Duration > 0 && pullRequestKey(pj) != "" {
+		return sr.coalesce(ctx, log, pj, globalSlackConfig.CoalesceWindow.Duration)
+	}
+	if globalSlackConfig.ReplyInThread {
+		return []*prowapi.ProwJob{pj}, nil, sr.reportInThread(ctx, log, pj)
+	}
+	return []*prowapi.ProwJob{pj}, nil, sr.report(ctx, log, pj)
 }
 
-func (sr *slackReporter) report(log *logrus.Entry, pj *prowapi.ProwJob) error {
+// ReportTarget returns the Slack host and channels the job is reported to.
+func (sr *slackReporter) ReportTarget(pj *prowapi.ProwJob) string {
 	globalSlackConfig, jobSlackConfig := sr.getConfig(pj)
+	mergedSlackConfig := jobSlackConfig
 	if globalSlackConfig != nil {
-		jobSlackConfig = jobSlackConfig.ApplyDefault(&globalSlackConfig.SlackReporterConfig)
+		mergedSlackConfig = jobSlackConfig.ApplyDefault(&globalSlackConfig.SlackReporterConfig)
 	}
-	if jobSlackConfig == nil {
-		return errors.New("resolved slack config is empty") // Shouldn't happen at all, just in case
+	if mergedSlackConfig == nil {
+		return ""
 	}
-	host, channel := hostAndChannel(jobSlackConfig)
+	host, channel := hostAndChannel(mergedSlackConfig)
+	return host + "/" + strings.Join(reportChannels(globalSlackConfig, jobSlackConfig, channel), ",")
+}
 
-	client, ok := sr.clients[host]
-	if !ok {
-		return fmt.Errorf("host '%s' not supported", host)
+// message is a rendered report and where it is sent to.
+type message struct {
+	host     string
+	channels []string
+	text     string
+	// blocks are set if the report is sent as a Block Kit message, in which
+	// case text is its fallback.
+	blocks []slackclient.Block
+}
+
+// post posts the message to channel, in the thread of threadTS if set, and
+// returns the timestamp of the new message.
+func (m *message) post(ctx context.Context, client slackClient, channel, threadTS string) (string, error) {
+	if m.blocks != nil {
+		return client.PostBlocks(ctx, m.text, m.blocks, channel, threadTS)
+	}
+	return client.PostMessage(ctx, m.text, channel, threadTS)
+}
+
+func (sr *slackReporter) render(log *logrus.Entry, pj *prowapi.ProwJob) (*message, error) {
+	globalSlackConfig, jobSlackConfig := sr.getConfig(pj)
+	mergedSlackConfig := jobSlackConfig
+	if globalSlackConfig != nil {
+		mergedSlackConfig = jobSlackConfig.ApplyDefault(&globalSlackConfig.SlackReporterConfig)
+	}
+	if mergedSlackConfig == nil {
+		return nil, errors.New("resolved slack config is empty") // Shouldn't happen at all, just in case
+	}
+	host, channel := hostAndChannel(mergedSlackConfig)
+	channels := reportChannels(globalSlackConfig, jobSlackConfig, channel)
+
+	if _, ok := sr.clients[host]; !ok {
+		return nil, fmt.Errorf("host '%s' not supported", host)
 	}
 	b := &bytes.Buffer{}
-	tmpl, err := template.New("").Parse(jobSlackConfig.ReportTemplate)
+	if emoji := globalSlackConfig.EmojiFor(pj.Status.State); emoji != "" {
+		b.WriteString(emoji + " ")
+	}
+	tmpl, err := template.New("").Parse(globalSlackConfig.ReportTemplateFor(pj.Status.State, jobSlackConfig))
 	if err != nil {
 		log.WithError(err).Error("failed to parse template")
-		return fmt.Errorf("failed to parse template: %w", err)
+		return nil, fmt.Errorf("failed to parse template: %w", err)
 	}
 	if err := tmpl.Execute(b, pj); err != nil {
 		log.WithError(err).Error("failed to execute report template")
-		return fmt.Errorf("failed to execute report template: %w", err)
+		return nil, fmt.Errorf("failed to execute report template: %w", err)
+	}
+	if mentions := mentionsFor(globalSlackConfig, pj.Status.State); mentions != "" {
+		b.WriteString(" " + mentions)
+	}
+	group := groupOf(globalSlackConfig, pj)
+	if group != "" && !globalSlackConfig.UseBlockKit {
+		b.WriteString("\n" + group)
+	}
+	msg := &message{host: host, channels: channels, text: b.String()}
+	if globalSlackConfig.UseBlockKit {
+		msg.blocks = blocksFor(pj, msg.text, group)
+	}
+	return msg, nil
+}
+
+// groupOf returns the text showing the group of related jobs the job is part
+// of, taken from its GroupingAnnotation, or an empty string.
+func groupOf(cfg *config.SlackReporter, pj *prowapi.ProwJob) string {
+	if cfg.GroupingAnnotation == "" || pj.Annotations[cfg.GroupingAnnotation] == "" {
+		return ""
+	}
+	return "Group: " + pj.Annotations[cfg.GroupingAnnotation]
+}
+
+// maxHeaderLength is the maximum length of the text of a header block.
+const maxHeaderLength = 150
+
+func truncate(s string, length int) string {
+	if r := []rune(s); len(r) > length {
+		return string(r[:length-1]) + "…"
+	}
+	return s
+}
+
+// blocksFor returns the Block Kit blocks reporting the job: a header with its
+// name, the rendered report with its state and duration, the group of related
+// jobs it's part of if it has one, and a button linking to its logs.
+func blocksFor(pj *prowapi.ProwJob, text, group string) []slackclient.Block {
+	fields := []*slackclient.TextObject{
+		slackclient.Markdown("*State*\n" + string(pj.Status.State)),
+	}
+	if pj.Status.CompletionTime != nil {
+		duration := pj.Status.CompletionTime.Sub(pj.Status.StartTime.Time).Round(time.Second)
+		fields = append(fields, slackclient.Markdown("*Duration*\n"+duration.String()))
+	}
+	blocks := []slackclient.Block{
+		{Type: "header", Text: slackclient.PlainText(truncate(pj.Spec.Job, maxHeaderLength))},
+		{Type: "section", Text: slackclient.Markdown(text), Fields: fields},
+	}
+	if group != "" {
+		blocks = append(blocks, slackclient.Block{
+			Type:     "context",
+			Elements: []slackclient.BlockElement{slackclient.Markdown(group)},
+		})
+	}
+	if pj.Status.URL != "" {
+		blocks = append(blocks, slackclient.Block{
+			Type: "actions",
+			Elements: []slackclient.BlockElement{&slackclient.Element{
+				Type:     "button",
+				Text:     slackclient.PlainText("View logs"),
+				URL:      pj.Status.URL,
+				ActionID: "view_logs",
+			}},
+		})
+	}
+	return blocks
+}
+
+// mentionsFor returns the Slack mentions of MentionsOnFailure if the job
+// failed. They are omitted for other states to avoid alert fatigue.
+func mentionsFor(cfg *config.SlackReporter, state prowapi.ProwJobState) string {
+	if state != prowapi.FailureState && state != prowapi.ErrorState {
+		return ""
+	}
+	var mentions []string
+	for _, id := range cfg.MentionsOnFailure {
+		if strings.HasPrefix(id, "S") {
+			mentions = append(mentions, "<!subteam^"+id+">")
+		} else {
+			mentions = append(mentions, "<@"+id+">")
+		}
+	}
+	return strings.Join(mentions, " ")
+}
+
+func (sr *slackReporter) report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) error {
+	msg, err := sr.render(log, pj)
+	if err != nil {
+		return err
+	}
+	if sr.dryRun {
+		log.WithField("messagetext", msg.text).WithField("channels", msg.channels).Debug("Skipping reporting because dry-run is enabled")
+		return nil
+	}
+	return sr.deliver(ctx, log, pj, msg.channels, func(channel string) error {
+		if msg.blocks != nil {
+			_, err := msg.post(ctx, sr.clients[msg.host], channel, "")
+			return err
+		}
+		return sr.clients[msg.host].WriteMessage(ctx, msg.text, channel)
+	})
+}
+
+// reportInThread posts the report as a reply to the first message posted for
+// the job in each channel, and stores the timestamps of new first messages
+// on the job.
+func (sr *slackReporter) reportInThread(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) error {
+	msg, err := sr.render(log, pj)
+	if err != nil {
+		return err
+	}
+	threads := map[string]string{}
+	if raw, ok := pj.Annotations[ThreadsAnnotation]; ok {
+		if err := json.Unmarshal([]byte(raw), &threads); err != nil {
+			log.WithError(err).Warn("Ignoring invalid Slack threads annotation")
+			threads = map[string]string{}
+		}
 	}
 	if sr.dryRun {
-		log.WithField("messagetext", b.String()).Debug("Skipping reporting because dry-run is enabled")
+		log.WithField("messagetext", msg.text).WithField("channels", msg.channels).WithField("threads", threads).Debug("Skipping reporting because dry-run is enabled")
+		return nil
+	}
+
+	client := sr.clients[msg.host]
+	var newThreads bool
+	err = sr.deliver(ctx, log, pj, msg.channels, func(channel string) error {
+		threadTS := threads[channel]
+		ts, err := msg.post(ctx, client, channel, threadTS)
+		if threadTS != "" && errors.Is(err, slackclient.ErrThreadNotFound) {
+			log.WithField("channel", channel).Info("First message of the job was deleted, posting a new one")
+			threadTS = ""
+			ts, err = msg.post(ctx, client, channel, "")
+		}
+		if err != nil {
+			return err
+		}
+		if threadTS == "" && ts != "" {
+			threads[channel] = ts
+			newThreads = true
+		}
 		return nil
+	})
+	if newThreads {
+		// The messages are already posted, so failing to store the threads
+		// only means that later reports start new threads.
+		if err := sr.storeAnnotation(ctx, pj, ThreadsAnnotation, threads); err != nil {
+			log.WithError(err).Warn("Failed to store Slack threads on the job")
+		}
+	}
+	return err
+}
+
+// deliveredReport is the value of the DeliveredAnnotation.
+type deliveredReport struct {
+	State    prowapi.ProwJobState `json:"state"`
+	Channels []string             `json:"channels"`
+}
+
+// deliveredChannels returns the channels the current state of the job was
+// already posted to.
+func deliveredChannels(log *logrus.Entry, pj *prowapi.ProwJob) sets.Set[string] {
+	raw, ok := pj.Annotations[DeliveredAnnotation]
+	if !ok {
+		return sets.New[string]()
+	}
+	var delivered deliveredReport
+	if err := json.Unmarshal([]byte(raw), &delivered); err != nil {
+		log.WithError(err).Warn("Ignoring invalid Slack delivered annotation")
+		return sets.New[string]()
 	}
-	if err := client.WriteMessage(b.String(), channel); err != nil {
-		log.WithError(err).Error("failed to write Slack message")
-		return fmt.Errorf("failed to write Slack message: %w", err)
+	if delivered.State != pj.Status.State {
+		return sets.New[string]()
+	}
+	return sets.New(delivered.Channels...)
+}
+
+// deliver writes the report of the job to the channels the current state of
+// the job wasn't posted to yet. If some channels fail, the channels that were
+// written to are stored on the job, so that a retry doesn't post to them
+// again.
+func (sr *slackReporter) deliver(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob, channels []string, write func(channel string) error) error {
+	delivered := deliveredChannels(log, pj)
+	var pending []string
+	for _, channel := range channels {
+		if delivered.Has(channel) {
+			log.WithField("channel", channel).Debug("Already posted to channel")
+			continue
+		}
+		pending = append(pending, channel)
+	}
+	written, err := writeToChannels(log, pending, write)
+	if err != nil && len(written) > 0 {
+		delivered.Insert(written...)
+		if err := sr.storeAnnotation(ctx, pj, DeliveredAnnotation, deliveredReport{State: pj.Status.State, Channels: sets.List(delivered)}); err != nil {
+			log.WithError(err).Warn("Failed to store the Slack channels the job was posted to, retries post to them again")
+		}
+	}
+	return err
+}
+
+// storeAnnotation stores the value as JSON in the annotation of the job.
+func (sr *slackReporter) storeAnnotation(ctx context.Context, pj *prowapi.ProwJob, annotation string, value interface{}) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	newpj := pj.DeepCopy()
+	if newpj.Annotations == nil {
+		newpj.Annotations = map[string]string{}
+	}
+	newpj.Annotations[annotation] = string(raw)
+	if err := sr.pjclient.Patch(ctx, newpj, ctrlruntimeclient.MergeFrom(pj)); err != nil {
+		return fmt.Errorf("failed to patch prowjob: %w", err)
 	}
 	return nil
 }
 
+// writeToChannels writes to all channels even if some fail, so that one
+// broken channel doesn't keep the others from being notified. It returns the
+// channels that were written to.
+func writeToChannels(log *logrus.Entry, channels []string, write func(channel string) error) ([]string, error) {
+	var written, failedChannels []string
+	var errs []error
+	for _, channel := range channels {
+		if err := write(channel); err != nil {
+			log.WithError(err).WithField("channel", channel).Error("failed to write Slack message")
+			failedChannels = append(failedChannels, channel)
+			errs = append(errs, err)
+			continue
+		}
+		written = append(written, channel)
+	}
+	if len(errs) > 0 {
+		err := fmt.Errorf("failed to write Slack message to channel(s) %s: %w", strings.Join(failedChannels, ", "), utilerrors.NewAggregate(errs))
+		// A retry only writes to the failed channels, so it is only
+		// pointless if none of them can succeed.
+		for _, channelErr := range errs {
+			if !isPermanent(channelErr) {
+				return written, criercommonlib.TransientError(err)
+			}
+		}
+		return written, criercommonlib.PermanentError(err)
+	}
+	return written, nil
+}
+
+// isPermanent tells whether the error was returned by Slack for a request
+// that fails until the config is fixed, e.g. because the channel doesn't
+// exist.
+func isPermanent(err error) bool {
+	var apiErr *slackclient.APIError
+	return errors.As(err, &apiErr) && !apiErr.Retryable()
+}
+
+// CheckConnectivity checks that every configured Slack host accepts its
+// token.
+func (sr *slackReporter) CheckConnectivity(ctx context.Context) error {
+	var errs []error
+	for host, client := range sr.clients {
+		if err := client.AuthTest(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("host %s: %w", host, err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// Validate checks that every configured Slack host accepts its token and
+// that the channels of the configs of all orgs and repos exist on their
+// host. Channels set on jobs aren't checked.
+func (sr *slackReporter) Validate(ctx context.Context) error {
+	if err := sr.CheckConnectivity(ctx); err != nil {
+		return err
+	}
+
+	channelsByHost := map[string]sets.Set[string]{}
+	add := func(host string, channels ...string) {
+		if channelsByHost[host] == nil {
+			channelsByHost[host] = sets.New[string]()
+		}
+		for _, channel := range channels {
+			if channel != "" {
+				channelsByHost[host].Insert(channel)
+			}
+		}
+	}
+	var keys []string
+	if sr.configKeys != nil {
+		keys = sr.configKeys()
+	}
+	for _, refs := range criercommonlib.RefsForConfigKeys(keys) {
+		cfg := sr.config(refs)
+		host, channel := hostAndChannel(&cfg.SlackReporterConfig)
+		add(host, channel)
+		add(host, cfg.Channels...)
+		add(host, sets.List(sets.KeySet(cfg.ChannelTopics))...)
+	}
+
+	var errs []error
+	for _, host := range sets.List(sets.KeySet(channelsByHost)) {
+		client, ok := sr.clients[host]
+		if !ok {
+			errs = append(errs, fmt.Errorf("host %s has no token", host))
+			continue
+		}
+		if channelsByHost[host].Len() == 0 {
+			continue
+		}
+		existing, err := client.Channels(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("host %s: %w", host, err))
+			continue
+		}
+		for _, channel := range sets.List(channelsByHost[host]) {
+			if _, ok := existing[strings.TrimPrefix(channel, "#")]; !ok {
+				errs = append(errs, fmt.Errorf("host %s: channel %s not found", host, channel))
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
 func (sr *slackReporter) GetName() string {
 	return reporterName
 }
 
+// ShouldReport returns whether a message should be posted for the job, or
+// the job's result is shown in the topic of a channel.
 func (sr *slackReporter) ShouldReport(_ context.Context, logger *logrus.Entry, pj *prowapi.ProwJob) bool {
+	globalSlackConfig, _ := sr.getConfig(pj)
+	return tracksTopic(globalSlackConfig, pj) || sr.shouldReportMessage(logger, pj)
+}
+
+// shouldReportMessage returns whether a message should be posted for the
+// job.
+func (sr *slackReporter) shouldReportMessage(logger *logrus.Entry, pj *prowapi.ProwJob) bool {
 	globalSlackConfig, jobSlackConfig := sr.getConfig(pj)
 
 	var typeShouldReport bool
@@ -149,18 +572,32 @@ func (sr *slackReporter) ShouldReport(_ context.Context, logger *logrus.Entry, p
 	}
 
 	shouldReport := stateShouldReport && (typeShouldReport || jobShouldReport)
+	if shouldReport && globalSlackConfig.QuietHours != nil && globalSlackConfig.QuietHours.Contains(sr.now()) &&
+		pj.Status.State != prowapi.FailureState && pj.Status.State != prowapi.ErrorState {
+		logger.WithField("state", pj.Status.State).Debug("Skip slack reporting during quiet hours.")
+		return false
+	}
 	logger.WithField("reporting", shouldReport).Debug("Determined should report")
 	return shouldReport
 }
 
-func New(cfg func(refs *prowapi.Refs) config.SlackReporter, dryRun bool, tokensMap map[string]func() []byte) *slackReporter {
+// New returns a Slack reporter. configKeys returns the keys of the config
+// that cfg resolves, which are checked by Validate. The IDs of channels are
+// looked up again once channelCacheTTL passed.
+func New(cfg func(refs *prowapi.Refs) config.SlackReporter, configKeys func() []string, dryRun bool, tokensMap map[string]func() []byte, pjclient ctrlruntimeclient.Client, channelCacheTTL time.Duration, httpClient *http.Client) *slackReporter {
 	clients := map[string]slackClient{}
 	for key, val := range tokensMap {
-		clients[key] = slackclient.NewClient(val)
+		clients[key] = slackclient.NewClient(val, httpClient)
 	}
 	return &slackReporter{
-		clients: clients,
-		config:  cfg,
-		dryRun:  dryRun,
+		clients:    clients,
+		config:     cfg,
+		configKeys: configKeys,
+		dryRun:     dryRun,
+		coalescer:  newCoalescer(),
+		pjclient:   pjclient,
+		topics:     newTopicThrottle(topicUpdateInterval),
+		channels:   newChannelCache(channelCacheTTL),
+		now:        time.Now,
 	}
 }
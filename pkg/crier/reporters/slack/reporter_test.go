@@ -18,12 +18,27 @@ package slack
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
 	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/crier/reporters/criercommonlib"
+	slackclient "sigs.k8s.io/prow/pkg/slack"
+	"sigs.k8s.io/prow/pkg/testutil"
 )
 
 func TestShouldReport(t *testing.T) {
@@ -72,6 +87,42 @@ func TestShouldReport(t *testing.T) {
 			},
 			expected: false,
 		},
+		{
+			name: "Presubmit Job should not report when only postsubmits and periodics are listed",
+			config: config.SlackReporter{
+				JobTypesToReport: []v1.ProwJobType{v1.PostsubmitJob, v1.PeriodicJob},
+				SlackReporterConfig: v1.SlackReporterConfig{
+					JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+				},
+			},
+			pj: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Type: v1.PresubmitJob,
+				},
+				Status: v1.ProwJobStatus{
+					State: v1.FailureState,
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "Periodic Job should report when postsubmits and periodics are listed",
+			config: config.SlackReporter{
+				JobTypesToReport: []v1.ProwJobType{v1.PostsubmitJob, v1.PeriodicJob},
+				SlackReporterConfig: v1.SlackReporterConfig{
+					JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+				},
+			},
+			pj: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Type: v1.PeriodicJob,
+				},
+				Status: v1.ProwJobStatus{
+					State: v1.FailureState,
+				},
+			},
+			expected: true,
+		},
 		{
 			name: "Successful Job should report",
 			config: config.SlackReporter{
@@ -620,20 +671,271 @@ func TestShouldReportDefaultsToExtraRefs(t *testing.T) {
 	}
 }
 
+func TestShouldReportQuietHours(t *testing.T) {
+	quietHours := &config.SlackQuietHours{TimeZone: "America/New_York", Start: "20:00", End: "08:00"}
+	testCases := []struct {
+		name     string
+		now      time.Time
+		state    v1.ProwJobState
+		expected bool
+	}{
+		{
+			name:     "success outside of quiet hours is reported",
+			now:      time.Date(2024, time.June, 3, 16, 0, 0, 0, time.UTC),
+			state:    v1.SuccessState,
+			expected: true,
+		},
+		{
+			name:  "success during quiet hours is not reported",
+			now:   time.Date(2024, time.June, 4, 2, 0, 0, 0, time.UTC),
+			state: v1.SuccessState,
+		},
+		{
+			name:  "pending during quiet hours is not reported",
+			now:   time.Date(2024, time.June, 4, 2, 0, 0, 0, time.UTC),
+			state: v1.PendingState,
+		},
+		{
+			name:     "failure during quiet hours is reported",
+			now:      time.Date(2024, time.June, 4, 2, 0, 0, 0, time.UTC),
+			state:    v1.FailureState,
+			expected: true,
+		},
+		{
+			name:     "error during quiet hours is reported",
+			now:      time.Date(2024, time.June, 4, 2, 0, 0, 0, time.UTC),
+			state:    v1.ErrorState,
+			expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			job := &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PeriodicJob},
+				Status: v1.ProwJobStatus{State: tc.state},
+			}
+			sr := slackReporter{
+				config: func(*v1.Refs) config.SlackReporter {
+					return config.SlackReporter{
+						JobTypesToReport: []v1.ProwJobType{v1.PeriodicJob},
+						QuietHours:       quietHours,
+						SlackReporterConfig: v1.SlackReporterConfig{
+							Channel:           "my-channel",
+							JobStatesToReport: []v1.ProwJobState{v1.PendingState, v1.SuccessState, v1.FailureState, v1.ErrorState},
+						},
+					}
+				},
+				now: func() time.Time { return tc.now },
+			}
+			if actual := sr.ShouldReport(context.Background(), logrus.NewEntry(logrus.StandardLogger()), job); actual != tc.expected {
+				t.Errorf("expected ShouldReport to be %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
 type fakeSlackClient struct {
 	messages map[string]string
+	// writes are the channels written to with WriteMessage, in order.
+	writes []string
+	// threads holds the replies of threaded messages by channel.
+	threads map[string][]string
+	// posts are the messages posted with PostMessage.
+	posts []fakePost
+	// deletedThreads are the timestamps of deleted messages.
+	deletedThreads sets.Set[string]
+	// errors are returned when writing to the given channels.
+	errors map[string]error
+	// authErr is returned by AuthTest.
+	authErr error
+	// topics are the topics set per channel.
+	topics map[string][]string
+	// channels maps the names and IDs of the channels that exist to
+	// their IDs.
+	channels map[string]string
+	// channelLookups counts the calls of Channels.
+	channelLookups int
+}
+
+type fakePost struct {
+	channel  string
+	threadTS string
+	text     string
+	blocks   []slackclient.Block
 }
 
-func (fsc *fakeSlackClient) WriteMessage(text, channel string) error {
+func (fsc *fakeSlackClient) WriteMessage(_ context.Context, text, channel string) error {
+	if err := fsc.errors[channel]; err != nil {
+		return err
+	}
 	if fsc.messages == nil {
 		fsc.messages = map[string]string{}
 	}
 	fsc.messages[channel] = text
+	fsc.writes = append(fsc.writes, channel)
 	return nil
 }
 
+func (fsc *fakeSlackClient) WriteThreadedMessage(ctx context.Context, text string, replies []string, channel string) error {
+	if err := fsc.WriteMessage(ctx, text, channel); err != nil {
+		return err
+	}
+	if fsc.threads == nil {
+		fsc.threads = map[string][]string{}
+	}
+	fsc.threads[channel] = replies
+	return nil
+}
+
+func (fsc *fakeSlackClient) PostMessage(_ context.Context, text, channel, threadTS string) (string, error) {
+	if err := fsc.errors[channel]; err != nil {
+		return "", err
+	}
+	if threadTS != "" && fsc.deletedThreads.Has(threadTS) {
+		return "", slackclient.ErrThreadNotFound
+	}
+	fsc.posts = append(fsc.posts, fakePost{channel: channel, threadTS: threadTS, text: text})
+	return fmt.Sprintf("ts-%d", len(fsc.posts)), nil
+}
+
+func (fsc *fakeSlackClient) PostBlocks(ctx context.Context, text string, blocks []slackclient.Block, channel, threadTS string) (string, error) {
+	ts, err := fsc.PostMessage(ctx, text, channel, threadTS)
+	if err != nil {
+		return "", err
+	}
+	fsc.posts[len(fsc.posts)-1].blocks = blocks
+	return ts, nil
+}
+
+func (fsc *fakeSlackClient) SetTopic(_ context.Context, channel, topic string) error {
+	if err := fsc.errors[channel]; err != nil {
+		return err
+	}
+	if fsc.topics == nil {
+		fsc.topics = map[string][]string{}
+	}
+	fsc.topics[channel] = append(fsc.topics[channel], topic)
+	return nil
+}
+
+func (fsc *fakeSlackClient) AuthTest(_ context.Context) error {
+	return fsc.authErr
+}
+
+func (fsc *fakeSlackClient) Channels(_ context.Context) (map[string]string, error) {
+	fsc.channelLookups++
+	return fsc.channels, nil
+}
+
 var _ slackClient = &fakeSlackClient{}
 
+// channelIDs returns the channel IDs the fake client knows, which are the
+// channels themselves.
+func channelIDs(channels ...string) map[string]string {
+	ids := map[string]string{}
+	for _, channel := range channels {
+		ids[channel] = channel
+	}
+	return ids
+}
+
+func TestCheckConnectivity(t *testing.T) {
+	testCases := []struct {
+		name        string
+		clients     map[string]slackClient
+		expectedErr bool
+	}{
+		{
+			name:    "all hosts reachable",
+			clients: map[string]slackClient{DefaultHostName: &fakeSlackClient{}, "other": &fakeSlackClient{}},
+		},
+		{
+			name:        "one host unreachable",
+			clients:     map[string]slackClient{DefaultHostName: &fakeSlackClient{}, "other": &fakeSlackClient{authErr: errors.New("invalid_auth")}},
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sr := slackReporter{clients: tc.clients}
+			if err := sr.CheckConnectivity(context.Background()); (err != nil) != tc.expectedErr {
+				t.Errorf("expected error: %t, got: %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	configs := config.SlackReporterConfigs{
+		"*": {
+			SlackReporterConfig: v1.SlackReporterConfig{Channel: "#prow"},
+		},
+		"org": {
+			Channels:      []string{"C0123"},
+			ChannelTopics: map[string]config.SlackChannelTopic{"#org-status": {}},
+		},
+		"other-org/repo": {
+			SlackReporterConfig: v1.SlackReporterConfig{Host: "other", Channel: "repo"},
+		},
+	}
+	testCases := []struct {
+		name        string
+		clients     map[string]slackClient
+		expectedErr string
+	}{
+		{
+			name: "all channels exist",
+			clients: map[string]slackClient{
+				DefaultHostName: &fakeSlackClient{channels: channelIDs("prow", "C0123", "org-status")},
+				"other":         &fakeSlackClient{channels: channelIDs("repo")},
+			},
+		},
+		{
+			name: "missing channels fail",
+			clients: map[string]slackClient{
+				DefaultHostName: &fakeSlackClient{channels: channelIDs("prow")},
+				"other":         &fakeSlackClient{channels: channelIDs("repo")},
+			},
+			expectedErr: "[host *: channel #org-status not found, host *: channel C0123 not found]",
+		},
+		{
+			name: "host without token fails",
+			clients: map[string]slackClient{
+				DefaultHostName: &fakeSlackClient{channels: channelIDs("prow", "C0123", "org-status")},
+			},
+			expectedErr: "host other has no token",
+		},
+		{
+			name: "rejected token fails",
+			clients: map[string]slackClient{
+				DefaultHostName: &fakeSlackClient{authErr: errors.New("invalid_auth")},
+				"other":         &fakeSlackClient{channels: channelIDs("repo")},
+			},
+			expectedErr: "host *: invalid_auth",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sr := slackReporter{
+				clients: tc.clients,
+				config:  configs.GetSlackReporter,
+				configKeys: func() []string {
+					return sets.List(sets.KeySet(configs))
+				},
+			}
+			err := sr.Validate(context.Background())
+			var got string
+			if err != nil {
+				got = err.Error()
+			}
+			if got != tc.expectedErr {
+				t.Errorf("expected error %q, got %q", tc.expectedErr, got)
+			}
+		})
+	}
+}
+
 func TestReportDefaultsToExtraRefs(t *testing.T) {
 	job := &v1.ProwJob{
 		Spec: v1.ProwJobSpec{
@@ -669,3 +971,774 @@ func TestReportDefaultsToExtraRefs(t *testing.T) {
 		t.Errorf("expected the channel 'emergency' to contain message 'there you go' but wasn't the case, all messages: %v", fsc.messages)
 	}
 }
+
+func TestReportTemplate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		template    string
+		expected    string
+		expectedErr bool
+	}{
+		{
+			name:     "template accesses job fields",
+			template: "{{.Spec.Job}} ended with {{.Status.State}}: {{.Status.URL}}",
+			expected: "my-job ended with failure: https://prow.k8s.io/view/my-job/1",
+		},
+		{
+			name:        "unparsable template errors",
+			template:    "{{ if .Spec.Job }}",
+			expectedErr: true,
+		},
+		{
+			name:        "template accessing invalid field errors",
+			template:    "{{.Undef}}",
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			job := &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Job:  "my-job",
+					Type: v1.PeriodicJob,
+					Refs: &v1.Refs{Org: "org"},
+				},
+				Status: v1.ProwJobStatus{
+					State: v1.FailureState,
+					URL:   "https://prow.k8s.io/view/my-job/1",
+				},
+			}
+			fsc := &fakeSlackClient{}
+			sr := slackReporter{
+				config: func(*v1.Refs) config.SlackReporter {
+					return config.SlackReporter{
+						SlackReporterConfig: v1.SlackReporterConfig{
+							Channel:        "oncall",
+							ReportTemplate: tc.template,
+						},
+					}
+				},
+				clients: map[string]slackClient{DefaultHostName: fsc},
+			}
+
+			_, _, err := sr.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), job)
+			if tc.expectedErr != (err != nil) {
+				t.Fatalf("expected error: %t, got: %v", tc.expectedErr, err)
+			}
+			if tc.expectedErr {
+				return
+			}
+			if fsc.messages["oncall"] != tc.expected {
+				t.Errorf("expected message %q, got %q", tc.expected, fsc.messages["oncall"])
+			}
+		})
+	}
+}
+
+func TestReportJobStatesToMessages(t *testing.T) {
+	testCases := []struct {
+		name        string
+		state       v1.ProwJobState
+		jobTemplate string
+		expected    string
+	}{
+		{
+			name:     "state with a message uses it",
+			state:    v1.FailureState,
+			expected: ":x: my-job failed",
+		},
+		{
+			name:     "state without a message uses the report template",
+			state:    v1.AbortedState,
+			expected: "my-job ended with aborted",
+		},
+		{
+			name:        "report template of the job takes precedence",
+			state:       v1.FailureState,
+			jobTemplate: "{{.Spec.Job}} is {{.Status.State}}",
+			expected:    "my-job is failure",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			job := &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Job:  "my-job",
+					Type: v1.PeriodicJob,
+					Refs: &v1.Refs{Org: "org"},
+				},
+				Status: v1.ProwJobStatus{
+					State: tc.state,
+				},
+			}
+			if tc.jobTemplate != "" {
+				job.Spec.ReporterConfig = &v1.ReporterConfig{Slack: &v1.SlackReporterConfig{ReportTemplate: tc.jobTemplate}}
+			}
+			fsc := &fakeSlackClient{}
+			sr := slackReporter{
+				config: func(*v1.Refs) config.SlackReporter {
+					return config.SlackReporter{
+						JobStatesToMessages: map[v1.ProwJobState]string{
+							v1.SuccessState: ":white_check_mark: {{.Spec.Job}} succeeded",
+							v1.FailureState: ":x: {{.Spec.Job}} failed",
+						},
+						SlackReporterConfig: v1.SlackReporterConfig{
+							Channel:        "oncall",
+							ReportTemplate: "{{.Spec.Job}} ended with {{.Status.State}}",
+						},
+					}
+				},
+				clients: map[string]slackClient{DefaultHostName: fsc},
+			}
+
+			if _, _, err := sr.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), job); err != nil {
+				t.Fatalf("reporting failed: %v", err)
+			}
+			if fsc.messages["oncall"] != tc.expected {
+				t.Errorf("expected message %q, got %q", tc.expected, fsc.messages["oncall"])
+			}
+		})
+	}
+}
+
+func TestReportMentionsOnFailure(t *testing.T) {
+	testCases := []struct {
+		name     string
+		state    v1.ProwJobState
+		mentions []string
+		expected string
+	}{
+		{
+			name:     "users and groups are mentioned on failure",
+			state:    v1.FailureState,
+			mentions: []string{"U123", "W456", "S789"},
+			expected: "my-job ended with failure <@U123> <@W456> <!subteam^S789>",
+		},
+		{
+			name:     "mentions on error",
+			state:    v1.ErrorState,
+			mentions: []string{"S789"},
+			expected: "my-job ended with error <!subteam^S789>",
+		},
+		{
+			name:     "no mentions on success",
+			state:    v1.SuccessState,
+			mentions: []string{"U123", "S789"},
+			expected: "my-job ended with success",
+		},
+		{
+			name:     "no mentions on abort",
+			state:    v1.AbortedState,
+			mentions: []string{"U123"},
+			expected: "my-job ended with aborted",
+		},
+		{
+			name:     "failure without mentions configured",
+			state:    v1.FailureState,
+			expected: "my-job ended with failure",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			job := &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Job:  "my-job",
+					Type: v1.PeriodicJob,
+					Refs: &v1.Refs{Org: "org"},
+				},
+				Status: v1.ProwJobStatus{
+					State: tc.state,
+				},
+			}
+			fsc := &fakeSlackClient{}
+			sr := slackReporter{
+				config: func(*v1.Refs) config.SlackReporter {
+					return config.SlackReporter{
+						MentionsOnFailure: tc.mentions,
+						SlackReporterConfig: v1.SlackReporterConfig{
+							Channel:        "oncall",
+							ReportTemplate: "{{.Spec.Job}} ended with {{.Status.State}}",
+						},
+					}
+				},
+				clients: map[string]slackClient{DefaultHostName: fsc},
+			}
+
+			if _, _, err := sr.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), job); err != nil {
+				t.Fatalf("reporting failed: %v", err)
+			}
+			if fsc.messages["oncall"] != tc.expected {
+				t.Errorf("expected message %q, got %q", tc.expected, fsc.messages["oncall"])
+			}
+		})
+	}
+}
+
+func TestReportStateEmojis(t *testing.T) {
+	emojis := map[v1.ProwJobState]string{
+		v1.TriggeredState: ":rocket:",
+		v1.PendingState:   ":running:",
+		v1.SuccessState:   ":sunny:",
+		v1.FailureState:   ":fire:",
+		v1.AbortedState:   ":octagonal_sign:",
+	}
+	testCases := []struct {
+		name     string
+		state    v1.ProwJobState
+		emojis   map[v1.ProwJobState]string
+		expected string
+	}{
+		{
+			name:     "unmapped state uses the default emoji",
+			state:    v1.ErrorState,
+			emojis:   emojis,
+			expected: ":warning: my-job ended with error",
+		},
+		{
+			name:     "unmapped aborted state uses a neutral emoji",
+			state:    v1.AbortedState,
+			emojis:   map[v1.ProwJobState]string{v1.FailureState: ":fire:"},
+			expected: ":white_circle: my-job ended with aborted",
+		},
+		{
+			name:     "no emojis configured",
+			state:    v1.FailureState,
+			expected: "my-job ended with failure",
+		},
+	}
+	for state, emoji := range emojis {
+		testCases = append(testCases, struct {
+			name     string
+			state    v1.ProwJobState
+			emojis   map[v1.ProwJobState]string
+			expected string
+		}{
+			name:     fmt.Sprintf("configured emoji for %s", state),
+			state:    state,
+			emojis:   emojis,
+			expected: fmt.Sprintf("%s my-job ended with %s", emoji, state),
+		})
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			job := &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Job:  "my-job",
+					Type: v1.PeriodicJob,
+					Refs: &v1.Refs{Org: "org"},
+				},
+				Status: v1.ProwJobStatus{
+					State: tc.state,
+				},
+			}
+			fsc := &fakeSlackClient{}
+			sr := slackReporter{
+				config: func(*v1.Refs) config.SlackReporter {
+					return config.SlackReporter{
+						StateEmojis: tc.emojis,
+						SlackReporterConfig: v1.SlackReporterConfig{
+							Channel:        "builds",
+							ReportTemplate: "{{.Spec.Job}} ended with {{.Status.State}}",
+						},
+					}
+				},
+				clients: map[string]slackClient{DefaultHostName: fsc},
+			}
+
+			if _, _, err := sr.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), job); err != nil {
+				t.Fatalf("reporting failed: %v", err)
+			}
+			if fsc.messages["builds"] != tc.expected {
+				t.Errorf("expected message %q, got %q", tc.expected, fsc.messages["builds"])
+			}
+		})
+	}
+}
+
+func TestReportToMultipleChannels(t *testing.T) {
+	testCases := []struct {
+		name             string
+		config           config.SlackReporter
+		jobConfig        *v1.SlackReporterConfig
+		errors           map[string]error
+		expectedMessages map[string]string
+		expectedErr      string
+		expectedErrClass string
+	}{
+		{
+			name: "message is sent to channel and channels",
+			config: config.SlackReporter{
+				Channels:            []string{"dashboard", "team"},
+				SlackReporterConfig: v1.SlackReporterConfig{Channel: "team", ReportTemplate: "msg"},
+			},
+			expectedMessages: map[string]string{"team": "msg", "dashboard": "msg"},
+		},
+		{
+			name: "channels without channel",
+			config: config.SlackReporter{
+				Channels:            []string{"dashboard", "team"},
+				SlackReporterConfig: v1.SlackReporterConfig{ReportTemplate: "msg"},
+			},
+			expectedMessages: map[string]string{"team": "msg", "dashboard": "msg"},
+		},
+		{
+			name: "job channel overrides all global channels",
+			config: config.SlackReporter{
+				Channels:            []string{"dashboard"},
+				SlackReporterConfig: v1.SlackReporterConfig{Channel: "team", ReportTemplate: "msg"},
+			},
+			jobConfig:        &v1.SlackReporterConfig{Channel: "job"},
+			expectedMessages: map[string]string{"job": "msg"},
+		},
+		{
+			name: "failing channels don't block the others and are listed",
+			config: config.SlackReporter{
+				Channels:            []string{"dashboard", "archive", "team"},
+				SlackReporterConfig: v1.SlackReporterConfig{ReportTemplate: "msg"},
+			},
+			errors: map[string]error{
+				"dashboard": errors.New("channel_not_found"),
+				"team":      errors.New("not_in_channel"),
+			},
+			expectedMessages: map[string]string{"archive": "msg"},
+			expectedErr:      "failed to write Slack message to channel(s) dashboard, team: [channel_not_found, not_in_channel]",
+		},
+		{
+			name: "channels that don't exist are a permanent error",
+			config: config.SlackReporter{
+				Channels:            []string{"dashboard", "team"},
+				SlackReporterConfig: v1.SlackReporterConfig{ReportTemplate: "msg"},
+			},
+			errors: map[string]error{
+				"dashboard": &slackclient.APIError{StatusCode: 200, Code: "channel_not_found"},
+				"team":      &slackclient.APIError{StatusCode: 200, Code: "is_archived"},
+			},
+			expectedErr:      "failed to write Slack message to channel(s) dashboard, team: [request failed: channel_not_found, request failed: is_archived]",
+			expectedErrClass: criercommonlib.ErrorClassPermanent,
+		},
+		{
+			name: "any retryable channel makes the error transient",
+			config: config.SlackReporter{
+				Channels:            []string{"dashboard", "team"},
+				SlackReporterConfig: v1.SlackReporterConfig{ReportTemplate: "msg"},
+			},
+			errors: map[string]error{
+				"dashboard": &slackclient.APIError{StatusCode: 200, Code: "channel_not_found"},
+				"team":      &slackclient.APIError{StatusCode: 503},
+			},
+			expectedErr:      "failed to write Slack message to channel(s) dashboard, team: [request failed: channel_not_found, request failed: status 503]",
+			expectedErrClass: criercommonlib.ErrorClassTransient,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			job := &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Type: v1.PeriodicJob,
+					Refs: &v1.Refs{Org: "org"},
+				},
+				Status: v1.ProwJobStatus{
+					State: v1.FailureState,
+				},
+			}
+			job.Name = "job-id"
+			if tc.jobConfig != nil {
+				job.Spec.ReporterConfig = &v1.ReporterConfig{Slack: tc.jobConfig}
+			}
+			fsc := &fakeSlackClient{errors: tc.errors}
+			sr := slackReporter{
+				config:   func(*v1.Refs) config.SlackReporter { return tc.config },
+				clients:  map[string]slackClient{DefaultHostName: fsc},
+				pjclient: fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(job).Build(),
+			}
+
+			_, _, err := sr.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), job)
+			var errMsg string
+			if err != nil {
+				errMsg = err.Error()
+			}
+			if errMsg != tc.expectedErr {
+				t.Errorf("expected error %q, got %q", tc.expectedErr, errMsg)
+			}
+			if tc.expectedErrClass != "" {
+				if class := criercommonlib.ErrorClass(err); class != tc.expectedErrClass {
+					t.Errorf("expected error class %q, got %q", tc.expectedErrClass, class)
+				}
+			}
+			if diff := cmp.Diff(tc.expectedMessages, fsc.messages); diff != "" {
+				t.Errorf("messages differ from expected: %s", diff)
+			}
+		})
+	}
+}
+
+func TestReportRetryOnlyWritesToFailedChannels(t *testing.T) {
+	pj := &v1.ProwJob{
+		Spec: v1.ProwJobSpec{
+			Job:  "my-job",
+			Type: v1.PeriodicJob,
+		},
+		Status: v1.ProwJobStatus{
+			State: v1.FailureState,
+		},
+	}
+	pj.Name = "my-job-id"
+	pjclient := fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(pj).Build()
+	fsc := &fakeSlackClient{errors: map[string]error{"team": errors.New("slack is down")}}
+	sr := slackReporter{
+		config: func(*v1.Refs) config.SlackReporter {
+			return config.SlackReporter{
+				Channels:            []string{"dashboard", "team"},
+				SlackReporterConfig: v1.SlackReporterConfig{ReportTemplate: "{{.Spec.Job}} is {{.Status.State}}"},
+			}
+		},
+		clients:  map[string]slackClient{DefaultHostName: fsc},
+		pjclient: pjclient,
+	}
+	report := func(state v1.ProwJobState) error {
+		t.Helper()
+		current := &v1.ProwJob{}
+		if err := pjclient.Get(context.Background(), types.NamespacedName{Name: pj.Name}, current); err != nil {
+			t.Fatalf("failed to get prowjob: %v", err)
+		}
+		current.Status.State = state
+		_, _, err := sr.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), current)
+		return err
+	}
+
+	if err := report(v1.FailureState); err == nil {
+		t.Fatal("expected the report to fail")
+	}
+	fsc.errors = nil
+	if err := report(v1.FailureState); err != nil {
+		t.Fatalf("reporting failed: %v", err)
+	}
+	// A later state is written to all channels again.
+	if err := report(v1.SuccessState); err != nil {
+		t.Fatalf("reporting failed: %v", err)
+	}
+
+	expected := []string{"dashboard", "team", "dashboard", "team"}
+	if diff := cmp.Diff(expected, fsc.writes); diff != "" {
+		t.Errorf("writes differ from expected: %s", diff)
+	}
+}
+
+func TestReportInThread(t *testing.T) {
+	pj := &v1.ProwJob{
+		Spec: v1.ProwJobSpec{
+			Job:  "my-job",
+			Type: v1.PeriodicJob,
+		},
+		Status: v1.ProwJobStatus{
+			State: v1.PendingState,
+		},
+	}
+	pj.Name = "my-job-id"
+	pj.Namespace = "prowjobs"
+	pjclient := fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(pj).Build()
+	fsc := &fakeSlackClient{}
+	sr := slackReporter{
+		config: func(*v1.Refs) config.SlackReporter {
+			return config.SlackReporter{
+				Channels:      []string{"dashboard"},
+				ReplyInThread: true,
+				SlackReporterConfig: v1.SlackReporterConfig{
+					Channel:        "team",
+					ReportTemplate: "{{.Spec.Job}} is {{.Status.State}}",
+				},
+			}
+		},
+		clients:  map[string]slackClient{DefaultHostName: fsc},
+		pjclient: pjclient,
+	}
+	report := func(state v1.ProwJobState) {
+		t.Helper()
+		current := &v1.ProwJob{}
+		if err := pjclient.Get(context.Background(), types.NamespacedName{Namespace: pj.Namespace, Name: pj.Name}, current); err != nil {
+			t.Fatalf("failed to get prowjob: %v", err)
+		}
+		current.Status.State = state
+		if _, _, err := sr.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), current); err != nil {
+			t.Fatalf("reporting failed: %v", err)
+		}
+	}
+	assertThreads := func(expected string) {
+		t.Helper()
+		current := &v1.ProwJob{}
+		if err := pjclient.Get(context.Background(), types.NamespacedName{Namespace: pj.Namespace, Name: pj.Name}, current); err != nil {
+			t.Fatalf("failed to get prowjob: %v", err)
+		}
+		if actual := current.Annotations[ThreadsAnnotation]; actual != expected {
+			t.Errorf("expected threads annotation %q, got %q", expected, actual)
+		}
+	}
+
+	// The first report starts a thread in every channel.
+	report(v1.PendingState)
+	assertThreads(`{"dashboard":"ts-2","team":"ts-1"}`)
+
+	// Later reports reply in the threads.
+	report(v1.FailureState)
+	assertThreads(`{"dashboard":"ts-2","team":"ts-1"}`)
+
+	// A deleted first message is replaced by a new one.
+	fsc.deletedThreads = sets.New[string]("ts-1")
+	report(v1.SuccessState)
+	assertThreads(`{"dashboard":"ts-2","team":"ts-5"}`)
+
+	expected := []fakePost{
+		{channel: "team", text: "my-job is pending"},
+		{channel: "dashboard", text: "my-job is pending"},
+		{channel: "team", threadTS: "ts-1", text: "my-job is failure"},
+		{channel: "dashboard", threadTS: "ts-2", text: "my-job is failure"},
+		{channel: "team", text: "my-job is success"},
+		{channel: "dashboard", threadTS: "ts-2", text: "my-job is success"},
+	}
+	if diff := cmp.Diff(expected, fsc.posts, cmp.AllowUnexported(fakePost{})); diff != "" {
+		t.Errorf("posts differ from expected: %s", diff)
+	}
+}
+
+func TestBlocksFor(t *testing.T) {
+	start := metav1.NewTime(time.Date(2024, time.May, 1, 10, 0, 0, 0, time.UTC))
+	completion := metav1.NewTime(start.Add(12*time.Minute + 34*time.Second))
+	testCases := []struct {
+		name   string
+		golden string
+		job    *v1.ProwJob
+		text   string
+		group  string
+	}{
+		{
+			name:   "completed job with logs",
+			golden: "blocks_completed.json",
+			job: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{Job: "my-job"},
+				Status: v1.ProwJobStatus{
+					State:          v1.FailureState,
+					StartTime:      start,
+					CompletionTime: &completion,
+					URL:            "https://prow.example.com/view/my-job/1",
+				},
+			},
+			text: "Job my-job ended with failure <@U123>",
+		},
+		{
+			name:   "pending job without logs",
+			golden: "blocks_pending.json",
+			job: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{Job: "my-job"},
+				Status: v1.ProwJobStatus{
+					State:     v1.PendingState,
+					StartTime: start,
+				},
+			},
+			text: "Job my-job is pending",
+		},
+		{
+			name:   "long job name is truncated",
+			golden: "blocks_long_name.json",
+			job: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{Job: strings.Repeat("a", 200)},
+				Status: v1.ProwJobStatus{
+					State:     v1.TriggeredState,
+					StartTime: start,
+				},
+			},
+			text: "Job triggered",
+		},
+		{
+			name:   "job with group",
+			golden: "blocks_grouped.json",
+			job: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{Job: "my-job"},
+				Status: v1.ProwJobStatus{
+					State:          v1.SuccessState,
+					StartTime:      start,
+					CompletionTime: &completion,
+					URL:            "https://prow.example.com/view/my-job/1",
+				},
+			},
+			text:  "Job my-job ended with success",
+			group: "Group: release-1.2",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			raw, err := json.MarshalIndent(blocksFor(tc.job, tc.text, tc.group), "", "  ")
+			if err != nil {
+				t.Fatalf("failed to marshal blocks: %v", err)
+			}
+			output := filepath.Join(t.TempDir(), tc.golden)
+			if err := os.WriteFile(output, append(raw, '\n'), 0644); err != nil {
+				t.Fatalf("failed to write blocks: %v", err)
+			}
+			testutil.CompareWithFixture(t, filepath.Join("testdata", tc.golden), output)
+		})
+	}
+}
+
+func TestReportUseBlockKit(t *testing.T) {
+	testCases := []struct {
+		name          string
+		useBlockKit   bool
+		replyInThread bool
+		expectBlocks  bool
+	}{
+		{
+			name: "text by default",
+		},
+		{
+			name:         "blocks",
+			useBlockKit:  true,
+			expectBlocks: true,
+		},
+		{
+			name:          "blocks in threads",
+			useBlockKit:   true,
+			replyInThread: true,
+			expectBlocks:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			job := &v1.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-job-1", Namespace: "prowjobs"},
+				Spec: v1.ProwJobSpec{
+					Job:  "my-job",
+					Type: v1.PeriodicJob,
+					Refs: &v1.Refs{Org: "org"},
+				},
+				Status: v1.ProwJobStatus{
+					State: v1.SuccessState,
+					URL:   "https://prow.example.com/view/my-job/1",
+				},
+			}
+			fsc := &fakeSlackClient{}
+			sr := slackReporter{
+				config: func(*v1.Refs) config.SlackReporter {
+					return config.SlackReporter{
+						UseBlockKit:   tc.useBlockKit,
+						ReplyInThread: tc.replyInThread,
+						SlackReporterConfig: v1.SlackReporterConfig{
+							Channel:        "oncall",
+							ReportTemplate: "{{.Spec.Job}} ended with {{.Status.State}}",
+						},
+					}
+				},
+				clients:  map[string]slackClient{DefaultHostName: fsc},
+				pjclient: fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(job).Build(),
+			}
+
+			if _, _, err := sr.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), job); err != nil {
+				t.Fatalf("reporting failed: %v", err)
+			}
+			if !tc.expectBlocks {
+				if len(fsc.posts) != 0 || fsc.messages["oncall"] != "my-job ended with success" {
+					t.Errorf("expected a text message, got messages %v and posts %v", fsc.messages, fsc.posts)
+				}
+				return
+			}
+			if len(fsc.posts) != 1 {
+				t.Fatalf("expected 1 post, got %d", len(fsc.posts))
+			}
+			post := fsc.posts[0]
+			if post.text != "my-job ended with success" {
+				t.Errorf("expected fallback text %q, got %q", "my-job ended with success", post.text)
+			}
+			if diff := cmp.Diff(blocksFor(job, post.text, ""), post.blocks); diff != "" {
+				t.Errorf("unexpected blocks (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReportGroupingAnnotation(t *testing.T) {
+	testCases := []struct {
+		name         string
+		useBlockKit  bool
+		annotations  map[string]string
+		expectedText string
+		expectGroup  bool
+	}{
+		{
+			name:         "no annotation",
+			expectedText: "my-job ended with success",
+		},
+		{
+			name:         "annotation in text",
+			annotations:  map[string]string{"example.com/workflow-run": "release-1.2"},
+			expectedText: "my-job ended with success\nGroup: release-1.2",
+		},
+		{
+			name:         "annotation in context block",
+			useBlockKit:  true,
+			annotations:  map[string]string{"example.com/workflow-run": "release-1.2"},
+			expectedText: "my-job ended with success",
+			expectGroup:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			job := &v1.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-job-1", Namespace: "prowjobs", Annotations: tc.annotations},
+				Spec: v1.ProwJobSpec{
+					Job:  "my-job",
+					Type: v1.PeriodicJob,
+					Refs: &v1.Refs{Org: "org"},
+				},
+				Status: v1.ProwJobStatus{State: v1.SuccessState},
+			}
+			fsc := &fakeSlackClient{}
+			sr := slackReporter{
+				config: func(*v1.Refs) config.SlackReporter {
+					return config.SlackReporter{
+						UseBlockKit:        tc.useBlockKit,
+						GroupingAnnotation: "example.com/workflow-run",
+						SlackReporterConfig: v1.SlackReporterConfig{
+							Channel:        "oncall",
+							ReportTemplate: "{{.Spec.Job}} ended with {{.Status.State}}",
+						},
+					}
+				},
+				clients:  map[string]slackClient{DefaultHostName: fsc},
+				pjclient: fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(job).Build(),
+			}
+
+			if _, _, err := sr.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), job); err != nil {
+				t.Fatalf("reporting failed: %v", err)
+			}
+			if !tc.useBlockKit {
+				if actual := fsc.messages["oncall"]; actual != tc.expectedText {
+					t.Errorf("expected message %q, got %q", tc.expectedText, actual)
+				}
+				return
+			}
+			if len(fsc.posts) != 1 {
+				t.Fatalf("expected 1 post, got %d", len(fsc.posts))
+			}
+			post := fsc.posts[0]
+			if post.text != tc.expectedText {
+				t.Errorf("expected fallback text %q, got %q", tc.expectedText, post.text)
+			}
+			var hasGroup bool
+			for _, block := range post.blocks {
+				if block.Type == "context" && cmp.Equal(block.Elements, []slackclient.BlockElement{slackclient.Markdown("Group: release-1.2")}) {
+					hasGroup = true
+				}
+			}
+			if hasGroup != tc.expectGroup {
+				t.Errorf("expected group context block: %t, got blocks %v", tc.expectGroup, post.blocks)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slack
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+)
+
+const (
+	// topicUpdateInterval is the minimum time between two updates of the
+	// topic of a channel. Slack rate limits conversations.setTopic, and
+	// every update posts a message to the channel.
+	topicUpdateInterval = time.Minute
+	// maxTopicLength is the maximum length of a channel topic.
+	maxTopicLength = 250
+)
+
+// topicThrottle sets the topics of channels at most once per interval. Topics
+// that are set more often are coalesced: once the interval passed, only the
+// last one is set.
+type topicThrottle struct {
+	lock     sync.Mutex
+	interval time.Duration
+	now      func() time.Time
+	after    func(time.Duration, func())
+	channels map[string]*topicState
+}
+
+type topicState struct {
+	// current is the topic that was set last.
+	current string
+	// last is when the topic was set last.
+	last time.Time
+	// pending is the topic to set once the interval passed.
+	pending   string
+	scheduled bool
+}
+
+func newTopicThrottle(interval time.Duration) *topicThrottle {
+	return &topicThrottle{
+		interval: interval,
+		now:      time.Now,
+		after:    func(d time.Duration, f func()) { time.AfterFunc(d, f) },
+		channels: map[string]*topicState{},
+	}
+}
+
+// update sets the topic of the channel identified by key through set, right
+// away if the topic wasn't set within the interval and otherwise once the
+// interval passed. Topics that don't change are not set again. Topics that
+// are set right away are set with ctx.
+func (t *topicThrottle) update(ctx context.Context, log *logrus.Entry, key, topic string, set func(ctx context.Context, topic string) error) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	state, ok := t.channels[key]
+	if !ok {
+		state = &topicState{}
+		t.channels[key] = state
+	}
+	if state.scheduled {
+		state.pending = topic
+		return nil
+	}
+	if state.current == topic {
+		return nil
+	}
+	now := t.now()
+	if wait := state.last.Add(t.interval).Sub(now); wait > 0 {
+		state.pending = topic
+		state.scheduled = true
+		t.after(wait, func() { t.flush(log, key, set) })
+		return nil
+	}
+	if err := set(ctx, topic); err != nil {
+		return err
+	}
+	state.current, state.last = topic, now
+	return nil
+}
+
+// flush sets the pending topic of the channel identified by key. It runs
+// after the report that scheduled it is done, so it doesn't use the context
+// of that report.
+func (t *topicThrottle) flush(log *logrus.Entry, key string, set func(ctx context.Context, topic string) error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	state := t.channels[key]
+	state.scheduled = false
+	topic := state.pending
+	state.pending = ""
+	if topic == state.current {
+		return
+	}
+	if err := set(context.Background(), topic); err != nil {
+		log.WithError(err).WithField("channel", key).Error("Failed to set Slack channel topic")
+		return
+	}
+	state.current, state.last = topic, t.now()
+}
+
+// tracksTopic returns whether the job is complete and its result is shown in
+// the topic of a channel.
+func tracksTopic(cfg *config.SlackReporter, pj *prowapi.ProwJob) bool {
+	return pj.Complete() && len(cfg.ChannelTopicsOf(pj.Spec.Job)) > 0
+}
+
+// updateTopics sets the topics of the channels that show the result of the
+// job.
+func (sr *slackReporter) updateTopics(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) error {
+	cfg, _ := sr.getConfig(pj)
+	if !tracksTopic(cfg, pj) {
+		return nil
+	}
+	host, _ := hostAndChannel(&cfg.SlackReporterConfig)
+	client := sr.clients[host]
+	var errs []error
+	for _, channel := range cfg.ChannelTopicsOf(pj.Spec.Job) {
+		topic, err := renderTopic(cfg.ChannelTopics[channel], pj)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("channel %s: %w", channel, err))
+			continue
+		}
+		if sr.dryRun {
+			log.WithField("channel", channel).WithField("topic", topic).Debug("Skipping setting the topic because dry-run is enabled")
+			continue
+		}
+		if err := sr.topics.update(ctx, log, host+"/"+channel, topic, func(ctx context.Context, topic string) error {
+			// conversations.setTopic only accepts channel IDs.
+			id, err := sr.channels.resolve(ctx, host, client, channel)
+			if err != nil {
+				return err
+			}
+			err = client.SetTopic(ctx, id, topic)
+			sr.channels.invalidateOnNotFound(host, err)
+			return err
+		}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func renderTopic(cfg config.SlackChannelTopic, pj *prowapi.ProwJob) (string, error) {
+	tmpl, err := template.New("").Parse(cfg.TemplateOrDefault())
+	if err != nil {
+		return "", fmt.Errorf("failed to parse topic template: %w", err)
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, pj); err != nil {
+		return "", fmt.Errorf("failed to render topic template: %w", err)
+	}
+	return truncate(b.String(), maxTopicLength), nil
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slack
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+)
+
+func TestTopicThrottle(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var scheduled []func()
+	var waits []time.Duration
+	throttle := newTopicThrottle(time.Minute)
+	throttle.now = func() time.Time { return now }
+	throttle.after = func(d time.Duration, f func()) {
+		waits = append(waits, d)
+		scheduled = append(scheduled, f)
+	}
+	var set []string
+	setTopic := func(_ context.Context, topic string) error {
+		set = append(set, topic)
+		return nil
+	}
+	log := logrus.WithField("test", t.Name())
+	update := func(topic string) {
+		t.Helper()
+		if err := throttle.update(context.Background(), log, "host/channel", topic, setTopic); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+	}
+
+	update("passing")
+	if diff := cmp.Diff([]string{"passing"}, set); diff != "" {
+		t.Fatalf("first topic should be set right away (-want +got):\n%s", diff)
+	}
+
+	now = now.Add(10 * time.Second)
+	update("failing")
+	update("still failing")
+	if len(set) != 1 {
+		t.Errorf("topics within the interval should not be set right away, got %v", set)
+	}
+	if diff := cmp.Diff([]time.Duration{50 * time.Second}, waits); diff != "" {
+		t.Fatalf("expected a single update to be scheduled once the interval passed (-want +got):\n%s", diff)
+	}
+
+	now = now.Add(50 * time.Second)
+	scheduled[0]()
+	if diff := cmp.Diff([]string{"passing", "still failing"}, set); diff != "" {
+		t.Errorf("only the last topic should be set once the interval passed (-want +got):\n%s", diff)
+	}
+
+	now = now.Add(time.Hour)
+	update("still failing")
+	if len(set) != 2 {
+		t.Errorf("unchanged topic should not be set again, got %v", set)
+	}
+}
+
+func TestReportChannelTopics(t *testing.T) {
+	testCases := []struct {
+		name             string
+		job              string
+		state            v1.ProwJobState
+		template         string
+		dryRun           bool
+		expectedTopics   map[string][]string
+		expectedMessages map[string]string
+	}{
+		{
+			name:           "successful tracked job sets topic without message",
+			job:            "periodic-main",
+			state:          v1.SuccessState,
+			expectedTopics: map[string][]string{"C_STATUS": {":white_check_mark: periodic-main: success"}},
+		},
+		{
+			name:             "failed tracked job sets topic and posts message",
+			job:              "periodic-main",
+			state:            v1.FailureState,
+			expectedTopics:   map[string][]string{"C_STATUS": {":x: periodic-main: failure"}},
+			expectedMessages: map[string]string{"oncall": "periodic-main failed"},
+		},
+		{
+			name:           "aborted tracked job sets neutral topic",
+			job:            "periodic-main",
+			state:          v1.AbortedState,
+			expectedTopics: map[string][]string{"C_STATUS": {":white_circle: periodic-main: aborted"}},
+		},
+		{
+			name:           "custom template",
+			job:            "periodic-main",
+			state:          v1.SuccessState,
+			template:       `main: {{if eq .Status.State "success"}}✅ passing{{else}}❌ failing{{end}}`,
+			expectedTopics: map[string][]string{"C_STATUS": {"main: ✅ passing"}},
+		},
+		{
+			name:             "untracked job doesn't set topic",
+			job:              "periodic-other",
+			state:            v1.FailureState,
+			expectedMessages: map[string]string{"oncall": "periodic-other failed"},
+		},
+		{
+			name:   "dry run",
+			job:    "periodic-main",
+			state:  v1.SuccessState,
+			dryRun: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config.SlackReporter{
+				JobTypesToReport: []v1.ProwJobType{v1.PeriodicJob},
+				ChannelTopics: map[string]config.SlackChannelTopic{
+					"status": {Jobs: []string{"periodic-main"}, Template: tc.template},
+				},
+				SlackReporterConfig: v1.SlackReporterConfig{
+					Channel:           "oncall",
+					JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+					ReportTemplate:    "{{.Spec.Job}} failed",
+				},
+			}
+			fsc := &fakeSlackClient{channels: map[string]string{"status": "C_STATUS", "C_STATUS": "C_STATUS"}}
+			sr := New(func(*v1.Refs) config.SlackReporter { return cfg }, nil, tc.dryRun, nil, nil, DefaultChannelCacheTTL, nil)
+			sr.clients = map[string]slackClient{DefaultHostName: fsc}
+			pj := &v1.ProwJob{
+				Spec: v1.ProwJobSpec{Job: tc.job, Type: v1.PeriodicJob},
+				Status: v1.ProwJobStatus{
+					State:          tc.state,
+					CompletionTime: &metav1.Time{},
+				},
+			}
+
+			log := logrus.WithField("test", tc.name)
+			if !sr.ShouldReport(context.Background(), log, pj) {
+				if tc.expectedTopics != nil || tc.expectedMessages != nil {
+					t.Fatal("expected job to be reported")
+				}
+				return
+			}
+			if _, _, err := sr.Report(context.Background(), log, pj); err != nil {
+				t.Fatalf("Report: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedTopics, fsc.topics); diff != "" {
+				t.Errorf("topics differ from expected (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedMessages, fsc.messages); diff != "" {
+				t.Errorf("messages differ from expected (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReportResolvesTopicChannelOnce(t *testing.T) {
+	cfg := config.SlackReporter{
+		ChannelTopics: map[string]config.SlackChannelTopic{
+			"#status": {Jobs: []string{"periodic-main", "periodic-release"}},
+		},
+	}
+	fsc := &fakeSlackClient{channels: map[string]string{"status": "C_STATUS", "C_STATUS": "C_STATUS"}}
+	sr := New(func(*v1.Refs) config.SlackReporter { return cfg }, nil, false, nil, nil, DefaultChannelCacheTTL, nil)
+	sr.clients = map[string]slackClient{DefaultHostName: fsc}
+	sr.topics = newTopicThrottle(0)
+
+	log := logrus.WithField("test", t.Name())
+	for _, job := range []string{"periodic-main", "periodic-release"} {
+		pj := &v1.ProwJob{
+			Spec: v1.ProwJobSpec{Job: job, Type: v1.PeriodicJob},
+			Status: v1.ProwJobStatus{
+				State:          v1.SuccessState,
+				CompletionTime: &metav1.Time{},
+			},
+		}
+		if _, _, err := sr.Report(context.Background(), log, pj); err != nil {
+			t.Fatalf("Report: %v", err)
+		}
+	}
+
+	expected := map[string][]string{"C_STATUS": {":white_check_mark: periodic-main: success", ":white_check_mark: periodic-release: success"}}
+	if diff := cmp.Diff(expected, fsc.topics); diff != "" {
+		t.Errorf("topics differ from expected (-want +got):\n%s", diff)
+	}
+	if fsc.channelLookups != 1 {
+		t.Errorf("expected the channel ID to be looked up once and then cached, got %d lookups", fsc.channelLookups)
+	}
+}
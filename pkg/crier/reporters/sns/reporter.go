@@ -0,0 +1,213 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sns contains a reporter that publishes prowjob statuses to Amazon
+// SNS topics.
+package sns
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/crier/reporters/criercommonlib"
+	pubsubreporter "sigs.k8s.io/prow/pkg/crier/reporters/pubsub"
+)
+
+const (
+	reporterName = "snsreporter"
+
+	// SNSRunIDAnnotation is a user assigned ID of the run, it is passed
+	// through as the `runid` of the message.
+	SNSRunIDAnnotation = "prow.k8s.io/sns.runID"
+
+	// Message attributes set on every message, so that subscriptions can
+	// filter on them.
+	JobNameAttribute  = "job_name"
+	JobTypeAttribute  = "job_type"
+	JobStateAttribute = "state"
+)
+
+type snsClient interface {
+	Publish(ctx context.Context, params *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error)
+	GetTopicAttributes(ctx context.Context, params *sns.GetTopicAttributesInput, optFns ...func(*sns.Options)) (*sns.GetTopicAttributesOutput, error)
+}
+
+type snsReporter struct {
+	config    func(*prowapi.Refs) config.SNSReporter
+	prowCfg   config.Getter
+	dryRun    bool
+	clientFor func(ctx context.Context, region string) (snsClient, error)
+}
+
+func (sr *snsReporter) getConfig(pj *prowapi.ProwJob) config.SNSReporter {
+	refs := pj.Spec.Refs
+	if refs == nil && len(pj.Spec.ExtraRefs) > 0 {
+		refs = &pj.Spec.ExtraRefs[0]
+	}
+	return sr.config(refs)
+}
+
+func (sr *snsReporter) Report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) ([]*prowapi.ProwJob, *reconcile.Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	return []*prowapi.ProwJob{pj}, nil, sr.report(ctx, log, pj)
+}
+
+func (sr *snsReporter) report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) error {
+	cfg := sr.getConfig(pj)
+	region, err := config.SNSTopicRegion(cfg.TopicARN)
+	if err != nil {
+		return err
+	}
+
+	// The message has the same schema as the one of the Pub/Sub reporter,
+	// with the topic ARN in place of the topic.
+	message := pubsubreporter.NewReportMessage(sr.prowCfg, pj, "", cfg.TopicARN, pj.Annotations[SNSRunIDAnnotation])
+	b, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("could not marshal sns report: %w", err)
+	}
+
+	log = log.WithField("topic", cfg.TopicARN)
+	if sr.dryRun {
+		log.WithField("message", string(b)).Debug("Skipping reporting because dry-run is enabled")
+		return nil
+	}
+
+	client, err := sr.clientFor(ctx, region)
+	if err != nil {
+		return fmt.Errorf("could not create sns client: %w", err)
+	}
+	if _, err := client.Publish(ctx, &sns.PublishInput{
+		TopicArn: aws.String(cfg.TopicARN),
+		Message:  aws.String(string(b)),
+		MessageAttributes: map[string]types.MessageAttributeValue{
+			JobNameAttribute:  stringAttribute(pj.Spec.Job),
+			JobTypeAttribute:  stringAttribute(string(pj.Spec.Type)),
+			JobStateAttribute: stringAttribute(string(pj.Status.State)),
+		},
+	}); err != nil {
+		err = fmt.Errorf("failed to publish sns message to topic %q: %w", cfg.TopicARN, err)
+		// A topic that doesn't exist or can't be published to is a
+		// configuration problem.
+		var notFound *types.NotFoundException
+		var authErr *types.AuthorizationErrorException
+		if errors.As(err, &notFound) || errors.As(err, &authErr) {
+			return criercommonlib.UserError(err)
+		}
+		return err
+	}
+	return nil
+}
+
+func stringAttribute(value string) types.MessageAttributeValue {
+	return types.MessageAttributeValue{
+		DataType:    aws.String("String"),
+		StringValue: aws.String(value),
+	}
+}
+
+func (sr *snsReporter) GetName() string {
+	return reporterName
+}
+
+func (sr *snsReporter) ShouldReport(_ context.Context, logger *logrus.Entry, pj *prowapi.ProwJob) bool {
+	cfg := sr.getConfig(pj)
+	if cfg.TopicARN == "" {
+		return false
+	}
+
+	shouldReport := criercommonlib.ShouldReportJob(pj, cfg.JobTypesToReport, cfg.JobStatesToReport)
+	logger.WithField("reporting", shouldReport).Debug("Determined should report")
+	return shouldReport
+}
+
+// Validate checks that the topics of the configs of all orgs and repos
+// exist and can be read with crier's credentials.
+func (sr *snsReporter) Validate(ctx context.Context) error {
+	keys := sets.KeySet(sr.prowCfg().SNSReporterConfigs)
+	topics := sets.New[string]()
+	for _, refs := range criercommonlib.RefsForConfigKeys(sets.List(keys)) {
+		if topic := sr.config(refs).TopicARN; topic != "" {
+			topics.Insert(topic)
+		}
+	}
+
+	var errs []error
+	for _, topic := range sets.List(topics) {
+		region, err := config.SNSTopicRegion(topic)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		client, err := sr.clientFor(ctx, region)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("could not create sns client: %w", err))
+			continue
+		}
+		if _, err := client.GetTopicAttributes(ctx, &sns.GetTopicAttributesInput{TopicArn: aws.String(topic)}); err != nil {
+			errs = append(errs, fmt.Errorf("topic %q: %w", topic, err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// clientCache creates one SNS client per region. Credentials are loaded
+// through the default AWS credential chain.
+type clientCache struct {
+	lock    sync.Mutex
+	clients map[string]snsClient
+}
+
+func (c *clientCache) clientFor(ctx context.Context, region string) (snsClient, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if client, ok := c.clients[region]; ok {
+		return client, nil
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	client := sns.NewFromConfig(awsCfg)
+	c.clients[region] = client
+	return client, nil
+}
+
+func New(cfg func(refs *prowapi.Refs) config.SNSReporter, prowCfg config.Getter, dryRun bool) *snsReporter {
+	cache := &clientCache{clients: map[string]snsClient{}}
+	return &snsReporter{
+		config:    cfg,
+		prowCfg:   prowCfg,
+		dryRun:    dryRun,
+		clientFor: cache.clientFor,
+	}
+}
@@ -0,0 +1,298 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sns
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/crier/reporters/criercommonlib"
+	pubsubreporter "sigs.k8s.io/prow/pkg/crier/reporters/pubsub"
+)
+
+func TestShouldReport(t *testing.T) {
+	testCases := []struct {
+		name     string
+		config   config.SNSReporter
+		pj       *v1.ProwJob
+		expected bool
+	}{
+		{
+			name: "matching type and state should report",
+			config: config.SNSReporter{
+				JobTypesToReport:  []v1.ProwJobType{v1.PostsubmitJob},
+				JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+				TopicARN:          "arn:aws:sns:us-east-1:123456789012:prow",
+			},
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PostsubmitJob},
+				Status: v1.ProwJobStatus{State: v1.FailureState},
+			},
+			expected: true,
+		},
+		{
+			name: "wrong job type should not report",
+			config: config.SNSReporter{
+				JobTypesToReport:  []v1.ProwJobType{v1.PostsubmitJob},
+				JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+				TopicARN:          "arn:aws:sns:us-east-1:123456789012:prow",
+			},
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PresubmitJob},
+				Status: v1.ProwJobStatus{State: v1.FailureState},
+			},
+			expected: false,
+		},
+		{
+			name: "wrong state should not report",
+			config: config.SNSReporter{
+				JobTypesToReport:  []v1.ProwJobType{v1.PostsubmitJob},
+				JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+				TopicARN:          "arn:aws:sns:us-east-1:123456789012:prow",
+			},
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PostsubmitJob},
+				Status: v1.ProwJobStatus{State: v1.SuccessState},
+			},
+			expected: false,
+		},
+		{
+			name: "no topic should not report",
+			config: config.SNSReporter{
+				JobTypesToReport:  []v1.ProwJobType{v1.PostsubmitJob},
+				JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+			},
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PostsubmitJob},
+				Status: v1.ProwJobStatus{State: v1.FailureState},
+			},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reporter := &snsReporter{
+				config: func(*v1.Refs) config.SNSReporter { return tc.config },
+			}
+			if result := reporter.ShouldReport(context.Background(), logrus.NewEntry(logrus.StandardLogger()), tc.pj); result != tc.expected {
+				t.Errorf("expected result to be %t but was %t", tc.expected, result)
+			}
+		})
+	}
+}
+
+type fakeSNSClient struct {
+	region    string
+	published []*sns.PublishInput
+	err       error
+	// topics are the ARNs of the topics that exist.
+	topics sets.Set[string]
+}
+
+func (fsc *fakeSNSClient) Publish(_ context.Context, params *sns.PublishInput, _ ...func(*sns.Options)) (*sns.PublishOutput, error) {
+	if fsc.err != nil {
+		return nil, fsc.err
+	}
+	fsc.published = append(fsc.published, params)
+	return &sns.PublishOutput{}, nil
+}
+
+func (fsc *fakeSNSClient) GetTopicAttributes(_ context.Context, params *sns.GetTopicAttributesInput, _ ...func(*sns.Options)) (*sns.GetTopicAttributesOutput, error) {
+	if !fsc.topics.Has(*params.TopicArn) {
+		return nil, &types.NotFoundException{Message: aws.String("Topic does not exist")}
+	}
+	return &sns.GetTopicAttributesOutput{}, nil
+}
+
+var _ snsClient = &fakeSNSClient{}
+
+type fca struct {
+	c config.Config
+}
+
+func (ca fca) Config() *config.Config {
+	return &ca.c
+}
+
+func TestReport(t *testing.T) {
+	const topic = "arn:aws:sns:eu-west-1:123456789012:prow"
+	pj := &v1.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "abc",
+			Annotations: map[string]string{SNSRunIDAnnotation: "run-1"},
+		},
+		Spec: v1.ProwJobSpec{
+			Job:  "my-job",
+			Type: v1.PostsubmitJob,
+			Refs: &v1.Refs{Org: "org", Repo: "repo"},
+		},
+		Status: v1.ProwJobStatus{
+			State:       v1.FailureState,
+			Description: "Job failed.",
+		},
+	}
+
+	testCases := []struct {
+		name              string
+		dryRun            bool
+		publishErr        error
+		expectedPublished bool
+		expectedUserErr   bool
+	}{
+		{
+			name:              "message is published with attributes",
+			expectedPublished: true,
+		},
+		{
+			name:   "dry-run does not publish",
+			dryRun: true,
+		},
+		{
+			name:            "missing topic is a user error",
+			publishErr:      &types.NotFoundException{Message: aws.String("Topic does not exist")},
+			expectedUserErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fsc := &fakeSNSClient{err: tc.publishErr}
+			reporter := &snsReporter{
+				config: func(*v1.Refs) config.SNSReporter {
+					return config.SNSReporter{TopicARN: topic}
+				},
+				prowCfg: fca{}.Config,
+				dryRun:  tc.dryRun,
+				clientFor: func(_ context.Context, region string) (snsClient, error) {
+					fsc.region = region
+					return fsc, nil
+				},
+			}
+
+			_, _, err := reporter.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj)
+			if tc.publishErr != nil {
+				if err == nil {
+					t.Fatalf("expected publish error, got %v", err)
+				}
+				if criercommonlib.IsUserError(err) != tc.expectedUserErr {
+					t.Errorf("expected user error to be %t, got %v", tc.expectedUserErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("reporting failed: %v", err)
+			}
+			if !tc.expectedPublished {
+				if len(fsc.published) != 0 {
+					t.Errorf("expected nothing to be published, got %d messages", len(fsc.published))
+				}
+				return
+			}
+
+			if len(fsc.published) != 1 {
+				t.Fatalf("expected one message to be published, got %d", len(fsc.published))
+			}
+			if fsc.region != "eu-west-1" {
+				t.Errorf("expected client for region eu-west-1, got %q", fsc.region)
+			}
+			input := fsc.published[0]
+			if aws.ToString(input.TopicArn) != topic {
+				t.Errorf("expected topic %q, got %q", topic, aws.ToString(input.TopicArn))
+			}
+			attributes := map[string]string{}
+			for name, value := range input.MessageAttributes {
+				attributes[name] = aws.ToString(value.StringValue)
+			}
+			expectedAttributes := map[string]string{"job_name": "my-job", "job_type": "postsubmit", "state": "failure"}
+			if diff := cmp.Diff(expectedAttributes, attributes); diff != "" {
+				t.Errorf("attributes differ from expected: %s", diff)
+			}
+
+			var message pubsubreporter.ReportMessage
+			if err := json.Unmarshal([]byte(aws.ToString(input.Message)), &message); err != nil {
+				t.Fatalf("failed to unmarshal message: %v", err)
+			}
+			expectedMessage := pubsubreporter.ReportMessage{
+				Topic:   topic,
+				RunID:   "run-1",
+				Status:  v1.FailureState,
+				Refs:    []v1.Refs{{Org: "org", Repo: "repo"}},
+				JobType: v1.PostsubmitJob,
+				JobName: "my-job",
+				Message: "Job failed.",
+			}
+			if diff := cmp.Diff(expectedMessage, message); diff != "" {
+				t.Errorf("message differs from expected: %s", diff)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	configs := config.SNSReporterConfigs{
+		"*":        {TopicARN: "arn:aws:sns:eu-west-1:123456789012:prow"},
+		"org":      {TopicARN: "arn:aws:sns:us-east-1:123456789012:org"},
+		"org/repo": {TopicARN: "arn:aws:sns:eu-west-1:123456789012:prow"},
+	}
+	testCases := []struct {
+		name        string
+		topics      sets.Set[string]
+		expectedErr bool
+	}{
+		{
+			name:   "all topics exist",
+			topics: sets.New("arn:aws:sns:eu-west-1:123456789012:prow", "arn:aws:sns:us-east-1:123456789012:org"),
+		},
+		{
+			name:        "missing topic fails",
+			topics:      sets.New("arn:aws:sns:eu-west-1:123456789012:prow"),
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var regions []string
+			reporter := &snsReporter{
+				config:  configs.GetSNSReporter,
+				prowCfg: fca{c: config.Config{ProwConfig: config.ProwConfig{SNSReporterConfigs: configs}}}.Config,
+				clientFor: func(_ context.Context, region string) (snsClient, error) {
+					regions = append(regions, region)
+					return &fakeSNSClient{topics: tc.topics}, nil
+				},
+			}
+			if err := reporter.Validate(context.Background()); (err != nil) != tc.expectedErr {
+				t.Errorf("expected error: %t, got: %v", tc.expectedErr, err)
+			}
+			if diff := cmp.Diff([]string{"eu-west-1", "us-east-1"}, regions); diff != "" {
+				t.Errorf("every topic should be checked once in its region (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sql contains a reporter that records every completed prowjob as a
+// row of a SQL database table, e.g. for long-term analytics.
+package sql
+
+import (
+	"context"
+	sqldb "database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+)
+
+const reporterName = "sqlreporter"
+
+// columns are the columns of the table written, in the order of the
+// arguments of the upsert statement. The name of the ProwJob identifies
+// the row, so reporting a job again updates its row instead of adding one.
+var columns = []string{"name", "job", "type", "org", "repo", "state", "start_time", "completion_time", "url"}
+
+// dialect renders the upsert statement for the SQL flavor of a driver.
+type dialect func(table string) string
+
+// postgres upserts with ON CONFLICT, which requires a unique constraint on
+// the name column.
+func postgres(table string) string {
+	placeholders := make([]string, len(columns))
+	updates := make([]string, 0, len(columns)-1)
+	for i, column := range columns {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		if column != "name" {
+			updates = append(updates, fmt.Sprintf("%s = EXCLUDED.%s", column, column))
+		}
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (name) DO UPDATE SET %s",
+		table, strings.Join(columns, ", "), strings.Join(placeholders, ", "), strings.Join(updates, ", "))
+}
+
+// mysql upserts with ON DUPLICATE KEY UPDATE, which requires a unique key
+// on the name column.
+func mysql(table string) string {
+	placeholders := make([]string, len(columns))
+	updates := make([]string, 0, len(columns)-1)
+	for i, column := range columns {
+		placeholders[i] = "?"
+		if column != "name" {
+			updates = append(updates, fmt.Sprintf("%s = VALUES(%s)", column, column))
+		}
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON DUPLICATE KEY UPDATE %s",
+		table, strings.Join(columns, ", "), strings.Join(placeholders, ", "), strings.Join(updates, ", "))
+}
+
+// dialects are the SQL flavors, keyed by the name their drivers register
+// with database/sql.
+var dialects = map[string]dialect{
+	"postgres": postgres,
+	"mysql":    mysql,
+}
+
+// SupportedDrivers returns the names of the database/sql drivers the
+// reporter can write with. Only drivers that are registered with
+// database/sql, i.e. linked into the binary, are supported.
+func SupportedDrivers() []string {
+	var drivers []string
+	for _, driver := range sqldb.Drivers() {
+		if _, ok := dialects[driver]; ok {
+			drivers = append(drivers, driver)
+		}
+	}
+	sort.Strings(drivers)
+	return drivers
+}
+
+type sqlReporter struct {
+	db      *sqldb.DB
+	dialect dialect
+	config  func() *config.SQLReporter
+	dryRun  bool
+
+	lock sync.Mutex
+	// stmts are the prepared upsert statements, keyed by table, so that
+	// changing the table in the config doesn't require a restart.
+	stmts map[string]*sqldb.Stmt
+}
+
+// New returns a reporter that upserts the completed jobs into the
+// configured table of the database. The driver is the name the driver of
+// db is registered with, and must be one of SupportedDrivers.
+func New(db *sqldb.DB, driver string, cfg func() *config.SQLReporter, dryRun bool) (*sqlReporter, error) {
+	d, ok := dialects[driver]
+	if !ok {
+		return nil, fmt.Errorf("unsupported driver %q, must be one of %s", driver, strings.Join(SupportedDrivers(), ", "))
+	}
+	return &sqlReporter{
+		db:      db,
+		dialect: d,
+		config:  cfg,
+		dryRun:  dryRun,
+		stmts:   map[string]*sqldb.Stmt{},
+	}, nil
+}
+
+func (sr *sqlReporter) GetName() string {
+	return reporterName
+}
+
+func (sr *sqlReporter) ShouldReport(_ context.Context, _ *logrus.Entry, pj *prowapi.ProwJob) bool {
+	return pj.Complete()
+}
+
+func (sr *sqlReporter) Report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) ([]*prowapi.ProwJob, *reconcile.Result, error) {
+	return []*prowapi.ProwJob{pj}, nil, sr.report(ctx, log, pj)
+}
+
+func (sr *sqlReporter) report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) error {
+	table := sr.config().TableName()
+	log = log.WithField("table", table)
+	if sr.dryRun {
+		log.WithField("state", pj.Status.State).Debug("Skipping reporting because dry-run is enabled")
+		return nil
+	}
+
+	stmt, err := sr.stmt(ctx, table)
+	if err != nil {
+		return err
+	}
+	if _, err := stmt.ExecContext(ctx, row(pj)...); err != nil {
+		return fmt.Errorf("failed to upsert job %q into %s: %w", pj.Name, table, err)
+	}
+	return nil
+}
+
+// stmt returns the prepared upsert statement for the table, preparing it on
+// first use.
+func (sr *sqlReporter) stmt(ctx context.Context, table string) (*sqldb.Stmt, error) {
+	sr.lock.Lock()
+	defer sr.lock.Unlock()
+	if stmt, ok := sr.stmts[table]; ok {
+		return stmt, nil
+	}
+	stmt, err := sr.db.PrepareContext(ctx, sr.dialect(table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare upsert into %s: %w", table, err)
+	}
+	sr.stmts[table] = stmt
+	return stmt, nil
+}
+
+// CheckConnectivity pings the database.
+func (sr *sqlReporter) CheckConnectivity(ctx context.Context) error {
+	if err := sr.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	return nil
+}
+
+// Validate checks that the database can be reached and that the upsert
+// into the configured table can be prepared.
+func (sr *sqlReporter) Validate(ctx context.Context) error {
+	if err := sr.CheckConnectivity(ctx); err != nil {
+		return err
+	}
+	_, err := sr.stmt(ctx, sr.config().TableName())
+	return err
+}
+
+// row returns the values of columns for the job.
+func row(pj *prowapi.ProwJob) []any {
+	var org, repo string
+	refs := pj.Spec.Refs
+	if refs == nil && len(pj.Spec.ExtraRefs) > 0 {
+		refs = &pj.Spec.ExtraRefs[0]
+	}
+	if refs != nil {
+		org, repo = refs.Org, refs.Repo
+	}
+	var completionTime time.Time
+	if pj.Status.CompletionTime != nil {
+		completionTime = pj.Status.CompletionTime.Time
+	}
+	return []any{
+		pj.Name,
+		pj.Spec.Job,
+		string(pj.Spec.Type),
+		org,
+		repo,
+		string(pj.Status.State),
+		pj.Status.StartTime.Time,
+		completionTime,
+		pj.Status.URL,
+	}
+}
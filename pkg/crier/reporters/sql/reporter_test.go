@@ -0,0 +1,239 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sql
+
+import (
+	"context"
+	sqldb "database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+)
+
+// fakeDB is a database/sql driver that keeps the upserted rows in memory,
+// keyed by their first value.
+type fakeDB struct {
+	lock     sync.Mutex
+	prepared []string
+	rows     map[string][]driver.Value
+	execErr  error
+	pingErr  error
+}
+
+func newFakeDB() *fakeDB {
+	return &fakeDB{rows: map[string][]driver.Value{}}
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: f}, nil }
+func (f *fakeDB) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	c.db.lock.Lock()
+	defer c.db.lock.Unlock()
+	c.db.prepared = append(c.db.prepared, query)
+	return &fakeStmt{db: c.db}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+func (c *fakeConn) Ping(context.Context) error {
+	return c.db.pingErr
+}
+
+type fakeStmt struct {
+	db *fakeDB
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return len(columns) }
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.db.lock.Lock()
+	defer s.db.lock.Unlock()
+	if s.db.execErr != nil {
+		return nil, s.db.execErr
+	}
+	s.db.rows[args[0].(string)] = args
+	return driver.RowsAffected(1), nil
+}
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.New("queries are not supported")
+}
+
+func newReporter(t *testing.T, fake *fakeDB, driverName string, cfg *config.SQLReporter, dryRun bool) *sqlReporter {
+	db := sqldb.OpenDB(fake)
+	t.Cleanup(func() { db.Close() })
+	reporter, err := New(db, driverName, func() *config.SQLReporter { return cfg }, dryRun)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return reporter
+}
+
+func TestReport(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	completed := func(name, job string, state prowapi.ProwJobState, refs *prowapi.Refs) *prowapi.ProwJob {
+		return &prowapi.ProwJob{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       prowapi.ProwJobSpec{Job: job, Type: prowapi.PresubmitJob, Refs: refs},
+			Status: prowapi.ProwJobStatus{
+				State:          state,
+				StartTime:      metav1.NewTime(start),
+				CompletionTime: &metav1.Time{Time: start.Add(time.Minute)},
+				URL:            "https://prow.example.com/view/" + name,
+			},
+		}
+	}
+
+	testCases := []struct {
+		name             string
+		driver           string
+		cfg              *config.SQLReporter
+		dryRun           bool
+		jobs             []*prowapi.ProwJob
+		expectedPrepared []string
+		expectedRows     map[string][]driver.Value
+	}{
+		{
+			name:   "postgres",
+			driver: "postgres",
+			jobs:   []*prowapi.ProwJob{completed("a", "pull-unit", prowapi.FailureState, &prowapi.Refs{Org: "org", Repo: "repo"})},
+			expectedPrepared: []string{
+				"INSERT INTO prowjobs (name, job, type, org, repo, state, start_time, completion_time, url) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) ON CONFLICT (name) DO UPDATE SET job = EXCLUDED.job, type = EXCLUDED.type, org = EXCLUDED.org, repo = EXCLUDED.repo, state = EXCLUDED.state, start_time = EXCLUDED.start_time, completion_time = EXCLUDED.completion_time, url = EXCLUDED.url",
+			},
+			expectedRows: map[string][]driver.Value{
+				"a": {"a", "pull-unit", "presubmit", "org", "repo", "failure", start, start.Add(time.Minute), "https://prow.example.com/view/a"},
+			},
+		},
+		{
+			name:   "mysql with configured table",
+			driver: "mysql",
+			cfg:    &config.SQLReporter{Table: "ci.jobs"},
+			jobs:   []*prowapi.ProwJob{completed("a", "pull-unit", prowapi.SuccessState, nil)},
+			expectedPrepared: []string{
+				"INSERT INTO ci.jobs (name, job, type, org, repo, state, start_time, completion_time, url) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE job = VALUES(job), type = VALUES(type), org = VALUES(org), repo = VALUES(repo), state = VALUES(state), start_time = VALUES(start_time), completion_time = VALUES(completion_time), url = VALUES(url)",
+			},
+			expectedRows: map[string][]driver.Value{
+				"a": {"a", "pull-unit", "presubmit", "", "", "success", start, start.Add(time.Minute), "https://prow.example.com/view/a"},
+			},
+		},
+		{
+			name:   "reporting a job again updates its row and reuses the statement",
+			driver: "postgres",
+			jobs: []*prowapi.ProwJob{
+				completed("a", "pull-unit", prowapi.FailureState, &prowapi.Refs{Org: "org", Repo: "repo"}),
+				completed("b", "pull-unit", prowapi.SuccessState, &prowapi.Refs{Org: "org", Repo: "repo"}),
+				completed("a", "pull-unit", prowapi.AbortedState, &prowapi.Refs{Org: "org", Repo: "repo"}),
+			},
+			expectedRows: map[string][]driver.Value{
+				"a": {"a", "pull-unit", "presubmit", "org", "repo", "aborted", start, start.Add(time.Minute), "https://prow.example.com/view/a"},
+				"b": {"b", "pull-unit", "presubmit", "org", "repo", "success", start, start.Add(time.Minute), "https://prow.example.com/view/b"},
+			},
+		},
+		{
+			name:         "dry run",
+			driver:       "postgres",
+			dryRun:       true,
+			jobs:         []*prowapi.ProwJob{completed("a", "pull-unit", prowapi.SuccessState, nil)},
+			expectedRows: map[string][]driver.Value{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := newFakeDB()
+			reporter := newReporter(t, fake, tc.driver, tc.cfg, tc.dryRun)
+			for _, pj := range tc.jobs {
+				if _, _, err := reporter.Report(context.Background(), logrus.WithField("test", tc.name), pj); err != nil {
+					t.Fatalf("Report: %v", err)
+				}
+			}
+			if !tc.dryRun && len(fake.prepared) != 1 {
+				t.Errorf("expected the statement to be prepared once, got %d times", len(fake.prepared))
+			}
+			if tc.expectedPrepared != nil {
+				if diff := cmp.Diff(tc.expectedPrepared, fake.prepared); diff != "" {
+					t.Errorf("prepared statements differ from expected (-want +got):\n%s", diff)
+				}
+			}
+			if diff := cmp.Diff(tc.expectedRows, fake.rows); diff != "" {
+				t.Errorf("rows differ from expected (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReportError(t *testing.T) {
+	fake := newFakeDB()
+	fake.execErr = errors.New("connection reset")
+	pj := &prowapi.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "a"},
+		Status:     prowapi.ProwJobStatus{State: prowapi.SuccessState, CompletionTime: &metav1.Time{}},
+	}
+	if _, _, err := newReporter(t, fake, "postgres", nil, false).Report(context.Background(), logrus.NewEntry(logrus.New()), pj); err == nil {
+		t.Error("expected an error when the upsert fails")
+	}
+}
+
+func TestNewUnsupportedDriver(t *testing.T) {
+	if _, err := New(sqldb.OpenDB(newFakeDB()), "sqlite3", func() *config.SQLReporter { return nil }, false); err == nil {
+		t.Error("expected an error for an unsupported driver")
+	}
+}
+
+func TestShouldReport(t *testing.T) {
+	reporter := newReporter(t, newFakeDB(), "postgres", nil, false)
+	log := logrus.NewEntry(logrus.New())
+	if reporter.ShouldReport(context.Background(), log, &prowapi.ProwJob{Status: prowapi.ProwJobStatus{State: prowapi.PendingState}}) {
+		t.Error("expected pending jobs not to be reported")
+	}
+	if !reporter.ShouldReport(context.Background(), log, &prowapi.ProwJob{Status: prowapi.ProwJobStatus{State: prowapi.SuccessState, CompletionTime: &metav1.Time{}}}) {
+		t.Error("expected completed jobs to be reported")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	fake := newFakeDB()
+	reporter := newReporter(t, fake, "postgres", &config.SQLReporter{Table: "jobs"}, false)
+	if err := reporter.Validate(context.Background()); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+	if len(fake.prepared) != 1 {
+		t.Errorf("expected Validate to prepare the upsert, got %d prepared statements", len(fake.prepared))
+	}
+
+	fake.pingErr = errors.New("connection refused")
+	if err := reporter.Validate(context.Background()); err == nil {
+		t.Error("expected an error when the database can't be reached")
+	}
+}
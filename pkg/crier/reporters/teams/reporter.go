@@ -0,0 +1,131 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package teams
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"text/template"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/crier/reporters/criercommonlib"
+	teamsclient "sigs.k8s.io/prow/pkg/teams"
+)
+
+const (
+	reporterName = "teamsreporter"
+)
+
+// themeColor returns the color of the bar shown on the left of the
+// MessageCard of a job in the given state. Jobs that ended without failing
+// but didn't succeed, e.g. aborted ones, are gray.
+func themeColor(state prowapi.ProwJobState) string {
+	switch {
+	case state == prowapi.SuccessState:
+		return "2EB886"
+	case criercommonlib.IsTerminalNonFailure(state):
+		return "808080"
+	case state == prowapi.FailureState || state == prowapi.ErrorState:
+		return "A30200"
+	default:
+		return "DAA038"
+	}
+}
+
+type teamsClient interface {
+	WriteMessage(ctx context.Context, card *teamsclient.MessageCard, channel string) error
+}
+
+type teamsReporter struct {
+	client teamsClient
+	config func(*prowapi.Refs) config.TeamsReporter
+	dryRun bool
+}
+
+func (tr *teamsReporter) getConfig(pj *prowapi.ProwJob) config.TeamsReporter {
+	refs := pj.Spec.Refs
+	if refs == nil && len(pj.Spec.ExtraRefs) > 0 {
+		refs = &pj.Spec.ExtraRefs[0]
+	}
+	return tr.config(refs)
+}
+
+func (tr *teamsReporter) Report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) ([]*prowapi.ProwJob, *reconcile.Result, error) {
+	return []*prowapi.ProwJob{pj}, nil, tr.report(ctx, log, pj)
+}
+
+func (tr *teamsReporter) report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) error {
+	cfg := tr.getConfig(pj)
+
+	b := &bytes.Buffer{}
+	tmpl, err := template.New("").Parse(cfg.ReportTemplate)
+	if err != nil {
+		log.WithError(err).Error("failed to parse template")
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+	if err := tmpl.Execute(b, pj); err != nil {
+		log.WithError(err).Error("failed to execute report template")
+		return fmt.Errorf("failed to execute report template: %w", err)
+	}
+
+	card := messageCard(pj, b.String())
+	if tr.dryRun {
+		payload, _ := json.Marshal(card)
+		log.WithField("messagecard", string(payload)).Debug("Skipping reporting because dry-run is enabled")
+		return nil
+	}
+	if err := tr.client.WriteMessage(ctx, card, cfg.Channel); err != nil {
+		log.WithError(err).Error("failed to write Teams message")
+		return fmt.Errorf("failed to write Teams message: %w", err)
+	}
+	return nil
+}
+
+func messageCard(pj *prowapi.ProwJob, text string) *teamsclient.MessageCard {
+	card := teamsclient.NewMessageCard(fmt.Sprintf("%s: %s", pj.Spec.Job, pj.Status.State), text, themeColor(pj.Status.State))
+	if pj.Status.URL != "" {
+		card.AddOpenURIAction("View logs", pj.Status.URL)
+	}
+	return card
+}
+
+func (tr *teamsReporter) GetName() string {
+	return reporterName
+}
+
+func (tr *teamsReporter) ShouldReport(_ context.Context, logger *logrus.Entry, pj *prowapi.ProwJob) bool {
+	cfg := tr.getConfig(pj)
+
+	shouldReport := criercommonlib.ShouldReportJob(pj, cfg.JobTypesToReport, cfg.JobStatesToReport)
+	logger.WithField("reporting", shouldReport).Debug("Determined should report")
+	return shouldReport
+}
+
+func New(cfg func(refs *prowapi.Refs) config.TeamsReporter, dryRun bool, webhooksGenerator func() []byte, httpClient *http.Client) *teamsReporter {
+	return &teamsReporter{
+		client: teamsclient.NewClient(webhooksGenerator, httpClient),
+		config: cfg,
+		dryRun: dryRun,
+	}
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package teams
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+
+	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	teamsclient "sigs.k8s.io/prow/pkg/teams"
+)
+
+func TestShouldReport(t *testing.T) {
+	testCases := []struct {
+		name     string
+		config   config.TeamsReporter
+		pj       *v1.ProwJob
+		expected bool
+	}{
+		{
+			name: "matching type and state should report",
+			config: config.TeamsReporter{
+				JobTypesToReport:  []v1.ProwJobType{v1.PostsubmitJob},
+				JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+			},
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PostsubmitJob},
+				Status: v1.ProwJobStatus{State: v1.FailureState},
+			},
+			expected: true,
+		},
+		{
+			name: "wrong job type should not report",
+			config: config.TeamsReporter{
+				JobTypesToReport:  []v1.ProwJobType{v1.PostsubmitJob},
+				JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+			},
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PresubmitJob},
+				Status: v1.ProwJobStatus{State: v1.FailureState},
+			},
+			expected: false,
+		},
+		{
+			name: "wrong state should not report",
+			config: config.TeamsReporter{
+				JobTypesToReport:  []v1.ProwJobType{v1.PostsubmitJob},
+				JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+			},
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PostsubmitJob},
+				Status: v1.ProwJobStatus{State: v1.SuccessState},
+			},
+			expected: false,
+		},
+		{
+			name: "empty config should not report",
+			pj: &v1.ProwJob{
+				Spec:   v1.ProwJobSpec{Type: v1.PostsubmitJob},
+				Status: v1.ProwJobStatus{State: v1.SuccessState},
+			},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reporter := &teamsReporter{
+				config: func(*v1.Refs) config.TeamsReporter { return tc.config },
+			}
+			if result := reporter.ShouldReport(context.Background(), logrus.NewEntry(logrus.StandardLogger()), tc.pj); result != tc.expected {
+				t.Errorf("expected result to be %t but was %t", tc.expected, result)
+			}
+		})
+	}
+}
+
+type fakeTeamsClient struct {
+	messages map[string]*teamsclient.MessageCard
+}
+
+func (ftc *fakeTeamsClient) WriteMessage(_ context.Context, card *teamsclient.MessageCard, channel string) error {
+	if ftc.messages == nil {
+		ftc.messages = map[string]*teamsclient.MessageCard{}
+	}
+	ftc.messages[channel] = card
+	return nil
+}
+
+var _ teamsClient = &fakeTeamsClient{}
+
+func TestReport(t *testing.T) {
+	testCases := []struct {
+		name     string
+		pj       *v1.ProwJob
+		dryRun   bool
+		expected map[string]*teamsclient.MessageCard
+	}{
+		{
+			name: "failed job is reported with red bar and link",
+			pj: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Job:       "my-job",
+					Type:      v1.PeriodicJob,
+					ExtraRefs: []v1.Refs{{Org: "org"}},
+				},
+				Status: v1.ProwJobStatus{
+					State: v1.FailureState,
+					URL:   "https://prow.k8s.io/view/my-job/1",
+				},
+			},
+			expected: map[string]*teamsclient.MessageCard{
+				"oncall": {
+					Type:       "MessageCard",
+					Context:    "https://schema.org/extensions",
+					ThemeColor: "A30200",
+					Summary:    "my-job: failure",
+					Title:      "my-job: failure",
+					Text:       "my-job ended with failure",
+					PotentialAction: []teamsclient.Action{{
+						Type:    "OpenUri",
+						Name:    "View logs",
+						Targets: []teamsclient.Target{{OS: "default", URI: "https://prow.k8s.io/view/my-job/1"}},
+					}},
+				},
+			},
+		},
+		{
+			name: "successful job without URL has no action",
+			pj: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Job:  "my-job",
+					Type: v1.PeriodicJob,
+					Refs: &v1.Refs{Org: "org"},
+				},
+				Status: v1.ProwJobStatus{
+					State: v1.SuccessState,
+				},
+			},
+			expected: map[string]*teamsclient.MessageCard{
+				"oncall": {
+					Type:       "MessageCard",
+					Context:    "https://schema.org/extensions",
+					ThemeColor: "2EB886",
+					Summary:    "my-job: success",
+					Title:      "my-job: success",
+					Text:       "my-job ended with success",
+				},
+			},
+		},
+		{
+			name: "aborted job is reported with gray bar",
+			pj: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Job:  "my-job",
+					Type: v1.PeriodicJob,
+					Refs: &v1.Refs{Org: "org"},
+				},
+				Status: v1.ProwJobStatus{
+					State: v1.AbortedState,
+				},
+			},
+			expected: map[string]*teamsclient.MessageCard{
+				"oncall": {
+					Type:       "MessageCard",
+					Context:    "https://schema.org/extensions",
+					ThemeColor: "808080",
+					Summary:    "my-job: aborted",
+					Title:      "my-job: aborted",
+					Text:       "my-job ended with aborted",
+				},
+			},
+		},
+		{
+			name:   "dry-run does not send",
+			dryRun: true,
+			pj: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Job:  "my-job",
+					Type: v1.PeriodicJob,
+					Refs: &v1.Refs{Org: "org"},
+				},
+				Status: v1.ProwJobStatus{
+					State: v1.SuccessState,
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ftc := &fakeTeamsClient{}
+			reporter := &teamsReporter{
+				client: ftc,
+				config: func(r *v1.Refs) config.TeamsReporter {
+					if r != nil && r.Org == "org" {
+						return config.TeamsReporter{
+							Channel:        "oncall",
+							ReportTemplate: "{{.Spec.Job}} ended with {{.Status.State}}",
+						}
+					}
+					return config.TeamsReporter{}
+				},
+				dryRun: tc.dryRun,
+			}
+
+			if _, _, err := reporter.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), tc.pj); err != nil {
+				t.Fatalf("reporting failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, ftc.messages); diff != "" {
+				t.Errorf("messages differ from expected: %s", diff)
+			}
+		})
+	}
+}
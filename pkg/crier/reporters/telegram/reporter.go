@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/crier/reporters/criercommonlib"
+	telegramclient "sigs.k8s.io/prow/pkg/telegram"
+)
+
+const (
+	reporterName = "telegramreporter"
+)
+
+// stateEmoji returns the emoji shown in front of the job name. Jobs that
+// ended without failing but didn't succeed, e.g. aborted ones, get a
+// neutral one.
+func stateEmoji(state prowapi.ProwJobState) string {
+	switch {
+	case state == prowapi.SuccessState:
+		return "✅"
+	case criercommonlib.IsTerminalNonFailure(state):
+		return "⚪"
+	case state == prowapi.FailureState:
+		return "❌"
+	case state == prowapi.ErrorState:
+		return "⚠️"
+	default:
+		return "⏳"
+	}
+}
+
+type telegramClient interface {
+	SendMessage(ctx context.Context, chatID, text string) error
+}
+
+type telegramReporter struct {
+	client telegramClient
+	config func(*prowapi.Refs) config.TelegramReporter
+	dryRun bool
+}
+
+func (tr *telegramReporter) getConfig(pj *prowapi.ProwJob) config.TelegramReporter {
+	refs := pj.Spec.Refs
+	if refs == nil && len(pj.Spec.ExtraRefs) > 0 {
+		refs = &pj.Spec.ExtraRefs[0]
+	}
+	return tr.config(refs)
+}
+
+func (tr *telegramReporter) Report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) ([]*prowapi.ProwJob, *reconcile.Result, error) {
+	return []*prowapi.ProwJob{pj}, nil, tr.report(ctx, log, pj)
+}
+
+func (tr *telegramReporter) report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) error {
+	cfg := tr.getConfig(pj)
+
+	b := &bytes.Buffer{}
+	tmpl, err := template.New("").Parse(cfg.ReportTemplate)
+	if err != nil {
+		log.WithError(err).Error("failed to parse template")
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+	if err := tmpl.Execute(b, pj); err != nil {
+		log.WithError(err).Error("failed to execute report template")
+		return fmt.Errorf("failed to execute report template: %w", err)
+	}
+
+	text := message(pj, b.String())
+	if tr.dryRun {
+		log.WithField("message", text).Debug("Skipping reporting because dry-run is enabled")
+		return nil
+	}
+	if err := tr.client.SendMessage(ctx, cfg.ChatID, text); err != nil {
+		log.WithError(err).Error("failed to send Telegram message")
+		return fmt.Errorf("failed to send Telegram message: %w", err)
+	}
+	return nil
+}
+
+// message formats the message in MarkdownV2. Everything but the markup
+// added here is escaped, as Telegram rejects messages with unescaped
+// special characters.
+func message(pj *prowapi.ProwJob, text string) string {
+	lines := []string{
+		fmt.Sprintf("%s *%s*: %s", stateEmoji(pj.Status.State), telegramclient.EscapeMarkdownV2(pj.Spec.Job), telegramclient.EscapeMarkdownV2(string(pj.Status.State))),
+		telegramclient.EscapeMarkdownV2(text),
+	}
+	if pj.Status.URL != "" {
+		lines = append(lines, telegramclient.MarkdownV2Link("View logs", pj.Status.URL))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (tr *telegramReporter) GetName() string {
+	return reporterName
+}
+
+func (tr *telegramReporter) ShouldReport(_ context.Context, logger *logrus.Entry, pj *prowapi.ProwJob) bool {
+	cfg := tr.getConfig(pj)
+
+	shouldReport := criercommonlib.ShouldReportJob(pj, cfg.JobTypesToReport, cfg.JobStatesToReport)
+	logger.WithField("reporting", shouldReport).Debug("Determined should report")
+	return shouldReport
+}
+
+func New(cfg func(refs *prowapi.Refs) config.TelegramReporter, dryRun bool, tokenGenerator func() []byte, httpClient *http.Client) *telegramReporter {
+	return &telegramReporter{
+		client: telegramclient.NewClient(tokenGenerator, httpClient),
+		config: cfg,
+		dryRun: dryRun,
+	}
+}
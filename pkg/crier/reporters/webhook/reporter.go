@@ -0,0 +1,236 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package webhook contains a crier reporter that POSTs ProwJobs as JSON to
+// an arbitrary HTTP endpoint.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/crier/reporters/criercommonlib"
+)
+
+const (
+	reporterName = "webhookreporter"
+)
+
+var (
+	webhookMetrics = struct {
+		// Count of ProwJobs that could not be delivered.
+		failures *prometheus.CounterVec
+	}{
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "crier_webhook_reporter_failures",
+			Help: "Count of ProwJobs the webhook reporter failed to deliver after all retries, by reason.",
+		}, []string{
+			"reason",
+		}),
+	}
+
+	// defaultBackoff is the backoff between retries of a failed request.
+	// Steps is set from the MaxRetries of the config.
+	defaultBackoff = wait.Backoff{
+		Duration: time.Second,
+		Factor:   2,
+		Cap:      30 * time.Second,
+		Jitter:   0.1,
+	}
+)
+
+func init() {
+	prometheus.MustRegister(webhookMetrics.failures)
+}
+
+// permanentError is returned for requests that must not be retried.
+type permanentError struct {
+	error
+}
+
+type webhookReporter struct {
+	config         func(*prowapi.Refs) config.WebhookReporter
+	tokenGenerator func() []byte
+	client         *http.Client
+	backoff        wait.Backoff
+	dryRun         bool
+}
+
+func (wr *webhookReporter) getConfig(pj *prowapi.ProwJob) config.WebhookReporter {
+	refs := pj.Spec.Refs
+	if refs == nil && len(pj.Spec.ExtraRefs) > 0 {
+		refs = &pj.Spec.ExtraRefs[0]
+	}
+	return wr.config(refs)
+}
+
+func (wr *webhookReporter) Report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) ([]*prowapi.ProwJob, *reconcile.Result, error) {
+	return []*prowapi.ProwJob{pj}, nil, wr.report(ctx, log, pj)
+}
+
+// ReportTarget returns the URL the job is posted to, without its query
+// and user info, which may contain credentials.
+func (wr *webhookReporter) ReportTarget(pj *prowapi.ProwJob) string {
+	u, err := url.Parse(wr.getConfig(pj).URL)
+	if err != nil {
+		return ""
+	}
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
+
+func (wr *webhookReporter) report(ctx context.Context, log *logrus.Entry, pj *prowapi.ProwJob) error {
+	cfg := wr.getConfig(pj)
+
+	payload, err := payload(pj, cfg.Fields)
+	if err != nil {
+		webhookMetrics.failures.WithLabelValues("marshal").Inc()
+		return fmt.Errorf("failed to marshal ProwJob: %w", err)
+	}
+	if wr.dryRun {
+		log.WithField("url", cfg.URL).WithField("payload", string(payload)).Debug("Skipping reporting because dry-run is enabled")
+		return nil
+	}
+
+	backoff := wr.backoff
+	backoff.Steps = cfg.MaxRetries + 1
+	var lastErr error
+	err = wait.ExponentialBackoffWithContext(ctx, backoff, func(ctx context.Context) (bool, error) {
+		lastErr = wr.post(ctx, cfg, payload)
+		if lastErr == nil {
+			return true, nil
+		}
+		if _, ok := lastErr.(permanentError); ok {
+			return false, lastErr
+		}
+		log.WithError(lastErr).Debug("Failed to post ProwJob, retrying")
+		return false, nil
+	})
+	if err != nil {
+		if lastErr == nil {
+			lastErr = err
+		}
+		reason := "retries_exhausted"
+		if _, ok := lastErr.(permanentError); ok {
+			reason = "permanent"
+		}
+		webhookMetrics.failures.WithLabelValues(reason).Inc()
+		log.WithError(lastErr).Error("failed to post ProwJob to webhook")
+		return fmt.Errorf("failed to post ProwJob to webhook: %w", lastErr)
+	}
+	return nil
+}
+
+func (wr *webhookReporter) post(ctx context.Context, cfg config.WebhookReporter, payload []byte) error {
+	if cfg.Timeout != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout.Duration)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.URL, bytes.NewReader(payload))
+	if err != nil {
+		return permanentError{err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	if wr.tokenGenerator != nil {
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(wr.tokenGenerator())))
+	}
+
+	resp, err := wr.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	body, _ := io.ReadAll(resp.Body)
+	err = fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(body))
+	// Client errors other than rate limiting won't go away by retrying.
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+		return permanentError{err}
+	}
+	return err
+}
+
+// payload returns the JSON representation of the ProwJob, limited to the
+// given top level fields if any.
+func payload(pj *prowapi.ProwJob, fields []string) ([]byte, error) {
+	b, err := json.Marshal(pj)
+	if err != nil || len(fields) == 0 {
+		return b, err
+	}
+	all := map[string]json.RawMessage{}
+	if err := json.Unmarshal(b, &all); err != nil {
+		return nil, err
+	}
+	subset := map[string]json.RawMessage{}
+	for _, field := range fields {
+		if v, ok := all[field]; ok {
+			subset[field] = v
+		}
+	}
+	return json.Marshal(subset)
+}
+
+func (wr *webhookReporter) GetName() string {
+	return reporterName
+}
+
+func (wr *webhookReporter) ShouldReport(_ context.Context, logger *logrus.Entry, pj *prowapi.ProwJob) bool {
+	cfg := wr.getConfig(pj)
+
+	shouldReport := criercommonlib.ShouldReportJob(pj, cfg.JobTypesToReport, cfg.JobStatesToReport)
+	logger.WithField("reporting", shouldReport).Debug("Determined should report")
+	return shouldReport
+}
+
+// New returns a webhook reporter. If tokenGenerator is non-nil, its value is
+// sent as bearer token with every request. Requests are sent with httpClient,
+// or http.DefaultClient if nil.
+func New(cfg func(refs *prowapi.Refs) config.WebhookReporter, dryRun bool, tokenGenerator func() []byte, httpClient *http.Client) *webhookReporter {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &webhookReporter{
+		config:         cfg,
+		tokenGenerator: tokenGenerator,
+		client:         httpClient,
+		backoff:        defaultBackoff,
+		dryRun:         dryRun,
+	}
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"k8s.io/apimachinery/pkg/labels"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// WithLabelSelector makes the controller only reconcile jobs whose labels
+// match the selector, so that jobs reported by something other than this
+// crier are left alone. Jobs that don't match are dropped from the events of
+// the controller and never passed to the reporter. The cache of the manager
+// should be restricted to the same selector, so that non-matching jobs
+// aren't held in memory either.
+func WithLabelSelector(selector labels.Selector) Option {
+	return func(o *Options) {
+		o.LabelSelector = selector
+	}
+}
+
+// matchesSelector returns whether the job should be reconciled given the
+// label selector, which may be nil.
+func matchesSelector(selector labels.Selector, obj ctrlruntimeclient.Object) bool {
+	return selector == nil || selector.Matches(labels.Set(obj.GetLabels()))
+}
+
+func selectorPredicate(selector labels.Selector) predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj ctrlruntimeclient.Object) bool {
+		return matchesSelector(selector, obj)
+	})
+}
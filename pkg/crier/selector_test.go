@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlruntime "sigs.k8s.io/controller-runtime"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+)
+
+func TestReconcileLabelSelector(t *testing.T) {
+	selector, err := labels.Parse("reporting=crier")
+	if err != nil {
+		t.Fatalf("failed to parse selector: %v", err)
+	}
+	testCases := []struct {
+		name     string
+		labels   map[string]string
+		selector labels.Selector
+		expected []string
+	}{
+		{
+			name:     "matching job is reported",
+			labels:   map[string]string{"reporting": "crier"},
+			selector: selector,
+			expected: []string{"foo"},
+		},
+		{
+			name:     "job with other label value is not reported",
+			labels:   map[string]string{"reporting": "pipeline"},
+			selector: selector,
+		},
+		{
+			name:     "job without label is not reported",
+			selector: selector,
+		},
+		{
+			name:     "all jobs are reported without selector",
+			expected: []string{"foo"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			job := &prowv1.ProwJob{
+				Spec:   prowv1.ProwJobSpec{Job: "foo", Report: true},
+				Status: prowv1.ProwJobStatus{State: prowv1.SuccessState},
+			}
+			job.Name = "foo"
+			job.Labels = tc.labels
+			rp := &fakeReporter{shouldReportFunc: func(*prowv1.ProwJob) bool { return true }}
+			r := &reconciler{
+				pjclientset:       fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(job).Build(),
+				reporter:          rp,
+				enablementChecker: func(_, _ string) bool { return true },
+				labelSelector:     tc.selector,
+			}
+			if _, err := r.Reconcile(context.Background(), ctrlruntime.Request{NamespacedName: types.NamespacedName{Name: "foo"}}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(rp.reported) != len(tc.expected) {
+				t.Errorf("expected reports %v, got %v", tc.expected, rp.reported)
+			}
+
+			if matches := selectorPredicate(selector).Update(event.UpdateEvent{ObjectOld: job, ObjectNew: job}); matches != (tc.labels["reporting"] == "crier") {
+				t.Errorf("expected predicate to match: %t, got %t", !matches, matches)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"strconv"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/kube"
+)
+
+// WithSkipReporting makes the controller skip the jobs that skip returns
+// true for, e.g. because their labels opt them out of reporting. Unlike
+// WithLabelSelector, skip is consulted on every reconcile, so that it can
+// change without restarting crier, and skipped jobs count as done with for
+// WithSkipReportedJobs.
+func WithSkipReporting(skip func(*prowv1.ProwJob) bool) Option {
+	return func(o *Options) {
+		o.SkipReporting = skip
+	}
+}
+
+// WithSuppressRetryableFailures makes the controller skip failed jobs that
+// are going to be retried while enabled returns true, so that only the
+// outcome of the final attempt is reported. It is meant for notification
+// reporters: status reporters must report every attempt, or the status of
+// an attempt whose retry never gets created stays pending.
+func WithSuppressRetryableFailures(enabled func() bool) Option {
+	return func(o *Options) {
+		o.SuppressRetryableFailures = enabled
+	}
+}
+
+// willBeRetried tells whether the job failed and its retry annotations say
+// that it is going to be retried, either explicitly or because it hasn't
+// reached its maximum number of retries yet.
+func willBeRetried(pj *prowv1.ProwJob) bool {
+	if pj.Status.State != prowv1.FailureState && pj.Status.State != prowv1.ErrorState {
+		return false
+	}
+	if pj.Annotations[kube.WillRetryAnnotation] == "true" {
+		return true
+	}
+	attempt, err := strconv.Atoi(pj.Annotations[kube.RetryAttemptAnnotation])
+	if err != nil {
+		return false
+	}
+	maxRetries, err := strconv.Atoi(pj.Annotations[kube.MaxRetriesAnnotation])
+	if err != nil {
+		return false
+	}
+	return attempt < maxRetries
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/types"
+	ctrlruntime "sigs.k8s.io/controller-runtime"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+)
+
+// namedReporter is a fakeReporter with its own name, so that several of
+// them can report the same job.
+type namedReporter struct {
+	fakeReporter
+	name string
+}
+
+func (n *namedReporter) GetName() string {
+	return n.name
+}
+
+func TestReconcileSkipReporting(t *testing.T) {
+	crierConfig := config.Crier{SkipLabels: map[string]string{"report": "none"}}
+	testCases := []struct {
+		name           string
+		labels         map[string]string
+		skip           func(*prowv1.ProwJob) bool
+		expectReported bool
+	}{
+		{
+			name:   "labeled job is skipped by every reporter",
+			labels: map[string]string{"report": "none"},
+			skip:   crierConfig.SkipsReporting,
+		},
+		{
+			name:           "job with other label value is reported",
+			labels:         map[string]string{"report": "all"},
+			skip:           crierConfig.SkipsReporting,
+			expectReported: true,
+		},
+		{
+			name:           "labeled job is reported without skip rules",
+			labels:         map[string]string{"report": "none"},
+			expectReported: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			job := &prowv1.ProwJob{
+				Spec:   prowv1.ProwJobSpec{Job: "foo", Report: true},
+				Status: prowv1.ProwJobStatus{State: prowv1.SuccessState},
+			}
+			job.Name = "foo"
+			job.Labels = tc.labels
+			client := fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(job).Build()
+
+			for _, name := range []string{"githubreporter", "slackreporter", "pubsubreporter"} {
+				rp := &namedReporter{fakeReporter: fakeReporter{shouldReportFunc: func(*prowv1.ProwJob) bool { return true }}, name: name}
+				r := &reconciler{
+					pjclientset:       client,
+					reporter:          rp,
+					enablementChecker: func(_, _ string) bool { return true },
+					skipReporting:     tc.skip,
+				}
+				if _, err := r.Reconcile(context.Background(), ctrlruntime.Request{NamespacedName: types.NamespacedName{Name: "foo"}}); err != nil {
+					t.Fatalf("%s: unexpected error: %v", name, err)
+				}
+				if reported := len(rp.reported) > 0; reported != tc.expectReported {
+					t.Errorf("%s: expected reported=%t, got %t", name, tc.expectReported, reported)
+				}
+			}
+		})
+	}
+}
+
+func TestReconcileSuppressRetryableFailures(t *testing.T) {
+	testCases := []struct {
+		name           string
+		suppress       func() bool
+		state          prowv1.ProwJobState
+		annotations    map[string]string
+		expectReported bool
+	}{
+		{
+			name:        "failed job mid-retry is suppressed",
+			suppress:    func() bool { return true },
+			state:       prowv1.FailureState,
+			annotations: map[string]string{"prow.k8s.io/retry-attempt": "1", "prow.k8s.io/max-retries": "3"},
+		},
+		{
+			name:        "errored job that will be retried is suppressed",
+			suppress:    func() bool { return true },
+			state:       prowv1.ErrorState,
+			annotations: map[string]string{"prow.k8s.io/will-retry": "true"},
+		},
+		{
+			name:           "final attempt is reported",
+			suppress:       func() bool { return true },
+			state:          prowv1.FailureState,
+			annotations:    map[string]string{"prow.k8s.io/retry-attempt": "3", "prow.k8s.io/max-retries": "3"},
+			expectReported: true,
+		},
+		{
+			name:           "successful attempt is reported",
+			suppress:       func() bool { return true },
+			state:          prowv1.SuccessState,
+			annotations:    map[string]string{"prow.k8s.io/retry-attempt": "1", "prow.k8s.io/max-retries": "3"},
+			expectReported: true,
+		},
+		{
+			name:           "failed job without retry annotations is reported",
+			suppress:       func() bool { return true },
+			state:          prowv1.FailureState,
+			expectReported: true,
+		},
+		{
+			name:           "job mid-retry is reported when disabled in the config",
+			suppress:       func() bool { return false },
+			state:          prowv1.FailureState,
+			annotations:    map[string]string{"prow.k8s.io/will-retry": "true"},
+			expectReported: true,
+		},
+		{
+			name:           "job mid-retry is reported by reporters without the option",
+			state:          prowv1.FailureState,
+			annotations:    map[string]string{"prow.k8s.io/will-retry": "true"},
+			expectReported: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			job := &prowv1.ProwJob{
+				Spec:   prowv1.ProwJobSpec{Job: "foo", Report: true},
+				Status: prowv1.ProwJobStatus{State: tc.state},
+			}
+			job.Name = "foo"
+			job.Annotations = tc.annotations
+			rp := &fakeReporter{shouldReportFunc: func(*prowv1.ProwJob) bool { return true }}
+			r := &reconciler{
+				pjclientset:       fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(job).Build(),
+				reporter:          rp,
+				enablementChecker: func(_, _ string) bool { return true },
+				suppressRetryable: tc.suppress,
+			}
+			if _, err := r.Reconcile(context.Background(), ctrlruntime.Request{NamespacedName: types.NamespacedName{Name: "foo"}}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if reported := len(rp.reported) > 0; reported != tc.expectReported {
+				t.Errorf("expected reported=%t, got %t", tc.expectReported, reported)
+			}
+		})
+	}
+}
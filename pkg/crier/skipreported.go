@@ -0,0 +1,124 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+)
+
+// FullyReportedAnnotation is set to "true" on completed jobs that all
+// reporters are done with. Controllers using WithSkipReportedJobs don't
+// reconcile such jobs anymore.
+const FullyReportedAnnotation = "prow.k8s.io/crier-fully-reported"
+
+// ReportedJobs tracks which reporters are done with the final state of
+// completed jobs. A reporter is done with a job once it reported its state,
+// or decided not to report it. Once all reporters registered with
+// WithSkipReportedJobs are done with a job, it is annotated with
+// FullyReportedAnnotation.
+//
+// The progress of jobs is only tracked in memory. After a restart, the jobs
+// that weren't annotated yet are reconciled by all reporters again, which
+// rebuilds it.
+type ReportedJobs struct {
+	lock      sync.Mutex
+	reporters sets.Set[string]
+	done      map[types.NamespacedName]sets.Set[string]
+}
+
+// NewReportedJobs returns an empty ReportedJobs.
+func NewReportedJobs() *ReportedJobs {
+	return &ReportedJobs{
+		reporters: sets.New[string](),
+		done:      map[types.NamespacedName]sets.Set[string]{},
+	}
+}
+
+// WithSkipReportedJobs registers the reporter with reported, and filters
+// jobs carrying FullyReportedAnnotation out of the controller's events. The
+// same ReportedJobs must be passed to the controllers of all reporters.
+func WithSkipReportedJobs(reported *ReportedJobs) Option {
+	return func(o *Options) {
+		o.ReportedJobs = reported
+	}
+}
+
+func (r *ReportedJobs) register(reporter string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.reporters.Insert(reporter)
+}
+
+// markDone records that reporter is done with the job, and returns whether
+// all registered reporters are done with it.
+func (r *ReportedJobs) markDone(reporter string, key types.NamespacedName) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.done[key] == nil {
+		r.done[key] = sets.New[string]()
+	}
+	r.done[key].Insert(reporter)
+	return r.done[key].IsSuperset(r.reporters)
+}
+
+// forget stops tracking the job once it was annotated or deleted, e.g. by
+// sinker before all reporters were done with it.
+func (r *ReportedJobs) forget(key types.NamespacedName) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.done, key)
+}
+
+// notFullyReported filters jobs that all reporters are done with out of the
+// events of the controller.
+var notFullyReported = predicate.NewPredicateFuncs(func(obj ctrlruntimeclient.Object) bool {
+	return obj.GetAnnotations()[FullyReportedAnnotation] != "true"
+})
+
+// reportDone records that the reporter is done with the job if it is
+// complete, and annotates the job once all reporters are.
+func (r *reconciler) reportDone(ctx context.Context, log *logrus.Entry, pj *prowv1.ProwJob) error {
+	if r.reportedJobs == nil || !pj.Complete() {
+		return nil
+	}
+	if !r.reportedJobs.markDone(r.reporter.GetName(), ctrlruntimeclient.ObjectKeyFromObject(pj)) {
+		return nil
+	}
+	original := pj.DeepCopy()
+	if pj.Annotations == nil {
+		pj.Annotations = map[string]string{}
+	}
+	pj.Annotations[FullyReportedAnnotation] = "true"
+	if err := r.pjclientset.Patch(ctx, pj, ctrlruntimeclient.MergeFrom(original)); err != nil {
+		// The job stays tracked, so annotating it is retried when it is
+		// reconciled again.
+		return fmt.Errorf("failed to annotate prowjob %s as fully reported: %w", pj.Name, err)
+	}
+	r.reportedJobs.forget(ctrlruntimeclient.ObjectKeyFromObject(pj))
+	log.Debug("Annotated job as fully reported")
+	return nil
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlruntime "sigs.k8s.io/controller-runtime"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+)
+
+type otherFakeReporter struct {
+	fakeReporter
+}
+
+func (f *otherFakeReporter) GetName() string {
+	return "otherFakeReporter"
+}
+
+func TestNotFullyReported(t *testing.T) {
+	testCases := []struct {
+		name        string
+		annotations map[string]string
+		expected    bool
+	}{
+		{
+			name:     "no annotations",
+			expected: true,
+		},
+		{
+			name:        "other annotations",
+			annotations: map[string]string{"foo": "bar"},
+			expected:    true,
+		},
+		{
+			name:        "fully reported",
+			annotations: map[string]string{FullyReportedAnnotation: "true"},
+			expected:    false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pj := &prowv1.ProwJob{ObjectMeta: metav1.ObjectMeta{Name: "foo", Annotations: tc.annotations}}
+			if got := notFullyReported.Create(event.CreateEvent{Object: pj}); got != tc.expected {
+				t.Errorf("create event: expected %t, got %t", tc.expected, got)
+			}
+			if got := notFullyReported.Update(event.UpdateEvent{ObjectOld: pj, ObjectNew: pj}); got != tc.expected {
+				t.Errorf("update event: expected %t, got %t", tc.expected, got)
+			}
+			if got := notFullyReported.Generic(event.GenericEvent{Object: pj}); got != tc.expected {
+				t.Errorf("generic event: expected %t, got %t", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestReconcileAnnotatesFullyReportedJobs(t *testing.T) {
+	const toReconcile = "foo"
+	now := metav1.Now()
+	job := &prowv1.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{Name: toReconcile, UID: "uid"},
+		Spec: prowv1.ProwJobSpec{
+			Job:    "foo",
+			Report: true,
+		},
+		Status: prowv1.ProwJobStatus{
+			State:          prowv1.SuccessState,
+			CompletionTime: &now,
+		},
+	}
+	cs := fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(job).Build()
+	reported := NewReportedJobs()
+	reporting := &fakeReporter{shouldReportFunc: func(*prowv1.ProwJob) bool { return true }}
+	notReporting := &otherFakeReporter{fakeReporter{shouldReportFunc: func(*prowv1.ProwJob) bool { return false }}}
+	reported.register(reporting.GetName())
+	reported.register(notReporting.GetName())
+	req := ctrlruntime.Request{NamespacedName: types.NamespacedName{Name: toReconcile}}
+
+	fullyReported := func() bool {
+		var pj prowv1.ProwJob
+		if err := cs.Get(context.Background(), req.NamespacedName, &pj); err != nil {
+			t.Fatalf("failed to get job: %v", err)
+		}
+		return pj.Annotations[FullyReportedAnnotation] == "true"
+	}
+
+	r := &reconciler{pjclientset: cs, reporter: reporting, reportedJobs: reported}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(reporting.reported) != 1 {
+		t.Errorf("expected 1 report, got %d", len(reporting.reported))
+	}
+	if fullyReported() {
+		t.Error("expected job not to be annotated before all reporters are done with it")
+	}
+
+	r = &reconciler{pjclientset: cs, reporter: notReporting, reportedJobs: reported}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !fullyReported() {
+		t.Error("expected job to be annotated once all reporters are done with it")
+	}
+	if _, tracked := reported.done[req.NamespacedName]; tracked {
+		t.Error("expected job not to be tracked anymore once annotated")
+	}
+}
+
+func TestReconcileForgetsDeletedJobs(t *testing.T) {
+	reported := NewReportedJobs()
+	reporting := &fakeReporter{shouldReportFunc: func(*prowv1.ProwJob) bool { return true }}
+	reported.register(reporting.GetName())
+	reported.register((&otherFakeReporter{}).GetName())
+	req := ctrlruntime.Request{NamespacedName: types.NamespacedName{Name: "foo"}}
+	// One reporter is done with the job, but it is deleted before the other
+	// one is.
+	reported.markDone(reporting.GetName(), req.NamespacedName)
+
+	r := &reconciler{pjclientset: fakectrlruntimeclient.NewClientBuilder().Build(), reporter: reporting, reportedJobs: reported}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, tracked := reported.done[req.NamespacedName]; tracked {
+		t.Error("expected deleted job not to be tracked anymore")
+	}
+}
+
+func TestReconcileDoesNotAnnotateIncompleteJobs(t *testing.T) {
+	const toReconcile = "foo"
+	job := &prowv1.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{Name: toReconcile, UID: "uid"},
+		Spec: prowv1.ProwJobSpec{
+			Job:    "foo",
+			Report: true,
+		},
+		Status: prowv1.ProwJobStatus{
+			State: prowv1.PendingState,
+		},
+	}
+	cs := fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(job).Build()
+	reported := NewReportedJobs()
+	reporting := &fakeReporter{shouldReportFunc: func(*prowv1.ProwJob) bool { return true }}
+	reported.register(reporting.GetName())
+	req := ctrlruntime.Request{NamespacedName: types.NamespacedName{Name: toReconcile}}
+
+	r := &reconciler{pjclientset: cs, reporter: reporting, reportedJobs: reported}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var pj prowv1.ProwJob
+	if err := cs.Get(context.Background(), req.NamespacedName, &pj); err != nil {
+		t.Fatalf("failed to get job: %v", err)
+	}
+	if _, ok := pj.Annotations[FullyReportedAnnotation]; ok {
+		t.Error("expected pending job not to be annotated")
+	}
+	if len(reported.done) != 0 {
+		t.Errorf("expected pending job not to be tracked, got %v", reported.done)
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+)
+
+// WithReportTimeout limits how long a single Report call of the reporter may
+// take to the duration timeout returns for the reporter's name. A call that
+// exceeds it is cancelled and the job is retried with backoff. A duration of
+// zero or less means no limit.
+func WithReportTimeout(timeout func(reporter string) time.Duration) Option {
+	return func(o *Options) {
+		o.ReportTimeout = timeout
+	}
+}
+
+// report calls the reporter with a context that is cancelled once the report
+// timeout passed. The reporter is called synchronously rather than abandoned
+// on timeout, so that a report that is still in flight can neither deliver
+// after the job was requeued nor pile up outside the worker limit; reporters
+// must pass the context on to their clients for the timeout to take effect.
+func (r *reconciler) report(ctx context.Context, log *logrus.Entry, pj *prowv1.ProwJob) ([]*prowv1.ProwJob, *reconcile.Result, error) {
+	var timeout time.Duration
+	if r.reportTimeout != nil {
+		timeout = r.reportTimeout(r.reporter.GetName())
+	}
+	if timeout <= 0 {
+		return r.reporter.Report(ctx, log, pj)
+	}
+
+	reportCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	pjs, requeue, err := r.reporter.Report(reportCtx, log, pj)
+	if err != nil && ctx.Err() == nil && reportCtx.Err() == context.DeadlineExceeded {
+		crierMetrics.reportTimeouts.WithLabelValues(r.reporter.GetName()).Inc()
+		return nil, nil, fmt.Errorf("report timed out after %s: %w", timeout, err)
+	}
+	return pjs, requeue, err
+}
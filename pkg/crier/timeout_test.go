@@ -0,0 +1,128 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlruntime "sigs.k8s.io/controller-runtime"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+)
+
+// blockingReporter blocks in Report until unblock is closed or its context
+// is cancelled, like a reporter waiting for a backend that hangs.
+type blockingReporter struct {
+	unblock chan struct{}
+}
+
+func (b *blockingReporter) Report(ctx context.Context, _ *logrus.Entry, pj *prowv1.ProwJob) ([]*prowv1.ProwJob, *reconcile.Result, error) {
+	select {
+	case <-b.unblock:
+		return []*prowv1.ProwJob{pj}, nil, nil
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+}
+
+func (b *blockingReporter) GetName() string {
+	return reporterName
+}
+
+func (b *blockingReporter) ShouldReport(_ context.Context, _ *logrus.Entry, _ *prowv1.ProwJob) bool {
+	return true
+}
+
+func TestReconcileReportTimeout(t *testing.T) {
+	testCases := []struct {
+		name          string
+		block         bool
+		expectErr     bool
+		expectedState prowv1.ProwJobState
+	}{
+		{
+			name:          "report within the timeout succeeds",
+			expectedState: prowv1.SuccessState,
+		},
+		{
+			name:      "report exceeding the timeout is cancelled",
+			block:     true,
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			const toReconcile = "foo"
+			job := &prowv1.ProwJob{
+				Spec: prowv1.ProwJobSpec{
+					Job:    "foo",
+					Report: true,
+				},
+				Status: prowv1.ProwJobStatus{
+					State: prowv1.SuccessState,
+				},
+			}
+			job.Name = toReconcile
+			cs := fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(job).Build()
+			rp := &blockingReporter{unblock: make(chan struct{})}
+			if !tc.block {
+				close(rp.unblock)
+			} else {
+				defer close(rp.unblock)
+			}
+			r := &reconciler{
+				pjclientset:   cs,
+				reporter:      rp,
+				reportTimeout: func(string) time.Duration { return 100 * time.Millisecond },
+			}
+			timeoutsBefore := testutil.ToFloat64(crierMetrics.reportTimeouts.WithLabelValues(reporterName))
+
+			start := time.Now()
+			req := ctrlruntime.Request{NamespacedName: types.NamespacedName{Name: toReconcile}}
+			_, err := r.Reconcile(context.Background(), req)
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("expected error: %t, got: %v", tc.expectErr, err)
+			}
+			if elapsed := time.Since(start); elapsed > 10*time.Second {
+				t.Errorf("expected the worker to be freed after the timeout, took %s", elapsed)
+			}
+
+			var expectedTimeouts float64
+			if tc.block {
+				expectedTimeouts = 1
+			}
+			if timeouts := testutil.ToFloat64(crierMetrics.reportTimeouts.WithLabelValues(reporterName)) - timeoutsBefore; timeouts != expectedTimeouts {
+				t.Errorf("expected %v timeouts to be recorded, got %v", expectedTimeouts, timeouts)
+			}
+			var pj prowv1.ProwJob
+			if err := cs.Get(context.Background(), req.NamespacedName, &pj); err != nil {
+				t.Fatalf("failed to get prowjob: %v", err)
+			}
+			if state := pj.Status.PrevReportStates[reporterName]; state != tc.expectedState {
+				t.Errorf("expected report state %q, got %q", tc.expectedState, state)
+			}
+		})
+	}
+}
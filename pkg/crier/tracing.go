@@ -0,0 +1,72 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+)
+
+const (
+	tracerName = "sigs.k8s.io/prow/pkg/crier"
+
+	reporterAttribute = attribute.Key("crier.reporter")
+	prowJobAttribute  = attribute.Key("prow.prowjob")
+	jobAttribute      = attribute.Key("prow.job")
+	stateAttribute    = attribute.Key("prow.job.state")
+)
+
+// tracer returns the tracer of crier's spans. Spans are only recorded if
+// tracing was set up, e.g. with tracing.Instrument.
+func tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// jobAttributes are the span attributes describing the job.
+func jobAttributes(pj *prowv1.ProwJob) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		jobAttribute.String(pj.Spec.Job),
+		stateAttribute.String(string(pj.Status.State)),
+	}
+}
+
+// endSpan records the error, if any, on the span and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// tracedReport calls the reporter within a span, so that traces show how
+// much of a reconcile is spent in the reporter.
+func (r *reconciler) tracedReport(ctx context.Context, log *logrus.Entry, pj *prowv1.ProwJob) ([]*prowv1.ProwJob, *reconcile.Result, error) {
+	attributes := append([]attribute.KeyValue{reporterAttribute.String(r.reporter.GetName())}, jobAttributes(pj)...)
+	ctx, span := tracer().Start(ctx, "crier.Report", trace.WithAttributes(attributes...))
+	pjs, requeue, err := r.report(ctx, log, pj)
+	endSpan(span, err)
+	return pjs, requeue, err
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlruntime "sigs.k8s.io/controller-runtime"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+)
+
+func TestReconcileSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	pj := &prowv1.ProwJob{
+		ObjectMeta: v1.ObjectMeta{Name: "foo"},
+		Spec:       prowv1.ProwJobSpec{Job: "my-job"},
+		Status:     prowv1.ProwJobStatus{State: prowv1.FailureState},
+	}
+	r := &reconciler{
+		pjclientset: fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(pj).Build(),
+		reporter: &fakeReporter{
+			shouldReportFunc: func(*prowv1.ProwJob) bool { return true },
+			err:              errors.New("slack is down"),
+		},
+	}
+	req := ctrlruntime.Request{NamespacedName: types.NamespacedName{Name: "foo"}}
+	if _, err := r.Reconcile(context.Background(), req); err == nil {
+		t.Fatal("expected reconcile to fail")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected a reconcile and a report span, got %d spans", len(spans))
+	}
+	// The report span ends first.
+	report, reconcile := spans[0], spans[1]
+	if report.Name() != "crier.Report" || reconcile.Name() != "crier.Reconcile" {
+		t.Fatalf("unexpected spans %q and %q", report.Name(), reconcile.Name())
+	}
+	if report.Parent().SpanID() != reconcile.SpanContext().SpanID() {
+		t.Error("expected the report span to be a child of the reconcile span")
+	}
+	expected := map[attribute.Key]string{
+		reporterAttribute: reporterName,
+		jobAttribute:      "my-job",
+		stateAttribute:    "failure",
+	}
+	if diff := cmp.Diff(expected, spanAttributes(report)); diff != "" {
+		t.Errorf("report span attributes differ from expected (-want +got):\n%s", diff)
+	}
+	expected[prowJobAttribute] = "foo"
+	if diff := cmp.Diff(expected, spanAttributes(reconcile)); diff != "" {
+		t.Errorf("reconcile span attributes differ from expected (-want +got):\n%s", diff)
+	}
+	for _, span := range spans {
+		if span.Status().Code != codes.Error {
+			t.Errorf("expected span %q to record the error, got status %v", span.Name(), span.Status())
+		}
+	}
+}
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]string {
+	attributes := map[attribute.Key]string{}
+	for _, kv := range span.Attributes() {
+		attributes[kv.Key] = kv.Value.AsString()
+	}
+	return attributes
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"context"
+	"fmt"
+	stdio "io"
+	"time"
+)
+
+// ValidationTimeout bounds how long validating the config of a reporter may
+// take.
+const ValidationTimeout = time.Minute
+
+// ConfigValidator is implemented by reporters that can check their config
+// against their backends, e.g. that their tokens are accepted and the
+// channels they report to exist.
+type ConfigValidator interface {
+	// Validate returns an error describing every problem with the config.
+	Validate(ctx context.Context) error
+}
+
+// ValidateReporters validates the config of every reporter that implements
+// ConfigValidator and writes the result for every reporter to out. It
+// returns an error if any validation failed.
+func ValidateReporters(ctx context.Context, out stdio.Writer, reporters []ReportClient) error {
+	var failed []string
+	for _, reporter := range reporters {
+		name := reporter.GetName()
+		validator, ok := reporter.(ConfigValidator)
+		if !ok {
+			fmt.Fprintf(out, "%s: SKIPPED (no validation)\n", name)
+			continue
+		}
+		validateCtx, cancel := context.WithTimeout(ctx, ValidationTimeout)
+		err := validator.Validate(validateCtx)
+		cancel()
+		if err != nil {
+			fmt.Fprintf(out, "%s: FAILED\n  %v\n", name, err)
+			failed = append(failed, name)
+			continue
+		}
+		fmt.Fprintf(out, "%s: OK\n", name)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("config validation failed for %d reporter(s): %v", len(failed), failed)
+	}
+	return nil
+}
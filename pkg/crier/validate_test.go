@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// validatingReporter is a checkingReporter whose config can be validated.
+type validatingReporter struct {
+	checkingReporter
+}
+
+func (r *validatingReporter) Validate(_ context.Context) error {
+	return r.err
+}
+
+func TestValidateReporters(t *testing.T) {
+	testCases := []struct {
+		name           string
+		reporters      []ReportClient
+		expectedOutput string
+		expectedErr    bool
+	}{
+		{
+			name: "all configs valid",
+			reporters: []ReportClient{
+				&validatingReporter{checkingReporter{name: "slackreporter"}},
+				&validatingReporter{checkingReporter{name: "dingtalkreporter"}},
+			},
+			expectedOutput: "slackreporter: OK\ndingtalkreporter: OK\n",
+		},
+		{
+			name: "invalid config fails",
+			reporters: []ReportClient{
+				&validatingReporter{checkingReporter{name: "slackreporter", err: errors.New("channel #foo not found")}},
+				&validatingReporter{checkingReporter{name: "dingtalkreporter"}},
+			},
+			expectedOutput: "slackreporter: FAILED\n  channel #foo not found\ndingtalkreporter: OK\n",
+			expectedErr:    true,
+		},
+		{
+			name: "reporters without validation are skipped",
+			reporters: []ReportClient{
+				&checkingReporter{name: "pubsubreporter", err: errors.New("not validated")},
+			},
+			expectedOutput: "pubsubreporter: SKIPPED (no validation)\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := ValidateReporters(context.Background(), &out, tc.reporters)
+			if (err != nil) != tc.expectedErr {
+				t.Errorf("expected error: %t, got: %v", tc.expectedErr, err)
+			}
+			if diff := cmp.Diff(tc.expectedOutput, out.String()); diff != "" {
+				t.Errorf("output differs from expected (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
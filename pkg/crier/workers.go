@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"sync"
+)
+
+// maxWorkers is the concurrency a controller is started with if its number
+// of workers can be changed at runtime, as the concurrency of a controller
+// can't change once it's started. Configured worker counts are capped to it
+// unless more workers were requested via flags.
+const maxWorkers = 100
+
+// workerLimiter limits the number of reconciles that run concurrently to a
+// value that is looked up on every reconcile, so that it follows config
+// reloads.
+type workerLimiter struct {
+	reporter       string
+	defaultWorkers int
+	max            int
+	overrides      func() map[string]int
+
+	lock   sync.Mutex
+	cond   *sync.Cond
+	active int
+}
+
+func newWorkerLimiter(reporter string, defaultWorkers int, overrides func() map[string]int) *workerLimiter {
+	l := &workerLimiter{
+		reporter:       reporter,
+		defaultWorkers: defaultWorkers,
+		max:            max(defaultWorkers, maxWorkers),
+		overrides:      overrides,
+	}
+	l.cond = sync.NewCond(&l.lock)
+	return l
+}
+
+// limit returns the number of workers currently configured for the reporter.
+func (l *workerLimiter) limit() int {
+	workers := l.defaultWorkers
+	if configured, ok := l.overrides()[l.reporter]; ok {
+		workers = configured
+	}
+	return min(max(workers, 1), l.max)
+}
+
+// acquire blocks until fewer reconciles than the configured number of
+// workers are running.
+func (l *workerLimiter) acquire() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	// The limit is at least one, so there is always a running reconcile
+	// that wakes us up once it releases.
+	for l.active >= l.limit() {
+		l.cond.Wait()
+	}
+	l.active++
+}
+
+// release marks a reconcile started by acquire as done.
+func (l *workerLimiter) release() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.active--
+	l.cond.Broadcast()
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWorkerLimiterLimit(t *testing.T) {
+	testCases := []struct {
+		name           string
+		defaultWorkers int
+		overrides      map[string]int
+		expected       int
+	}{
+		{
+			name:           "not configured, flag value is used",
+			defaultWorkers: 5,
+			expected:       5,
+		},
+		{
+			name:           "other reporter configured, flag value is used",
+			defaultWorkers: 5,
+			overrides:      map[string]int{"other": 2},
+			expected:       5,
+		},
+		{
+			name:           "configured value is used",
+			defaultWorkers: 5,
+			overrides:      map[string]int{"test-reporter": 2},
+			expected:       2,
+		},
+		{
+			name:           "configured value is capped",
+			defaultWorkers: 5,
+			overrides:      map[string]int{"test-reporter": maxWorkers + 1},
+			expected:       maxWorkers,
+		},
+		{
+			name:           "more workers than the cap can be requested via flag",
+			defaultWorkers: maxWorkers + 10,
+			overrides:      map[string]int{"test-reporter": maxWorkers + 5},
+			expected:       maxWorkers + 5,
+		},
+		{
+			name:           "at least one worker is used",
+			defaultWorkers: 5,
+			overrides:      map[string]int{"test-reporter": 0},
+			expected:       1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			l := newWorkerLimiter("test-reporter", tc.defaultWorkers, func() map[string]int { return tc.overrides })
+			if limit := l.limit(); limit != tc.expected {
+				t.Errorf("expected limit %d, got %d", tc.expected, limit)
+			}
+		})
+	}
+}
+
+func TestWorkerLimiterFollowsConfig(t *testing.T) {
+	var lock sync.Mutex
+	overrides := map[string]int{"test-reporter": 1}
+	l := newWorkerLimiter("test-reporter", 5, func() map[string]int {
+		lock.Lock()
+		defer lock.Unlock()
+		return overrides
+	})
+
+	l.acquire()
+	acquired := make(chan struct{})
+	go func() {
+		l.acquire()
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("expected second acquire to block with a single worker")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	l.release()
+	<-acquired
+
+	// Raising the limit lets more reconciles run concurrently.
+	lock.Lock()
+	overrides = map[string]int{"test-reporter": 3}
+	lock.Unlock()
+	done := make(chan struct{})
+	go func() {
+		l.acquire()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected acquire to succeed after the limit was raised")
+	}
+}
@@ -18,12 +18,18 @@ package dingtalk
 
 import (
 	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
@@ -38,7 +44,9 @@ type Logger interface {
 type Client struct {
 	// If logger is non-nil, log all method calls with it.
 	logger Logger
-	fake   bool
+
+	httpClient *http.Client
+	fake       bool
 }
 
 type dingTalkMsg struct {
@@ -56,10 +64,15 @@ const (
 	chatPostMessage = "https://oapi.dingtalk.com/robot/send"
 )
 
-// NewClient creates a slack client with an API token.
-func NewClient() *Client {
+// NewClient creates a slack client with an API token. If httpClient is nil,
+// http.DefaultClient is used.
+func NewClient(httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
 	return &Client{
-		logger: logrus.WithField("client", "dingTalk"),
+		logger:     logrus.WithField("client", "dingTalk"),
+		httpClient: httpClient,
 	}
 }
 
@@ -81,10 +94,25 @@ func (sl *Client) log(methodName string, args ...interface{}) {
 	sl.logger.Debugf("%s(%s)", methodName, strings.Join(as, ", "))
 }
 
-func (sl *Client) postMessage(msg, token string) error {
+// sign returns the signature of a message sent at timestamp, in
+// milliseconds since the epoch, as specified by DingTalk: the base64 encoded
+// HMAC-SHA256 of "<timestamp>\n<secret>" keyed with the secret.
+func sign(secret []byte, timestamp int64) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "\n"))
+	mac.Write(secret)
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (sl *Client) postMessage(ctx context.Context, msg, token string, secret []byte) error {
 	u, _ := url.Parse(chatPostMessage)
 	var uv = url.Values{}
 	uv.Add("access_token", token)
+	if len(secret) > 0 {
+		timestamp := time.Now().UnixMilli()
+		uv.Add("timestamp", strconv.FormatInt(timestamp, 10))
+		uv.Add("sign", sign(secret, timestamp))
+	}
 	u.RawQuery = uv.Encode()
 
 	dtMsg := dingTalkMsg{
@@ -100,7 +128,12 @@ func (sl *Client) postMessage(msg, token string) error {
 		return err
 	}
 
-	resp, err := http.Post(u.String(), "application/json", &buf)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := sl.httpClient.Do(req)
 	if err != nil {
 		return err
 	}
@@ -125,13 +158,27 @@ func (sl *Client) postMessage(msg, token string) error {
 }
 
 // WriteMessage adds text to channel
-func (sl *Client) WriteMessage(msg, token string) error {
+func (sl *Client) WriteMessage(ctx context.Context, msg, token string) error {
 	sl.log("WriteMessage", msg, token)
 	if sl.fake {
 		return nil
 	}
 
-	if err := sl.postMessage(msg, token); err != nil {
+	if err := sl.postMessage(ctx, msg, token, nil); err != nil {
+		return fmt.Errorf("failed to post message to %s: %w", token, err)
+	}
+	return nil
+}
+
+// WriteSignedMessage adds text to channel, signing the message with the
+// secret of the bot's "Additional Signature" security setting.
+func (sl *Client) WriteSignedMessage(ctx context.Context, msg, token string, secret []byte) error {
+	sl.log("WriteSignedMessage", msg, token)
+	if sl.fake {
+		return nil
+	}
+
+	if err := sl.postMessage(ctx, msg, token, secret); err != nil {
 		return fmt.Errorf("failed to post message to %s: %w", token, err)
 	}
 	return nil
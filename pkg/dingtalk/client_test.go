@@ -0,0 +1,45 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dingtalk
+
+import "testing"
+
+func TestSign(t *testing.T) {
+	testCases := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		expected  string
+	}{
+		{
+			// Computed independently following the Python example of
+			// DingTalk's documentation.
+			name:      "test vector",
+			secret:    "SEC0123456789abcdef",
+			timestamp: 1700000000000,
+			expected:  "TSZbRFUuvaSQaRKUpF970OPCb2/LcQAP3wOvwZIzBZk=",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := sign([]byte(tc.secret), tc.timestamp); got != tc.expected {
+				t.Errorf("expected signature %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
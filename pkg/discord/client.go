@@ -0,0 +1,88 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package discord provides a client for posting messages to Discord
+// webhooks.
+package discord
+
+import (
+	"context"
+	"net/http"
+
+	"sigs.k8s.io/prow/pkg/webhookclient"
+)
+
+// MaxContentLength is the maximum number of characters Discord accepts in
+// the content of a message.
+const MaxContentLength = 2000
+
+// Message is the payload accepted by Discord webhooks.
+// See https://discord.com/developers/docs/resources/webhook#execute-webhook
+type Message struct {
+	Content string  `json:"content,omitempty"`
+	Embeds  []Embed `json:"embeds,omitempty"`
+}
+
+// Embed is a rich content block attached to a message.
+type Embed struct {
+	Title string `json:"title,omitempty"`
+	URL   string `json:"url,omitempty"`
+	Color int    `json:"color,omitempty"`
+}
+
+// NewMessage returns a Message with the given content and a single embed.
+// Content that exceeds MaxContentLength is truncated with an ellipsis.
+func NewMessage(content string, embed Embed) *Message {
+	return &Message{
+		Content: truncate(content, MaxContentLength),
+		Embeds:  []Embed{embed},
+	}
+}
+
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-1]) + "…"
+}
+
+// Client allows you to post messages to Discord channels. Channels are
+// resolved to webhook URLs using a secret mapping of channel name to URL.
+type Client struct {
+	webhooks *webhookclient.Client
+}
+
+// NewClient creates a Discord client. The webhooksGenerator must return a
+// YAML or JSON map of channel names to webhook URLs. If httpClient is nil,
+// a client with webhookclient.DefaultTimeout is used.
+func NewClient(webhooksGenerator func() []byte, httpClient *http.Client) *Client {
+	return &Client{
+		webhooks: webhookclient.NewClient("discord", webhooksGenerator, httpClient),
+	}
+}
+
+// NewFakeClient returns a client that takes no actions.
+func NewFakeClient() *Client {
+	return &Client{
+		webhooks: webhookclient.NewFakeClient(),
+	}
+}
+
+// WriteMessage posts the message to the channel.
+func (c *Client) WriteMessage(ctx context.Context, msg *Message, channel string) error {
+	return c.webhooks.Post(ctx, channel, nil, msg)
+}
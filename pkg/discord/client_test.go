@@ -0,0 +1,88 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestNewMessageTruncatesContent(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "short content is kept",
+			content:  "job failed",
+			expected: "job failed",
+		},
+		{
+			name:     "content at the limit is kept",
+			content:  strings.Repeat("a", MaxContentLength),
+			expected: strings.Repeat("a", MaxContentLength),
+		},
+		{
+			name:     "long content is truncated with an ellipsis",
+			content:  strings.Repeat("a", MaxContentLength+1),
+			expected: strings.Repeat("a", MaxContentLength-1) + "…",
+		},
+		{
+			name:     "multi-byte characters are counted as one",
+			content:  strings.Repeat("ü", MaxContentLength+10),
+			expected: strings.Repeat("ü", MaxContentLength-1) + "…",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			msg := NewMessage(tc.content, Embed{})
+			if msg.Content != tc.expected {
+				t.Errorf("expected content of length %d, got length %d", utf8.RuneCountInString(tc.expected), utf8.RuneCountInString(msg.Content))
+			}
+		})
+	}
+}
+
+func TestWriteMessage(t *testing.T) {
+	var received Message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	c := NewClient(func() []byte { return []byte("oncall: " + server.URL) }, nil)
+	if err := c.WriteMessage(context.Background(), NewMessage("hello", Embed{Title: "title"}), "oncall"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if received.Content != "hello" || len(received.Embeds) != 1 || received.Embeds[0].Title != "title" {
+		t.Errorf("unexpected message received: %+v", received)
+	}
+
+	if err := c.WriteMessage(context.Background(), NewMessage("hello", Embed{}), "unknown"); err == nil {
+		t.Error("expected error for unknown channel")
+	}
+}
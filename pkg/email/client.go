@@ -0,0 +1,232 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package email provides a client for sending HTML emails through an SMTP
+// server.
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/yaml"
+)
+
+// Logger provides an interface to log debug messages.
+type Logger interface {
+	Debugf(s string, v ...interface{})
+}
+
+// Message is an HTML email.
+type Message struct {
+	To       []string
+	Subject  string
+	HTMLBody string
+}
+
+// Bytes returns the RFC 5322 representation of the message.
+func (m *Message) Bytes(from string) []byte {
+	// Header values must not contain line breaks.
+	subject := strings.NewReplacer("\r", " ", "\n", " ").Replace(m.Subject)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(m.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/html; charset=\"UTF-8\"\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(m.HTMLBody)
+	return b.Bytes()
+}
+
+// ConnectionError is returned when the SMTP server could not be reached.
+type ConnectionError struct {
+	err error
+}
+
+func (e *ConnectionError) Error() string {
+	return fmt.Sprintf("failed to connect to SMTP server: %v", e.err)
+}
+
+func (e *ConnectionError) Unwrap() error {
+	return e.err
+}
+
+// IsConnectionError returns whether the error is a ConnectionError.
+func IsConnectionError(err error) bool {
+	var connErr *ConnectionError
+	return errors.As(err, &connErr)
+}
+
+// Credentials are the credentials used to authenticate with the SMTP server.
+type Credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// ServerOptions describe how to reach the SMTP server.
+type ServerOptions struct {
+	Host string
+	Port int
+	From string
+	// ImplicitTLS makes the client connect using TLS right away, which is
+	// usually done on port 465. Otherwise STARTTLS is used if the server
+	// supports it.
+	ImplicitTLS bool
+}
+
+// Client allows you to send emails.
+type Client struct {
+	// If logger is non-nil, log all method calls with it.
+	logger Logger
+
+	opts                 ServerOptions
+	credentialsGenerator func() []byte
+	fake                 bool
+}
+
+// NewClient creates an email client. If credentialsGenerator is non-nil it
+// must return YAML or JSON Credentials.
+func NewClient(opts ServerOptions, credentialsGenerator func() []byte) *Client {
+	return &Client{
+		logger:               logrus.WithField("client", "email"),
+		opts:                 opts,
+		credentialsGenerator: credentialsGenerator,
+	}
+}
+
+// NewFakeClient returns a client that takes no actions.
+func NewFakeClient() *Client {
+	return &Client{
+		fake: true,
+	}
+}
+
+func (c *Client) log(methodName string, args ...interface{}) {
+	if c.logger == nil {
+		return
+	}
+	var as []string
+	for _, arg := range args {
+		as = append(as, fmt.Sprintf("%v", arg))
+	}
+	c.logger.Debugf("%s(%s)", methodName, strings.Join(as, ", "))
+}
+
+// From returns the address emails are sent from.
+func (c *Client) From() string {
+	return c.opts.From
+}
+
+func (c *Client) dial(ctx context.Context) (*smtp.Client, error) {
+	addr := net.JoinHostPort(c.opts.Host, strconv.Itoa(c.opts.Port))
+	tlsConfig := &tls.Config{ServerName: c.opts.Host}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	// The SMTP client doesn't take a context, so the conversation with the
+	// server is bounded by a deadline on the connection instead.
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	if c.opts.ImplicitTLS {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	client, err := smtp.NewClient(conn, c.opts.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if c.opts.ImplicitTLS {
+		return client, nil
+	}
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+	return client, nil
+}
+
+// Send sends the message. Sending fails once the deadline of ctx passed.
+func (c *Client) Send(ctx context.Context, msg *Message) error {
+	c.log("Send", msg.Subject, msg.To)
+	if c.fake {
+		return nil
+	}
+
+	client, err := c.dial(ctx)
+	if err != nil {
+		return &ConnectionError{err: err}
+	}
+	defer client.Close()
+
+	if c.credentialsGenerator != nil {
+		var creds Credentials
+		if err := yaml.Unmarshal(c.credentialsGenerator(), &creds); err != nil {
+			return fmt.Errorf("failed to parse credentials: %w", err)
+		}
+		if err := client.Auth(smtp.PlainAuth("", creds.Username, creds.Password, c.opts.Host)); err != nil {
+			return fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+
+	from, err := mail.ParseAddress(c.opts.From)
+	if err != nil {
+		return fmt.Errorf("invalid from address: %w", err)
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	for _, to := range msg.To {
+		addr, err := mail.ParseAddress(to)
+		if err != nil {
+			return fmt.Errorf("invalid recipient %q: %w", to, err)
+		}
+		if err := client.Rcpt(addr.Address); err != nil {
+			return fmt.Errorf("failed to add recipient %s: %w", to, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg.Bytes(c.opts.From)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package email
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMessageBytes(t *testing.T) {
+	msg := &Message{
+		To:       []string{"a@example.com", "b@example.com"},
+		Subject:  "job\r\nfailed",
+		HTMLBody: "<p>failed</p>",
+	}
+	expected := "From: prow@example.com\r\n" +
+		"To: a@example.com, b@example.com\r\n" +
+		"Subject: job  failed\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/html; charset=\"UTF-8\"\r\n" +
+		"\r\n" +
+		"<p>failed</p>"
+	if diff := cmp.Diff(expected, string(msg.Bytes("prow@example.com"))); diff != "" {
+		t.Errorf("message differs from expected: %s", diff)
+	}
+}
+
+func TestSendConnectionError(t *testing.T) {
+	// Grab a free port and close it again so nothing is listening on it.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+
+	c := NewClient(ServerOptions{Host: "127.0.0.1", Port: port, From: "prow@example.com"}, nil)
+	err = c.Send(context.Background(), &Message{To: []string{"a@example.com"}})
+	if !IsConnectionError(err) {
+		t.Errorf("expected connection error, got: %v", err)
+	}
+}
+
+func TestSendHonoursDeadline(t *testing.T) {
+	// The server accepts connections but never sends its greeting.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	c := NewClient(ServerOptions{Host: "127.0.0.1", Port: l.Addr().(*net.TCPAddr).Port, From: "prow@example.com"}, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- c.Send(ctx, &Message{To: []string{"a@example.com"}}) }()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("expected error when the deadline passed")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Send didn't return after the deadline passed")
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
@@ -65,6 +66,10 @@ type GitHubOptions struct {
 	max404Retries  int
 	initialDelay   time.Duration
 	maxSleepTime   time.Duration
+
+	// BaseRoundTripper is the transport of the clients, e.g. to send their
+	// requests through a proxy. Defaults to http.DefaultTransport.
+	BaseRoundTripper http.RoundTripper
 }
 
 type throttlerSettings struct {
@@ -314,6 +319,8 @@ func (o *GitHubOptions) baseClientOptions() github.ClientOptions {
 		MaxSleepTime:    o.maxSleepTime,
 		MaxRetries:      o.maxRetries,
 		Max404Retries:   o.max404Retries,
+
+		BaseRoundTripper: o.BaseRoundTripper,
 	}
 }
 
@@ -20,6 +20,7 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"net/http"
 	"net/url"
 
 	"sigs.k8s.io/prow/pkg/config/secret"
@@ -42,6 +43,11 @@ type JiraOptions struct {
 	username        string
 	passwordFile    string
 	bearerTokenFile string
+
+	// BaseRoundTripper is the transport of the client, e.g. to send its
+	// requests through a proxy. Defaults to the transport of the Jira
+	// client.
+	BaseRoundTripper http.RoundTripper
 }
 
 // JiraNoBasicAuth disables the presence of the basic auth flags
@@ -125,6 +131,9 @@ func (o *JiraOptions) Client() (jira.Client, error) {
 	}
 
 	var opts []jira.Option
+	if o.BaseRoundTripper != nil {
+		opts = append(opts, jira.WithTransport(o.BaseRoundTripper))
+	}
 	if o.passwordFile != "" {
 		if err := secret.Add(o.passwordFile); err != nil {
 			return nil, fmt.Errorf("failed to get --jira-password-file: %w", err)
@@ -0,0 +1,76 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"net/url"
+	"strings"
+)
+
+// gerritTokens holds the tokens to authenticate to Gerrit with by the
+// domain of their cookie. The token under the empty domain is used for
+// hosts no cookie matches.
+type gerritTokens map[string]string
+
+// forInstance returns the token to authenticate to the given instance with:
+// the token of a cookie for the instance's host, else the token of a cookie
+// for the closest of its parent domains, else the default token.
+func (t gerritTokens) forInstance(instance string) string {
+	host := instance
+	if u, err := url.Parse(instance); err == nil && u.Host != "" {
+		host = u.Hostname()
+	}
+	if token, ok := t[host]; ok {
+		return token
+	}
+	for domain := host; ; {
+		// Cookies with a leading dot apply to subdomains as well.
+		if token, ok := t["."+domain]; ok {
+			return token
+		}
+		var found bool
+		if _, domain, found = strings.Cut(domain, "."); !found {
+			break
+		}
+	}
+	return t[""]
+}
+
+// parseCookiefile returns the tokens of the cookies in a file in the
+// Netscape cookie format, like the .gitcookies files that authenticate
+// to several Gerrit hosts at once:
+//
+//	.googlesource.com	TRUE	/	TRUE	2147483647	o	git-user=token
+//
+// The last token of the file is the default token, so that files with a
+// single token that isn't in the cookie format keep working for all hosts.
+func parseCookiefile(raw string) gerritTokens {
+	tokens := gerritTokens{}
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimPrefix(strings.TrimSpace(line), "#HttpOnly_")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if fields := strings.Fields(line); len(fields) == 7 {
+			tokens[fields[0]] = fields[6]
+		}
+	}
+	if fields := strings.Fields(raw); len(fields) > 0 {
+		tokens[""] = fields[len(fields)-1]
+	}
+	return tokens
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	gerrit "github.com/andygrunwald/go-gerrit"
+	"github.com/google/go-cmp/cmp"
+)
+
+const twoHostCookiefile = `# Netscape HTTP Cookie File
+#HttpOnly_beta-review.example.org	FALSE	/	TRUE	2147483647	o	git-bot=beta-token
+.alpha.example.com	TRUE	/	TRUE	2147483647	o	git-bot=alpha-token
+`
+
+func TestParseCookiefile(t *testing.T) {
+	testCases := []struct {
+		name     string
+		raw      string
+		expected gerritTokens
+	}{
+		{
+			name: "cookies of several hosts",
+			raw:  twoHostCookiefile,
+			expected: gerritTokens{
+				".alpha.example.com":      "git-bot=alpha-token",
+				"beta-review.example.org": "git-bot=beta-token",
+				"":                        "git-bot=alpha-token",
+			},
+		},
+		{
+			name:     "bare token",
+			raw:      "git-bot=token\n",
+			expected: gerritTokens{"": "git-bot=token"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, parseCookiefile(tc.raw)); diff != "" {
+				t.Errorf("tokens differ from expected (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTokensForInstance(t *testing.T) {
+	tokens := parseCookiefile(twoHostCookiefile)
+	testCases := []struct {
+		instance string
+		expected string
+	}{
+		{instance: "https://alpha.example.com", expected: "git-bot=alpha-token"},
+		{instance: "https://review.alpha.example.com:8443", expected: "git-bot=alpha-token"},
+		{instance: "https://beta-review.example.org", expected: "git-bot=beta-token"},
+		// The cookie of beta-review.example.org doesn't apply to
+		// subdomains, which get the default token instead.
+		{instance: "https://sub.beta-review.example.org", expected: "git-bot=alpha-token"},
+		{instance: "https://unknown.example.net", expected: "git-bot=alpha-token"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.instance, func(t *testing.T) {
+			if token := tokens.forInstance(tc.instance); token != tc.expected {
+				t.Errorf("expected token %q, got %q", tc.expected, token)
+			}
+		})
+	}
+}
+
+type fakeAuth struct {
+	cookies map[string]string
+}
+
+func (f *fakeAuth) SetCookieAuth(name, value string) {
+	f.cookies[name] = value
+}
+
+type fakeReviewer struct {
+	reviews []string
+	gerritChange
+}
+
+func (f *fakeReviewer) SetReview(changeID, revisionID string, input *gerrit.ReviewInput) (*gerrit.ReviewResult, *gerrit.Response, error) {
+	f.reviews = append(f.reviews, changeID)
+	return &gerrit.ReviewResult{}, nil, nil
+}
+
+func TestAuthenticateAndRouteToSeveralHosts(t *testing.T) {
+	const alpha, beta = "https://review.alpha.example.com", "https://beta-review.example.org"
+	auths := map[string]*fakeAuth{alpha: {cookies: map[string]string{}}, beta: {cookies: map[string]string{}}}
+	reviewers := map[string]*fakeReviewer{alpha: {}, beta: {}}
+	c := &Client{
+		handlers: map[string]*gerritInstanceHandler{},
+		authentication: func() (gerritTokens, error) {
+			return parseCookiefile(twoHostCookiefile), nil
+		},
+	}
+	for _, instance := range []string{alpha, beta} {
+		c.handlers[instance] = &gerritInstanceHandler{
+			instance:      instance,
+			authService:   auths[instance],
+			changeService: reviewers[instance],
+		}
+	}
+
+	c.authenticateOnce()
+	if cookie := auths[alpha].cookies["o"]; cookie != "git-bot=alpha-token" {
+		t.Errorf("expected %s to be authenticated with its own token, got %q", alpha, cookie)
+	}
+	if cookie := auths[beta].cookies["o"]; cookie != "git-bot=beta-token" {
+		t.Errorf("expected %s to be authenticated with its own token, got %q", beta, cookie)
+	}
+
+	if err := c.SetReview(alpha, "alpha-change", "rev", "msg", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.SetReview(beta, "beta-change", "rev", "msg", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"alpha-change"}, reviewers[alpha].reviews); diff != "" {
+		t.Errorf("unexpected reviews on %s (-want +got):\n%s", alpha, diff)
+	}
+	if diff := cmp.Diff([]string{"beta-change"}, reviewers[beta].reviews); diff != "" {
+		t.Errorf("unexpected reviews on %s (-want +got):\n%s", beta, diff)
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"os"
 	"sort"
@@ -126,8 +127,8 @@ type Client struct {
 
 	httpClient http.Client
 
-	authentication func() (string, error)
-	previousToken  string
+	authentication func() (gerritTokens, error)
+	previousTokens gerritTokens
 	lock           sync.RWMutex
 }
 
@@ -240,18 +241,18 @@ func (c *Client) authenticateOnce() {
 		logrus.WithError(err).Error("Failed to read gerrit auth token")
 	}
 
-	if current == c.previousToken {
+	if maps.Equal(current, c.previousTokens) {
 		return
 	}
 
 	logrus.Info("New gerrit token, updating handler authentication...")
 	c.lock.Lock()
-	c.previousToken = current // We need the write lock for this.
+	c.previousTokens = current // We need the write lock for this.
 	c.lock.Unlock()
 
 	// update auth token for each instance
-	for _, handler := range c.getAllHandlers() {
-		handler.authService.SetCookieAuth("o", current)
+	for instance, handler := range c.getAllHandlers() {
+		handler.authService.SetCookieAuth("o", current.forInstance(instance))
 	}
 }
 
@@ -268,9 +269,11 @@ func (c *Client) getAllHandlers() map[string]*gerritInstanceHandler {
 
 // Authenticate client calls using the specified file.
 // Periodically re-reads the file to check for an updated value.
-// cookiefilePath takes precedence over tokenPath if both are set.
+// cookiefilePath takes precedence over tokenPath if both are set. Each
+// instance is authenticated with the cookie of its host, so one cookiefile
+// can hold the credentials of several Gerrit hosts.
 func (c *Client) Authenticate(cookiefilePath, tokenPath string) {
-	var was, auth func() (string, error)
+	var was, auth func() (gerritTokens, error)
 	switch {
 	case cookiefilePath != "":
 		if tokenPath != "" {
@@ -279,23 +282,21 @@ func (c *Client) Authenticate(cookiefilePath, tokenPath string) {
 				"token":      tokenPath,
 			}).Warn("Ignoring token path in favor of cookiefile")
 		}
-		auth = func() (string, error) {
+		auth = func() (gerritTokens, error) {
 			// TODO(fejta): listen for changes
 			raw, err := os.ReadFile(cookiefilePath)
 			if err != nil {
-				return "", fmt.Errorf("read cookie: %w", err)
+				return nil, fmt.Errorf("read cookie: %w", err)
 			}
-			fields := strings.Fields(string(raw))
-			token := fields[len(fields)-1]
-			return token, nil
+			return parseCookiefile(string(raw)), nil
 		}
 	case tokenPath != "":
-		auth = func() (string, error) {
+		auth = func() (gerritTokens, error) {
 			raw, err := os.ReadFile(tokenPath)
 			if err != nil {
-				return "", fmt.Errorf("read token: %w", err)
+				return nil, fmt.Errorf("read token: %w", err)
 			}
-			return strings.TrimSpace(string(raw)), nil
+			return gerritTokens{"": strings.TrimSpace(string(raw))}, nil
 		}
 	default:
 		logrus.Info("Using anonymous authentication to gerrit")
@@ -353,6 +354,11 @@ func (c *Client) UpdateClients(instances map[string]map[string]*config.GerritQue
 			errs = append(errs, err)
 			continue
 		}
+		// Instances added after the client authenticated are only
+		// authenticated here, as the tokens didn't change since.
+		if c.previousTokens != nil {
+			handler.authService.SetCookieAuth("o", c.previousTokens.forInstance(instance))
+		}
 		newHandlers[instance] = handler
 	}
 	c.handlers = newHandlers
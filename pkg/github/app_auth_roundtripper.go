@@ -90,10 +90,15 @@ func (arr *appsRoundTripper) canonicalizedPath(url *url.URL) string {
 
 var installationPath = regexp.MustCompile(`^/repos/[^/]+/[^/]+/installation$`)
 
-func (arr *appsRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
-	path := arr.canonicalizedPath(r.URL)
+// usesAppJWT tells whether requests to the path are authenticated as the app
+// rather than as one of its installations.
+func usesAppJWT(path string) bool {
 	// We need to use a JWT when we are getting /app/* endpoints or installation information for a particular repo
-	if strings.HasPrefix(path, "/app") || installationPath.MatchString(path) {
+	return strings.HasPrefix(path, "/app") || installationPath.MatchString(path)
+}
+
+func (arr *appsRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	if usesAppJWT(arr.canonicalizedPath(r.URL)) {
 		if err := arr.addAppAuth(r); err != nil {
 			return nil, err
 		}
@@ -42,6 +42,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	"sigs.k8s.io/prow/pkg/ghcache"
+	"sigs.k8s.io/prow/pkg/github/ghmetrics"
 	"sigs.k8s.io/prow/pkg/throttle"
 	"sigs.k8s.io/prow/pkg/version"
 )
@@ -848,6 +849,25 @@ func NewNotFound() error {
 	}
 }
 
+// NewRequestError returns the error of a request that failed with the
+// given status code, which may be useful for tests.
+func NewRequestError(statusCode int, message string) error {
+	return requestError{
+		StatusCode:  statusCode,
+		ErrorString: message,
+	}
+}
+
+// StatusCode returns the HTTP status code of the failed request the error
+// originates from, or 0 if it doesn't originate from a failed request.
+func StatusCode(err error) int {
+	var requestErr requestError
+	if !errors.As(err, &requestErr) {
+		return 0
+	}
+	return requestErr.StatusCode
+}
+
 func IsNotFound(err error) bool {
 	if err == nil {
 		return false
@@ -944,6 +964,7 @@ func (c *client) requestRetryWithContext(ctx context.Context, method, path, acce
 		}
 		resp, err = c.doRequest(ctx, method, c.bases[hostIndex]+path, accept, org, body)
 		if err == nil {
+			ghmetrics.CollectGitHubRateLimitRemaining(c.tokenBudgetIdentifier(path, org), resp.Header)
 			if resp.StatusCode == 404 && retries < c.max404Retries {
 				// Retry 404s a couple times. Sometimes GitHub is inconsistent in
 				// the sense that they send us an event such as "PR opened" but an
@@ -1161,6 +1182,27 @@ func init() {
 	prometheus.MustRegister(userInfo)
 }
 
+// tokenHash returns the hash of the authorization header, which labels the
+// metrics of the token.
+func (c *client) tokenHash() string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(c.authHeader()))) // use %x to make this a utf-8 string for use as a label
+}
+
+// tokenBudgetIdentifier returns the label of the token budget a request to
+// the path on behalf of the org counts against. With GitHub App auth the
+// client has no token of its own: every app installation has its own budget,
+// identified by its org, and requests authenticated as the app itself count
+// against the budget of the app.
+func (c *client) tokenBudgetIdentifier(path, org string) string {
+	if !c.usesAppsAuth {
+		return c.tokenHash()
+	}
+	if usesAppJWT(path) {
+		return "app"
+	}
+	return "installation:" + org
+}
+
 // Not thread-safe - callers need to hold c.mut.
 func (c *client) getUserData(ctx context.Context) error {
 	if c.delegate.usesAppsAuth {
@@ -1195,8 +1237,7 @@ func (c *client) getUserData(ctx context.Context) error {
 	// https://developer.github.com/v3/users/#get-a-single-user
 
 	// record information for the user
-	authHeaderHash := fmt.Sprintf("%x", sha256.Sum256([]byte(c.authHeader()))) // use %x to make this a utf-8 string for use as a label
-	userInfo.With(prometheus.Labels{"token_hash": authHeaderHash, "login": c.userData.Login, "email": c.userData.Email}).Set(1)
+	userInfo.With(prometheus.Labels{"token_hash": c.tokenHash(), "login": c.userData.Login, "email": c.userData.Email}).Set(1)
 	return nil
 }
 
@@ -36,6 +36,7 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/shurcooL/githubv4"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -111,6 +112,83 @@ func TestRequestRateLimit(t *testing.T) {
 	}
 }
 
+func TestRateLimitRemaining(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/search" {
+			w.Header().Set("X-RateLimit-Resource", "search")
+			w.Header().Set("X-RateLimit-Remaining", "29")
+			return
+		}
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+	}))
+	defer ts.Close()
+	c := getClient(ts.URL)
+	for _, path := range []string{"/", "/search"} {
+		if _, err := c.requestRetry(http.MethodGet, path, "", "", nil); err != nil {
+			t.Fatalf("Error from request: %v", err)
+		}
+	}
+	if expected, remaining := map[string]float64{"core": 4999, "search": 29}, rateLimitRemaining(t, c.tokenHash()); !reflect.DeepEqual(remaining, expected) {
+		t.Errorf("Expected remaining requests %v, got %v", expected, remaining)
+	}
+}
+
+func TestRateLimitRemainingAppsAuth(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/app":
+			w.Header().Set("X-RateLimit-Remaining", "4000")
+		case "/orgs/org-a":
+			w.Header().Set("X-RateLimit-Remaining", "3000")
+		case "/orgs/org-b":
+			w.Header().Set("X-RateLimit-Remaining", "2000")
+		}
+	}))
+	defer ts.Close()
+	c := getClient(ts.URL)
+	c.usesAppsAuth = true
+	for path, org := range map[string]string{"/app": "", "/orgs/org-a": "org-a", "/orgs/org-b": "org-b"} {
+		if _, err := c.requestRetry(http.MethodGet, path, "", org, nil); err != nil {
+			t.Fatalf("Error from request: %v", err)
+		}
+	}
+	expected := map[string]map[string]float64{
+		"app":                {"core": 4000},
+		"installation:org-a": {"core": 3000},
+		"installation:org-b": {"core": 2000},
+	}
+	for identifier, expectedRemaining := range expected {
+		if remaining := rateLimitRemaining(t, identifier); !reflect.DeepEqual(remaining, expectedRemaining) {
+			t.Errorf("Expected remaining requests %v for %s, got %v", expectedRemaining, identifier, remaining)
+		}
+	}
+}
+
+// rateLimitRemaining returns the remaining requests per rate limit resource
+// of the token budget.
+func rateLimitRemaining(t *testing.T, tokenBudget string) map[string]float64 {
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("Error gathering metrics: %v", err)
+	}
+	remaining := map[string]float64{}
+	for _, family := range families {
+		if family.GetName() != "github_rate_limit_remaining" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			if labels["token_hash"] == tokenBudget {
+				remaining[labels["ratelimit_resource"]] = m.GetGauge().GetValue()
+			}
+		}
+	}
+	return remaining
+}
+
 func TestAbuseRateLimit(t *testing.T) {
 	tc := &testTime{now: time.Now()}
 	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -1077,6 +1155,39 @@ func TestIsNotFound_nested(t *testing.T) {
 
 }
 
+func TestStatusCode(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{
+			name:     "request error",
+			err:      NewRequestError(http.StatusUnprocessableEntity, "validation failed"),
+			expected: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "wrapped request error",
+			err:      fmt.Errorf("wrapping: %w", NewRequestError(http.StatusBadGateway, "bad gateway")),
+			expected: http.StatusBadGateway,
+		},
+		{
+			name:     "other error",
+			err:      errors.New("connection reset"),
+			expected: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if code := StatusCode(tc.err); code != tc.expected {
+				t.Errorf("expected status code %d, got %d", tc.expected, code)
+			}
+		})
+	}
+}
+
 func TestAssignIssue(t *testing.T) {
 	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
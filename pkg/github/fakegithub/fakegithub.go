@@ -61,6 +61,9 @@ type FakeClient struct {
 	IssueEvents                map[int][]github.ListedIssueEvent
 	Commits                    map[string]github.RepositoryCommit
 
+	// CheckRuns is a map of org/repo to the check runs created in the repo
+	CheckRuns map[string][]github.CheckRun
+
 	// All Labels That Exist In The Repo
 	RepoLabelsExisting []string
 	// org/repo#number:label
@@ -1201,3 +1204,61 @@ func (f *FakeClient) RequestReview(org, repo string, number int, logins []string
 	f.ReviewersRequested = logins
 	return nil
 }
+
+// ListCheckRuns lists the check runs of the given ref.
+func (f *FakeClient) ListCheckRuns(org, repo, ref string) (*github.CheckRunList, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	list := &github.CheckRunList{}
+	for _, cr := range f.CheckRuns[org+"/"+repo] {
+		if cr.HeadSHA == ref {
+			list.CheckRuns = append(list.CheckRuns, cr)
+		}
+	}
+	list.Total = len(list.CheckRuns)
+	return list, nil
+}
+
+// CreateCheckRun creates a check run and returns its ID.
+func (f *FakeClient) CreateCheckRun(org, repo string, checkRun github.CheckRun) (int64, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.CheckRuns == nil {
+		f.CheckRuns = map[string][]github.CheckRun{}
+	}
+	key := org + "/" + repo
+	checkRun.ID = int64(len(f.CheckRuns[key]) + 1)
+	f.CheckRuns[key] = append(f.CheckRuns[key], checkRun)
+	return checkRun.ID, nil
+}
+
+// UpdateCheckRun updates the check run with the given ID. Only fields that
+// are set on checkRun are updated, and annotations are appended like GitHub
+// does.
+func (f *FakeClient) UpdateCheckRun(org, repo string, checkRunId int64, checkRun github.CheckRun) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	runs := f.CheckRuns[org+"/"+repo]
+	for i := range runs {
+		if runs[i].ID != checkRunId {
+			continue
+		}
+		if checkRun.Status != "" {
+			runs[i].Status = checkRun.Status
+		}
+		if checkRun.Conclusion != "" {
+			runs[i].Conclusion = checkRun.Conclusion
+		}
+		if checkRun.DetailsURL != "" {
+			runs[i].DetailsURL = checkRun.DetailsURL
+		}
+		if checkRun.CompletedAt != "" {
+			runs[i].CompletedAt = checkRun.CompletedAt
+		}
+		annotations := append(runs[i].Output.Annotations, checkRun.Output.Annotations...)
+		runs[i].Output = checkRun.Output
+		runs[i].Output.Annotations = annotations
+		return nil
+	}
+	return fmt.Errorf("check run %d not found in %s/%s", checkRunId, org, repo)
+}
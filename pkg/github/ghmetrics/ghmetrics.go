@@ -47,6 +47,17 @@ var ghTokenUsageGaugeVec = prometheus.NewGaugeVec(
 	[]string{"token_hash", "api_version", "ratelimit_resource"},
 )
 
+// ghRateLimitRemainingGaugeVec provides the 'github_rate_limit_remaining'
+// gauge that keeps track of the quotas of tokens as seen by the clients,
+// including the ones that don't go through ghproxy.
+var ghRateLimitRemainingGaugeVec = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "github_rate_limit_remaining",
+		Help: "Number of requests left in the current rate limit window, as of the last response.",
+	},
+	[]string{"token_hash", "ratelimit_resource"},
+)
+
 // ghRequestDurationHistVec provides the 'github_request_duration' histogram that keeps track
 // of the duration of GitHub requests by API path.
 var ghRequestDurationHistVec = prometheus.NewHistogramVec(
@@ -107,6 +118,7 @@ var lastGitHubResponse time.Time
 func init() {
 	prometheus.MustRegister(ghTokenUntilResetGaugeVec)
 	prometheus.MustRegister(ghTokenUsageGaugeVec)
+	prometheus.MustRegister(ghRateLimitRemainingGaugeVec)
 	prometheus.MustRegister(ghRequestDurationHistVec)
 	prometheus.MustRegister(ghRequestWaitDurationHistVec)
 	prometheus.MustRegister(cacheCounter)
@@ -150,6 +162,21 @@ func CollectGitHubTokenMetrics(tokenHash, apiVersion string, headers http.Header
 	}
 }
 
+// CollectGitHubRateLimitRemaining publishes the remaining requests of the
+// rate limit headers of a response, if it has them, to
+// `github_rate_limit_remaining` on prometheus.
+func CollectGitHubRateLimitRemaining(tokenHash string, headers http.Header) {
+	remaining, err := strconv.Atoi(headers.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	resource := headers.Get("X-RateLimit-Resource")
+	if resource == "" {
+		resource = "core"
+	}
+	ghRateLimitRemainingGaugeVec.With(prometheus.Labels{"token_hash": tokenHash, "ratelimit_resource": resource}).Set(float64(remaining))
+}
+
 // CollectGitHubRequestMetrics publishes the number of requests by API path to
 // `github_requests` on prometheus.
 func CollectGitHubRequestMetrics(tokenHash, path, statusCode, userAgent string, roundTripTime float64) {
@@ -0,0 +1,45 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ghmetrics
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollectGitHubRateLimitRemaining(t *testing.T) {
+	CollectGitHubRateLimitRemaining("first", http.Header{"X-Ratelimit-Remaining": {"4999"}})
+	CollectGitHubRateLimitRemaining("first", http.Header{"X-Ratelimit-Remaining": {"29"}, "X-Ratelimit-Resource": {"search"}})
+	CollectGitHubRateLimitRemaining("second", http.Header{"X-Ratelimit-Remaining": {"10"}})
+	// Responses without rate limit headers don't reset the gauge.
+	CollectGitHubRateLimitRemaining("second", http.Header{})
+
+	for _, tc := range []struct {
+		tokenHash, resource string
+		expected            float64
+	}{
+		{tokenHash: "first", resource: "core", expected: 4999},
+		{tokenHash: "first", resource: "search", expected: 29},
+		{tokenHash: "second", resource: "core", expected: 10},
+	} {
+		if got := testutil.ToFloat64(ghRateLimitRemainingGaugeVec.WithLabelValues(tc.tokenHash, tc.resource)); got != tc.expected {
+			t.Errorf("expected %v remaining %s requests of %s, got %v", tc.expected, tc.resource, tc.tokenHash, got)
+		}
+	}
+}
@@ -66,13 +66,16 @@ func prowjobStateToGitHubStatus(pjState prowapi.ProwJobState) (string, error) {
 	case prowapi.FailureState:
 		return github.StatusFailure, nil
 	case prowapi.AbortedState:
+		// Statuses can't be cancelled, and a successful status would let
+		// tide merge a change whose job never finished. Check runs report
+		// aborted jobs as cancelled instead.
 		return github.StatusFailure, nil
 	}
 	return "", fmt.Errorf("Unknown prowjob state: %s", pjState)
 }
 
 // reportStatus should be called on any prowjob status changes
-func reportStatus(ctx context.Context, ghc GitHubClient, pj prowapi.ProwJob) error {
+func reportStatus(ctx context.Context, ghc GitHubClient, pj prowapi.ProwJob, cfg config.GitHubReporter) error {
 	refs := pj.Spec.Refs
 	if pj.Spec.Report {
 		contextState, err := prowjobStateToGitHubStatus(pj.Status.State)
@@ -86,7 +89,7 @@ func reportStatus(ctx context.Context, ghc GitHubClient, pj prowapi.ProwJob) err
 		if err := ghc.CreateStatusWithContext(ctx, refs.Org, refs.Repo, sha, github.Status{
 			State:       contextState,
 			Description: config.ContextDescriptionWithBaseSha(pj.Status.Description, refs.BaseSHA),
-			Context:     pj.Spec.Context, // consider truncating this too
+			Context:     cfg.StatusContext(pj.Spec.Context, pj.Spec.Cluster),
 			TargetURL:   pj.Status.URL,
 		}); err != nil {
 			return err
@@ -141,7 +144,7 @@ func ReportStatusContext(ctx context.Context, ghc GitHubClient, pj prowapi.ProwJ
 		return nil
 	}
 
-	if err := reportStatus(ctx, ghc, pj); err != nil {
+	if err := reportStatus(ctx, ghc, pj, config); err != nil {
 		return fmt.Errorf("error setting status: %w", err)
 	}
 	return nil
@@ -375,7 +375,7 @@ func TestReportStatus(t *testing.T) {
 				},
 			}
 			// Run
-			if err := reportStatus(context.Background(), ghc, pj); err != nil {
+			if err := reportStatus(context.Background(), ghc, pj, config.GitHubReporter{}); err != nil {
 				t.Error(err)
 			}
 			// Check
@@ -395,6 +395,37 @@ func TestReportStatus(t *testing.T) {
 	}
 }
 
+func TestReportStatusAppendsCluster(t *testing.T) {
+	cfg := config.GitHubReporter{AppendClusterToContext: true, ClusterContextSeparator: "@"}
+	ghc := &fakeGhClient{}
+	for _, cluster := range []string{"build01", "build02"} {
+		pj := prowapi.ProwJob{
+			Status: prowapi.ProwJobStatus{State: prowapi.SuccessState},
+			Spec: prowapi.ProwJobSpec{
+				Type:    prowapi.PresubmitJob,
+				Context: "parent",
+				Cluster: cluster,
+				Report:  true,
+				Refs: &prowapi.Refs{
+					Org:   "k8s",
+					Repo:  "test-infra",
+					Pulls: []prowapi.Pull{{Number: 1, SHA: "abcdef"}},
+				},
+			},
+		}
+		if err := reportStatus(context.Background(), ghc, pj, cfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var contexts []string
+	for _, status := range ghc.status {
+		contexts = append(contexts, status.Context)
+	}
+	if diff := cmp.Diff([]string{"parent@build01", "parent@build02"}, contexts); diff != "" {
+		t.Errorf("contexts differ from expected: %s", diff)
+	}
+}
+
 func TestShouldReport(t *testing.T) {
 	var testcases = []struct {
 		name       string
@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/github"
+)
+
+// rollupRanks orders the states of jobs from best to worst for the rollup
+// status.
+var rollupRanks = map[prowapi.ProwJobState]int{
+	prowapi.SuccessState:   0,
+	prowapi.TriggeredState: 1,
+	prowapi.PendingState:   1,
+	prowapi.AbortedState:   2,
+	prowapi.FailureState:   3,
+	prowapi.ErrorState:     4,
+}
+
+// WorstState returns the worst state of the jobs, in the order error,
+// failure, aborted, pending or triggered and success. It returns an empty
+// state if there are no jobs.
+func WorstState(pjs []prowapi.ProwJob) prowapi.ProwJobState {
+	var worst prowapi.ProwJobState
+	for _, pj := range pjs {
+		if worst == "" || rollupRanks[pj.Status.State] > rollupRanks[worst] {
+			worst = pj.Status.State
+		}
+	}
+	return worst
+}
+
+// RollupStatus returns a status with the given context that rolls up the
+// states of the jobs. Its target URL is that of the first job in the worst
+// state.
+func RollupStatus(pjs []prowapi.ProwJob, context string) (github.Status, error) {
+	worst := WorstState(pjs)
+	if worst == "" {
+		return github.Status{}, errors.New("no jobs to roll up")
+	}
+	state, err := prowjobStateToGitHubStatus(worst)
+	if err != nil {
+		return github.Status{}, err
+	}
+
+	var failed, pending int
+	var targetURL string
+	for _, pj := range pjs {
+		switch pj.Status.State {
+		case prowapi.FailureState, prowapi.ErrorState, prowapi.AbortedState:
+			failed++
+		case prowapi.TriggeredState, prowapi.PendingState:
+			pending++
+		}
+		if targetURL == "" && pj.Status.State == worst {
+			targetURL = pj.Status.URL
+		}
+	}
+	var description string
+	switch {
+	case failed > 0 && pending > 0:
+		description = fmt.Sprintf("%d/%d jobs failed, %d pending", failed, len(pjs), pending)
+	case failed > 0:
+		description = fmt.Sprintf("%d/%d jobs failed", failed, len(pjs))
+	case pending > 0:
+		description = fmt.Sprintf("%d/%d jobs pending", pending, len(pjs))
+	default:
+		description = fmt.Sprintf("All %d jobs passed", len(pjs))
+	}
+
+	return github.Status{
+		State:       state,
+		Description: description,
+		Context:     context,
+		TargetURL:   targetURL,
+	}, nil
+}
+
+// ReportRollupStatus reports the rollup status of the jobs, which must all
+// have run for the same pull request commit, on that commit.
+func ReportRollupStatus(ctx context.Context, ghc GitHubClient, pjs []prowapi.ProwJob, cfg config.GitHubReporter) error {
+	if len(pjs) == 0 {
+		return nil
+	}
+	refs := pjs[0].Spec.Refs
+	if refs == nil || len(refs.Pulls) != 1 {
+		return nil
+	}
+	status, err := RollupStatus(pjs, cfg.RollupStatusContext())
+	if err != nil {
+		return err
+	}
+	if err := ghc.CreateStatusWithContext(ctx, refs.Org, refs.Repo, refs.Pulls[0].SHA, status); err != nil {
+		return fmt.Errorf("error setting rollup status: %w", err)
+	}
+	return nil
+}
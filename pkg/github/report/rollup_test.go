@@ -0,0 +1,147 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/github"
+)
+
+func jobsInStates(states ...prowapi.ProwJobState) []prowapi.ProwJob {
+	var pjs []prowapi.ProwJob
+	for i, state := range states {
+		pjs = append(pjs, prowapi.ProwJob{Status: prowapi.ProwJobStatus{
+			State: state,
+			URL:   "https://prow.example.com/" + string(rune('a'+i)),
+		}})
+	}
+	return pjs
+}
+
+func TestWorstState(t *testing.T) {
+	testCases := []struct {
+		name     string
+		states   []prowapi.ProwJobState
+		expected prowapi.ProwJobState
+	}{
+		{
+			name: "no jobs",
+		},
+		{
+			name:     "all succeeded",
+			states:   []prowapi.ProwJobState{prowapi.SuccessState, prowapi.SuccessState},
+			expected: prowapi.SuccessState,
+		},
+		{
+			name:     "pending is worse than success",
+			states:   []prowapi.ProwJobState{prowapi.SuccessState, prowapi.PendingState, prowapi.TriggeredState},
+			expected: prowapi.PendingState,
+		},
+		{
+			name:     "aborted is worse than pending",
+			states:   []prowapi.ProwJobState{prowapi.PendingState, prowapi.AbortedState, prowapi.SuccessState},
+			expected: prowapi.AbortedState,
+		},
+		{
+			name:     "failure is worse than aborted",
+			states:   []prowapi.ProwJobState{prowapi.AbortedState, prowapi.FailureState, prowapi.PendingState},
+			expected: prowapi.FailureState,
+		},
+		{
+			name:     "error is worst",
+			states:   []prowapi.ProwJobState{prowapi.FailureState, prowapi.ErrorState, prowapi.SuccessState},
+			expected: prowapi.ErrorState,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if worst := WorstState(jobsInStates(tc.states...)); worst != tc.expected {
+				t.Errorf("expected worst state %q, got %q", tc.expected, worst)
+			}
+		})
+	}
+}
+
+func TestRollupStatus(t *testing.T) {
+	testCases := []struct {
+		name     string
+		states   []prowapi.ProwJobState
+		expected github.Status
+	}{
+		{
+			name:   "all succeeded",
+			states: []prowapi.ProwJobState{prowapi.SuccessState, prowapi.SuccessState},
+			expected: github.Status{
+				State:       github.StatusSuccess,
+				Description: "All 2 jobs passed",
+				Context:     "prow",
+				TargetURL:   "https://prow.example.com/a",
+			},
+		},
+		{
+			name:   "pending",
+			states: []prowapi.ProwJobState{prowapi.SuccessState, prowapi.PendingState, prowapi.TriggeredState},
+			expected: github.Status{
+				State:       github.StatusPending,
+				Description: "2/3 jobs pending",
+				Context:     "prow",
+				TargetURL:   "https://prow.example.com/b",
+			},
+		},
+		{
+			name:   "failed while others are pending",
+			states: []prowapi.ProwJobState{prowapi.PendingState, prowapi.SuccessState, prowapi.FailureState, prowapi.AbortedState},
+			expected: github.Status{
+				State:       github.StatusFailure,
+				Description: "2/4 jobs failed, 1 pending",
+				Context:     "prow",
+				TargetURL:   "https://prow.example.com/c",
+			},
+		},
+		{
+			name:   "error",
+			states: []prowapi.ProwJobState{prowapi.FailureState, prowapi.ErrorState},
+			expected: github.Status{
+				State:       github.StatusError,
+				Description: "2/2 jobs failed",
+				Context:     "prow",
+				TargetURL:   "https://prow.example.com/b",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			status, err := RollupStatus(jobsInStates(tc.states...), "prow")
+			if err != nil {
+				t.Fatalf("RollupStatus: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, status); diff != "" {
+				t.Errorf("status differs from expected (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if _, err := RollupStatus(nil, "prow"); err == nil {
+		t.Error("expected an error without jobs")
+	}
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gitlab provides a client for posting commit statuses and merge
+// request notes to GitLab through its REST API.
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// The states a commit status can be in.
+const (
+	Pending  = "pending"
+	Running  = "running"
+	Success  = "success"
+	Failed   = "failed"
+	Canceled = "canceled"
+)
+
+// CommitStatus is the status of a commit. Statuses with the same name
+// replace each other, so a name is usually the name of the build.
+type CommitStatus struct {
+	State       string `json:"state"`
+	Name        string `json:"name"`
+	TargetURL   string `json:"target_url,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Logger provides an interface to log debug messages.
+type Logger interface {
+	Debugf(s string, v ...interface{})
+}
+
+type errorResponse struct {
+	Message interface{} `json:"message"`
+	Error   string      `json:"error"`
+}
+
+// Client allows you to post commit statuses and merge request notes to
+// GitLab.
+type Client struct {
+	// If logger is non-nil, log all method calls with it.
+	logger Logger
+
+	httpClient     *http.Client
+	tokenGenerator func() []byte
+	fake           bool
+}
+
+// NewClient creates a GitLab client. The tokenGenerator must return an
+// access token with the api scope on the projects that are reported to. If
+// httpClient is nil, http.DefaultClient is used.
+func NewClient(tokenGenerator func() []byte, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{
+		logger:         logrus.WithField("client", "gitlab"),
+		httpClient:     httpClient,
+		tokenGenerator: tokenGenerator,
+	}
+}
+
+// NewFakeClient returns a client that takes no actions.
+func NewFakeClient() *Client {
+	return &Client{
+		fake: true,
+	}
+}
+
+func (c *Client) log(methodName string, args ...interface{}) {
+	if c.logger == nil {
+		return
+	}
+	var as []string
+	for _, arg := range args {
+		as = append(as, fmt.Sprintf("%v", arg))
+	}
+	c.logger.Debugf("%s(%s)", methodName, strings.Join(as, ", "))
+}
+
+// SetCommitStatus posts the status of the commit of the project, e.g.
+// `group/project`, to the given server.
+func (c *Client) SetCommitStatus(ctx context.Context, server, project, sha string, status CommitStatus) error {
+	c.log("SetCommitStatus", server, project, sha, status.Name, status.State)
+	if c.fake {
+		return nil
+	}
+
+	path := fmt.Sprintf("/projects/%s/statuses/%s", url.PathEscape(project), url.PathEscape(sha))
+	if err := c.post(ctx, server, path, status); err != nil {
+		return fmt.Errorf("failed to set commit status of %s: %w", sha, err)
+	}
+	return nil
+}
+
+// CreateMergeRequestNote comments on the merge request of the project with
+// the given internal ID.
+func (c *Client) CreateMergeRequestNote(ctx context.Context, server, project string, iid int, body string) error {
+	c.log("CreateMergeRequestNote", server, project, iid)
+	if c.fake {
+		return nil
+	}
+
+	path := fmt.Sprintf("/projects/%s/merge_requests/%d/notes", url.PathEscape(project), iid)
+	if err := c.post(ctx, server, path, map[string]string{"body": body}); err != nil {
+		return fmt.Errorf("failed to comment on merge request %d: %w", iid, err)
+	}
+	return nil
+}
+
+func (c *Client) post(ctx context.Context, server, path string, body interface{}) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	endpoint := strings.TrimSuffix(server, "/") + "/api/v4" + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("PRIVATE-TOKEN", strings.TrimSpace(string(c.tokenGenerator())))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var errResp errorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil || (errResp.Message == nil && errResp.Error == "") {
+			return fmt.Errorf("status %d", resp.StatusCode)
+		}
+		if errResp.Message == nil {
+			return fmt.Errorf("status %d: %s", resp.StatusCode, errResp.Error)
+		}
+		return fmt.Errorf("status %d: %v", resp.StatusCode, errResp.Message)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSetCommitStatus(t *testing.T) {
+	var received CommitStatus
+	var path, method, token string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		method = r.Method
+		token = r.Header.Get("PRIVATE-TOKEN")
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		if strings.HasSuffix(path, "/unknown") {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"404 Commit Not Found"}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	c := NewClient(func() []byte { return []byte("secret-token\n") }, nil)
+
+	status := CommitStatus{State: Success, Name: "pull-test", TargetURL: "https://prow.example.com/view/1", Description: "Job succeeded."}
+	if err := c.SetCommitStatus(context.Background(), server.URL+"/", "group/project", "abc123", status); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if method != http.MethodPost {
+		t.Errorf("unexpected method %q", method)
+	}
+	if expected := "/api/v4/projects/group%2Fproject/statuses/abc123"; path != expected {
+		t.Errorf("expected path %q, got %q", expected, path)
+	}
+	if token != "secret-token" {
+		t.Errorf("unexpected token header %q", token)
+	}
+	if received != status {
+		t.Errorf("unexpected status received: %+v", received)
+	}
+
+	err := c.SetCommitStatus(context.Background(), server.URL, "group/project", "unknown", status)
+	if err == nil || !strings.Contains(err.Error(), "404 Commit Not Found") {
+		t.Errorf("expected error about the unknown commit, got %v", err)
+	}
+}
+
+func TestCreateMergeRequestNote(t *testing.T) {
+	var received map[string]string
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	c := NewClient(func() []byte { return []byte("secret-token") }, nil)
+	if err := c.CreateMergeRequestNote(context.Background(), server.URL, "group/sub/project", 42, "Job failed."); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "/api/v4/projects/group%2Fsub%2Fproject/merge_requests/42/notes"; path != expected {
+		t.Errorf("expected path %q, got %q", expected, path)
+	}
+	if received["body"] != "Job failed." {
+		t.Errorf("unexpected note received: %v", received)
+	}
+}
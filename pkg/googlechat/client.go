@@ -0,0 +1,137 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package googlechat provides a client for posting messages to Google Chat
+// spaces through incoming webhooks.
+package googlechat
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"sigs.k8s.io/prow/pkg/webhookclient"
+)
+
+// Message is a Google Chat message. Only the fields used by Prow are
+// included.
+// See https://developers.google.com/workspace/chat/api/reference/rest/v1/spaces.messages
+type Message struct {
+	Text    string       `json:"text,omitempty"`
+	CardsV2 []CardWithID `json:"cardsV2,omitempty"`
+	Thread  *Thread      `json:"thread,omitempty"`
+}
+
+// Thread groups messages. Messages posted with the same thread key are
+// shown as replies in the same thread.
+type Thread struct {
+	ThreadKey string `json:"threadKey"`
+}
+
+// CardWithID is a card of a message.
+type CardWithID struct {
+	CardID string `json:"cardId"`
+	Card   Card   `json:"card"`
+}
+
+// Card is the content of a card.
+type Card struct {
+	Header   *CardHeader `json:"header,omitempty"`
+	Sections []Section   `json:"sections,omitempty"`
+}
+
+// CardHeader is shown at the top of a card.
+type CardHeader struct {
+	Title    string `json:"title"`
+	Subtitle string `json:"subtitle,omitempty"`
+}
+
+// Section is a group of widgets of a card.
+type Section struct {
+	Widgets []Widget `json:"widgets"`
+}
+
+// Widget is an element of a section. Exactly one of its fields must be set.
+type Widget struct {
+	TextParagraph *TextParagraph `json:"textParagraph,omitempty"`
+	ButtonList    *ButtonList    `json:"buttonList,omitempty"`
+}
+
+// TextParagraph is a paragraph of text, which supports basic HTML
+// formatting.
+type TextParagraph struct {
+	Text string `json:"text"`
+}
+
+// ButtonList is a row of buttons.
+type ButtonList struct {
+	Buttons []Button `json:"buttons"`
+}
+
+// Button is a button that opens a link when clicked.
+type Button struct {
+	Text    string  `json:"text"`
+	OnClick OnClick `json:"onClick"`
+}
+
+// OnClick is the action of a button.
+type OnClick struct {
+	OpenLink OpenLink `json:"openLink"`
+}
+
+// OpenLink opens the URL in a new tab.
+type OpenLink struct {
+	URL string `json:"url"`
+}
+
+// NewLinkButton returns a button that opens the URL.
+func NewLinkButton(text, url string) Button {
+	return Button{Text: text, OnClick: OnClick{OpenLink: OpenLink{URL: url}}}
+}
+
+// Client allows you to post messages to Google Chat spaces. Spaces are
+// resolved to incoming webhook URLs using a secret mapping of space name
+// to URL.
+type Client struct {
+	webhooks *webhookclient.Client
+}
+
+// NewClient creates a Google Chat client. The webhooksGenerator must return
+// a YAML or JSON map of space names to incoming webhook URLs. If httpClient
+// is nil, a client with webhookclient.DefaultTimeout is used.
+func NewClient(webhooksGenerator func() []byte, httpClient *http.Client) *Client {
+	return &Client{
+		webhooks: webhookclient.NewClient("googlechat", webhooksGenerator, httpClient),
+	}
+}
+
+// NewFakeClient returns a client that takes no actions.
+func NewFakeClient() *Client {
+	return &Client{
+		webhooks: webhookclient.NewFakeClient(),
+	}
+}
+
+// WriteMessage posts the message to the space.
+func (c *Client) WriteMessage(ctx context.Context, msg *Message, space string) error {
+	var query url.Values
+	if msg.Thread != nil {
+		// Without this, the thread key is ignored and every message
+		// starts a new thread.
+		query = url.Values{"messageReplyOption": {"REPLY_MESSAGE_FALLBACK_TO_NEW_THREAD"}}
+	}
+	return c.webhooks.Post(ctx, space, query, msg)
+}
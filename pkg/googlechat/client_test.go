@@ -0,0 +1,63 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package googlechat
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteMessage(t *testing.T) {
+	var received Message
+	var replyOption string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		replyOption = r.URL.Query().Get("messageReplyOption")
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	c := NewClient(func() []byte { return []byte("oncall: " + server.URL + "?key=k") }, nil)
+	if err := c.WriteMessage(context.Background(), &Message{Text: "hello"}, "oncall"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if received.Text != "hello" || received.Thread != nil {
+		t.Errorf("unexpected message received: %+v", received)
+	}
+	if replyOption != "" {
+		t.Errorf("expected no reply option without a thread key, got %q", replyOption)
+	}
+
+	if err := c.WriteMessage(context.Background(), &Message{Text: "threaded", Thread: &Thread{ThreadKey: "job"}}, "oncall"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if received.Thread == nil || received.Thread.ThreadKey != "job" {
+		t.Errorf("expected thread key to be sent, got %+v", received)
+	}
+	if replyOption != "REPLY_MESSAGE_FALLBACK_TO_NEW_THREAD" {
+		t.Errorf("expected reply option to be set for threaded messages, got %q", replyOption)
+	}
+
+	if err := c.WriteMessage(context.Background(), &Message{Text: "hello"}, "unknown"); err == nil {
+		t.Error("expected error for unknown space")
+	}
+}
@@ -104,6 +104,13 @@ var gracePeriod = 1 * time.Minute
 // have had time to gracefully shut down, or times out. This function is
 // blocking.
 func WaitForGracefulShutdown() {
+	WaitForGracefulShutdownWithTimeout(gracePeriod)
+}
+
+// WaitForGracefulShutdownWithTimeout is like WaitForGracefulShutdown, but
+// waits up to timeout for workers to shut down instead of the default grace
+// period. This is useful for workers that drain in-flight work on shutdown.
+func WaitForGracefulShutdownWithTimeout(timeout time.Duration) {
 	wait(func() {
 		logrus.Info("Interrupt received.")
 	})
@@ -115,7 +122,7 @@ func WaitForGracefulShutdown() {
 	select {
 	case <-finished:
 		logrus.Info("All workers gracefully terminated, exiting.")
-	case <-time.After(gracePeriod):
+	case <-time.After(timeout):
 		logrus.Warn("Timed out waiting for workers to gracefully terminate, exiting.")
 	}
 }
@@ -19,6 +19,8 @@ package fakeopener
 import (
 	"bytes"
 	"context"
+	"crypto/md5"
+	"io"
 	"os"
 
 	pkgio "sigs.k8s.io/prow/pkg/io"
@@ -85,3 +87,32 @@ func (fo *FakeOpener) Writer(ctx context.Context, path string, opts ...pkgio.Wri
 
 	return &nopReadWriteCloser{Buffer: fo.Buffer[path]}, nil
 }
+
+func (fo *FakeOpener) Attributes(ctx context.Context, path string) (pkgio.Attributes, error) {
+	if fo.ReadError != nil {
+		return pkgio.Attributes{}, fo.ReadError
+	}
+	buf, ok := fo.Buffer[path]
+	if !ok {
+		return pkgio.Attributes{}, os.ErrNotExist
+	}
+	sum := md5.Sum(buf.Bytes())
+	return pkgio.Attributes{Size: int64(buf.Len()), MD5: sum[:]}, nil
+}
+
+func (fo *FakeOpener) RangeReader(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	if fo.ReadError != nil {
+		return nil, fo.ReadError
+	}
+	buf, ok := fo.Buffer[path]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	content := buf.Bytes()
+	offset = min(offset, int64(len(content)))
+	end := int64(len(content))
+	if length >= 0 {
+		end = min(offset+length, end)
+	}
+	return io.NopCloser(bytes.NewReader(content[offset:end])), nil
+}
@@ -0,0 +1,259 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fakes3 provides an in-memory server implementing the part of the
+// S3 API the opener uses, so that uploads to s3:// paths can be tested
+// without AWS.
+package fakes3
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Object is an object stored by the server.
+type Object struct {
+	Content         []byte
+	ContentType     string
+	ContentEncoding string
+	Metadata        map[string]string
+	LastModified    time.Time
+}
+
+func (o Object) etag() string {
+	sum := md5.Sum(o.Content)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// Server serves the objects of all buckets with path-style requests. It
+// doesn't check the signatures of requests.
+type Server struct {
+	*httptest.Server
+	lock sync.Mutex
+	// objects are keyed by bucket and key, e.g. `bucket/logs/started.json`.
+	objects map[string]Object
+}
+
+// NewServer starts a server. It has to be closed by the caller.
+func NewServer() *Server {
+	s := &Server{objects: map[string]Object{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Credentials returns the content of an s3-credentials-file that points
+// the opener to the server.
+func (s *Server) Credentials() []byte {
+	creds, _ := json.Marshal(map[string]interface{}{
+		"region":              "us-east-1",
+		"endpoint":            s.URL,
+		"s3_force_path_style": true,
+		"access_key":          "access_key",
+		"secret_key":          "secret_key",
+	})
+	return creds
+}
+
+// Put stores the content as the object with the key in the bucket.
+func (s *Server) Put(bucket, key string, content []byte) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.objects[bucket+"/"+key] = Object{Content: content, LastModified: time.Now()}
+}
+
+// Object returns the object with the key in the bucket.
+func (s *Server) Object(bucket, key string) (Object, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	o, ok := s.objects[bucket+"/"+key]
+	return o, ok
+}
+
+// Keys returns the sorted keys of the objects in the bucket.
+func (s *Server) Keys(bucket string) []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var keys []string
+	for name := range s.objects {
+		if key, ok := strings.CutPrefix(name, bucket+"/"); ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	switch {
+	case key == "" && r.Method == http.MethodGet:
+		s.list(w, r, bucket)
+	case key != "" && r.Method == http.MethodPut:
+		s.put(w, r, bucket, key)
+	case key != "" && (r.Method == http.MethodGet || r.Method == http.MethodHead):
+		s.get(w, r, bucket, key)
+	case key != "" && r.Method == http.MethodDelete:
+		s.lock.Lock()
+		delete(s.objects, bucket+"/"+key)
+		s.lock.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusNotImplemented, "NotImplemented", fmt.Sprintf("%s %s is not implemented", r.Method, r.URL.Path))
+	}
+}
+
+func (s *Server) put(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	content, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "IncompleteBody", err.Error())
+		return
+	}
+	o := Object{
+		Content:         content,
+		ContentType:     r.Header.Get("Content-Type"),
+		ContentEncoding: r.Header.Get("Content-Encoding"),
+		LastModified:    time.Now(),
+	}
+	for name, values := range r.Header {
+		if meta, ok := strings.CutPrefix(strings.ToLower(name), "x-amz-meta-"); ok {
+			if o.Metadata == nil {
+				o.Metadata = map[string]string{}
+			}
+			o.Metadata[meta] = values[0]
+		}
+	}
+	s.lock.Lock()
+	s.objects[bucket+"/"+key] = o
+	s.lock.Unlock()
+	w.Header().Set("ETag", o.etag())
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) get(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	o, ok := s.Object(bucket, key)
+	if !ok {
+		writeError(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+		return
+	}
+	content := o.Content
+	status := http.StatusOK
+	if rng := r.Header.Get("Range"); rng != "" {
+		var start, end int
+		if _, err := fmt.Sscanf(rng, "bytes=%d-%d", &start, &end); err != nil || start >= len(content) {
+			writeError(w, http.StatusRequestedRangeNotSatisfiable, "InvalidRange", "The requested range is not satisfiable.")
+			return
+		}
+		end = min(end, len(content)-1)
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(content)))
+		content = content[start : end+1]
+		status = http.StatusPartialContent
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	w.Header().Set("ETag", o.etag())
+	w.Header().Set("Last-Modified", o.LastModified.UTC().Format(http.TimeFormat))
+	if o.ContentType != "" {
+		w.Header().Set("Content-Type", o.ContentType)
+	}
+	if o.ContentEncoding != "" {
+		w.Header().Set("Content-Encoding", o.ContentEncoding)
+	}
+	for name, value := range o.Metadata {
+		w.Header().Set("x-amz-meta-"+name, value)
+	}
+	w.WriteHeader(status)
+	if r.Method == http.MethodGet {
+		w.Write(content)
+	}
+}
+
+type listBucketResult struct {
+	XMLName        xml.Name       `xml:"ListBucketResult"`
+	Name           string         `xml:"Name"`
+	Prefix         string         `xml:"Prefix"`
+	Delimiter      string         `xml:"Delimiter,omitempty"`
+	KeyCount       int            `xml:"KeyCount"`
+	IsTruncated    bool           `xml:"IsTruncated"`
+	Contents       []listObject   `xml:"Contents"`
+	CommonPrefixes []commonPrefix `xml:"CommonPrefixes"`
+}
+
+type listObject struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int    `xml:"Size"`
+}
+
+type commonPrefix struct {
+	Prefix string `xml:"Prefix"`
+}
+
+// list implements ListObjectsV2 without pagination.
+func (s *Server) list(w http.ResponseWriter, r *http.Request, bucket string) {
+	prefix, delimiter := r.URL.Query().Get("prefix"), r.URL.Query().Get("delimiter")
+	result := listBucketResult{Name: bucket, Prefix: prefix, Delimiter: delimiter}
+	seenPrefixes := map[string]bool{}
+	for _, key := range s.Keys(bucket) {
+		rest, ok := strings.CutPrefix(key, prefix)
+		if !ok {
+			continue
+		}
+		if delimiter != "" {
+			if i := strings.Index(rest, delimiter); i >= 0 {
+				p := prefix + rest[:i+len(delimiter)]
+				if !seenPrefixes[p] {
+					seenPrefixes[p] = true
+					result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix{Prefix: p})
+				}
+				continue
+			}
+		}
+		o, _ := s.Object(bucket, key)
+		result.Contents = append(result.Contents, listObject{
+			Key:          key,
+			LastModified: o.LastModified.UTC().Format(time.RFC3339),
+			ETag:         o.etag(),
+			Size:         len(o.Content),
+		})
+	}
+	result.KeyCount = len(result.Contents) + len(result.CommonPrefixes)
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	xml.NewEncoder(w).Encode(result)
+}
+
+type errorResponse struct {
+	XMLName xml.Name `xml:"Error"`
+	Code    string   `xml:"Code"`
+	Message string   `xml:"Message"`
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	xml.NewEncoder(w).Encode(errorResponse{Code: code, Message: message})
+}
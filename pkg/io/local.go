@@ -0,0 +1,88 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package io
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/prow/pkg/io/providers"
+)
+
+// localPath returns the path on the local filesystem that p refers to, if
+// it is either an absolute path or a file:// URL.
+func localPath(p string) (string, bool) {
+	if strings.HasPrefix(p, "/") {
+		return p, true
+	}
+	if strings.HasPrefix(p, providers.File+"://") {
+		return strings.TrimPrefix(p, providers.File+"://"), true
+	}
+	return "", false
+}
+
+// localWriter writes to a temporary file in the destination's directory
+// and only moves it into place on Close, so that readers never see a
+// partially written file, e.g. on a shared NFS mount.
+type localWriter struct {
+	*os.File
+	path string
+	// doesNotExist makes Close fail with
+	// PreconditionFailedObjectAlreadyExists instead of replacing an
+	// existing file.
+	doesNotExist bool
+}
+
+func newLocalWriter(p string, doesNotExist bool) (*localWriter, error) {
+	dir := filepath.Dir(p)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create directory %q: %w", dir, err)
+	}
+	f, err := os.CreateTemp(dir, "."+filepath.Base(p)+".tmp-*")
+	if err != nil {
+		return nil, fmt.Errorf("create temporary file for %q: %w", p, err)
+	}
+	if err := f.Chmod(0644); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, fmt.Errorf("set permissions of temporary file for %q: %w", p, err)
+	}
+	return &localWriter{File: f, path: p, doesNotExist: doesNotExist}, nil
+}
+
+func (w *localWriter) Close() error {
+	// The temporary file is gone after a successful rename, otherwise it
+	// must not be left behind.
+	defer os.Remove(w.Name())
+	if err := w.File.Close(); err != nil {
+		return err
+	}
+	if w.doesNotExist {
+		// Unlike rename, link fails if the destination already exists.
+		if err := os.Link(w.Name(), w.path); err != nil {
+			if errors.Is(err, os.ErrExist) {
+				return PreconditionFailedObjectAlreadyExists
+			}
+			return err
+		}
+		return nil
+	}
+	return os.Rename(w.Name(), w.path)
+}
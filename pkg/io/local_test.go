@@ -0,0 +1,88 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package io
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/utils/ptr"
+)
+
+func TestLocalWriter(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	o := &opener{}
+	dest := filepath.Join(dir, "logs", "job", "1", "started.json")
+
+	w, err := o.Writer(ctx, "file://"+dest)
+	if err != nil {
+		t.Fatalf("Failed to open writer: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "logs", "job", "1")); err != nil {
+		t.Errorf("Expected parent directories to be created: %v", err)
+	}
+	if _, err := w.Write([]byte("first")); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Errorf("Expected file to be invisible until the writer is closed, got: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to close writer: %v", err)
+	}
+	content, err := ReadContent(ctx, logrus.NewEntry(logrus.StandardLogger()), o, "file://"+dest)
+	if err != nil {
+		t.Fatalf("Failed to read back file: %v", err)
+	}
+	if string(content) != "first" {
+		t.Errorf("Expected content %q, got %q", "first", content)
+	}
+
+	if err := WriteContent(ctx, logrus.NewEntry(logrus.StandardLogger()), o, dest, []byte("second")); err != nil {
+		t.Fatalf("Failed to overwrite file: %v", err)
+	}
+	w, err = o.Writer(ctx, dest, WriterOptions{PreconditionDoesNotExist: ptr.To(true)})
+	if err != nil {
+		t.Fatalf("Failed to open writer: %v", err)
+	}
+	if _, err := w.Write([]byte("third")); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	if err := w.Close(); !errors.Is(err, PreconditionFailedObjectAlreadyExists) {
+		t.Errorf("Expected precondition to fail, got: %v", err)
+	}
+	content, err = os.ReadFile(dest)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(content) != "second" {
+		t.Errorf("Expected content %q, got %q", "second", content)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(dest))
+	if err != nil {
+		t.Fatalf("Failed to list directory: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected temporary files to be cleaned up, got %v", entries)
+	}
+}
@@ -75,6 +75,9 @@ type Attributes struct {
 	ContentLanguage string
 	// Size is the size of the blob's content in bytes.
 	Size int64
+	// MD5 is the MD5 hash of the blob's content as stored, if the storage
+	// provider reports one.
+	MD5 []byte
 	// Metadata includes user-metadata associated with the file
 	Metadata map[string]string
 }
@@ -230,8 +233,8 @@ func (o *opener) Reader(ctx context.Context, path string) (io.ReadCloser, error)
 		}
 		return g.NewReader(ctx)
 	}
-	if strings.HasPrefix(path, "/") {
-		return os.Open(path)
+	if p, ok := localPath(path); ok {
+		return os.Open(p)
 	}
 
 	bucket, relativePath, err := o.getBucket(ctx, path)
@@ -286,14 +289,8 @@ func (o *opener) Writer(ctx context.Context, p string, opts ...WriterOptions) (i
 		options.apply(writer, nil)
 		return writer, nil
 	}
-	if strings.HasPrefix(p, "/") || strings.HasPrefix(p, providers.File+"://") {
-		p := strings.TrimPrefix(p, providers.File+"://")
-		// create parent dir if doesn't exist
-		dir := path.Dir(p)
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return nil, fmt.Errorf("create directory %q: %w", dir, err)
-		}
-		return os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	if p, ok := localPath(p); ok {
+		return newLocalWriter(p, options.PreconditionDoesNotExist != nil && *options.PreconditionDoesNotExist)
 	}
 
 	bucket, relativePath, err := o.getBucket(ctx, p)
@@ -305,7 +302,7 @@ func (o *opener) Writer(ctx context.Context, p string, opts ...WriterOptions) (i
 
 	if options.PreconditionDoesNotExist != nil && *options.PreconditionDoesNotExist {
 		wOpts.BeforeWrite = func(asFunc func(interface{}) bool) error {
-			_, err := o.Reader(ctx, p)
+			r, err := o.Reader(ctx, p)
 			if err != nil {
 				// we got an error, but not object not exists
 				if !IsNotExist(err) {
@@ -314,6 +311,7 @@ func (o *opener) Writer(ctx context.Context, p string, opts ...WriterOptions) (i
 				// Precondition fulfilled, return nil
 				return nil
 			}
+			r.Close()
 			// Precondition failed, we got no err because object already exists
 			return PreconditionFailedObjectAlreadyExists
 		}
@@ -342,6 +340,7 @@ func (o *opener) Attributes(ctx context.Context, path string) (Attributes, error
 			ContentDisposition: attr.ContentDisposition,
 			ContentLanguage:    attr.ContentLanguage,
 			Size:               attr.Size,
+			MD5:                attr.MD5,
 			Metadata:           attr.Metadata,
 		}, nil
 	}
@@ -361,6 +360,7 @@ func (o *opener) Attributes(ctx context.Context, path string) (Attributes, error
 		ContentDisposition: attr.ContentDisposition,
 		ContentLanguage:    attr.ContentLanguage,
 		Size:               attr.Size,
+		MD5:                attr.MD5,
 		Metadata:           attr.Metadata,
 	}, nil
 }
@@ -401,6 +401,10 @@ func (o *opener) SignedURL(ctx context.Context, p string, opts SignedURLOptions)
 	if err != nil {
 		return "", fmt.Errorf("could not get bucket: %w", err)
 	}
+	expiry := opts.Expiry
+	if expiry <= 0 {
+		expiry = DefaultSignedURLExpiry
+	}
 	if strings.HasPrefix(p, providers.GS+"://") {
 		// We specifically want to use cookie auth, see:
 		// https://cloud.google.com/storage/docs/access-control/cookie-based-authentication
@@ -444,7 +448,7 @@ func (o *opener) SignedURL(ctx context.Context, p string, opts SignedURLOptions)
 		}
 		return storage.SignedURL(bucketName, relativePath, &storage.SignedURLOptions{
 			Method:         "GET",
-			Expires:        time.Now().Add(10 * time.Minute),
+			Expires:        time.Now().Add(expiry),
 			GoogleAccessID: auth.ClientEmail,
 			PrivateKey:     []byte(auth.PrivateKey),
 		})
@@ -456,7 +460,7 @@ func (o *opener) SignedURL(ctx context.Context, p string, opts SignedURLOptions)
 	}
 	return bucket.SignedURL(ctx, relativePath, &blob.SignedURLOptions{
 		Method: "GET",
-		Expiry: 10 * time.Minute,
+		Expiry: expiry,
 	})
 }
 
@@ -519,6 +523,12 @@ func WriteContent(ctx context.Context, logger *logrus.Entry, opener Opener, path
 	log.Debug("Uploading")
 	w, err := opener.Writer(ctx, path, opts...)
 	if err != nil {
+		// Blob stores other than GCS, e.g. S3, check the precondition
+		// before returning the writer.
+		if !isErrUnexpected(err) {
+			log.WithError(err).Debug("Not uploading, precondition failed")
+			return nil
+		}
 		return err
 	}
 	_, err = w.Write(content)
@@ -17,6 +17,8 @@ limitations under the License.
 package io
 
 import (
+	"time"
+
 	"cloud.google.com/go/storage"
 	"gocloud.dev/blob"
 	"google.golang.org/api/googleapi"
@@ -32,6 +34,9 @@ type WriterOptions struct {
 	Metadata                 map[string]string
 	PreconditionDoesNotExist *bool
 	CacheControl             *string
+	// PredefinedACL is the predefined ACL of the object, e.g. publicRead. It
+	// is only supported by GCS and ignored by other storage providers.
+	PredefinedACL *string
 }
 
 func (wo WriterOptions) Apply(opts *WriterOptions) {
@@ -53,6 +58,9 @@ func (wo WriterOptions) Apply(opts *WriterOptions) {
 	if wo.CacheControl != nil {
 		opts.CacheControl = wo.CacheControl
 	}
+	if wo.PredefinedACL != nil {
+		opts.PredefinedACL = wo.PredefinedACL
+	}
 }
 
 // Apply applies the WriterOptions to storage.Writer and blob.WriterOptions
@@ -76,6 +84,9 @@ func (wo WriterOptions) apply(writer *storage.Writer, o *blob.WriterOptions) {
 		if wo.CacheControl != nil {
 			writer.ObjectAttrs.CacheControl = *wo.CacheControl
 		}
+		if wo.PredefinedACL != nil {
+			writer.ObjectAttrs.PredefinedACL = *wo.PredefinedACL
+		}
 	}
 
 	if o == nil {
@@ -110,4 +121,11 @@ type SignedURLOptions struct {
 	// UseGSCookieAuth defines if we should use cookie auth for GCS, see:
 	// https://cloud.google.com/storage/docs/access-control/cookie-based-authentication
 	UseGSCookieAuth bool
+	// Expiry is how long the signed URL is valid. Defaults to
+	// DefaultSignedURLExpiry.
+	Expiry time.Duration
 }
+
+// DefaultSignedURLExpiry is how long signed URLs are valid if
+// SignedURLOptions.Expiry is unset.
+const DefaultSignedURLExpiry = 10 * time.Minute
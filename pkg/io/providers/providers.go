@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"path"
 	"strings"
 
 	"gocloud.dev/blob"
@@ -30,8 +31,8 @@ import (
 const (
 	S3 = "s3"
 	GS = "gs"
-	// TODO(danilo-gemoli): complete the implementation since at this time only opener.Writer()
-	// is supported
+	// TODO(danilo-gemoli): complete the implementation since at this time only opener.Reader()
+	// and opener.Writer() are supported
 	File = "file"
 )
 
@@ -133,11 +134,16 @@ func ParseStoragePath(storagePath string) (storageProvider, bucket, relativePath
 	return storageProvider, bucket, relativePath, nil
 }
 
-// StoragePath is the reverse of ParseStoragePath.
-func StoragePath(bucket, path string) (string, error) {
+// StoragePath is the reverse of ParseStoragePath. For file buckets, e.g.
+// file:///mnt/artifacts, the bucket's path is kept, as the bucket is a
+// directory rather than a name.
+func StoragePath(bucket, p string) (string, error) {
 	pp, err := prowv1.ParsePath(bucket)
 	if err != nil {
 		return "", err
 	}
-	return fmt.Sprintf("%s://%s/%s", pp.StorageProvider(), pp.Bucket(), path), nil
+	if pp.StorageProvider() == File {
+		return fmt.Sprintf("%s://%s", File, path.Join(pp.FullPath(), p)), nil
+	}
+	return fmt.Sprintf("%s://%s/%s", pp.StorageProvider(), pp.Bucket(), p), nil
 }
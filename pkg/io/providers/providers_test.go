@@ -167,6 +167,12 @@ func TestStoragePath(t *testing.T) {
 			path:   "b",
 			want:   "s3://a/b",
 		},
+		{
+			name:   "local directory",
+			bucket: "file:///mnt/artifacts",
+			path:   "logs/job/1/started.json",
+			want:   "file:///mnt/artifacts/logs/job/1/started.json",
+		},
 	}
 
 	for _, tc := range tests {
@@ -104,6 +104,9 @@ type Options struct {
 	BasicAuth  BasicAuthGenerator
 	BearerAuth BearerAuthGenerator
 	LogFields  logrus.Fields
+	// Transport is the transport of the requests, e.g. to send them through
+	// a proxy. Defaults to the transport of go-retryablehttp.
+	Transport http.RoundTripper
 }
 
 type Option func(*Options)
@@ -126,6 +129,30 @@ func WithFields(fields logrus.Fields) Option {
 	}
 }
 
+// WithTransport sets the transport the requests of the client are sent
+// with.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *Options) {
+		o.Transport = transport
+	}
+}
+
+// newRetryingClient returns the retrying HTTP client of a Jira client, which
+// records whether it was used.
+func newRetryingClient(o Options, log *logrus.Entry) (*retryablehttp.Client, *clientUsedTransport) {
+	retryingClient := retryablehttp.NewClient()
+	if o.Transport != nil {
+		retryingClient.HTTPClient.Transport = o.Transport
+	}
+	usedFlagTransport := &clientUsedTransport{
+		m:        sync.Mutex{},
+		upstream: retryingClient.HTTPClient.Transport,
+	}
+	retryingClient.HTTPClient.Transport = usedFlagTransport
+	retryingClient.Logger = &retryableHTTPLogrusWrapper{log: log}
+	return retryingClient, usedFlagTransport
+}
+
 func newJiraClient(endpoint string, o Options, retryingClient *retryablehttp.Client) (*jira.Client, error) {
 	retryingClient.HTTPClient.Transport = &metricsTransport{
 		upstream:       retryingClient.HTTPClient.Transport,
@@ -164,13 +191,7 @@ func NewClient(endpoint string, opts ...Option) (Client, error) {
 	if len(o.LogFields) > 0 {
 		log = log.WithFields(o.LogFields)
 	}
-	retryingClient := retryablehttp.NewClient()
-	usedFlagTransport := &clientUsedTransport{
-		m:        sync.Mutex{},
-		upstream: retryingClient.HTTPClient.Transport,
-	}
-	retryingClient.HTTPClient.Transport = usedFlagTransport
-	retryingClient.Logger = &retryableHTTPLogrusWrapper{log: log}
+	retryingClient, usedFlagTransport := newRetryingClient(o, log)
 
 	jiraClient, err := newJiraClient(endpoint, o, retryingClient)
 	if err != nil {
@@ -592,13 +613,7 @@ func (jc *client) WithFields(fields logrus.Fields) Client {
 // a plugin identifier and log field
 func (jc *client) ForPlugin(plugin string) Client {
 	pluginLogger := jc.logger.WithField("plugin", plugin)
-	retryingClient := retryablehttp.NewClient()
-	usedFlagTransport := &clientUsedTransport{
-		m:        sync.Mutex{},
-		upstream: retryingClient.HTTPClient.Transport,
-	}
-	retryingClient.HTTPClient.Transport = usedFlagTransport
-	retryingClient.Logger = &retryableHTTPLogrusWrapper{log: pluginLogger}
+	retryingClient, usedFlagTransport := newRetryingClient(jc.options, pluginLogger)
 	// ignore error as url.String() was passed to the delegate
 	jiraClient, err := newJiraClient(jc.url, jc.options, retryingClient)
 	if err != nil {
@@ -19,6 +19,9 @@ package jira
 import (
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/go-retryablehttp"
@@ -63,3 +66,36 @@ func TestJiraErrorBody(t *testing.T) {
 		t.Errorf("body of non-jira error is `%s`; expected empty string", body)
 	}
 }
+
+// recordingTransport answers every request with an empty issue and records
+// the requested URLs.
+type recordingTransport struct {
+	requests []string
+}
+
+func (rt *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.requests = append(rt.requests, req.URL.String())
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"key": "ABC-1"}`)),
+		Request:    req,
+	}, nil
+}
+
+func TestWithTransport(t *testing.T) {
+	transport := &recordingTransport{}
+	c, err := NewClient("https://jira.example.com", WithTransport(transport))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if _, err := c.GetIssue("ABC-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := c.ForPlugin("test").GetIssue("ABC-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(transport.requests) != 2 {
+		t.Errorf("expected both clients to send their requests through the transport, got requests %v", transport.requests)
+	}
+}
@@ -69,6 +69,16 @@ const (
 	// IsOptionalLabel is added in resources created by prow and
 	// carries the Optional from a Presubmit job.
 	IsOptionalLabel = "prow.k8s.io/is-optional"
+	// WillRetryAnnotation is set to "true" on a failed ProwJob that is
+	// going to be retried automatically. Prow doesn't retry jobs itself,
+	// the retry annotations are set by the tools that do.
+	WillRetryAnnotation = "prow.k8s.io/will-retry"
+	// RetryAttemptAnnotation carries the number of the automatic retry a
+	// ProwJob is, starting at 0 for the original run.
+	RetryAttemptAnnotation = "prow.k8s.io/retry-attempt"
+	// MaxRetriesAnnotation carries how often a failed ProwJob is retried
+	// automatically at most.
+	MaxRetriesAnnotation = "prow.k8s.io/max-retries"
 
 	// Gerrit related labels that are used by Prow
 
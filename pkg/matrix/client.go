@@ -0,0 +1,141 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package matrix provides a client for sending messages to Matrix rooms
+// through the client-server API.
+package matrix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Message is the content of an `m.room.message` event. Body is shown by
+// clients that do not support HTML.
+type Message struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format,omitempty"`
+	FormattedBody string `json:"formatted_body,omitempty"`
+}
+
+// NewHTMLMessage returns a text message with the given plain text body and
+// HTML formatted body.
+func NewHTMLMessage(body, html string) Message {
+	return Message{
+		MsgType:       "m.text",
+		Body:          body,
+		Format:        "org.matrix.custom.html",
+		FormattedBody: html,
+	}
+}
+
+// Logger provides an interface to log debug messages.
+type Logger interface {
+	Debugf(s string, v ...interface{})
+}
+
+type errorResponse struct {
+	ErrCode string `json:"errcode"`
+	Error   string `json:"error"`
+}
+
+// Client allows you to send messages to Matrix rooms.
+type Client struct {
+	// If logger is non-nil, log all method calls with it.
+	logger Logger
+
+	httpClient     *http.Client
+	tokenGenerator func() []byte
+	fake           bool
+}
+
+// NewClient creates a Matrix client. The tokenGenerator must return the
+// access token of the user the messages are sent as. If httpClient is nil,
+// http.DefaultClient is used.
+func NewClient(tokenGenerator func() []byte, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{
+		logger:         logrus.WithField("client", "matrix"),
+		httpClient:     httpClient,
+		tokenGenerator: tokenGenerator,
+	}
+}
+
+// NewFakeClient returns a client that takes no actions.
+func NewFakeClient() *Client {
+	return &Client{
+		fake: true,
+	}
+}
+
+func (c *Client) log(methodName string, args ...interface{}) {
+	if c.logger == nil {
+		return
+	}
+	var as []string
+	for _, arg := range args {
+		as = append(as, fmt.Sprintf("%v", arg))
+	}
+	c.logger.Debugf("%s(%s)", methodName, strings.Join(as, ", "))
+}
+
+// SendMessage sends the message to the room on the given homeserver. The
+// homeserver deduplicates messages with the same transaction ID, which
+// makes retries safe.
+func (c *Client) SendMessage(ctx context.Context, homeserver, roomID, txnID string, msg Message) error {
+	c.log("SendMessage", homeserver, roomID, txnID, msg.Body)
+	if c.fake {
+		return nil
+	}
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		strings.TrimSuffix(homeserver, "/"), url.PathEscape(roomID), url.PathEscape(txnID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(c.tokenGenerator())))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send message to %s: %w", roomID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp errorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+			return fmt.Errorf("failed to send message to %s: status %d", roomID, resp.StatusCode)
+		}
+		return fmt.Errorf("failed to send message to %s: status %d: %s: %s", roomID, resp.StatusCode, errResp.ErrCode, errResp.Error)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package matrix
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSendMessage(t *testing.T) {
+	var received Message
+	var path, method, auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		method = r.Method
+		auth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		if strings.Contains(path, "unknown") {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errcode":"M_FORBIDDEN","error":"You are not in this room."}`))
+			return
+		}
+		w.Write([]byte(`{"event_id":"$abc"}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(func() []byte { return []byte("secret-token\n") }, nil)
+
+	msg := NewHTMLMessage("hello", "<b>hello</b>")
+	if err := c.SendMessage(context.Background(), server.URL+"/", "!room:example.com", "txn-1", msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if method != http.MethodPut {
+		t.Errorf("unexpected method %q", method)
+	}
+	if expected := "/_matrix/client/v3/rooms/%21room:example.com/send/m.room.message/txn-1"; path != expected {
+		t.Errorf("expected path %q, got %q", expected, path)
+	}
+	if auth != "Bearer secret-token" {
+		t.Errorf("unexpected authorization header %q", auth)
+	}
+	if received != msg {
+		t.Errorf("unexpected message received: %+v", received)
+	}
+
+	err := c.SendMessage(context.Background(), server.URL, "!unknown:example.com", "txn-2", msg)
+	if err == nil || !strings.Contains(err.Error(), "M_FORBIDDEN") {
+		t.Errorf("expected M_FORBIDDEN error, got %v", err)
+	}
+}
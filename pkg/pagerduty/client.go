@@ -0,0 +1,185 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pagerduty provides a client for sending events to the PagerDuty
+// Events API v2.
+package pagerduty
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// EventsAPIURL is the endpoint of the PagerDuty Events API v2.
+	EventsAPIURL = "https://events.pagerduty.com/v2/enqueue"
+
+	// MaxSummaryLength is the maximum number of characters PagerDuty accepts
+	// in the summary of an alert.
+	MaxSummaryLength = 1024
+)
+
+// EventAction is the type of an event.
+type EventAction string
+
+const (
+	// EventActionTrigger opens an alert, or adds to the open alert with the
+	// same dedup key.
+	EventActionTrigger EventAction = "trigger"
+	// EventActionResolve resolves the open alert with the same dedup key.
+	EventActionResolve EventAction = "resolve"
+)
+
+// Logger provides an interface to log debug messages.
+type Logger interface {
+	Debugf(s string, v ...interface{})
+}
+
+// Event is the payload accepted by the Events API v2.
+// See https://developer.pagerduty.com/docs/events-api-v2/trigger-events/
+type Event struct {
+	RoutingKey  string      `json:"routing_key"`
+	EventAction EventAction `json:"event_action"`
+	DedupKey    string      `json:"dedup_key,omitempty"`
+	// Payload is required when triggering an alert and must not be set
+	// when resolving one.
+	Payload *Payload `json:"payload,omitempty"`
+	Links   []Link   `json:"links,omitempty"`
+}
+
+// Payload describes the alert.
+type Payload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+// Link is a link attached to the alert.
+type Link struct {
+	Href string `json:"href"`
+	Text string `json:"text,omitempty"`
+}
+
+// NewTriggerEvent returns an Event that triggers an alert. Summaries that
+// exceed MaxSummaryLength are truncated with an ellipsis.
+func NewTriggerEvent(routingKey, dedupKey string, payload Payload) *Event {
+	payload.Summary = truncate(payload.Summary, MaxSummaryLength)
+	return &Event{
+		RoutingKey:  routingKey,
+		EventAction: EventActionTrigger,
+		DedupKey:    dedupKey,
+		Payload:     &payload,
+	}
+}
+
+// NewResolveEvent returns an Event that resolves the alert with the given
+// dedup key.
+func NewResolveEvent(routingKey, dedupKey string) *Event {
+	return &Event{
+		RoutingKey:  routingKey,
+		EventAction: EventActionResolve,
+		DedupKey:    dedupKey,
+	}
+}
+
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-1]) + "…"
+}
+
+// Client allows you to send events to PagerDuty.
+type Client struct {
+	// If logger is non-nil, log all method calls with it.
+	logger Logger
+
+	httpClient *http.Client
+	url        string
+	fake       bool
+}
+
+// NewClient creates a PagerDuty client. If httpClient is nil,
+// http.DefaultClient is used.
+func NewClient(httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{
+		logger:     logrus.WithField("client", "pagerduty"),
+		httpClient: httpClient,
+		url:        EventsAPIURL,
+	}
+}
+
+// NewFakeClient returns a client that takes no actions.
+func NewFakeClient() *Client {
+	return &Client{
+		fake: true,
+	}
+}
+
+func (c *Client) log(methodName string, args ...interface{}) {
+	if c.logger == nil {
+		return
+	}
+	var as []string
+	for _, arg := range args {
+		as = append(as, fmt.Sprintf("%v", arg))
+	}
+	c.logger.Debugf("%s(%s)", methodName, strings.Join(as, ", "))
+}
+
+// SendEvent sends the event to PagerDuty.
+func (c *Client) SendEvent(ctx context.Context, event *Event) error {
+	c.log("SendEvent", event.EventAction, event.DedupKey)
+	if c.fake {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(event); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send %s event: %w", event.EventAction, err)
+	}
+	defer resp.Body.Close()
+
+	// The Events API answers with 202 Accepted once the event was queued.
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s event failed with status %d: %s", event.EventAction, resp.StatusCode, string(body))
+	}
+
+	return nil
+}
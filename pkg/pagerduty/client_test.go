@@ -0,0 +1,67 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pagerduty
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewTriggerEventTruncatesSummary(t *testing.T) {
+	event := NewTriggerEvent("key", "dedup", Payload{Summary: strings.Repeat("a", MaxSummaryLength+1)})
+	if expected := strings.Repeat("a", MaxSummaryLength-1) + "…"; event.Payload.Summary != expected {
+		t.Errorf("expected summary to be truncated to %d characters, got %d", MaxSummaryLength, len([]rune(event.Payload.Summary)))
+	}
+}
+
+func TestSendEvent(t *testing.T) {
+	var received map[string]interface{}
+	status := http.StatusAccepted
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	c := NewClient(nil)
+	c.url = server.URL
+
+	if err := c.SendEvent(context.Background(), NewResolveEvent("key", "dedup")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]interface{}{
+		"routing_key":  "key",
+		"event_action": "resolve",
+		"dedup_key":    "dedup",
+	}
+	if diff := cmp.Diff(expected, received); diff != "" {
+		t.Errorf("received event differs from expected: %s", diff)
+	}
+
+	status = http.StatusBadRequest
+	if err := c.SendEvent(context.Background(), NewResolveEvent("key", "dedup")); err == nil {
+		t.Error("expected error for rejected event")
+	}
+}
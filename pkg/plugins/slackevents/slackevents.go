@@ -17,6 +17,7 @@ limitations under the License.
 package slackevents
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strings"
@@ -37,7 +38,7 @@ const (
 var sigMatcher = regexp.MustCompile(`(?m)@kubernetes/sig-([\w-]*)-(misc|test-failures|bugs|feature-requests|proposals|pr-reviews|api-reviews)`)
 
 type slackClient interface {
-	WriteMessage(text string, channel string) error
+	WriteMessage(ctx context.Context, text string, channel string) error
 }
 
 type githubClient interface {
@@ -150,7 +151,7 @@ func notifyOnSlackIfManualMerge(pc client, pe github.PushEvent) error {
 				message = fmt.Sprintf("*Warning:* %s (<@%s>) manually merged %d commit(s) into %s: %s", pe.Sender.Login, pe.Sender.Login, len(pe.Commits), pe.Branch(), pe.Compare)
 			}
 			for _, channel := range mw.Channels {
-				if err := pc.SlackClient.WriteMessage(message, channel); err != nil {
+				if err := pc.SlackClient.WriteMessage(context.TODO(), message, channel); err != nil {
 					return err
 				}
 			}
@@ -218,7 +219,7 @@ func echoToSlack(pc client, e github.GenericCommentEvent) error {
 		}
 
 		msg := fmt.Sprintf("%s was mentioned by %s (<@%s>) on GitHub. (%s)\n>>>%s", sig, e.User.Login, e.User.Login, e.HTMLURL, e.Body)
-		if err := pc.SlackClient.WriteMessage(msg, sig); err != nil {
+		if err := pc.SlackClient.WriteMessage(context.TODO(), msg, sig); err != nil {
 			return fmt.Errorf("Failed to send message on slack channel: %q with message %q. Err: %w", sig, msg, err)
 		}
 	}
@@ -17,6 +17,7 @@ limitations under the License.
 package slackevents
 
 import (
+	"context"
 	"encoding/json"
 	"strings"
 	"testing"
@@ -32,7 +33,7 @@ type FakeClient struct {
 	SentMessages map[string][]string
 }
 
-func (fk *FakeClient) WriteMessage(text string, channel string) error {
+func (fk *FakeClient) WriteMessage(_ context.Context, text string, channel string) error {
 	fk.SentMessages[channel] = append(fk.SentMessages[channel], text)
 	return nil
 }
@@ -19,22 +19,74 @@ package resultstore
 import (
 	"context"
 	"crypto/x509"
+	"errors"
 	"fmt"
+	"net"
+	"os"
+	"strconv"
 
 	"google.golang.org/genproto/googleapis/devtools/resultstore/v2"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/credentials/oauth"
 )
 
 // TODO: have client connect itself? Move to flagutils?
 const ResultStoreAddress = "resultstore.googleapis.com:443"
 
+// ConnectOptions configure the connection to ResultStore, e.g. to use a
+// staging instance instead of the production one.
+type ConnectOptions struct {
+	// Endpoint is the host:port of the ResultStore gRPC API. Defaults to
+	// ResultStoreAddress.
+	Endpoint string
+	// Insecure connects without TLS and without credentials, which is only
+	// meant for local fakes.
+	Insecure bool
+	// CAFile is a PEM file with the certificates the server certificate is
+	// verified with instead of the system cert pool.
+	CAFile string
+}
+
+// Validate checks that the endpoint is a host:port pair and that the TLS
+// options don't contradict each other.
+func (o ConnectOptions) Validate() error {
+	if o.Endpoint != "" {
+		host, port, err := net.SplitHostPort(o.Endpoint)
+		if err != nil {
+			return fmt.Errorf("endpoint %q is not in host:port format: %w", o.Endpoint, err)
+		}
+		if host == "" {
+			return fmt.Errorf("endpoint %q has no host", o.Endpoint)
+		}
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return fmt.Errorf("endpoint %q has an invalid port", o.Endpoint)
+		}
+	}
+	if o.Insecure && o.CAFile != "" {
+		return errors.New("a CA file can't be used with an insecure connection")
+	}
+	return nil
+}
+
 // Connect returns a ResultStore GRPC client connection.
-func Connect(ctx context.Context) (*grpc.ClientConn, error) {
-	pool, err := x509.SystemCertPool()
+func Connect(ctx context.Context, opts ConnectOptions) (*grpc.ClientConn, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	endpoint := opts.Endpoint
+	if endpoint == "" {
+		endpoint = ResultStoreAddress
+	}
+	if opts.Insecure {
+		// Per-RPC credentials are only sent over secure connections.
+		return grpc.NewClient(endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+
+	pool, err := certPool(opts.CAFile)
 	if err != nil {
-		return nil, fmt.Errorf("system cert pool: %w", err)
+		return nil, err
 	}
 	creds := credentials.NewClientTLSFromCert(pool, "")
 	const scope = "https://www.googleapis.com/auth/cloud-platform"
@@ -43,7 +95,7 @@ func Connect(ctx context.Context) (*grpc.ClientConn, error) {
 		return nil, fmt.Errorf("create oauth: %w", err)
 	}
 	conn, err := grpc.NewClient(
-		ResultStoreAddress,
+		endpoint,
 		grpc.WithTransportCredentials(creds),
 		grpc.WithPerRPCCredentials(perRPC),
 	)
@@ -53,6 +105,27 @@ func Connect(ctx context.Context) (*grpc.ClientConn, error) {
 	return conn, nil
 }
 
+// certPool returns the certificates of the CA file, or the system cert pool
+// if no file is given.
+func certPool(caFile string) (*x509.CertPool, error) {
+	if caFile == "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			return nil, fmt.Errorf("system cert pool: %w", err)
+		}
+		return pool, nil
+	}
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("read CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in CA file %s", caFile)
+	}
+	return pool, nil
+}
+
 type Client struct {
 	upload resultstore.ResultStoreUploadClient
 }
@@ -0,0 +1,102 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resultstore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConnectOptionsValidate(t *testing.T) {
+	tests := []struct {
+		desc    string
+		opts    ConnectOptions
+		wantErr bool
+	}{
+		{
+			desc: "default",
+		},
+		{
+			desc: "staging endpoint",
+			opts: ConnectOptions{Endpoint: "staging-resultstore.googleapis.com:443"},
+		},
+		{
+			desc: "insecure local endpoint",
+			opts: ConnectOptions{Endpoint: "localhost:8080", Insecure: true},
+		},
+		{
+			desc:    "missing port",
+			opts:    ConnectOptions{Endpoint: "staging-resultstore.googleapis.com"},
+			wantErr: true,
+		},
+		{
+			desc:    "missing host",
+			opts:    ConnectOptions{Endpoint: ":443"},
+			wantErr: true,
+		},
+		{
+			desc:    "invalid port",
+			opts:    ConnectOptions{Endpoint: "localhost:https"},
+			wantErr: true,
+		},
+		{
+			desc:    "URL instead of host:port",
+			opts:    ConnectOptions{Endpoint: "https://resultstore.googleapis.com"},
+			wantErr: true,
+		},
+		{
+			desc:    "CA file with insecure connection",
+			opts:    ConnectOptions{Endpoint: "localhost:8080", Insecure: true, CAFile: "ca.pem"},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := tc.opts.Validate()
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("Validate() got err %v, want err %t", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestConnectInsecure(t *testing.T) {
+	conn, err := Connect(context.Background(), ConnectOptions{Endpoint: "localhost:8080", Insecure: true})
+	if err != nil {
+		t.Fatalf("Connect() err: %v", err)
+	}
+	defer conn.Close()
+	if got, want := conn.Target(), "localhost:8080"; got != want {
+		t.Errorf("Connect() target got %q, want %q", got, want)
+	}
+}
+
+func TestCertPool(t *testing.T) {
+	dir := t.TempDir()
+	noCerts := filepath.Join(dir, "empty.pem")
+	if err := os.WriteFile(noCerts, []byte("not a certificate"), 0644); err != nil {
+		t.Fatalf("WriteFile() err: %v", err)
+	}
+	if _, err := certPool(noCerts); err == nil {
+		t.Error("certPool() of a file without certificates got no error")
+	}
+	if _, err := certPool(filepath.Join(dir, "missing.pem")); err == nil {
+		t.Error("certPool() of a missing file got no error")
+	}
+}
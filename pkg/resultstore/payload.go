@@ -17,9 +17,11 @@ limitations under the License.
 package resultstore
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -39,6 +41,33 @@ type Payload struct {
 	Finished  *metadata.Finished
 	Files     []*resultstore.File
 	ProjectID string
+	// Coverage maps coverage metrics, e.g. "lines", to their percentage.
+	// It is reported as invocation properties if set.
+	Coverage map[string]float64
+	// CustomProperties are additional invocation properties, e.g. the
+	// repo of the job, keyed by property key.
+	CustomProperties map[string]string
+}
+
+// CoverageFile is the artifact that coverage is read from, relative to the
+// job's artifacts dir.
+const CoverageFile = "coverage.json"
+
+// ParseCoverage parses the contents of a CoverageFile. It is a JSON object
+// mapping coverage metrics to their percentage, e.g.
+// {"lines": 81.5, "functions": 90}. Values that aren't numbers are ignored.
+func ParseCoverage(bs []byte) (map[string]float64, error) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(bs, &raw); err != nil {
+		return nil, err
+	}
+	coverage := map[string]float64{}
+	for k, v := range raw {
+		if f, ok := v.(float64); ok {
+			coverage[k] = f
+		}
+	}
+	return coverage, nil
 }
 
 // InvocationID returns the ResultStore InvocationId.
@@ -60,7 +89,7 @@ func (p *Payload) Invocation() (*resultstore.Invocation, error) {
 		Timing:               invocationTiming(p.Job),
 		InvocationAttributes: invocationAttributes(p.ProjectID, p.Job),
 		WorkspaceInfo:        workspaceInfo(p.Job),
-		Properties:           invocationProperties(p.Job, p.Started),
+		Properties:           invocationProperties(p.Job, p.Started, p.Coverage, p.CustomProperties),
 		Files:                p.Files,
 	}
 	return i, nil
@@ -159,10 +188,12 @@ func commandLines(pj *v1.ProwJob) []*resultstore.CommandLine {
 	return cl
 }
 
-func invocationProperties(pj *v1.ProwJob, started *metadata.Started) []*resultstore.Property {
+func invocationProperties(pj *v1.ProwJob, started *metadata.Started, coverage map[string]float64, custom map[string]string) []*resultstore.Property {
 	var ps []*resultstore.Property
 	ps = append(ps, jobProperties(pj)...)
 	ps = append(ps, startedProperties(started)...)
+	ps = append(ps, coverageProperties(coverage)...)
+	ps = append(ps, customProperties(custom)...)
 	return ps
 }
 
@@ -247,6 +278,40 @@ func startedProperties(started *metadata.Started) []*resultstore.Property {
 	return ps
 }
 
+func coverageProperties(coverage map[string]float64) []*resultstore.Property {
+	var keys []string
+	for k := range coverage {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	var ps []*resultstore.Property
+	for _, k := range keys {
+		ps = append(ps, &resultstore.Property{
+			Key:   "Coverage_" + k,
+			Value: strconv.FormatFloat(coverage[k], 'f', -1, 64),
+		})
+	}
+	return ps
+}
+
+func customProperties(custom map[string]string) []*resultstore.Property {
+	var keys []string
+	for k, v := range custom {
+		if k != "" && v != "" {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	var ps []*resultstore.Property
+	for _, k := range keys {
+		ps = append(ps, &resultstore.Property{
+			Key:   k,
+			Value: custom[k],
+		})
+	}
+	return ps
+}
+
 const defaultConfigurationId = "default"
 
 func (p *Payload) DefaultConfiguration() *resultstore.Configuration {
@@ -392,10 +392,12 @@ func TestInvocationTiming(t *testing.T) {
 
 func TestInvocationProperties(t *testing.T) {
 	for _, tc := range []struct {
-		desc    string
-		job     *v1.ProwJob
-		started *metadata.Started
-		want    []*resultstore.Property
+		desc     string
+		job      *v1.ProwJob
+		started  *metadata.Started
+		coverage map[string]float64
+		custom   map[string]string
+		want     []*resultstore.Property
 	}{
 		{
 			desc: "success",
@@ -461,6 +463,44 @@ func TestInvocationProperties(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "coverage",
+			job:  nil,
+			coverage: map[string]float64{
+				"lines":     81.5,
+				"functions": 90,
+			},
+			want: []*resultstore.Property{
+				{
+					Key:   "Coverage_functions",
+					Value: "90",
+				},
+				{
+					Key:   "Coverage_lines",
+					Value: "81.5",
+				},
+			},
+		},
+		{
+			desc: "custom",
+			job:  nil,
+			custom: map[string]string{
+				"Repo_Name": "repo",
+				"Org":       "org",
+				"Pull":      "",
+				"":          "no-key",
+			},
+			want: []*resultstore.Property{
+				{
+					Key:   "Org",
+					Value: "org",
+				},
+				{
+					Key:   "Repo_Name",
+					Value: "repo",
+				},
+			},
+		},
 		{
 			desc: "job nil",
 			job:  nil,
@@ -488,7 +528,7 @@ func TestInvocationProperties(t *testing.T) {
 		},
 	} {
 		t.Run(tc.desc, func(t *testing.T) {
-			got := invocationProperties(tc.job, tc.started)
+			got := invocationProperties(tc.job, tc.started, tc.coverage, tc.custom)
 			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
 				t.Errorf("properties differ (-want +got):\n%s", diff)
 			}
@@ -496,6 +536,41 @@ func TestInvocationProperties(t *testing.T) {
 	}
 }
 
+func TestParseCoverage(t *testing.T) {
+	for _, tc := range []struct {
+		desc    string
+		content string
+		want    map[string]float64
+		wantErr bool
+	}{
+		{
+			desc:    "numbers are parsed",
+			content: `{"lines": 81.5, "functions": 90}`,
+			want:    map[string]float64{"lines": 81.5, "functions": 90},
+		},
+		{
+			desc:    "other values are ignored",
+			content: `{"lines": 81.5, "tool": "gocov", "files": {"main.go": 50}}`,
+			want:    map[string]float64{"lines": 81.5},
+		},
+		{
+			desc:    "invalid JSON",
+			content: `[81.5]`,
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := ParseCoverage([]byte(tc.content))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseCoverage() err = %v, wantErr %t", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("coverage differs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestStartedProperties(t *testing.T) {
 	for _, tc := range []struct {
 		desc    string
@@ -0,0 +1,62 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slack
+
+// Block is a Block Kit layout block, see
+// https://api.slack.com/reference/block-kit/blocks. Only the fields used by
+// prow are supported.
+type Block struct {
+	Type     string         `json:"type"`
+	Text     *TextObject    `json:"text,omitempty"`
+	Fields   []*TextObject  `json:"fields,omitempty"`
+	Elements []BlockElement `json:"elements,omitempty"`
+}
+
+// BlockElement is an element of a block, i.e. an Element of an actions
+// block or a TextObject of a context block.
+type BlockElement interface {
+	blockElement()
+}
+
+// TextObject is a Block Kit text composition object, which is either
+// "plain_text" or "mrkdwn".
+type TextObject struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func (*TextObject) blockElement() {}
+
+// Element is an interactive Block Kit element of an actions block.
+type Element struct {
+	Type     string      `json:"type"`
+	Text     *TextObject `json:"text,omitempty"`
+	URL      string      `json:"url,omitempty"`
+	ActionID string      `json:"action_id,omitempty"`
+}
+
+func (*Element) blockElement() {}
+
+// PlainText returns a plain_text object.
+func PlainText(text string) *TextObject {
+	return &TextObject{Type: "plain_text", Text: text}
+}
+
+// Markdown returns a mrkdwn object.
+func Markdown(text string) *TextObject {
+	return &TextObject{Type: "mrkdwn", Text: text}
+}
@@ -17,7 +17,9 @@ limitations under the License.
 package slack
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -66,21 +68,73 @@ type Client struct {
 	// If logger is non-nil, log all method calls with it.
 	logger Logger
 
+	httpClient     *http.Client
 	tokenGenerator func() []byte
 	fake           bool
 }
 
+// ErrThreadNotFound is returned when replying to a message that was deleted.
+var ErrThreadNotFound = errors.New("thread not found")
+
+// APIError is returned when Slack rejects a request.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Code is the error code returned by Slack, e.g. `channel_not_found`.
+	// It is empty if the response didn't contain one, e.g. for 5xx errors.
+	Code string
+}
+
+func (e *APIError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("request failed: status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("request failed: %s", e.Code)
+}
+
+// permanentErrorCodes are the error codes of requests that keep failing
+// until the config or the Slack workspace is changed.
+var permanentErrorCodes = map[string]bool{
+	"account_inactive":  true,
+	"channel_not_found": true,
+	"invalid_auth":      true,
+	"invalid_blocks":    true,
+	"is_archived":       true,
+	"missing_scope":     true,
+	"msg_too_long":      true,
+	"no_text":           true,
+	"not_authed":        true,
+	"not_in_channel":    true,
+	"restricted_action": true,
+	"token_revoked":     true,
+}
+
+// Retryable tells whether retrying the request can succeed. Requests that
+// were rate limited or failed because of a Slack outage are retryable,
+// requests to channels that don't exist are not.
+func (e *APIError) Retryable() bool {
+	return !permanentErrorCodes[e.Code]
+}
+
 const (
 	chatPostMessage = "https://slack.com/api/chat.postMessage"
+	authTest        = "https://slack.com/api/auth.test"
+	setTopic        = "https://slack.com/api/conversations.setTopic"
+	listChannels    = "https://slack.com/api/conversations.list"
 
 	botName      = "prow"
 	botIconEmoji = ":prow:"
 )
 
-// NewClient creates a slack client with an API token.
-func NewClient(tokenGenerator func() []byte) *Client {
+// NewClient creates a slack client with an API token. If httpClient is nil,
+// http.DefaultClient is used.
+func NewClient(tokenGenerator func() []byte, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
 	return &Client{
 		logger:         logrus.WithField("client", "slack"),
+		httpClient:     httpClient,
 		tokenGenerator: tokenGenerator,
 	}
 }
@@ -111,10 +165,23 @@ func (sl *Client) urlValues() *url.Values {
 	return &uv
 }
 
-func (sl *Client) postMessage(url string, uv *url.Values) error {
-	resp, err := http.PostForm(url, *uv)
+// postForm posts the form to the API method at url. Unlike
+// http.Client.PostForm, the request is cancelled when ctx is done.
+func (sl *Client) postForm(ctx context.Context, url string, uv *url.Values) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(uv.Encode()))
 	if err != nil {
-		return err
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return sl.httpClient.Do(req)
+}
+
+// postMessage posts the message and returns its timestamp, which identifies
+// the message within its channel.
+func (sl *Client) postMessage(ctx context.Context, url string, uv *url.Values) (string, error) {
+	resp, err := sl.postForm(ctx, url, uv)
+	if err != nil {
+		return "", err
 	}
 	defer resp.Body.Close()
 
@@ -122,21 +189,29 @@ func (sl *Client) postMessage(url string, uv *url.Values) error {
 	apiResponse := struct {
 		Ok    bool   `json:"ok"`
 		Error string `json:"error"`
+		TS    string `json:"ts"`
 	}{}
 
 	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return fmt.Errorf("API returned invalid JSON (%q): %w", string(body), err)
+		if resp.StatusCode != 200 {
+			return "", &APIError{StatusCode: resp.StatusCode}
+		}
+		return "", fmt.Errorf("API returned invalid JSON (%q): %w", string(body), err)
 	}
 
+	switch apiResponse.Error {
+	case "thread_not_found", "invalid_thread_ts":
+		return "", ErrThreadNotFound
+	}
 	if resp.StatusCode != 200 || !apiResponse.Ok {
-		return fmt.Errorf("request failed: %s", apiResponse.Error)
+		return "", &APIError{StatusCode: resp.StatusCode, Code: apiResponse.Error}
 	}
 
-	return nil
+	return apiResponse.TS, nil
 }
 
 // WriteMessage adds text to channel
-func (sl *Client) WriteMessage(text, channel string) error {
+func (sl *Client) WriteMessage(ctx context.Context, text, channel string) error {
 	sl.log("WriteMessage", text, channel)
 	if sl.fake {
 		return nil
@@ -146,8 +221,192 @@ func (sl *Client) WriteMessage(text, channel string) error {
 	uv.Add("channel", channel)
 	uv.Add("text", text)
 
-	if err := sl.postMessage(chatPostMessage, uv); err != nil {
+	if _, err := sl.postMessage(ctx, chatPostMessage, uv); err != nil {
+		return fmt.Errorf("failed to post message to %s: %w", channel, err)
+	}
+	return nil
+}
+
+// SetTopic sets the topic of channel.
+func (sl *Client) SetTopic(ctx context.Context, channel, topic string) error {
+	sl.log("SetTopic", channel, topic)
+	if sl.fake {
+		return nil
+	}
+
+	uv := url.Values{}
+	uv.Add("token", string(sl.tokenGenerator()))
+	uv.Add("channel", channel)
+	uv.Add("topic", topic)
+	if _, err := sl.postMessage(ctx, setTopic, &uv); err != nil {
+		return fmt.Errorf("failed to set topic of %s: %w", channel, err)
+	}
+	return nil
+}
+
+// AuthTest checks that Slack can be reached and accepts the token.
+func (sl *Client) AuthTest(ctx context.Context) error {
+	sl.log("AuthTest")
+	if sl.fake {
+		return nil
+	}
+
+	uv := url.Values{}
+	uv.Add("token", string(sl.tokenGenerator()))
+	if _, err := sl.postMessage(ctx, authTest, &uv); err != nil {
+		return fmt.Errorf("auth test failed: %w", err)
+	}
+	return nil
+}
+
+// Channels maps the names and IDs of the public and private channels that
+// aren't archived and are visible with the token to their IDs.
+func (sl *Client) Channels(ctx context.Context) (map[string]string, error) {
+	sl.log("Channels")
+	if sl.fake {
+		return map[string]string{}, nil
+	}
+
+	channels := map[string]string{}
+	var cursor string
+	for {
+		uv := url.Values{}
+		uv.Add("token", string(sl.tokenGenerator()))
+		uv.Add("types", "public_channel,private_channel")
+		uv.Add("exclude_archived", "true")
+		uv.Add("limit", "1000")
+		if cursor != "" {
+			uv.Add("cursor", cursor)
+		}
+		page, next, err := sl.listChannels(ctx, &uv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list channels: %w", err)
+		}
+		for name, id := range page {
+			channels[name] = id
+		}
+		if next == "" {
+			return channels, nil
+		}
+		cursor = next
+	}
+}
+
+// listChannels returns a page of channels and the cursor of the next page,
+// which is empty for the last page.
+func (sl *Client) listChannels(ctx context.Context, uv *url.Values) (map[string]string, string, error) {
+	resp, err := sl.postForm(ctx, listChannels, uv)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	apiResponse := struct {
+		Ok       bool   `json:"ok"`
+		Error    string `json:"error"`
+		Channels []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"channels"`
+		ResponseMetadata struct {
+			NextCursor string `json:"next_cursor"`
+		} `json:"response_metadata"`
+	}{}
+	if err := json.Unmarshal(body, &apiResponse); err != nil {
+		if resp.StatusCode != 200 {
+			return nil, "", &APIError{StatusCode: resp.StatusCode}
+		}
+		return nil, "", fmt.Errorf("API returned invalid JSON (%q): %w", string(body), err)
+	}
+	if resp.StatusCode != 200 || !apiResponse.Ok {
+		return nil, "", &APIError{StatusCode: resp.StatusCode, Code: apiResponse.Error}
+	}
+
+	channels := map[string]string{}
+	for _, channel := range apiResponse.Channels {
+		channels[channel.ID] = channel.ID
+		channels[channel.Name] = channel.ID
+	}
+	return channels, apiResponse.ResponseMetadata.NextCursor, nil
+}
+
+// PostMessage adds text to channel and returns the timestamp of the new
+// message. If threadTS is set, the message is posted as a reply in the thread
+// of the message with that timestamp. ErrThreadNotFound is returned if that
+// message doesn't exist anymore.
+func (sl *Client) PostMessage(ctx context.Context, text, channel, threadTS string) (string, error) {
+	sl.log("PostMessage", text, channel, threadTS)
+	if sl.fake {
+		return "", nil
+	}
+
+	var uv = sl.urlValues()
+	uv.Add("channel", channel)
+	uv.Add("text", text)
+	if threadTS != "" {
+		uv.Add("thread_ts", threadTS)
+	}
+
+	ts, err := sl.postMessage(ctx, chatPostMessage, uv)
+	if err != nil {
+		return "", fmt.Errorf("failed to post message to %s: %w", channel, err)
+	}
+	return ts, nil
+}
+
+// PostBlocks adds the Block Kit blocks to channel and returns the timestamp
+// of the new message. Text is shown in notifications and by clients that
+// can't display blocks. ThreadTS behaves like for PostMessage.
+func (sl *Client) PostBlocks(ctx context.Context, text string, blocks []Block, channel, threadTS string) (string, error) {
+	sl.log("PostBlocks", text, len(blocks), channel, threadTS)
+	if sl.fake {
+		return "", nil
+	}
+
+	rawBlocks, err := json.Marshal(blocks)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal blocks: %w", err)
+	}
+	var uv = sl.urlValues()
+	uv.Add("channel", channel)
+	uv.Add("text", text)
+	uv.Add("blocks", string(rawBlocks))
+	if threadTS != "" {
+		uv.Add("thread_ts", threadTS)
+	}
+
+	ts, err := sl.postMessage(ctx, chatPostMessage, uv)
+	if err != nil {
+		return "", fmt.Errorf("failed to post message to %s: %w", channel, err)
+	}
+	return ts, nil
+}
+
+// WriteThreadedMessage adds text to channel and posts the replies in the
+// thread of that message.
+func (sl *Client) WriteThreadedMessage(ctx context.Context, text string, replies []string, channel string) error {
+	sl.log("WriteThreadedMessage", text, replies, channel)
+	if sl.fake {
+		return nil
+	}
+
+	var uv = sl.urlValues()
+	uv.Add("channel", channel)
+	uv.Add("text", text)
+
+	ts, err := sl.postMessage(ctx, chatPostMessage, uv)
+	if err != nil {
 		return fmt.Errorf("failed to post message to %s: %w", channel, err)
 	}
+	for _, reply := range replies {
+		uv := sl.urlValues()
+		uv.Add("channel", channel)
+		uv.Add("text", reply)
+		uv.Add("thread_ts", ts)
+		if _, err := sl.postMessage(ctx, chatPostMessage, uv); err != nil {
+			return fmt.Errorf("failed to post reply to %s: %w", channel, err)
+		}
+	}
 	return nil
 }
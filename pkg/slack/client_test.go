@@ -17,8 +17,13 @@ limitations under the License.
 package slack
 
 import (
+	"context"
 	"flag"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -34,3 +39,79 @@ func TestHostsFlag(t *testing.T) {
 		t.Fatalf("Arg parsing mismatch. Want(-), got(+):\n%s", diff)
 	}
 }
+
+func TestAPIErrorRetryable(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      *APIError
+		expected bool
+	}{
+		{
+			name:     "unknown channel is permanent",
+			err:      &APIError{StatusCode: 200, Code: "channel_not_found"},
+			expected: false,
+		},
+		{
+			name:     "revoked token is permanent",
+			err:      &APIError{StatusCode: 200, Code: "token_revoked"},
+			expected: false,
+		},
+		{
+			name:     "rate limit is retryable",
+			err:      &APIError{StatusCode: 429, Code: "ratelimited"},
+			expected: true,
+		},
+		{
+			name:     "server error is retryable",
+			err:      &APIError{StatusCode: 503},
+			expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if retryable := tc.err.Retryable(); retryable != tc.expected {
+				t.Errorf("expected Retryable() to be %t, got %t", tc.expected, retryable)
+			}
+		})
+	}
+}
+
+// redirectTransport sends all requests to the server at target, so that the
+// client can be tested against a fake Slack API.
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (rt *redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = rt.target.Scheme, rt.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestWriteMessageHonoursContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+	target, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("failed to parse server URL: %v", err)
+	}
+
+	c := NewClient(func() []byte { return []byte("token") }, &http.Client{Transport: &redirectTransport{target: target}})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- c.WriteMessage(ctx, "hello", "channel") }()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("expected error when the context is done")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("WriteMessage didn't return after the context was done")
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package teams provides a client for posting MessageCards to Microsoft
+// Teams incoming webhooks.
+package teams
+
+import (
+	"context"
+	"net/http"
+
+	"sigs.k8s.io/prow/pkg/webhookclient"
+)
+
+// MessageCard is the legacy actionable message card format accepted by
+// Teams incoming webhooks.
+// See https://learn.microsoft.com/en-us/outlook/actionable-messages/message-card-reference
+type MessageCard struct {
+	Type            string   `json:"@type"`
+	Context         string   `json:"@context"`
+	ThemeColor      string   `json:"themeColor,omitempty"`
+	Summary         string   `json:"summary"`
+	Title           string   `json:"title,omitempty"`
+	Text            string   `json:"text,omitempty"`
+	PotentialAction []Action `json:"potentialAction,omitempty"`
+}
+
+// Action is a MessageCard action. Only OpenUri actions are used by Prow.
+type Action struct {
+	Type    string   `json:"@type"`
+	Name    string   `json:"name"`
+	Targets []Target `json:"targets,omitempty"`
+}
+
+// Target is the target of an OpenUri action.
+type Target struct {
+	OS  string `json:"os"`
+	URI string `json:"uri"`
+}
+
+// NewMessageCard returns a MessageCard with the mandatory type and context set.
+func NewMessageCard(title, text, themeColor string) *MessageCard {
+	return &MessageCard{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		ThemeColor: themeColor,
+		Summary:    title,
+		Title:      title,
+		Text:       text,
+	}
+}
+
+// AddOpenURIAction adds a button to the card that opens the given URI.
+func (m *MessageCard) AddOpenURIAction(name, uri string) {
+	m.PotentialAction = append(m.PotentialAction, Action{
+		Type:    "OpenUri",
+		Name:    name,
+		Targets: []Target{{OS: "default", URI: uri}},
+	})
+}
+
+// Client allows you to post MessageCards to Teams channels. Channels are
+// resolved to incoming webhook URLs using a secret mapping of channel
+// name to URL.
+type Client struct {
+	webhooks *webhookclient.Client
+}
+
+// NewClient creates a Teams client. The webhooksGenerator must return a
+// YAML or JSON map of channel names to incoming webhook URLs. If
+// httpClient is nil, a client with webhookclient.DefaultTimeout is used.
+func NewClient(webhooksGenerator func() []byte, httpClient *http.Client) *Client {
+	return &Client{
+		webhooks: webhookclient.NewClient("teams", webhooksGenerator, httpClient),
+	}
+}
+
+// NewFakeClient returns a client that takes no actions.
+func NewFakeClient() *Client {
+	return &Client{
+		webhooks: webhookclient.NewFakeClient(),
+	}
+}
+
+// WriteMessage posts the card to the channel.
+func (c *Client) WriteMessage(ctx context.Context, card *MessageCard, channel string) error {
+	return c.webhooks.Post(ctx, channel, nil, card)
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package telegram provides a client for sending messages through the
+// Telegram Bot API.
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// APIURL is the endpoint of the Telegram Bot API.
+const APIURL = "https://api.telegram.org"
+
+// markdownV2Replacer escapes all characters that have a meaning in
+// MarkdownV2. See https://core.telegram.org/bots/api#markdownv2-style
+var markdownV2Replacer = strings.NewReplacer(
+	`\`, `\\`, `_`, `\_`, `*`, `\*`, `[`, `\[`, `]`, `\]`, `(`, `\(`, `)`, `\)`,
+	`~`, `\~`, "`", "\\`", `>`, `\>`, `#`, `\#`, `+`, `\+`, `-`, `\-`, `=`, `\=`,
+	`|`, `\|`, `{`, `\{`, `}`, `\}`, `.`, `\.`, `!`, `\!`,
+)
+
+// linkURLReplacer escapes the characters that must be escaped inside the
+// URL part of an inline link.
+var linkURLReplacer = strings.NewReplacer(`\`, `\\`, `)`, `\)`)
+
+// EscapeMarkdownV2 escapes s so that it is shown verbatim when sent with
+// the MarkdownV2 parse mode.
+func EscapeMarkdownV2(s string) string {
+	return markdownV2Replacer.Replace(s)
+}
+
+// MarkdownV2Link returns an inline link with the given text in MarkdownV2.
+func MarkdownV2Link(text, href string) string {
+	return fmt.Sprintf("[%s](%s)", EscapeMarkdownV2(text), linkURLReplacer.Replace(href))
+}
+
+// Logger provides an interface to log debug messages.
+type Logger interface {
+	Debugf(s string, v ...interface{})
+}
+
+type sendMessageRequest struct {
+	ChatID                string `json:"chat_id"`
+	Text                  string `json:"text"`
+	ParseMode             string `json:"parse_mode"`
+	DisableWebPagePreview bool   `json:"disable_web_page_preview"`
+}
+
+type apiResponse struct {
+	OK          bool   `json:"ok"`
+	Description string `json:"description"`
+}
+
+// Client allows you to send messages to Telegram chats as a bot.
+type Client struct {
+	// If logger is non-nil, log all method calls with it.
+	logger Logger
+
+	httpClient     *http.Client
+	tokenGenerator func() []byte
+	url            string
+	fake           bool
+}
+
+// NewClient creates a Telegram client. The tokenGenerator must return the
+// token of the bot the messages are sent as. If httpClient is nil,
+// http.DefaultClient is used.
+func NewClient(tokenGenerator func() []byte, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{
+		logger:         logrus.WithField("client", "telegram"),
+		httpClient:     httpClient,
+		tokenGenerator: tokenGenerator,
+		url:            APIURL,
+	}
+}
+
+// NewFakeClient returns a client that takes no actions.
+func NewFakeClient() *Client {
+	return &Client{
+		fake: true,
+	}
+}
+
+func (c *Client) log(methodName string, args ...interface{}) {
+	if c.logger == nil {
+		return
+	}
+	var as []string
+	for _, arg := range args {
+		as = append(as, fmt.Sprintf("%v", arg))
+	}
+	c.logger.Debugf("%s(%s)", methodName, strings.Join(as, ", "))
+}
+
+// SendMessage sends the MarkdownV2 formatted text to the chat, which is
+// either a numeric chat ID or the `@username` of a channel.
+func (c *Client) SendMessage(ctx context.Context, chatID, text string) error {
+	c.log("SendMessage", chatID, text)
+	if c.fake {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(sendMessageRequest{
+		ChatID:                chatID,
+		Text:                  text,
+		ParseMode:             "MarkdownV2",
+		DisableWebPagePreview: true,
+	}); err != nil {
+		return err
+	}
+
+	token := strings.TrimSpace(string(c.tokenGenerator()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/bot%s/sendMessage", c.url, token), &buf)
+	if err != nil {
+		// The error contains the URL, which contains the bot token.
+		return errors.New("failed to create request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		// The URL contains the bot token, don't leak it into the logs.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("failed to send message to %s: %w", chatID, err)
+	}
+	defer resp.Body.Close()
+
+	var apiResp apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return fmt.Errorf("failed to decode response with status %d: %w", resp.StatusCode, err)
+	}
+	if !apiResp.OK {
+		return fmt.Errorf("failed to send message to %s: status %d: %s", chatID, resp.StatusCode, apiResp.Description)
+	}
+	return nil
+}
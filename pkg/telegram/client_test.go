@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEscapeMarkdownV2(t *testing.T) {
+	testCases := []struct {
+		name     string
+		in       string
+		expected string
+	}{
+		{
+			name:     "plain text is kept",
+			in:       "job failed",
+			expected: "job failed",
+		},
+		{
+			name:     "special characters are escaped",
+			in:       `ci-job_1.2 (v1) [x] *bold* ~a~ ` + "`c`" + ` >#+=|{}!`,
+			expected: `ci\-job\_1\.2 \(v1\) \[x\] \*bold\* \~a\~ ` + "\\`c\\`" + ` \>\#\+\=\|\{\}\!`,
+		},
+		{
+			name:     "backslashes are escaped",
+			in:       `a\b`,
+			expected: `a\\b`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := EscapeMarkdownV2(tc.in); actual != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestMarkdownV2Link(t *testing.T) {
+	expected := `[View logs\!](https://prow.k8s.io/view/job_(1\))`
+	if actual := MarkdownV2Link("View logs!", "https://prow.k8s.io/view/job_(1)"); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
+
+func TestSendMessage(t *testing.T) {
+	var received sendMessageRequest
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		if received.ChatID == "unknown" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"ok":false,"description":"Bad Request: chat not found"}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(func() []byte { return []byte("secret-token\n") }, nil)
+	c.url = server.URL
+
+	if err := c.SendMessage(context.Background(), "@prow", "hello"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != "/botsecret-token/sendMessage" {
+		t.Errorf("unexpected path %q", path)
+	}
+	if received.ChatID != "@prow" || received.Text != "hello" || received.ParseMode != "MarkdownV2" {
+		t.Errorf("unexpected message received: %+v", received)
+	}
+
+	err := c.SendMessage(context.Background(), "unknown", "hello")
+	if err == nil || !strings.Contains(err.Error(), "chat not found") {
+		t.Errorf("expected chat not found error, got %v", err)
+	}
+}
+
+func TestSendMessageDoesNotLeakToken(t *testing.T) {
+	c := NewClient(func() []byte { return []byte("secret-token") }, nil)
+	c.url = "http://127.0.0.1:0"
+
+	err := c.SendMessage(context.Background(), "@prow", "hello")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if strings.Contains(err.Error(), "secret-token") {
+		t.Errorf("error leaks the bot token: %v", err)
+	}
+}
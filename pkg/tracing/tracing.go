@@ -0,0 +1,72 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracing contains helpers for exporting OpenTelemetry traces of
+// binaries.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+
+	"sigs.k8s.io/prow/pkg/interrupts"
+)
+
+// shutdownTimeout is how long exporting the spans that are still buffered
+// may take on shutdown.
+const shutdownTimeout = 5 * time.Second
+
+// Instrument exports the traces of the component via OTLP to the host:port
+// endpoint a user has asked for on the command line. Spans are created
+// through the global tracer provider, which doesn't record anything if no
+// endpoint is set.
+func Instrument(component, endpoint string) error {
+	if endpoint == "" {
+		return nil
+	}
+	// The exporter connects lazily, settings like TLS can be changed with
+	// the OTEL_EXPORTER_OTLP_* environment variables.
+	exporter, err := otlptracegrpc.New(context.Background(), otlptracegrpc.WithEndpoint(endpoint))
+	if err != nil {
+		return fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(component)))
+	if err != nil {
+		return fmt.Errorf("failed to create trace resource: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	interrupts.OnInterrupt(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := provider.Shutdown(ctx); err != nil {
+			logrus.WithError(err).Warn("Failed to export the remaining spans.")
+		}
+	})
+	logrus.WithField("endpoint", endpoint).Info("Exporting traces.")
+	return nil
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package webhookclient provides a client for posting JSON messages to
+// incoming webhooks of chat services, e.g. Discord, Teams and Google Chat.
+package webhookclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultTimeout is the timeout of the requests of clients that are created
+// without an HTTP client.
+const DefaultTimeout = 30 * time.Second
+
+// Logger provides an interface to log debug messages.
+type Logger interface {
+	Debugf(s string, v ...interface{})
+}
+
+// Client allows you to post messages to webhooks. Webhooks are resolved to
+// URLs using a secret mapping of webhook name to URL.
+type Client struct {
+	// If logger is non-nil, log all method calls with it.
+	logger Logger
+
+	httpClient        *http.Client
+	webhooksGenerator func() []byte
+	fake              bool
+}
+
+// NewClient creates a webhook client that logs as the given client. The
+// webhooksGenerator must return a YAML or JSON map of webhook names to
+// URLs. If httpClient is nil, a client with DefaultTimeout is used.
+func NewClient(client string, webhooksGenerator func() []byte, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: DefaultTimeout}
+	}
+	return &Client{
+		logger:            logrus.WithField("client", client),
+		httpClient:        httpClient,
+		webhooksGenerator: webhooksGenerator,
+	}
+}
+
+// NewFakeClient returns a client that takes no actions.
+func NewFakeClient() *Client {
+	return &Client{
+		fake: true,
+	}
+}
+
+func (c *Client) log(methodName string, args ...interface{}) {
+	if c.logger == nil {
+		return
+	}
+	var as []string
+	for _, arg := range args {
+		as = append(as, fmt.Sprintf("%v", arg))
+	}
+	c.logger.Debugf("%s(%s)", methodName, strings.Join(as, ", "))
+}
+
+func (c *Client) webhookURL(name string) (string, error) {
+	webhooks := map[string]string{}
+	if err := yaml.Unmarshal(c.webhooksGenerator(), &webhooks); err != nil {
+		return "", fmt.Errorf("failed to parse webhooks: %w", err)
+	}
+	webhookURL, ok := webhooks[name]
+	if !ok || webhookURL == "" {
+		return "", fmt.Errorf("no webhook configured for %q", name)
+	}
+	return webhookURL, nil
+}
+
+func (c *Client) post(ctx context.Context, webhookURL string, query url.Values, msg interface{}) error {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		// The error contains the URL, which is secret.
+		return errors.New("invalid webhook URL")
+	}
+	if len(query) > 0 {
+		q := u.Query()
+		for k, v := range query {
+			q[k] = v
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(msg); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		// Drop the URL of the webhook, which is secret, from the error.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return fmt.Errorf("%s request failed: %w", urlErr.Op, urlErr.Err)
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Some services answer with 204 No Content instead of 200 OK.
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// Post posts the message as JSON to the named webhook. The query is added
+// to the URL of the webhook.
+func (c *Client) Post(ctx context.Context, name string, query url.Values, msg interface{}) error {
+	c.log("Post", name)
+	if c.fake {
+		return nil
+	}
+
+	webhookURL, err := c.webhookURL(name)
+	if err != nil {
+		return err
+	}
+	if err := c.post(ctx, webhookURL, query, msg); err != nil {
+		return fmt.Errorf("failed to post message to %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhookclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPost(t *testing.T) {
+	testCases := []struct {
+		name        string
+		webhook     string
+		status      int
+		delay       time.Duration
+		query       url.Values
+		expectedErr bool
+	}{
+		{
+			name:    "message is posted",
+			webhook: "oncall",
+			status:  http.StatusOK,
+		},
+		{
+			name:    "no content is a success",
+			webhook: "oncall",
+			status:  http.StatusNoContent,
+		},
+		{
+			name:    "query is added to the URL",
+			webhook: "oncall",
+			status:  http.StatusOK,
+			query:   url.Values{"option": {"value"}},
+		},
+		{
+			name:        "unknown webhook",
+			webhook:     "unknown",
+			status:      http.StatusOK,
+			expectedErr: true,
+		},
+		{
+			name:        "error status",
+			webhook:     "oncall",
+			status:      http.StatusBadRequest,
+			expectedErr: true,
+		},
+		{
+			name:        "request times out",
+			webhook:     "oncall",
+			status:      http.StatusOK,
+			delay:       time.Second,
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var received map[string]string
+			var query url.Values
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(tc.delay)
+				query = r.URL.Query()
+				if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+					t.Errorf("failed to decode request: %v", err)
+				}
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			webhookURL := server.URL + "?key=secret"
+			c := NewClient("test", func() []byte { return []byte("oncall: " + webhookURL) }, &http.Client{Timeout: 100 * time.Millisecond})
+			err := c.Post(context.Background(), tc.webhook, tc.query, map[string]string{"text": "hello"})
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatal("expected error, got none")
+				}
+				if strings.Contains(err.Error(), "secret") {
+					t.Errorf("expected error not to contain the webhook URL: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if received["text"] != "hello" {
+				t.Errorf("unexpected message received: %v", received)
+			}
+			if query.Get("key") != "secret" {
+				t.Errorf("expected query of the webhook URL to be kept, got %v", query)
+			}
+			for k := range tc.query {
+				if query.Get(k) != tc.query.Get(k) {
+					t.Errorf("expected query %s=%s, got %v", k, tc.query.Get(k), query)
+				}
+			}
+		})
+	}
+}
+
+func TestPostHonoursContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	c := NewClient("test", func() []byte { return []byte("oncall: " + server.URL) }, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := c.Post(ctx, "oncall", nil, map[string]string{"text": "hello"}); err == nil {
+		t.Error("expected error when the context is done")
+	}
+}